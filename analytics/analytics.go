@@ -0,0 +1,263 @@
+// Package analytics 汇总一通电话中各方的说话事件，生成呼叫中心常用的通话指标：
+// 各方的说话时长、最长的独白、静音占比和抢话次数。
+//
+// Aggregator 实现 bridge.EventSink，可以直接接收多路 bridge.Run 的事件：
+//
+//	agg, err := analytics.New(analytics.Config{})
+//	go bridge.Run(ctx, model, caller, agg)
+//	go bridge.Run(ctx, model, agent, agg)
+//	...
+//	report := agg.Report(callSeconds)
+//
+// 各路音频的时间戳都是相对各自音频开始的秒数，因此各路须同时开始。
+package analytics
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rui-yang-me/silero-vad-go/bridge"
+	"github.com/rui-yang-me/silero-vad-go/speech"
+)
+
+// defaultMaxPause 未设置 Config.MaxPause 时独白中允许的最长停顿
+const defaultMaxPause = time.Second
+
+// Config Aggregator 的配置
+type Config struct {
+	// 同一方两段语音之间的停顿不超过该值且期间没有其他人说话时，计为同一段独白，0 表示默认 1 秒
+	MaxPause time.Duration
+	// 与他人说话重叠至少该时长才计为一次抢话，用于忽略“嗯”“好的”一类附和，0 表示任何重叠都计入
+	MinOverlap time.Duration
+}
+
+// PartyStats 一方的通话指标，时长均为秒
+type PartyStats struct {
+	// 说话总时长
+	TalkTime float64 `json:"talk_time"`
+	// 说话时长占通话时长的比例
+	TalkRatio float64 `json:"talk_ratio"`
+	// 语音片段数
+	Segments int `json:"segments"`
+	// 在他人说话时开始说话的次数
+	Interruptions int `json:"interruptions"`
+	// 最长独白的时长
+	LongestMonologue float64 `json:"longest_monologue"`
+}
+
+// Monologue 一方不间断的说话
+type Monologue struct {
+	Party string  `json:"party"`
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+}
+
+// Report 一通电话的指标，时长均为秒
+type Report struct {
+	// 通话时长
+	Duration float64 `json:"duration"`
+	// 各方的指标，键为事件的来源
+	Parties map[string]PartyStats `json:"parties"`
+	// 没有任何一方说话的时长占通话时长的比例
+	SilenceRatio float64 `json:"silence_ratio"`
+	// 两方或多方同时说话的总时长
+	Overlap float64 `json:"overlap"`
+	// 所有人中最长的独白，没有语音时为零值
+	LongestMonologue Monologue `json:"longest_monologue"`
+}
+
+// span 一方的一段语音
+type span struct {
+	party      string
+	start, end float64
+}
+
+// Aggregator 汇总一通电话中各方的语音片段，可以被多个协程并发调用
+type Aggregator struct {
+	maxPause   float64
+	minOverlap float64
+
+	mu    sync.Mutex
+	spans []span
+	open  map[string]float64 // 尚未结束的片段的开始时间
+}
+
+var _ bridge.EventSink = (*Aggregator)(nil)
+
+// New 创建通话指标汇总
+func New(cfg Config) (*Aggregator, error) {
+	if cfg.MaxPause < 0 {
+		return nil, fmt.Errorf("invalid MaxPause: should not be negative")
+	}
+	if cfg.MinOverlap < 0 {
+		return nil, fmt.Errorf("invalid MinOverlap: should not be negative")
+	}
+	if cfg.MaxPause == 0 {
+		cfg.MaxPause = defaultMaxPause
+	}
+	return &Aggregator{
+		maxPause:   cfg.MaxPause.Seconds(),
+		minOverlap: cfg.MinOverlap.Seconds(),
+		open:       map[string]float64{},
+	}, nil
+}
+
+// HandleEvent 实现 bridge.EventSink，记录开始和结束事件
+func (a *Aggregator) HandleEvent(ev bridge.Event) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if ev.Speaking {
+		a.open[ev.Source] = ev.Segment.SpeechStartAt
+		return nil
+	}
+	delete(a.open, ev.Source)
+	a.add(ev.Source, ev.Segment)
+	return nil
+}
+
+// Add 记录一方已结束的语音片段，用于离线检测的结果
+func (a *Aggregator) Add(party string, seg speech.Segment) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.add(party, seg)
+}
+
+func (a *Aggregator) add(party string, seg speech.Segment) {
+	if seg.SpeechEndAt > seg.SpeechStartAt {
+		a.spans = append(a.spans, span{party: party, start: seg.SpeechStartAt, end: seg.SpeechEndAt})
+	}
+}
+
+// Report 计算到目前为止的通话指标，duration 为通话时长（秒）
+// 尚未结束的片段按在 duration 处结束计算；duration 为 0 时使用最晚的片段结束时间。
+func (a *Aggregator) Report(duration float64) Report {
+	a.mu.Lock()
+	spans := slices.Clone(a.spans)
+	for party, start := range a.open {
+		spans = append(spans, span{party: party, start: start, end: max(duration, start)})
+	}
+	a.mu.Unlock()
+
+	sort.Slice(spans, func(i, j int) bool {
+		if spans[i].start != spans[j].start {
+			return spans[i].start < spans[j].start
+		}
+		return spans[i].party < spans[j].party
+	})
+	if duration == 0 {
+		for _, s := range spans {
+			duration = max(duration, s.end)
+		}
+	}
+
+	r := Report{Duration: duration, Parties: map[string]PartyStats{}}
+	for i, s := range spans {
+		st := r.Parties[s.party]
+		st.TalkTime += s.end - s.start
+		st.Segments++
+		for _, o := range spans[:i] {
+			if o.party != s.party && o.start < s.start && s.start < o.end && min(o.end, s.end)-s.start >= a.minOverlap {
+				st.Interruptions++
+				break
+			}
+		}
+		r.Parties[s.party] = st
+	}
+
+	speaking, overlap := coverage(spans)
+	r.Overlap = overlap
+	if duration > 0 {
+		r.SilenceRatio = max(duration-speaking, 0) / duration
+		for party, st := range r.Parties {
+			st.TalkRatio = st.TalkTime / duration
+			r.Parties[party] = st
+		}
+	}
+
+	a.monologues(spans, &r)
+	return r
+}
+
+// coverage 返回至少一方说话的总时长和两方以上同时说话的总时长
+func coverage(spans []span) (float64, float64) {
+	type edge struct {
+		at    float64
+		delta int
+	}
+	edges := make([]edge, 0, 2*len(spans))
+	for _, s := range spans {
+		edges = append(edges, edge{s.start, 1}, edge{s.end, -1})
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].at != edges[j].at {
+			return edges[i].at < edges[j].at
+		}
+		return edges[i].delta < edges[j].delta
+	})
+
+	var speaking, overlap, last float64
+	active := 0
+	for _, e := range edges {
+		if active >= 1 {
+			speaking += e.at - last
+		}
+		if active >= 2 {
+			overlap += e.at - last
+		}
+		active += e.delta
+		last = e.at
+	}
+	return speaking, overlap
+}
+
+// monologues 合并每一方连续的片段，记录各方和全体最长的独白
+// 片段按开始时间排序，任何其他人的片段都会打断当前的独白。
+func (a *Aggregator) monologues(spans []span, r *Report) {
+	if len(spans) == 0 {
+		return
+	}
+	finish := func(m Monologue) {
+		st := r.Parties[m.Party]
+		st.LongestMonologue = max(st.LongestMonologue, m.End-m.Start)
+		r.Parties[m.Party] = st
+		if m.End-m.Start > r.LongestMonologue.End-r.LongestMonologue.Start {
+			r.LongestMonologue = m
+		}
+	}
+
+	// 停顿期间有其他人说话时不能合并
+	lastEnd := map[string]float64{} // 各方已处理片段的最晚结束时间
+	interrupted := func(m Monologue) bool {
+		for party, end := range lastEnd {
+			if party != m.Party && end > m.End {
+				return true
+			}
+		}
+		return false
+	}
+
+	cur := Monologue{Party: spans[0].party, Start: spans[0].start, End: spans[0].end}
+	lastEnd[cur.Party] = cur.End
+	for _, s := range spans[1:] {
+		if s.party == cur.Party && s.start-cur.End <= a.maxPause && !interrupted(cur) {
+			cur.End = max(cur.End, s.end)
+		} else {
+			finish(cur)
+			cur = Monologue{Party: s.party, Start: s.start, End: s.end}
+		}
+		lastEnd[s.party] = max(lastEnd[s.party], s.end)
+	}
+	finish(cur)
+}
+
+// Reset 清空已记录的片段，开始汇总下一通电话
+func (a *Aggregator) Reset() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.spans = a.spans[:0]
+	clear(a.open)
+}
@@ -0,0 +1,118 @@
+package analytics
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rui-yang-me/silero-vad-go/audio"
+	"github.com/rui-yang-me/silero-vad-go/bridge"
+	"github.com/rui-yang-me/silero-vad-go/internal/testutil"
+	"github.com/rui-yang-me/silero-vad-go/speech"
+)
+
+func seg(start, end float64) speech.Segment {
+	return speech.Segment{SpeechStartAt: start, SpeechEndAt: end}
+}
+
+func TestReport(t *testing.T) {
+	_, err := New(Config{MaxPause: -time.Second})
+	require.Error(t, err)
+
+	agg, err := New(Config{MinOverlap: 300 * time.Millisecond})
+	require.NoError(t, err)
+	require.Equal(t, Report{Parties: map[string]PartyStats{}}, agg.Report(0))
+
+	// agent: 0-4, 4.5-8（同一段独白）、12-14
+	// caller: 8.5-11，在 13-13.2 附和（重叠不足 0.3 秒）、13.5-16 抢话
+	agg.Add("agent", seg(0, 4))
+	agg.Add("agent", seg(4.5, 8))
+	agg.Add("caller", seg(8.5, 11))
+	agg.Add("agent", seg(12, 14))
+	agg.Add("caller", seg(13, 13.2))
+	agg.Add("caller", seg(13.5, 16))
+
+	r := agg.Report(20)
+	require.Equal(t, 20.0, r.Duration)
+	agent, caller := r.Parties["agent"], r.Parties["caller"]
+	require.InDelta(t, 9.5, agent.TalkTime, 1e-9)
+	require.InDelta(t, 9.5/20, agent.TalkRatio, 1e-9)
+	require.Equal(t, 3, agent.Segments)
+	require.Zero(t, agent.Interruptions)
+	require.InDelta(t, 8, agent.LongestMonologue, 1e-9)
+	require.InDelta(t, 5.2, caller.TalkTime, 1e-9)
+	require.Equal(t, 1, caller.Interruptions)
+	require.InDelta(t, 2.5, caller.LongestMonologue, 1e-9)
+
+	// 有人说话：0-4、4.5-8、8.5-11、12-16，共 14 秒；重叠：13-13.2、13.5-14
+	require.InDelta(t, 6.0/20, r.SilenceRatio, 1e-9)
+	require.InDelta(t, 0.7, r.Overlap, 1e-9)
+	require.Equal(t, Monologue{Party: "agent", Start: 0, End: 8}, r.LongestMonologue)
+
+	// 更短的 MaxPause 把第一段独白分开
+	short, err := New(Config{MaxPause: 100 * time.Millisecond})
+	require.NoError(t, err)
+	short.Add("agent", seg(0, 4))
+	short.Add("agent", seg(4.5, 8))
+	require.InDelta(t, 4, short.Report(0).LongestMonologue.End, 1e-9)
+	require.Equal(t, 8.0, short.Report(0).Duration)
+
+	// 尚未结束的片段在 duration 处结束
+	require.NoError(t, agg.HandleEvent(bridge.Event{Source: "agent", Speaking: true, Segment: speech.Segment{SpeechStartAt: 18}}))
+	r = agg.Report(20)
+	require.InDelta(t, 11.5, r.Parties["agent"].TalkTime, 1e-9)
+	require.NoError(t, agg.HandleEvent(bridge.Event{Source: "agent", Segment: seg(18, 19)}))
+	require.InDelta(t, 10.5, agg.Report(20).Parties["agent"].TalkTime, 1e-9)
+
+	agg.Reset()
+	require.Empty(t, agg.Report(0).Parties)
+}
+
+func TestBridge(t *testing.T) {
+	sm := testutil.NewSharedModel(t)
+
+	samples := testutil.ReadSamples(t)
+
+	// 两路音频并发写入同一个 Aggregator，对方的音频延迟 0.5 秒
+	delayed := append(make([]float32, 8000), samples...)
+	agg, err := New(Config{})
+	require.NoError(t, err)
+	var wg sync.WaitGroup
+	parties := map[string][]float32{"agent": samples, "caller": delayed}
+	errs := make(chan error, len(parties))
+	for id, pcm := range parties {
+		raw := make([]byte, 4*len(pcm))
+		for i, v := range pcm {
+			binary.LittleEndian.PutUint32(raw[4*i:], math.Float32bits(v))
+		}
+		src, err := bridge.NewReaderSource(id, bytes.NewReader(raw), audio.FormatFloat32, 16000)
+		require.NoError(t, err)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- bridge.Run(context.Background(), sm, src, agg)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		require.NoError(t, err)
+	}
+
+	duration := float64(len(delayed)) / 16000
+	r := agg.Report(duration)
+	require.Len(t, r.Parties, 2)
+	agent, caller := r.Parties["agent"], r.Parties["caller"]
+	require.Positive(t, agent.TalkTime)
+	require.Positive(t, caller.TalkTime)
+	require.Positive(t, caller.Interruptions)
+	require.Positive(t, r.Overlap)
+	require.Greater(t, r.SilenceRatio, 0.0)
+	require.Less(t, r.SilenceRatio, 1.0)
+}
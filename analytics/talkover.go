@@ -0,0 +1,116 @@
+package analytics
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/rui-yang-me/silero-vad-go/audio"
+	"github.com/rui-yang-me/silero-vad-go/speech"
+)
+
+// 双声道通话录音中两方的名称，左声道为坐席，右声道为客户
+const (
+	PartyAgent    = "agent"
+	PartyCustomer = "customer"
+)
+
+// Interval 一段时间区间，相对音频开始的秒数
+type Interval struct {
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+}
+
+// TalkOverReport 双声道通话录音的抢话分析结果
+type TalkOverReport struct {
+	// 通话指标，Parties 的键为 PartyAgent 和 PartyCustomer
+	Report
+	// 双方同时说话的区间，按时间排序
+	Intervals []Interval `json:"intervals"`
+	// 同时说话的时长占通话时长的百分比
+	TalkOverPercent float64 `json:"talk_over_percent"`
+	// 同时说话的时长占有人说话时长的百分比
+	TalkOverSpeechPercent float64 `json:"talk_over_speech_percent"`
+}
+
+// TalkOver 分别检测双声道录音（坐席在左声道，客户在右声道）的两个声道，计算双方同时说话的区间和比例
+// stereo 为交错排列的双声道采样，采样率与 Detect 的输入相同。录音结束时尚未结束的片段延伸到结尾。
+func TalkOver(model *speech.SharedModel, stereo []float32, cfg Config) (TalkOverReport, error) {
+	if model == nil {
+		return TalkOverReport{}, fmt.Errorf("invalid nil shared model")
+	}
+	agg, err := New(cfg)
+	if err != nil {
+		return TalkOverReport{}, err
+	}
+
+	// 两个声道互不依赖，并发检测
+	var (
+		wg       sync.WaitGroup
+		segments [2][]speech.Segment
+		errs     [2]error
+	)
+	for ch := 0; ch < 2; ch++ {
+		pcm, err := audio.ExtractChannel(stereo, 2, ch)
+		if err != nil {
+			return TalkOverReport{}, err
+		}
+		wg.Add(1)
+		go func(ch int) {
+			defer wg.Done()
+			dc := model.NewContext()
+			defer dc.Close()
+			segments[ch], errs[ch] = dc.Detect(pcm)
+		}(ch)
+	}
+	wg.Wait()
+	for ch, err := range errs {
+		if err != nil {
+			return TalkOverReport{}, fmt.Errorf("failed to detect channel %d: %w", ch, err)
+		}
+	}
+
+	modelCfg := model.GetConfig()
+	rate := modelCfg.InputSampleRate
+	if rate == 0 {
+		rate = modelCfg.SampleRate
+	}
+	duration := float64(len(stereo)/2) / float64(rate)
+	for ch, party := range [2]string{PartyAgent, PartyCustomer} {
+		for i := range segments[ch] {
+			if segments[ch][i].SpeechEndAt == 0 {
+				segments[ch][i].SpeechEndAt = duration
+			}
+			agg.Add(party, segments[ch][i])
+		}
+	}
+
+	r := TalkOverReport{
+		Report:    agg.Report(duration),
+		Intervals: intersect(segments[0], segments[1]),
+	}
+	if r.Duration > 0 {
+		r.TalkOverPercent = 100 * r.Overlap / r.Duration
+	}
+	if speaking := r.Duration * (1 - r.SilenceRatio); speaking > 0 {
+		r.TalkOverSpeechPercent = 100 * r.Overlap / speaking
+	}
+	return r, nil
+}
+
+// intersect 返回两组按时间排序、组内互不重叠的片段的交集
+func intersect(a, b []speech.Segment) []Interval {
+	var out []Interval
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		start := max(a[i].SpeechStartAt, b[j].SpeechStartAt)
+		end := min(a[i].SpeechEndAt, b[j].SpeechEndAt)
+		if end > start {
+			out = append(out, Interval{Start: start, End: end})
+		}
+		if a[i].SpeechEndAt < b[j].SpeechEndAt {
+			i++
+		} else {
+			j++
+		}
+	}
+	return out
+}
@@ -0,0 +1,64 @@
+package analytics
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rui-yang-me/silero-vad-go/internal/testutil"
+	"github.com/rui-yang-me/silero-vad-go/speech"
+)
+
+func TestIntersect(t *testing.T) {
+	a := []speech.Segment{seg(0, 2), seg(3, 6), seg(8, 9)}
+	b := []speech.Segment{seg(1, 4), seg(5, 5.5), seg(5.8, 8.5)}
+	require.Equal(t, []Interval{{1, 2}, {3, 4}, {5, 5.5}, {5.8, 6}, {8, 8.5}}, intersect(a, b))
+	require.Empty(t, intersect(a, nil))
+}
+
+func TestTalkOver(t *testing.T) {
+	sm := testutil.NewSharedModel(t)
+
+	samples := testutil.ReadSamples(t)
+
+	_, err := TalkOver(nil, nil, Config{})
+	require.Error(t, err)
+
+	// 坐席在左声道，客户在右声道且延迟 0.3 秒
+	delay := 4800
+	stereo := make([]float32, 2*(len(samples)+delay))
+	for i, v := range samples {
+		stereo[2*i] = v
+		stereo[2*(i+delay)+1] = v
+	}
+	r, err := TalkOver(sm, stereo, Config{})
+	require.NoError(t, err)
+	require.InDelta(t, float64(len(samples)+delay)/16000, r.Duration, 1e-9)
+	require.Len(t, r.Parties, 2)
+	require.Positive(t, r.Parties[PartyAgent].TalkTime)
+	require.Positive(t, r.Parties[PartyCustomer].Interruptions)
+
+	require.NotEmpty(t, r.Intervals)
+	var total float64
+	for i, iv := range r.Intervals {
+		require.Greater(t, iv.End, iv.Start)
+		if i > 0 {
+			require.GreaterOrEqual(t, iv.Start, r.Intervals[i-1].End)
+		}
+		total += iv.End - iv.Start
+	}
+	require.InDelta(t, r.Overlap, total, 1e-9)
+	require.InDelta(t, 100*total/r.Duration, r.TalkOverPercent, 1e-9)
+	require.Greater(t, r.TalkOverSpeechPercent, r.TalkOverPercent)
+	require.Less(t, r.TalkOverSpeechPercent, 100.0)
+
+	// 只有一方说话时没有重叠
+	mono := make([]float32, 2*len(samples))
+	for i, v := range samples {
+		mono[2*i] = v
+	}
+	r, err = TalkOver(sm, mono, Config{})
+	require.NoError(t, err)
+	require.Empty(t, r.Intervals)
+	require.Zero(t, r.TalkOverPercent)
+}
@@ -0,0 +1,133 @@
+package audio
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+const (
+	// agcDefaultMaxGainDB 未设置 MaxGainDB 时允许的最大增益/衰减
+	agcDefaultMaxGainDB = 30
+	// agcDefaultWindow 未设置 Window 时增益跟踪的时间常数
+	agcDefaultWindow = time.Second
+	// agcLevelWindow 电平检测的时间常数，应覆盖几个音节以免增益随音节起伏
+	agcLevelWindow = 200 * time.Millisecond
+	// agcSilenceFloorDB 电平低于该值时冻结增益，避免在静音段把底噪放大
+	agcSilenceFloorDB = -60
+)
+
+// AGCConfig 自动增益控制参数
+type AGCConfig struct {
+	// 目标 RMS 电平（dBFS），例如 -20
+	TargetDB float64 `json:"target_db" yaml:"target_db"`
+	// 允许的最大增益（dB），同时也是最大衰减，0 表示默认 30dB
+	MaxGainDB float64 `json:"max_gain_db" yaml:"max_gain_db"`
+	// 增益跟踪的时间常数，越长越平稳，0 表示默认 1 秒
+	Window time.Duration `json:"window" yaml:"window"`
+}
+
+// IsValid 校验自动增益控制配置
+func (c *AGCConfig) IsValid() error {
+	if c.TargetDB >= 0 {
+		return fmt.Errorf("invalid AGC.TargetDB: should be negative")
+	}
+
+	if c.MaxGainDB < 0 {
+		return fmt.Errorf("invalid AGC.MaxGainDB: should not be negative")
+	}
+
+	if c.Window < 0 {
+		return fmt.Errorf("invalid AGC.Window: should not be negative")
+	}
+
+	return nil
+}
+
+// AGC 缓慢跟踪输入电平的自动增益控制，使远场麦克风等电平起伏较大的输入
+// 保持在稳定的 RMS 附近，从而让检测阈值更稳定。输出会被限制在 [-1, 1]。
+type AGC struct {
+	target    float64 // 目标 RMS 对应的功率
+	maxGain   float64
+	floor     float64 // 冻结增益的功率下限
+	levelCoef float64
+	gainCoef  float64
+	power     float64
+	gain      float64
+}
+
+var (
+	_ Processor = (*AGC)(nil)
+	_ Cloner    = (*AGC)(nil)
+)
+
+// NewAGC 创建自动增益控制
+func NewAGC(sampleRate int, cfg AGCConfig) (*AGC, error) {
+	if sampleRate <= 0 {
+		return nil, fmt.Errorf("invalid sample rate: %d", sampleRate)
+	}
+	if err := cfg.IsValid(); err != nil {
+		return nil, err
+	}
+
+	maxGainDB := cfg.MaxGainDB
+	if maxGainDB == 0 {
+		maxGainDB = agcDefaultMaxGainDB
+	}
+	window := cfg.Window
+	if window == 0 {
+		window = agcDefaultWindow
+	}
+
+	target := DBToAmplitude(cfg.TargetDB)
+	floor := DBToAmplitude(agcSilenceFloorDB)
+
+	return &AGC{
+		target:    target * target,
+		maxGain:   DBToAmplitude(maxGainDB),
+		floor:     floor * floor,
+		levelCoef: smoothingCoef(agcLevelWindow, sampleRate),
+		gainCoef:  smoothingCoef(window, sampleRate),
+		gain:      1,
+	}, nil
+}
+
+// Process 对 src 应用增益并把结果追加到 dst 后返回
+func (a *AGC) Process(dst, src []float32) []float32 {
+	for _, v := range src {
+		x := float64(v)
+		a.power = a.levelCoef*a.power + (1-a.levelCoef)*x*x
+
+		if a.power > a.floor {
+			desired := math.Sqrt(a.target / a.power)
+			desired = math.Max(1/a.maxGain, math.Min(a.maxGain, desired))
+			a.gain = a.gainCoef*a.gain + (1-a.gainCoef)*desired
+		}
+
+		y := x * a.gain
+		if y > 1 {
+			y = 1
+		} else if y < -1 {
+			y = -1
+		}
+		dst = append(dst, float32(y))
+	}
+	return dst
+}
+
+// Gain 返回当前的线性增益
+func (a *AGC) Gain() float64 {
+	return a.gain
+}
+
+// Reset 恢复单位增益并清空电平估计
+func (a *AGC) Reset() {
+	a.power = 0
+	a.gain = 1
+}
+
+// Clone 返回当前增益状态的副本
+func (a *AGC) Clone() Processor {
+	c := *a
+	return &c
+}
@@ -0,0 +1,44 @@
+package audio
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAGC(t *testing.T) {
+	_, err := NewAGC(0, AGCConfig{TargetDB: -20})
+	require.Error(t, err)
+	_, err = NewAGC(16000, AGCConfig{TargetDB: 0})
+	require.Error(t, err)
+	_, err = NewAGC(16000, AGCConfig{TargetDB: -20, MaxGainDB: -1})
+	require.Error(t, err)
+
+	// 小声和大声的输入都应在几秒内收敛到目标电平附近
+	for _, inputDB := range []float64{-40, -6} {
+		agc, err := NewAGC(16000, AGCConfig{TargetDB: -20, Window: 500 * time.Millisecond})
+		require.NoError(t, err)
+
+		// sine 的幅度为 0.5，换算为指定的 RMS 电平
+		in := applyGain(sine(300, 16000, 5*16000), DBToAmplitude(inputDB)*2*math.Sqrt2)
+		out := agc.Process(nil, in)
+
+		tail := out[len(out)-8000:]
+		require.InDelta(t, -20, AmplitudeToDB(RMS(tail)), 1, "input %gdB", inputDB)
+	}
+
+	// 增益受 MaxGainDB 限制
+	agc, err := NewAGC(16000, AGCConfig{TargetDB: -20, MaxGainDB: 10, Window: 200 * time.Millisecond})
+	require.NoError(t, err)
+	in := applyGain(sine(300, 16000, 3*16000), DBToAmplitude(-45)*2*math.Sqrt2)
+	agc.Process(nil, in)
+	require.InDelta(t, DBToAmplitude(10), agc.Gain(), 0.01)
+
+	// 静音段冻结增益，不会把底噪放大
+	agc.Reset()
+	require.Equal(t, 1.0, agc.Gain())
+	agc.Process(nil, make([]float32, 16000))
+	require.Equal(t, 1.0, agc.Gain())
+}
@@ -0,0 +1,49 @@
+package audio
+
+import "fmt"
+
+// Downmix 将交错排列的多声道音频平均混合为单声道
+// 末尾不完整的帧会被丢弃；channels 为 1 时返回输入的副本。
+func Downmix(interleaved []float32, channels int) ([]float32, error) {
+	if channels <= 0 {
+		return nil, fmt.Errorf("invalid channel count: %d", channels)
+	}
+
+	frames := len(interleaved) / channels
+	out := make([]float32, frames)
+	if channels == 1 {
+		copy(out, interleaved)
+		return out, nil
+	}
+
+	scale := 1 / float32(channels)
+	for i := range out {
+		frame := interleaved[i*channels : (i+1)*channels]
+		var sum float32
+		for _, v := range frame {
+			sum += v
+		}
+		out[i] = sum * scale
+	}
+
+	return out, nil
+}
+
+// ExtractChannel 从交错排列的多声道音频中取出第 channel 个声道（从 0 开始）
+// 适用于坐席和客户分别位于左右声道的通话录音。末尾不完整的帧会被丢弃。
+func ExtractChannel(interleaved []float32, channels, channel int) ([]float32, error) {
+	if channels <= 0 {
+		return nil, fmt.Errorf("invalid channel count: %d", channels)
+	}
+	if channel < 0 || channel >= channels {
+		return nil, fmt.Errorf("invalid channel %d: should be in range [0, %d)", channel, channels)
+	}
+
+	frames := len(interleaved) / channels
+	out := make([]float32, frames)
+	for i := range out {
+		out[i] = interleaved[i*channels+channel]
+	}
+
+	return out, nil
+}
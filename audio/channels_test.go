@@ -0,0 +1,28 @@
+package audio
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestChannels(t *testing.T) {
+	stereo := []float32{1, 0, 0.5, -0.5, 0.2, 0.4, 0.9}
+
+	mono, err := Downmix(stereo, 2)
+	require.NoError(t, err)
+	require.InDeltaSlice(t, []float32{0.5, 0, 0.3}, mono, 1e-6)
+
+	left, err := ExtractChannel(stereo, 2, 0)
+	require.NoError(t, err)
+	require.Equal(t, []float32{1, 0.5, 0.2}, left)
+
+	right, err := ExtractChannel(stereo, 2, 1)
+	require.NoError(t, err)
+	require.Equal(t, []float32{0, -0.5, 0.4}, right)
+
+	_, err = Downmix(stereo, 0)
+	require.Error(t, err)
+	_, err = ExtractChannel(stereo, 2, 2)
+	require.Error(t, err)
+}
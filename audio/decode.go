@@ -0,0 +1,84 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrUnsupportedFormat 采样格式不受支持，或该格式不能用于当前操作（例如压缩格式不能流式解码）
+var ErrUnsupportedFormat = errors.New("unsupported format")
+
+// ErrTooLong 解码出的音频超过调用方给出的长度上限
+var ErrTooLong = errors.New("audio too long")
+
+// Decode 读取 r 中的全部数据并解码为单声道 float32 采样
+// 原始格式（PCM16、Float32、G.711）按小端序解析，采样率需由调用方保证为 sampleRate；
+// 压缩格式（Ogg/Opus、MP3、FLAC）会被解码为 sampleRate 采样率的单声道音频。
+func Decode(r io.Reader, format SampleFormat, sampleRate int) ([]float32, error) {
+	switch format {
+	case FormatOggOpus:
+		return DecodeOggOpus(r, sampleRate)
+	case FormatMP3:
+		return DecodeMP3(r, sampleRate)
+	case FormatFLAC:
+		return DecodeFLAC(r, sampleRate)
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return DecodeBytes(data, format, sampleRate)
+}
+
+// DecodeBytes 与 Decode 相同，但直接解码内存中的数据
+func DecodeBytes(data []byte, format SampleFormat, sampleRate int) ([]float32, error) {
+	return DecodeBytesLimit(data, format, sampleRate, 0)
+}
+
+// DecodeBytesLimit 与 DecodeBytes 相同，但输出超过 maxSamples 个采样时停止解码并返回 ErrTooLong，0 表示不限制
+// 用于解码不可信的上传数据：很小的压缩数据可以解码出任意长的音频，上限在解码过程中检查，而不是解码完成之后。
+func DecodeBytesLimit(data []byte, format SampleFormat, sampleRate, maxSamples int) ([]float32, error) {
+	switch format {
+	case FormatPCM16, FormatFloat32, FormatULaw, FormatALaw:
+		if maxSamples > 0 && len(data)/format.BytesPerSample() > maxSamples {
+			return nil, tooLong(maxSamples)
+		}
+		return BytesToFloat32(nil, data, format, binary.LittleEndian)
+	case FormatOggOpus:
+		return decodeOggOpus(bytes.NewReader(data), sampleRate, maxSamples)
+	case FormatMP3:
+		return decodeMP3(bytes.NewReader(data), sampleRate, maxSamples)
+	case FormatFLAC:
+		return decodeFLAC(bytes.NewReader(data), sampleRate, maxSamples)
+	default:
+		return nil, fmt.Errorf("%w: sample format %s", ErrUnsupportedFormat, format)
+	}
+}
+
+// sourceLimit 把输出采样率下的长度上限换算为源采样率下的采样数，0 表示不限制
+func sourceLimit(maxSamples, srcRate, sampleRate int) int {
+	if maxSamples <= 0 {
+		return 0
+	}
+	return int(int64(maxSamples)*int64(srcRate)/int64(sampleRate)) + 1
+}
+
+func tooLong(maxSamples int) error {
+	return fmt.Errorf("%w: more than %d samples", ErrTooLong, maxSamples)
+}
+
+// checkSourceRate 在解码之前检查文件头中的采样率能否重采样到 sampleRate
+func checkSourceRate(srcRate, sampleRate int) error {
+	if srcRate == sampleRate {
+		return nil
+	}
+	if err := CheckResampleRate(srcRate); err != nil {
+		return fmt.Errorf("%w: %w", ErrUnsupportedFormat, err)
+	}
+	return nil
+}
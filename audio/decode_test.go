@@ -0,0 +1,20 @@
+package audio
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecode(t *testing.T) {
+	out, err := Decode(bytes.NewReader([]byte{0x00, 0x40, 0x00, 0xc0}), FormatPCM16, 16000)
+	require.NoError(t, err)
+	require.Equal(t, []float32{0.5, -0.5}, out)
+
+	_, err = Decode(bytes.NewReader([]byte("not an mp3 stream at all")), FormatMP3, 16000)
+	require.Error(t, err)
+
+	_, err = DecodeBytes(nil, SampleFormat(0), 16000)
+	require.ErrorIs(t, err, ErrUnsupportedFormat)
+}
@@ -0,0 +1,142 @@
+package audio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ffmpegStderrLimit 出错时保留的 ffmpeg 标准错误输出的字节数
+const ffmpegStderrLimit = 4096
+
+// FFmpegConfig 调用 ffmpeg 的参数
+type FFmpegConfig struct {
+	// ffmpeg 可执行文件，空时从 PATH 中查找 "ffmpeg"
+	Binary string
+	// 放在 -i 之前的输入参数，例如 []string{"-ss", "30"}
+	InputArgs []string
+	// 放在输出格式之前的其它参数，例如 []string{"-af", "highpass=f=100"}
+	ExtraArgs []string
+}
+
+// FFmpegStream ffmpeg 输出的 16 位小端序单声道 PCM（FormatPCM16）
+// 读到 io.EOF 后需要调用 Close 以等待进程退出并获取解码错误。
+type FFmpegStream struct {
+	cmd    *exec.Cmd
+	stdout io.ReadCloser
+	stderr *tailBuffer
+	once   sync.Once
+	err    error
+}
+
+// OpenFFmpeg 启动 ffmpeg 把 input（文件路径或 ffmpeg 支持的 URL）解码为 sampleRate 采样率的单声道 s16le
+// 适用于 MP4、M4A、AAC、WebM 等本包不能直接解码的容器和编码。ctx 取消时进程会被终止。
+func OpenFFmpeg(ctx context.Context, input string, sampleRate int, cfg FFmpegConfig) (*FFmpegStream, error) {
+	if sampleRate <= 0 {
+		return nil, fmt.Errorf("invalid sample rate: %d", sampleRate)
+	}
+
+	binary := cfg.Binary
+	if binary == "" {
+		binary = "ffmpeg"
+	}
+
+	args := []string{"-nostdin", "-hide_banner", "-loglevel", "error"}
+	args = append(args, cfg.InputArgs...)
+	args = append(args, "-i", input, "-vn")
+	args = append(args, cfg.ExtraArgs...)
+	args = append(args, "-f", "s16le", "-acodec", "pcm_s16le", "-ac", "1", "-ar", strconv.Itoa(sampleRate), "pipe:1")
+
+	cmd := exec.CommandContext(ctx, binary, args...)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	stderr := &tailBuffer{limit: ffmpegStderrLimit}
+	cmd.Stderr = stderr
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start ffmpeg: %w", err)
+	}
+
+	return &FFmpegStream{cmd: cmd, stdout: stdout, stderr: stderr}, nil
+}
+
+// Read 读取 PCM 字节
+func (s *FFmpegStream) Read(p []byte) (int, error) {
+	return s.stdout.Read(p)
+}
+
+// Close 等待 ffmpeg 退出，进程以错误退出时返回其标准错误输出
+// 未读完输出就关闭时进程会因管道关闭而退出，此时不视为错误。重复调用返回相同结果。
+func (s *FFmpegStream) Close() error {
+	s.once.Do(func() {
+		// 先关闭管道，使仍在写入的 ffmpeg 退出
+		drained := false
+		if n, err := s.stdout.Read(make([]byte, 1)); n == 0 && errors.Is(err, io.EOF) {
+			drained = true
+		}
+		s.stdout.Close()
+
+		err := s.cmd.Wait()
+		var exitErr *exec.ExitError
+		switch {
+		case err == nil:
+		case errors.As(err, &exitErr) && !drained:
+			// 提前关闭导致的 SIGPIPE 或写入失败
+		case errors.As(err, &exitErr):
+			if msg := strings.TrimSpace(s.stderr.String()); msg != "" {
+				err = fmt.Errorf("ffmpeg failed: %w: %s", err, msg)
+			} else {
+				err = fmt.Errorf("ffmpeg failed: %w", err)
+			}
+			s.err = err
+		default:
+			s.err = err
+		}
+	})
+	return s.err
+}
+
+// DecodeFFmpeg 通过 ffmpeg 把 input 完整解码为 sampleRate 采样率的单声道音频
+func DecodeFFmpeg(ctx context.Context, input string, sampleRate int, cfg FFmpegConfig) ([]float32, error) {
+	s, err := OpenFFmpeg(ctx, input, sampleRate, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	pcm, err := Decode(s, FormatPCM16, sampleRate)
+	if closeErr := s.Close(); closeErr != nil {
+		return nil, closeErr
+	}
+	return pcm, err
+}
+
+// tailBuffer 只保留最后 limit 字节的写入内容
+type tailBuffer struct {
+	mu    sync.Mutex
+	limit int
+	buf   []byte
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.buf = append(b.buf, p...)
+	if over := len(b.buf) - b.limit; over > 0 {
+		b.buf = append(b.buf[:0], b.buf[over:]...)
+	}
+	return len(p), nil
+}
+
+func (b *tailBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return string(b.buf)
+}
@@ -0,0 +1,72 @@
+package audio
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeFFmpeg 写一个模拟 ffmpeg 的脚本，把参数记录到 args 文件后执行 body
+func fakeFFmpeg(t *testing.T, body string) (binary, argsFile string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	argsFile = filepath.Join(dir, "args")
+	binary = filepath.Join(dir, "ffmpeg")
+	script := "#!/bin/sh\necho \"$@\" > " + argsFile + "\n" + body + "\n"
+	require.NoError(t, os.WriteFile(binary, []byte(script), 0o755))
+	return binary, argsFile
+}
+
+func TestFFmpeg(t *testing.T) {
+	pcm := Float32ToInt16(nil, sine(440, 8000, 800))
+	raw := make([]byte, 2*len(pcm))
+	for i, v := range pcm {
+		binary.LittleEndian.PutUint16(raw[2*i:], uint16(v))
+	}
+	rawFile := filepath.Join(t.TempDir(), "raw")
+	require.NoError(t, os.WriteFile(rawFile, raw, 0o644))
+
+	t.Run("decode", func(t *testing.T) {
+		bin, argsFile := fakeFFmpeg(t, "cat "+rawFile)
+		out, err := DecodeFFmpeg(context.Background(), "talk.m4a", 8000, FFmpegConfig{
+			Binary:    bin,
+			InputArgs: []string{"-ss", "1"},
+			ExtraArgs: []string{"-af", "volume=2"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, Int16ToFloat32(nil, pcm), out)
+
+		args, err := os.ReadFile(argsFile)
+		require.NoError(t, err)
+		require.Equal(t, "-nostdin -hide_banner -loglevel error -ss 1 -i talk.m4a -vn -af volume=2 -f s16le -acodec pcm_s16le -ac 1 -ar 8000 pipe:1",
+			strings.TrimSpace(string(args)))
+	})
+
+	t.Run("failure reports stderr", func(t *testing.T) {
+		bin, _ := fakeFFmpeg(t, "echo 'talk.m4a: No such file or directory' >&2\nexit 1")
+		_, err := DecodeFFmpeg(context.Background(), "talk.m4a", 8000, FFmpegConfig{Binary: bin})
+		require.ErrorContains(t, err, "No such file or directory")
+	})
+
+	t.Run("missing binary", func(t *testing.T) {
+		_, err := OpenFFmpeg(context.Background(), "talk.m4a", 8000, FFmpegConfig{Binary: filepath.Join(t.TempDir(), "ffmpeg")})
+		require.Error(t, err)
+	})
+
+	t.Run("early close", func(t *testing.T) {
+		bin, _ := fakeFFmpeg(t, "exec yes")
+		s, err := OpenFFmpeg(context.Background(), "talk.m4a", 8000, FFmpegConfig{Binary: bin})
+		require.NoError(t, err)
+		_, err = io.ReadFull(s, make([]byte, 100))
+		require.NoError(t, err)
+		require.NoError(t, s.Close())
+		require.NoError(t, s.Close())
+	})
+}
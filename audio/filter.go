@@ -0,0 +1,82 @@
+package audio
+
+import (
+	"fmt"
+	"math"
+)
+
+// butterworthQ 使二阶滤波器通带最平坦的品质因数
+const butterworthQ = 1 / math.Sqrt2
+
+// Processor 是可串联在检测器之前的有状态音频处理阶段
+type Processor interface {
+	// Process 处理 src 并把结果追加到 dst 后返回，滤波状态在调用之间保留
+	Process(dst, src []float32) []float32
+	// Reset 清空内部状态，用于开始处理新的音频流
+	Reset()
+}
+
+// Cloner 可以复制自身当前状态的 Processor，例如 speech.DetectorContext.Clone 需要复制预处理阶段
+type Cloner interface {
+	// Clone 返回状态相同、此后互不影响的副本
+	Clone() Processor
+}
+
+// Biquad 二阶 IIR 滤波器（直接 II 型转置结构）
+type Biquad struct {
+	b0, b1, b2 float64
+	a1, a2     float64
+	z1, z2     float64
+}
+
+var (
+	_ Processor = (*Biquad)(nil)
+	_ Cloner    = (*Biquad)(nil)
+)
+
+// NewHighPass 按 RBJ Audio EQ Cookbook 创建二阶巴特沃斯高通滤波器
+// 常用截止频率为 70–100Hz，可以去除直流偏置和廉价麦克风的低频隆隆声而不影响人声。
+func NewHighPass(sampleRate int, cutoffHz float64) (*Biquad, error) {
+	if sampleRate <= 0 {
+		return nil, fmt.Errorf("invalid sample rate: %d", sampleRate)
+	}
+	if cutoffHz <= 0 || cutoffHz >= float64(sampleRate)/2 {
+		return nil, fmt.Errorf("invalid cutoff %gHz: should be in range (0, %d)", cutoffHz, sampleRate/2)
+	}
+
+	w0 := 2 * math.Pi * cutoffHz / float64(sampleRate)
+	alpha := math.Sin(w0) / (2 * butterworthQ)
+	cosw0 := math.Cos(w0)
+	a0 := 1 + alpha
+
+	return &Biquad{
+		b0: (1 + cosw0) / 2 / a0,
+		b1: -(1 + cosw0) / a0,
+		b2: (1 + cosw0) / 2 / a0,
+		a1: -2 * cosw0 / a0,
+		a2: (1 - alpha) / a0,
+	}, nil
+}
+
+// Process 对 src 滤波并把结果追加到 dst 后返回
+func (f *Biquad) Process(dst, src []float32) []float32 {
+	for _, v := range src {
+		x := float64(v)
+		y := f.b0*x + f.z1
+		f.z1 = f.b1*x - f.a1*y + f.z2
+		f.z2 = f.b2*x - f.a2*y
+		dst = append(dst, float32(y))
+	}
+	return dst
+}
+
+// Reset 清空滤波器状态
+func (f *Biquad) Reset() {
+	f.z1, f.z2 = 0, 0
+}
+
+// Clone 返回当前滤波状态的副本
+func (f *Biquad) Clone() Processor {
+	c := *f
+	return &c
+}
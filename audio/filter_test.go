@@ -0,0 +1,53 @@
+package audio
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHighPass(t *testing.T) {
+	_, err := NewHighPass(16000, 9000)
+	require.Error(t, err)
+
+	f, err := NewHighPass(16000, 80)
+	require.NoError(t, err)
+
+	// 直流偏置被完全去除
+	dc := make([]float32, 16000)
+	for i := range dc {
+		dc[i] = 0.3
+	}
+	out := f.Process(nil, dc)
+	require.InDelta(t, 0, RMS(out[8000:]), 1e-4)
+
+	// 1kHz 人声频段基本不受影响
+	f.Reset()
+	tone := sine(1000, 16000, 16000)
+	out = f.Process(nil, tone)
+	require.InDelta(t, RMS(tone[8000:]), RMS(out[8000:]), 1e-3)
+
+	// 30Hz 低频隆隆声被明显衰减
+	f.Reset()
+	rumble := sine(30, 16000, 32000)
+	out = f.Process(nil, rumble)
+	require.Less(t, RMS(out[16000:]), RMS(rumble[16000:])/5)
+}
+
+func TestClone(t *testing.T) {
+	f, err := NewHighPass(16000, 80)
+	require.NoError(t, err)
+	r, err := NewResampler(48000, 16000)
+	require.NoError(t, err)
+	tone := sine(440, 48000, 4800)
+	f.Process(nil, tone[:1000])
+	r.Process(nil, tone[:1000])
+
+	// 副本从相同的状态继续，输出与原对象一致且互不影响
+	fc := f.Clone()
+	rc := r.Clone()
+	require.Equal(t, f.Process(nil, tone[1000:]), fc.Process(nil, tone[1000:]))
+	require.Equal(t, r.Process(nil, tone[1000:]), rc.Process(nil, tone[1000:]))
+	fc.Reset()
+	require.NotEqual(t, f.Process(nil, tone[:100]), fc.Process(nil, tone[:100]))
+}
@@ -0,0 +1,674 @@
+package audio
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// ErrInvalidFLAC 输入不是合法的 FLAC 数据
+var ErrInvalidFLAC = errors.New("invalid flac data")
+
+// FLACInfo 描述 FLAC 流的格式信息（来自 STREAMINFO 元数据块）
+type FLACInfo struct {
+	// 采样率，单位 Hz
+	SampleRate int
+	// 声道数，多声道数据按交错方式存储
+	Channels int
+	// 每个采样点的位数
+	BitsPerSample int
+	// 每个声道的总采样数，未知时为 0
+	TotalSamples int64
+}
+
+// flacCRC8Table FLAC 帧头使用的 CRC-8（多项式 0x07）
+var flacCRC8Table = func() [256]uint8 {
+	var t [256]uint8
+	for i := range t {
+		r := uint8(i)
+		for j := 0; j < 8; j++ {
+			if r&0x80 != 0 {
+				r = r<<1 ^ 0x07
+			} else {
+				r <<= 1
+			}
+		}
+		t[i] = r
+	}
+	return t
+}()
+
+// flacCRC16Table FLAC 帧尾使用的 CRC-16（多项式 0x8005）
+var flacCRC16Table = func() [256]uint16 {
+	var t [256]uint16
+	for i := range t {
+		r := uint16(i) << 8
+		for j := 0; j < 8; j++ {
+			if r&0x8000 != 0 {
+				r = r<<1 ^ 0x8005
+			} else {
+				r <<= 1
+			}
+		}
+		t[i] = r
+	}
+	return t
+}()
+
+// ReadFLACFile 读取 FLAC 文件，参见 ReadFLAC
+func ReadFLACFile(path string) ([]float32, FLACInfo, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, FLACInfo{}, err
+	}
+	defer f.Close()
+
+	return ReadFLAC(f)
+}
+
+// ReadFLAC 解码 FLAC 数据并返回归一化到 [-1, 1] 的 float32 采样点
+// 支持 4~32 位、1~8 声道的标准 FLAC 流，多声道数据保持交错排列；
+// 文件开头的 ID3v2 标签会被跳过，每一帧都会校验 CRC。
+func ReadFLAC(r io.Reader) ([]float32, FLACInfo, error) {
+	return readFLAC(r, nil)
+}
+
+// readFLAC 实现 ReadFLAC，check 在读取流信息后、解码帧之前调用，返回每声道采样数的上限，0 表示不限制
+func readFLAC(r io.Reader, check func(FLACInfo) (int, error)) ([]float32, FLACInfo, error) {
+	br := &flacBitReader{r: bufio.NewReader(r)}
+
+	info, err := br.readStreamInfo()
+	if err != nil {
+		return nil, FLACInfo{}, err
+	}
+	limit := 0
+	if check != nil {
+		if limit, err = check(info); err != nil {
+			return nil, FLACInfo{}, err
+		}
+	}
+
+	// 总采样数来自文件头，预分配时设置上限以免被损坏的数据撑爆内存
+	out := make([]float32, 0, min(info.TotalSamples, 1<<24)*int64(info.Channels))
+	scale := 1 / float32(int64(1)<<(info.BitsPerSample-1))
+	var chans [][]int64
+	for {
+		chans, err = br.readFrame(info, chans)
+		if errors.Is(err, io.EOF) {
+			return out, info, nil
+		}
+		if err != nil {
+			return nil, FLACInfo{}, err
+		}
+
+		if limit > 0 && len(out)/info.Channels+len(chans[0]) > limit {
+			return nil, FLACInfo{}, errFLACTooLong
+		}
+		for i := range chans[0] {
+			for ch := range chans {
+				out = append(out, float32(chans[ch][i])*scale)
+			}
+		}
+	}
+}
+
+// errFLACTooLong 由 readFLAC 在超过 check 返回的上限时返回，decodeFLAC 把它换成带输出上限的 ErrTooLong
+var errFLACTooLong = errors.New("flac stream exceeds the sample limit")
+
+// DecodeFLAC 解码 FLAC 数据为采样率为 sampleRate 的单声道音频
+func DecodeFLAC(r io.Reader, sampleRate int) ([]float32, error) {
+	return decodeFLAC(r, sampleRate, 0)
+}
+
+// decodeFLAC 实现 DecodeFLAC，输出超过 maxSamples 个采样时返回 ErrTooLong，0 表示不限制
+func decodeFLAC(r io.Reader, sampleRate, maxSamples int) ([]float32, error) {
+	samples, info, err := readFLAC(r, func(info FLACInfo) (int, error) {
+		if err := checkSourceRate(info.SampleRate, sampleRate); err != nil {
+			return 0, err
+		}
+		return sourceLimit(maxSamples, info.SampleRate, sampleRate), nil
+	})
+	if errors.Is(err, errFLACTooLong) {
+		return nil, tooLong(maxSamples)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	mono, err := Downmix(samples, info.Channels)
+	if err != nil {
+		return nil, err
+	}
+
+	return Resample(mono, info.SampleRate, sampleRate)
+}
+
+// flacBitReader 按位读取 FLAC 数据，并对读过的字节累计帧 CRC
+type flacBitReader struct {
+	r     *bufio.Reader
+	cache uint64
+	n     uint
+	crc8  uint8
+	crc16 uint16
+}
+
+func (b *flacBitReader) readByte() (byte, error) {
+	c, err := b.r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	b.crc8 = flacCRC8Table[b.crc8^c]
+	b.crc16 = b.crc16<<8 ^ flacCRC16Table[byte(b.crc16>>8)^c]
+	return c, nil
+}
+
+// bits 读取 n（≤ 57）位无符号整数
+func (b *flacBitReader) bits(n uint) (uint64, error) {
+	for b.n < n {
+		c, err := b.readByte()
+		if err != nil {
+			return 0, unexpectedEOF(err)
+		}
+		b.cache = b.cache<<8 | uint64(c)
+		b.n += 8
+	}
+	b.n -= n
+	v := b.cache >> b.n & (1<<n - 1)
+	return v, nil
+}
+
+// signed 读取 n 位补码整数
+func (b *flacBitReader) signed(n uint) (int64, error) {
+	if n == 0 {
+		return 0, nil
+	}
+	v, err := b.bits(n)
+	if err != nil {
+		return 0, err
+	}
+	return int64(v<<(64-n)) >> (64 - n), nil
+}
+
+// unary 读取一元编码（连续 0 的个数，以 1 结束）
+func (b *flacBitReader) unary() (uint64, error) {
+	var q uint64
+	for {
+		bit, err := b.bits(1)
+		if err != nil {
+			return 0, err
+		}
+		if bit == 1 {
+			return q, nil
+		}
+		q++
+	}
+}
+
+// align 丢弃当前字节中剩余的位
+func (b *flacBitReader) align() {
+	b.n -= b.n % 8
+}
+
+func unexpectedEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return fmt.Errorf("%w: %w", ErrInvalidFLAC, io.ErrUnexpectedEOF)
+	}
+	return err
+}
+
+// readStreamInfo 读取流标记和全部元数据块，返回 STREAMINFO
+func (b *flacBitReader) readStreamInfo() (FLACInfo, error) {
+	var magic [4]byte
+	if _, err := io.ReadFull(b.r, magic[:]); err != nil {
+		return FLACInfo{}, fmt.Errorf("%w: failed to read stream marker: %w", ErrInvalidFLAC, err)
+	}
+
+	// 跳过 ID3v2 标签，其长度为 4 个 7 位的 syncsafe 字节
+	if string(magic[:3]) == "ID3" {
+		var rest [6]byte
+		if _, err := io.ReadFull(b.r, rest[:]); err != nil {
+			return FLACInfo{}, fmt.Errorf("%w: truncated id3 tag: %w", ErrInvalidFLAC, err)
+		}
+		size := int64(rest[2])<<21 | int64(rest[3])<<14 | int64(rest[4])<<7 | int64(rest[5])
+		if _, err := io.CopyN(io.Discard, b.r, size); err != nil {
+			return FLACInfo{}, fmt.Errorf("%w: truncated id3 tag: %w", ErrInvalidFLAC, err)
+		}
+		if _, err := io.ReadFull(b.r, magic[:]); err != nil {
+			return FLACInfo{}, fmt.Errorf("%w: failed to read stream marker: %w", ErrInvalidFLAC, err)
+		}
+	}
+
+	if string(magic[:]) != "fLaC" {
+		return FLACInfo{}, fmt.Errorf("%w: missing fLaC marker", ErrInvalidFLAC)
+	}
+
+	var (
+		info    FLACInfo
+		hasInfo bool
+	)
+	for last := false; !last; {
+		var header [4]byte
+		if _, err := io.ReadFull(b.r, header[:]); err != nil {
+			return FLACInfo{}, fmt.Errorf("%w: failed to read metadata block: %w", ErrInvalidFLAC, err)
+		}
+		last = header[0]&0x80 != 0
+		kind := header[0] & 0x7f
+		size := int(header[1])<<16 | int(header[2])<<8 | int(header[3])
+
+		if kind != 0 {
+			if _, err := io.CopyN(io.Discard, b.r, int64(size)); err != nil {
+				return FLACInfo{}, fmt.Errorf("%w: truncated metadata block: %w", ErrInvalidFLAC, err)
+			}
+			continue
+		}
+
+		if size < 34 {
+			return FLACInfo{}, fmt.Errorf("%w: streaminfo block too small", ErrInvalidFLAC)
+		}
+		block := make([]byte, size)
+		if _, err := io.ReadFull(b.r, block); err != nil {
+			return FLACInfo{}, fmt.Errorf("%w: truncated streaminfo block: %w", ErrInvalidFLAC, err)
+		}
+		// 采样率 20 位、声道数 3 位、位深 5 位、总采样数 36 位
+		packed := binary.BigEndian.Uint64(block[10:18])
+		info.SampleRate = int(packed >> 44)
+		info.Channels = int(packed>>41&0x7) + 1
+		info.BitsPerSample = int(packed>>36&0x1f) + 1
+		info.TotalSamples = int64(packed & (1<<36 - 1))
+		hasInfo = true
+	}
+
+	if !hasInfo {
+		return FLACInfo{}, fmt.Errorf("%w: missing streaminfo block", ErrInvalidFLAC)
+	}
+	if info.SampleRate == 0 || info.BitsPerSample < 4 {
+		return FLACInfo{}, fmt.Errorf("%w: unsupported stream format %d Hz, %d bits", ErrInvalidFLAC, info.SampleRate, info.BitsPerSample)
+	}
+
+	return info, nil
+}
+
+// FLAC 帧的声道编码方式
+const (
+	flacLeftSide  = 8
+	flacSideRight = 9
+	flacMidSide   = 10
+)
+
+// readFrame 解码一帧音频，复用 chans 中的缓冲区，流结束时返回 io.EOF
+func (b *flacBitReader) readFrame(info FLACInfo, chans [][]int64) ([][]int64, error) {
+	b.cache, b.n = 0, 0
+	b.crc8, b.crc16 = 0, 0
+
+	first, err := b.readByte()
+	if err != nil {
+		return chans, err
+	}
+	second, err := b.readByte()
+	if err != nil {
+		return chans, unexpectedEOF(err)
+	}
+	if first != 0xff || second&0xfe != 0xf8 {
+		return chans, fmt.Errorf("%w: lost frame sync", ErrInvalidFLAC)
+	}
+
+	header, err := b.bits(16)
+	if err != nil {
+		return chans, err
+	}
+	blockCode := header >> 12
+	rateCode := header >> 8 & 0xf
+	assignment := int(header >> 4 & 0xf)
+	sizeCode := header >> 1 & 0x7
+
+	// 帧号或采样号使用类 UTF-8 的变长编码，这里只需跳过
+	lead, err := b.bits(8)
+	if err != nil {
+		return chans, err
+	}
+	for mask := uint64(0x80); lead&mask != 0 && mask > 1; mask >>= 1 {
+		if mask != 0x80 {
+			if _, err := b.bits(8); err != nil {
+				return chans, err
+			}
+		}
+	}
+
+	var blockSize int
+	switch {
+	case blockCode == 1:
+		blockSize = 192
+	case blockCode >= 2 && blockCode <= 5:
+		blockSize = 576 << (blockCode - 2)
+	case blockCode == 6:
+		v, err := b.bits(8)
+		if err != nil {
+			return chans, err
+		}
+		blockSize = int(v) + 1
+	case blockCode == 7:
+		v, err := b.bits(16)
+		if err != nil {
+			return chans, err
+		}
+		blockSize = int(v) + 1
+	case blockCode >= 8:
+		blockSize = 256 << (blockCode - 8)
+	default:
+		return chans, fmt.Errorf("%w: reserved block size", ErrInvalidFLAC)
+	}
+
+	// 帧头中的采样率只用于校验，解码始终以 STREAMINFO 为准
+	switch rateCode {
+	case 12:
+		_, err = b.bits(8)
+	case 13, 14:
+		_, err = b.bits(16)
+	case 15:
+		err = fmt.Errorf("%w: invalid sample rate code", ErrInvalidFLAC)
+	}
+	if err != nil {
+		return chans, err
+	}
+
+	bps := info.BitsPerSample
+	switch sizeCode {
+	case 0:
+	case 1:
+		bps = 8
+	case 2:
+		bps = 12
+	case 4:
+		bps = 16
+	case 5:
+		bps = 20
+	case 6:
+		bps = 24
+	case 7:
+		bps = 32
+	default:
+		return chans, fmt.Errorf("%w: reserved sample size", ErrInvalidFLAC)
+	}
+	if bps != info.BitsPerSample {
+		return chans, fmt.Errorf("%w: frame sample size %d differs from streaminfo %d", ErrInvalidFLAC, bps, info.BitsPerSample)
+	}
+
+	channels := assignment + 1
+	if assignment >= flacLeftSide {
+		if assignment > flacMidSide {
+			return chans, fmt.Errorf("%w: reserved channel assignment", ErrInvalidFLAC)
+		}
+		channels = 2
+	}
+	if channels != info.Channels {
+		return chans, fmt.Errorf("%w: frame has %d channels, streaminfo %d", ErrInvalidFLAC, channels, info.Channels)
+	}
+
+	crc := b.crc8
+	sum, err := b.bits(8)
+	if err != nil {
+		return chans, err
+	}
+	if uint8(sum) != crc {
+		return chans, fmt.Errorf("%w: frame header crc mismatch", ErrInvalidFLAC)
+	}
+
+	if len(chans) != channels {
+		chans = make([][]int64, channels)
+	}
+	for ch := range chans {
+		chans[ch] = grow(chans[ch], blockSize)
+
+		// 差分声道（side）多占 1 位
+		sampleBits := uint(bps)
+		if (assignment == flacLeftSide || assignment == flacMidSide) && ch == 1 ||
+			assignment == flacSideRight && ch == 0 {
+			sampleBits++
+		}
+		if err := b.readSubframe(chans[ch], sampleBits); err != nil {
+			return chans, err
+		}
+	}
+
+	b.align()
+	crc16 := b.crc16
+	sum, err = b.bits(16)
+	if err != nil {
+		return chans, err
+	}
+	if uint16(sum) != crc16 {
+		return chans, fmt.Errorf("%w: frame crc mismatch", ErrInvalidFLAC)
+	}
+
+	// 还原立体声去相关
+	switch assignment {
+	case flacLeftSide:
+		left, side := chans[0], chans[1]
+		for i := range side {
+			side[i] = left[i] - side[i]
+		}
+	case flacSideRight:
+		side, right := chans[0], chans[1]
+		for i := range side {
+			side[i] += right[i]
+		}
+	case flacMidSide:
+		mid, side := chans[0], chans[1]
+		for i := range mid {
+			m := mid[i]<<1 | side[i]&1
+			mid[i] = (m + side[i]) >> 1
+			side[i] = (m - side[i]) >> 1
+		}
+	}
+
+	return chans, nil
+}
+
+// readSubframe 解码一个声道的子帧到 out
+func (b *flacBitReader) readSubframe(out []int64, bps uint) error {
+	header, err := b.bits(8)
+	if err != nil {
+		return err
+	}
+	if header&0x80 != 0 {
+		return fmt.Errorf("%w: invalid subframe padding", ErrInvalidFLAC)
+	}
+	kind := header >> 1 & 0x3f
+
+	// 低位全零的采样被省略（wasted bits），解码后再左移还原
+	var wasted uint
+	if header&1 != 0 {
+		k, err := b.unary()
+		if err != nil {
+			return err
+		}
+		wasted = uint(k) + 1
+		if wasted >= bps {
+			return fmt.Errorf("%w: invalid wasted bits", ErrInvalidFLAC)
+		}
+		bps -= wasted
+	}
+
+	switch {
+	case kind == 0:
+		v, err := b.signed(bps)
+		if err != nil {
+			return err
+		}
+		for i := range out {
+			out[i] = v
+		}
+	case kind == 1:
+		for i := range out {
+			if out[i], err = b.signed(bps); err != nil {
+				return err
+			}
+		}
+	case kind >= 8 && kind <= 12:
+		if err := b.readFixed(out, bps, int(kind-8)); err != nil {
+			return err
+		}
+	case kind >= 32:
+		if err := b.readLPC(out, bps, int(kind-31)); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("%w: reserved subframe type %d", ErrInvalidFLAC, kind)
+	}
+
+	if wasted > 0 {
+		for i := range out {
+			out[i] <<= wasted
+		}
+	}
+
+	return nil
+}
+
+// flacFixedCoefs 固定预测器各阶的系数
+var flacFixedCoefs = [5][]int64{
+	{},
+	{1},
+	{2, -1},
+	{3, -3, 1},
+	{4, -6, 4, -1},
+}
+
+func (b *flacBitReader) readFixed(out []int64, bps uint, order int) error {
+	if order > len(out) {
+		return fmt.Errorf("%w: predictor order exceeds block size", ErrInvalidFLAC)
+	}
+	for i := 0; i < order; i++ {
+		v, err := b.signed(bps)
+		if err != nil {
+			return err
+		}
+		out[i] = v
+	}
+	if err := b.readResidual(out, order); err != nil {
+		return err
+	}
+
+	predict(out, flacFixedCoefs[order], 0)
+	return nil
+}
+
+func (b *flacBitReader) readLPC(out []int64, bps uint, order int) error {
+	if order > len(out) {
+		return fmt.Errorf("%w: predictor order exceeds block size", ErrInvalidFLAC)
+	}
+	for i := 0; i < order; i++ {
+		v, err := b.signed(bps)
+		if err != nil {
+			return err
+		}
+		out[i] = v
+	}
+
+	precision, err := b.bits(4)
+	if err != nil {
+		return err
+	}
+	if precision == 0xf {
+		return fmt.Errorf("%w: invalid lpc precision", ErrInvalidFLAC)
+	}
+	shift, err := b.signed(5)
+	if err != nil {
+		return err
+	}
+	if shift < 0 {
+		return fmt.Errorf("%w: negative lpc shift", ErrInvalidFLAC)
+	}
+	coefs := make([]int64, order)
+	for i := range coefs {
+		if coefs[i], err = b.signed(uint(precision) + 1); err != nil {
+			return err
+		}
+	}
+
+	if err := b.readResidual(out, order); err != nil {
+		return err
+	}
+
+	predict(out, coefs, uint(shift))
+	return nil
+}
+
+// predict 在 out[len(coefs):] 中保存的残差上叠加线性预测值
+func predict(out []int64, coefs []int64, shift uint) {
+	order := len(coefs)
+	for i := order; i < len(out); i++ {
+		var sum int64
+		for j, c := range coefs {
+			sum += c * out[i-j-1]
+		}
+		out[i] += sum >> shift
+	}
+}
+
+// readResidual 读取 Rice 编码的残差到 out[order:]
+func (b *flacBitReader) readResidual(out []int64, order int) error {
+	method, err := b.bits(2)
+	if err != nil {
+		return err
+	}
+	paramBits, escape := uint(4), uint64(0xf)
+	switch method {
+	case 0:
+	case 1:
+		paramBits, escape = 5, 0x1f
+	default:
+		return fmt.Errorf("%w: reserved residual coding method", ErrInvalidFLAC)
+	}
+
+	partitionOrder, err := b.bits(4)
+	if err != nil {
+		return err
+	}
+	partitions := 1 << partitionOrder
+	if len(out)%partitions != 0 || len(out)>>partitionOrder < order {
+		return fmt.Errorf("%w: invalid residual partition order", ErrInvalidFLAC)
+	}
+
+	i := order
+	for p := 0; p < partitions; p++ {
+		end := (p + 1) * (len(out) >> partitionOrder)
+
+		param, err := b.bits(paramBits)
+		if err != nil {
+			return err
+		}
+
+		// 转义分区直接以定长位数存储残差
+		if param == escape {
+			n, err := b.bits(5)
+			if err != nil {
+				return err
+			}
+			for ; i < end; i++ {
+				if out[i], err = b.signed(uint(n)); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		for ; i < end; i++ {
+			q, err := b.unary()
+			if err != nil {
+				return err
+			}
+			r, err := b.bits(uint(param))
+			if err != nil {
+				return err
+			}
+			v := q<<param | r
+			out[i] = int64(v>>1) ^ -int64(v&1)
+		}
+	}
+
+	return nil
+}
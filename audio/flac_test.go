@@ -0,0 +1,276 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// flacWriter 按位写入 FLAC 数据，用于构造测试流
+type flacWriter struct {
+	buf  []byte
+	bits uint
+}
+
+func (w *flacWriter) write(v uint64, n uint) {
+	for i := n; i > 0; i-- {
+		if w.bits%8 == 0 {
+			w.buf = append(w.buf, 0)
+		}
+		w.buf[len(w.buf)-1] |= byte(v>>(i-1)&1) << (7 - w.bits%8)
+		w.bits++
+	}
+}
+
+func (w *flacWriter) signed(v int64, n uint) {
+	w.write(uint64(v)&(1<<n-1), n)
+}
+
+func (w *flacWriter) rice(v int64, k uint) {
+	zig := uint64(v<<1 ^ v>>63)
+	for q := zig >> k; q > 0; q-- {
+		w.write(0, 1)
+	}
+	w.write(1, 1)
+	w.write(zig&(1<<k-1), k)
+}
+
+// residual 以单分区、固定 Rice 参数写入残差
+func (w *flacWriter) residual(res []int64, k uint) {
+	w.write(0, 2)
+	w.write(0, 4)
+	w.write(uint64(k), 4)
+	for _, v := range res {
+		w.rice(v, k)
+	}
+}
+
+func crc8(data []byte) uint8 {
+	var crc uint8
+	for _, c := range data {
+		crc = flacCRC8Table[crc^c]
+	}
+	return crc
+}
+
+func crc16(data []byte) uint16 {
+	var crc uint16
+	for _, c := range data {
+		crc = crc<<8 ^ flacCRC16Table[byte(crc>>8)^c]
+	}
+	return crc
+}
+
+// buildFLACFrame 构造一帧 16 位音频，subframes 负责写入各声道子帧
+func buildFLACFrame(assignment, blockSize int, subframes func(w *flacWriter)) []byte {
+	w := &flacWriter{}
+	w.write(0xfff8, 16)
+	w.write(7, 4) // 块大小以 16 位存储在帧头末尾
+	w.write(0, 4) // 采样率取自 STREAMINFO
+	w.write(uint64(assignment), 4)
+	w.write(4, 3) // 16 位
+	w.write(0, 1)
+	w.write(0, 8) // 帧号 0
+	w.write(uint64(blockSize-1), 16)
+	w.write(uint64(crc8(w.buf)), 8)
+
+	subframes(w)
+
+	w.bits += (8 - w.bits%8) % 8
+	w.write(uint64(crc16(w.buf)), 16)
+	return w.buf
+}
+
+func buildFLAC(rate, channels, totalSamples int, frames ...[]byte) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("fLaC")
+	buf.Write([]byte{0x80, 0, 0, 34})
+
+	info := make([]byte, 34)
+	binary.BigEndian.PutUint16(info[0:2], 16)
+	binary.BigEndian.PutUint16(info[2:4], 4096)
+	packed := uint64(rate)<<44 | uint64(channels-1)<<41 | uint64(15)<<36 | uint64(totalSamples)
+	binary.BigEndian.PutUint64(info[10:18], packed)
+	buf.Write(info)
+
+	for _, f := range frames {
+		buf.Write(f)
+	}
+	return buf.Bytes()
+}
+
+func testTone(n int, freq float64, amp float64) []int64 {
+	out := make([]int64, n)
+	for i := range out {
+		out[i] = int64(amp * math.Sin(2*math.Pi*freq*float64(i)/16000))
+	}
+	return out
+}
+
+func TestReadFLACSubframes(t *testing.T) {
+	const blockSize = 64
+	tone := testTone(4*blockSize, 440, 12000)
+	blocks := [][]int64{
+		tone[0*blockSize : 1*blockSize],
+		tone[1*blockSize : 2*blockSize],
+		tone[2*blockSize : 3*blockSize],
+		tone[3*blockSize : 4*blockSize],
+	}
+
+	verbatim := buildFLACFrame(0, blockSize, func(w *flacWriter) {
+		w.write(1<<1, 8)
+		for _, v := range blocks[0] {
+			w.signed(v, 16)
+		}
+	})
+
+	fixed := buildFLACFrame(0, blockSize, func(w *flacWriter) {
+		x := blocks[1]
+		w.write((8+2)<<1, 8)
+		w.signed(x[0], 16)
+		w.signed(x[1], 16)
+		res := make([]int64, 0, blockSize)
+		for i := 2; i < len(x); i++ {
+			res = append(res, x[i]-2*x[i-1]+x[i-2])
+		}
+		w.residual(res, 6)
+	})
+
+	// 二阶 LPC：系数 (3, -1) / 2，精度 4 位，移位 1
+	lpc := buildFLACFrame(0, blockSize, func(w *flacWriter) {
+		x := blocks[2]
+		w.write((32+1)<<1, 8)
+		w.signed(x[0], 16)
+		w.signed(x[1], 16)
+		w.write(3, 4)
+		w.signed(1, 5)
+		w.signed(3, 4)
+		w.signed(-1, 4)
+		res := make([]int64, 0, blockSize)
+		for i := 2; i < len(x); i++ {
+			res = append(res, x[i]-(3*x[i-1]-x[i-2])>>1)
+		}
+		w.residual(res, 10)
+	})
+
+	// 常量子帧，带 2 位 wasted bits
+	constant := buildFLACFrame(0, blockSize, func(w *flacWriter) {
+		w.write(0<<1|1, 8)
+		w.write(0b01, 2)
+		w.signed(-1000>>2, 14)
+	})
+
+	data := buildFLAC(16000, 1, 4*blockSize, verbatim, fixed, lpc, constant)
+	samples, info, err := ReadFLAC(bytes.NewReader(data))
+	require.NoError(t, err)
+	require.Equal(t, FLACInfo{SampleRate: 16000, Channels: 1, BitsPerSample: 16, TotalSamples: 4 * blockSize}, info)
+	require.Len(t, samples, 4*blockSize)
+
+	for i, v := range tone[:3*blockSize] {
+		require.Equal(t, float32(v)/32768, samples[i], "sample %d", i)
+	}
+	for _, v := range samples[3*blockSize:] {
+		require.Equal(t, float32(-1000)/32768, v)
+	}
+}
+
+func TestReadFLACStereo(t *testing.T) {
+	const blockSize = 32
+	left := testTone(blockSize, 300, 8000)
+	right := testTone(blockSize, 500, -6000)
+
+	verbatim := func(w *flacWriter, x []int64, bits uint) {
+		w.write(1<<1, 8)
+		for _, v := range x {
+			w.signed(v, bits)
+		}
+	}
+
+	side := make([]int64, blockSize)
+	mid := make([]int64, blockSize)
+	for i := range side {
+		side[i] = left[i] - right[i]
+		mid[i] = (left[i] + right[i]) >> 1
+	}
+
+	frames := [][]byte{
+		buildFLACFrame(1, blockSize, func(w *flacWriter) {
+			verbatim(w, left, 16)
+			verbatim(w, right, 16)
+		}),
+		buildFLACFrame(flacLeftSide, blockSize, func(w *flacWriter) {
+			verbatim(w, left, 16)
+			verbatim(w, side, 17)
+		}),
+		buildFLACFrame(flacSideRight, blockSize, func(w *flacWriter) {
+			verbatim(w, side, 17)
+			verbatim(w, right, 16)
+		}),
+		buildFLACFrame(flacMidSide, blockSize, func(w *flacWriter) {
+			verbatim(w, mid, 16)
+			verbatim(w, side, 17)
+		}),
+	}
+
+	samples, info, err := ReadFLAC(bytes.NewReader(buildFLAC(16000, 2, 4*blockSize, frames...)))
+	require.NoError(t, err)
+	require.Equal(t, 2, info.Channels)
+	require.Len(t, samples, 2*4*blockSize)
+
+	for f := 0; f < len(frames); f++ {
+		for i := 0; i < blockSize; i++ {
+			off := 2 * (f*blockSize + i)
+			require.Equal(t, float32(left[i])/32768, samples[off], "frame %d sample %d", f, i)
+			require.Equal(t, float32(right[i])/32768, samples[off+1], "frame %d sample %d", f, i)
+		}
+	}
+}
+
+func TestReadFLACInvalid(t *testing.T) {
+	_, _, err := ReadFLAC(bytes.NewReader([]byte("RIFF\x00\x00\x00\x00WAVE")))
+	require.ErrorIs(t, err, ErrInvalidFLAC)
+
+	frame := buildFLACFrame(0, 16, func(w *flacWriter) {
+		w.write(0, 8)
+		w.signed(5, 16)
+	})
+	data := buildFLAC(16000, 1, 16, frame)
+
+	corrupt := bytes.Clone(data)
+	corrupt[len(corrupt)-3] ^= 0x01
+	_, _, err = ReadFLAC(bytes.NewReader(corrupt))
+	require.ErrorIs(t, err, ErrInvalidFLAC)
+
+	_, _, err = ReadFLAC(bytes.NewReader(data[:len(data)-1]))
+	require.ErrorIs(t, err, ErrInvalidFLAC)
+}
+
+func TestDecodeFLAC(t *testing.T) {
+	tone := testTone(256, 200, 10000)
+	frame := buildFLACFrame(0, len(tone), func(w *flacWriter) {
+		w.write(1<<1, 8)
+		for _, v := range tone {
+			w.signed(v, 16)
+		}
+	})
+
+	out, err := Decode(bytes.NewReader(buildFLAC(8000, 1, len(tone), frame)), FormatFLAC, 16000)
+	require.NoError(t, err)
+	require.InDelta(t, 2*len(tone), len(out), 2)
+
+	// 长度上限在解码帧时检查
+	data := buildFLAC(8000, 1, len(tone), frame)
+	_, err = DecodeBytesLimit(data, FormatFLAC, 16000, len(tone))
+	require.ErrorIs(t, err, ErrTooLong)
+	out, err = DecodeBytesLimit(data, FormatFLAC, 16000, 4*len(tone))
+	require.NoError(t, err)
+	require.InDelta(t, 2*len(tone), len(out), 2)
+
+	// 文件头中超出范围的采样率在解码之前拒绝
+	_, err = Decode(bytes.NewReader(buildFLAC(1, 1, len(tone), frame)), FormatFLAC, 16000)
+	require.ErrorIs(t, err, ErrUnsupportedRate)
+	require.ErrorIs(t, err, ErrUnsupportedFormat)
+}
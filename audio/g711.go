@@ -0,0 +1,93 @@
+package audio
+
+import "math/bits"
+
+// G.711 解码表，在包初始化时按标准算法生成
+var (
+	ulawTable [256]int16
+	alawTable [256]int16
+)
+
+func init() {
+	for i := 0; i < 256; i++ {
+		ulawTable[i] = ulawToLinear(byte(i))
+		alawTable[i] = alawToLinear(byte(i))
+	}
+}
+
+func ulawToLinear(u byte) int16 {
+	const bias = 0x84
+
+	u = ^u
+	t := (int(u&0x0f) << 3) + bias
+	t <<= (u & 0x70) >> 4
+	if u&0x80 != 0 {
+		return int16(bias - t)
+	}
+	return int16(t - bias)
+}
+
+func alawToLinear(a byte) int16 {
+	a ^= 0x55
+	t := int(a&0x0f) << 4
+	switch seg := (a & 0x70) >> 4; seg {
+	case 0:
+		t += 8
+	case 1:
+		t += 0x108
+	default:
+		t += 0x108
+		t <<= seg - 1
+	}
+	if a&0x80 != 0 {
+		return int16(t)
+	}
+	return int16(-t)
+}
+
+// DecodeULaw 将 G.711 µ-law 字节解码为 float32 采样
+// 结果写入 dst（容量不足时重新分配）并返回。
+func DecodeULaw(dst []float32, src []byte) []float32 {
+	dst = grow(dst, len(src))
+	for i, b := range src {
+		dst[i] = float32(ulawTable[b]) / 32768
+	}
+	return dst
+}
+
+// DecodeALaw 将 G.711 A-law 字节解码为 float32 采样
+// 结果写入 dst（容量不足时重新分配）并返回。
+func DecodeALaw(dst []float32, src []byte) []float32 {
+	dst = grow(dst, len(src))
+	for i, b := range src {
+		dst[i] = float32(alawTable[b]) / 32768
+	}
+	return dst
+}
+
+// EncodeULaw 将 float32 采样编码为 G.711 µ-law 字节，超出 [-1, 1] 的采样会被截断
+// 结果写入 dst（容量不足时重新分配）并返回，可用于向电话网络回送音频。
+func EncodeULaw(dst []byte, src []float32) []byte {
+	dst = grow(dst, len(src))
+	for i, v := range src {
+		dst[i] = linearToULaw(int(clampInt(int64(v*32768), -32768, 32767)))
+	}
+	return dst
+}
+
+func linearToULaw(pcm int) byte {
+	const (
+		bias = 0x84
+		clip = 32635
+	)
+
+	var sign byte
+	if pcm < 0 {
+		sign = 0x80
+		pcm = -pcm
+	}
+	pcm = min(pcm, clip) + bias
+	exponent := bits.Len(uint(pcm>>7)) - 1
+	mantissa := (pcm >> (exponent + 3)) & 0x0f
+	return ^(sign | byte(exponent<<4) | byte(mantissa))
+}
@@ -0,0 +1,37 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestG711(t *testing.T) {
+	require.Equal(t, []int16{0, 0, -32124, 32124}, []int16{ulawTable[0xff], ulawTable[0x7f], ulawTable[0x00], ulawTable[0x80]})
+	require.Equal(t, []int16{8, -8, 32256, -32256}, []int16{alawTable[0xd5], alawTable[0x55], alawTable[0xaa], alawTable[0x2a]})
+
+	out, err := BytesToFloat32(nil, []byte{0xff, 0x80}, FormatULaw, binary.LittleEndian)
+	require.NoError(t, err)
+	require.Equal(t, []float32{0, 32124.0 / 32768}, out)
+
+	out, err = BytesToFloat32(nil, []byte{0xaa}, FormatALaw, nil)
+	require.NoError(t, err)
+	require.Equal(t, []float32{32256.0 / 32768}, out)
+
+	samples, info, err := ReadWAV(bytes.NewReader(buildWAV(wavFormatULaw, 1, 8000, 8, []byte{0xff, 0x00})))
+	require.NoError(t, err)
+	require.Equal(t, FormatULaw, info.Companding)
+	require.Equal(t, []float32{0, -32124.0 / 32768}, samples)
+
+	// 除负零（0x7f）外每个码字都能经解码、编码还原
+	codes := make([]byte, 256)
+	for i := range codes {
+		codes[i] = byte(i)
+	}
+	encoded := EncodeULaw(nil, DecodeULaw(nil, codes))
+	codes[0x7f] = 0xff
+	require.Equal(t, codes, encoded)
+	require.Equal(t, []byte{0x80, 0x00}, EncodeULaw(nil, []float32{2, -2}))
+}
@@ -0,0 +1,74 @@
+package audio
+
+import "math"
+
+// Peak 返回采样绝对值的最大值
+func Peak(pcm []float32) float64 {
+	var peak float64
+	for _, v := range pcm {
+		if a := math.Abs(float64(v)); a > peak {
+			peak = a
+		}
+	}
+	return peak
+}
+
+// RMS 返回采样的均方根
+func RMS(pcm []float32) float64 {
+	if len(pcm) == 0 {
+		return 0
+	}
+
+	var sum float64
+	for _, v := range pcm {
+		sum += float64(v) * float64(v)
+	}
+	return math.Sqrt(sum / float64(len(pcm)))
+}
+
+// AmplitudeToDB 将线性幅度转换为 dBFS，幅度为 0 时返回 -Inf
+func AmplitudeToDB(amplitude float64) float64 {
+	return 20 * math.Log10(amplitude)
+}
+
+// DBToAmplitude 将 dBFS 转换为线性幅度
+func DBToAmplitude(db float64) float64 {
+	return math.Pow(10, db/20)
+}
+
+// Normalize 按峰值归一化，使结果的峰值为 targetDB（dBFS，通常为负数，如 -1）
+// 返回新的切片，不修改输入；全静音的输入原样复制返回。
+func Normalize(pcm []float32, targetDB float64) []float32 {
+	peak := Peak(pcm)
+	if peak == 0 {
+		return append([]float32(nil), pcm...)
+	}
+
+	return applyGain(pcm, DBToAmplitude(targetDB)/peak)
+}
+
+// NormalizeRMS 按均方根归一化，使结果的 RMS 电平为 targetDB（dBFS，如 -20）
+// 增益会受限以保证峰值不超过 0 dBFS，因此动态范围很大的输入可能达不到目标电平。
+// 返回新的切片，不修改输入。
+func NormalizeRMS(pcm []float32, targetDB float64) []float32 {
+	rms := RMS(pcm)
+	if rms == 0 {
+		return append([]float32(nil), pcm...)
+	}
+
+	gain := DBToAmplitude(targetDB) / rms
+	if maxGain := 1 / Peak(pcm); gain > maxGain {
+		gain = maxGain
+	}
+
+	return applyGain(pcm, gain)
+}
+
+func applyGain(pcm []float32, gain float64) []float32 {
+	out := make([]float32, len(pcm))
+	g := float32(gain)
+	for i, v := range pcm {
+		out[i] = v * g
+	}
+	return out
+}
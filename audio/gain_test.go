@@ -0,0 +1,30 @@
+package audio
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalize(t *testing.T) {
+	quiet := sine(440, 16000, 16000)
+	for i := range quiet {
+		quiet[i] *= 0.01
+	}
+
+	out := Normalize(quiet, -1)
+	require.InDelta(t, -1, AmplitudeToDB(Peak(out)), 1e-3)
+	require.InDelta(t, 0.005, Peak(quiet), 1e-4, "input must not be modified")
+
+	out = NormalizeRMS(quiet, -20)
+	require.InDelta(t, -20, AmplitudeToDB(RMS(out)), 1e-3)
+
+	// 峰值限制：目标过高时不超过 0 dBFS
+	out = NormalizeRMS(quiet, 0)
+	require.LessOrEqual(t, Peak(out), 1.0+1e-6)
+
+	silence := make([]float32, 10)
+	require.Equal(t, silence, Normalize(silence, -1))
+	require.True(t, math.IsInf(AmplitudeToDB(RMS(silence)), -1))
+}
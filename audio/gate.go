@@ -0,0 +1,100 @@
+package audio
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// NoiseGateConfig 噪声门参数
+type NoiseGateConfig struct {
+	// 输入音频的采样率
+	SampleRate int
+	// 开门阈值（dBFS），包络低于该值时门逐渐关闭，例如 -45
+	ThresholdDB float64
+	// 从关闭到完全打开所需的时间，过短会产生咔嗒声，通常 1–10ms
+	Attack time.Duration
+	// 从打开到完全关闭所需的时间，应覆盖词间的短停顿，通常 50–200ms
+	Release time.Duration
+}
+
+// gateEnvelopeRelease 电平检测包络的衰减时间，只需跨过低频信号的过零点即可，
+// 门的关闭速度由 NoiseGateConfig.Release 决定
+const gateEnvelopeRelease = 10 * time.Millisecond
+
+// NoiseGate 简单的噪声门，用于在常开设备上抑制风扇、空调等持续的低电平噪声
+type NoiseGate struct {
+	threshold   float64
+	attackCoef  float64
+	releaseCoef float64
+	envCoef     float64
+	env         float64
+	gain        float64
+}
+
+var (
+	_ Processor = (*NoiseGate)(nil)
+	_ Cloner    = (*NoiseGate)(nil)
+)
+
+// NewNoiseGate 创建噪声门
+func NewNoiseGate(cfg NoiseGateConfig) (*NoiseGate, error) {
+	if cfg.SampleRate <= 0 {
+		return nil, fmt.Errorf("invalid sample rate: %d", cfg.SampleRate)
+	}
+	if cfg.ThresholdDB > 0 {
+		return nil, fmt.Errorf("invalid threshold %gdB: should not be positive", cfg.ThresholdDB)
+	}
+	if cfg.Attack < 0 || cfg.Release < 0 {
+		return nil, fmt.Errorf("invalid attack/release: should not be negative")
+	}
+
+	return &NoiseGate{
+		threshold:   DBToAmplitude(cfg.ThresholdDB),
+		attackCoef:  smoothingCoef(cfg.Attack, cfg.SampleRate),
+		releaseCoef: smoothingCoef(cfg.Release, cfg.SampleRate),
+		envCoef:     smoothingCoef(gateEnvelopeRelease, cfg.SampleRate),
+	}, nil
+}
+
+// smoothingCoef 返回一阶平滑系数，时间为 0 时立即跳变
+func smoothingCoef(d time.Duration, sampleRate int) float64 {
+	if d <= 0 {
+		return 0
+	}
+	return math.Exp(-1 / (d.Seconds() * float64(sampleRate)))
+}
+
+// Process 对 src 应用噪声门并把结果追加到 dst 后返回
+func (g *NoiseGate) Process(dst, src []float32) []float32 {
+	for _, v := range src {
+		// 峰值包络：上升沿立即跟随，下降沿快速衰减
+		a := math.Abs(float64(v))
+		if a > g.env {
+			g.env = a
+		} else {
+			g.env = g.envCoef*g.env + (1-g.envCoef)*a
+		}
+
+		target, coef := 0.0, g.releaseCoef
+		if g.env >= g.threshold {
+			target, coef = 1, g.attackCoef
+		}
+		g.gain = coef*g.gain + (1-coef)*target
+
+		dst = append(dst, float32(float64(v)*g.gain))
+	}
+	return dst
+}
+
+// Reset 关闭噪声门并清空包络
+func (g *NoiseGate) Reset() {
+	g.env = 0
+	g.gain = 0
+}
+
+// Clone 返回当前包络和增益状态的副本
+func (g *NoiseGate) Clone() Processor {
+	c := *g
+	return &c
+}
@@ -0,0 +1,38 @@
+package audio
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNoiseGate(t *testing.T) {
+	g, err := NewNoiseGate(NoiseGateConfig{
+		SampleRate:  16000,
+		ThresholdDB: -40,
+		Attack:      5 * time.Millisecond,
+		Release:     100 * time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	// 低于阈值的持续噪声被抑制
+	noise := sine(200, 16000, 16000)
+	for i := range noise {
+		noise[i] *= 0.001
+	}
+	out := g.Process(nil, noise)
+	require.Less(t, RMS(out), RMS(noise)/100)
+
+	// 高于阈值的语音在起音时间后基本无衰减
+	speech := sine(300, 16000, 16000)
+	out = g.Process(nil, speech)
+	require.InDelta(t, RMS(speech[1600:]), RMS(out[1600:]), 1e-3)
+
+	// 语音结束后门在释放时间内关闭
+	out = g.Process(nil, noise)
+	require.Less(t, RMS(out[8000:]), RMS(noise)/100)
+
+	_, err = NewNoiseGate(NoiseGateConfig{SampleRate: 16000, ThresholdDB: 3})
+	require.Error(t, err)
+}
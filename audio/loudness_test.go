@@ -0,0 +1,58 @@
+package audio
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestKWeighting(t *testing.T) {
+	_, err := NewKWeighting(0)
+	require.Error(t, err)
+
+	sine := func(freq, amp float64, rate, n int) []float32 {
+		pcm := make([]float32, n)
+		for i := range pcm {
+			pcm[i] = float32(amp * math.Sin(2*math.Pi*freq*float64(i)/float64(rate)))
+		}
+		return pcm
+	}
+	meanSquare := func(pcm []float32) float64 {
+		var sum float64
+		for _, v := range pcm {
+			sum += float64(v) * float64(v)
+		}
+		return sum / float64(len(pcm))
+	}
+
+	for _, rate := range []int{8000, 16000, 48000} {
+		k, err := NewKWeighting(rate)
+		require.NoError(t, err)
+
+		// 满幅 997Hz 正弦为 -3.01 LUFS；8kHz 下双线性变换的频率畸变使高架提前起效，误差稍大
+		out := k.Process(nil, sine(997, 1, rate, rate))
+		require.InDelta(t, -3.01, Loudness(meanSquare(out[rate/2:])), 0.25, "rate %d", rate)
+
+		// 低频被高通衰减，高频被高架提升
+		k.Reset()
+		out = k.Process(nil, sine(20, 1, rate, rate))
+		require.Less(t, Loudness(meanSquare(out[rate/2:])), -10.0)
+		k.Reset()
+		out = k.Process(nil, sine(3000, 1, rate, rate))
+		require.Greater(t, Loudness(meanSquare(out[rate/2:])), -3.01+3)
+	}
+}
+
+func TestGatedLoudness(t *testing.T) {
+	require.True(t, math.IsInf(GatedLoudness(nil), -1))
+	require.True(t, math.IsInf(GatedLoudness([]float64{1e-9}), -1))
+
+	loud := math.Pow(10, (-20+0.691)/10)
+	quiet := math.Pow(10, (-40+0.691)/10)
+	// 低于相对门限的块被排除
+	require.InDelta(t, -20, GatedLoudness([]float64{loud, loud, quiet, 1e-12}), 1e-9)
+	// 相差不足 10 LU 的块都参与平均
+	mid := math.Pow(10, (-25+0.691)/10)
+	require.InDelta(t, Loudness((loud+mid)/2), GatedLoudness([]float64{loud, mid}), 1e-9)
+}
@@ -0,0 +1,69 @@
+package audio
+
+import (
+	"fmt"
+	"os"
+)
+
+// MappedFile 以只读方式映射到内存的文件
+// 数据由内核按需从磁盘分页读入，不经过 Go 堆，适合逐段处理数 GB 的录音；
+// 在不支持 mmap 的平台上退化为一次读入内存。
+type MappedFile struct {
+	data   []byte
+	mapped bool
+}
+
+// MapFile 把 path 只读映射到内存，使用完毕后需要调用 Close
+func MapFile(path string) (*MappedFile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	size := fi.Size()
+	if size != int64(int(size)) {
+		return nil, fmt.Errorf("file too large to map: %d bytes", size)
+	}
+	if size == 0 {
+		return &MappedFile{}, nil
+	}
+
+	data, mapped, err := mapFile(f, int(size))
+	if err != nil {
+		return nil, fmt.Errorf("failed to map %s: %w", path, err)
+	}
+	return &MappedFile{data: data, mapped: mapped}, nil
+}
+
+// Bytes 返回文件内容，Close 之后不能再访问
+func (m *MappedFile) Bytes() []byte {
+	return m.data
+}
+
+// Len 返回文件的字节数
+func (m *MappedFile) Len() int {
+	return len(m.data)
+}
+
+// Release 提示内核 [0, end) 范围已处理完毕，其页面可以立即回收
+// 顺序处理大文件时定期调用，可使进程的常驻内存保持平稳。之后仍可访问这些数据，内核会重新读入。
+func (m *MappedFile) Release(end int) {
+	if m.mapped {
+		releasePages(m.data, min(end, len(m.data)))
+	}
+}
+
+// Close 解除映射，重复调用是安全的
+func (m *MappedFile) Close() error {
+	data, mapped := m.data, m.mapped
+	m.data, m.mapped = nil, false
+	if mapped {
+		return unmapFile(data)
+	}
+	return nil
+}
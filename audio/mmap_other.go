@@ -0,0 +1,23 @@
+//go:build !linux && !darwin
+
+package audio
+
+import (
+	"io"
+	"os"
+)
+
+// mapFile 不支持 mmap 的平台上读入整个文件
+func mapFile(f *os.File, size int) ([]byte, bool, error) {
+	data := make([]byte, size)
+	if _, err := io.ReadFull(f, data); err != nil {
+		return nil, false, err
+	}
+	return data, false, nil
+}
+
+func unmapFile([]byte) error {
+	return nil
+}
+
+func releasePages([]byte, int) {}
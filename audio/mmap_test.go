@@ -0,0 +1,44 @@
+package audio
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMapFile(t *testing.T) {
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "data.bin")
+	data := make([]byte, 3*os.Getpagesize()+100)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	require.NoError(t, os.WriteFile(path, data, 0o644))
+
+	m, err := MapFile(path)
+	require.NoError(t, err)
+	require.Equal(t, len(data), m.Len())
+	require.Equal(t, data, m.Bytes())
+
+	// 释放后的页面仍可读取，内容由内核重新读入
+	m.Release(2*os.Getpagesize() + 10)
+	m.Release(m.Len() + 1)
+	require.Equal(t, data, m.Bytes())
+
+	require.NoError(t, m.Close())
+	require.NoError(t, m.Close())
+	require.Nil(t, m.Bytes())
+
+	empty := filepath.Join(dir, "empty.bin")
+	require.NoError(t, os.WriteFile(empty, nil, 0o644))
+	m, err = MapFile(empty)
+	require.NoError(t, err)
+	require.Zero(t, m.Len())
+	require.NoError(t, m.Close())
+
+	_, err = MapFile(filepath.Join(dir, "missing.bin"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
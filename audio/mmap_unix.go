@@ -0,0 +1,31 @@
+//go:build linux || darwin
+
+package audio
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+func mapFile(f *os.File, size int) ([]byte, bool, error) {
+	data, err := unix.Mmap(int(f.Fd()), 0, size, unix.PROT_READ, unix.MAP_SHARED)
+	if err != nil {
+		return nil, false, err
+	}
+	// 检测是顺序读取，提示内核加大预读
+	_ = unix.Madvise(data, unix.MADV_SEQUENTIAL)
+	return data, true, nil
+}
+
+func unmapFile(data []byte) error {
+	return unix.Munmap(data)
+}
+
+// releasePages 对 [0, end) 中完整的页调用 MADV_DONTNEED
+func releasePages(data []byte, end int) {
+	end -= end % os.Getpagesize()
+	if end > 0 {
+		_ = unix.Madvise(data[:end], unix.MADV_DONTNEED)
+	}
+}
@@ -0,0 +1,52 @@
+package audio
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/hajimehoshi/go-mp3"
+)
+
+// DecodeMP3 解码 MP3 数据为采样率为 sampleRate 的单声道音频
+// 使用纯 Go 解码器，无需 cgo；解码后的立体声会被混为单声道并重采样到 sampleRate。
+func DecodeMP3(r io.Reader, sampleRate int) ([]float32, error) {
+	return decodeMP3(r, sampleRate, 0)
+}
+
+// decodeMP3 实现 DecodeMP3，输出超过 maxSamples 个采样时返回 ErrTooLong，0 表示不限制
+func decodeMP3(r io.Reader, sampleRate, maxSamples int) ([]float32, error) {
+	dec, err := mp3.NewDecoder(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create mp3 decoder: %w", err)
+	}
+	if err := checkSourceRate(dec.SampleRate(), sampleRate); err != nil {
+		return nil, err
+	}
+
+	// go-mp3 总是输出 16 位小端立体声
+	var src io.Reader = dec
+	limit := sourceLimit(maxSamples, dec.SampleRate(), sampleRate)
+	if limit > 0 {
+		src = io.LimitReader(dec, int64(limit)*4+1)
+	}
+	data, err := io.ReadAll(src)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode mp3: %w", err)
+	}
+	if limit > 0 && len(data) > limit*4 {
+		return nil, tooLong(maxSamples)
+	}
+	data = data[:len(data)/4*4]
+
+	stereo := make([]int16, len(data)/2)
+	for i := range stereo {
+		stereo[i] = int16(uint16(data[2*i]) | uint16(data[2*i+1])<<8)
+	}
+
+	mono, err := Downmix(Int16ToFloat32(nil, stereo), 2)
+	if err != nil {
+		return nil, err
+	}
+
+	return Resample(mono, dec.SampleRate(), sampleRate)
+}
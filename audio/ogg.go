@@ -0,0 +1,129 @@
+package audio
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrInvalidOgg 输入不是合法的 Ogg 数据
+var ErrInvalidOgg = errors.New("invalid ogg data")
+
+// oggCRCTable Ogg 使用的 CRC-32（多项式 0x04c11db7，不反转）
+var oggCRCTable = func() [256]uint32 {
+	var t [256]uint32
+	for i := range t {
+		r := uint32(i) << 24
+		for j := 0; j < 8; j++ {
+			if r&0x80000000 != 0 {
+				r = r<<1 ^ 0x04c11db7
+			} else {
+				r <<= 1
+			}
+		}
+		t[i] = r
+	}
+	return t
+}()
+
+func oggCRC(crc uint32, data []byte) uint32 {
+	for _, b := range data {
+		crc = crc<<8 ^ oggCRCTable[byte(crc>>24)^b]
+	}
+	return crc
+}
+
+// OggReader 从 Ogg 容器中按顺序读取第一个逻辑流的数据包
+// 其它逻辑流（如视频轨）的页面会被跳过。
+type OggReader struct {
+	r       *bufio.Reader
+	serial  uint32
+	locked  bool
+	lacing  []byte
+	body    []byte
+	seg     int
+	off     int
+	partial []byte
+}
+
+// NewOggReader 创建 Ogg 数据包读取器
+func NewOggReader(r io.Reader) *OggReader {
+	return &OggReader{r: bufio.NewReader(r)}
+}
+
+// ReadPacket 返回下一个完整的数据包，流结束时返回 io.EOF
+// 返回的切片在下一次调用前有效。
+func (o *OggReader) ReadPacket() ([]byte, error) {
+	o.partial = o.partial[:0]
+	for {
+		for o.seg < len(o.lacing) {
+			n := int(o.lacing[o.seg])
+			o.partial = append(o.partial, o.body[o.off:o.off+n]...)
+			o.seg++
+			o.off += n
+			// 长度小于 255 的段表示数据包结束
+			if n < 255 {
+				return o.partial, nil
+			}
+		}
+
+		if err := o.readPage(); err != nil {
+			if errors.Is(err, io.EOF) && len(o.partial) > 0 {
+				return nil, fmt.Errorf("%w: truncated packet", ErrInvalidOgg)
+			}
+			return nil, err
+		}
+	}
+}
+
+// readPage 读取并校验下一个属于当前逻辑流的页面
+func (o *OggReader) readPage() error {
+	for {
+		var header [27]byte
+		if _, err := io.ReadFull(o.r, header[:]); err != nil {
+			if errors.Is(err, io.ErrUnexpectedEOF) {
+				return fmt.Errorf("%w: truncated page header", ErrInvalidOgg)
+			}
+			return err
+		}
+		if string(header[0:4]) != "OggS" || header[4] != 0 {
+			return fmt.Errorf("%w: bad page capture pattern", ErrInvalidOgg)
+		}
+
+		lacing := make([]byte, header[26])
+		if _, err := io.ReadFull(o.r, lacing); err != nil {
+			return fmt.Errorf("%w: truncated lacing table: %w", ErrInvalidOgg, err)
+		}
+		size := 0
+		for _, l := range lacing {
+			size += int(l)
+		}
+		body := make([]byte, size)
+		if _, err := io.ReadFull(o.r, body); err != nil {
+			return fmt.Errorf("%w: truncated page body: %w", ErrInvalidOgg, err)
+		}
+
+		want := binary.LittleEndian.Uint32(header[22:26])
+		binary.LittleEndian.PutUint32(header[22:26], 0)
+		crc := oggCRC(0, header[:])
+		crc = oggCRC(crc, lacing)
+		crc = oggCRC(crc, body)
+		if crc != want {
+			return fmt.Errorf("%w: page checksum mismatch", ErrInvalidOgg)
+		}
+
+		serial := binary.LittleEndian.Uint32(header[14:18])
+		if !o.locked {
+			o.serial = serial
+			o.locked = true
+		}
+		if serial != o.serial {
+			continue
+		}
+
+		o.lacing, o.body, o.seg, o.off = lacing, body, 0, 0
+		return nil
+	}
+}
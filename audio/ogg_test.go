@@ -0,0 +1,67 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// buildOggPage 拼装一个 Ogg 页面，packets 中的每个元素按 lacing 规则写入
+func buildOggPage(serial, seq uint32, packets ...[]byte) []byte {
+	var lacing, body []byte
+	for _, p := range packets {
+		n := len(p)
+		for n >= 255 {
+			lacing = append(lacing, 255)
+			n -= 255
+		}
+		lacing = append(lacing, byte(n))
+		body = append(body, p...)
+	}
+
+	header := make([]byte, 27)
+	copy(header, "OggS")
+	binary.LittleEndian.PutUint32(header[14:], serial)
+	binary.LittleEndian.PutUint32(header[18:], seq)
+	header[26] = byte(len(lacing))
+
+	crc := oggCRC(0, header)
+	crc = oggCRC(crc, lacing)
+	crc = oggCRC(crc, body)
+	binary.LittleEndian.PutUint32(header[22:], crc)
+
+	return append(append(header, lacing...), body...)
+}
+
+func TestOggReader(t *testing.T) {
+	long := bytes.Repeat([]byte{7}, 600)
+
+	var stream []byte
+	stream = append(stream, buildOggPage(1, 0, []byte("first"))...)
+	stream = append(stream, buildOggPage(2, 0, []byte("other stream"))...)
+	stream = append(stream, buildOggPage(1, 1, long, []byte("last"))...)
+
+	r := NewOggReader(bytes.NewReader(stream))
+	for _, want := range [][]byte{[]byte("first"), long, []byte("last")} {
+		p, err := r.ReadPacket()
+		require.NoError(t, err)
+		require.Equal(t, want, p)
+	}
+	_, err := r.ReadPacket()
+	require.True(t, errors.Is(err, io.EOF))
+
+	// 校验和错误
+	bad := buildOggPage(1, 0, []byte("data"))
+	bad[len(bad)-1] ^= 0xff
+	_, err = NewOggReader(bytes.NewReader(bad)).ReadPacket()
+	require.ErrorIs(t, err, ErrInvalidOgg)
+}
+
+func TestDecodeOggOpusHeader(t *testing.T) {
+	_, err := DecodeOggOpus(bytes.NewReader(buildOggPage(1, 0, []byte("NotOpus!"))), 16000)
+	require.ErrorIs(t, err, ErrInvalidOgg)
+}
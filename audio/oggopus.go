@@ -0,0 +1,72 @@
+package audio
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrOpusUnsupported 当前构建未启用 Opus 解码（需要 opus 构建标签）
+var ErrOpusUnsupported = errors.New("opus decoding not supported: build with -tags opus")
+
+// opusRate Opus 内部时间基准，OpusHead 中的 pre-skip 以此为单位
+const opusRate = 48000
+
+// DecodeOggOpus 解码 Ogg/Opus 文件为采样率为 sampleRate 的单声道音频
+// sampleRate 必须是 Opus 支持的输出采样率（8000、12000、16000、24000 或 48000）。
+// 只支持映射族 0（单声道/立体声），立体声会被混为单声道。
+func DecodeOggOpus(r io.Reader, sampleRate int) ([]float32, error) {
+	return decodeOggOpus(r, sampleRate, 0)
+}
+
+// decodeOggOpus 实现 DecodeOggOpus，输出超过 maxSamples 个采样时返回 ErrTooLong，0 表示不限制
+func decodeOggOpus(r io.Reader, sampleRate, maxSamples int) ([]float32, error) {
+	ogg := NewOggReader(r)
+
+	head, err := ogg.ReadPacket()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read OpusHead: %w", err)
+	}
+	if len(head) < 19 || string(head[0:8]) != "OpusHead" {
+		return nil, fmt.Errorf("%w: missing OpusHead", ErrInvalidOgg)
+	}
+	if family := head[18]; family != 0 {
+		return nil, fmt.Errorf("unsupported opus channel mapping family %d", family)
+	}
+	preSkip := int(binary.LittleEndian.Uint16(head[10:12])) * sampleRate / opusRate
+
+	// 第二个数据包是 OpusTags 元数据
+	if _, err := ogg.ReadPacket(); err != nil {
+		return nil, fmt.Errorf("failed to read OpusTags: %w", err)
+	}
+
+	dec, err := NewOpusDecoder(sampleRate, 1)
+	if err != nil {
+		return nil, err
+	}
+	defer dec.Close()
+
+	var pcm []float32
+	for {
+		packet, err := ogg.ReadPacket()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if pcm, err = dec.Decode(pcm, packet); err != nil {
+			return nil, err
+		}
+		if maxSamples > 0 && len(pcm)-preSkip > maxSamples {
+			return nil, tooLong(maxSamples)
+		}
+	}
+
+	// 去掉编码器引入的起始延迟
+	if preSkip > len(pcm) {
+		preSkip = len(pcm)
+	}
+	return pcm[preSkip:], nil
+}
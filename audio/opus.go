@@ -0,0 +1,66 @@
+//go:build opus
+
+package audio
+
+// #cgo pkg-config: opus
+// #include <opus.h>
+import "C"
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+// opusMaxFrameMs Opus 单个数据包最长可包含 120ms 音频
+const opusMaxFrameMs = 120
+
+// OpusDecoder 基于 libopus 的 Opus 数据包解码器，适用于 WebRTC/RTP 等直接收到的 Opus 包
+// OpusDecoder 不是并发安全的，使用完毕后需要调用 Close。
+type OpusDecoder struct {
+	dec        *C.OpusDecoder
+	sampleRate int
+	channels   int
+	buf        []float32
+}
+
+// NewOpusDecoder 创建输出采样率为 sampleRate、声道数为 channels 的解码器
+// sampleRate 必须是 8000、12000、16000、24000 或 48000；channels 为 1 时立体声流会被自动混为单声道。
+func NewOpusDecoder(sampleRate, channels int) (*OpusDecoder, error) {
+	var errCode C.int
+	dec := C.opus_decoder_create(C.opus_int32(sampleRate), C.int(channels), &errCode)
+	if errCode != C.OPUS_OK {
+		return nil, fmt.Errorf("failed to create opus decoder: %s", C.GoString(C.opus_strerror(errCode)))
+	}
+
+	return &OpusDecoder{
+		dec:        dec,
+		sampleRate: sampleRate,
+		channels:   channels,
+		buf:        make([]float32, sampleRate*opusMaxFrameMs/1000*channels),
+	}, nil
+}
+
+// Decode 解码一个 Opus 数据包并把交错排列的采样追加到 dst 后返回
+// packet 为 nil 时执行丢包补偿，生成一帧估计的音频。
+func (d *OpusDecoder) Decode(dst []float32, packet []byte) ([]float32, error) {
+	var data *C.uchar
+	if len(packet) > 0 {
+		data = (*C.uchar)(unsafe.Pointer(&packet[0]))
+	}
+
+	n := C.opus_decode_float(d.dec, data, C.opus_int32(len(packet)),
+		(*C.float)(unsafe.Pointer(&d.buf[0])), C.int(len(d.buf)/d.channels), 0)
+	if n < 0 {
+		return dst, fmt.Errorf("failed to decode opus packet: %s", C.GoString(C.opus_strerror(n)))
+	}
+
+	return append(dst, d.buf[:int(n)*d.channels]...), nil
+}
+
+// Close 释放解码器
+func (d *OpusDecoder) Close() {
+	if d.dec != nil {
+		C.opus_decoder_destroy(d.dec)
+		d.dec = nil
+	}
+}
@@ -0,0 +1,20 @@
+//go:build !opus
+
+package audio
+
+// OpusDecoder 在未启用 opus 构建标签时不可用
+// 使用 `go build -tags opus` 并安装 libopus 开发包以启用 Opus 解码。
+type OpusDecoder struct{}
+
+// NewOpusDecoder 未启用 opus 构建标签时总是返回 ErrOpusUnsupported
+func NewOpusDecoder(sampleRate, channels int) (*OpusDecoder, error) {
+	return nil, ErrOpusUnsupported
+}
+
+// Decode 未启用 opus 构建标签时总是返回 ErrOpusUnsupported
+func (d *OpusDecoder) Decode(dst []float32, packet []byte) ([]float32, error) {
+	return dst, ErrOpusUnsupported
+}
+
+// Close 未启用 opus 构建标签时无操作
+func (d *OpusDecoder) Close() {}
@@ -0,0 +1,146 @@
+package audio
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// SampleFormat 描述原始字节流中单个采样点的编码
+type SampleFormat int
+
+const (
+	// FormatPCM16 16 位有符号整数 PCM
+	FormatPCM16 SampleFormat = iota + 1
+	// FormatFloat32 32 位 IEEE 754 浮点
+	FormatFloat32
+	// FormatULaw G.711 µ-law，每个采样 1 字节，常见于北美/日本电话网络
+	FormatULaw
+	// FormatALaw G.711 A-law，每个采样 1 字节，常见于欧洲电话网络
+	FormatALaw
+	// FormatOggOpus Ogg 封装的 Opus 音频（需要 opus 构建标签），只能通过 Decode 解码
+	FormatOggOpus
+	// FormatMP3 MP3 音频，只能通过 Decode 解码
+	FormatMP3
+	// FormatFLAC FLAC 无损音频，只能通过 Decode 解码
+	FormatFLAC
+)
+
+// BytesPerSample 返回该格式下每个采样点占用的字节数，压缩格式返回 0
+func (f SampleFormat) BytesPerSample() int {
+	switch f {
+	case FormatPCM16:
+		return 2
+	case FormatFloat32:
+		return 4
+	case FormatULaw, FormatALaw:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func (f SampleFormat) String() string {
+	switch f {
+	case FormatPCM16:
+		return "pcm16"
+	case FormatFloat32:
+		return "float32"
+	case FormatULaw:
+		return "ulaw"
+	case FormatALaw:
+		return "alaw"
+	case FormatOggOpus:
+		return "oggopus"
+	case FormatMP3:
+		return "mp3"
+	case FormatFLAC:
+		return "flac"
+	default:
+		return fmt.Sprintf("SampleFormat(%d)", int(f))
+	}
+}
+
+// ParseSampleFormat 按 String 返回的名称解析格式，便于从命令行参数或请求参数中读取
+func ParseSampleFormat(name string) (SampleFormat, error) {
+	for f := FormatPCM16; f <= FormatFLAC; f++ {
+		if f.String() == name {
+			return f, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: sample format %q", ErrUnsupportedFormat, name)
+}
+
+// grow 返回长度为 n 的切片，容量足够时复用 dst 的底层数组
+func grow[T any](dst []T, n int) []T {
+	if cap(dst) >= n {
+		return dst[:n]
+	}
+	return make([]T, n)
+}
+
+// Int16ToFloat32 将 16 位 PCM 转换为 [-1, 1) 范围的 float32
+// 结果写入 dst（容量不足时重新分配）并返回，便于在流式处理中复用缓冲区。
+func Int16ToFloat32(dst []float32, src []int16) []float32 {
+	dst = grow(dst, len(src))
+	int16ToFloat32(dst, src)
+	return dst
+}
+
+// Float32ToInt16 将 float32 采样转换为 16 位 PCM，超出 [-1, 1] 的值会被截断
+// 结果写入 dst（容量不足时重新分配）并返回。
+func Float32ToInt16(dst []int16, src []float32) []int16 {
+	dst = grow(dst, len(src))
+	for i, v := range src {
+		x := math.Round(float64(v) * 32768)
+		if x > math.MaxInt16 {
+			x = math.MaxInt16
+		} else if x < math.MinInt16 {
+			x = math.MinInt16
+		}
+		dst[i] = int16(x)
+	}
+	return dst
+}
+
+// BytesToFloat32 按指定格式和字节序解码原始采样数据
+// 结果写入 dst（容量不足时重新分配）并返回。data 的长度必须是采样点大小的整数倍。
+// 单字节的 G.711 格式忽略 order。
+func BytesToFloat32(dst []float32, data []byte, format SampleFormat, order binary.ByteOrder) ([]float32, error) {
+	size := format.BytesPerSample()
+	if size == 0 {
+		return nil, fmt.Errorf("%w: sample format %s", ErrUnsupportedFormat, format)
+	}
+	if len(data)%size != 0 {
+		return nil, fmt.Errorf("invalid data length %d: not a multiple of %d", len(data), size)
+	}
+
+	n := len(data) / size
+	dst = grow(dst, n)
+
+	le := order == binary.ByteOrder(binary.LittleEndian)
+	switch format {
+	case FormatPCM16:
+		if le {
+			pcm16LEToFloat32(dst, data)
+			break
+		}
+		for i := 0; i < n; i++ {
+			dst[i] = float32(int16(order.Uint16(data[i*2:]))) * pcm16Scale
+		}
+	case FormatFloat32:
+		if le {
+			float32LEToFloat32(dst, data)
+			break
+		}
+		for i := 0; i < n; i++ {
+			dst[i] = math.Float32frombits(order.Uint32(data[i*4:]))
+		}
+	case FormatULaw:
+		dst = DecodeULaw(dst, data)
+	case FormatALaw:
+		dst = DecodeALaw(dst, data)
+	}
+
+	return dst, nil
+}
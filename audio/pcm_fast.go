@@ -0,0 +1,75 @@
+package audio
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// pcm16Scale 16 位 PCM 归一化的比例，乘以 2 的整数次幂与除以 32768 的结果完全相同
+const pcm16Scale = 1.0 / 32768
+
+// 下面的转换每次处理 8 个采样：把子切片转换为定长数组指针后，编译器可以省去循环内逐个采样的边界检查，
+// 并且小端序直接使用具体类型，避免通过 binary.ByteOrder 接口对每个采样做一次动态调用。
+// 长时间录音中大部分是静音，这部分转换的开销不应超过模型推理。
+
+// int16ToFloat32 把 src 转换到等长的 dst
+func int16ToFloat32(dst []float32, src []int16) {
+	n := len(src) &^ 7
+	for i := 0; i < n; i += 8 {
+		s := (*[8]int16)(src[i:])
+		d := (*[8]float32)(dst[i:])
+		d[0] = float32(s[0]) * pcm16Scale
+		d[1] = float32(s[1]) * pcm16Scale
+		d[2] = float32(s[2]) * pcm16Scale
+		d[3] = float32(s[3]) * pcm16Scale
+		d[4] = float32(s[4]) * pcm16Scale
+		d[5] = float32(s[5]) * pcm16Scale
+		d[6] = float32(s[6]) * pcm16Scale
+		d[7] = float32(s[7]) * pcm16Scale
+	}
+	for i := n; i < len(src); i++ {
+		dst[i] = float32(src[i]) * pcm16Scale
+	}
+}
+
+// pcm16LEToFloat32 把小端序 16 位 PCM 字节转换到 dst，len(data) 必须等于 2*len(dst)
+func pcm16LEToFloat32(dst []float32, data []byte) {
+	le := binary.LittleEndian
+	n := len(dst) &^ 7
+	for i := 0; i < n; i += 8 {
+		b := (*[16]byte)(data[i*2:])
+		d := (*[8]float32)(dst[i:])
+		d[0] = float32(int16(le.Uint16(b[0:]))) * pcm16Scale
+		d[1] = float32(int16(le.Uint16(b[2:]))) * pcm16Scale
+		d[2] = float32(int16(le.Uint16(b[4:]))) * pcm16Scale
+		d[3] = float32(int16(le.Uint16(b[6:]))) * pcm16Scale
+		d[4] = float32(int16(le.Uint16(b[8:]))) * pcm16Scale
+		d[5] = float32(int16(le.Uint16(b[10:]))) * pcm16Scale
+		d[6] = float32(int16(le.Uint16(b[12:]))) * pcm16Scale
+		d[7] = float32(int16(le.Uint16(b[14:]))) * pcm16Scale
+	}
+	for i := n; i < len(dst); i++ {
+		dst[i] = float32(int16(le.Uint16(data[i*2:]))) * pcm16Scale
+	}
+}
+
+// float32LEToFloat32 把小端序 32 位浮点字节转换到 dst，len(data) 必须等于 4*len(dst)
+func float32LEToFloat32(dst []float32, data []byte) {
+	le := binary.LittleEndian
+	n := len(dst) &^ 7
+	for i := 0; i < n; i += 8 {
+		b := (*[32]byte)(data[i*4:])
+		d := (*[8]float32)(dst[i:])
+		d[0] = math.Float32frombits(le.Uint32(b[0:]))
+		d[1] = math.Float32frombits(le.Uint32(b[4:]))
+		d[2] = math.Float32frombits(le.Uint32(b[8:]))
+		d[3] = math.Float32frombits(le.Uint32(b[12:]))
+		d[4] = math.Float32frombits(le.Uint32(b[16:]))
+		d[5] = math.Float32frombits(le.Uint32(b[20:]))
+		d[6] = math.Float32frombits(le.Uint32(b[24:]))
+		d[7] = math.Float32frombits(le.Uint32(b[28:]))
+	}
+	for i := n; i < len(dst); i++ {
+		dst[i] = math.Float32frombits(le.Uint32(data[i*4:]))
+	}
+}
@@ -0,0 +1,96 @@
+package audio
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPCMConversion(t *testing.T) {
+	t.Run("int16 round trip", func(t *testing.T) {
+		src := []int16{0, 16384, -16384, math.MaxInt16, math.MinInt16}
+		f := Int16ToFloat32(nil, src)
+		require.Equal(t, []float32{0, 0.5, -0.5, 32767.0 / 32768, -1}, f)
+		require.Equal(t, src, Float32ToInt16(nil, f))
+	})
+
+	t.Run("float32 to int16 clamps", func(t *testing.T) {
+		require.Equal(t, []int16{math.MaxInt16, math.MinInt16}, Float32ToInt16(nil, []float32{1.5, -2}))
+	})
+
+	t.Run("buffer reuse", func(t *testing.T) {
+		buf := make([]float32, 0, 8)
+		out := Int16ToFloat32(buf, []int16{1, 2, 3})
+		require.Len(t, out, 3)
+		require.Same(t, &buf[:1][0], &out[0])
+	})
+
+	t.Run("bytes", func(t *testing.T) {
+		le := []byte{0x00, 0x40, 0x00, 0xc0}
+		out, err := BytesToFloat32(nil, le, FormatPCM16, binary.LittleEndian)
+		require.NoError(t, err)
+		require.Equal(t, []float32{0.5, -0.5}, out)
+
+		be := []byte{0x40, 0x00, 0xc0, 0x00}
+		out, err = BytesToFloat32(out, be, FormatPCM16, binary.BigEndian)
+		require.NoError(t, err)
+		require.Equal(t, []float32{0.5, -0.5}, out)
+
+		f := make([]byte, 4)
+		binary.LittleEndian.PutUint32(f, math.Float32bits(0.125))
+		out, err = BytesToFloat32(nil, f, FormatFloat32, binary.LittleEndian)
+		require.NoError(t, err)
+		require.Equal(t, []float32{0.125}, out)
+
+		_, err = BytesToFloat32(nil, []byte{1, 2, 3}, FormatPCM16, binary.LittleEndian)
+		require.Error(t, err)
+	})
+	t.Run("parse format", func(t *testing.T) {
+		for f := FormatPCM16; f <= FormatFLAC; f++ {
+			got, err := ParseSampleFormat(f.String())
+			require.NoError(t, err)
+			require.Equal(t, f, got)
+		}
+		_, err := ParseSampleFormat("aac")
+		require.Error(t, err)
+	})
+}
+
+func TestPCMFastPaths(t *testing.T) {
+	// 覆盖批量部分和剩余部分，结果与逐个采样的转换一致
+	for _, n := range []int{1, 7, 8, 9, 31, 100} {
+		src := make([]int16, n)
+		le := make([]byte, 2*n)
+		be := make([]byte, 2*n)
+		f := make([]byte, 4*n)
+		want := make([]float32, n)
+		for i := range src {
+			src[i] = int16(i*7919 - 32768)
+			binary.LittleEndian.PutUint16(le[i*2:], uint16(src[i]))
+			binary.BigEndian.PutUint16(be[i*2:], uint16(src[i]))
+			want[i] = float32(src[i]) / 32768
+			binary.LittleEndian.PutUint32(f[i*4:], math.Float32bits(want[i]))
+		}
+
+		require.Equal(t, want, Int16ToFloat32(nil, src))
+		for _, tc := range []struct {
+			data   []byte
+			format SampleFormat
+			order  binary.ByteOrder
+		}{
+			{le, FormatPCM16, binary.LittleEndian},
+			{be, FormatPCM16, binary.BigEndian},
+			{f, FormatFloat32, binary.LittleEndian},
+		} {
+			out, err := BytesToFloat32(nil, tc.data, tc.format, tc.order)
+			require.NoError(t, err)
+			require.Equal(t, want, out, "%s %s n=%d", tc.format, tc.order, n)
+		}
+
+		out, err := DecodeWAVSamples(nil, le, WAVInfo{SampleRate: 16000, Channels: 1, BitsPerSample: 16})
+		require.NoError(t, err)
+		require.Equal(t, want, out)
+	}
+}
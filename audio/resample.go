@@ -0,0 +1,233 @@
+package audio
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// 重采样支持的采样率范围，超出范围的采样率通常来自损坏或恶意构造的文件头
+const (
+	MinResampleRate = 1000
+	MaxResampleRate = 384000
+)
+
+// ErrUnsupportedRate 采样率超出重采样支持的范围，或两个采样率的比例过于复杂
+var ErrUnsupportedRate = errors.New("unsupported sample rate")
+
+const (
+	// resampleTaps 插值时每个相位的滤波器长度，抽取时按抽取比例加长，
+	// 以保证过渡带相对于新的奈奎斯特频率足够窄
+	resampleTaps = 32
+	// resampleRolloff 截止频率相对奈奎斯特频率的比例，留出过渡带避免混叠
+	resampleRolloff = 0.92
+	// resampleKaiserBeta Kaiser 窗参数，约对应 80dB 阻带衰减
+	resampleKaiserBeta = 8.0
+	// maxResampleFactor 插值和抽取倍数的上限，限制滤波器系数的内存占用（最多约 2MB），
+	// 常见采样率之间的转换远低于这个值，例如 44100→16000 为 160/441
+	maxResampleFactor = 4096
+)
+
+// Resampler 基于加窗 sinc 的多相重采样器，支持任意整数采样率之间的转换
+// 例如 48000→16000、44100→16000、16000→8000。
+// Resampler 保存跨调用的滤波器历史，可以逐块处理流式音频；输出与输入在时间上对齐，
+// 代价是约半个滤波器长度的固定延迟，需要调用 Flush 取出尾部数据。
+// Resampler 不是并发安全的。
+type Resampler struct {
+	inRate  int
+	outRate int
+	up      int // 插值倍数 L
+	down    int // 抽取倍数 M
+	taps    int // 每个相位的滤波器长度
+	phases  [][]float32
+
+	buf   []float32 // 滤波器历史 + 尚未消耗的输入
+	idx   int       // 下一个输出对应的 buf 下标
+	phase int       // 下一个输出对应的相位
+}
+
+// CheckResampleRate 检查采样率是否在 [MinResampleRate, MaxResampleRate] 范围内，超出时返回包含 ErrUnsupportedRate 的错误
+// 用于在分配缓冲区和创建重采样器之前校验来自文件头或请求参数的采样率。
+func CheckResampleRate(rate int) error {
+	if rate < MinResampleRate || rate > MaxResampleRate {
+		return fmt.Errorf("%w: %d Hz (supported range is %d-%d Hz)", ErrUnsupportedRate, rate, MinResampleRate, MaxResampleRate)
+	}
+	return nil
+}
+
+// NewResampler 创建从 inRate 到 outRate 的重采样器
+// 采样率需在 CheckResampleRate 允许的范围内，且约分后的插值和抽取倍数不超过 4096，否则返回 ErrUnsupportedRate。
+func NewResampler(inRate, outRate int) (*Resampler, error) {
+	if err := CheckResampleRate(inRate); err != nil {
+		return nil, err
+	}
+	if err := CheckResampleRate(outRate); err != nil {
+		return nil, err
+	}
+
+	g := gcd(inRate, outRate)
+	r := &Resampler{
+		inRate:  inRate,
+		outRate: outRate,
+		up:      outRate / g,
+		down:    inRate / g,
+	}
+	if r.up > maxResampleFactor || r.down > maxResampleFactor {
+		return nil, fmt.Errorf("%w: conversion %d -> %d needs a %d/%d polyphase filter", ErrUnsupportedRate, inRate, outRate, r.up, r.down)
+	}
+	r.taps = resampleTaps * ((r.down + r.up - 1) / r.up)
+	r.phases = designPolyphase(r.up, r.down, r.taps)
+	r.Reset()
+
+	return r, nil
+}
+
+// InRate 返回输入采样率
+func (r *Resampler) InRate() int {
+	return r.inRate
+}
+
+// OutRate 返回输出采样率
+func (r *Resampler) OutRate() int {
+	return r.outRate
+}
+
+// Reset 清空滤波器历史，用于开始处理新的音频流
+func (r *Resampler) Reset() {
+	r.buf = make([]float32, r.taps-1, r.taps*4)
+	// 将起点偏移半个滤波器长度以补偿线性相位延迟，使输出与输入对齐
+	delay := (r.taps*r.up - 1) / 2
+	r.idx = r.taps - 1 + delay/r.up
+	r.phase = delay % r.up
+}
+
+// Clone 返回当前滤波器历史和相位的副本，滤波器系数与 r 共享
+func (r *Resampler) Clone() *Resampler {
+	c := *r
+	c.buf = append(make([]float32, 0, cap(r.buf)), r.buf...)
+	return &c
+}
+
+// Process 处理一块输入并把产生的输出追加到 dst 后返回
+func (r *Resampler) Process(dst, src []float32) []float32 {
+	if r.up == r.down {
+		return append(dst, src...)
+	}
+
+	r.buf = append(r.buf, src...)
+	for r.idx < len(r.buf) {
+		taps := r.phases[r.phase]
+		window := r.buf[r.idx-r.taps+1 : r.idx+1]
+
+		var acc float32
+		for k, h := range taps {
+			acc += h * window[r.taps-1-k]
+		}
+		dst = append(dst, acc)
+
+		r.phase += r.down
+		r.idx += r.phase / r.up
+		r.phase %= r.up
+	}
+
+	// 只保留下一次计算需要的历史
+	consumed := r.idx - (r.taps - 1)
+	if consumed > len(r.buf) {
+		consumed = len(r.buf)
+	}
+	n := copy(r.buf, r.buf[consumed:])
+	r.buf = r.buf[:n]
+	r.idx -= consumed
+
+	return dst
+}
+
+// Flush 输入补零以取出滤波器延迟中剩余的输出，之后应调用 Reset 再处理新数据
+func (r *Resampler) Flush(dst []float32) []float32 {
+	if r.up == r.down {
+		return dst
+	}
+	return r.Process(dst, make([]float32, r.taps))
+}
+
+// Resample 一次性转换整段音频的采样率
+func Resample(src []float32, inRate, outRate int) ([]float32, error) {
+	r, err := NewResampler(inRate, outRate)
+	if err != nil {
+		return nil, err
+	}
+
+	outLen := int((int64(len(src))*int64(r.up) + int64(r.down) - 1) / int64(r.down))
+	dst := make([]float32, 0, outLen+r.taps)
+	dst = r.Process(dst, src)
+	dst = r.Flush(dst)
+	if len(dst) > outLen {
+		dst = dst[:outLen]
+	}
+
+	return dst, nil
+}
+
+// designPolyphase 设计插值 up、抽取 down 的低通原型滤波器并拆分为 up 个相位
+func designPolyphase(up, down, taps int) [][]float32 {
+	n := taps * up
+	cutoff := resampleRolloff * 0.5 / float64(max(up, down))
+	// 中心取整数位置，使滤波器延迟恰好是整数个插值采样，便于 Reset 中精确补偿
+	center := (n - 1) / 2
+
+	proto := make([]float64, n)
+	var sum float64
+	for j := range proto {
+		x := float64(j - center)
+		proto[j] = 2 * cutoff * sinc(2*cutoff*x) * kaiser(x, float64(center), resampleKaiserBeta)
+		sum += proto[j]
+	}
+
+	// 归一化使每个相位的直流增益为 1
+	scale := float64(up) / sum
+	phases := make([][]float32, up)
+	for p := range phases {
+		phases[p] = make([]float32, taps)
+		for k := range phases[p] {
+			phases[p][k] = float32(proto[p+k*up] * scale)
+		}
+	}
+
+	return phases
+}
+
+func sinc(x float64) float64 {
+	if x == 0 {
+		return 1
+	}
+	return math.Sin(math.Pi*x) / (math.Pi * x)
+}
+
+// kaiser 返回以 0 为中心、半宽为 half 的 Kaiser 窗在 x 处的值
+func kaiser(x, half, beta float64) float64 {
+	r := x / half
+	if r < -1 || r > 1 {
+		return 0
+	}
+	return besselI0(beta*math.Sqrt(1-r*r)) / besselI0(beta)
+}
+
+// besselI0 第一类零阶修正贝塞尔函数的级数展开
+func besselI0(x float64) float64 {
+	sum, term := 1.0, 1.0
+	for k := 1; k < 50; k++ {
+		term *= (x / 2) / float64(k)
+		sum += term * term
+		if term*term < sum*1e-12 {
+			break
+		}
+	}
+	return sum
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
@@ -0,0 +1,81 @@
+package audio
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func sine(freq float64, rate, n int) []float32 {
+	out := make([]float32, n)
+	for i := range out {
+		out[i] = float32(0.5 * math.Sin(2*math.Pi*freq*float64(i)/float64(rate)))
+	}
+	return out
+}
+
+func TestResample(t *testing.T) {
+	for _, tc := range []struct {
+		in, out int
+	}{
+		{48000, 16000},
+		{44100, 16000},
+		{16000, 8000},
+		{8000, 16000},
+	} {
+		src := sine(440, tc.in, tc.in/2)
+		got, err := Resample(src, tc.in, tc.out)
+		require.NoError(t, err)
+		require.Len(t, got, tc.out/2)
+
+		// 去掉边缘的滤波器暖机区域后应与理想正弦一致
+		want := sine(440, tc.out, tc.out/2)
+		for i := 64; i < len(got)-64; i++ {
+			require.InDelta(t, want[i], got[i], 2e-3, "%d->%d sample %d", tc.in, tc.out, i)
+		}
+	}
+
+	t.Run("aliasing is suppressed", func(t *testing.T) {
+		// 7kHz 在 48k->8k 时高于新的奈奎斯特频率，应被滤除
+		got, err := Resample(sine(7000, 48000, 48000), 48000, 8000)
+		require.NoError(t, err)
+
+		var energy float64
+		for _, v := range got[100 : len(got)-100] {
+			energy += float64(v * v)
+		}
+		require.Less(t, math.Sqrt(energy/float64(len(got)-200)), 1e-3)
+	})
+
+	t.Run("streaming matches one-shot", func(t *testing.T) {
+		src := sine(300, 44100, 44100)
+		want, err := Resample(src, 44100, 16000)
+		require.NoError(t, err)
+
+		r, err := NewResampler(44100, 16000)
+		require.NoError(t, err)
+		var got []float32
+		for i := 0; i < len(src); i += 1000 {
+			got = r.Process(got, src[i:min(i+1000, len(src))])
+		}
+		got = r.Flush(got)
+		require.GreaterOrEqual(t, len(got), len(want))
+		require.Equal(t, want, got[:len(want)])
+	})
+
+	t.Run("invalid rates", func(t *testing.T) {
+		_, err := NewResampler(0, 16000)
+		require.ErrorIs(t, err, ErrUnsupportedRate)
+
+		// 过大、过小或比例过于复杂的采样率返回错误，而不是分配巨大的滤波器
+		for _, rates := range [][2]int{{2147483647, 16000}, {1, 16000}, {16000, 999}, {16000, 384001}, {16001, 16000}} {
+			_, err = NewResampler(rates[0], rates[1])
+			require.ErrorIs(t, err, ErrUnsupportedRate, rates)
+			_, err = Resample([]float32{0}, rates[0], rates[1])
+			require.ErrorIs(t, err, ErrUnsupportedRate, rates)
+		}
+		_, err = NewResampler(MaxResampleRate, MinResampleRate)
+		require.NoError(t, err)
+	})
+}
@@ -0,0 +1,99 @@
+package audio
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// RingBuffer 单生产者/单消费者的无锁 float32 环形缓冲
+// 生产者（通常是声卡回调线程）调用 Write，消费者（检测协程）调用 Read，
+// 两端都不加锁、不分配内存。多个生产者或多个消费者同时调用是不安全的。
+type RingBuffer struct {
+	buf  []float32
+	mask uint64
+
+	// 读写位置单调递增，各自只由一端修改；填充字段避免两者落在同一缓存行
+	_       [64]byte
+	head    atomic.Uint64 // 下一个要读取的位置，仅消费者修改
+	_       [56]byte
+	tail    atomic.Uint64 // 下一个要写入的位置，仅生产者修改
+	_       [56]byte
+	dropped atomic.Uint64 // 因缓冲已满被丢弃的采样数
+}
+
+// NewRingBuffer 创建能容纳至少 capacity 时长音频的环形缓冲
+// 实际容量会向上取整到 2 的幂。
+func NewRingBuffer(sampleRate int, capacity time.Duration) (*RingBuffer, error) {
+	if sampleRate <= 0 {
+		return nil, fmt.Errorf("invalid sample rate: %d", sampleRate)
+	}
+	if capacity <= 0 {
+		return nil, fmt.Errorf("invalid capacity: %s", capacity)
+	}
+
+	n := uint64((int64(capacity)*int64(sampleRate) + int64(time.Second) - 1) / int64(time.Second))
+	size := uint64(1)
+	for size < n {
+		size <<= 1
+	}
+
+	return &RingBuffer{
+		buf:  make([]float32, size),
+		mask: size - 1,
+	}, nil
+}
+
+// Cap 返回缓冲可容纳的采样数
+func (r *RingBuffer) Cap() int {
+	return len(r.buf)
+}
+
+// Len 返回当前可读取的采样数
+func (r *RingBuffer) Len() int {
+	return int(r.tail.Load() - r.head.Load())
+}
+
+// Write 写入尽可能多的采样并返回写入数量，仅供生产者调用
+// 缓冲已满时多出的采样会被丢弃并计入 Dropped，写入端永远不会阻塞。
+func (r *RingBuffer) Write(src []float32) int {
+	tail := r.tail.Load()
+	free := uint64(len(r.buf)) - (tail - r.head.Load())
+
+	n := uint64(len(src))
+	if n > free {
+		r.dropped.Add(n - free)
+		n = free
+	}
+
+	start := tail & r.mask
+	c := copy(r.buf[start:], src[:n])
+	copy(r.buf, src[c:n])
+
+	// 先写数据再发布位置，消费者看到新的 tail 时数据一定可见
+	r.tail.Store(tail + n)
+	return int(n)
+}
+
+// Read 读取最多 len(dst) 个采样并返回读取数量，仅供消费者调用
+func (r *RingBuffer) Read(dst []float32) int {
+	head := r.head.Load()
+	avail := r.tail.Load() - head
+
+	n := uint64(len(dst))
+	if n > avail {
+		n = avail
+	}
+
+	start := head & r.mask
+	c := copy(dst[:n], r.buf[start:])
+	copy(dst[c:n], r.buf)
+
+	r.head.Store(head + n)
+	return int(n)
+}
+
+// Dropped 返回因缓冲已满而丢弃的采样总数，可用于监控消费者是否跟不上
+func (r *RingBuffer) Dropped() uint64 {
+	return r.dropped.Load()
+}
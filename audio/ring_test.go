@@ -0,0 +1,88 @@
+package audio
+
+import (
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRingBuffer(t *testing.T) {
+	_, err := NewRingBuffer(0, time.Second)
+	require.Error(t, err)
+	_, err = NewRingBuffer(16000, 0)
+	require.Error(t, err)
+
+	// 10ms@16kHz = 160 个采样，向上取整到 256
+	r, err := NewRingBuffer(16000, 10*time.Millisecond)
+	require.NoError(t, err)
+	require.Equal(t, 256, r.Cap())
+
+	src := make([]float32, 200)
+	for i := range src {
+		src[i] = float32(i)
+	}
+	dst := make([]float32, 200)
+
+	// 多次读写使位置跨过缓冲末尾
+	for round := 0; round < 5; round++ {
+		require.Equal(t, 200, r.Write(src))
+		require.Equal(t, 200, r.Len())
+		require.Equal(t, 200, r.Read(dst))
+		require.Equal(t, src, dst)
+	}
+
+	require.Equal(t, 200, r.Write(src))
+	require.Equal(t, 56, r.Write(src))
+	require.EqualValues(t, 144, r.Dropped())
+	require.Equal(t, 256, r.Len())
+
+	require.Equal(t, 200, r.Read(dst))
+	require.Equal(t, src, dst)
+	require.Equal(t, 56, r.Read(dst))
+	require.Equal(t, src[:56], dst[:56])
+	require.Zero(t, r.Read(dst))
+}
+
+func TestRingBufferConcurrent(t *testing.T) {
+	r, err := NewRingBuffer(16000, 20*time.Millisecond)
+	require.NoError(t, err)
+
+	const total = 100000
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		chunk := make([]float32, 160)
+		next := 0
+		for next < total {
+			n := min(len(chunk), total-next)
+			for i := 0; i < n; i++ {
+				chunk[i] = float32(next + i)
+			}
+			// 空间不足时等待消费者，保证不丢数据以便校验顺序
+			for r.Cap()-r.Len() < n {
+				runtime.Gosched()
+			}
+			require.Equal(t, n, r.Write(chunk[:n]))
+			next += n
+		}
+	}()
+
+	got := 0
+	buf := make([]float32, 512)
+	for got < total {
+		n := r.Read(buf)
+		if n == 0 {
+			runtime.Gosched()
+		}
+		for i := 0; i < n; i++ {
+			require.Equal(t, float32(got+i), buf[i])
+		}
+		got += n
+	}
+	wg.Wait()
+	require.Zero(t, r.Dropped())
+}
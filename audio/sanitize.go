@@ -0,0 +1,60 @@
+package audio
+
+import "math"
+
+const (
+	float32ExpMask  = 0x7f800000
+	float32FracMask = 0x007fffff
+)
+
+// FindNonFinite 返回第一个 NaN 或 ±Inf 采样的下标，全部为有限值时返回 -1
+func FindNonFinite(pcm []float32) int {
+	for i, v := range pcm {
+		if math.Float32bits(v)&float32ExpMask == float32ExpMask {
+			return i
+		}
+	}
+	return -1
+}
+
+// needsSanitize 返回 v 是否为 NaN、±Inf 或非规格化数
+func needsSanitize(v float32) bool {
+	bits := math.Float32bits(v)
+	exp := bits & float32ExpMask
+	return exp == float32ExpMask || (exp == 0 && bits&float32FracMask != 0)
+}
+
+// Sanitize 把 src 复制到 dst 中并修正异常采样：NaN 和非规格化数替换为 0，±Inf 截断为 ±1
+// 返回结果和被修正的采样数。dst 容量不足时重新分配；没有异常采样时返回 src 本身，不做复制。
+func Sanitize(dst, src []float32) ([]float32, int) {
+	first := -1
+	for i, v := range src {
+		if needsSanitize(v) {
+			first = i
+			break
+		}
+	}
+	if first < 0 {
+		return src, 0
+	}
+
+	dst = grow(dst, len(src))
+	copy(dst, src[:first])
+	fixed := 0
+	for i := first; i < len(src); i++ {
+		v := src[i]
+		if needsSanitize(v) {
+			fixed++
+			switch {
+			case math.IsInf(float64(v), 1):
+				v = 1
+			case math.IsInf(float64(v), -1):
+				v = -1
+			default:
+				v = 0
+			}
+		}
+		dst[i] = v
+	}
+	return dst, fixed
+}
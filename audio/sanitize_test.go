@@ -0,0 +1,30 @@
+package audio
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSanitize(t *testing.T) {
+	nan := float32(math.NaN())
+	inf := float32(math.Inf(1))
+	denormal := math.Float32frombits(1)
+
+	clean := []float32{0, 0.5, -1, 1e-30}
+	require.Equal(t, -1, FindNonFinite(clean))
+	out, n := Sanitize(nil, clean)
+	require.Zero(t, n)
+	require.Equal(t, &clean[0], &out[0], "clean input must not be copied")
+
+	in := []float32{0.25, nan, -inf, inf, denormal, -0.5}
+	require.Equal(t, 1, FindNonFinite(in))
+	require.Zero(t, FindNonFinite(in[2:3]))
+	require.Equal(t, -1, FindNonFinite(in[4:]), "denormals are finite")
+
+	out, n = Sanitize(make([]float32, 0, 2), in)
+	require.Equal(t, 4, n)
+	require.Equal(t, []float32{0.25, 0, -1, 1, 0, -0.5}, out)
+	require.True(t, math.IsNaN(float64(in[1])), "input must not be modified")
+}
@@ -0,0 +1,446 @@
+// Package audio 提供语音检测前后常用的音频读写与处理工具。
+package audio
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+)
+
+// WAV 格式标签
+const (
+	wavFormatPCM        = 0x0001
+	wavFormatFloat      = 0x0003
+	wavFormatALaw       = 0x0006
+	wavFormatULaw       = 0x0007
+	wavFormatExtensible = 0xFFFE
+)
+
+// maxFmtChunkSize fmt 块的长度上限，WAVE_FORMAT_EXTENSIBLE 为 40 字节，留出余量
+const maxFmtChunkSize = 64
+
+// ErrInvalidWAV 输入不是可识别的 WAV 数据
+var ErrInvalidWAV = errors.New("invalid wav data")
+
+// WAVInfo 描述 WAV 文件的格式信息
+type WAVInfo struct {
+	// 采样率，单位 Hz
+	SampleRate int
+	// 声道数，多声道数据按交错方式存储
+	Channels int
+	// 每个采样点的位数
+	BitsPerSample int
+	// 采样点是否为浮点格式
+	Float bool
+	// 8 位 G.711 编码（FormatULaw 或 FormatALaw），线性 PCM 时为 0
+	Companding SampleFormat
+}
+
+// ReadWAVFile 读取 WAV 文件，参见 ReadWAV
+func ReadWAVFile(path string) ([]float32, WAVInfo, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, WAVInfo{}, err
+	}
+	defer f.Close()
+
+	return ReadWAV(f)
+}
+
+// ReadWAV 解析 WAV 数据并返回归一化到 [-1, 1] 的 float32 采样点
+// 支持 8/16/24/32 位整数 PCM、32/64 位浮点以及 G.711 µ-law/A-law 格式，
+// 多声道数据保持交错排列。
+func ReadWAV(r io.Reader) ([]float32, WAVInfo, error) {
+	br := bufio.NewReader(r)
+
+	info, size, err := readWAVHeader(br)
+	if err != nil {
+		return nil, WAVInfo{}, err
+	}
+
+	// 声明的长度来自文件头，不可信：按实际读到的数据分配，而不是预先分配 size 字节。
+	// 部分录音程序不会回填 data 长度，读到 EOF 时容忍截断的数据
+	data, err := io.ReadAll(io.LimitReader(br, int64(size)))
+	if err != nil {
+		return nil, WAVInfo{}, fmt.Errorf("%w: failed to read data chunk: %w", ErrInvalidWAV, err)
+	}
+	samples, err := DecodeWAVSamples(nil, data, info)
+	if err != nil {
+		return nil, WAVInfo{}, err
+	}
+	return samples, info, nil
+}
+
+// ParseWAV 解析内存中（例如 MapFile 映射）的 WAV 数据，返回格式信息和 data 块的原始字节
+// 返回的切片引用 data 而不复制，可以配合 DecodeWAVSamples 分段解码。
+func ParseWAV(data []byte) (WAVInfo, []byte, error) {
+	r := bytes.NewReader(data)
+	info, size, err := readWAVHeader(r)
+	if err != nil {
+		return WAVInfo{}, nil, err
+	}
+
+	off := len(data) - r.Len()
+	end := min(off+int(size), len(data))
+	return info, data[off:end], nil
+}
+
+// readWAVHeader 读取到 data 块的开头，返回格式信息和 data 块声明的字节数
+func readWAVHeader(r io.Reader) (WAVInfo, uint32, error) {
+	var header [12]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return WAVInfo{}, 0, fmt.Errorf("%w: failed to read riff header: %w", ErrInvalidWAV, err)
+	}
+	if string(header[0:4]) != "RIFF" || string(header[8:12]) != "WAVE" {
+		return WAVInfo{}, 0, fmt.Errorf("%w: missing RIFF/WAVE header", ErrInvalidWAV)
+	}
+
+	var (
+		info      WAVInfo
+		format    uint16
+		hasFormat bool
+	)
+	for {
+		var chunk [8]byte
+		if _, err := io.ReadFull(r, chunk[:]); err != nil {
+			return WAVInfo{}, 0, fmt.Errorf("%w: missing data chunk: %w", ErrInvalidWAV, err)
+		}
+		id := string(chunk[0:4])
+		size := binary.LittleEndian.Uint32(chunk[4:8])
+
+		switch id {
+		case "fmt ":
+			if size < 16 {
+				return WAVInfo{}, 0, fmt.Errorf("%w: fmt chunk too short", ErrInvalidWAV)
+			}
+			if size > maxFmtChunkSize {
+				return WAVInfo{}, 0, fmt.Errorf("%w: fmt chunk too long (%d bytes)", ErrInvalidWAV, size)
+			}
+			data := make([]byte, size)
+			if _, err := io.ReadFull(r, data); err != nil {
+				return WAVInfo{}, 0, fmt.Errorf("%w: failed to read fmt chunk: %w", ErrInvalidWAV, err)
+			}
+			format = binary.LittleEndian.Uint16(data[0:2])
+			info.Channels = int(binary.LittleEndian.Uint16(data[2:4]))
+			info.SampleRate = int(binary.LittleEndian.Uint32(data[4:8]))
+			info.BitsPerSample = int(binary.LittleEndian.Uint16(data[14:16]))
+			// WAVE_FORMAT_EXTENSIBLE 的真实格式位于子格式 GUID 的前两个字节
+			if format == wavFormatExtensible && size >= 26 {
+				format = binary.LittleEndian.Uint16(data[24:26])
+			}
+			hasFormat = true
+		case "data":
+			if !hasFormat {
+				return WAVInfo{}, 0, fmt.Errorf("%w: data chunk before fmt chunk", ErrInvalidWAV)
+			}
+			if err := info.setFormat(format); err != nil {
+				return WAVInfo{}, 0, err
+			}
+			return info, size, nil
+		default:
+			if _, err := io.CopyN(io.Discard, r, int64(size)); err != nil {
+				return WAVInfo{}, 0, fmt.Errorf("%w: failed to skip %q chunk: %w", ErrInvalidWAV, id, err)
+			}
+		}
+
+		// RIFF 块按偶数字节对齐
+		if size%2 == 1 {
+			if _, err := io.CopyN(io.Discard, r, 1); err != nil {
+				return WAVInfo{}, 0, fmt.Errorf("%w: %w", ErrInvalidWAV, err)
+			}
+		}
+	}
+}
+
+// setFormat 校验格式标签与位深的组合
+func (info *WAVInfo) setFormat(format uint16) error {
+	if info.Channels <= 0 {
+		return fmt.Errorf("%w: invalid channel count %d", ErrInvalidWAV, info.Channels)
+	}
+	if info.SampleRate <= 0 {
+		return fmt.Errorf("%w: invalid sample rate %d", ErrInvalidWAV, info.SampleRate)
+	}
+
+	switch format {
+	case wavFormatPCM:
+		switch info.BitsPerSample {
+		case 8, 16, 24, 32:
+			return nil
+		}
+	case wavFormatFloat:
+		switch info.BitsPerSample {
+		case 32, 64:
+			info.Float = true
+			return nil
+		}
+	case wavFormatULaw, wavFormatALaw:
+		if info.BitsPerSample == 8 {
+			info.Companding = FormatULaw
+			if format == wavFormatALaw {
+				info.Companding = FormatALaw
+			}
+			return nil
+		}
+	default:
+		return fmt.Errorf("%w: unsupported format tag 0x%04x", ErrInvalidWAV, format)
+	}
+
+	return fmt.Errorf("%w: unsupported bits per sample %d", ErrInvalidWAV, info.BitsPerSample)
+}
+
+// DecodeWAVSamples 把 WAV data 块中的字节解码为 float32 采样
+// 结果写入 dst（容量不足时重新分配）并返回，末尾不完整的采样会被忽略，多声道数据保持交错排列。
+func DecodeWAVSamples(dst []float32, data []byte, info WAVInfo) ([]float32, error) {
+	switch info.Companding {
+	case FormatULaw:
+		return DecodeULaw(dst, data), nil
+	case FormatALaw:
+		return DecodeALaw(dst, data), nil
+	}
+
+	bytesPerSample := info.BitsPerSample / 8
+	if bytesPerSample == 0 {
+		return nil, fmt.Errorf("%w: unsupported bits per sample %d", ErrInvalidWAV, info.BitsPerSample)
+	}
+	n := len(data) / bytesPerSample
+	samples := grow(dst, n)
+
+	// 最常见的 16 位 PCM 和 32 位浮点走批量转换
+	switch {
+	case !info.Float && info.BitsPerSample == 16:
+		pcm16LEToFloat32(samples, data[:n*2])
+		return samples, nil
+	case info.Float && info.BitsPerSample == 32:
+		float32LEToFloat32(samples, data[:n*4])
+		return samples, nil
+	}
+
+	for i := 0; i < n; i++ {
+		b := data[i*bytesPerSample : (i+1)*bytesPerSample]
+		switch {
+		case info.Float && info.BitsPerSample == 32:
+			samples[i] = math.Float32frombits(binary.LittleEndian.Uint32(b))
+		case info.Float && info.BitsPerSample == 64:
+			samples[i] = float32(math.Float64frombits(binary.LittleEndian.Uint64(b)))
+		case info.BitsPerSample == 8:
+			// 8 位 PCM 是无符号数
+			samples[i] = float32(int(b[0])-128) / 128
+		case info.BitsPerSample == 16:
+			samples[i] = float32(int16(binary.LittleEndian.Uint16(b))) / 32768
+		case info.BitsPerSample == 24:
+			v := int32(uint32(b[0])<<8|uint32(b[1])<<16|uint32(b[2])<<24) >> 8
+			samples[i] = float32(v) / 8388608
+		case info.BitsPerSample == 32:
+			samples[i] = float32(float64(int32(binary.LittleEndian.Uint32(b))) / 2147483648)
+		default:
+			return nil, fmt.Errorf("%w: unsupported bits per sample %d", ErrInvalidWAV, info.BitsPerSample)
+		}
+	}
+
+	return samples, nil
+}
+
+// WriteWAVFile 将采样点写入 WAV 文件，参见 WriteWAV
+func WriteWAVFile(path string, samples []float32, info WAVInfo) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := WriteWAV(f, samples, info); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// WriteWAV 将 [-1, 1] 范围的采样点编码为 WAV 数据
+// info.BitsPerSample 为 0 时使用 16 位 PCM；info.Float 为 true 时写入 32 位浮点。
+// 超出范围的整数采样会被截断。
+func WriteWAV(w io.Writer, samples []float32, info WAVInfo) error {
+	info, format, err := wavWriteInfo(info)
+	if err != nil {
+		return err
+	}
+
+	bytesPerSample := info.BitsPerSample / 8
+	bw := bufio.NewWriter(w)
+	if _, err := bw.Write(wavHeader(info, format, len(samples)*bytesPerSample)); err != nil {
+		return err
+	}
+
+	b := make([]byte, bytesPerSample)
+	for _, s := range samples {
+		encodeWAVSample(b, s, info)
+		if _, err := bw.Write(b); err != nil {
+			return err
+		}
+	}
+
+	return bw.Flush()
+}
+
+// wavWriteInfo 填充写入时的默认格式并校验，返回 fmt 块中的格式代码
+func wavWriteInfo(info WAVInfo) (WAVInfo, uint16, error) {
+	if info.Channels == 0 {
+		info.Channels = 1
+	}
+	if info.BitsPerSample == 0 {
+		info.BitsPerSample = 16
+		if info.Float {
+			info.BitsPerSample = 32
+		}
+	}
+
+	if info.Companding != 0 {
+		return info, 0, fmt.Errorf("%w: writing %s is not supported", ErrInvalidWAV, info.Companding)
+	}
+
+	format := uint16(wavFormatPCM)
+	if info.Float {
+		format = wavFormatFloat
+	}
+	if err := info.setFormat(format); err != nil {
+		return info, 0, err
+	}
+	if info.Float && info.BitsPerSample != 32 {
+		return info, 0, fmt.Errorf("%w: unsupported float bits per sample %d", ErrInvalidWAV, info.BitsPerSample)
+	}
+	return info, format, nil
+}
+
+// wavHeader 返回数据长度为 dataLen 字节的 44 字节 WAV 文件头
+func wavHeader(info WAVInfo, format uint16, dataLen int) []byte {
+	bytesPerSample := info.BitsPerSample / 8
+	header := make([]byte, 44)
+	le := binary.LittleEndian
+	copy(header[0:4], "RIFF")
+	le.PutUint32(header[4:8], uint32(36+dataLen))
+	copy(header[8:12], "WAVE")
+	copy(header[12:16], "fmt ")
+	le.PutUint32(header[16:20], 16)
+	le.PutUint16(header[20:22], format)
+	le.PutUint16(header[22:24], uint16(info.Channels))
+	le.PutUint32(header[24:28], uint32(info.SampleRate))
+	le.PutUint32(header[28:32], uint32(info.SampleRate*info.Channels*bytesPerSample))
+	le.PutUint16(header[32:34], uint16(info.Channels*bytesPerSample))
+	le.PutUint16(header[34:36], uint16(info.BitsPerSample))
+	copy(header[36:40], "data")
+	le.PutUint32(header[40:44], uint32(dataLen))
+	return header
+}
+
+// WAVWriter 以流式方式写入 WAV 数据，适合事先不知道长度的录音
+// 文件头先以零长度写入，Close 时回写实际长度，因此底层须支持 Seek。WAVWriter 不是并发安全的。
+type WAVWriter struct {
+	w       io.WriteSeeker
+	bw      *bufio.Writer
+	info    WAVInfo
+	format  uint16
+	buf     []byte
+	dataLen int
+	closed  bool
+}
+
+// NewWAVWriter 写入文件头并创建 WAVWriter，info 的默认值同 WriteWAV
+func NewWAVWriter(w io.WriteSeeker, info WAVInfo) (*WAVWriter, error) {
+	if w == nil {
+		return nil, fmt.Errorf("invalid nil writer")
+	}
+	info, format, err := wavWriteInfo(info)
+	if err != nil {
+		return nil, err
+	}
+
+	ww := &WAVWriter{w: w, bw: bufio.NewWriter(w), info: info, format: format}
+	if _, err := ww.bw.Write(wavHeader(info, format, 0)); err != nil {
+		return nil, err
+	}
+	return ww, nil
+}
+
+// Write 追加 [-1, 1] 范围的采样点，多声道时须为交错数据
+func (ww *WAVWriter) Write(samples []float32) error {
+	if ww.closed {
+		return fmt.Errorf("wav writer closed")
+	}
+	bytesPerSample := ww.info.BitsPerSample / 8
+	if cap(ww.buf) < len(samples)*bytesPerSample {
+		ww.buf = make([]byte, len(samples)*bytesPerSample)
+	}
+	b := ww.buf[:len(samples)*bytesPerSample]
+	for i, s := range samples {
+		encodeWAVSample(b[i*bytesPerSample:(i+1)*bytesPerSample], s, ww.info)
+	}
+	if _, err := ww.bw.Write(b); err != nil {
+		return err
+	}
+	ww.dataLen += len(b)
+	return nil
+}
+
+// Samples 返回已写入的采样点数（多声道时为各声道之和）
+func (ww *WAVWriter) Samples() int {
+	return ww.dataLen / (ww.info.BitsPerSample / 8)
+}
+
+// Close 写出缓冲的数据并回写文件头中的长度，不关闭底层 writer，重复调用是安全的
+func (ww *WAVWriter) Close() error {
+	if ww.closed {
+		return nil
+	}
+	ww.closed = true
+	if err := ww.bw.Flush(); err != nil {
+		return err
+	}
+	if _, err := ww.w.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if _, err := ww.w.Write(wavHeader(ww.info, ww.format, ww.dataLen)); err != nil {
+		return err
+	}
+	_, err := ww.w.Seek(0, io.SeekEnd)
+	return err
+}
+
+func encodeWAVSample(b []byte, s float32, info WAVInfo) {
+	if info.Float {
+		binary.LittleEndian.PutUint32(b, math.Float32bits(s))
+		return
+	}
+
+	v := float64(s)
+	if v > 1 {
+		v = 1
+	} else if v < -1 {
+		v = -1
+	}
+
+	switch info.BitsPerSample {
+	case 8:
+		b[0] = byte(clampInt(int64(math.Round(v*128))+128, 0, 255))
+	case 16:
+		binary.LittleEndian.PutUint16(b, uint16(clampInt(int64(math.Round(v*32768)), math.MinInt16, math.MaxInt16)))
+	case 24:
+		x := uint32(clampInt(int64(math.Round(v*8388608)), -8388608, 8388607))
+		b[0], b[1], b[2] = byte(x), byte(x>>8), byte(x>>16)
+	case 32:
+		binary.LittleEndian.PutUint32(b, uint32(clampInt(int64(math.Round(v*2147483648)), math.MinInt32, math.MaxInt32)))
+	}
+}
+
+func clampInt(v, lo, hi int64) int64 {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
@@ -0,0 +1,203 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// buildWAV 按给定格式拼装一个最小的 WAV 文件
+func buildWAV(format uint16, channels, sampleRate, bits int, data []byte) []byte {
+	var buf bytes.Buffer
+	le := binary.LittleEndian
+	buf.WriteString("RIFF")
+	_ = binary.Write(&buf, le, uint32(4+8+16+8+len(data)))
+	buf.WriteString("WAVE")
+	buf.WriteString("fmt ")
+	_ = binary.Write(&buf, le, uint32(16))
+	_ = binary.Write(&buf, le, format)
+	_ = binary.Write(&buf, le, uint16(channels))
+	_ = binary.Write(&buf, le, uint32(sampleRate))
+	_ = binary.Write(&buf, le, uint32(sampleRate*channels*bits/8))
+	_ = binary.Write(&buf, le, uint16(channels*bits/8))
+	_ = binary.Write(&buf, le, uint16(bits))
+	buf.WriteString("data")
+	_ = binary.Write(&buf, le, uint32(len(data)))
+	buf.Write(data)
+	return buf.Bytes()
+}
+
+func TestReadWAV(t *testing.T) {
+	le := binary.LittleEndian
+
+	t.Run("pcm16 stereo", func(t *testing.T) {
+		data := make([]byte, 8)
+		le.PutUint16(data[0:], uint16(16384))
+		le.PutUint16(data[2:], 0x8000) // -32768
+		le.PutUint16(data[4:], 0)
+		le.PutUint16(data[6:], uint16(32767))
+
+		samples, info, err := ReadWAV(bytes.NewReader(buildWAV(wavFormatPCM, 2, 16000, 16, data)))
+		require.NoError(t, err)
+		require.Equal(t, WAVInfo{SampleRate: 16000, Channels: 2, BitsPerSample: 16}, info)
+		require.InDeltaSlice(t, []float32{0.5, -1, 0, 32767.0 / 32768}, samples, 1e-6)
+	})
+
+	t.Run("pcm24", func(t *testing.T) {
+		data := []byte{0x00, 0x00, 0x40, 0x00, 0x00, 0xc0}
+
+		samples, info, err := ReadWAV(bytes.NewReader(buildWAV(wavFormatPCM, 1, 8000, 24, data)))
+		require.NoError(t, err)
+		require.Equal(t, 8000, info.SampleRate)
+		require.InDeltaSlice(t, []float32{0.5, -0.5}, samples, 1e-6)
+	})
+
+	t.Run("pcm32", func(t *testing.T) {
+		data := make([]byte, 4)
+		le.PutUint32(data, uint32(1<<30))
+
+		samples, _, err := ReadWAV(bytes.NewReader(buildWAV(wavFormatPCM, 1, 16000, 32, data)))
+		require.NoError(t, err)
+		require.InDeltaSlice(t, []float32{0.5}, samples, 1e-6)
+	})
+
+	t.Run("float32", func(t *testing.T) {
+		data := make([]byte, 8)
+		le.PutUint32(data[0:], math.Float32bits(0.25))
+		le.PutUint32(data[4:], math.Float32bits(-0.75))
+
+		samples, info, err := ReadWAV(bytes.NewReader(buildWAV(wavFormatFloat, 1, 16000, 32, data)))
+		require.NoError(t, err)
+		require.True(t, info.Float)
+		require.Equal(t, []float32{0.25, -0.75}, samples)
+	})
+
+	t.Run("float64", func(t *testing.T) {
+		data := make([]byte, 8)
+		le.PutUint64(data, math.Float64bits(-0.5))
+
+		samples, _, err := ReadWAV(bytes.NewReader(buildWAV(wavFormatFloat, 1, 16000, 64, data)))
+		require.NoError(t, err)
+		require.Equal(t, []float32{-0.5}, samples)
+	})
+
+	t.Run("unsupported format", func(t *testing.T) {
+		_, _, err := ReadWAV(bytes.NewReader(buildWAV(0x0055, 1, 16000, 16, nil)))
+		require.ErrorIs(t, err, ErrInvalidWAV)
+	})
+
+	t.Run("not a wav", func(t *testing.T) {
+		_, _, err := ReadWAV(bytes.NewReader([]byte("definitely not a riff file")))
+		require.ErrorIs(t, err, ErrInvalidWAV)
+	})
+
+	t.Run("oversized chunk sizes", func(t *testing.T) {
+		// 头部声明约 4GB 的 data 块，只按实际数据分配
+		wav := buildWAV(wavFormatPCM, 1, 16000, 16, []byte{0, 0x40})
+		binary.LittleEndian.PutUint32(wav[40:44], 0xFFFFFFF0)
+		samples, _, err := ReadWAV(bytes.NewReader(wav))
+		require.NoError(t, err)
+		require.Equal(t, []float32{0.5}, samples)
+
+		wav = buildWAV(wavFormatPCM, 1, 16000, 16, nil)
+		binary.LittleEndian.PutUint32(wav[16:20], 0xFFFFFFF0)
+		_, _, err = ReadWAV(bytes.NewReader(wav))
+		require.ErrorIs(t, err, ErrInvalidWAV)
+		require.ErrorContains(t, err, "fmt chunk too long")
+	})
+}
+
+func TestParseWAV(t *testing.T) {
+	le := binary.LittleEndian
+	data := make([]byte, 6)
+	le.PutUint16(data[0:], uint16(16384))
+	le.PutUint16(data[2:], 0x8000)
+	le.PutUint16(data[4:], 0)
+	wav := buildWAV(wavFormatPCM, 1, 8000, 16, data)
+
+	info, pcm, err := ParseWAV(wav)
+	require.NoError(t, err)
+	require.Equal(t, WAVInfo{SampleRate: 8000, Channels: 1, BitsPerSample: 16}, info)
+	require.Equal(t, data, pcm)
+	// 返回的是原数据的子切片
+	require.Same(t, &wav[len(wav)-len(data)], &pcm[0])
+
+	// 分段解码与整体解码结果相同，dst 的空间被复用
+	all, err := DecodeWAVSamples(nil, pcm, info)
+	require.NoError(t, err)
+	require.Equal(t, []float32{0.5, -1, 0}, all)
+	part, err := DecodeWAVSamples(all[:0], pcm[2:5], info)
+	require.NoError(t, err)
+	require.Equal(t, []float32{-1}, part)
+	require.Same(t, &all[0], &part[0])
+
+	// 截断的 data 块
+	info, pcm, err = ParseWAV(wav[:len(wav)-2])
+	require.NoError(t, err)
+	require.Len(t, pcm, 4)
+
+	_, _, err = ParseWAV([]byte("RIFF"))
+	require.ErrorIs(t, err, ErrInvalidWAV)
+}
+
+func TestWriteWAV(t *testing.T) {
+	samples := []float32{0, 0.5, -0.5, 1, -1, 0.25}
+
+	for _, info := range []WAVInfo{
+		{SampleRate: 16000, Channels: 1},
+		{SampleRate: 8000, Channels: 2, BitsPerSample: 24},
+		{SampleRate: 16000, Channels: 1, BitsPerSample: 32},
+		{SampleRate: 16000, Channels: 1, Float: true},
+	} {
+		var buf bytes.Buffer
+		require.NoError(t, WriteWAV(&buf, samples, info))
+
+		got, gotInfo, err := ReadWAV(&buf)
+		require.NoError(t, err)
+		require.Equal(t, info.SampleRate, gotInfo.SampleRate)
+		require.Equal(t, info.Channels, gotInfo.Channels)
+		require.Equal(t, info.Float, gotInfo.Float)
+		require.InDeltaSlice(t, samples, got, 1.0/32767)
+	}
+
+	t.Run("file", func(t *testing.T) {
+		path := t.TempDir() + "/out.wav"
+		require.NoError(t, WriteWAVFile(path, samples, WAVInfo{SampleRate: 16000}))
+
+		got, info, err := ReadWAVFile(path)
+		require.NoError(t, err)
+		require.Equal(t, WAVInfo{SampleRate: 16000, Channels: 1, BitsPerSample: 16}, info)
+		require.Len(t, got, len(samples))
+	})
+}
+
+func TestWAVWriter(t *testing.T) {
+	samples := []float32{0, 0.5, -0.5, 1, -1, 0.25}
+	path := t.TempDir() + "/stream.wav"
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	_, err = NewWAVWriter(f, WAVInfo{SampleRate: 16000, Companding: FormatULaw})
+	require.Error(t, err)
+
+	w, err := NewWAVWriter(f, WAVInfo{SampleRate: 16000})
+	require.NoError(t, err)
+	require.NoError(t, w.Write(samples[:2]))
+	require.NoError(t, w.Write(samples[2:]))
+	require.Equal(t, len(samples), w.Samples())
+	require.NoError(t, w.Close())
+	require.NoError(t, w.Close())
+	require.Error(t, w.Write(samples))
+
+	// 与一次性写入的结果完全相同
+	var expected bytes.Buffer
+	require.NoError(t, WriteWAV(&expected, samples, WAVInfo{SampleRate: 16000}))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, expected.Bytes(), data)
+}
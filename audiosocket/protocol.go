@@ -0,0 +1,95 @@
+// Package audiosocket 实现 Asterisk AudioSocket 协议的服务端，使拨号方案可以把通话音频直接送入语音检测。
+//
+// 拨号方案示例（Asterisk 18+）：
+//
+//	exten => 100,1,Answer()
+//	 same => n,AudioSocket(40325ec2-5efd-4bd3-805f-53576e581d13,vad-host:9092)
+//
+// 服务端：
+//
+//	l, _ := net.Listen("tcp", ":9092")
+//	err := audiosocket.Serve(ctx, l, model, bridge.SinkFunc(func(ev bridge.Event) error {
+//		log.Printf("call %s speaking=%v", ev.Source, ev.Speaking)
+//		return nil
+//	}))
+//
+// 事件的 Source 为通话的 UUID，可据此通过 ARI/AMI 对对应通话执行打断等操作。
+package audiosocket
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Kind AudioSocket 消息类型
+type Kind byte
+
+const (
+	// KindHangup 挂断，没有负载
+	KindHangup Kind = 0x00
+	// KindID 通话标识，负载为 16 字节 UUID，是连接上的第一条消息
+	KindID Kind = 0x01
+	// KindAudio 音频，负载为 8kHz 单声道 16 位小端序线性 PCM
+	KindAudio Kind = 0x10
+	// KindError 错误，负载为 1 字节错误码
+	KindError Kind = 0xff
+)
+
+// SampleRate AudioSocket 音频的采样率
+const SampleRate = 8000
+
+// maxPayload 协议长度字段为 16 位
+const maxPayload = 1<<16 - 1
+
+// ErrProtocol 对端发送了不符合协议的数据
+var ErrProtocol = errors.New("audiosocket protocol error")
+
+// Message 一条 AudioSocket 消息
+type Message struct {
+	Kind    Kind
+	Payload []byte
+}
+
+// ReadMessage 读取一条消息：1 字节类型、2 字节大端序长度和负载
+// buf 用于存放负载以减少分配，返回的 Payload 可能引用 buf。
+func ReadMessage(r io.Reader, buf []byte) (Message, error) {
+	var header [3]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return Message{}, err
+	}
+
+	n := int(binary.BigEndian.Uint16(header[1:]))
+	if cap(buf) < n {
+		buf = make([]byte, n)
+	}
+	buf = buf[:n]
+	if _, err := io.ReadFull(r, buf); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return Message{}, fmt.Errorf("failed to read %d byte payload: %w", n, err)
+	}
+
+	return Message{Kind: Kind(header[0]), Payload: buf}, nil
+}
+
+// WriteMessage 写出一条消息
+func WriteMessage(w io.Writer, kind Kind, payload []byte) error {
+	if len(payload) > maxPayload {
+		return fmt.Errorf("payload too large: %d bytes", len(payload))
+	}
+
+	b := make([]byte, 3+len(payload))
+	b[0] = byte(kind)
+	binary.BigEndian.PutUint16(b[1:], uint16(len(payload)))
+	copy(b[3:], payload)
+	_, err := w.Write(b)
+	return err
+}
+
+// formatUUID 把 16 字节 UUID 格式化为 8-4-4-4-12 的十六进制形式
+func formatUUID(b []byte) string {
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
@@ -0,0 +1,143 @@
+package audiosocket
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"sync"
+
+	"github.com/rui-yang-me/silero-vad-go/audio"
+	"github.com/rui-yang-me/silero-vad-go/bridge"
+	"github.com/rui-yang-me/silero-vad-go/speech"
+)
+
+// Conn 一路 AudioSocket 通话，实现 bridge.AudioSource
+type Conn struct {
+	conn net.Conn
+	id   string
+	buf  []byte
+}
+
+var _ bridge.AudioSource = (*Conn)(nil)
+
+// NewConn 读取连接上的第一条消息（通话 UUID）并返回 Conn
+func NewConn(conn net.Conn) (*Conn, error) {
+	msg, err := ReadMessage(conn, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read call id: %w", err)
+	}
+	if msg.Kind != KindID || len(msg.Payload) != 16 {
+		return nil, fmt.Errorf("%w: expected 16 byte id message, got kind 0x%02x with %d bytes", ErrProtocol, byte(msg.Kind), len(msg.Payload))
+	}
+	return &Conn{conn: conn, id: formatUUID(msg.Payload)}, nil
+}
+
+// ID 返回通话 UUID
+func (c *Conn) ID() string {
+	return c.id
+}
+
+// SampleRate 实现 bridge.AudioSource，固定为 8kHz
+func (c *Conn) SampleRate() int {
+	return SampleRate
+}
+
+// ReadAudio 读取下一条音频消息，通话挂断或连接关闭时返回 io.EOF
+func (c *Conn) ReadAudio(dst []float32) ([]float32, error) {
+	for {
+		msg, err := ReadMessage(c.conn, c.buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				err = io.EOF
+			}
+			return dst, err
+		}
+		c.buf = msg.Payload[:0]
+
+		switch msg.Kind {
+		case KindAudio:
+			if len(msg.Payload)%2 != 0 {
+				return dst, fmt.Errorf("%w: odd audio payload length %d", ErrProtocol, len(msg.Payload))
+			}
+			pcm, err := audio.BytesToFloat32(dst[len(dst):], msg.Payload, audio.FormatPCM16, binary.LittleEndian)
+			if err != nil {
+				return dst, err
+			}
+			return append(dst, pcm...), nil
+		case KindHangup:
+			return dst, io.EOF
+		case KindError:
+			code := -1
+			if len(msg.Payload) > 0 {
+				code = int(msg.Payload[0])
+			}
+			return dst, fmt.Errorf("asterisk reported error code %d", code)
+		default:
+			// 忽略未知类型，便于兼容协议的扩展
+		}
+	}
+}
+
+// Hangup 请求 Asterisk 结束该 AudioSocket 会话
+func (c *Conn) Hangup() error {
+	return WriteMessage(c.conn, KindHangup, nil)
+}
+
+// Close 关闭连接
+func (c *Conn) Close() error {
+	return c.conn.Close()
+}
+
+// Serve 在 l 上接受 AudioSocket 连接，每路通话使用独立的检测上下文，事件发送到 sink
+// sink 可能被多个通话并发调用。ctx 取消时关闭 l 和所有连接并返回 nil。
+func Serve(ctx context.Context, l net.Listener, model *speech.SharedModel, sink bridge.EventSink) error {
+	if model == nil {
+		return fmt.Errorf("invalid nil shared model")
+	}
+
+	stop := context.AfterFunc(ctx, func() {
+		l.Close()
+	})
+	defer stop()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer conn.Close()
+
+			// ctx 取消时关闭连接，中断阻塞的读取
+			stopConn := context.AfterFunc(ctx, func() {
+				conn.Close()
+			})
+			defer stopConn()
+
+			if err := serveConn(ctx, conn, model, sink); err != nil && ctx.Err() == nil {
+				slog.Warn("audiosocket call failed", slog.String("remote", conn.RemoteAddr().String()), slog.Any("error", err))
+			}
+		}()
+	}
+}
+
+func serveConn(ctx context.Context, conn net.Conn, model *speech.SharedModel, sink bridge.EventSink) error {
+	c, err := NewConn(conn)
+	if err != nil {
+		return err
+	}
+	return bridge.Run(ctx, model, c, sink)
+}
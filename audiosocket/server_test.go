@@ -0,0 +1,131 @@
+package audiosocket
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rui-yang-me/silero-vad-go/audio"
+	"github.com/rui-yang-me/silero-vad-go/bridge"
+	"github.com/rui-yang-me/silero-vad-go/internal/testutil"
+	"github.com/rui-yang-me/silero-vad-go/speech"
+)
+
+var callID = []byte{0x40, 0x32, 0x5e, 0xc2, 0x5e, 0xfd, 0x4b, 0xd3, 0x80, 0x5f, 0x53, 0x57, 0x6e, 0x58, 0x1d, 0x13}
+
+func setup(t *testing.T) (*speech.SharedModel, []byte) {
+	t.Helper()
+
+	// 模拟 Asterisk 的 8kHz slin
+	r, err := audio.NewResampler(16000, SampleRate)
+	require.NoError(t, err)
+	slin := r.Flush(r.Process(nil, testutil.ReadSamples(t)))
+	pcm := make([]byte, 0, len(slin)*2)
+	for _, v := range audio.Float32ToInt16(nil, slin) {
+		pcm = binary.LittleEndian.AppendUint16(pcm, uint16(v))
+	}
+	return testutil.NewSharedModel(t), pcm
+}
+
+func TestMessage(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteMessage(&buf, KindID, callID))
+	require.NoError(t, WriteMessage(&buf, KindHangup, nil))
+	require.Equal(t, []byte{0x01, 0x00, 0x10}, buf.Bytes()[:3])
+
+	msg, err := ReadMessage(&buf, nil)
+	require.NoError(t, err)
+	require.Equal(t, KindID, msg.Kind)
+	require.Equal(t, callID, msg.Payload)
+	require.Equal(t, "40325ec2-5efd-4bd3-805f-53576e581d13", formatUUID(msg.Payload))
+
+	msg, err = ReadMessage(&buf, nil)
+	require.NoError(t, err)
+	require.Equal(t, KindHangup, msg.Kind)
+	require.Empty(t, msg.Payload)
+
+	_, err = ReadMessage(&buf, nil)
+	require.ErrorIs(t, err, io.EOF)
+
+	// 负载不完整
+	_, err = ReadMessage(bytes.NewReader([]byte{0x10, 0x01, 0x40, 0x00}), nil)
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+
+	require.Error(t, WriteMessage(io.Discard, KindAudio, make([]byte, maxPayload+1)))
+}
+
+func TestNewConn(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	go WriteMessage(client, KindAudio, make([]byte, 320))
+	_, err := NewConn(server)
+	require.ErrorIs(t, err, ErrProtocol)
+}
+
+func TestServe(t *testing.T) {
+	sm, pcm := setup(t)
+
+	// 参考结果：同样的 8kHz 音频直接通过 bridge.Run 检测
+	src, err := bridge.NewReaderSource("ref", bytes.NewReader(pcm), audio.FormatPCM16, SampleRate)
+	require.NoError(t, err)
+	var expected []bridge.Event
+	require.NoError(t, bridge.Run(context.Background(), sm, src, bridge.SinkFunc(func(ev bridge.Event) error {
+		ev.Source = ""
+		expected = append(expected, ev)
+		return nil
+	})))
+	require.NotEmpty(t, expected)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	var (
+		mu     sync.Mutex
+		events []bridge.Event
+		done   = make(chan struct{})
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() {
+		served <- Serve(ctx, l, sm, bridge.SinkFunc(func(ev bridge.Event) error {
+			mu.Lock()
+			defer mu.Unlock()
+			events = append(events, ev)
+			if len(events) == len(expected) {
+				close(done)
+			}
+			return nil
+		}))
+	}()
+
+	conn, err := net.Dial("tcp", l.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	require.NoError(t, WriteMessage(conn, KindID, callID))
+	// Asterisk 每 20ms 发送 320 字节
+	for i := 0; i < len(pcm); i += 320 {
+		require.NoError(t, WriteMessage(conn, KindAudio, pcm[i:min(i+320, len(pcm))]))
+	}
+	require.NoError(t, WriteMessage(conn, KindHangup, nil))
+
+	<-done
+	cancel()
+	require.NoError(t, <-served)
+
+	mu.Lock()
+	defer mu.Unlock()
+	for i := range events {
+		require.Equal(t, "40325ec2-5efd-4bd3-805f-53576e581d13", events[i].Source)
+		events[i].Source = ""
+	}
+	require.Equal(t, expected, events)
+}
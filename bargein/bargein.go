@@ -0,0 +1,179 @@
+// Package bargein 在语音机器人播放 TTS 时监听麦克风，用户一开口就触发打断事件。
+//
+// 播放状态由应用提供：开始播放时调用 SetPlaying(true)，播放结束或被打断后调用 SetPlaying(false)。
+// 麦克风音频持续写入 Monitor，不播放时也应写入，使检测状态保持连续：
+//
+//	m, err := bargein.NewMonitor(model, bargein.Config{MinSpeech: 96 * time.Millisecond})
+//	...
+//	m.SetPlaying(true)
+//	for frame := range mic {
+//		events, err := m.Write(frame)
+//		...
+//		if len(events) > 0 {
+//			tts.Stop()
+//			m.SetPlaying(false)
+//		}
+//	}
+//
+// 判定逐窗口进行，不等待语音片段的起点确认，延迟约为 MinSpeech 加一个窗口（16kHz 下 32ms）。
+package bargein
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/rui-yang-me/silero-vad-go/speech"
+)
+
+// defaultMinSpeech 未设置 Config.MinSpeech 时判定打断所需的连续语音时长
+const defaultMinSpeech = 64 * time.Millisecond
+
+// hysteresis 语音概率低于阈值减去该值后才重新允许触发，与语音片段结束的判定一致
+const hysteresis = 0.15
+
+// Config Monitor 的配置，两个字段共同决定灵敏度
+type Config struct {
+	// 判定为语音的概率阈值，0 表示使用模型配置的 Threshold；
+	// 扬声器回声较强时调高可以减少 TTS 自身触发的误打断
+	Threshold float32
+	// 连续多长的语音才判定为打断，按窗口向上取整，0 表示默认 64ms；
+	// 调大可以忽略咳嗽、附和声等短促声音，代价是更高的延迟
+	MinSpeech time.Duration
+}
+
+// Event 播放期间用户开始说话
+type Event struct {
+	// 用户开始说话的时间，即第一个语音窗口的起点，相对音频开始的秒数
+	Start float64
+	// 判定打断时已检测到的音频位置（秒），At 与 Start 之差为判定所需的音频时长
+	At float64
+	// 触发判定的窗口的语音概率
+	Probability float32
+}
+
+// Monitor 对一路麦克风音频做打断检测
+// Write 和 Reset 不是并发安全的；SetPlaying 可以在播放器的协程中调用。
+type Monitor struct {
+	cfg        Config
+	sampleRate int
+	minWindows int
+	dc         *speech.DetectorContext
+	chunker    *speech.StreamChunker
+
+	playing atomic.Bool
+	rearm   atomic.Bool // 开始新的播放后重新允许触发
+
+	// 以下只在 Write 中使用
+	threshold float32
+	run       int // 连续的语音窗口数
+	runStart  int // 连续语音开始的采样位置
+	armed     bool
+	events    []Event
+}
+
+// NewMonitor 创建打断检测器，音频须为模型采样率的单声道采样
+func NewMonitor(model *speech.SharedModel, cfg Config) (*Monitor, error) {
+	if model == nil {
+		return nil, fmt.Errorf("invalid nil shared model")
+	}
+	if cfg.Threshold < 0 || cfg.Threshold >= 1 {
+		return nil, fmt.Errorf("invalid threshold %g: should be in range [0, 1)", cfg.Threshold)
+	}
+	if cfg.MinSpeech < 0 {
+		return nil, fmt.Errorf("invalid min speech duration: %s", cfg.MinSpeech)
+	}
+	if cfg.MinSpeech == 0 {
+		cfg.MinSpeech = defaultMinSpeech
+	}
+
+	sampleRate := model.GetConfig().SampleRate
+	chunker, err := speech.NewStreamChunker(sampleRate)
+	if err != nil {
+		return nil, err
+	}
+	window := time.Duration(chunker.WindowSize()) * time.Second / time.Duration(sampleRate)
+
+	m := &Monitor{
+		cfg:        cfg,
+		sampleRate: sampleRate,
+		minWindows: int((cfg.MinSpeech + window - 1) / window),
+		dc:         model.NewContext(),
+		chunker:    chunker,
+		armed:      true,
+	}
+	m.dc.SetWindowObserver(m.observe)
+	return m, nil
+}
+
+// SetPlaying 设置 TTS 是否正在播放，只有播放期间才会触发打断
+// 每次开始播放都会重新允许触发，即使用户在上一次播放结束时仍在说话。
+func (m *Monitor) SetPlaying(playing bool) {
+	if m.playing.Swap(playing) != playing && playing {
+		m.rearm.Store(true)
+	}
+}
+
+// Playing 返回 TTS 是否正在播放
+func (m *Monitor) Playing() bool {
+	return m.playing.Load()
+}
+
+// Write 检测一块麦克风音频，返回其中产生的打断事件
+// 每次连续的语音最多触发一次，语音概率回落后才会再次触发。
+func (m *Monitor) Write(pcm []float32) ([]Event, error) {
+	m.threshold = m.cfg.Threshold
+	if m.threshold == 0 {
+		m.threshold = m.dc.GetConfig().Threshold
+	}
+	if m.rearm.Swap(false) {
+		m.armed = true
+	}
+
+	m.events = m.events[:0]
+	m.chunker.Write(pcm)
+	if _, err := m.dc.DetectChunks(m.chunker); err != nil {
+		return nil, err
+	}
+	if len(m.events) == 0 {
+		return nil, nil
+	}
+	return append([]Event(nil), m.events...), nil
+}
+
+// observe 逐窗口更新连续语音的计数，播放期间达到 MinSpeech 时记录事件
+func (m *Monitor) observe(r speech.WindowResult) {
+	if r.Probability >= m.threshold {
+		if m.run == 0 {
+			m.runStart = r.Sample - m.chunker.WindowSize()
+		}
+		m.run++
+	} else {
+		m.run = 0
+		if r.Probability < m.threshold-hysteresis {
+			m.armed = true
+		}
+	}
+
+	if m.armed && m.run >= m.minWindows && m.playing.Load() {
+		m.armed = false
+		m.events = append(m.events, Event{
+			Start:       float64(m.runStart) / float64(m.sampleRate),
+			At:          float64(r.Sample) / float64(m.sampleRate),
+			Probability: r.Probability,
+		})
+	}
+}
+
+// Reset 清空检测状态和缓冲的音频，用于开始新的一路通话；播放状态保持不变
+func (m *Monitor) Reset() error {
+	m.chunker.Reset()
+	m.run = 0
+	m.armed = true
+	return m.dc.Reset()
+}
+
+// Close 释放检测上下文
+func (m *Monitor) Close() error {
+	return m.dc.Close()
+}
@@ -0,0 +1,71 @@
+package bargein
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rui-yang-me/silero-vad-go/internal/testutil"
+)
+
+// feed 按 20ms 一块写入音频，返回所有事件
+func feed(t *testing.T, m *Monitor, samples []float32) []Event {
+	t.Helper()
+
+	var events []Event
+	for off := 0; off < len(samples); off += 320 {
+		evs, err := m.Write(samples[off:min(off+320, len(samples))])
+		require.NoError(t, err)
+		events = append(events, evs...)
+	}
+	return events
+}
+
+func TestMonitor(t *testing.T) {
+	sm := testutil.NewSharedModel(t)
+	samples := testutil.ReadSamples(t)
+
+	dc := sm.NewContext()
+	segments, err := dc.Detect(samples)
+	require.NoError(t, err)
+	require.NoError(t, dc.Close())
+	require.NotEmpty(t, segments)
+
+	_, err = NewMonitor(nil, Config{})
+	require.Error(t, err)
+	_, err = NewMonitor(sm, Config{Threshold: 1})
+	require.Error(t, err)
+	_, err = NewMonitor(sm, Config{MinSpeech: -time.Millisecond})
+	require.Error(t, err)
+
+	m, err := NewMonitor(sm, Config{})
+	require.NoError(t, err)
+	defer m.Close()
+
+	// 不播放时不触发
+	require.Empty(t, feed(t, m, samples))
+
+	// 播放期间每段语音触发一次，起点与语音片段的开始一致，判定延迟不超过 MinSpeech 加一个窗口
+	require.NoError(t, m.Reset())
+	m.SetPlaying(true)
+	require.True(t, m.Playing())
+	events := feed(t, m, samples)
+	require.NotEmpty(t, events)
+	require.LessOrEqual(t, len(events), len(segments)*2)
+	require.InDelta(t, segments[0].SpeechStartAt, events[0].Start, 0.05)
+	require.GreaterOrEqual(t, events[0].Probability, float32(0.5))
+	for _, ev := range events {
+		require.InDelta(t, 0.064, ev.At-ev.Start, 1e-9)
+	}
+
+	// 更长的 MinSpeech 降低灵敏度，判定更晚
+	slow, err := NewMonitor(sm, Config{MinSpeech: 200 * time.Millisecond})
+	require.NoError(t, err)
+	defer slow.Close()
+	slow.SetPlaying(true)
+	slowEvents := feed(t, slow, samples)
+	require.NotEmpty(t, slowEvents)
+	require.LessOrEqual(t, len(slowEvents), len(events))
+	require.InDelta(t, 0.224, slowEvents[0].At-slowEvents[0].Start, 1e-9)
+}
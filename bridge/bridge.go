@@ -0,0 +1,150 @@
+// Package bridge 定义媒体平台接入语音检测的最小接口。
+//
+// SFU 或通话平台（LiveKit、Mattermost Calls、Janus 等）只需为每路音频实现 AudioSource，
+// 并用 EventSink 接收说话事件，采样率转换、分窗和流式检测都由 Run 完成：
+//
+//	src, err := bridge.NewReaderSource("caller", conn, audio.FormatPCM16, 8000)
+//	...
+//	err = bridge.Run(ctx, model, src, bridge.SinkFunc(func(ev bridge.Event) error {
+//		log.Printf("%s speaking=%v", ev.Source, ev.Speaking)
+//		return nil
+//	}))
+package bridge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/rui-yang-me/silero-vad-go/audio"
+	"github.com/rui-yang-me/silero-vad-go/speech"
+)
+
+// AudioSource 一路单声道音频，例如通话中的一个参与者
+type AudioSource interface {
+	// ID 返回音频来源的标识，会写入每个事件
+	ID() string
+	// SampleRate 返回 ReadAudio 输出的采样率
+	SampleRate() int
+	// ReadAudio 阻塞读取下一块采样并追加到 dst 后返回，音频结束时返回 io.EOF
+	ReadAudio(dst []float32) ([]float32, error)
+}
+
+// Event 音频来源开始或停止说话
+type Event struct {
+	// 产生事件的 AudioSource.ID
+	Source string
+	// true 表示开始说话，false 表示停止说话
+	Speaking bool
+	// 对应的语音片段，时间为相对音频开始的秒数；开始事件中 SpeechEndAt 为 0
+	Segment speech.Segment
+}
+
+// EventSink 接收说话事件，返回错误时 Run 会停止
+type EventSink interface {
+	HandleEvent(ev Event) error
+}
+
+// StreamEnder 可选接口，EventSink 同时实现它时 Run 在每路音频结束（包括出错返回）后调用 EndStream，
+// 用于释放按来源保存的状态
+type StreamEnder interface {
+	EndStream(source string)
+}
+
+// SinkFunc 把普通函数适配为 EventSink
+type SinkFunc func(ev Event) error
+
+// HandleEvent 实现 EventSink
+func (f SinkFunc) HandleEvent(ev Event) error {
+	return f(ev)
+}
+
+// Run 从 src 读取音频并检测，直到音频结束、ctx 被取消或 sink 返回错误
+// 音频结束（io.EOF）时补零检测最后不足一个窗口的尾部；此时仍在说话则以音频时长为终点发出停止事件，
+// 因此每个开始事件都有对应的停止事件，然后返回 nil。sink 实现了 StreamEnder 时 Run 返回前调用其 EndStream。ctx 只在两次 ReadAudio 之间检查，
+// 需要立即中断阻塞读取时应由 AudioSource 自行处理（例如关闭底层连接）。
+func Run(ctx context.Context, model *speech.SharedModel, src AudioSource, sink EventSink) error {
+	if model == nil {
+		return fmt.Errorf("invalid nil shared model")
+	}
+	if e, ok := sink.(StreamEnder); ok {
+		defer e.EndStream(src.ID())
+	}
+
+	modelRate := model.GetConfig().SampleRate
+	var resampler *audio.Resampler
+	if rate := src.SampleRate(); rate != modelRate {
+		var err error
+		if resampler, err = audio.NewResampler(rate, modelRate); err != nil {
+			return err
+		}
+	}
+
+	chunker, err := speech.NewStreamChunker(modelRate)
+	if err != nil {
+		return err
+	}
+
+	dc := model.NewContext()
+	defer dc.Close()
+
+	var (
+		speaking bool
+		start    float64 // 当前片段的开始时间
+		read     int     // 已读取的来源采样数
+		buf, out []float32
+	)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		buf, err = src.ReadAudio(buf[:0])
+		if err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("failed to read audio from %s: %w", src.ID(), err)
+		}
+		eof := err != nil
+
+		if resampler != nil {
+			out = resampler.Process(out[:0], buf)
+			if eof {
+				out = resampler.Flush(out)
+			}
+			chunker.Write(out)
+		} else {
+			chunker.Write(buf)
+		}
+		read += len(buf)
+		if eof {
+			chunker.Pad()
+		}
+
+		segments, err := dc.DetectChunks(chunker)
+		if err != nil {
+			return err
+		}
+		for _, seg := range segments {
+			if !speaking {
+				start = seg.SpeechStartAt
+				if err := sink.HandleEvent(Event{Source: src.ID(), Speaking: true, Segment: speech.Segment{SpeechStartAt: start}}); err != nil {
+					return err
+				}
+			}
+			speaking = seg.SpeechEndAt == 0
+			if !speaking {
+				if err := sink.HandleEvent(Event{Source: src.ID(), Segment: seg}); err != nil {
+					return err
+				}
+			}
+		}
+
+		if eof {
+			if speaking {
+				end := float64(read) / float64(src.SampleRate())
+				return sink.HandleEvent(Event{Source: src.ID(), Segment: speech.Segment{SpeechStartAt: start, SpeechEndAt: end}})
+			}
+			return nil
+		}
+	}
+}
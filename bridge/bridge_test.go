@@ -0,0 +1,123 @@
+package bridge
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rui-yang-me/silero-vad-go/audio"
+	"github.com/rui-yang-me/silero-vad-go/internal/testutil"
+	"github.com/rui-yang-me/silero-vad-go/speech"
+)
+
+func float32Bytes(samples []float32) []byte {
+	data := make([]byte, 4*len(samples))
+	for i, v := range samples {
+		binary.LittleEndian.PutUint32(data[4*i:], math.Float32bits(v))
+	}
+	return data
+}
+
+func TestRun(t *testing.T) {
+	sm := testutil.NewSharedModel(t)
+	samples := testutil.ReadSamples(t)
+
+	dc := sm.NewContext()
+	defer dc.Close()
+	expected, err := dc.Detect(samples)
+	require.NoError(t, err)
+
+	src, err := NewReaderSource("caller", bytes.NewReader(float32Bytes(samples)), audio.FormatFloat32, 16000)
+	require.NoError(t, err)
+
+	var events []Event
+	require.NoError(t, Run(context.Background(), sm, src, SinkFunc(func(ev Event) error {
+		events = append(events, ev)
+		return nil
+	})))
+
+	var starts []float64
+	var ends []speech.Segment
+	for _, ev := range events {
+		require.Equal(t, "caller", ev.Source)
+		if ev.Speaking {
+			starts = append(starts, ev.Segment.SpeechStartAt)
+		} else {
+			ends = append(ends, ev.Segment)
+		}
+	}
+	require.Len(t, starts, len(expected))
+	for i, seg := range expected {
+		require.Equal(t, seg.SpeechStartAt, starts[i])
+		if seg.SpeechEndAt > 0 {
+			require.Equal(t, seg, ends[i])
+		}
+	}
+
+	// 音频在说话中途结束时以音频时长为终点发出停止事件，不足一个窗口的尾部也会被检测
+	cut := int(expected[0].SpeechStartAt*16000) + 8000 + 100
+	src, err = NewReaderSource("caller", bytes.NewReader(float32Bytes(samples[:cut])), audio.FormatFloat32, 16000)
+	require.NoError(t, err)
+	events = events[:0]
+	require.NoError(t, Run(context.Background(), sm, src, SinkFunc(func(ev Event) error {
+		events = append(events, ev)
+		return nil
+	})))
+	require.Len(t, events, 2)
+	require.Equal(t, Event{Source: "caller", Speaking: true, Segment: speech.Segment{SpeechStartAt: expected[0].SpeechStartAt}}, events[0])
+	require.Equal(t, Event{Source: "caller", Segment: speech.Segment{SpeechStartAt: expected[0].SpeechStartAt, SpeechEndAt: float64(cut) / 16000}}, events[1])
+
+	// 采样率不同的来源会被重采样，片段数量应与原始音频接近
+	down, err := audio.Resample(samples, 16000, 8000)
+	require.NoError(t, err)
+	src, err = NewReaderSource("narrowband", bytes.NewReader(float32Bytes(down)), audio.FormatFloat32, 8000)
+	require.NoError(t, err)
+	var n int
+	require.NoError(t, Run(context.Background(), sm, src, SinkFunc(func(ev Event) error {
+		if ev.Speaking {
+			n++
+		}
+		return nil
+	})))
+	require.InDelta(t, len(expected), n, 1)
+
+	// sink 的错误会终止 Run
+	stop := errors.New("stop")
+	src, err = NewReaderSource("caller", bytes.NewReader(float32Bytes(samples)), audio.FormatFloat32, 16000)
+	require.NoError(t, err)
+	err = Run(context.Background(), sm, src, SinkFunc(func(ev Event) error {
+		return stop
+	}))
+	require.ErrorIs(t, err, stop)
+
+	// 取消的 ctx
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.ErrorIs(t, Run(ctx, sm, src, SinkFunc(func(Event) error { return nil })), context.Canceled)
+}
+
+func TestReaderSource(t *testing.T) {
+	_, err := NewReaderSource("x", nil, audio.FormatMP3, 16000)
+	require.Error(t, err)
+
+	// 每次读取 20ms，末尾不完整的采样被丢弃
+	data := make([]byte, 2*(160+10)+1)
+	src, err := NewReaderSource("x", bytes.NewReader(data), audio.FormatPCM16, 8000)
+	require.NoError(t, err)
+
+	buf := []float32{1}
+	buf, err = src.ReadAudio(buf)
+	require.NoError(t, err)
+	require.Len(t, buf, 161)
+	require.Equal(t, float32(1), buf[0])
+
+	buf, err = src.ReadAudio(buf[:0])
+	require.ErrorIs(t, err, io.EOF)
+	require.Len(t, buf, 10)
+}
@@ -0,0 +1,69 @@
+package bridge
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/rui-yang-me/silero-vad-go/audio"
+)
+
+// readerFrameMs ReaderSource 每次读取的音频时长
+const readerFrameMs = 20
+
+// ReaderSource 从 io.Reader 读取原始音频的参考实现
+// 适用于以管道、TCP 或 WebSocket 转发裸 PCM/G.711 的媒体服务器，
+// 也可以作为编写其它平台适配器的样板。
+type ReaderSource struct {
+	id         string
+	r          io.Reader
+	format     audio.SampleFormat
+	sampleRate int
+	buf        []byte
+}
+
+var _ AudioSource = (*ReaderSource)(nil)
+
+// NewReaderSource 创建读取小端序原始音频的 AudioSource，format 必须是 PCM16、Float32 或 G.711
+func NewReaderSource(id string, r io.Reader, format audio.SampleFormat, sampleRate int) (*ReaderSource, error) {
+	bps := format.BytesPerSample()
+	if bps == 0 {
+		return nil, fmt.Errorf("%w: streaming format %s", audio.ErrUnsupportedFormat, format)
+	}
+	if sampleRate <= 0 {
+		return nil, fmt.Errorf("invalid sample rate: %d", sampleRate)
+	}
+	return &ReaderSource{
+		id:         id,
+		r:          r,
+		format:     format,
+		sampleRate: sampleRate,
+		buf:        make([]byte, sampleRate*readerFrameMs/1000*bps),
+	}, nil
+}
+
+// ID 实现 AudioSource
+func (s *ReaderSource) ID() string {
+	return s.id
+}
+
+// SampleRate 实现 AudioSource
+func (s *ReaderSource) SampleRate() int {
+	return s.sampleRate
+}
+
+// ReadAudio 实现 AudioSource，每次读取一帧（20ms），流末尾不完整的采样会被丢弃
+func (s *ReaderSource) ReadAudio(dst []float32) ([]float32, error) {
+	n, err := io.ReadFull(s.r, s.buf)
+	if err == io.ErrUnexpectedEOF {
+		err = io.EOF
+	}
+	n -= n % s.format.BytesPerSample()
+
+	// 转换结果写在 dst 的空闲容量中（不足时另行分配），再追加到 dst 之后
+	pcm, convErr := audio.BytesToFloat32(dst[len(dst):], s.buf[:n], s.format, binary.LittleEndian)
+	if convErr != nil {
+		return dst, convErr
+	}
+	return append(dst, pcm...), err
+}
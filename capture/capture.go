@@ -0,0 +1,125 @@
+// Package capture 从麦克风采集音频并接入流式检测，用于“检测我何时开始说话”一类的桌面演示。
+//
+// 采集依赖 PortAudio，需要安装其开发包（例如 portaudio19-dev）并使用 portaudio 构建标签：
+//
+//	go run -tags portaudio ./your/demo
+//
+// 未启用构建标签时 OpenMicrophone 和 Listen 返回 ErrUnsupported。
+//
+//	err := capture.Listen(ctx, model, func(ev bridge.Event) {
+//		fmt.Println("speaking:", ev.Speaking)
+//	})
+package capture
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rui-yang-me/silero-vad-go/bridge"
+	"github.com/rui-yang-me/silero-vad-go/speech"
+)
+
+// ErrUnsupported 未启用 portaudio 构建标签
+var ErrUnsupported = errors.New("microphone capture requires the portaudio build tag")
+
+// defaultFrameDuration 未设置 Config.FrameDuration 时每次读取的时长
+const defaultFrameDuration = 32 * time.Millisecond
+
+// Config 麦克风参数
+type Config struct {
+	// 采集采样率，0 表示 16000；与模型不一致时 Listen 会自动重采样
+	SampleRate int
+	// 每次读取的时长，越短延迟越低，0 表示 32ms
+	FrameDuration time.Duration
+}
+
+// device 单声道采集设备，由具体的音频后端实现
+type device interface {
+	// read 阻塞读取一帧并追加到 dst 后返回
+	read(dst []float32) ([]float32, error)
+	close() error
+}
+
+// Microphone 系统默认输入设备的单声道音频流，实现 bridge.AudioSource
+type Microphone struct {
+	dev        device
+	sampleRate int
+}
+
+var _ bridge.AudioSource = (*Microphone)(nil)
+
+// OpenMicrophone 打开系统默认输入设备，使用完毕后需要调用 Close
+func OpenMicrophone(cfg Config) (*Microphone, error) {
+	if cfg.SampleRate == 0 {
+		cfg.SampleRate = 16000
+	}
+	if cfg.FrameDuration == 0 {
+		cfg.FrameDuration = defaultFrameDuration
+	}
+	if cfg.SampleRate < 0 || cfg.FrameDuration < 0 {
+		return nil, fmt.Errorf("invalid microphone config: %+v", cfg)
+	}
+
+	frames := int(time.Duration(cfg.SampleRate) * cfg.FrameDuration / time.Second)
+	dev, err := openDevice(cfg.SampleRate, frames)
+	if err != nil {
+		return nil, err
+	}
+	return &Microphone{dev: dev, sampleRate: cfg.SampleRate}, nil
+}
+
+// ID 实现 bridge.AudioSource
+func (m *Microphone) ID() string {
+	return "microphone"
+}
+
+// SampleRate 实现 bridge.AudioSource
+func (m *Microphone) SampleRate() int {
+	return m.sampleRate
+}
+
+// ReadAudio 实现 bridge.AudioSource，阻塞读取一帧
+func (m *Microphone) ReadAudio(dst []float32) ([]float32, error) {
+	return m.dev.read(dst)
+}
+
+// Close 停止采集并释放设备，可以在其它协程中调用以中断阻塞的 ReadAudio
+func (m *Microphone) Close() error {
+	return m.dev.close()
+}
+
+// Listen 以模型采样率打开默认麦克风并持续检测，直到 ctx 被取消
+// 每次开始或停止说话时调用 handle，ctx 取消时返回 nil。
+func Listen(ctx context.Context, model *speech.SharedModel, handle func(bridge.Event)) error {
+	if model == nil {
+		return fmt.Errorf("invalid nil shared model")
+	}
+	mic, err := OpenMicrophone(Config{SampleRate: model.GetConfig().SampleRate})
+	if err != nil {
+		return err
+	}
+	return listen(ctx, model, mic, handle)
+}
+
+func listen(ctx context.Context, model *speech.SharedModel, mic *Microphone, handle func(bridge.Event)) error {
+	// ctx 取消时关闭设备，使阻塞的读取返回
+	stop := context.AfterFunc(ctx, func() {
+		mic.Close()
+	})
+	defer func() {
+		if stop() {
+			mic.Close()
+		}
+	}()
+
+	err := bridge.Run(ctx, model, mic, bridge.SinkFunc(func(ev bridge.Event) error {
+		handle(ev)
+		return nil
+	}))
+	if ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,75 @@
+package capture
+
+import (
+	"context"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rui-yang-me/silero-vad-go/bridge"
+	"github.com/rui-yang-me/silero-vad-go/internal/testutil"
+)
+
+// fakeDevice 按帧回放测试音频，回放结束后阻塞直到被关闭
+type fakeDevice struct {
+	samples []float32
+	frame   int
+	once    sync.Once
+	done    chan struct{}
+}
+
+func (d *fakeDevice) read(dst []float32) ([]float32, error) {
+	if len(d.samples) == 0 {
+		<-d.done
+		return dst, io.EOF
+	}
+	n := min(d.frame, len(d.samples))
+	dst = append(dst, d.samples[:n]...)
+	d.samples = d.samples[n:]
+	return dst, nil
+}
+
+func (d *fakeDevice) close() error {
+	d.once.Do(func() { close(d.done) })
+	return nil
+}
+
+func TestListen(t *testing.T) {
+	_, err := OpenMicrophone(Config{SampleRate: -1})
+	require.Error(t, err)
+
+	sm := testutil.NewSharedModel(t)
+
+	samples := testutil.ReadSamples(t)
+
+	dc := sm.NewContext()
+	defer dc.Close()
+	expected, err := dc.Detect(samples)
+	require.NoError(t, err)
+
+	mic := &Microphone{dev: &fakeDevice{samples: samples, frame: 512, done: make(chan struct{})}, sampleRate: 16000}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var starts int
+	go func() {
+		// 所有片段都开始后停止采集，统计中已包含上面 Detect 的片段
+		for {
+			time.Sleep(10 * time.Millisecond)
+			if sm.Stats().Segments >= uint64(2*len(expected)) {
+				cancel()
+				return
+			}
+		}
+	}()
+
+	require.NoError(t, listen(ctx, sm, mic, func(ev bridge.Event) {
+		require.Equal(t, "microphone", ev.Source)
+		if ev.Speaking {
+			starts++
+		}
+	}))
+	require.Equal(t, len(expected), starts)
+}
@@ -0,0 +1,69 @@
+//go:build portaudio
+
+package capture
+
+import (
+	"io"
+	"sync"
+
+	"github.com/gordonklaus/portaudio"
+)
+
+// paDevice 基于 PortAudio 阻塞接口的采集设备
+type paDevice struct {
+	stream *portaudio.Stream
+	buf    []float32
+
+	// mu 保证 close 不会与正在进行的 Read 并发，Read 最多阻塞一帧
+	mu     sync.Mutex
+	closed bool
+}
+
+func openDevice(sampleRate, frames int) (device, error) {
+	if err := portaudio.Initialize(); err != nil {
+		return nil, err
+	}
+
+	d := &paDevice{buf: make([]float32, frames)}
+	stream, err := portaudio.OpenDefaultStream(1, 0, float64(sampleRate), frames, d.buf)
+	if err != nil {
+		portaudio.Terminate()
+		return nil, err
+	}
+	if err := stream.Start(); err != nil {
+		stream.Close()
+		portaudio.Terminate()
+		return nil, err
+	}
+	d.stream = stream
+	return d, nil
+}
+
+func (d *paDevice) read(dst []float32) ([]float32, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.closed {
+		return dst, io.EOF
+	}
+
+	// 输入溢出只意味着丢了一部分音频，不中断采集
+	if err := d.stream.Read(); err != nil && err != portaudio.InputOverflowed {
+		return dst, err
+	}
+	return append(dst, d.buf...), nil
+}
+
+func (d *paDevice) close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.closed {
+		return nil
+	}
+	d.closed = true
+
+	d.stream.Stop()
+	err := d.stream.Close()
+	portaudio.Terminate()
+	return err
+}
@@ -0,0 +1,8 @@
+//go:build !portaudio
+
+package capture
+
+// openDevice 未启用 portaudio 构建标签时总是返回 ErrUnsupported
+func openDevice(sampleRate, frames int) (device, error) {
+	return nil, ErrUnsupported
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rui-yang-me/silero-vad-go/speech"
+)
+
+// batchSummary batch 子命令写出的 summary.json
+type batchSummary struct {
+	Files          int             `json:"files"`
+	Failed         int             `json:"failed"`
+	Skipped        int             `json:"skipped"`
+	AudioSeconds   float64         `json:"audio_seconds"`
+	SpeechSeconds  float64         `json:"speech_seconds"`
+	ElapsedSeconds float64         `json:"elapsed_seconds"`
+	Results        []batchFileInfo `json:"results"`
+}
+
+// batchFileInfo 单个文件的处理结果，Output 为报告路径，Skipped 表示因 -skip-existing 跳过
+type batchFileInfo struct {
+	File          string  `json:"file"`
+	Output        string  `json:"output,omitempty"`
+	Segments      int     `json:"segments"`
+	AudioSeconds  float64 `json:"audio_seconds"`
+	SpeechSeconds float64 `json:"speech_seconds"`
+	Skipped       bool    `json:"skipped,omitempty"`
+	Error         string  `json:"error,omitempty"`
+}
+
+func runBatch(args []string) error {
+	fset := flag.NewFlagSet("batch", flag.ExitOnError)
+	var df detectorFlags
+	df.register(fset)
+	workers := fset.Int("workers", runtime.NumCPU(), "number of files processed concurrently")
+	outDir := fset.String("out", "results", "directory for per-file segment JSON and summary.json")
+	skipExisting := fset.Bool("skip-existing", false, "skip files whose segment JSON already exists")
+	fset.Usage = func() {
+		fmt.Fprintln(fset.Output(), `Usage: silerovad batch [flags] dir...
+
+Detects speech in every audio file under the given directories and writes
+<out>/<relative path>.json (the same report as 'detect -output json') for each
+file, plus <out>/summary.json with totals and per-file errors.`)
+		fset.PrintDefaults()
+	}
+
+	dirs, err := parseArgs(fset, args)
+	if err != nil {
+		return err
+	}
+	if len(dirs) == 0 || *workers <= 0 {
+		fset.Usage()
+		os.Exit(2)
+	}
+
+	// 多个输入目录时报告路径以目录名开头，避免同名文件互相覆盖
+	type job struct {
+		root, path, prefix string
+	}
+	var jobs []job
+	for _, root := range dirs {
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() && isAudioFile(path) {
+				prefix := ""
+				if len(dirs) > 1 {
+					prefix = filepath.Base(filepath.Clean(root))
+				}
+				jobs = append(jobs, job{root, path, prefix})
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	df.sessions = *workers
+	model, err := df.newModel()
+	if err != nil {
+		return err
+	}
+	defer model.Destroy()
+
+	start := time.Now()
+	results := make([]batchFileInfo, len(jobs))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < min(*workers, len(jobs)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			dc := model.NewContext()
+			defer dc.Close()
+			for i := range next {
+				results[i] = processBatchFile(dc, model.GetConfig(), &df, jobs[i].root, jobs[i].path, filepath.Join(*outDir, jobs[i].prefix), *skipExisting)
+				if results[i].Error != "" {
+					fmt.Fprintf(os.Stderr, "%s: %s\n", results[i].File, results[i].Error)
+				}
+			}
+		}()
+	}
+	for i := range jobs {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	summary := batchSummary{
+		Files:          len(jobs),
+		ElapsedSeconds: time.Since(start).Seconds(),
+		Results:        results,
+	}
+	for _, r := range results {
+		if r.Error != "" {
+			summary.Failed++
+		}
+		if r.Skipped {
+			summary.Skipped++
+		}
+		summary.AudioSeconds += r.AudioSeconds
+		summary.SpeechSeconds += r.SpeechSeconds
+	}
+
+	if err := os.MkdirAll(*outDir, 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(*outDir, "summary.json"), append(data, '\n'), 0o644); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "%d files (%d failed, %d skipped), %.1fs audio, %.1fs speech, in %.1fs\n",
+		summary.Files, summary.Failed, summary.Skipped, summary.AudioSeconds, summary.SpeechSeconds, summary.ElapsedSeconds)
+	if summary.Failed > 0 {
+		return fmt.Errorf("%d of %d files failed", summary.Failed, summary.Files)
+	}
+	return nil
+}
+
+// processBatchFile 检测一个文件并写出其报告，错误记录在返回值中
+func processBatchFile(dc *speech.DetectorContext, cfg speech.DetectorConfig, df *detectorFlags, root, path, outDir string, skipExisting bool) batchFileInfo {
+	info := batchFileInfo{File: path}
+
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		info.Error = err.Error()
+		return info
+	}
+	out := filepath.Join(outDir, rel+".json")
+	info.Output = out
+	if skipExisting {
+		if _, err := os.Stat(out); err == nil {
+			info.Skipped = true
+			return info
+		}
+	}
+
+	pcm, err := loadAudio(path, df)
+	if err != nil {
+		info.Error = err.Error()
+		return info
+	}
+	dc.Reset()
+	segments, err := dc.Detect(pcm)
+	if err != nil {
+		info.Error = err.Error()
+		return info
+	}
+
+	info.Segments = len(segments)
+	info.AudioSeconds = float64(len(pcm)) / float64(df.sampleRate)
+	for _, seg := range segments {
+		end := seg.SpeechEndAt
+		if end == 0 {
+			end = info.AudioSeconds
+		}
+		info.SpeechSeconds += end - seg.SpeechStartAt
+	}
+
+	if err := os.MkdirAll(filepath.Dir(out), 0o755); err != nil {
+		info.Error = err.Error()
+		return info
+	}
+	f, err := os.Create(out)
+	if err != nil {
+		info.Error = err.Error()
+		return info
+	}
+	err = speech.NewSegmentReport(cfg, segments).WriteJSON(f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		info.Error = err.Error()
+	}
+	return info
+}
+
+// isAudioFile 按扩展名判断是否为 batch 处理的音频文件
+func isAudioFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".pcm", ".raw":
+		return true
+	case "":
+		return false
+	}
+	// formatFromExt 对未知扩展名返回 pcm16，这里只接受明确识别的扩展名
+	return formatFromExt(path) != "pcm16"
+}
@@ -0,0 +1,334 @@
+// silerovad 是语音检测的命令行工具，无需编写 Go 代码即可对音频文件做检测。
+//
+// 用法：
+//
+//	silerovad detect [flags] file...
+//	silerovad split [flags] -o dir file
+//	silerovad batch [flags] -out dir dir...
+//	silerovad serve [flags]
+//
+// 运行 silerovad <command> -h 查看各子命令的参数。
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rui-yang-me/silero-vad-go/audio"
+	"github.com/rui-yang-me/silero-vad-go/speech"
+)
+
+const usage = `Usage: silerovad <command> [flags] file...
+
+Commands:
+  detect   print speech segments of audio files
+  split    write each speech segment of a file to its own WAV file
+  batch    detect speech in every audio file under directories concurrently
+  serve    run a local daemon detecting speech in audio sent over a unix socket
+
+Run 'silerovad <command> -h' for the flags of a command.
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	var err error
+	switch cmd, args := os.Args[1], os.Args[2:]; cmd {
+	case "detect":
+		err = runDetect(args)
+	case "split":
+		err = runSplit(args)
+	case "batch":
+		err = runBatch(args)
+	case "serve":
+		err = runServe(args)
+	case "-h", "-help", "--help", "help":
+		fmt.Print(usage)
+	default:
+		fmt.Fprintf(os.Stderr, "silerovad: unknown command %q\n\n%s", cmd, usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "silerovad: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// detectorFlags 各子命令共用的检测与输入参数
+type detectorFlags struct {
+	model       string
+	sampleRate  int
+	threshold   float64
+	minSilence  int
+	speechPad   int
+	highPass    float64
+	inputFormat string
+	inputRate   int
+	// sessions 只由 serve 和 batch 设置
+	sessions int
+}
+
+func (f *detectorFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.model, "model", "silero_vad.onnx", "path to the Silero VAD ONNX model")
+	fs.IntVar(&f.sampleRate, "sample-rate", speech.DefaultSampleRate, "detection sample rate (8000 or 16000), input is resampled to it")
+	fs.Float64Var(&f.threshold, "threshold", speech.DefaultThreshold, "speech probability threshold")
+	fs.IntVar(&f.minSilence, "min-silence-ms", speech.DefaultMinSilenceDurationMs, "silence duration that ends a segment")
+	fs.IntVar(&f.speechPad, "speech-pad-ms", speech.DefaultSpeechPadMs, "padding added around segments")
+	fs.Float64Var(&f.highPass, "high-pass", 0, "high-pass filter cutoff in Hz, 0 to disable")
+	fs.StringVar(&f.inputFormat, "input-format", "", "input format (wav, pcm16, float32, ulaw, alaw, oggopus, mp3, flac, or ffmpeg to decode anything else with ffmpeg), detected from the file extension by default")
+	fs.IntVar(&f.inputRate, "input-rate", 0, "sample rate of raw input, defaults to -sample-rate")
+}
+
+func (f *detectorFlags) newModel() (*speech.SharedModel, error) {
+	return speech.NewSharedModel(speech.DetectorConfig{
+		ModelPath:            f.model,
+		SampleRate:           f.sampleRate,
+		Threshold:            float32(f.threshold),
+		MinSilenceDurationMs: f.minSilence,
+		SpeechPadMs:          f.speechPad,
+		HighPassCutoffHz:     f.highPass,
+		SessionPoolSize:      f.sessions,
+	})
+}
+
+// parseArgs 解析参数，允许参数出现在文件名之后，例如 detect file.wav --json
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	var files []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			return files, nil
+		}
+		files = append(files, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+// loadAudio 读取文件并转换为 sampleRate 采样率的单声道音频
+func loadAudio(path string, f *detectorFlags) ([]float32, error) {
+	format := f.inputFormat
+	if format == "" {
+		format = formatFromExt(path)
+	}
+
+	if format == "ffmpeg" {
+		return audio.DecodeFFmpeg(context.Background(), path, f.sampleRate, audio.FFmpegConfig{})
+	}
+
+	if format == "wav" {
+		pcm, info, err := audio.ReadWAVFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if info.Channels > 1 {
+			if pcm, err = audio.Downmix(pcm, info.Channels); err != nil {
+				return nil, err
+			}
+		}
+		return resample(pcm, info.SampleRate, f.sampleRate)
+	}
+
+	sf, err := audio.ParseSampleFormat(format)
+	if err != nil {
+		return nil, err
+	}
+	r, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	pcm, err := audio.Decode(r, sf, f.sampleRate)
+	if err != nil {
+		return nil, err
+	}
+	if sf.BytesPerSample() > 0 && f.inputRate > 0 {
+		return resample(pcm, f.inputRate, f.sampleRate)
+	}
+	return pcm, nil
+}
+
+func formatFromExt(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".wav", ".wave":
+		return "wav"
+	case ".mp3":
+		return "mp3"
+	case ".flac":
+		return "flac"
+	case ".ogg", ".opus":
+		return "oggopus"
+	case ".mp4", ".m4a", ".aac", ".webm", ".mkv", ".mov":
+		return "ffmpeg"
+	case ".f32", ".float32":
+		return "float32"
+	case ".ulaw", ".mulaw":
+		return "ulaw"
+	case ".alaw":
+		return "alaw"
+	default:
+		return "pcm16"
+	}
+}
+
+func resample(pcm []float32, inRate, outRate int) ([]float32, error) {
+	if inRate == outRate {
+		return pcm, nil
+	}
+	return audio.Resample(pcm, inRate, outRate)
+}
+
+func runDetect(args []string) error {
+	fs := flag.NewFlagSet("detect", flag.ExitOnError)
+	var df detectorFlags
+	df.register(fs)
+	output := fs.String("output", "text", "output format: text, json, csv, srt, vtt or audacity")
+	asJSON := fs.Bool("json", false, "shorthand for -output json")
+	outPath := fs.String("o", "", "write output to this file instead of stdout")
+	showStats := fs.Bool("stats", false, "print processing speed (real-time factor) to stderr")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: silerovad detect [flags] file...")
+		fs.PrintDefaults()
+	}
+
+	files, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	if *asJSON {
+		*output = "json"
+	}
+
+	w := io.Writer(os.Stdout)
+	if *outPath != "" {
+		f, err := os.Create(*outPath)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	model, err := df.newModel()
+	if err != nil {
+		return err
+	}
+	defer model.Destroy()
+
+	dc := model.NewContext()
+	defer dc.Close()
+
+	for _, path := range files {
+		pcm, err := loadAudio(path, &df)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		dc.Reset()
+		segments, err := dc.Detect(pcm)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+
+		// 多个文件时在每个结果前标注文件名，JSON 则依次输出每个文件的报告
+		if len(files) > 1 && *output != "json" {
+			fmt.Fprintf(w, "# %s\n", path)
+		}
+		if err := writeSegments(w, *output, model.GetConfig(), segments); err != nil {
+			return err
+		}
+	}
+
+	if *showStats {
+		r := dc.RTF()
+		fmt.Fprintf(os.Stderr, "audio %.1fs, detection %.2fs, %.0fx real time, RTF %.4f (%.0f streams per core)\n",
+			r.Audio.Seconds(), r.Elapsed.Seconds(), r.Speed(), r.RealTimeFactor(), r.StreamsPerCore())
+	}
+	return nil
+}
+
+func writeSegments(w io.Writer, output string, cfg speech.DetectorConfig, segments []speech.Segment) error {
+	switch output {
+	case "text":
+		for _, seg := range segments {
+			if seg.SpeechEndAt == 0 {
+				fmt.Fprintf(w, "%.3f\t-\n", seg.SpeechStartAt)
+			} else {
+				fmt.Fprintf(w, "%.3f\t%.3f\n", seg.SpeechStartAt, seg.SpeechEndAt)
+			}
+		}
+		return nil
+	case "json":
+		return speech.NewSegmentReport(cfg, segments).WriteJSON(w)
+	case "csv":
+		return speech.NewSegmentReport(cfg, segments).WriteCSV(w)
+	case "srt":
+		return speech.Segments(segments).WriteSRT(w, nil)
+	case "vtt":
+		return speech.Segments(segments).WriteWebVTT(w, nil)
+	case "audacity":
+		return speech.Segments(segments).WriteAudacityLabels(w)
+	default:
+		return fmt.Errorf("unknown output format %q", output)
+	}
+}
+
+func runSplit(args []string) error {
+	fs := flag.NewFlagSet("split", flag.ExitOnError)
+	var df detectorFlags
+	df.register(fs)
+	dir := fs.String("o", ".", "directory for the segment WAV files")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: silerovad split [flags] -o dir file")
+		fs.PrintDefaults()
+	}
+
+	files, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(files) != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	pcm, err := loadAudio(files[0], &df)
+	if err != nil {
+		return err
+	}
+
+	model, err := df.newModel()
+	if err != nil {
+		return err
+	}
+	defer model.Destroy()
+
+	dc := model.NewContext()
+	defer dc.Close()
+
+	segments, err := dc.Detect(pcm)
+	if err != nil {
+		return err
+	}
+	paths, err := speech.ExportSegmentsWAV(pcm, df.sampleRate, segments, *dir)
+	if err != nil {
+		return err
+	}
+	for _, p := range paths {
+		fmt.Println(p)
+	}
+	return nil
+}
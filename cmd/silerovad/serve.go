@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/rui-yang-me/silero-vad-go/audio"
+	"github.com/rui-yang-me/silero-vad-go/server"
+)
+
+func runServe(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	var df detectorFlags
+	df.register(fs)
+	socket := fs.String("socket", "/tmp/silerovad.sock", "path of the unix domain socket to listen on")
+	fs.IntVar(&df.sessions, "sessions", 1, "number of ONNX sessions shared by connections")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), `Usage: silerovad serve [flags]
+
+Runs a local daemon detecting speech in audio sent over a unix domain socket.
+Each request is a 4-byte big-endian length followed by little-endian audio in
+-input-format (pcm16 by default) at -input-rate; each response is a 4-byte
+big-endian length followed by a JSON array of events, for example
+[{"type":"speech_start","start":1.056}]. A zero-length request ends the
+current stream.`)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	format := audio.FormatPCM16
+	if df.inputFormat != "" {
+		var err error
+		if format, err = audio.ParseSampleFormat(df.inputFormat); err != nil {
+			return err
+		}
+	}
+
+	model, err := df.newModel()
+	if err != nil {
+		return err
+	}
+	defer model.Destroy()
+
+	srv, err := server.New(model)
+	if err != nil {
+		return err
+	}
+
+	// 清理上次异常退出留下的套接字文件
+	if fi, err := os.Lstat(*socket); err == nil && fi.Mode()&os.ModeSocket != 0 {
+		os.Remove(*socket)
+	}
+	l, err := net.Listen("unix", *socket)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Fprintf(os.Stderr, "listening on %s\n", *socket)
+	return srv.ServeSocket(ctx, l, server.SocketConfig{
+		Format:     format,
+		SampleRate: df.inputRate,
+	})
+}
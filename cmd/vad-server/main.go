@@ -0,0 +1,156 @@
+// vad-server 以 gRPC 流式服务的形式提供语音检测，接口定义见 proto/silerovad/v1/vad.proto，
+// 指定 -http 时还会提供批量检测的 HTTP 接口和流式检测的 WebSocket 接口，
+// 指定 -audiosocket 时接受 Asterisk AudioSocket 连接并把每路通话的说话事件写入日志。
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/rui-yang-me/silero-vad-go/audiosocket"
+	"github.com/rui-yang-me/silero-vad-go/bridge"
+	"github.com/rui-yang-me/silero-vad-go/metrics"
+	"github.com/rui-yang-me/silero-vad-go/server"
+	"github.com/rui-yang-me/silero-vad-go/speech"
+)
+
+func main() {
+	addr := flag.String("addr", ":50051", "gRPC listen address")
+	httpAddr := flag.String("http", "", "HTTP listen address for /v1/detect (batch), /v1/stream (WebSocket), /v1/twilio (Twilio Media Streams), /metrics, /healthz and /readyz, empty to disable")
+	audioSocketAddr := flag.String("audiosocket", "", "Asterisk AudioSocket listen address, empty to disable")
+	modelPath := flag.String("model", "silero_vad.onnx", "path to the Silero VAD ONNX model")
+	sampleRate := flag.Int("sample-rate", speech.DefaultSampleRate, "sample rate of incoming audio (8000 or 16000)")
+	threshold := flag.Float64("threshold", speech.DefaultThreshold, "speech probability threshold")
+	minSilence := flag.Int("min-silence-ms", speech.DefaultMinSilenceDurationMs, "silence duration that ends a segment")
+	speechPad := flag.Int("speech-pad-ms", speech.DefaultSpeechPadMs, "padding added around segments")
+	poolSize := flag.Int("sessions", 1, "number of ONNX sessions shared by streams")
+	configPath := flag.String("config", "", "JSON or YAML detector config file, flags given explicitly override its values")
+	flag.Parse()
+
+	cfg := speech.DetectorConfig{
+		ModelPath:            *modelPath,
+		SampleRate:           *sampleRate,
+		Threshold:            float32(*threshold),
+		MinSilenceDurationMs: *minSilence,
+		SpeechPadMs:          *speechPad,
+		SessionPoolSize:      *poolSize,
+	}
+	if *configPath != "" {
+		loaded, err := speech.LoadConfig(*configPath)
+		if err != nil {
+			log.Fatalf("Failed to load config: %v", err)
+		}
+		flag.Visit(func(f *flag.Flag) {
+			switch f.Name {
+			case "model":
+				loaded.ModelPath = cfg.ModelPath
+			case "sample-rate":
+				loaded.SampleRate = cfg.SampleRate
+			case "threshold":
+				loaded.Threshold = cfg.Threshold
+			case "min-silence-ms":
+				loaded.MinSilenceDurationMs = cfg.MinSilenceDurationMs
+			case "speech-pad-ms":
+				loaded.SpeechPadMs = cfg.SpeechPadMs
+			case "sessions":
+				loaded.SessionPoolSize = cfg.SessionPoolSize
+			}
+		})
+		cfg = loaded
+		*poolSize = max(cfg.SessionPoolSize, 1)
+	}
+
+	model, err := speech.NewSharedModel(cfg)
+	if err != nil {
+		log.Fatalf("Failed to load model: %v", err)
+	}
+	defer model.Destroy()
+
+	if _, err := metrics.Register(prometheus.DefaultRegisterer, model, metrics.Opts{}); err != nil {
+		log.Fatalf("Failed to register metrics: %v", err)
+	}
+
+	srv, err := server.New(model)
+	if err != nil {
+		log.Fatalf("Failed to create server: %v", err)
+	}
+	// 预热失败时服务不就绪，/readyz 返回 503，保留进程便于排查
+	if err := srv.Warmup(); err != nil {
+		log.Printf("Warmup failed: %v", err)
+	}
+
+	lis, err := net.Listen("tcp", *addr)
+	if err != nil {
+		log.Fatalf("Failed to listen on %s: %v", *addr, err)
+	}
+
+	var hs *http.Server
+	if *httpAddr != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/v1/detect", srv.HTTPHandler(server.HTTPConfig{MaxConcurrent: *poolSize * 4}))
+		mux.Handle("/v1/stream", srv.WebSocketHandler(server.WebSocketConfig{}))
+		mux.Handle("/v1/twilio", srv.TwilioHandler(server.TwilioConfig{ClearOnSpeech: true}))
+		mux.Handle("/metrics", promhttp.Handler())
+		mux.Handle("/healthz", srv.HealthHandler())
+		mux.Handle("/readyz", srv.ReadyHandler())
+		hs = &http.Server{Addr: *httpAddr, Handler: mux}
+		go func() {
+			log.Printf("HTTP endpoint listening on %s", *httpAddr)
+			if err := hs.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Fatalf("HTTP server stopped: %v", err)
+			}
+		}()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if *audioSocketAddr != "" {
+		al, err := net.Listen("tcp", *audioSocketAddr)
+		if err != nil {
+			log.Fatalf("Failed to listen on %s: %v", *audioSocketAddr, err)
+		}
+		go func() {
+			log.Printf("AudioSocket listening on %s", al.Addr())
+			err := audiosocket.Serve(ctx, al, model, bridge.SinkFunc(func(ev bridge.Event) error {
+				if ev.Speaking {
+					log.Printf("call %s: speech start at %.2fs", ev.Source, ev.Segment.SpeechStartAt)
+				} else {
+					log.Printf("call %s: speech end at %.2fs", ev.Source, ev.Segment.SpeechEndAt)
+				}
+				return nil
+			}))
+			if err != nil {
+				log.Fatalf("AudioSocket server stopped: %v", err)
+			}
+		}()
+	}
+
+	gs := server.NewGRPCServer(srv)
+	go func() {
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+		<-sig
+		log.Printf("Shutting down, waiting for open streams")
+		srv.SetReady(false)
+		cancel()
+		if hs != nil {
+			hs.Shutdown(context.Background())
+		}
+		gs.GracefulStop()
+	}()
+
+	log.Printf("VAD server listening on %s", lis.Addr())
+	if err := gs.Serve(lis); err != nil {
+		log.Fatalf("Server stopped: %v", err)
+	}
+}
@@ -0,0 +1,214 @@
+// Package dtmf 使用 Goertzel 算法检测电话按键音（DTMF），用于 IVR 等需要同时识别按键和语音的场景。
+//
+// Detector 与 VAD 使用相同的分帧（speech.StreamChunker），可以把同一路音频同时写入两者：
+//
+//	d, err := dtmf.NewDetector(dtmf.Config{SampleRate: 8000})
+//	for frame := range frames {
+//		for _, ev := range d.Write(frame) {
+//			fmt.Printf("key %c at %.2fs\n", ev.Digit, ev.Start)
+//		}
+//		stream.Push(frame)
+//	}
+package dtmf
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/rui-yang-me/silero-vad-go/speech"
+)
+
+const (
+	// defaultMinDuration 未设置 Config.MinDuration 时按键音的最短时长
+	defaultMinDuration = 40 * time.Millisecond
+	// defaultMinLevelDB 未设置 Config.MinLevelDB 时每个单音的最低电平
+	defaultMinLevelDB = -35
+	// maxTwistDB 行、列两个单音的最大电平差
+	maxTwistDB = 8
+	// minPeakRatioDB 最强的单音须比同组其它频率至少高出的电平
+	minPeakRatioDB = 6
+	// minToneRatio 两个单音的能量至少占一帧总能量的比例，用于排除语音和宽带噪声
+	minToneRatio = 0.5
+)
+
+var (
+	rowFreqs = [4]float64{697, 770, 852, 941}
+	colFreqs = [4]float64{1209, 1336, 1477, 1633}
+	keys     = [4][4]byte{
+		{'1', '2', '3', 'A'},
+		{'4', '5', '6', 'B'},
+		{'7', '8', '9', 'C'},
+		{'*', '0', '#', 'D'},
+	}
+)
+
+// Config Detector 的配置
+type Config struct {
+	// 输入音频的采样率，8000 或 16000
+	SampleRate int
+	// 按键音持续多长才报告，0 表示默认 40ms；实际按帧（32ms）计，至少一帧
+	MinDuration time.Duration
+	// 每个单音的最低电平（dBFS，满幅正弦为 -3dB），0 表示默认 -35dB
+	MinLevelDB float64
+}
+
+// Event 一次按键，时间为相对音频开始的秒数
+type Event struct {
+	// 按键字符：'0'-'9'、'*'、'#' 或 'A'-'D'
+	Digit byte `json:"digit"`
+	// 按键音开始的时间
+	Start float64 `json:"start"`
+	// 确认按键的时间，即报告事件时已处理音频的结尾
+	At float64 `json:"at"`
+}
+
+// Detector 按帧检测按键音，每次按键只报告一次，同一按键须间隔至少一帧才会再次报告
+// Detector 不是并发安全的。
+type Detector struct {
+	chunker   *speech.StreamChunker
+	rate      float64
+	coefs     [8]float64 // 行、列频率的 Goertzel 系数
+	minFrames int
+	minLevel  float64 // 每个采样的单音能量下限
+
+	pos      int  // 已检测的采样数
+	digit    byte // 上一帧检测到的按键，0 表示没有
+	run      int  // 连续检测到 digit 的帧数
+	start    int  // digit 开始的采样位置
+	reported bool // digit 已报告
+	powers   [8]float64
+}
+
+// NewDetector 创建按键音检测器
+func NewDetector(cfg Config) (*Detector, error) {
+	chunker, err := speech.NewStreamChunker(cfg.SampleRate)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.MinDuration < 0 {
+		return nil, fmt.Errorf("invalid MinDuration: should not be negative")
+	}
+	if cfg.MinLevelDB > 0 {
+		return nil, fmt.Errorf("invalid MinLevelDB %gdB: should not be positive", cfg.MinLevelDB)
+	}
+	if cfg.MinDuration == 0 {
+		cfg.MinDuration = defaultMinDuration
+	}
+	if cfg.MinLevelDB == 0 {
+		cfg.MinLevelDB = defaultMinLevelDB
+	}
+
+	d := &Detector{
+		chunker:  chunker,
+		rate:     float64(cfg.SampleRate),
+		minLevel: math.Pow(10, cfg.MinLevelDB/10),
+	}
+	frame := time.Duration(chunker.WindowSize()) * time.Second / time.Duration(cfg.SampleRate)
+	d.minFrames = max(1, int(cfg.MinDuration/frame))
+	for i, f := range append(rowFreqs[:], colFreqs[:]...) {
+		d.coefs[i] = 2 * math.Cos(2*math.Pi*f/d.rate)
+	}
+	return d, nil
+}
+
+// Write 检测一块音频，返回其中确认的按键
+// 不足一帧的采样保留到下一次写入。
+func (d *Detector) Write(pcm []float32) []Event {
+	d.chunker.Write(pcm)
+
+	var events []Event
+	for {
+		frame, ok := d.chunker.Next()
+		if !ok {
+			return events
+		}
+		digit := d.detect(frame)
+		if digit != d.digit {
+			d.digit = digit
+			d.run = 0
+			d.start = d.pos
+			d.reported = false
+		}
+		d.pos += len(frame)
+		if digit == 0 {
+			continue
+		}
+		d.run++
+		if !d.reported && d.run >= d.minFrames {
+			d.reported = true
+			events = append(events, Event{
+				Digit: digit,
+				Start: float64(d.start) / d.rate,
+				At:    float64(d.pos) / d.rate,
+			})
+		}
+	}
+}
+
+// detect 返回一帧中的按键，没有有效的按键音时返回 0
+func (d *Detector) detect(frame []float32) byte {
+	var total float64
+	for _, v := range frame {
+		total += float64(v) * float64(v)
+	}
+	if total == 0 {
+		return 0
+	}
+
+	// Goertzel：2|X(f)|²/N 为频率 f 的单音在这一帧中的能量
+	n := float64(len(frame))
+	for i, coef := range d.coefs {
+		var s1, s2 float64
+		for _, v := range frame {
+			s1, s2 = float64(v)+coef*s1-s2, s1
+		}
+		d.powers[i] = 2 * (s1*s1 + s2*s2 - coef*s1*s2) / n
+	}
+
+	row, rowPower, ok := peak(d.powers[:4])
+	if !ok {
+		return 0
+	}
+	col, colPower, ok := peak(d.powers[4:])
+	if !ok {
+		return 0
+	}
+	if rowPower/n < d.minLevel || colPower/n < d.minLevel {
+		return 0
+	}
+	if math.Abs(10*math.Log10(rowPower/colPower)) > maxTwistDB {
+		return 0
+	}
+	if (rowPower+colPower)/total < minToneRatio {
+		return 0
+	}
+	return keys[row][col]
+}
+
+// peak 返回最强频率的下标和能量，其它频率与之相差不足 minPeakRatioDB 时返回 false
+func peak(powers []float64) (int, float64, bool) {
+	best := 0
+	for i, p := range powers {
+		if p > powers[best] {
+			best = i
+		}
+	}
+	limit := powers[best] * math.Pow(10, -minPeakRatioDB/10.0)
+	for i, p := range powers {
+		if i != best && p > limit {
+			return 0, 0, false
+		}
+	}
+	return best, powers[best], true
+}
+
+// Reset 丢弃缓冲的音频和检测状态，时间戳从 0 重新开始
+func (d *Detector) Reset() {
+	d.chunker.Reset()
+	d.pos = 0
+	d.digit = 0
+	d.run = 0
+	d.start = 0
+	d.reported = false
+}
@@ -0,0 +1,104 @@
+package dtmf
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rui-yang-me/silero-vad-go/internal/testutil"
+)
+
+// dial 合成按键序列，每个按键音 tone 长，之后静音 gap 长
+func dial(digits string, sampleRate int, tone, gap time.Duration, amplitude float64) []float32 {
+	toneLen := int(tone.Seconds() * float64(sampleRate))
+	gapLen := int(gap.Seconds() * float64(sampleRate))
+	var pcm []float32
+	for _, c := range []byte(digits) {
+		var row, col int
+		for r := range keys {
+			if i := strings.IndexByte(string(keys[r][:]), c); i >= 0 {
+				row, col = r, i
+			}
+		}
+		for i := 0; i < toneLen; i++ {
+			t := float64(i) / float64(sampleRate)
+			v := amplitude * (math.Sin(2*math.Pi*rowFreqs[row]*t) + math.Sin(2*math.Pi*colFreqs[col]*t))
+			pcm = append(pcm, float32(v))
+		}
+		pcm = append(pcm, make([]float32, gapLen)...)
+	}
+	return pcm
+}
+
+// feed 按 20ms 一块写入音频，返回所有事件
+func feed(d *Detector, pcm []float32, sampleRate int) []Event {
+	var events []Event
+	chunk := sampleRate / 50
+	for off := 0; off < len(pcm); off += chunk {
+		events = append(events, d.Write(pcm[off:min(off+chunk, len(pcm))])...)
+	}
+	return events
+}
+
+func TestDetector(t *testing.T) {
+	_, err := NewDetector(Config{SampleRate: 44100})
+	require.Error(t, err)
+	_, err = NewDetector(Config{SampleRate: 8000, MinLevelDB: 3})
+	require.Error(t, err)
+
+	const digits = "0123456789*#ABCD55"
+	for _, rate := range []int{8000, 16000} {
+		d, err := NewDetector(Config{SampleRate: rate})
+		require.NoError(t, err)
+
+		pcm := dial(digits, rate, 100*time.Millisecond, 100*time.Millisecond, 0.2)
+		events := feed(d, pcm, rate)
+		require.Len(t, events, len(digits))
+		for i, ev := range events {
+			require.Equal(t, digits[i], ev.Digit)
+			require.InDelta(t, float64(i)*0.2, ev.Start, 0.032)
+			require.Greater(t, ev.At, ev.Start)
+		}
+
+		// 太弱的按键音不报告
+		d.Reset()
+		require.Empty(t, feed(d, dial("1", rate, 100*time.Millisecond, 100*time.Millisecond, 0.005), rate))
+
+		// 时间戳在 Reset 后从 0 开始
+		d.Reset()
+		events = feed(d, dial("7", rate, 100*time.Millisecond, 0, 0.2), rate)
+		require.Len(t, events, 1)
+		require.Less(t, events[0].Start, 0.032)
+	}
+
+	// 更长的 MinDuration 过滤短按键音
+	d, err := NewDetector(Config{SampleRate: 8000, MinDuration: 150 * time.Millisecond})
+	require.NoError(t, err)
+	require.Empty(t, feed(d, dial("9", 8000, 100*time.Millisecond, 100*time.Millisecond, 0.2), 8000))
+	require.Len(t, feed(d, dial("9", 8000, 250*time.Millisecond, 100*time.Millisecond, 0.2), 8000), 1)
+}
+
+func TestDetectorSpeech(t *testing.T) {
+	samples := testutil.ReadSamples(t)
+
+	// 语音中不应误报按键
+	d, err := NewDetector(Config{SampleRate: 16000})
+	require.NoError(t, err)
+	require.Empty(t, feed(d, samples, 16000))
+
+	// 语音中叠加的按键音仍能检测到
+	d.Reset()
+	mixed := append([]float32(nil), samples...)
+	tone := dial("3", 16000, 120*time.Millisecond, 0, 0.3)
+	offset := len(mixed) / 2
+	for i, v := range tone {
+		mixed[offset+i] = mixed[offset+i]*0.3 + v
+	}
+	events := feed(d, mixed, 16000)
+	require.Len(t, events, 1)
+	require.Equal(t, byte('3'), events[0].Digit)
+	require.InDelta(t, float64(offset)/16000, events[0].Start, 0.032)
+}
@@ -0,0 +1,179 @@
+// Package events 把说话事件发布到 NATS、Kafka 等消息系统，供呼叫中心分析等下游系统直接订阅。
+//
+// Sink 实现 bridge.EventSink，可以直接用于 bridge.Run 和 audiosocket.Serve：
+//
+//	nc, _ := nats.Connect(nats.DefaultURL)
+//	sink, err := events.NewSink(events.NATSPublisher(nc), events.Config{Topic: "vad.events"})
+//	...
+//	err = audiosocket.Serve(ctx, l, model, sink)
+//
+// Kafka 通过 PublisherFunc 适配，以流标识作为消息键，保证同一路音频的事件有序：
+//
+//	w := &kafka.Writer{Addr: kafka.TCP("localhost:9092"), Balancer: &kafka.Hash{}}
+//	pub := events.PublisherFunc(func(ctx context.Context, topic string, key, payload []byte) error {
+//		return w.WriteMessages(ctx, kafka.Message{Topic: topic, Key: key, Value: payload})
+//	})
+//
+// 每条消息是一个 JSON 对象（Message），字段如下：
+//
+//	{
+//	  "version": 1,                              // 格式版本，不兼容的修改会增加该值
+//	  "type": "speech_start",                    // speech_start 或 speech_end
+//	  "stream_id": "40325ec2-5efd-...",          // 音频来源标识，即 bridge.Event.Source
+//	  "seq": 3,                                  // 该流内从 1 开始递增的序号
+//	  "start": 12.416,                           // 语音开始时间，相对音频开始的秒数
+//	  "end": 14.208,                             // 语音结束时间，仅 speech_end 包含
+//	  "timestamp": "2024-05-01T08:00:00.123Z"    // 事件发布时的时间（RFC 3339，UTC）
+//	}
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rui-yang-me/silero-vad-go/bridge"
+)
+
+// SchemaVersion 当前的消息格式版本
+const SchemaVersion = 1
+
+// 消息类型
+const (
+	TypeSpeechStart = "speech_start"
+	TypeSpeechEnd   = "speech_end"
+)
+
+// defaultTimeout 未设置 Config.Timeout 时单次发布的超时
+const defaultTimeout = 5 * time.Second
+
+// Message 发布的事件消息，格式见包文档
+type Message struct {
+	Version   int       `json:"version"`
+	Type      string    `json:"type"`
+	StreamID  string    `json:"stream_id"`
+	Seq       uint64    `json:"seq"`
+	Start     float64   `json:"start"`
+	End       *float64  `json:"end,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Publisher 把一条消息发布到 topic，key 为流标识，可用于分区
+type Publisher interface {
+	Publish(ctx context.Context, topic string, key, payload []byte) error
+}
+
+// PublisherFunc 把普通函数适配为 Publisher
+type PublisherFunc func(ctx context.Context, topic string, key, payload []byte) error
+
+// Publish 实现 Publisher
+func (f PublisherFunc) Publish(ctx context.Context, topic string, key, payload []byte) error {
+	return f(ctx, topic, key, payload)
+}
+
+// natsConn *nats.Conn 满足的最小接口，避免依赖 NATS 客户端
+type natsConn interface {
+	Publish(subject string, data []byte) error
+}
+
+// NATSPublisher 把 *nats.Conn 适配为 Publisher，topic 即 NATS 主题，key 不使用
+func NATSPublisher(conn natsConn) Publisher {
+	return PublisherFunc(func(_ context.Context, topic string, _, payload []byte) error {
+		return conn.Publish(topic, payload)
+	})
+}
+
+// Config Sink 的配置
+type Config struct {
+	// 发布的主题，必填
+	Topic string
+	// 单次发布的超时，0 表示默认 5 秒
+	Timeout time.Duration
+	// 发布失败时调用，此时 HandleEvent 返回 nil，检测继续进行；为 nil 时 HandleEvent 返回错误
+	OnError func(err error)
+	// 返回当前时间，为 nil 时使用 time.Now，便于测试
+	Now func() time.Time
+}
+
+// Sink 把 bridge.Event 编码为 Message 并发布，可被多路音频并发使用
+type Sink struct {
+	pub Publisher
+	cfg Config
+
+	mu   sync.Mutex
+	seqs map[string]uint64
+}
+
+var (
+	_ bridge.EventSink   = (*Sink)(nil)
+	_ bridge.StreamEnder = (*Sink)(nil)
+)
+
+// NewSink 创建发布事件的 Sink
+func NewSink(pub Publisher, cfg Config) (*Sink, error) {
+	if pub == nil {
+		return nil, fmt.Errorf("invalid nil publisher")
+	}
+	if cfg.Topic == "" {
+		return nil, fmt.Errorf("invalid empty topic")
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout
+	}
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+	return &Sink{pub: pub, cfg: cfg, seqs: make(map[string]uint64)}, nil
+}
+
+// HandleEvent 实现 bridge.EventSink
+func (s *Sink) HandleEvent(ev bridge.Event) error {
+	msg := Message{
+		Version:   SchemaVersion,
+		Type:      TypeSpeechStart,
+		StreamID:  ev.Source,
+		Seq:       s.next(ev),
+		Start:     ev.Segment.SpeechStartAt,
+		Timestamp: s.cfg.Now().UTC(),
+	}
+	if !ev.Speaking {
+		end := ev.Segment.SpeechEndAt
+		msg.Type = TypeSpeechEnd
+		msg.End = &end
+	}
+
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.cfg.Timeout)
+	defer cancel()
+	if err := s.pub.Publish(ctx, s.cfg.Topic, []byte(ev.Source), payload); err != nil {
+		err = fmt.Errorf("failed to publish %s event for %s: %w", msg.Type, ev.Source, err)
+		if s.cfg.OnError != nil {
+			s.cfg.OnError(err)
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// next 返回流的下一个序号
+func (s *Sink) next(ev bridge.Event) uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.seqs[ev.Source]++
+	return s.seqs[ev.Source]
+}
+
+// EndStream 实现 bridge.StreamEnder，在一路音频结束后清除其序号状态，
+// bridge.Run 和 rtpvad 会自动调用；不经过它们直接调用 HandleEvent 时应在每路音频结束时调用
+func (s *Sink) EndStream(streamID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.seqs, streamID)
+}
@@ -0,0 +1,127 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rui-yang-me/silero-vad-go/audio"
+	"github.com/rui-yang-me/silero-vad-go/bridge"
+	"github.com/rui-yang-me/silero-vad-go/internal/testutil"
+	"github.com/rui-yang-me/silero-vad-go/speech"
+)
+
+type published struct {
+	topic   string
+	key     string
+	payload []byte
+}
+
+type fakeNATS struct {
+	subjects []string
+}
+
+func (f *fakeNATS) Publish(subject string, _ []byte) error {
+	f.subjects = append(f.subjects, subject)
+	return nil
+}
+
+func TestSink(t *testing.T) {
+	sm := testutil.NewSharedModel(t)
+
+	data, err := os.ReadFile("../testfiles/samples.pcm")
+	require.NoError(t, err)
+	samples, err := audio.BytesToFloat32(nil, data, audio.FormatFloat32, binary.LittleEndian)
+	require.NoError(t, err)
+
+	dc := sm.NewContext()
+	defer dc.Close()
+	expected, err := dc.Detect(samples)
+	require.NoError(t, err)
+
+	var msgs []published
+	now := time.Date(2024, 5, 1, 16, 0, 0, 0, time.FixedZone("CST", 8*3600))
+	sink, err := NewSink(PublisherFunc(func(ctx context.Context, topic string, key, payload []byte) error {
+		_, ok := ctx.Deadline()
+		require.True(t, ok)
+		msgs = append(msgs, published{topic, string(key), payload})
+		return nil
+	}), Config{Topic: "vad.events", Now: func() time.Time { return now }})
+	require.NoError(t, err)
+
+	src, err := bridge.NewReaderSource("call-1", bytes.NewReader(data), audio.FormatFloat32, 16000)
+	require.NoError(t, err)
+	require.NoError(t, bridge.Run(context.Background(), sm, src, sink))
+
+	var starts []float64
+	var ends []speech.Segment
+	for i, m := range msgs {
+		require.Equal(t, "vad.events", m.topic)
+		require.Equal(t, "call-1", m.key)
+
+		var msg Message
+		require.NoError(t, json.Unmarshal(m.payload, &msg))
+		require.Equal(t, SchemaVersion, msg.Version)
+		require.Equal(t, "call-1", msg.StreamID)
+		require.Equal(t, uint64(i+1), msg.Seq)
+		require.True(t, msg.Timestamp.Equal(now))
+		require.Contains(t, string(m.payload), `"timestamp":"2024-05-01T08:00:00Z"`)
+
+		switch msg.Type {
+		case TypeSpeechStart:
+			require.Nil(t, msg.End)
+			starts = append(starts, msg.Start)
+		case TypeSpeechEnd:
+			require.NotNil(t, msg.End)
+			ends = append(ends, speech.Segment{SpeechStartAt: msg.Start, SpeechEndAt: *msg.End})
+		default:
+			t.Fatalf("unexpected type %q", msg.Type)
+		}
+	}
+	require.Len(t, starts, len(expected))
+	for i, seg := range expected {
+		require.Equal(t, seg.SpeechStartAt, starts[i])
+		if seg.SpeechEndAt > 0 {
+			require.Equal(t, seg, ends[i])
+		}
+	}
+
+	// Run 返回时清除流的序号状态，同一标识的新流从 1 开始计数
+	require.Empty(t, sink.seqs)
+	require.NoError(t, sink.HandleEvent(bridge.Event{Source: "call-1", Speaking: true}))
+	var msg Message
+	require.NoError(t, json.Unmarshal(msgs[len(msgs)-1].payload, &msg))
+	require.Equal(t, uint64(1), msg.Seq)
+}
+
+func TestSinkErrors(t *testing.T) {
+	failing := PublisherFunc(func(context.Context, string, []byte, []byte) error {
+		return errors.New("broker unavailable")
+	})
+
+	sink, err := NewSink(failing, Config{Topic: "vad"})
+	require.NoError(t, err)
+	require.ErrorContains(t, sink.HandleEvent(bridge.Event{Source: "a", Speaking: true}), "broker unavailable")
+
+	var reported error
+	sink, err = NewSink(failing, Config{Topic: "vad", OnError: func(err error) { reported = err }})
+	require.NoError(t, err)
+	require.NoError(t, sink.HandleEvent(bridge.Event{Source: "a", Speaking: true}))
+	require.ErrorContains(t, reported, "speech_start event for a")
+
+	_, err = NewSink(failing, Config{})
+	require.Error(t, err)
+
+	nc := &fakeNATS{}
+	sink, err = NewSink(NATSPublisher(nc), Config{Topic: "vad.events"})
+	require.NoError(t, err)
+	require.NoError(t, sink.HandleEvent(bridge.Event{Source: "a"}))
+	require.Equal(t, []string{"vad.events"}, nc.subjects)
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"encoding/binary"
 	"fmt"
 	"log"
 	"math"
@@ -8,6 +9,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/rui-yang-me/silero-vad-go/audio"
 	"github.com/rui-yang-me/silero-vad-go/speech"
 )
 
@@ -56,6 +58,7 @@ func main() {
 
 			// 每个协程创建自己的检测器上下文
 			context := sharedModel.NewContext()
+			defer context.Close()
 
 			// 处理音频数据
 			segments, err := context.Detect(audioData[workerID])
@@ -110,7 +113,7 @@ func main() {
 	var wg2 sync.WaitGroup
 	for i, pcmfile := range pcmfiles {
 		wg2.Add(1)
-		go func(i int) {
+		go func(i int, pcmfile string) {
 			startTime := time.Now()
 			defer func() {
 				log.Printf("[%s] IsSpeech worker %d finished in %v", pcmfile,
@@ -122,6 +125,7 @@ func main() {
 			}
 			// 每个协程创建自己的检测器上下文
 			context := sharedModel.NewContext()
+			defer context.Close()
 			isSpeech, err := context.IsSpeech(audioData[i])
 			if err != nil {
 				log.Printf("[%s] IsSpeech failed: %v", pcmfile, err)
@@ -130,7 +134,7 @@ func main() {
 			fmt.Printf("[%s] IsSpeech result: %v\n", pcmfile, isSpeech)
 			// // 重置状态用于下一次检测
 			// context.Reset()
-		}(i)
+		}(i, pcmfile)
 
 		// startTime := time.Now()
 		// hasSpeech, err := context2.IsSpeech(audioData[i])
@@ -152,6 +156,7 @@ func main() {
 	// 演示快速检测方法
 	fmt.Println("\n--- Testing IsSpeechQuick method ---")
 	context3 := sharedModel.NewContext()
+	defer context3.Close()
 
 	for i, pcmfile := range pcmfiles {
 		if i >= len(audioData) {
@@ -199,15 +204,8 @@ func readPCMFile(filePath string) ([]float32, error) {
 		return nil, err
 	}
 
-	// 将PCM数据转换为float32切片
-	var audioData []float32
-	for i := 0; i < len(data); i += 2 {
-		// 读取16位PCM数据并转换为float32
-		sample := int16(data[i]) | int16(data[i+1])<<8
-		audioData = append(audioData, float32(sample)/32768.0)
-	}
-
-	return audioData, nil
+	// 将16位小端PCM数据转换为float32切片
+	return audio.BytesToFloat32(nil, data, audio.FormatPCM16, binary.LittleEndian)
 }
 
 // 流式处理示例
@@ -216,6 +214,7 @@ func streamProcessingExample(sharedModel *speech.SharedModel) {
 
 	// 模拟实时音频流
 	context := sharedModel.NewContext()
+	defer context.Close()
 	chunkSize := 1600 // 100ms chunks at 16kHz
 
 	for i := 0; i < 10; i++ { // 处理10个chunk
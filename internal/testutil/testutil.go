@@ -0,0 +1,53 @@
+// Package testutil 提供各子包测试共用的模型和测试音频
+package testutil
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rui-yang-me/silero-vad-go/audio"
+	"github.com/rui-yang-me/silero-vad-go/speech"
+)
+
+// File 返回 testfiles 目录中 name 的路径，与调用测试所在的目录无关
+func File(name string) string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "..", "..", "testfiles", name)
+}
+
+// NewSharedModel 创建测试用的共享模型并在测试结束时销毁
+// 使用 16kHz、阈值 0.5 的 Silero VAD 模型，opts 可以在创建前修改配置。
+func NewSharedModel(t testing.TB, opts ...func(*speech.DetectorConfig)) *speech.SharedModel {
+	t.Helper()
+
+	cfg := speech.DetectorConfig{
+		ModelPath:  File("silero_vad.onnx"),
+		SampleRate: 16000,
+		Threshold:  0.5,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	sm, err := speech.NewSharedModel(cfg)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, sm.Destroy())
+	})
+	return sm
+}
+
+// ReadSamples 读取 testfiles/samples.pcm，16kHz 单声道 float32 采样
+func ReadSamples(t testing.TB) []float32 {
+	t.Helper()
+
+	data, err := os.ReadFile(File("samples.pcm"))
+	require.NoError(t, err)
+	samples, err := audio.BytesToFloat32(nil, data, audio.FormatFloat32, binary.LittleEndian)
+	require.NoError(t, err)
+	return samples
+}
@@ -0,0 +1,150 @@
+package kws
+
+import (
+	"fmt"
+
+	"github.com/rui-yang-me/silero-vad-go/speech"
+)
+
+// Gate 流式检测语音，只在语音片段内识别关键词，只缓存尚未识别的音频，不是并发安全的
+// 片段开始后每凑够一帧就识别一次，命中的延迟约为一帧；静音期间不调用 Spotter。
+type Gate struct {
+	sc         *scanner
+	sampleRate int
+	padSamples int
+	dc         *speech.DetectorContext
+	chunker    *speech.StreamChunker
+
+	// buf 保存从绝对采样位置 offset 开始的音频
+	buf    []float32
+	offset int
+	// open 为 true 时 openStart 是尚未结束的片段的开始采样位置
+	open      bool
+	openStart int
+	// pending 已结束但尚未识别完的片段，以采样区间表示
+	pending [][2]int
+}
+
+// NewGate 创建流式关键词检测，输入音频需为模型采样率
+func NewGate(model *speech.SharedModel, s Spotter, cfg Config) (*Gate, error) {
+	if model == nil {
+		return nil, fmt.Errorf("invalid nil shared model")
+	}
+	vadCfg := model.GetConfig()
+	sc, err := newScanner(s, cfg, vadCfg.SampleRate)
+	if err != nil {
+		return nil, err
+	}
+	chunker, err := speech.NewStreamChunker(vadCfg.SampleRate)
+	if err != nil {
+		return nil, err
+	}
+	return &Gate{
+		sc:         sc,
+		sampleRate: vadCfg.SampleRate,
+		padSamples: vadCfg.SpeechPadMs * vadCfg.SampleRate / 1000,
+		dc:         model.NewContext(),
+		chunker:    chunker,
+	}, nil
+}
+
+// Write 写入一段音频，返回由此识别出的关键词命中
+// 识别在调用方的 goroutine 中同步进行。
+func (g *Gate) Write(pcm []float32) ([]Hit, error) {
+	g.buf = append(g.buf, pcm...)
+	g.chunker.Write(pcm)
+
+	segments, err := g.dc.DetectChunks(g.chunker)
+	if err != nil {
+		return nil, err
+	}
+	total := g.offset + len(g.buf)
+	for _, seg := range segments {
+		start, end := sampleRange(seg, g.sampleRate, total)
+		start = max(start, g.offset)
+		g.open = seg.SpeechEndAt == 0
+		if g.open {
+			g.openStart = start
+		} else {
+			g.pending = append(g.pending, [2]int{start, end})
+		}
+	}
+
+	hits, err := g.spot(false)
+	g.trim()
+	return hits, err
+}
+
+// Flush 在输入结束时调用：识别所有剩余的片段，超出音频结尾的帧以零补齐
+// 之后不应再调用 Write。
+func (g *Gate) Flush() ([]Hit, error) {
+	if g.open {
+		g.pending = append(g.pending, [2]int{g.openStart, g.offset + len(g.buf)})
+		g.open = false
+	}
+
+	hits, err := g.spot(true)
+	g.trim()
+	return hits, err
+}
+
+// spot 依次识别已结束的片段和尚未结束的片段中音频已经到达的帧
+func (g *Gate) spot(force bool) ([]Hit, error) {
+	var hits []Hit
+	for len(g.pending) > 0 {
+		r := g.pending[0]
+		h, done, err := g.sc.scan(g.buf, g.offset, r[0], r[1], force)
+		hits = append(hits, h...)
+		if err != nil || !done {
+			return hits, err
+		}
+		g.pending = g.pending[1:]
+	}
+	if g.open {
+		h, _, err := g.sc.scan(g.buf, g.offset, g.openStart, g.offset+len(g.buf), false)
+		hits = append(hits, h...)
+		if err != nil {
+			return hits, err
+		}
+	}
+	return hits, nil
+}
+
+// trim 丢弃不会再被任何帧用到的音频
+// 新片段的开始时间最早为已检测位置之前一个窗口再减去 SpeechPadMs 的填充。
+func (g *Gate) trim() {
+	keep := g.offset + len(g.buf) - g.chunker.Buffered() - g.chunker.WindowSize() - g.padSamples
+	// 下一帧从片段开始处或上次识别的位置开始，取二者中较晚的一个
+	if len(g.pending) > 0 {
+		keep = min(keep, max(g.pending[0][0], g.sc.next))
+	} else if g.open {
+		keep = min(keep, max(g.openStart, g.sc.next))
+	}
+
+	if drop := keep - g.offset; drop > 0 {
+		n := copy(g.buf, g.buf[drop:])
+		g.buf = g.buf[:n]
+		g.offset = keep
+	}
+}
+
+// Reset 清空检测状态和缓存，开始一路新的音频
+func (g *Gate) Reset() error {
+	if err := g.dc.Reset(); err != nil {
+		return err
+	}
+	g.chunker.Reset()
+	g.buf = g.buf[:0]
+	g.offset = 0
+	g.open = false
+	g.pending = nil
+	g.sc.segStart = -1
+	g.sc.next = 0
+	clear(g.sc.active)
+	return nil
+}
+
+// Close 释放检测上下文
+func (g *Gate) Close() error {
+	return g.dc.Close()
+}
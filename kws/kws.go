@@ -0,0 +1,219 @@
+// Package kws 只在 VAD 判定为语音的音频上运行关键词检测（KWS）模型，返回带时间戳的关键词命中。
+//
+// 关键词模型常驻运行的开销远大于 VAD，以 VAD 作为门控、只对语音片段逐帧识别是低功耗设备上的常用做法。
+// 模型通过 Spotter 接口接入，ONNXSpotter 使用 ortaudio.Runner 运行以波形为输入、输出各关键词得分的 ONNX 模型：
+//
+//	s, err := kws.NewONNXSpotter(ortaudio.Config{ModelPath: "kws.onnx"})
+//	...
+//	cfg := kws.Config{Keywords: []string{"hey_robot", "stop"}, FrameSize: 16000}
+//	hits, err := kws.Segments(model, pcm, s, cfg)
+//
+// 流式场景使用 Gate，语音片段开始后每凑够一帧就识别一次，不必等到片段结束。
+package kws
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/rui-yang-me/silero-vad-go/ortaudio"
+	"github.com/rui-yang-me/silero-vad-go/speech"
+)
+
+// defaultThreshold 未设置 Config.Threshold 时判定命中的得分
+const defaultThreshold = 0.5
+
+// Spotter 对一帧单声道音频给出各关键词的得分，下标与 Config.Keywords 一致
+// frame 在调用返回后会被复用，Spotter 不应持有它。
+type Spotter interface {
+	Spot(frame []float32, sampleRate int) ([]float32, error)
+}
+
+// Func 把普通函数适配为 Spotter
+type Func func(frame []float32, sampleRate int) ([]float32, error)
+
+// Spot 实现 Spotter
+func (f Func) Spot(frame []float32, sampleRate int) ([]float32, error) {
+	return f(frame, sampleRate)
+}
+
+// ONNXSpotter 使用 ONNX 模型识别关键词，模型输入为 [1, FrameSize] 的波形，第一个输出为各关键词的得分
+// 模型须以 VAD 的采样率工作。可以被并发调用。
+type ONNXSpotter struct {
+	runner *ortaudio.Runner
+}
+
+// NewONNXSpotter 加载关键词模型
+func NewONNXSpotter(cfg ortaudio.Config) (*ONNXSpotter, error) {
+	r, err := ortaudio.NewRunner(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &ONNXSpotter{runner: r}, nil
+}
+
+// Spot 实现 Spotter
+func (s *ONNXSpotter) Spot(frame []float32, _ int) ([]float32, error) {
+	return s.runner.RunWindow(frame)
+}
+
+// Close 释放模型
+func (s *ONNXSpotter) Close() error {
+	return s.runner.Close()
+}
+
+// Config 关键词检测的配置
+type Config struct {
+	// 关键词名称，顺序与模型输出一致
+	Keywords []string
+	// 每次识别的采样点数，即模型的输入长度
+	FrameSize int
+	// 同一片段内相邻两帧之间的采样点数，取值 [0, FrameSize]，0 表示 FrameSize 的一半
+	Hop int
+	// 得分不低于该值时判定命中，取值 (0, 1)，0 表示默认 0.5
+	Threshold float32
+}
+
+// normalize 校验配置并填充默认值
+func (c *Config) normalize() error {
+	if len(c.Keywords) == 0 {
+		return fmt.Errorf("invalid Keywords: should not be empty")
+	}
+	if c.FrameSize <= 0 {
+		return fmt.Errorf("invalid FrameSize: should be a positive number")
+	}
+	if c.Hop < 0 || c.Hop > c.FrameSize {
+		return fmt.Errorf("invalid Hop: should be in range [0, FrameSize]")
+	}
+	if c.Threshold < 0 || c.Threshold >= 1 {
+		return fmt.Errorf("invalid Threshold: should be in range (0, 1)")
+	}
+	if c.Hop == 0 {
+		c.Hop = max(c.FrameSize/2, 1)
+	}
+	if c.Threshold == 0 {
+		c.Threshold = defaultThreshold
+	}
+	return nil
+}
+
+// Hit 一次关键词命中，时间为命中帧的起止，相对音频开始的秒数
+// 同一片段内关键词的得分持续高于阈值时只报告第一帧。
+type Hit struct {
+	Keyword string  `json:"keyword"`
+	Start   float64 `json:"start"`
+	End     float64 `json:"end"`
+	Score   float32 `json:"score"`
+}
+
+// scanner 在语音片段内逐帧识别，保存跨调用的帧位置和各关键词的命中状态
+type scanner struct {
+	spotter    Spotter
+	cfg        Config
+	sampleRate int
+
+	segStart int    // 当前片段的开始采样位置，尚无片段时为 -1
+	next     int    // 下一帧的开始采样位置
+	active   []bool // 各关键词在当前片段的上一帧是否命中
+	frame    []float32
+}
+
+func newScanner(s Spotter, cfg Config, sampleRate int) (*scanner, error) {
+	if s == nil {
+		return nil, fmt.Errorf("invalid nil spotter")
+	}
+	if err := cfg.normalize(); err != nil {
+		return nil, err
+	}
+	return &scanner{
+		spotter:    s,
+		cfg:        cfg,
+		sampleRate: sampleRate,
+		segStart:   -1,
+		active:     make([]bool, len(cfg.Keywords)),
+		frame:      make([]float32, cfg.FrameSize),
+	}, nil
+}
+
+// scan 识别采样区间 [start, end) 中的帧，buf 保存从绝对采样位置 offset 开始的音频
+// 帧从片段开始处按 Hop 排列，最后一帧可以越过片段结尾；帧的音频尚未全部到达时停止，
+// force 为 true 时以零补齐。返回命中和片段是否已识别完。
+func (sc *scanner) scan(buf []float32, offset, start, end int, force bool) ([]Hit, bool, error) {
+	if start != sc.segStart {
+		sc.segStart = start
+		sc.next = max(sc.next, start)
+		clear(sc.active)
+	}
+
+	total := offset + len(buf)
+	rate := float64(sc.sampleRate)
+	var hits []Hit
+	for ; sc.next < end; sc.next += sc.cfg.Hop {
+		p := sc.next
+		if p+sc.cfg.FrameSize > total && !force {
+			return hits, false, nil
+		}
+		n := copy(sc.frame, buf[min(p-offset, len(buf)):])
+		clear(sc.frame[n:])
+
+		scores, err := sc.spotter.Spot(sc.frame, sc.sampleRate)
+		if err != nil {
+			return hits, false, fmt.Errorf("failed to spot frame at %.3fs: %w", float64(p)/rate, err)
+		}
+		if len(scores) != len(sc.cfg.Keywords) {
+			return hits, false, fmt.Errorf("invalid number of scores %d: expected %d", len(scores), len(sc.cfg.Keywords))
+		}
+		for i, score := range scores {
+			hit := score >= sc.cfg.Threshold
+			if hit && !sc.active[i] {
+				hits = append(hits, Hit{
+					Keyword: sc.cfg.Keywords[i],
+					Start:   float64(p) / rate,
+					End:     float64(p+sc.cfg.FrameSize) / rate,
+					Score:   score,
+				})
+			}
+			sc.active[i] = hit
+		}
+	}
+	return hits, true, nil
+}
+
+// sampleRange 把片段换算为采样区间，SpeechEndAt 为 0 时延伸到 total
+func sampleRange(seg speech.Segment, sampleRate, total int) (int, int) {
+	start := int(math.Round(seg.SpeechStartAt * float64(sampleRate)))
+	end := total
+	if seg.SpeechEndAt > 0 {
+		end = int(math.Round(seg.SpeechEndAt * float64(sampleRate)))
+	}
+	return min(start, end), end
+}
+
+// Segments 检测 pcm（模型采样率）中的语音片段，只在片段内识别关键词，返回按时间排序的命中
+func Segments(model *speech.SharedModel, pcm []float32, s Spotter, cfg Config) ([]Hit, error) {
+	if model == nil {
+		return nil, fmt.Errorf("invalid nil shared model")
+	}
+	sampleRate := model.GetConfig().SampleRate
+	sc, err := newScanner(s, cfg, sampleRate)
+	if err != nil {
+		return nil, err
+	}
+
+	dc := model.NewContext()
+	defer dc.Close()
+	segments, err := dc.Detect(pcm)
+	if err != nil {
+		return nil, err
+	}
+
+	var hits []Hit
+	for _, seg := range segments {
+		start, end := sampleRange(seg, sampleRate, len(pcm))
+		h, _, err := sc.scan(pcm, 0, start, min(end, len(pcm)), true)
+		hits = append(hits, h...)
+		if err != nil {
+			return hits, err
+		}
+	}
+	return hits, nil
+}
@@ -0,0 +1,146 @@
+package kws
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rui-yang-me/silero-vad-go/internal/testutil"
+	"github.com/rui-yang-me/silero-vad-go/ortaudio"
+)
+
+// counter 返回一个总是命中 "word" 的 Spotter，并统计调用次数
+func counter(calls *int) Spotter {
+	return Func(func(frame []float32, _ int) ([]float32, error) {
+		*calls++
+		return []float32{1}, nil
+	})
+}
+
+func TestSegments(t *testing.T) {
+	sm := testutil.NewSharedModel(t)
+	samples := testutil.ReadSamples(t)
+
+	dc := sm.NewContext()
+	segments, err := dc.Detect(samples)
+	require.NoError(t, err)
+	require.NoError(t, dc.Close())
+	require.NotEmpty(t, segments)
+
+	var calls int
+	cfg := Config{Keywords: []string{"word"}, FrameSize: 16000}
+	_, err = Segments(nil, samples, counter(&calls), cfg)
+	require.Error(t, err)
+	_, err = Segments(sm, samples, nil, cfg)
+	require.Error(t, err)
+	for _, bad := range []Config{
+		{FrameSize: 16000},
+		{Keywords: []string{"word"}},
+		{Keywords: []string{"word"}, FrameSize: 16000, Hop: 16001},
+		{Keywords: []string{"word"}, FrameSize: 16000, Threshold: 1},
+	} {
+		_, err = Segments(sm, samples, counter(&calls), bad)
+		require.Error(t, err)
+	}
+
+	// 静音不调用 Spotter
+	hits, err := Segments(sm, make([]float32, 16000*3), counter(&calls), cfg)
+	require.NoError(t, err)
+	require.Empty(t, hits)
+	require.Zero(t, calls)
+
+	// 每个片段内持续命中只报告一次，识别的帧数不超过语音时长按 Hop 划分的帧数
+	calls = 0
+	hits, err = Segments(sm, samples, counter(&calls), cfg)
+	require.NoError(t, err)
+	require.NotEmpty(t, hits)
+	require.LessOrEqual(t, len(hits), len(segments))
+	require.InDelta(t, segments[0].SpeechStartAt, hits[0].Start, 1e-3)
+	var speechSamples int
+	for _, seg := range segments {
+		start, end := sampleRange(seg, 16000, len(samples))
+		speechSamples += end - start
+	}
+	require.LessOrEqual(t, calls, speechSamples/8000+len(segments))
+	require.Less(t, calls, len(samples)/8000)
+	for i, hit := range hits {
+		require.Equal(t, "word", hit.Keyword)
+		require.InDelta(t, 1.0, hit.End-hit.Start, 1e-9)
+		require.Equal(t, float32(1), hit.Score)
+		if i > 0 {
+			require.Greater(t, hit.Start, hits[i-1].Start)
+		}
+	}
+
+	// 得分个数与关键词不一致、Spotter 出错
+	_, err = Segments(sm, samples, Func(func([]float32, int) ([]float32, error) {
+		return []float32{1, 0}, nil
+	}), cfg)
+	require.Error(t, err)
+	errSpot := errors.New("spot failed")
+	_, err = Segments(sm, samples, Func(func([]float32, int) ([]float32, error) {
+		return nil, errSpot
+	}), cfg)
+	require.ErrorIs(t, err, errSpot)
+}
+
+func TestGate(t *testing.T) {
+	sm := testutil.NewSharedModel(t)
+	samples := testutil.ReadSamples(t)
+
+	// classifier_test.onnx 输出 softmax([0, 50*mean(input)])，均值为正的帧命中第二个关键词
+	s, err := NewONNXSpotter(ortaudio.Config{ModelPath: "../testfiles/classifier_test.onnx"})
+	require.NoError(t, err)
+	defer s.Close()
+
+	// 后半段加上直流偏置
+	half := len(samples) / 2
+	pcm := append([]float32(nil), samples...)
+	for i := half; i < len(pcm); i++ {
+		pcm[i] += 0.05
+	}
+	cfg := Config{Keywords: []string{"negative", "positive"}, FrameSize: 8000, Threshold: 0.9}
+	expected, err := Segments(sm, pcm, s, cfg)
+	require.NoError(t, err)
+	require.NotEmpty(t, expected)
+	for _, hit := range expected {
+		require.Equal(t, "positive", hit.Keyword)
+		require.GreaterOrEqual(t, hit.End, float64(half)/16000)
+	}
+
+	_, err = NewGate(nil, s, cfg)
+	require.Error(t, err)
+	_, err = NewGate(sm, s, Config{})
+	require.Error(t, err)
+
+	g, err := NewGate(sm, s, cfg)
+	require.NoError(t, err)
+	defer g.Close()
+
+	// 按 20ms 一块写入，命中与整段检测一致，缓存不随音频长度增长
+	run := func() []Hit {
+		var hits []Hit
+		var maxBuf int
+		for off := 0; off < len(pcm); off += 320 {
+			h, err := g.Write(pcm[off:min(off+320, len(pcm))])
+			require.NoError(t, err)
+			hits = append(hits, h...)
+			maxBuf = max(maxBuf, len(g.buf))
+		}
+		h, err := g.Flush()
+		require.NoError(t, err)
+		require.Less(t, maxBuf, len(pcm)/2)
+		return append(hits, h...)
+	}
+	hits := run()
+	require.Len(t, hits, len(expected))
+	for i := range hits {
+		require.Equal(t, expected[i].Keyword, hits[i].Keyword)
+		require.InDelta(t, expected[i].Start, hits[i].Start, 0.05)
+	}
+
+	// Reset 之后时间戳从头开始
+	require.NoError(t, g.Reset())
+	require.Equal(t, hits, run())
+}
@@ -0,0 +1,98 @@
+package metrics
+
+import (
+	"encoding/binary"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rui-yang-me/silero-vad-go/audio"
+	"github.com/rui-yang-me/silero-vad-go/speech"
+)
+
+func TestCollector(t *testing.T) {
+	sm, err := speech.NewSharedModel(speech.DetectorConfig{
+		ModelPath:  "../testfiles/silero_vad.onnx",
+		SampleRate: 16000,
+		Threshold:  0.5,
+	})
+	require.NoError(t, err)
+	defer sm.Destroy()
+
+	reg := prometheus.NewPedanticRegistry()
+	c, err := Register(reg, sm, Opts{ConstLabels: prometheus.Labels{"model": "test"}})
+	require.NoError(t, err)
+	defer c.Close()
+
+	data, err := os.ReadFile("../testfiles/samples.pcm")
+	require.NoError(t, err)
+	samples, err := audio.BytesToFloat32(nil, data, audio.FormatFloat32, binary.LittleEndian)
+	require.NoError(t, err)
+
+	dc := sm.NewContext()
+	defer dc.Close()
+	segments, err := dc.Detect(samples)
+	require.NoError(t, err)
+
+	stats := sm.Stats()
+	require.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(`
+# HELP silerovad_active_contexts Number of detector contexts not yet closed.
+# TYPE silerovad_active_contexts gauge
+silerovad_active_contexts{model="test"} 1
+# HELP silerovad_inferences_total Number of windows run through the model.
+# TYPE silerovad_inferences_total counter
+silerovad_inferences_total{model="test"} `+formatInt(stats.Inferences)+`
+# HELP silerovad_model_bytes Size of the model files loaded by all sessions of the model.
+# TYPE silerovad_model_bytes gauge
+silerovad_model_bytes{model="test"} `+formatInt(uint64(sm.MemStats().ModelBytes))+`
+# HELP silerovad_segments_total Number of speech segments detected.
+# TYPE silerovad_segments_total counter
+silerovad_segments_total{model="test"} `+formatInt(uint64(len(segments)))+`
+`), "silerovad_active_contexts", "silerovad_inferences_total", "silerovad_model_bytes", "silerovad_segments_total"))
+
+	// 每个窗口都记录到直方图中
+	families, err := reg.Gather()
+	require.NoError(t, err)
+	var found bool
+	for _, mf := range families {
+		if mf.GetName() == "silerovad_window_inference_seconds" {
+			found = true
+			require.Equal(t, stats.Inferences, mf.GetMetric()[0].GetHistogram().GetSampleCount())
+		}
+	}
+	require.True(t, found)
+
+	// 同一个模型不能重复注册到同一个 Registry
+	_, err = Register(reg, sm, Opts{ConstLabels: prometheus.Labels{"model": "test"}})
+	require.Error(t, err)
+
+	// 自定义分桶时从注册起记录推理耗时
+	custom := prometheus.NewPedanticRegistry()
+	cc, err := Register(custom, sm, Opts{Buckets: []float64{1e-4, 1e-3, 1e-2}})
+	require.NoError(t, err)
+	defer cc.Close()
+	_, err = dc.Detect(samples)
+	require.NoError(t, err)
+
+	families, err = custom.Gather()
+	require.NoError(t, err)
+	found = false
+	for _, mf := range families {
+		if mf.GetName() == "silerovad_window_inference_seconds" {
+			found = true
+			h := mf.GetMetric()[0].GetHistogram()
+			require.Equal(t, sm.Stats().Inferences-stats.Inferences, h.GetSampleCount())
+			require.Len(t, h.GetBucket(), 3)
+		}
+	}
+	require.True(t, found)
+}
+
+func formatInt(v uint64) string {
+	return strconv.FormatUint(v, 10)
+}
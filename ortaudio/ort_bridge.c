@@ -0,0 +1,98 @@
+#include <stdlib.h>
+
+#include "ort_bridge.h"
+
+const OrtApi* OrtAudioGetApi() {
+  return OrtGetApiBase()->GetApi(ORT_API_VERSION);
+}
+
+void OrtAudioReleaseStatus(OrtApi* api, OrtStatus* status) {
+  return api->ReleaseStatus(status);
+}
+
+const char* OrtAudioGetErrorMessage(OrtApi* api, OrtStatus* status) {
+  return api->GetErrorMessage(status);
+}
+
+OrtErrorCode OrtAudioGetErrorCode(OrtApi* api, OrtStatus* status) {
+  return api->GetErrorCode(status);
+}
+
+OrtStatus* OrtAudioCreateEnv(OrtApi* api, OrtLoggingLevel log_level, const char* log_id, OrtEnv** env) {
+  return api->CreateEnv(log_level, log_id, env);
+}
+
+void OrtAudioReleaseEnv(OrtApi* api, OrtEnv* env) {
+  return api->ReleaseEnv(env);
+}
+
+OrtStatus* OrtAudioCreateSessionOptions(OrtApi* api, OrtSessionOptions** opts) {
+  return api->CreateSessionOptions(opts);
+}
+
+void OrtAudioReleaseSessionOptions(OrtApi* api, OrtSessionOptions* opts) {
+  return api->ReleaseSessionOptions(opts);
+}
+
+OrtStatus* OrtAudioSetIntraOpNumThreads(OrtApi* api, OrtSessionOptions* opts, int intra_op_num_threads) {
+  return api->SetIntraOpNumThreads(opts, intra_op_num_threads);
+}
+
+OrtStatus* OrtAudioSetInterOpNumThreads(OrtApi* api, OrtSessionOptions* opts, int inter_op_num_threads) {
+  return api->SetInterOpNumThreads(opts, inter_op_num_threads);
+}
+
+OrtStatus* OrtAudioCreateSession(OrtApi* api, OrtEnv* env, const char* model_path, OrtSessionOptions* opts, OrtSession** session) {
+  return api->CreateSession(env, model_path, opts, session);
+}
+
+void OrtAudioReleaseSession(OrtApi* api, OrtSession* session) {
+  return api->ReleaseSession(session);
+}
+
+OrtStatus* OrtAudioCreateCpuMemoryInfo(OrtApi* api, enum OrtAllocatorType alloc_type, enum OrtMemType mem_type, OrtMemoryInfo** minfo) {
+  return api->CreateCpuMemoryInfo(alloc_type, mem_type, minfo);
+}
+
+void OrtAudioReleaseMemoryInfo(OrtApi* api, OrtMemoryInfo *minfo) {
+  return api->ReleaseMemoryInfo(minfo);
+}
+
+OrtStatus* OrtAudioCreateTensorWithDataAsOrtValue(OrtApi* api, const OrtMemoryInfo* minfo, void* data,
+    size_t data_len, const int64_t* shape, size_t shape_len, ONNXTensorElementDataType data_type, OrtValue** value) {
+  return api->CreateTensorWithDataAsOrtValue(minfo, data, data_len, shape, shape_len, data_type, value);
+}
+
+void OrtAudioReleaseValue(OrtApi* api, OrtValue *value) {
+  return api->ReleaseValue(value);
+}
+
+OrtStatus* OrtAudioRun(OrtApi* api, OrtSession* session, const OrtRunOptions* run_options,
+    const char* const* input_names, const OrtValue* const* inputs, size_t inputs_len,
+    const char* const* output_names, size_t output_names_len, OrtValue** outputs) {
+  return api->Run(session, run_options, input_names, inputs, inputs_len, output_names, output_names_len, outputs);
+}
+
+OrtStatus* OrtAudioGetTensorMutableData(OrtApi* api, OrtValue* value, void** data) {
+  return api->GetTensorMutableData(value, data);
+}
+
+OrtStatus* OrtAudioGetTensorTypeAndShape(OrtApi* api, const OrtValue* value, OrtTensorTypeAndShapeInfo** info) {
+  return api->GetTensorTypeAndShape(value, info);
+}
+
+void OrtAudioReleaseTensorTypeAndShapeInfo(OrtApi* api, OrtTensorTypeAndShapeInfo* info) {
+  return api->ReleaseTensorTypeAndShapeInfo(info);
+}
+
+OrtStatus* OrtAudioGetTensorElementType(OrtApi* api, const OrtTensorTypeAndShapeInfo* info, ONNXTensorElementDataType* type) {
+  return api->GetTensorElementType(info, type);
+}
+
+OrtStatus* OrtAudioGetDimensionsCount(OrtApi* api, const OrtTensorTypeAndShapeInfo* info, size_t* count) {
+  return api->GetDimensionsCount(info, count);
+}
+
+OrtStatus* OrtAudioGetDimensions(OrtApi* api, const OrtTensorTypeAndShapeInfo* info, int64_t* dims, size_t dims_len) {
+  return api->GetDimensions(info, dims, dims_len);
+}
@@ -0,0 +1,39 @@
+#include "onnxruntime_c_api.h"
+
+const OrtApi *OrtAudioGetApi();
+
+const char *OrtAudioGetErrorMessage(OrtApi *api, OrtStatus *status);
+OrtErrorCode OrtAudioGetErrorCode(OrtApi *api, OrtStatus *status);
+
+void OrtAudioReleaseStatus(OrtApi *api, OrtStatus *status);
+
+OrtStatus *OrtAudioCreateEnv(OrtApi *api, OrtLoggingLevel log_level, const char *log_id, OrtEnv **env);
+void OrtAudioReleaseEnv(OrtApi *api, OrtEnv *env);
+
+OrtStatus *OrtAudioCreateSessionOptions(OrtApi *api, OrtSessionOptions **opts);
+void OrtAudioReleaseSessionOptions(OrtApi *api, OrtSessionOptions *opts);
+
+OrtStatus *OrtAudioSetIntraOpNumThreads(OrtApi *api, OrtSessionOptions *opts, int intra_op_num_threads);
+OrtStatus *OrtAudioSetInterOpNumThreads(OrtApi *api, OrtSessionOptions *opts, int inter_op_num_threads);
+
+OrtStatus *OrtAudioCreateSession(OrtApi *api, OrtEnv *env, const char *model_path, OrtSessionOptions *opts, OrtSession **session);
+void OrtAudioReleaseSession(OrtApi *api, OrtSession *session);
+
+OrtStatus *OrtAudioCreateCpuMemoryInfo(OrtApi *api, enum OrtAllocatorType alloc_type, enum OrtMemType mem_type, OrtMemoryInfo **minfo);
+void OrtAudioReleaseMemoryInfo(OrtApi *api, OrtMemoryInfo *minfo);
+
+OrtStatus *OrtAudioCreateTensorWithDataAsOrtValue(OrtApi *api, const OrtMemoryInfo *minfo, void *data, size_t data_len,
+                                                const int64_t *shape, size_t shape_len, ONNXTensorElementDataType data_type, OrtValue **value);
+void OrtAudioReleaseValue(OrtApi *api, OrtValue *value);
+
+OrtStatus *OrtAudioRun(OrtApi *api, OrtSession *session, const OrtRunOptions *run_options,
+                     const char *const *input_names, const OrtValue *const *inputs, size_t inputs_len,
+                     const char *const *output_names, size_t output_names_len, OrtValue **outputs);
+
+OrtStatus *OrtAudioGetTensorMutableData(OrtApi *api, OrtValue *value, void **data);
+
+OrtStatus *OrtAudioGetTensorTypeAndShape(OrtApi *api, const OrtValue *value, OrtTensorTypeAndShapeInfo **info);
+void OrtAudioReleaseTensorTypeAndShapeInfo(OrtApi *api, OrtTensorTypeAndShapeInfo *info);
+OrtStatus *OrtAudioGetTensorElementType(OrtApi *api, const OrtTensorTypeAndShapeInfo *info, ONNXTensorElementDataType *type);
+OrtStatus *OrtAudioGetDimensionsCount(OrtApi *api, const OrtTensorTypeAndShapeInfo *info, size_t *count);
+OrtStatus *OrtAudioGetDimensions(OrtApi *api, const OrtTensorTypeAndShapeInfo *info, int64_t *dims, size_t dims_len);
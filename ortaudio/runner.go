@@ -0,0 +1,310 @@
+// Package ortaudio 以最小的接口运行任意 ONNX 音频模型，例如语种识别、语音情感识别（SER）等与 VAD 搭配的模型。
+//
+// Runner 封装了 ONNX Runtime 的环境、会话和张量转换，调用方只需处理 float32 数据：
+//
+//	r, err := ortaudio.NewRunner(ortaudio.Config{ModelPath: "langid.onnx"})
+//	...
+//	defer r.Close()
+//	logits, err := r.RunWindow(pcm) // 输入 [1, len(pcm)]，返回第一个输出
+//
+// 多输入、多输出的模型使用 Run，输入输出的顺序与 Config 中的节点名称一致。
+// ONNX Runtime 返回的错误为 *speech.OrtError，模型无法加载时错误包装 speech.ErrModelLoad。
+package ortaudio
+
+// #cgo CFLAGS: -Wall -Werror -std=c99
+// #cgo LDFLAGS: -lonnxruntime
+// #include <stdlib.h>
+// #include <string.h>
+// #include "ort_bridge.h"
+import "C"
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"unsafe"
+
+	"github.com/rui-yang-me/silero-vad-go/speech"
+)
+
+// ErrClosed Runner 已被关闭
+var ErrClosed = errors.New("runner closed")
+
+// Config Runner 的配置
+type Config struct {
+	// ONNX 模型路径
+	ModelPath string
+	// 输入节点名称，默认为 ["input"]
+	InputNames []string
+	// 输出节点名称，默认为 ["output"]
+	OutputNames []string
+	// 单次推理使用的线程数，0 表示 1；多个协程并发调用 Run 时通常保持为 1
+	Threads int
+}
+
+// Tensor 一个 float32 张量，Data 按行优先存放，元素个数须等于 Shape 各维之积
+type Tensor struct {
+	Data  []float32
+	Shape []int64
+}
+
+// Runner 加载一个 ONNX 模型并对 float32 输入推理
+// Run 和 RunWindow 可以在多个协程中并发调用；Close 会等待进行中的推理结束。
+type Runner struct {
+	api         *C.OrtApi
+	env         *C.OrtEnv
+	sessionOpts *C.OrtSessionOptions
+	session     *C.OrtSession
+	memoryInfo  *C.OrtMemoryInfo
+	inputNames  []*C.char
+	outputNames []*C.char
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+// NewRunner 加载模型并创建 Runner
+func NewRunner(cfg Config) (*Runner, error) {
+	if cfg.ModelPath == "" {
+		return nil, fmt.Errorf("invalid ModelPath: should not be empty")
+	}
+	if cfg.Threads < 0 {
+		return nil, fmt.Errorf("invalid Threads: should not be negative")
+	}
+	if len(cfg.InputNames) == 0 {
+		cfg.InputNames = []string{"input"}
+	}
+	if len(cfg.OutputNames) == 0 {
+		cfg.OutputNames = []string{"output"}
+	}
+	if cfg.Threads == 0 {
+		cfg.Threads = 1
+	}
+
+	r := &Runner{api: C.OrtAudioGetApi()}
+	if r.api == nil {
+		return nil, fmt.Errorf("failed to get API")
+	}
+	for _, name := range cfg.InputNames {
+		r.inputNames = append(r.inputNames, C.CString(name))
+	}
+	for _, name := range cfg.OutputNames {
+		r.outputNames = append(r.outputNames, C.CString(name))
+	}
+	if err := r.init(cfg); err != nil {
+		r.release()
+		return nil, err
+	}
+	return r, nil
+}
+
+// init 创建环境、会话选项、会话和内存信息，出错时由调用方释放已创建的部分
+func (r *Runner) init(cfg Config) error {
+	loggerName := C.CString("ortaudio")
+	defer C.free(unsafe.Pointer(loggerName))
+	status := C.OrtAudioCreateEnv(r.api, C.ORT_LOGGING_LEVEL_WARNING, loggerName, &r.env)
+	if err := r.check(status, "failed to create env"); err != nil {
+		return err
+	}
+
+	status = C.OrtAudioCreateSessionOptions(r.api, &r.sessionOpts)
+	if err := r.check(status, "failed to create session options"); err != nil {
+		return err
+	}
+	status = C.OrtAudioSetIntraOpNumThreads(r.api, r.sessionOpts, C.int(cfg.Threads))
+	if err := r.check(status, "failed to set intra threads"); err != nil {
+		return err
+	}
+	status = C.OrtAudioSetInterOpNumThreads(r.api, r.sessionOpts, 1)
+	if err := r.check(status, "failed to set inter threads"); err != nil {
+		return err
+	}
+
+	modelPath := C.CString(cfg.ModelPath)
+	defer C.free(unsafe.Pointer(modelPath))
+	status = C.OrtAudioCreateSession(r.api, r.env, modelPath, r.sessionOpts, &r.session)
+	if err := r.check(status, "failed to create session"); err != nil {
+		return fmt.Errorf("%w: %w", speech.ErrModelLoad, err)
+	}
+
+	status = C.OrtAudioCreateCpuMemoryInfo(r.api, C.OrtArenaAllocator, C.OrtMemTypeDefault, &r.memoryInfo)
+	return r.check(status, "failed to create memory info")
+}
+
+// check 把非空的 status 转换为 *speech.OrtError 并释放 status
+func (r *Runner) check(status *C.OrtStatus, msg string) error {
+	if status == nil {
+		return nil
+	}
+	defer C.OrtAudioReleaseStatus(r.api, status)
+	return fmt.Errorf("%s: %w", msg, &speech.OrtError{
+		Code: speech.OrtErrorCode(C.OrtAudioGetErrorCode(r.api, status)),
+		Msg:  C.GoString(C.OrtAudioGetErrorMessage(r.api, status)),
+	})
+}
+
+// RunWindow 以形状 [1, len(window)] 的单个输入推理，返回第一个输出的数据
+func (r *Runner) RunWindow(window []float32) ([]float32, error) {
+	outputs, err := r.Run(Tensor{Data: window, Shape: []int64{1, int64(len(window))}})
+	if err != nil {
+		return nil, err
+	}
+	return outputs[0].Data, nil
+}
+
+// Run 推理一次，inputs 与 Config.InputNames 一一对应，返回与 Config.OutputNames 一一对应的输出
+// 输出的数据复制到 Go 内存中，调用方可以一直持有。输出必须是 float32 张量。
+func (r *Runner) Run(inputs ...Tensor) ([]Tensor, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.closed {
+		return nil, ErrClosed
+	}
+	if len(inputs) != len(r.inputNames) {
+		return nil, fmt.Errorf("invalid number of inputs %d: expected %d", len(inputs), len(r.inputNames))
+	}
+
+	values := make([]*C.OrtValue, len(inputs))
+	defer func() {
+		for _, v := range values {
+			if v != nil {
+				C.OrtAudioReleaseValue(r.api, v)
+			}
+		}
+	}()
+	for i, t := range inputs {
+		n := int64(1)
+		dims := make([]C.int64_t, len(t.Shape))
+		for j, d := range t.Shape {
+			n *= d
+			dims[j] = C.int64_t(d)
+		}
+		if len(t.Shape) == 0 || n <= 0 || n != int64(len(t.Data)) {
+			return nil, fmt.Errorf("invalid input %d: %d elements do not match shape %v", i, len(t.Data), t.Shape)
+		}
+		status := C.OrtAudioCreateTensorWithDataAsOrtValue(
+			r.api,
+			r.memoryInfo,
+			unsafe.Pointer(&t.Data[0]),
+			C.size_t(len(t.Data)*4),
+			&dims[0],
+			C.size_t(len(dims)),
+			C.ONNX_TENSOR_ELEMENT_DATA_TYPE_FLOAT,
+			&values[i],
+		)
+		if err := r.check(status, "failed to create input value"); err != nil {
+			return nil, err
+		}
+	}
+
+	outputs := make([]*C.OrtValue, len(r.outputNames))
+	status := C.OrtAudioRun(
+		r.api,
+		r.session,
+		nil,
+		&r.inputNames[0],
+		&values[0],
+		C.size_t(len(values)),
+		&r.outputNames[0],
+		C.size_t(len(r.outputNames)),
+		&outputs[0],
+	)
+	if err := r.check(status, "failed to run"); err != nil {
+		return nil, err
+	}
+	defer func() {
+		for _, v := range outputs {
+			C.OrtAudioReleaseValue(r.api, v)
+		}
+	}()
+
+	result := make([]Tensor, len(outputs))
+	for i, v := range outputs {
+		t, err := r.tensor(v)
+		if err != nil {
+			return nil, fmt.Errorf("output %d: %w", i, err)
+		}
+		result[i] = t
+	}
+	return result, nil
+}
+
+// tensor 读取输出张量的形状并复制其数据
+func (r *Runner) tensor(v *C.OrtValue) (Tensor, error) {
+	var info *C.OrtTensorTypeAndShapeInfo
+	if err := r.check(C.OrtAudioGetTensorTypeAndShape(r.api, v, &info), "failed to get tensor shape"); err != nil {
+		return Tensor{}, err
+	}
+	defer C.OrtAudioReleaseTensorTypeAndShapeInfo(r.api, info)
+
+	var elemType C.ONNXTensorElementDataType
+	if err := r.check(C.OrtAudioGetTensorElementType(r.api, info, &elemType), "failed to get tensor type"); err != nil {
+		return Tensor{}, err
+	}
+	if elemType != C.ONNX_TENSOR_ELEMENT_DATA_TYPE_FLOAT {
+		return Tensor{}, fmt.Errorf("unsupported tensor element type %d: only float32 outputs are supported", elemType)
+	}
+
+	var count C.size_t
+	if err := r.check(C.OrtAudioGetDimensionsCount(r.api, info, &count), "failed to get tensor rank"); err != nil {
+		return Tensor{}, err
+	}
+	t := Tensor{Shape: make([]int64, count)}
+	n := 1
+	if count > 0 {
+		dims := make([]C.int64_t, count)
+		if err := r.check(C.OrtAudioGetDimensions(r.api, info, &dims[0], count), "failed to get tensor dimensions"); err != nil {
+			return Tensor{}, err
+		}
+		for i, d := range dims {
+			t.Shape[i] = int64(d)
+			n *= int(d)
+		}
+	}
+
+	t.Data = make([]float32, n)
+	if n == 0 {
+		return t, nil
+	}
+	var data unsafe.Pointer
+	if err := r.check(C.OrtAudioGetTensorMutableData(r.api, v, &data), "failed to get tensor data"); err != nil {
+		return Tensor{}, err
+	}
+	C.memcpy(unsafe.Pointer(&t.Data[0]), data, C.size_t(n*4))
+	return t, nil
+}
+
+// Close 等待进行中的推理结束后释放所有原生资源，重复调用是安全的
+func (r *Runner) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+	r.release()
+	return nil
+}
+
+// release 释放已创建的原生资源
+func (r *Runner) release() {
+	if r.memoryInfo != nil {
+		C.OrtAudioReleaseMemoryInfo(r.api, r.memoryInfo)
+	}
+	if r.session != nil {
+		C.OrtAudioReleaseSession(r.api, r.session)
+	}
+	if r.sessionOpts != nil {
+		C.OrtAudioReleaseSessionOptions(r.api, r.sessionOpts)
+	}
+	if r.env != nil {
+		C.OrtAudioReleaseEnv(r.api, r.env)
+	}
+	for _, ptr := range r.inputNames {
+		C.free(unsafe.Pointer(ptr))
+	}
+	for _, ptr := range r.outputNames {
+		C.free(unsafe.Pointer(ptr))
+	}
+	r.inputNames, r.outputNames = nil, nil
+}
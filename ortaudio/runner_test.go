@@ -0,0 +1,77 @@
+package ortaudio
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rui-yang-me/silero-vad-go/speech"
+)
+
+func TestRunner(t *testing.T) {
+	_, err := NewRunner(Config{})
+	require.Error(t, err)
+	_, err = NewRunner(Config{ModelPath: "../testfiles/missing.onnx"})
+	require.ErrorIs(t, err, speech.ErrModelLoad)
+	var ortErr *speech.OrtError
+	require.True(t, errors.As(err, &ortErr))
+
+	// classifier_test.onnx: output = softmax([0, 50*mean(input)])
+	r, err := NewRunner(Config{ModelPath: "../testfiles/classifier_test.onnx"})
+	require.NoError(t, err)
+
+	window := make([]float32, 512)
+	for i := range window {
+		window[i] = 0.1
+	}
+	out, err := r.RunWindow(window)
+	require.NoError(t, err)
+	require.Len(t, out, 2)
+	require.InDelta(t, 1, out[0]+out[1], 1e-6)
+	require.Greater(t, out[1], float32(0.99))
+
+	// 并发推理
+	var wg sync.WaitGroup
+	results := make([][]Tensor, 8)
+	errs := make([]error, len(results))
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], errs[i] = r.Run(Tensor{Data: window, Shape: []int64{1, 512}})
+		}(i)
+	}
+	wg.Wait()
+	for i, outputs := range results {
+		require.NoError(t, errs[i])
+		require.Equal(t, []int64{1, 2}, outputs[0].Shape)
+	}
+
+	_, err = r.Run(Tensor{Data: window, Shape: []int64{1, 100}})
+	require.Error(t, err)
+	_, err = r.Run()
+	require.Error(t, err)
+
+	// 多输入多输出的模型按名称顺序传入和返回
+	d, err := NewRunner(Config{
+		ModelPath:   "../testfiles/denoiser_test.onnx",
+		InputNames:  []string{"input", "state"},
+		OutputNames: []string{"output", "stateN"},
+	})
+	require.NoError(t, err)
+	defer d.Close()
+	outputs, err := d.Run(
+		Tensor{Data: []float32{0.4, 0.2, -0.2}, Shape: []int64{1, 3}},
+		Tensor{Data: []float32{1, 2}, Shape: []int64{2}},
+	)
+	require.NoError(t, err)
+	require.Equal(t, []float32{0.2, 0.1, -0.1}, outputs[0].Data)
+	require.Equal(t, []float32{2, 3}, outputs[1].Data)
+
+	require.NoError(t, r.Close())
+	require.NoError(t, r.Close())
+	_, err = r.RunWindow(window)
+	require.ErrorIs(t, err, ErrClosed)
+}
@@ -0,0 +1,200 @@
+// Package pionvad 对 Pion WebRTC 的远端音频轨道做语音检测，输出每个参与者的说话/停止说话事件。
+//
+// 本包只依赖 pion/rtp 和 pion/interceptor，*webrtc.TrackRemote 直接满足 TrackReader 接口：
+//
+//	pc.OnTrack(func(track *webrtc.TrackRemote, _ *webrtc.RTPReceiver) {
+//		td, err := pionvad.NewTrackDetector(model, track.StreamID())
+//		if err != nil {
+//			return
+//		}
+//		defer td.Close()
+//		td.Run(track, func(ev pionvad.Event) { ... })
+//	})
+//
+// Opus 解码需要 opus 构建标签和 libopus，参见 audio.NewOpusDecoder。
+package pionvad
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/pion/interceptor"
+	"github.com/pion/rtp"
+
+	"github.com/rui-yang-me/silero-vad-go/audio"
+	"github.com/rui-yang-me/silero-vad-go/speech"
+)
+
+const (
+	// opusClockRate WebRTC 中 Opus 的 RTP 时钟频率固定为 48kHz（RFC 7587）
+	opusClockRate = 48000
+	// maxConcealedPackets 连续丢包不超过该数量时使用 Opus 丢包补偿，否则按时间戳补静音
+	maxConcealedPackets = 5
+	// maxGapSeconds 时间戳跳变超过该值时视为流重置，不再补静音
+	maxGapSeconds = 10
+)
+
+// TrackReader 读取 RTP 包的最小接口，*webrtc.TrackRemote 满足该接口
+type TrackReader interface {
+	ReadRTP() (*rtp.Packet, interceptor.Attributes, error)
+}
+
+// Event 参与者开始或停止说话
+type Event struct {
+	// 创建 TrackDetector 时指定的参与者标识
+	Participant string
+	// true 表示开始说话，false 表示停止说话
+	Speaking bool
+	// 对应的语音片段，时间为相对轨道开始的秒数；开始事件中 SpeechEndAt 为 0
+	Segment speech.Segment
+}
+
+// packetDecoder 把一个 RTP 负载解码为单声道采样，packet 为 nil 时执行丢包补偿
+type packetDecoder interface {
+	Decode(dst []float32, packet []byte) ([]float32, error)
+	Close()
+}
+
+// TrackDetector 对一个 Opus 音频轨道做流式检测，不是并发安全的
+type TrackDetector struct {
+	participant string
+	sampleRate  int
+	dc          *speech.DetectorContext
+	dec         packetDecoder
+	chunker     *speech.StreamChunker
+
+	started     bool
+	lastSeq     uint16
+	lastTS      uint32
+	lastSamples int // 上一个包解码出的采样数
+	speaking    bool
+	pcm         []float32
+}
+
+// NewTrackDetector 为一个参与者的音频轨道创建检测器，Opus 直接解码为模型采样率的单声道音频
+func NewTrackDetector(model *speech.SharedModel, participant string) (*TrackDetector, error) {
+	if model == nil {
+		return nil, fmt.Errorf("invalid nil shared model")
+	}
+	dec, err := audio.NewOpusDecoder(model.GetConfig().SampleRate, 1)
+	if err != nil {
+		return nil, err
+	}
+	return newTrackDetector(model, participant, dec)
+}
+
+func newTrackDetector(model *speech.SharedModel, participant string, dec packetDecoder) (*TrackDetector, error) {
+	sampleRate := model.GetConfig().SampleRate
+	chunker, err := speech.NewStreamChunker(sampleRate)
+	if err != nil {
+		dec.Close()
+		return nil, err
+	}
+	return &TrackDetector{
+		participant: participant,
+		sampleRate:  sampleRate,
+		dc:          model.NewContext(),
+		dec:         dec,
+		chunker:     chunker,
+	}, nil
+}
+
+// WritePacket 处理一个 RTP 包，返回由此产生的事件
+// 重复或迟到的包会被丢弃；少量丢包使用 Opus 丢包补偿，较长的中断按时间戳补静音，
+// 从而保证事件时间与轨道时间一致。
+func (t *TrackDetector) WritePacket(pkt *rtp.Packet) ([]Event, error) {
+	pcm := t.pcm[:0]
+
+	if t.started {
+		delta := int16(pkt.SequenceNumber - t.lastSeq)
+		if delta <= 0 {
+			return nil, nil
+		}
+
+		if lost := int(delta) - 1; lost > 0 && lost <= maxConcealedPackets {
+			for i := 0; i < lost; i++ {
+				var err error
+				if pcm, err = t.dec.Decode(pcm, nil); err != nil {
+					return nil, err
+				}
+			}
+		} else if lost > 0 {
+			ticks := int64(int32(pkt.Timestamp - t.lastTS))
+			gap := ticks*int64(t.sampleRate)/opusClockRate - int64(t.lastSamples)
+			if gap > 0 && gap <= int64(maxGapSeconds*t.sampleRate) {
+				pcm = append(pcm, make([]float32, gap)...)
+			}
+		}
+	}
+
+	t.started = true
+	t.lastSeq = pkt.SequenceNumber
+	t.lastTS = pkt.Timestamp
+
+	if len(pkt.Payload) > 0 {
+		n := len(pcm)
+		var err error
+		if pcm, err = t.dec.Decode(pcm, pkt.Payload); err != nil {
+			return nil, err
+		}
+		t.lastSamples = len(pcm) - n
+	}
+	t.pcm = pcm
+
+	t.chunker.Write(pcm)
+	segments, err := t.dc.DetectChunks(t.chunker)
+	if err != nil {
+		return nil, err
+	}
+	return t.events(segments), nil
+}
+
+// events 把流式检测返回的片段转换为开始/停止说话事件
+// 尚未结束的片段在之后的调用中会以完整片段再次返回，此时只补发停止事件。
+func (t *TrackDetector) events(segments []speech.Segment) []Event {
+	var events []Event
+	for _, seg := range segments {
+		if !t.speaking {
+			events = append(events, Event{Participant: t.participant, Speaking: true, Segment: speech.Segment{SpeechStartAt: seg.SpeechStartAt}})
+		}
+		t.speaking = seg.SpeechEndAt == 0
+		if !t.speaking {
+			events = append(events, Event{Participant: t.participant, Segment: seg})
+		}
+	}
+	return events
+}
+
+// Speaking 返回参与者当前是否在说话
+func (t *TrackDetector) Speaking() bool {
+	return t.speaking
+}
+
+// Run 持续读取轨道直到其结束，每个事件都会同步调用 handle
+// 轨道正常结束（io.EOF）时返回 nil。
+func (t *TrackDetector) Run(track TrackReader, handle func(Event)) error {
+	for {
+		pkt, _, err := track.ReadRTP()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+
+		events, err := t.WritePacket(pkt)
+		if err != nil {
+			return err
+		}
+		for _, ev := range events {
+			handle(ev)
+		}
+	}
+}
+
+// Close 释放解码器和检测上下文
+func (t *TrackDetector) Close() error {
+	t.dec.Close()
+	return t.dc.Close()
+}
@@ -0,0 +1,132 @@
+package pionvad
+
+import (
+	"encoding/binary"
+	"io"
+	"testing"
+
+	"github.com/pion/interceptor"
+	"github.com/pion/rtp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rui-yang-me/silero-vad-go/internal/testutil"
+	"github.com/rui-yang-me/silero-vad-go/speech"
+)
+
+// frameSamples 测试中每个包 20ms（16kHz）
+const frameSamples = 320
+
+// fakeDecoder 负载为 2 字节的帧序号，解码为测试音频中对应的 20ms，丢包补偿输出静音
+type fakeDecoder struct {
+	samples []float32
+}
+
+func (d *fakeDecoder) Decode(dst []float32, packet []byte) ([]float32, error) {
+	if packet == nil {
+		return append(dst, make([]float32, frameSamples)...), nil
+	}
+	i := int(binary.BigEndian.Uint16(packet)) * frameSamples
+	return append(dst, d.samples[i:i+frameSamples]...), nil
+}
+
+func (d *fakeDecoder) Close() {}
+
+type fakeTrack struct {
+	packets []*rtp.Packet
+}
+
+func (f *fakeTrack) ReadRTP() (*rtp.Packet, interceptor.Attributes, error) {
+	if len(f.packets) == 0 {
+		return nil, nil, io.EOF
+	}
+	pkt := f.packets[0]
+	f.packets = f.packets[1:]
+	return pkt, nil, nil
+}
+
+func newPacket(frame int) *rtp.Packet {
+	payload := make([]byte, 2)
+	binary.BigEndian.PutUint16(payload, uint16(frame))
+	return &rtp.Packet{
+		Header: rtp.Header{
+			SequenceNumber: uint16(60000 + frame), // 覆盖序号回绕
+			Timestamp:      uint32(frame * 960),
+		},
+		Payload: payload,
+	}
+}
+
+func setup(t *testing.T) (*speech.SharedModel, []float32) {
+	t.Helper()
+
+	samples := testutil.ReadSamples(t)
+	return testutil.NewSharedModel(t), samples[:len(samples)/frameSamples*frameSamples]
+}
+
+func TestTrackDetector(t *testing.T) {
+	sm, samples := setup(t)
+
+	dc := sm.NewContext()
+	defer dc.Close()
+	expected, err := dc.Detect(samples)
+	require.NoError(t, err)
+
+	td, err := newTrackDetector(sm, "alice", &fakeDecoder{samples: samples})
+	require.NoError(t, err)
+	defer td.Close()
+
+	track := &fakeTrack{}
+	for i := 0; i < len(samples)/frameSamples; i++ {
+		track.packets = append(track.packets, newPacket(i))
+		// 重复的包被丢弃
+		if i == 10 {
+			track.packets = append(track.packets, newPacket(i))
+		}
+	}
+
+	var events []Event
+	require.NoError(t, td.Run(track, func(ev Event) {
+		events = append(events, ev)
+	}))
+
+	var starts []float64
+	var ends []speech.Segment
+	for _, ev := range events {
+		require.Equal(t, "alice", ev.Participant)
+		if ev.Speaking {
+			starts = append(starts, ev.Segment.SpeechStartAt)
+		} else {
+			ends = append(ends, ev.Segment)
+		}
+	}
+	require.Len(t, starts, len(expected))
+	for i, seg := range expected {
+		require.Equal(t, seg.SpeechStartAt, starts[i])
+		if seg.SpeechEndAt > 0 {
+			require.Equal(t, seg, ends[i])
+		}
+	}
+	require.Equal(t, expected[len(expected)-1].SpeechEndAt == 0, td.Speaking())
+}
+
+func TestTrackDetectorPacketLoss(t *testing.T) {
+	sm, samples := setup(t)
+
+	td, err := newTrackDetector(sm, "bob", &fakeDecoder{samples: samples})
+	require.NoError(t, err)
+	defer td.Close()
+
+	// 少量丢包由丢包补偿填充，大量丢包按时间戳补静音，两种情况下轨道时间都保持连续
+	frames := []int{0, 1, 2, 5, 6, 20, 21}
+	for _, i := range frames {
+		_, err := td.WritePacket(newPacket(i))
+		require.NoError(t, err)
+	}
+	// 迟到的包被丢弃
+	_, err = td.WritePacket(newPacket(3))
+	require.NoError(t, err)
+
+	r := td.dc.RTF()
+	written := int(r.Windows)*512 + td.chunker.Buffered()
+	require.Equal(t, 22*frameSamples, written)
+}
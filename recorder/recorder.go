@@ -0,0 +1,283 @@
+// Package recorder 消费实时音频流，只在有人说话时录音，每段话写为一个 WAV 文件。
+//
+//	rec, err := recorder.New(model, recorder.Config{
+//		Dir:     "recordings",
+//		PreRoll: 300 * time.Millisecond,
+//		OnFile: func(r recorder.Recording) {
+//			log.Printf("saved %s (%.1fs)", r.Path, r.End-r.Start)
+//		},
+//	})
+//	...
+//	for frame := range mic {
+//		if err := rec.Write(frame); err != nil {
+//			...
+//		}
+//	}
+//	rec.Close() // 写完并关闭最后一个文件
+//
+// 文件边建立边写入，长时间说话也不会在内存中累积；设置 MaxFileDuration 后超长的一段话按时长轮转为多个文件。
+package recorder
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/rui-yang-me/silero-vad-go/audio"
+	"github.com/rui-yang-me/silero-vad-go/speech"
+)
+
+// Recording 一个写完的录音文件，时间为相对音频开始的秒数
+type Recording struct {
+	// 文件路径
+	Path string `json:"path"`
+	// 第几段话，从 1 开始
+	Utterance int `json:"utterance"`
+	// 同一段话因 MaxFileDuration 轮转出的第几个文件，从 0 开始
+	Part int `json:"part"`
+	// 文件中音频的起止时间，第一个文件的开始时间包括 PreRoll
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+	// 这段话在下一个文件中继续
+	Continued bool `json:"continued,omitempty"`
+}
+
+// Config Recorder 的配置
+type Config struct {
+	// 输出目录，不存在时自动创建，空表示当前目录
+	Dir string
+	// 由 Utterance、Part 和 Start 生成文件名（相对 Dir，可以包含子目录），nil 表示 utterance_000001.wav，
+	// 轮转出的后续文件为 utterance_000001_1.wav、utterance_000001_2.wav……
+	Name func(r Recording) string
+	// 语音开始之前额外录入的音频，在 SpeechPadMs 的填充之外，避免丢掉起始音节
+	PreRoll time.Duration
+	// 单个文件的最长时长，超过时关闭当前文件并在新文件中继续，0 表示不限制
+	MaxFileDuration time.Duration
+	// 文件格式，SampleRate 总是使用模型的采样率，其余字段的默认值同 audio.WriteWAV（16 位 PCM）
+	WAV audio.WAVInfo
+	// 每个文件写完并关闭后在 Write 或 Close 的 goroutine 中同步调用
+	OnFile func(r Recording)
+}
+
+// defaultName 默认的文件名
+func defaultName(r Recording) string {
+	if r.Part == 0 {
+		return fmt.Sprintf("utterance_%06d.wav", r.Utterance)
+	}
+	return fmt.Sprintf("utterance_%06d_%d.wav", r.Utterance, r.Part)
+}
+
+// Recorder 检测写入的音频，把每段语音连同 PreRoll 写为单独的文件，不是并发安全的
+// 文件的结尾为片段的结束时间（包括 SpeechPadMs 的填充），不包括判定结束所需的 MinSilenceDurationMs 静音。
+type Recorder struct {
+	cfg        Config
+	sampleRate int
+	preRoll    int // 以下均为采样数
+	padSamples int
+	minSilence int
+	maxFile    int
+	dc         *speech.DetectorContext
+	chunker    *speech.StreamChunker
+
+	// buf 保存从绝对采样位置 offset 开始的音频
+	buf    []float32
+	offset int
+
+	active     bool // 正在录制一段话
+	utterances int
+	file       *os.File
+	wav        *audio.WAVWriter
+	rec        Recording // 当前文件，Path 以外的时间字段在关闭时填写
+	fileStart  int       // 当前文件第一个采样的绝对位置
+	written    int       // 已写入文件的绝对采样位置
+}
+
+// New 创建 Recorder，输入音频需为模型采样率
+func New(model *speech.SharedModel, cfg Config) (*Recorder, error) {
+	if model == nil {
+		return nil, fmt.Errorf("invalid nil shared model")
+	}
+	if cfg.PreRoll < 0 || cfg.MaxFileDuration < 0 {
+		return nil, fmt.Errorf("invalid recorder durations: should not be negative")
+	}
+	if cfg.Name == nil {
+		cfg.Name = defaultName
+	}
+	if cfg.Dir == "" {
+		cfg.Dir = "."
+	}
+	if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create output dir: %w", err)
+	}
+
+	vadCfg := model.GetConfig()
+	cfg.WAV.SampleRate = vadCfg.SampleRate
+	chunker, err := speech.NewStreamChunker(vadCfg.SampleRate)
+	if err != nil {
+		return nil, err
+	}
+	samples := func(d time.Duration) int {
+		return int(d.Seconds() * float64(vadCfg.SampleRate))
+	}
+	maxFile := samples(cfg.MaxFileDuration)
+	if cfg.MaxFileDuration > 0 && maxFile == 0 {
+		return nil, fmt.Errorf("invalid MaxFileDuration %s: shorter than one sample", cfg.MaxFileDuration)
+	}
+
+	return &Recorder{
+		cfg:        cfg,
+		sampleRate: vadCfg.SampleRate,
+		preRoll:    samples(cfg.PreRoll),
+		padSamples: vadCfg.SpeechPadMs * vadCfg.SampleRate / 1000,
+		minSilence: vadCfg.MinSilenceDurationMs * vadCfg.SampleRate / 1000,
+		maxFile:    maxFile,
+		dc:         model.NewContext(),
+		chunker:    chunker,
+	}, nil
+}
+
+// Write 写入一段音频，语音进行中时随即写入文件
+func (r *Recorder) Write(pcm []float32) error {
+	r.buf = append(r.buf, pcm...)
+	r.chunker.Write(pcm)
+
+	segments, err := r.dc.DetectChunks(r.chunker)
+	if err != nil {
+		return err
+	}
+	for _, seg := range segments {
+		if !r.active {
+			start := int(math.Round(seg.SpeechStartAt * float64(r.sampleRate)))
+			r.utterances++
+			r.written = max(start-r.preRoll, r.offset)
+			if err := r.openFile(0); err != nil {
+				return err
+			}
+			r.active = true
+		}
+		if seg.SpeechEndAt > 0 {
+			if err := r.writeUpTo(int(math.Round(seg.SpeechEndAt * float64(r.sampleRate)))); err != nil {
+				return err
+			}
+			if err := r.closeFile(false); err != nil {
+				return err
+			}
+			r.active = false
+		}
+	}
+
+	// 片段的结束时间不早于已检测位置之前 MinSilenceDurationMs 处，这之前的音频可以先写入
+	if r.active {
+		if err := r.writeUpTo(r.detected() - r.minSilence); err != nil {
+			return err
+		}
+	}
+	r.trim()
+	return nil
+}
+
+// detected 返回已检测的绝对采样位置
+func (r *Recorder) detected() int {
+	return r.offset + len(r.buf) - r.chunker.Buffered()
+}
+
+// writeUpTo 把音频写入文件直到绝对采样位置 end，达到 MaxFileDuration 时轮转文件
+func (r *Recorder) writeUpTo(end int) error {
+	end = min(end, r.offset+len(r.buf))
+	for r.written < end {
+		if r.maxFile > 0 && r.written-r.fileStart >= r.maxFile {
+			part := r.rec.Part + 1
+			if err := r.closeFile(true); err != nil {
+				return err
+			}
+			if err := r.openFile(part); err != nil {
+				return err
+			}
+		}
+		n := end
+		if r.maxFile > 0 {
+			n = min(n, r.fileStart+r.maxFile)
+		}
+		if err := r.wav.Write(r.buf[r.written-r.offset : n-r.offset]); err != nil {
+			return fmt.Errorf("failed to write %s: %w", r.rec.Path, err)
+		}
+		r.written = n
+	}
+	return nil
+}
+
+// openFile 从 written 处开始一个新文件
+func (r *Recorder) openFile(part int) error {
+	r.rec = Recording{
+		Utterance: r.utterances,
+		Part:      part,
+		Start:     float64(r.written) / float64(r.sampleRate),
+	}
+	r.rec.Path = filepath.Join(r.cfg.Dir, r.cfg.Name(r.rec))
+	if err := os.MkdirAll(filepath.Dir(r.rec.Path), 0o755); err != nil {
+		return fmt.Errorf("failed to create output dir: %w", err)
+	}
+	f, err := os.Create(r.rec.Path)
+	if err != nil {
+		return err
+	}
+	wav, err := audio.NewWAVWriter(f, r.cfg.WAV)
+	if err != nil {
+		f.Close()
+		return err
+	}
+	r.file, r.wav = f, wav
+	r.fileStart = r.written
+	return nil
+}
+
+// closeFile 关闭当前文件并回调，continued 表示这段话在下一个文件中继续
+func (r *Recorder) closeFile(continued bool) error {
+	err := r.wav.Close()
+	if cerr := r.file.Close(); err == nil {
+		err = cerr
+	}
+	r.file, r.wav = nil, nil
+	if err != nil {
+		return fmt.Errorf("failed to close %s: %w", r.rec.Path, err)
+	}
+
+	r.rec.End = float64(r.written) / float64(r.sampleRate)
+	r.rec.Continued = continued
+	if r.cfg.OnFile != nil {
+		r.cfg.OnFile(r.rec)
+	}
+	return nil
+}
+
+// trim 丢弃不会再被写入文件的音频
+// 新片段的开始时间最早为已检测位置之前一个窗口再减去 SpeechPadMs 的填充，文件还要从它之前 PreRoll 处开始。
+func (r *Recorder) trim() {
+	keep := r.detected() - r.chunker.WindowSize() - r.padSamples - r.preRoll
+	if r.active {
+		keep = min(keep, r.written)
+	}
+	if drop := keep - r.offset; drop > 0 {
+		n := copy(r.buf, r.buf[drop:])
+		r.buf = r.buf[:n]
+		r.offset = keep
+	}
+}
+
+// Close 在输入结束时调用：写完正在录制的一段话并关闭文件，然后释放检测上下文
+func (r *Recorder) Close() error {
+	var err error
+	if r.active {
+		err = r.writeUpTo(r.offset + len(r.buf))
+		if cerr := r.closeFile(false); err == nil {
+			err = cerr
+		}
+		r.active = false
+	}
+	if cerr := r.dc.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
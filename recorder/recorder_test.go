@@ -0,0 +1,101 @@
+package recorder
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rui-yang-me/silero-vad-go/audio"
+	"github.com/rui-yang-me/silero-vad-go/internal/testutil"
+	"github.com/rui-yang-me/silero-vad-go/speech"
+)
+
+// record 按 20ms 一块写入音频，返回写完的文件
+func record(t *testing.T, model *speech.SharedModel, samples []float32, cfg Config) []Recording {
+	t.Helper()
+
+	var recs []Recording
+	cfg.OnFile = func(r Recording) {
+		recs = append(recs, r)
+	}
+	rec, err := New(model, cfg)
+	require.NoError(t, err)
+	for off := 0; off < len(samples); off += 320 {
+		require.NoError(t, rec.Write(samples[off:min(off+320, len(samples))]))
+		require.Less(t, len(rec.buf), 16000*3)
+	}
+	require.NoError(t, rec.Close())
+	return recs
+}
+
+// clip 返回文件对应的原始音频
+func clip(samples []float32, r Recording) []float32 {
+	return samples[int(r.Start*16000+0.5):int(r.End*16000+0.5)]
+}
+
+func TestRecorder(t *testing.T) {
+	sm := testutil.NewSharedModel(t)
+	samples := testutil.ReadSamples(t)
+
+	dc := sm.NewContext()
+	segments, err := dc.Detect(samples)
+	require.NoError(t, err)
+	require.NoError(t, dc.Close())
+	require.NotEmpty(t, segments)
+
+	_, err = New(nil, Config{})
+	require.Error(t, err)
+	_, err = New(sm, Config{PreRoll: -time.Second})
+	require.Error(t, err)
+
+	dir := t.TempDir()
+	recs := record(t, sm, samples, Config{Dir: dir, PreRoll: 200 * time.Millisecond})
+	require.Len(t, recs, len(segments))
+	for i, r := range recs {
+		require.Equal(t, filepath.Join(dir, defaultName(r)), r.Path)
+		require.Equal(t, i+1, r.Utterance)
+		require.Zero(t, r.Part)
+		require.False(t, r.Continued)
+		require.InDelta(t, max(segments[i].SpeechStartAt-0.2, 0), r.Start, 1e-3)
+		if segments[i].SpeechEndAt > 0 {
+			require.InDelta(t, segments[i].SpeechEndAt, r.End, 1e-3)
+		} else {
+			require.InDelta(t, float64(len(samples))/16000, r.End, 1e-3)
+		}
+
+		pcm, info, err := audio.ReadWAVFile(r.Path)
+		require.NoError(t, err)
+		require.Equal(t, 16000, info.SampleRate)
+		require.Equal(t, 16, info.BitsPerSample)
+		require.InDeltaSlice(t, clip(samples, r), pcm, 1.0/32767)
+	}
+
+	// 按时长轮转，各部分首尾相接
+	recs = record(t, sm, samples, Config{
+		Dir:             t.TempDir(),
+		MaxFileDuration: 200 * time.Millisecond,
+		WAV:             audio.WAVInfo{Float: true},
+		Name: func(r Recording) string {
+			return filepath.Join("calls", defaultName(r))
+		},
+	})
+	require.Greater(t, len(recs), len(segments))
+	parts := 0
+	for i, r := range recs {
+		require.LessOrEqual(t, r.End-r.Start, 0.2+1e-9)
+		require.Contains(t, r.Path, "calls")
+		if r.Part > 0 {
+			prev := recs[i-1]
+			require.True(t, prev.Continued)
+			require.Equal(t, prev.Utterance, r.Utterance)
+			require.Equal(t, prev.End, r.Start)
+			parts++
+		}
+		pcm, _, err := audio.ReadWAVFile(r.Path)
+		require.NoError(t, err)
+		require.Equal(t, clip(samples, r), pcm)
+	}
+	require.Positive(t, parts)
+}
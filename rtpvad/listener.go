@@ -0,0 +1,235 @@
+// Package rtpvad 在 UDP 端口上接收 RTP 音频并做语音检测，适用于 SIPREC 录音分流或交换机的媒体镜像。
+//
+// 同一端口上的多路流按 SSRC 区分，每路流使用独立的检测上下文和 goroutine，
+// 事件通过 bridge.EventSink 输出，可以直接接入 events.Sink 等实现：
+//
+//	conn, _ := net.ListenPacket("udp", ":40000")
+//	err := rtpvad.Serve(ctx, conn, model, rtpvad.Config{}, bridge.SinkFunc(func(ev bridge.Event) error {
+//		log.Printf("%s speaking=%v", ev.Source, ev.Speaking)
+//		return nil
+//	}))
+//
+// 默认支持静态负载类型 0（PCMU）和 8（PCMA）；Opus 使用动态负载类型，需要在 Config.PayloadTypes 中
+// 按 SDP 协商结果指定，并且需要 opus 构建标签，参见 audio.NewOpusDecoder。
+package rtpvad
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pion/rtp"
+
+	"github.com/rui-yang-me/silero-vad-go/bridge"
+	"github.com/rui-yang-me/silero-vad-go/speech"
+)
+
+const (
+	// g711ClockRate G.711 的 RTP 时钟频率
+	g711ClockRate = 8000
+	// opusClockRate Opus 的 RTP 时钟频率固定为 48kHz（RFC 7587）
+	opusClockRate = 48000
+	// defaultIdleTimeout 未设置 Config.IdleTimeout 时流的超时
+	defaultIdleTimeout = 30 * time.Second
+	// streamQueueSize 每路流待处理包的队列长度，队列满时丢弃新包（按丢包处理）
+	streamQueueSize = 64
+	// maxPacketSize UDP 数据报的最大长度
+	maxPacketSize = 1 << 16
+)
+
+// Codec RTP 负载的编码
+type Codec int
+
+const (
+	// CodecPCMU G.711 µ-law，8kHz
+	CodecPCMU Codec = iota + 1
+	// CodecPCMA G.711 A-law，8kHz
+	CodecPCMA
+	// CodecOpus Opus，48kHz 时钟
+	CodecOpus
+)
+
+// DefaultPayloadTypes 未设置 Config.PayloadTypes 时使用的静态负载类型
+var DefaultPayloadTypes = map[uint8]Codec{
+	0: CodecPCMU,
+	8: CodecPCMA,
+}
+
+// Config 监听的配置
+type Config struct {
+	// 负载类型到编码的映射，nil 时使用 DefaultPayloadTypes；其它负载类型的包会被忽略
+	PayloadTypes map[uint8]Codec
+	// 流在多长时间没有收到包后结束，0 表示默认 30 秒
+	IdleTimeout time.Duration
+	// 返回流的标识（事件的 Source），nil 时使用 8 位十六进制的 SSRC
+	StreamID func(ssrc uint32, addr net.Addr) string
+}
+
+// Serve 从 conn 读取 RTP 包并按 SSRC 分流检测，直到 ctx 被取消或 conn 出错
+// 流结束（超时或 Serve 返回）时，尚未结束的语音以流的结尾作为终点补发停止事件。sink 实现了 bridge.StreamEnder 时随后调用其 EndStream。
+// sink 会被不同的流并发调用，返回的错误只记录日志。ctx 取消时关闭 conn 并返回 nil。
+func Serve(ctx context.Context, conn net.PacketConn, model *speech.SharedModel, cfg Config, sink bridge.EventSink) error {
+	if model == nil {
+		return fmt.Errorf("invalid nil shared model")
+	}
+	if cfg.PayloadTypes == nil {
+		cfg.PayloadTypes = DefaultPayloadTypes
+	}
+	if cfg.IdleTimeout <= 0 {
+		cfg.IdleTimeout = defaultIdleTimeout
+	}
+	if cfg.StreamID == nil {
+		cfg.StreamID = func(ssrc uint32, _ net.Addr) string {
+			return fmt.Sprintf("%08x", ssrc)
+		}
+	}
+
+	stop := context.AfterFunc(ctx, func() {
+		conn.Close()
+	})
+	defer stop()
+
+	d := &demuxer{model: model, cfg: cfg, sink: sink, workers: make(map[uint32]*worker)}
+	defer d.closeAll()
+
+	sweepInterval := min(cfg.IdleTimeout/2, time.Second)
+	nextSweep := time.Now().Add(sweepInterval)
+	buf := make([]byte, maxPacketSize)
+	for {
+		if err := conn.SetReadDeadline(nextSweep); err != nil {
+			return err
+		}
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			if !errors.Is(err, os.ErrDeadlineExceeded) {
+				return err
+			}
+		}
+
+		now := time.Now()
+		if n > 0 {
+			d.dispatch(buf[:n], addr, now)
+		}
+		if !now.Before(nextSweep) {
+			d.sweep(now)
+			nextSweep = now.Add(sweepInterval)
+		}
+	}
+}
+
+// demuxer 按 SSRC 把包分发给各自的 worker，只在 Serve 的 goroutine 中使用
+type demuxer struct {
+	model   *speech.SharedModel
+	cfg     Config
+	sink    bridge.EventSink
+	workers map[uint32]*worker
+	wg      sync.WaitGroup
+}
+
+// worker 一路流的处理 goroutine，packets 为 nil 表示流创建失败，其后续包直接丢弃
+type worker struct {
+	packets  chan *rtp.Packet
+	lastSeen time.Time
+}
+
+func (d *demuxer) dispatch(data []byte, addr net.Addr, now time.Time) {
+	pkt := &rtp.Packet{}
+	// 复制数据，负载会被 worker 异步使用
+	if err := pkt.Unmarshal(append([]byte(nil), data...)); err != nil {
+		return
+	}
+	codec, ok := d.cfg.PayloadTypes[pkt.PayloadType]
+	if !ok {
+		return
+	}
+
+	w, ok := d.workers[pkt.SSRC]
+	if !ok {
+		id := d.cfg.StreamID(pkt.SSRC, addr)
+		w = &worker{}
+		d.workers[pkt.SSRC] = w
+		if s, err := newStream(d.model, id, codec); err != nil {
+			slog.Warn("failed to create rtp stream", slog.String("stream", id), slog.Any("error", err))
+		} else {
+			w.packets = make(chan *rtp.Packet, streamQueueSize)
+			d.wg.Add(1)
+			go d.run(s, w.packets)
+		}
+	}
+	w.lastSeen = now
+	if w.packets == nil {
+		return
+	}
+
+	select {
+	case w.packets <- pkt:
+	default:
+		// 处理跟不上时丢弃，由序号检测按丢包补齐时间
+	}
+}
+
+func (d *demuxer) run(s *stream, packets <-chan *rtp.Packet) {
+	defer d.wg.Done()
+	defer s.close()
+
+	failed := false
+	for pkt := range packets {
+		if failed {
+			continue
+		}
+		events, err := s.write(pkt)
+		if err != nil {
+			// 解码或检测失败后丢弃该流剩余的包，直到超时
+			slog.Warn("rtp stream failed", slog.String("stream", s.id), slog.Any("error", err))
+			failed = true
+			continue
+		}
+		d.emit(s.id, events)
+	}
+	if !failed {
+		d.emit(s.id, s.end())
+	}
+	if e, ok := d.sink.(bridge.StreamEnder); ok {
+		e.EndStream(s.id)
+	}
+}
+
+func (d *demuxer) emit(id string, events []bridge.Event) {
+	for _, ev := range events {
+		if err := d.sink.HandleEvent(ev); err != nil {
+			slog.Warn("failed to handle rtp stream event", slog.String("stream", id), slog.Any("error", err))
+		}
+	}
+}
+
+// sweep 结束超时的流
+func (d *demuxer) sweep(now time.Time) {
+	for ssrc, w := range d.workers {
+		if now.Sub(w.lastSeen) >= d.cfg.IdleTimeout {
+			w.close()
+			delete(d.workers, ssrc)
+		}
+	}
+}
+
+func (d *demuxer) closeAll() {
+	for ssrc, w := range d.workers {
+		w.close()
+		delete(d.workers, ssrc)
+	}
+	d.wg.Wait()
+}
+
+func (w *worker) close() {
+	if w.packets != nil {
+		close(w.packets)
+	}
+}
@@ -0,0 +1,318 @@
+package rtpvad
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pion/rtp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rui-yang-me/silero-vad-go/audio"
+	"github.com/rui-yang-me/silero-vad-go/bridge"
+	"github.com/rui-yang-me/silero-vad-go/internal/testutil"
+	"github.com/rui-yang-me/silero-vad-go/speech"
+)
+
+// frameBytes 每个包 20ms 的 8kHz µ-law
+const frameBytes = 160
+
+func setup(t *testing.T) (*speech.SharedModel, []byte) {
+	t.Helper()
+
+	sm := testutil.NewSharedModel(t, func(cfg *speech.DetectorConfig) {
+		cfg.SessionPoolSize = 2
+	})
+	r, err := audio.NewResampler(16000, g711ClockRate)
+	require.NoError(t, err)
+	ulaw := audio.EncodeULaw(nil, r.Flush(r.Process(nil, testutil.ReadSamples(t))))
+	return sm, ulaw[:len(ulaw)/frameBytes*frameBytes]
+}
+
+// reference 用 bridge.Run 按同样的 20ms 分帧检测，作为期望结果
+func reference(t *testing.T, sm *speech.SharedModel, ulaw []byte) []bridge.Event {
+	t.Helper()
+
+	src, err := bridge.NewReaderSource("", bytes.NewReader(ulaw), audio.FormatULaw, g711ClockRate)
+	require.NoError(t, err)
+	var events []bridge.Event
+	require.NoError(t, bridge.Run(context.Background(), sm, src, bridge.SinkFunc(func(ev bridge.Event) error {
+		events = append(events, ev)
+		return nil
+	})))
+	require.NotEmpty(t, events)
+	return events
+}
+
+func newPacket(ssrc uint32, frame int, payload []byte) *rtp.Packet {
+	return &rtp.Packet{
+		Header: rtp.Header{
+			Version:        2,
+			PayloadType:    0,
+			SequenceNumber: uint16(65000 + frame), // 覆盖序号回绕
+			Timestamp:      uint32(frame * frameBytes),
+			SSRC:           ssrc,
+		},
+		Payload: payload,
+	}
+}
+
+func TestStream(t *testing.T) {
+	sm, ulaw := setup(t)
+	expected := reference(t, sm, ulaw)
+
+	s, err := newStream(sm, "call", CodecPCMU)
+	require.NoError(t, err)
+	defer s.close()
+
+	var events []bridge.Event
+	for i := 0; i*frameBytes < len(ulaw); i++ {
+		pkt := newPacket(1, i, ulaw[i*frameBytes:(i+1)*frameBytes])
+		evs, err := s.write(pkt)
+		require.NoError(t, err)
+		events = append(events, evs...)
+		// 重复的包被丢弃
+		if i == 10 {
+			evs, err := s.write(pkt)
+			require.NoError(t, err)
+			require.Empty(t, evs)
+		}
+	}
+	events = append(events, s.end()...)
+
+	// 流结束时未结束的语音以流的结尾作为终点
+	require.Len(t, events, len(expected)+len(expected)%2)
+	for i, ev := range expected {
+		require.Equal(t, ev.Speaking, events[i].Speaking)
+		require.Equal(t, ev.Segment.SpeechStartAt, events[i].Segment.SpeechStartAt)
+		if !ev.Speaking {
+			require.Equal(t, ev.Segment, events[i].Segment)
+		}
+	}
+	require.False(t, events[len(events)-1].Speaking)
+}
+
+func TestStreamPacketLoss(t *testing.T) {
+	sm, ulaw := setup(t)
+
+	s, err := newStream(sm, "call", CodecPCMU)
+	require.NoError(t, err)
+	defer s.close()
+
+	// 丢包按时间戳补静音，流的时间保持连续；迟到的包被丢弃
+	for _, i := range []int{0, 1, 2, 5, 6, 20, 21, 3} {
+		_, err := s.write(newPacket(1, i, ulaw[i*frameBytes:(i+1)*frameBytes]))
+		require.NoError(t, err)
+	}
+	// 8kHz 到 16kHz 的重采样器有几十个采样的固定延迟
+	require.InDelta(t, 22*frameBytes*2, s.written, 64)
+}
+
+func TestDemuxer(t *testing.T) {
+	sm, ulaw := setup(t)
+	expected := reference(t, sm, ulaw)
+
+	var (
+		mu     sync.Mutex
+		events = map[string][]bridge.Event{}
+	)
+	d := &demuxer{
+		model: sm,
+		cfg: Config{
+			PayloadTypes: DefaultPayloadTypes,
+			IdleTimeout:  time.Second,
+			StreamID:     func(ssrc uint32, _ net.Addr) string { return fmt.Sprintf("%08x", ssrc) },
+		},
+		sink: bridge.SinkFunc(func(ev bridge.Event) error {
+			mu.Lock()
+			defer mu.Unlock()
+			events[ev.Source] = append(events[ev.Source], ev)
+			return nil
+		}),
+		workers: make(map[uint32]*worker),
+	}
+
+	// 两路流交错分发，另有一个不支持的负载类型被忽略；
+	// 队列满时 dispatch 会按丢包处理，这里等 worker 腾出空间再分发，使结果确定
+	now := time.Now()
+	for i := 0; i*frameBytes < len(ulaw); i++ {
+		payload := ulaw[i*frameBytes : (i+1)*frameBytes]
+		for _, w := range d.workers {
+			for w.packets != nil && len(w.packets) == cap(w.packets) {
+				time.Sleep(time.Millisecond)
+			}
+		}
+		for _, ssrc := range []uint32{0xa1, 0xb2} {
+			data, err := newPacket(ssrc, i, payload).Marshal()
+			require.NoError(t, err)
+			d.dispatch(data, nil, now)
+		}
+		video := newPacket(0xc3, i, payload)
+		video.PayloadType = 96
+		data, err := video.Marshal()
+		require.NoError(t, err)
+		d.dispatch(data, nil, now)
+	}
+	require.Len(t, d.workers, 2)
+
+	// 超时后两路流都结束，并补发了最后的停止事件
+	d.sweep(now.Add(d.cfg.IdleTimeout))
+	require.Empty(t, d.workers)
+	d.closeAll()
+
+	require.Len(t, events, 2)
+	for id, evs := range events {
+		require.Len(t, evs, len(expected)+len(expected)%2, id)
+		require.False(t, evs[len(evs)-1].Speaking, id)
+	}
+}
+
+func TestServe(t *testing.T) {
+	sm, ulaw := setup(t)
+
+	conn := newMemConn()
+	var (
+		mu      sync.Mutex
+		events  = map[string][]bridge.Event{}
+		created []string
+	)
+	cfg := Config{
+		IdleTimeout: 100 * time.Millisecond,
+		StreamID: func(ssrc uint32, _ net.Addr) string {
+			id := fmt.Sprintf("%08x", ssrc)
+			mu.Lock()
+			defer mu.Unlock()
+			created = append(created, id)
+			return id
+		},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() {
+		served <- Serve(ctx, conn, sm, cfg, bridge.SinkFunc(func(ev bridge.Event) error {
+			mu.Lock()
+			defer mu.Unlock()
+			events[ev.Source] = append(events[ev.Source], ev)
+			return nil
+		}))
+	}()
+
+	send := func(pkt *rtp.Packet) {
+		data, err := pkt.Marshal()
+		require.NoError(t, err)
+		conn.deliver(data)
+	}
+	// 两路流各发送第一个片段附近的 40 个包（少于队列长度，不会被丢弃），另有一个不支持的负载类型被忽略
+	for i := 50; i < 90; i++ {
+		payload := ulaw[i*frameBytes : (i+1)*frameBytes]
+		send(newPacket(0xa1, i, payload))
+		send(newPacket(0xb2, i, payload))
+		video := newPacket(0xc3, i, payload)
+		video.PayloadType = 96
+		send(video)
+	}
+
+	// 超时后两路流都结束，语音以停止事件收尾
+	ended := func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, id := range []string{"000000a1", "000000b2"} {
+			evs := events[id]
+			if len(evs) < 2 || evs[len(evs)-1].Speaking {
+				return false
+			}
+		}
+		return true
+	}
+	require.Eventually(t, ended, 10*time.Second, 10*time.Millisecond)
+
+	// 超时结束的流再收到包时作为新的流
+	time.Sleep(3 * cfg.IdleTimeout)
+	send(newPacket(0xa1, 90, ulaw[90*frameBytes:91*frameBytes]))
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(created) == 3
+	}, 10*time.Second, 10*time.Millisecond)
+
+	cancel()
+	require.NoError(t, <-served)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, []string{"000000a1", "000000b2", "000000a1"}, created)
+	require.Len(t, events, 2)
+}
+
+// memConn 内存中的 net.PacketConn，deliver 投递的包按顺序且不丢失地由 ReadFrom 读出
+type memConn struct {
+	packets chan []byte
+	closed  chan struct{}
+	once    sync.Once
+
+	mu       sync.Mutex
+	deadline time.Time
+}
+
+func newMemConn() *memConn {
+	return &memConn{packets: make(chan []byte, 1024), closed: make(chan struct{})}
+}
+
+func (c *memConn) deliver(data []byte) {
+	c.packets <- data
+}
+
+func (c *memConn) ReadFrom(p []byte) (int, net.Addr, error) {
+	c.mu.Lock()
+	deadline := c.deadline
+	c.mu.Unlock()
+
+	var timeout <-chan time.Time
+	if !deadline.IsZero() {
+		timer := time.NewTimer(time.Until(deadline))
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	select {
+	case data := <-c.packets:
+		return copy(p, data), c.LocalAddr(), nil
+	case <-c.closed:
+		return 0, nil, net.ErrClosed
+	case <-timeout:
+		return 0, nil, os.ErrDeadlineExceeded
+	}
+}
+
+func (c *memConn) WriteTo(p []byte, _ net.Addr) (int, error) {
+	c.deliver(append([]byte(nil), p...))
+	return len(p), nil
+}
+
+func (c *memConn) Close() error {
+	c.once.Do(func() { close(c.closed) })
+	return nil
+}
+
+func (c *memConn) LocalAddr() net.Addr {
+	return &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 40000}
+}
+
+func (c *memConn) SetDeadline(t time.Time) error {
+	return c.SetReadDeadline(t)
+}
+
+func (c *memConn) SetReadDeadline(t time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.deadline = t
+	return nil
+}
+
+func (c *memConn) SetWriteDeadline(time.Time) error {
+	return nil
+}
@@ -0,0 +1,190 @@
+package rtpvad
+
+import (
+	"fmt"
+
+	"github.com/pion/rtp"
+
+	"github.com/rui-yang-me/silero-vad-go/audio"
+	"github.com/rui-yang-me/silero-vad-go/bridge"
+	"github.com/rui-yang-me/silero-vad-go/speech"
+)
+
+const (
+	// maxConcealedPackets 连续丢包不超过该数量且编码支持时使用丢包补偿，否则按时间戳补静音
+	maxConcealedPackets = 5
+	// maxGapSeconds 时间戳跳变超过该值时视为流重置，不再补静音
+	maxGapSeconds = 10
+)
+
+// payloadDecoder 把一个 RTP 负载解码为模型采样率的单声道采样，payload 为 nil 时执行丢包补偿
+type payloadDecoder interface {
+	Decode(dst []float32, payload []byte) ([]float32, error)
+	Close()
+}
+
+// g711Decoder 解码 G.711 并重采样到模型采样率，不支持丢包补偿
+type g711Decoder struct {
+	format    audio.SampleFormat
+	resampler *audio.Resampler
+	pcm       []float32
+}
+
+func newG711Decoder(format audio.SampleFormat, sampleRate int) (*g711Decoder, error) {
+	d := &g711Decoder{format: format}
+	if sampleRate != g711ClockRate {
+		var err error
+		if d.resampler, err = audio.NewResampler(g711ClockRate, sampleRate); err != nil {
+			return nil, err
+		}
+	}
+	return d, nil
+}
+
+func (d *g711Decoder) Decode(dst []float32, payload []byte) ([]float32, error) {
+	if payload == nil {
+		return dst, nil
+	}
+	if d.format == audio.FormatALaw {
+		d.pcm = audio.DecodeALaw(d.pcm, payload)
+	} else {
+		d.pcm = audio.DecodeULaw(d.pcm, payload)
+	}
+	if d.resampler == nil {
+		return append(dst, d.pcm...), nil
+	}
+	return d.resampler.Process(dst, d.pcm), nil
+}
+
+func (d *g711Decoder) Close() {}
+
+// stream 一个 SSRC 的流式检测状态，只在自己的 goroutine 中使用
+type stream struct {
+	id         string
+	sampleRate int
+	clockRate  int
+	plc        bool
+	dc         *speech.DetectorContext
+	dec        payloadDecoder
+	chunker    *speech.StreamChunker
+
+	started     bool
+	lastSeq     uint16
+	lastTS      uint32
+	lastSamples int // 上一个包解码出的采样数
+	written     int // 写入 chunker 的采样数
+	speaking    bool
+	start       float64
+	pcm         []float32
+}
+
+func newStream(model *speech.SharedModel, id string, codec Codec) (*stream, error) {
+	sampleRate := model.GetConfig().SampleRate
+	chunker, err := speech.NewStreamChunker(sampleRate)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &stream{id: id, sampleRate: sampleRate, chunker: chunker}
+	switch codec {
+	case CodecPCMU, CodecPCMA:
+		format := audio.FormatULaw
+		if codec == CodecPCMA {
+			format = audio.FormatALaw
+		}
+		if s.dec, err = newG711Decoder(format, sampleRate); err != nil {
+			return nil, err
+		}
+		s.clockRate = g711ClockRate
+	case CodecOpus:
+		if s.dec, err = audio.NewOpusDecoder(sampleRate, 1); err != nil {
+			return nil, err
+		}
+		s.clockRate = opusClockRate
+		s.plc = true
+	default:
+		return nil, fmt.Errorf("unsupported codec: %d", codec)
+	}
+
+	s.dc = model.NewContext()
+	return s, nil
+}
+
+// write 处理一个 RTP 包，返回由此产生的事件
+// 重复或迟到的包会被丢弃；Opus 的少量丢包使用丢包补偿，其它中断按时间戳补静音。
+func (s *stream) write(pkt *rtp.Packet) ([]bridge.Event, error) {
+	pcm := s.pcm[:0]
+
+	if s.started {
+		delta := int16(pkt.SequenceNumber - s.lastSeq)
+		if delta <= 0 {
+			return nil, nil
+		}
+
+		if lost := int(delta) - 1; lost > 0 && lost <= maxConcealedPackets && s.plc {
+			for i := 0; i < lost; i++ {
+				var err error
+				if pcm, err = s.dec.Decode(pcm, nil); err != nil {
+					return nil, err
+				}
+			}
+		} else if lost > 0 {
+			ticks := int64(int32(pkt.Timestamp - s.lastTS))
+			gap := ticks*int64(s.sampleRate)/int64(s.clockRate) - int64(s.lastSamples)
+			if gap > 0 && gap <= int64(maxGapSeconds*s.sampleRate) {
+				pcm = append(pcm, make([]float32, gap)...)
+			}
+		}
+	}
+
+	s.started = true
+	s.lastSeq = pkt.SequenceNumber
+	s.lastTS = pkt.Timestamp
+
+	if len(pkt.Payload) > 0 {
+		n := len(pcm)
+		var err error
+		if pcm, err = s.dec.Decode(pcm, pkt.Payload); err != nil {
+			return nil, err
+		}
+		s.lastSamples = len(pcm) - n
+	}
+	s.pcm = pcm
+
+	s.chunker.Write(pcm)
+	s.written += len(pcm)
+	segments, err := s.dc.DetectChunks(s.chunker)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []bridge.Event
+	for _, seg := range segments {
+		if !s.speaking {
+			events = append(events, bridge.Event{Source: s.id, Speaking: true, Segment: speech.Segment{SpeechStartAt: seg.SpeechStartAt}})
+			s.start = seg.SpeechStartAt
+		}
+		s.speaking = seg.SpeechEndAt == 0
+		if !s.speaking {
+			events = append(events, bridge.Event{Source: s.id, Segment: seg})
+		}
+	}
+	return events, nil
+}
+
+// end 在流超时或监听结束时调用，尚未结束的语音以流的结尾作为终点
+func (s *stream) end() []bridge.Event {
+	if !s.speaking {
+		return nil
+	}
+	s.speaking = false
+	return []bridge.Event{{Source: s.id, Segment: speech.Segment{
+		SpeechStartAt: s.start,
+		SpeechEndAt:   float64(s.written) / float64(s.sampleRate),
+	}}}
+}
+
+func (s *stream) close() {
+	s.dec.Close()
+	s.dc.Close()
+}
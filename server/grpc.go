@@ -0,0 +1,132 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/rui-yang-me/silero-vad-go/speech"
+)
+
+const (
+	serviceName      = "silerovad.v1.VAD"
+	streamDetectName = "/" + serviceName + "/StreamDetect"
+)
+
+// serviceDesc 对应 proto/silerovad/v1/vad.proto 中的 VAD 服务
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: serviceName,
+	HandlerType: (*any)(nil),
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamDetect",
+			Handler:       streamDetectHandler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "silerovad/v1/vad.proto",
+}
+
+// NewGRPCServer 创建注册了 VAD 服务的 grpc.Server
+// 本包的消息不依赖 protoc 生成的代码，因此需要通过 grpc.ForceServerCodec 安装专用的编解码器；
+// 其它服务的消息仍交给默认的 protobuf 编解码器处理，可以注册到同一个 Server 上。
+func NewGRPCServer(s *Server, opts ...grpc.ServerOption) *grpc.Server {
+	gs := grpc.NewServer(append(opts, grpc.ForceServerCodec(codec{}))...)
+	gs.RegisterService(&serviceDesc, s)
+	return gs
+}
+
+func streamDetectHandler(srv any, stream grpc.ServerStream) error {
+	return srv.(*Server).streamDetect(stream)
+}
+
+func (s *Server) streamDetect(stream grpc.ServerStream) error {
+	dc := s.model.NewContext()
+	defer dc.Close()
+
+	chunker, err := speech.NewStreamChunker(s.model.GetConfig().SampleRate)
+	if err != nil {
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+
+	var events eventTracker
+	for {
+		var chunk AudioChunk
+		if err := stream.RecvMsg(&chunk); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+
+		format, err := chunk.Encoding.SampleFormat()
+		if err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+		if err := chunker.WriteBytes(chunk.Audio, format); err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+
+		segments, err := dc.DetectChunks(chunker)
+		if err != nil {
+			if errors.Is(err, speech.ErrModelDestroyed) {
+				return status.Error(codes.Unavailable, err.Error())
+			}
+			return status.Error(codes.Internal, err.Error())
+		}
+
+		for _, ev := range events.update(segments) {
+			if err := stream.SendMsg(&ev); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Client VAD 服务的 Go 客户端
+type Client struct {
+	cc grpc.ClientConnInterface
+}
+
+// NewClient 基于已建立的连接创建客户端
+func NewClient(cc grpc.ClientConnInterface) *Client {
+	return &Client{cc: cc}
+}
+
+// DetectStream 一次 StreamDetect 调用
+type DetectStream struct {
+	stream grpc.ClientStream
+}
+
+// StreamDetect 打开一个双向流
+func (c *Client) StreamDetect(ctx context.Context, opts ...grpc.CallOption) (*DetectStream, error) {
+	stream, err := c.cc.NewStream(ctx, &serviceDesc.Streams[0], streamDetectName, append(opts, grpc.ForceCodec(codec{}))...)
+	if err != nil {
+		return nil, err
+	}
+	return &DetectStream{stream: stream}, nil
+}
+
+// Send 发送一块音频
+func (s *DetectStream) Send(chunk *AudioChunk) error {
+	return s.stream.SendMsg(chunk)
+}
+
+// CloseSend 表示音频已发送完毕，之后仍可继续 Recv 剩余的事件
+func (s *DetectStream) CloseSend() error {
+	return s.stream.CloseSend()
+}
+
+// Recv 接收下一个事件，服务端结束时返回 io.EOF
+func (s *DetectStream) Recv() (*VADEvent, error) {
+	var ev VADEvent
+	if err := s.stream.RecvMsg(&ev); err != nil {
+		return nil, err
+	}
+	return &ev, nil
+}
@@ -0,0 +1,97 @@
+package server
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/rui-yang-me/silero-vad-go/internal/testutil"
+	"github.com/rui-yang-me/silero-vad-go/speech"
+)
+
+func TestGRPCStreamDetect(t *testing.T) {
+	sm := testutil.NewSharedModel(t)
+	srv, err := New(sm)
+	require.NoError(t, err)
+
+	lis := bufconn.Listen(1 << 20)
+	gs := NewGRPCServer(srv)
+	go gs.Serve(lis)
+	defer gs.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	samples := testutil.ReadSamples(t)
+	dc := sm.NewContext()
+	defer dc.Close()
+	expected, err := dc.Detect(samples)
+	require.NoError(t, err)
+
+	stream, err := NewClient(conn).StreamDetect(context.Background())
+	require.NoError(t, err)
+
+	go func() {
+		data := make([]byte, 4*len(samples))
+		for i, v := range samples {
+			binary.LittleEndian.PutUint32(data[4*i:], math.Float32bits(v))
+		}
+		for off := 0; off < len(data); off += 1280 {
+			if err := stream.Send(&AudioChunk{Audio: data[off:min(off+1280, len(data))], Encoding: AudioEncodingFloat32}); err != nil {
+				return
+			}
+		}
+		stream.CloseSend()
+	}()
+
+	var events []*VADEvent
+	for {
+		ev, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		events = append(events, ev)
+	}
+
+	// 每个片段一个开始事件，已结束的片段再加一个结束事件
+	var starts, ends []speech.Segment
+	for _, ev := range events {
+		switch ev.Type {
+		case EventTypeSpeechStart:
+			starts = append(starts, speech.Segment{SpeechStartAt: ev.Start})
+		case EventTypeSpeechEnd:
+			ends = append(ends, speech.Segment{SpeechStartAt: ev.Start, SpeechEndAt: ev.End})
+		}
+	}
+	require.Len(t, starts, len(expected))
+	for i, seg := range expected {
+		require.Equal(t, seg.SpeechStartAt, starts[i].SpeechStartAt)
+		if seg.SpeechEndAt > 0 {
+			require.Equal(t, seg, ends[i])
+		}
+	}
+
+	// 不支持的编码返回 InvalidArgument
+	stream, err = NewClient(conn).StreamDetect(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&AudioChunk{Audio: []byte{0}, Encoding: 42}))
+	_, err = stream.Recv()
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
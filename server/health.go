@@ -0,0 +1,81 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// healthStatus 健康检查接口的响应
+type healthStatus struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// probe 在一个新的上下文上对一个静音窗口推理，验证模型仍然可用
+func (s *Server) probe() error {
+	dc := s.model.NewContext()
+	defer dc.Close()
+
+	// IsSpeech 需要多于一个窗口的输入才会推理
+	_, err := dc.IsSpeech(make([]float32, 2*512))
+	return err
+}
+
+// Warmup 在会话池的每个会话上执行一次推理，成功后服务进入就绪状态
+// ONNX Runtime 的会话和执行提供程序在首次推理时才完成部分初始化，
+// 启动时调用可以让首个请求不承担这部分延迟，并尽早发现加载失败。
+func (s *Server) Warmup() error {
+	sessions := max(s.model.GetConfig().SessionPoolSize, 1)
+	for i := 0; i < sessions; i++ {
+		// 上下文按轮询顺序绑定会话，连续创建 sessions 个即可覆盖全部会话
+		if err := s.probe(); err != nil {
+			return fmt.Errorf("warmup inference failed: %w", err)
+		}
+	}
+	s.ready.Store(true)
+	return nil
+}
+
+// SetReady 手动设置就绪状态，例如在优雅停止时置为 false，使负载均衡不再分配新流量
+func (s *Server) SetReady(ready bool) {
+	s.ready.Store(ready)
+}
+
+// HealthHandler 返回存活检查（/healthz）接口，模型被销毁后返回 503
+func (s *Server) HealthHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.model.Destroyed() {
+			writeHealth(w, http.StatusServiceUnavailable, fmt.Errorf("model destroyed"))
+			return
+		}
+		writeHealth(w, http.StatusOK, nil)
+	})
+}
+
+// ReadyHandler 返回就绪检查（/readyz）接口
+// 只有 Warmup 成功、未被 SetReady(false) 且此时的探测推理成功时返回 200，否则返回 503。
+func (s *Server) ReadyHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.ready.Load() {
+			writeHealth(w, http.StatusServiceUnavailable, fmt.Errorf("not ready"))
+			return
+		}
+		if err := s.probe(); err != nil {
+			writeHealth(w, http.StatusServiceUnavailable, err)
+			return
+		}
+		writeHealth(w, http.StatusOK, nil)
+	})
+}
+
+func writeHealth(w http.ResponseWriter, status int, err error) {
+	resp := healthStatus{Status: "ok"}
+	if err != nil {
+		resp = healthStatus{Status: "unavailable", Error: err.Error()}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resp)
+}
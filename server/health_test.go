@@ -0,0 +1,61 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rui-yang-me/silero-vad-go/speech"
+)
+
+func getHealth(t *testing.T, h http.Handler) (int, healthStatus) {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	var resp healthStatus
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	return rec.Code, resp
+}
+
+func TestHealthHandlers(t *testing.T) {
+	sm, err := speech.NewSharedModel(speech.DetectorConfig{
+		ModelPath:       "../testfiles/silero_vad.onnx",
+		SampleRate:      16000,
+		Threshold:       0.5,
+		SessionPoolSize: 2,
+	})
+	require.NoError(t, err)
+	srv, err := New(sm)
+	require.NoError(t, err)
+
+	// 预热之前存活但未就绪
+	code, _ := getHealth(t, srv.HealthHandler())
+	require.Equal(t, http.StatusOK, code)
+	code, resp := getHealth(t, srv.ReadyHandler())
+	require.Equal(t, http.StatusServiceUnavailable, code)
+	require.Equal(t, "unavailable", resp.Status)
+
+	require.NoError(t, srv.Warmup())
+	require.EqualValues(t, 2, sm.Stats().Inferences)
+	code, resp = getHealth(t, srv.ReadyHandler())
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "ok", resp.Status)
+
+	// 优雅停止时先摘除流量
+	srv.SetReady(false)
+	code, _ = getHealth(t, srv.ReadyHandler())
+	require.Equal(t, http.StatusServiceUnavailable, code)
+	srv.SetReady(true)
+
+	require.NoError(t, sm.Destroy())
+	code, resp = getHealth(t, srv.ReadyHandler())
+	require.Equal(t, http.StatusServiceUnavailable, code)
+	require.Contains(t, resp.Error, "destroyed")
+	code, _ = getHealth(t, srv.HealthHandler())
+	require.Equal(t, http.StatusServiceUnavailable, code)
+	require.ErrorIs(t, srv.Warmup(), speech.ErrModelDestroyed)
+}
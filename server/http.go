@@ -0,0 +1,237 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/rui-yang-me/silero-vad-go/audio"
+	"github.com/rui-yang-me/silero-vad-go/speech"
+)
+
+// defaultMaxBodyBytes 未设置 HTTPConfig.MaxBodyBytes 时允许的请求体大小
+const defaultMaxBodyBytes = 50 << 20
+
+// HTTPConfig HTTP 接口的资源限制
+type HTTPConfig struct {
+	// 请求体的最大字节数，0 表示默认 50MB
+	MaxBodyBytes int64
+	// 解码后音频的最大时长，0 表示不限制
+	MaxDuration time.Duration
+	// 同时处理的最大请求数，超出时直接返回 503，0 表示不限制
+	MaxConcurrent int
+}
+
+type httpHandler struct {
+	model *speech.SharedModel
+	cfg   HTTPConfig
+	sem   chan struct{}
+}
+
+// httpError 携带 HTTP 状态码的错误
+type httpError struct {
+	status int
+	err    error
+}
+
+func (e *httpError) Error() string {
+	return e.err.Error()
+}
+
+// HTTPHandler 返回批量检测的 http.Handler，可以挂载到任意路由下
+//
+// 请求使用 POST，请求体为完整的音频：
+//   - Content-Type 为 audio/wav（或请求体以 RIFF 开头）时按 WAV 解析，多声道会被混合为单声道；
+//   - 否则按查询参数 format 解析（pcm16、float32、ulaw、alaw、oggopus、mp3、flac，默认 pcm16），
+//     原始格式可以通过 sample_rate 参数声明采样率。
+//
+// 采样率与模型不一致时会自动重采样，响应为 speech.SegmentReport 的 JSON。
+func (s *Server) HTTPHandler(cfg HTTPConfig) http.Handler {
+	if cfg.MaxBodyBytes <= 0 {
+		cfg.MaxBodyBytes = defaultMaxBodyBytes
+	}
+	h := &httpHandler{model: s.model, cfg: cfg}
+	if cfg.MaxConcurrent > 0 {
+		h.sem = make(chan struct{}, cfg.MaxConcurrent)
+	}
+	return h
+}
+
+func (h *httpHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeHTTPError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		return
+	}
+
+	if h.sem != nil {
+		select {
+		case h.sem <- struct{}{}:
+			defer func() { <-h.sem }()
+		default:
+			writeHTTPError(w, http.StatusServiceUnavailable, errors.New("too many concurrent requests"))
+			return
+		}
+	}
+
+	report, err := h.detect(w, r)
+	if err != nil {
+		var he *httpError
+		if errors.As(err, &he) {
+			writeHTTPError(w, he.status, he.err)
+		} else if errors.Is(err, speech.ErrModelDestroyed) {
+			writeHTTPError(w, http.StatusServiceUnavailable, err)
+		} else {
+			writeHTTPError(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	report.WriteJSON(w)
+}
+
+func (h *httpHandler) detect(w http.ResponseWriter, r *http.Request) (speech.SegmentReport, error) {
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, h.cfg.MaxBodyBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return speech.SegmentReport{}, &httpError{http.StatusRequestEntityTooLarge, err}
+		}
+		return speech.SegmentReport{}, &httpError{http.StatusBadRequest, fmt.Errorf("failed to read body: %w", err)}
+	}
+
+	cfg := h.model.GetConfig()
+	maxSamples := 0
+	if h.cfg.MaxDuration > 0 {
+		maxSamples = int(h.cfg.MaxDuration.Seconds() * float64(cfg.SampleRate))
+	}
+	pcm, err := decodeUpload(r, data, cfg.SampleRate, maxSamples)
+	if errors.Is(err, audio.ErrTooLong) {
+		return speech.SegmentReport{}, &httpError{http.StatusRequestEntityTooLarge, fmt.Errorf("audio longer than %s", h.cfg.MaxDuration)}
+	}
+	if err != nil {
+		return speech.SegmentReport{}, err
+	}
+
+	if h.cfg.MaxDuration > 0 && time.Duration(len(pcm))*time.Second/time.Duration(cfg.SampleRate) > h.cfg.MaxDuration {
+		return speech.SegmentReport{}, &httpError{http.StatusRequestEntityTooLarge, fmt.Errorf("audio longer than %s", h.cfg.MaxDuration)}
+	}
+
+	dc := h.model.GetContext()
+	defer h.model.PutContext(dc)
+
+	segments, err := dc.Detect(pcm)
+	if err != nil {
+		return speech.SegmentReport{}, err
+	}
+	return speech.NewSegmentReport(cfg, segments), nil
+}
+
+// decodeUpload 把上传的音频解码为模型采样率的单声道采样
+// maxSamples 大于 0 时按模型采样率限制音频长度，超过时在解码和重采样之前返回 audio.ErrTooLong。
+func decodeUpload(r *http.Request, data []byte, sampleRate, maxSamples int) ([]float32, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "audio/wav" || mediaType == "audio/x-wav" || mediaType == "audio/wave" || bytes.HasPrefix(data, []byte("RIFF")) {
+		// 请求体已经在内存中，直接解析而不是按头部声明的长度重新分配
+		info, raw, err := audio.ParseWAV(data)
+		if err != nil {
+			return nil, &httpError{http.StatusBadRequest, err}
+		}
+		if err := checkUploadRate(info.SampleRate, sampleRate); err != nil {
+			return nil, err
+		}
+		frameBytes := max(1, info.Channels*info.BitsPerSample/8)
+		if limit := inputLimit(maxSamples, info.SampleRate, sampleRate); limit > 0 && len(raw)/frameBytes > limit {
+			return nil, fmt.Errorf("%w: more than %d samples", audio.ErrTooLong, maxSamples)
+		}
+		pcm, err := audio.DecodeWAVSamples(nil, raw, info)
+		if err != nil {
+			return nil, &httpError{http.StatusBadRequest, err}
+		}
+		if info.Channels > 1 {
+			if pcm, err = audio.Downmix(pcm, info.Channels); err != nil {
+				return nil, &httpError{http.StatusBadRequest, err}
+			}
+		}
+		return resampleUpload(pcm, info.SampleRate, sampleRate)
+	}
+
+	query := r.URL.Query()
+	format := audio.FormatPCM16
+	if name := query.Get("format"); name != "" {
+		f, err := audio.ParseSampleFormat(name)
+		if err != nil {
+			return nil, &httpError{http.StatusUnsupportedMediaType, err}
+		}
+		format = f
+	}
+
+	// 压缩格式由 DecodeBytesLimit 直接解码到目标采样率，只有原始格式需要按声明的采样率重采样
+	inRate := sampleRate
+	limit := maxSamples
+	if rate := query.Get("sample_rate"); rate != "" && format.BytesPerSample() > 0 {
+		v, err := strconv.Atoi(rate)
+		if err != nil || v <= 0 {
+			return nil, &httpError{http.StatusBadRequest, fmt.Errorf("invalid sample_rate: %q", rate)}
+		}
+		if err := checkUploadRate(v, sampleRate); err != nil {
+			return nil, err
+		}
+		inRate = v
+		limit = inputLimit(maxSamples, inRate, sampleRate)
+	}
+
+	pcm, err := audio.DecodeBytesLimit(data, format, sampleRate, limit)
+	if errors.Is(err, audio.ErrTooLong) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, &httpError{http.StatusBadRequest, err}
+	}
+	return resampleUpload(pcm, inRate, sampleRate)
+}
+
+// checkUploadRate 在重采样之前检查上传音频的采样率
+func checkUploadRate(inRate, outRate int) error {
+	if inRate == outRate {
+		return nil
+	}
+	if err := audio.CheckResampleRate(inRate); err != nil {
+		return &httpError{http.StatusBadRequest, err}
+	}
+	return nil
+}
+
+// inputLimit 把模型采样率下的长度上限换算为输入采样率下的采样数，0 表示不限制
+func inputLimit(maxSamples, inRate, outRate int) int {
+	if maxSamples <= 0 {
+		return 0
+	}
+	return int(int64(maxSamples) * int64(inRate) / int64(outRate))
+}
+
+func resampleUpload(pcm []float32, inRate, outRate int) ([]float32, error) {
+	if inRate == outRate {
+		return pcm, nil
+	}
+	out, err := audio.Resample(pcm, inRate, outRate)
+	if err != nil {
+		return nil, &httpError{http.StatusBadRequest, err}
+	}
+	return out, nil
+}
+
+func writeHTTPError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(struct {
+		Error string `json:"error"`
+	}{err.Error()})
+}
@@ -0,0 +1,110 @@
+package server
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rui-yang-me/silero-vad-go/audio"
+	"github.com/rui-yang-me/silero-vad-go/internal/testutil"
+	"github.com/rui-yang-me/silero-vad-go/speech"
+)
+
+func TestHTTPHandler(t *testing.T) {
+	sm := testutil.NewSharedModel(t)
+	srv, err := New(sm)
+	require.NoError(t, err)
+
+	samples := testutil.ReadSamples(t)
+	dc := sm.NewContext()
+	defer dc.Close()
+	expected, err := dc.Detect(samples)
+	require.NoError(t, err)
+
+	ts := httptest.NewServer(srv.HTTPHandler(HTTPConfig{MaxBodyBytes: 8 << 20, MaxDuration: time.Minute}))
+	defer ts.Close()
+
+	post := func(t *testing.T, url, contentType string, body []byte) (*http.Response, speech.SegmentReport) {
+		t.Helper()
+		resp, err := http.Post(url, contentType, bytes.NewReader(body))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		var report speech.SegmentReport
+		if resp.StatusCode == http.StatusOK {
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&report))
+		}
+		return resp, report
+	}
+
+	t.Run("wav", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, audio.WriteWAV(&buf, samples, audio.WAVInfo{SampleRate: 16000, Channels: 1, BitsPerSample: 32, Float: true}))
+
+		resp, report := post(t, ts.URL, "audio/wav", buf.Bytes())
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, 16000, report.SampleRate)
+		require.Equal(t, speech.Segments(expected), report.Segments)
+
+		// 头部声明的 data 长度远大于请求体时只解码实际上传的数据
+		wav := buf.Bytes()
+		off := bytes.Index(wav, []byte("data")) + 4
+		binary.LittleEndian.PutUint32(wav[off:off+4], 0xFFFFFFF0)
+		resp, report = post(t, ts.URL, "audio/wav", wav)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, speech.Segments(expected), report.Segments)
+	})
+
+	t.Run("raw float32", func(t *testing.T) {
+		data := make([]byte, 4*len(samples))
+		for i, v := range samples {
+			binary.LittleEndian.PutUint32(data[4*i:], math.Float32bits(v))
+		}
+
+		resp, report := post(t, ts.URL+"?format=float32&sample_rate=16000", "application/octet-stream", data)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, speech.Segments(expected), report.Segments)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		resp, err := http.Get(ts.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+
+		resp, _ = post(t, ts.URL+"?format=aac", "application/octet-stream", []byte{0, 0})
+		require.Equal(t, http.StatusUnsupportedMediaType, resp.StatusCode)
+
+		resp, _ = post(t, ts.URL, "audio/wav", []byte("not a wav file"))
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		resp, _ = post(t, ts.URL, "application/octet-stream", make([]byte, 9<<20))
+		require.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+
+		// 2 分钟的 8kHz µ-law 不超过请求体限制，但超过时长限制
+		resp, _ = post(t, ts.URL+"?format=ulaw&sample_rate=8000", "application/octet-stream", make([]byte, 120*8000))
+		require.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+
+		// 超出范围的采样率在重采样之前拒绝，不会按巨大的比例分配内存
+		resp, _ = post(t, ts.URL+"?format=pcm16&sample_rate=1", "application/octet-stream", make([]byte, 200))
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		var wav bytes.Buffer
+		require.NoError(t, audio.WriteWAV(&wav, make([]float32, 100), audio.WAVInfo{SampleRate: 16000}))
+		binary.LittleEndian.PutUint32(wav.Bytes()[24:28], 2147483647)
+		resp, _ = post(t, ts.URL, "audio/wav", wav.Bytes())
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		// 采样率很低的 WAV 按声明的时长检查，不会先重采样为很长的音频
+		wav.Reset()
+		require.NoError(t, audio.WriteWAV(&wav, make([]float32, 61*1000), audio.WAVInfo{SampleRate: 1000}))
+		resp, _ = post(t, ts.URL, "audio/wav", wav.Bytes())
+		require.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+	})
+}
@@ -0,0 +1,182 @@
+package server
+
+import (
+	"fmt"
+	"math"
+
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/rui-yang-me/silero-vad-go/audio"
+)
+
+// 本文件按 proto/silerovad/v1/vad.proto 手写消息的编解码，线上格式与 protoc 生成的代码一致，
+// 从而不必在构建时依赖 protoc。修改 proto 时需要同步修改这里。
+
+// AudioEncoding 对应 proto 中的 silerovad.v1.AudioEncoding
+type AudioEncoding int32
+
+const (
+	AudioEncodingUnspecified AudioEncoding = iota
+	AudioEncodingPCM16
+	AudioEncodingFloat32
+	AudioEncodingULaw
+	AudioEncodingALaw
+)
+
+// SampleFormat 返回对应的 audio.SampleFormat，未指定时按 PCM16 处理
+func (e AudioEncoding) SampleFormat() (audio.SampleFormat, error) {
+	switch e {
+	case AudioEncodingUnspecified, AudioEncodingPCM16:
+		return audio.FormatPCM16, nil
+	case AudioEncodingFloat32:
+		return audio.FormatFloat32, nil
+	case AudioEncodingULaw:
+		return audio.FormatULaw, nil
+	case AudioEncodingALaw:
+		return audio.FormatALaw, nil
+	default:
+		return 0, fmt.Errorf("unsupported audio encoding: %d", e)
+	}
+}
+
+// EventType 对应 proto 中的 silerovad.v1.EventType
+type EventType int32
+
+const (
+	EventTypeUnspecified EventType = iota
+	EventTypeSpeechStart
+	EventTypeSpeechEnd
+)
+
+// AudioChunk 客户端发送的一块音频
+type AudioChunk struct {
+	Audio    []byte
+	Encoding AudioEncoding
+}
+
+// VADEvent 服务端返回的语音开始/结束事件，时间为相对流开始的秒数
+type VADEvent struct {
+	Type  EventType
+	Start float64
+	End   float64
+}
+
+// wireMessage 由本包手写编解码的消息
+type wireMessage interface {
+	marshal() []byte
+	unmarshal(data []byte) error
+}
+
+func (m *AudioChunk) marshal() []byte {
+	var b []byte
+	if len(m.Audio) > 0 {
+		b = protowire.AppendTag(b, 1, protowire.BytesType)
+		b = protowire.AppendBytes(b, m.Audio)
+	}
+	if m.Encoding != 0 {
+		b = protowire.AppendTag(b, 2, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(m.Encoding))
+	}
+	return b
+}
+
+func (m *AudioChunk) unmarshal(data []byte) error {
+	*m = AudioChunk{}
+	return consumeFields(data, func(num protowire.Number, typ protowire.Type, data []byte) (int, error) {
+		switch {
+		case num == 1 && typ == protowire.BytesType:
+			v, n := protowire.ConsumeBytes(data)
+			m.Audio = append([]byte(nil), v...)
+			return n, nil
+		case num == 2 && typ == protowire.VarintType:
+			v, n := protowire.ConsumeVarint(data)
+			m.Encoding = AudioEncoding(int32(v))
+			return n, nil
+		}
+		return protowire.ConsumeFieldValue(num, typ, data), nil
+	})
+}
+
+func (m *VADEvent) marshal() []byte {
+	var b []byte
+	if m.Type != 0 {
+		b = protowire.AppendTag(b, 1, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(m.Type))
+	}
+	if m.Start != 0 {
+		b = protowire.AppendTag(b, 2, protowire.Fixed64Type)
+		b = protowire.AppendFixed64(b, math.Float64bits(m.Start))
+	}
+	if m.End != 0 {
+		b = protowire.AppendTag(b, 3, protowire.Fixed64Type)
+		b = protowire.AppendFixed64(b, math.Float64bits(m.End))
+	}
+	return b
+}
+
+func (m *VADEvent) unmarshal(data []byte) error {
+	*m = VADEvent{}
+	return consumeFields(data, func(num protowire.Number, typ protowire.Type, data []byte) (int, error) {
+		switch {
+		case num == 1 && typ == protowire.VarintType:
+			v, n := protowire.ConsumeVarint(data)
+			m.Type = EventType(int32(v))
+			return n, nil
+		case num == 2 && typ == protowire.Fixed64Type:
+			v, n := protowire.ConsumeFixed64(data)
+			m.Start = math.Float64frombits(v)
+			return n, nil
+		case num == 3 && typ == protowire.Fixed64Type:
+			v, n := protowire.ConsumeFixed64(data)
+			m.End = math.Float64frombits(v)
+			return n, nil
+		}
+		return protowire.ConsumeFieldValue(num, typ, data), nil
+	})
+}
+
+// consumeFields 依次解析每个字段，field 返回消耗的字节数（负数表示解析错误），未知字段应跳过
+func consumeFields(data []byte, field func(num protowire.Number, typ protowire.Type, data []byte) (int, error)) error {
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return fmt.Errorf("invalid message: %w", protowire.ParseError(n))
+		}
+		data = data[n:]
+
+		n, err := field(num, typ, data)
+		if err != nil {
+			return err
+		}
+		if n < 0 {
+			return fmt.Errorf("invalid field %d: %w", num, protowire.ParseError(n))
+		}
+		data = data[n:]
+	}
+	return nil
+}
+
+// codec 编解码本包的消息，其它类型交给 gRPC 默认的 protobuf 编解码器，
+// 因此同一个 grpc.Server 上注册的其它服务不受影响
+type codec struct{}
+
+var _ encoding.Codec = codec{}
+
+func (codec) Marshal(v any) ([]byte, error) {
+	if m, ok := v.(wireMessage); ok {
+		return m.marshal(), nil
+	}
+	return encoding.GetCodec("proto").Marshal(v)
+}
+
+func (codec) Unmarshal(data []byte, v any) error {
+	if m, ok := v.(wireMessage); ok {
+		return m.unmarshal(data)
+	}
+	return encoding.GetCodec("proto").Unmarshal(data, v)
+}
+
+func (codec) Name() string {
+	return "proto"
+}
@@ -0,0 +1,32 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func TestMessagesWireFormat(t *testing.T) {
+	chunk := &AudioChunk{Audio: []byte{1, 2}, Encoding: AudioEncodingULaw}
+	data := chunk.marshal()
+	require.Equal(t, []byte{0x0a, 0x02, 0x01, 0x02, 0x10, 0x03}, data)
+
+	// 未知字段（例如新版本 proto 增加的字段）会被跳过
+	data = protowire.AppendTag(data, 15, protowire.BytesType)
+	data = protowire.AppendString(data, "future")
+
+	var decoded AudioChunk
+	require.NoError(t, decoded.unmarshal(data))
+	require.Equal(t, *chunk, decoded)
+
+	ev := &VADEvent{Type: EventTypeSpeechEnd, Start: 1.056, End: 1.632}
+	var decodedEv VADEvent
+	require.NoError(t, decodedEv.unmarshal(ev.marshal()))
+	require.Equal(t, *ev, decodedEv)
+
+	require.Error(t, decodedEv.unmarshal([]byte{0x11, 0x01}))
+
+	_, err := AudioEncoding(9).SampleFormat()
+	require.Error(t, err)
+}
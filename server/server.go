@@ -0,0 +1,47 @@
+// Package server 通过网络提供语音检测服务，便于非 Go 服务使用共享的 VAD 模型。
+package server
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/rui-yang-me/silero-vad-go/speech"
+)
+
+// Server 基于共享模型的 VAD 服务，每个流使用独立的检测上下文
+type Server struct {
+	model *speech.SharedModel
+	// ready 由 Warmup 和 SetReady 设置，供就绪检查使用
+	ready atomic.Bool
+}
+
+// New 创建服务，model 的生命周期由调用方管理，需在服务停止后再 Destroy
+func New(model *speech.SharedModel) (*Server, error) {
+	if model == nil {
+		return nil, fmt.Errorf("invalid nil shared model")
+	}
+	return &Server{model: model}, nil
+}
+
+// eventTracker 把流式检测返回的片段转换为开始/结束事件
+// 尚未结束的片段在之后的调用中会以完整片段再次返回，此时只补发结束事件。
+type eventTracker struct {
+	open bool
+	// start 尚未结束的片段的开始时间
+	start float64
+}
+
+func (t *eventTracker) update(segments []speech.Segment) []VADEvent {
+	var events []VADEvent
+	for _, seg := range segments {
+		if !t.open {
+			events = append(events, VADEvent{Type: EventTypeSpeechStart, Start: seg.SpeechStartAt})
+			t.start = seg.SpeechStartAt
+		}
+		t.open = seg.SpeechEndAt == 0
+		if !t.open {
+			events = append(events, VADEvent{Type: EventTypeSpeechEnd, Start: seg.SpeechStartAt, End: seg.SpeechEndAt})
+		}
+	}
+	return events
+}
@@ -0,0 +1,222 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"sync"
+
+	"github.com/rui-yang-me/silero-vad-go/audio"
+	"github.com/rui-yang-me/silero-vad-go/speech"
+)
+
+// SocketConfig 本地套接字协议的配置
+type SocketConfig struct {
+	// 音频格式，必须是原始格式（pcm16、float32、ulaw、alaw），零值为 pcm16
+	Format audio.SampleFormat
+	// 音频采样率，0 表示与模型一致，不一致时自动重采样
+	SampleRate int
+	// 单帧的最大字节数，0 表示默认 1MB
+	MaxFrameBytes int
+}
+
+// ServeSocket 在 l（通常是 unix 域套接字）上提供长度前缀的流式检测协议，
+// 使同一主机上的其它语言进程无需加载 ONNX Runtime 即可使用检测。
+//
+// 请求和响应都是 4 字节大端序长度加负载的帧，每个请求帧对应一个响应帧：
+//   - 请求负载为一段小端序音频，响应负载为由此产生的事件的 JSON 数组，
+//     事件格式与 WebSocketHandler 相同，例如 [{"type":"speech_start","start":1.056}]；
+//   - 长度为 0 的请求表示当前音频流结束：尚未结束的语音以音频结尾作为终点返回 speech_end，
+//     之后检测状态被重置，同一连接可以开始新的音频流，时间重新从 0 计算。
+//
+// 每个连接使用独立的检测上下文。ctx 取消时关闭 l 和所有连接并返回 nil。
+func (s *Server) ServeSocket(ctx context.Context, l net.Listener, cfg SocketConfig) error {
+	if cfg.Format == 0 {
+		cfg.Format = audio.FormatPCM16
+	}
+	if cfg.Format.BytesPerSample() == 0 {
+		return fmt.Errorf("%w: streaming format %s", audio.ErrUnsupportedFormat, cfg.Format)
+	}
+	if cfg.SampleRate < 0 {
+		return fmt.Errorf("invalid sample rate: %d", cfg.SampleRate)
+	}
+	if cfg.SampleRate == 0 {
+		cfg.SampleRate = s.model.GetConfig().SampleRate
+	}
+	if cfg.MaxFrameBytes <= 0 {
+		cfg.MaxFrameBytes = defaultMaxMessageBytes
+	}
+
+	stop := context.AfterFunc(ctx, func() {
+		l.Close()
+	})
+	defer stop()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer conn.Close()
+
+			stopConn := context.AfterFunc(ctx, func() {
+				conn.Close()
+			})
+			defer stopConn()
+
+			if err := s.serveSocketConn(conn, cfg); err != nil && ctx.Err() == nil {
+				slog.Warn("socket connection failed", slog.Any("error", err))
+			}
+		}()
+	}
+}
+
+// socketStream 一个连接上的流式检测状态
+type socketStream struct {
+	cfg       SocketConfig
+	modelRate int
+	dc        *speech.DetectorContext
+	chunker   *speech.StreamChunker
+	resampler *audio.Resampler
+	events    eventTracker
+	written   int // 写入 chunker 的采样数，用于计算流结束时间
+
+	samples []float32
+	out     []float32
+}
+
+func (s *Server) serveSocketConn(conn net.Conn, cfg SocketConfig) error {
+	st := &socketStream{cfg: cfg, modelRate: s.model.GetConfig().SampleRate}
+	var err error
+	if st.chunker, err = speech.NewStreamChunker(st.modelRate); err != nil {
+		return err
+	}
+	if cfg.SampleRate != st.modelRate {
+		if st.resampler, err = audio.NewResampler(cfg.SampleRate, st.modelRate); err != nil {
+			return err
+		}
+	}
+	st.dc = s.model.NewContext()
+	defer st.dc.Close()
+
+	r := bufio.NewReader(conn)
+	w := bufio.NewWriter(conn)
+	var (
+		header [4]byte
+		frame  []byte
+	)
+	for {
+		if _, err := io.ReadFull(r, header[:]); err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+
+		n := int(binary.BigEndian.Uint32(header[:]))
+		if n > cfg.MaxFrameBytes {
+			return fmt.Errorf("frame too large: %d bytes", n)
+		}
+		if cap(frame) < n {
+			frame = make([]byte, n)
+		}
+		frame = frame[:n]
+		if _, err := io.ReadFull(r, frame); err != nil {
+			return fmt.Errorf("failed to read %d byte frame: %w", n, err)
+		}
+
+		var events []VADEvent
+		if n == 0 {
+			events, err = st.end()
+		} else {
+			events, err = st.write(frame)
+		}
+		if err != nil {
+			return err
+		}
+
+		resp := make([]wsEvent, len(events))
+		for i, ev := range events {
+			resp[i] = newWSEvent(ev)
+		}
+		payload, err := json.Marshal(resp)
+		if err != nil {
+			return err
+		}
+		binary.BigEndian.PutUint32(header[:], uint32(len(payload)))
+		if _, err := w.Write(header[:]); err != nil {
+			return err
+		}
+		if _, err := w.Write(payload); err != nil {
+			return err
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+}
+
+// write 检测一帧音频
+func (st *socketStream) write(frame []byte) ([]VADEvent, error) {
+	var err error
+	if st.samples, err = audio.BytesToFloat32(st.samples[:0], frame, st.cfg.Format, binary.LittleEndian); err != nil {
+		return nil, err
+	}
+	pcm := st.samples
+	if st.resampler != nil {
+		st.out = st.resampler.Process(st.out[:0], st.samples)
+		pcm = st.out
+	}
+	return st.detect(pcm)
+}
+
+func (st *socketStream) detect(pcm []float32) ([]VADEvent, error) {
+	st.chunker.Write(pcm)
+	st.written += len(pcm)
+	segments, err := st.dc.DetectChunks(st.chunker)
+	if err != nil {
+		return nil, err
+	}
+	return st.events.update(segments), nil
+}
+
+// end 结束当前音频流，补发未结束语音的结束事件并重置状态
+func (st *socketStream) end() ([]VADEvent, error) {
+	var events []VADEvent
+	if st.resampler != nil {
+		var err error
+		if events, err = st.detect(st.resampler.Flush(st.out[:0])); err != nil {
+			return nil, err
+		}
+		st.resampler.Reset()
+	}
+
+	if st.events.open {
+		events = append(events, VADEvent{
+			Type:  EventTypeSpeechEnd,
+			Start: st.events.start,
+			End:   float64(st.written) / float64(st.modelRate),
+		})
+	}
+
+	st.events = eventTracker{}
+	st.written = 0
+	st.chunker.Reset()
+	return events, st.dc.Reset()
+}
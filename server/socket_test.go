@@ -0,0 +1,97 @@
+package server
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"math"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rui-yang-me/silero-vad-go/audio"
+	"github.com/rui-yang-me/silero-vad-go/internal/testutil"
+)
+
+func writeFrame(t *testing.T, conn net.Conn, payload []byte) []wsEvent {
+	t.Helper()
+
+	var header [4]byte
+	binary.BigEndian.PutUint32(header[:], uint32(len(payload)))
+	_, err := conn.Write(append(header[:], payload...))
+	require.NoError(t, err)
+
+	_, err = io.ReadFull(conn, header[:])
+	require.NoError(t, err)
+	resp := make([]byte, binary.BigEndian.Uint32(header[:]))
+	_, err = io.ReadFull(conn, resp)
+	require.NoError(t, err)
+
+	var events []wsEvent
+	require.NoError(t, json.Unmarshal(resp, &events))
+	return events
+}
+
+func TestServeSocket(t *testing.T) {
+	sm := testutil.NewSharedModel(t)
+	srv, err := New(sm)
+	require.NoError(t, err)
+
+	samples := testutil.ReadSamples(t)
+	dc := sm.NewContext()
+	defer dc.Close()
+	expected, err := dc.Detect(samples)
+	require.NoError(t, err)
+
+	// unix 域套接字路径长度有限，不使用 t.TempDir
+	dir, err := os.MkdirTemp("", "vad")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	l, err := net.Listen("unix", filepath.Join(dir, "vad.sock"))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() {
+		served <- srv.ServeSocket(ctx, l, SocketConfig{Format: audio.FormatFloat32})
+	}()
+
+	conn, err := net.Dial("unix", l.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	data := make([]byte, 4*len(samples))
+	for i, v := range samples {
+		binary.LittleEndian.PutUint32(data[4*i:], math.Float32bits(v))
+	}
+
+	// 同一连接上连续检测两个音频流，第二个流的时间重新从 0 计算
+	for round := 0; round < 2; round++ {
+		var events []wsEvent
+		for off := 0; off < len(data); off += 4000 {
+			events = append(events, writeFrame(t, conn, data[off:min(off+4000, len(data))])...)
+		}
+		events = append(events, writeFrame(t, conn, nil)...)
+
+		require.Len(t, events, 2*len(expected))
+		for i, seg := range expected {
+			start, end := events[2*i], events[2*i+1]
+			require.Equal(t, "speech_start", start.Type)
+			require.Equal(t, seg.SpeechStartAt, start.Start)
+			require.Equal(t, "speech_end", end.Type)
+			require.Equal(t, seg.SpeechStartAt, end.Start)
+			if seg.SpeechEndAt > 0 {
+				require.Equal(t, seg.SpeechEndAt, *end.End)
+			} else {
+				require.Equal(t, float64(len(samples))/16000, *end.End)
+			}
+		}
+	}
+
+	cancel()
+	require.NoError(t, <-served)
+}
@@ -0,0 +1,243 @@
+package server
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/rui-yang-me/silero-vad-go/audio"
+	"github.com/rui-yang-me/silero-vad-go/speech"
+)
+
+const (
+	// twilioSampleRate Twilio Media Streams 固定使用 8kHz µ-law
+	twilioSampleRate = 8000
+	// TwilioMarkSpeechStart 语音开始时发送的 mark 名称
+	TwilioMarkSpeechStart = "speech_start"
+	// TwilioMarkSpeechEnd 语音结束时发送的 mark 名称
+	TwilioMarkSpeechEnd = "speech_end"
+)
+
+// TwilioConfig Twilio Media Streams 接口的配置
+type TwilioConfig struct {
+	// 校验请求的 Origin，nil 时只允许同源请求（Twilio 不发送 Origin，因此默认可以连接）
+	CheckOrigin func(r *http.Request) bool
+	// 为 true 时在语音开始时先发送 clear 消息，清空 Twilio 尚未播放的音频，实现打断（barge-in）
+	ClearOnSpeech bool
+	// 每个事件发送 mark 之后同步调用，可以为 nil；调用期间会阻塞读取音频
+	OnEvent func(stream *TwilioStream, ev VADEvent)
+}
+
+// TwilioStream 一路 Twilio 媒体流，可以在 OnEvent 中或其它 goroutine 中向 Twilio 回送消息
+type TwilioStream struct {
+	// start 消息中的流和通话标识
+	StreamSid string
+	CallSid   string
+	// TwiML <Stream> 中 <Parameter> 传入的自定义参数
+	CustomParameters map[string]string
+
+	mu   sync.Mutex
+	conn *websocket.Conn
+}
+
+// twilioMessage Twilio 发送的消息，只解析检测需要的字段
+type twilioMessage struct {
+	Event     string `json:"event"`
+	StreamSid string `json:"streamSid"`
+	Start     *struct {
+		CallSid          string            `json:"callSid"`
+		CustomParameters map[string]string `json:"customParameters"`
+		MediaFormat      struct {
+			Encoding   string `json:"encoding"`
+			SampleRate int    `json:"sampleRate"`
+		} `json:"mediaFormat"`
+	} `json:"start"`
+	Media *struct {
+		Track   string `json:"track"`
+		Payload string `json:"payload"`
+	} `json:"media"`
+}
+
+// twilioOutbound 发送给 Twilio 的消息
+type twilioOutbound struct {
+	Event     string         `json:"event"`
+	StreamSid string         `json:"streamSid"`
+	Media     *twilioPayload `json:"media,omitempty"`
+	Mark      *twilioMark    `json:"mark,omitempty"`
+}
+
+type twilioPayload struct {
+	Payload string `json:"payload"`
+}
+
+type twilioMark struct {
+	Name string `json:"name"`
+}
+
+func (s *TwilioStream) send(msg twilioOutbound) error {
+	msg.StreamSid = s.StreamSid
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.conn.WriteJSON(msg)
+}
+
+// SendMedia 向通话播放一段 8kHz µ-law 音频（双向流时有效）
+func (s *TwilioStream) SendMedia(ulaw []byte) error {
+	return s.send(twilioOutbound{Event: "media", Media: &twilioPayload{Payload: base64.StdEncoding.EncodeToString(ulaw)}})
+}
+
+// SendMark 发送 mark 消息，Twilio 会在此前的音频播放完毕后回送同名 mark
+func (s *TwilioStream) SendMark(name string) error {
+	return s.send(twilioOutbound{Event: "mark", Mark: &twilioMark{Name: name}})
+}
+
+// Clear 清空 Twilio 中尚未播放的音频
+func (s *TwilioStream) Clear() error {
+	return s.send(twilioOutbound{Event: "clear"})
+}
+
+// TwilioHandler 返回 Twilio Media Streams 的 WebSocket 接口，在 TwiML 中配置为 <Stream url="wss://...">
+//
+// 入站音频（base64 编码的 8kHz µ-law）被解码并重采样到模型采样率后做流式检测。
+// 语音开始和结束时分别向 Twilio 发送名为 speech_start 和 speech_end 的 mark 消息，
+// ClearOnSpeech 为 true 时还会在语音开始时清空待播放的音频，用于语音机器人的打断。
+func (s *Server) TwilioHandler(cfg TwilioConfig) http.Handler {
+	return &twilioHandler{
+		model:    s.model,
+		cfg:      cfg,
+		upgrader: websocket.Upgrader{CheckOrigin: cfg.CheckOrigin},
+	}
+}
+
+type twilioHandler struct {
+	model    *speech.SharedModel
+	cfg      TwilioConfig
+	upgrader websocket.Upgrader
+}
+
+func (h *twilioHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	modelRate := h.model.GetConfig().SampleRate
+	var resampler *audio.Resampler
+	if modelRate != twilioSampleRate {
+		var err error
+		if resampler, err = audio.NewResampler(twilioSampleRate, modelRate); err != nil {
+			writeHTTPError(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	chunker, err := speech.NewStreamChunker(modelRate)
+	if err != nil {
+		writeHTTPError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	conn.SetReadLimit(defaultMaxMessageBytes)
+
+	dc := h.model.NewContext()
+	defer dc.Close()
+
+	var (
+		stream  *TwilioStream
+		events  eventTracker
+		ulaw    []byte
+		samples []float32
+		out     []float32
+	)
+	for {
+		var msg twilioMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			return
+		}
+
+		switch msg.Event {
+		case "start":
+			if msg.Start == nil {
+				closeWS(conn, websocket.CloseUnsupportedData, "missing start payload")
+				return
+			}
+			if f := msg.Start.MediaFormat; f.Encoding != "" && (f.Encoding != "audio/x-mulaw" || f.SampleRate != twilioSampleRate) {
+				closeWS(conn, websocket.CloseUnsupportedData, fmt.Sprintf("unsupported media format: %s %dHz", f.Encoding, f.SampleRate))
+				return
+			}
+			stream = &TwilioStream{
+				StreamSid:        msg.StreamSid,
+				CallSid:          msg.Start.CallSid,
+				CustomParameters: msg.Start.CustomParameters,
+				conn:             conn,
+			}
+			continue
+		case "media":
+		case "stop":
+			return
+		default:
+			// connected、mark、dtmf 等消息与检测无关
+			continue
+		}
+
+		if stream == nil {
+			closeWS(conn, websocket.CloseUnsupportedData, "media received before start")
+			return
+		}
+		// 双轨流中只检测来电方（inbound）的音频
+		if msg.Media == nil || (msg.Media.Track != "" && msg.Media.Track != "inbound") {
+			continue
+		}
+
+		if n := base64.StdEncoding.DecodedLen(len(msg.Media.Payload)); cap(ulaw) < n {
+			ulaw = make([]byte, n)
+		}
+		n, err := base64.StdEncoding.Decode(ulaw[:cap(ulaw)], []byte(msg.Media.Payload))
+		if err != nil {
+			closeWS(conn, websocket.CloseUnsupportedData, "invalid media payload")
+			return
+		}
+		if resampler == nil {
+			chunker.WriteBytes(ulaw[:n], audio.FormatULaw)
+		} else {
+			samples = audio.DecodeULaw(samples[:0], ulaw[:n])
+			out = resampler.Process(out[:0], samples)
+			chunker.Write(out)
+		}
+
+		segments, err := dc.DetectChunks(chunker)
+		if err != nil {
+			closeWS(conn, websocket.CloseInternalServerErr, err.Error())
+			return
+		}
+		for _, ev := range events.update(segments) {
+			if err := h.emit(stream, ev); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// emit 把一个事件作为 mark 发送给 Twilio 并通知 OnEvent
+func (h *twilioHandler) emit(stream *TwilioStream, ev VADEvent) error {
+	name := TwilioMarkSpeechEnd
+	if ev.Type == EventTypeSpeechStart {
+		name = TwilioMarkSpeechStart
+		if h.cfg.ClearOnSpeech {
+			if err := stream.Clear(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := stream.SendMark(name); err != nil {
+		return err
+	}
+	if h.cfg.OnEvent != nil {
+		h.cfg.OnEvent(stream, ev)
+	}
+	return nil
+}
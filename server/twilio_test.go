@@ -0,0 +1,118 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rui-yang-me/silero-vad-go/audio"
+	"github.com/rui-yang-me/silero-vad-go/bridge"
+	"github.com/rui-yang-me/silero-vad-go/internal/testutil"
+)
+
+func TestTwilioHandler(t *testing.T) {
+	sm := testutil.NewSharedModel(t)
+	srv, err := New(sm)
+	require.NoError(t, err)
+
+	// 模拟电话网络的 8kHz µ-law
+	r, err := audio.NewResampler(16000, twilioSampleRate)
+	require.NoError(t, err)
+	ulaw := audio.EncodeULaw(nil, r.Flush(r.Process(nil, testutil.ReadSamples(t))))
+
+	// 参考结果：同样的 µ-law 音频按 20ms 一帧直接检测
+	src, err := bridge.NewReaderSource("ref", bytes.NewReader(ulaw), audio.FormatULaw, twilioSampleRate)
+	require.NoError(t, err)
+	var expected []VADEvent
+	require.NoError(t, bridge.Run(context.Background(), sm, src, bridge.SinkFunc(func(ev bridge.Event) error {
+		if ev.Speaking {
+			expected = append(expected, VADEvent{Type: EventTypeSpeechStart, Start: ev.Segment.SpeechStartAt})
+		} else {
+			expected = append(expected, VADEvent{Type: EventTypeSpeechEnd, Start: ev.Segment.SpeechStartAt, End: ev.Segment.SpeechEndAt})
+		}
+		return nil
+	})))
+	require.NotEmpty(t, expected)
+
+	var (
+		events  []VADEvent
+		streams []*TwilioStream
+	)
+	ts := httptest.NewServer(srv.TwilioHandler(TwilioConfig{
+		ClearOnSpeech: true,
+		OnEvent: func(stream *TwilioStream, ev VADEvent) {
+			streams = append(streams, stream)
+			events = append(events, ev)
+		},
+	}))
+	defer ts.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(ts.URL, "http"), nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	go func() {
+		conn.WriteMessage(websocket.TextMessage, []byte(`{"event":"connected","protocol":"Call","version":"1.0.0"}`))
+		conn.WriteMessage(websocket.TextMessage, []byte(`{"event":"start","sequenceNumber":"1","streamSid":"MZ123","start":{"streamSid":"MZ123","callSid":"CA123","tracks":["inbound","outbound"],"customParameters":{"agent":"bot"},"mediaFormat":{"encoding":"audio/x-mulaw","sampleRate":8000,"channels":1}}}`))
+		for i := 0; i < len(ulaw); i += 160 {
+			payload := base64.StdEncoding.EncodeToString(ulaw[i:min(i+160, len(ulaw))])
+			msg := fmt.Sprintf(`{"event":"media","streamSid":"MZ123","media":{"track":"inbound","chunk":"%d","payload":"%s"}}`, i/160+1, payload)
+			if err := conn.WriteMessage(websocket.TextMessage, []byte(msg)); err != nil {
+				return
+			}
+			// 出站轨道的音频被忽略
+			conn.WriteMessage(websocket.TextMessage, []byte(`{"event":"media","streamSid":"MZ123","media":{"track":"outbound","payload":"AAAA"}}`))
+		}
+		conn.WriteMessage(websocket.TextMessage, []byte(`{"event":"stop","streamSid":"MZ123","stop":{"callSid":"CA123"}}`))
+	}()
+
+	var received []string
+	for {
+		var msg twilioOutbound
+		if err := conn.ReadJSON(&msg); err != nil {
+			break
+		}
+		require.Equal(t, "MZ123", msg.StreamSid)
+		if msg.Mark != nil {
+			received = append(received, msg.Mark.Name)
+		} else {
+			received = append(received, msg.Event)
+		}
+	}
+
+	var want []string
+	for _, ev := range expected {
+		if ev.Type == EventTypeSpeechStart {
+			want = append(want, "clear", TwilioMarkSpeechStart)
+		} else {
+			want = append(want, TwilioMarkSpeechEnd)
+		}
+	}
+	require.Equal(t, want, received)
+	require.Equal(t, expected, events)
+	require.Equal(t, "CA123", streams[0].CallSid)
+	require.Equal(t, "bot", streams[0].CustomParameters["agent"])
+}
+
+func TestTwilioHandlerMediaFormat(t *testing.T) {
+	srv, err := New(testutil.NewSharedModel(t))
+	require.NoError(t, err)
+
+	ts := httptest.NewServer(srv.TwilioHandler(TwilioConfig{}))
+	defer ts.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(ts.URL, "http"), nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(`{"event":"start","streamSid":"MZ1","start":{"mediaFormat":{"encoding":"audio/x-l16","sampleRate":16000}}}`)))
+	_, _, err = conn.ReadMessage()
+	require.True(t, websocket.IsCloseError(err, websocket.CloseUnsupportedData), err)
+}
@@ -0,0 +1,178 @@
+package server
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/rui-yang-me/silero-vad-go/audio"
+	"github.com/rui-yang-me/silero-vad-go/speech"
+)
+
+// defaultMaxMessageBytes 未设置 WebSocketConfig.MaxMessageBytes 时单条消息的大小上限
+const defaultMaxMessageBytes = 1 << 20
+
+// WebSocketConfig WebSocket 接口的配置
+type WebSocketConfig struct {
+	// 校验请求的 Origin，nil 时只允许同源请求
+	CheckOrigin func(r *http.Request) bool
+	// 单条消息的最大字节数，0 表示默认 1MB
+	MaxMessageBytes int64
+}
+
+// wsEvent 推送给客户端的 JSON 事件
+type wsEvent struct {
+	Type  string   `json:"type"`
+	Start float64  `json:"start"`
+	End   *float64 `json:"end,omitempty"`
+}
+
+func newWSEvent(ev VADEvent) wsEvent {
+	if ev.Type == EventTypeSpeechEnd {
+		end := ev.End
+		return wsEvent{Type: "speech_end", Start: ev.Start, End: &end}
+	}
+	return wsEvent{Type: "speech_start", Start: ev.Start}
+}
+
+// WebSocketHandler 返回流式检测的 WebSocket 接口
+//
+// 客户端以二进制消息发送单声道音频，格式由查询参数 format（pcm16、float32、ulaw、alaw，默认 pcm16）
+// 和 sample_rate（默认与模型一致，不一致时自动重采样）指定；服务端在语音开始和结束时推送文本消息：
+//
+//	{"type": "speech_start", "start": 1.056}
+//	{"type": "speech_end", "start": 1.056, "end": 1.632}
+//
+// 时间为相对连接开始的秒数。重采样时每条消息需包含完整的采样点。
+func (s *Server) WebSocketHandler(cfg WebSocketConfig) http.Handler {
+	if cfg.MaxMessageBytes <= 0 {
+		cfg.MaxMessageBytes = defaultMaxMessageBytes
+	}
+	return &wsHandler{
+		model:    s.model,
+		cfg:      cfg,
+		upgrader: websocket.Upgrader{CheckOrigin: cfg.CheckOrigin},
+	}
+}
+
+type wsHandler struct {
+	model    *speech.SharedModel
+	cfg      WebSocketConfig
+	upgrader websocket.Upgrader
+}
+
+func (h *wsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	modelRate := h.model.GetConfig().SampleRate
+	format, sampleRate, err := parseStreamParams(r, modelRate)
+	if err != nil {
+		writeHTTPError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var resampler *audio.Resampler
+	if sampleRate != modelRate {
+		if resampler, err = audio.NewResampler(sampleRate, modelRate); err != nil {
+			writeHTTPError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	chunker, err := speech.NewStreamChunker(modelRate)
+	if err != nil {
+		writeHTTPError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	// Upgrade 失败时已经写出了错误响应
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	conn.SetReadLimit(h.cfg.MaxMessageBytes)
+
+	dc := h.model.NewContext()
+	defer dc.Close()
+
+	var (
+		events  eventTracker
+		samples []float32
+		out     []float32
+	)
+	for {
+		typ, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		if typ != websocket.BinaryMessage {
+			closeWS(conn, websocket.CloseUnsupportedData, "expected binary audio frames")
+			return
+		}
+
+		if resampler == nil {
+			err = chunker.WriteBytes(data, format)
+		} else if samples, err = audio.BytesToFloat32(samples[:0], data, format, binary.LittleEndian); err == nil {
+			out = resampler.Process(out[:0], samples)
+			chunker.Write(out)
+		}
+		if err != nil {
+			closeWS(conn, websocket.CloseUnsupportedData, err.Error())
+			return
+		}
+
+		segments, err := dc.DetectChunks(chunker)
+		if err != nil {
+			closeWS(conn, websocket.CloseInternalServerErr, err.Error())
+			return
+		}
+		for _, ev := range events.update(segments) {
+			if err := conn.WriteJSON(newWSEvent(ev)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// parseStreamParams 解析流式接口的 format 和 sample_rate 查询参数，只支持原始格式
+func parseStreamParams(r *http.Request, defaultRate int) (audio.SampleFormat, int, error) {
+	query := r.URL.Query()
+
+	format := audio.FormatPCM16
+	if name := query.Get("format"); name != "" {
+		f, err := audio.ParseSampleFormat(name)
+		if err != nil {
+			return 0, 0, err
+		}
+		if f.BytesPerSample() == 0 {
+			return 0, 0, fmt.Errorf("%w: streaming format %s", audio.ErrUnsupportedFormat, f)
+		}
+		format = f
+	}
+
+	sampleRate := defaultRate
+	if rate := query.Get("sample_rate"); rate != "" {
+		v, err := strconv.Atoi(rate)
+		if err != nil || v <= 0 {
+			return 0, 0, fmt.Errorf("invalid sample_rate: %q", rate)
+		}
+		if v != defaultRate {
+			// 在创建重采样器之前拒绝超出范围的采样率
+			if err := audio.CheckResampleRate(v); err != nil {
+				return 0, 0, fmt.Errorf("invalid sample_rate: %w", err)
+			}
+		}
+		sampleRate = v
+	}
+	return format, sampleRate, nil
+}
+
+func closeWS(conn *websocket.Conn, code int, reason string) {
+	// 关闭原因最长 123 字节
+	if len(reason) > 123 {
+		reason = reason[:123]
+	}
+	conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason))
+}
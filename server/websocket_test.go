@@ -0,0 +1,86 @@
+package server
+
+import (
+	"encoding/binary"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rui-yang-me/silero-vad-go/internal/testutil"
+)
+
+func TestWebSocketHandler(t *testing.T) {
+	sm := testutil.NewSharedModel(t)
+	srv, err := New(sm)
+	require.NoError(t, err)
+
+	samples := testutil.ReadSamples(t)
+	dc := sm.NewContext()
+	defer dc.Close()
+	expected, err := dc.Detect(samples)
+	require.NoError(t, err)
+
+	ts := httptest.NewServer(srv.WebSocketHandler(WebSocketConfig{}))
+	defer ts.Close()
+	url := "ws" + strings.TrimPrefix(ts.URL, "http")
+
+	conn, _, err := websocket.DefaultDialer.Dial(url+"?format=float32", nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	go func() {
+		data := make([]byte, 4*len(samples))
+		for i, v := range samples {
+			binary.LittleEndian.PutUint32(data[4*i:], math.Float32bits(v))
+		}
+		for off := 0; off < len(data); off += 1280 {
+			if err := conn.WriteMessage(websocket.BinaryMessage, data[off:min(off+1280, len(data))]); err != nil {
+				return
+			}
+		}
+		conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+	}()
+
+	var starts []float64
+	var ends []wsEvent
+	for {
+		var ev wsEvent
+		if err := conn.ReadJSON(&ev); err != nil {
+			break
+		}
+		switch ev.Type {
+		case "speech_start":
+			require.Nil(t, ev.End)
+			starts = append(starts, ev.Start)
+		case "speech_end":
+			require.NotNil(t, ev.End)
+			ends = append(ends, ev)
+		}
+	}
+
+	require.Len(t, starts, len(expected))
+	for i, seg := range expected {
+		require.Equal(t, seg.SpeechStartAt, starts[i])
+		if seg.SpeechEndAt > 0 {
+			require.Equal(t, seg.SpeechStartAt, ends[i].Start)
+			require.Equal(t, seg.SpeechEndAt, *ends[i].End)
+		}
+	}
+
+	// 压缩格式不能用于流式输入
+	_, resp, err := websocket.DefaultDialer.Dial(url+"?format=mp3", nil)
+	require.ErrorIs(t, err, websocket.ErrBadHandshake)
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	// 超出范围的采样率在升级之前返回 400
+	for _, rate := range []string{"2147483647", "1", "16001"} {
+		_, resp, err = websocket.DefaultDialer.Dial(url+"?sample_rate="+rate, nil)
+		require.ErrorIs(t, err, websocket.ErrBadHandshake, rate)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode, rate)
+	}
+}
@@ -0,0 +1,207 @@
+// Package speaker 为检测到的语音片段计算说话人嵌入，标记相邻片段之间可能的说话人切换，
+// 为不需要完整说话人分离（diarization）的场景提供轻量的线索。
+//
+// 嵌入模型通过 Embedder 接口接入，ONNXEmbedder 使用 ortaudio.Runner 运行任意以波形为输入的 ONNX 模型：
+//
+//	e, err := speaker.NewONNXEmbedder(ortaudio.Config{ModelPath: "speaker.onnx"})
+//	...
+//	turns, err := speaker.Segments(model, pcm, e, speaker.Config{})
+//
+// 流式场景在每个片段结束时调用 Tracker.Observe。
+package speaker
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/rui-yang-me/silero-vad-go/ortaudio"
+	"github.com/rui-yang-me/silero-vad-go/speech"
+)
+
+const (
+	// defaultThreshold 未设置 Config.Threshold 时判定切换的余弦相似度
+	defaultThreshold = 0.5
+	// defaultMinDuration 未设置 Config.MinDuration 时计算嵌入所需的最短片段
+	defaultMinDuration = 500 * time.Millisecond
+)
+
+// Embedder 计算一段单声道音频的说话人嵌入
+type Embedder interface {
+	Embed(pcm []float32, sampleRate int) ([]float32, error)
+}
+
+// Func 把普通函数适配为 Embedder
+type Func func(pcm []float32, sampleRate int) ([]float32, error)
+
+// Embed 实现 Embedder
+func (f Func) Embed(pcm []float32, sampleRate int) ([]float32, error) {
+	return f(pcm, sampleRate)
+}
+
+// ONNXEmbedder 使用 ONNX 模型计算嵌入，模型输入为 [1, N] 的波形，第一个输出为嵌入向量
+// 模型须以片段的采样率工作。可以被并发调用。
+type ONNXEmbedder struct {
+	runner *ortaudio.Runner
+}
+
+// NewONNXEmbedder 加载嵌入模型
+func NewONNXEmbedder(cfg ortaudio.Config) (*ONNXEmbedder, error) {
+	r, err := ortaudio.NewRunner(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &ONNXEmbedder{runner: r}, nil
+}
+
+// Embed 实现 Embedder
+func (e *ONNXEmbedder) Embed(pcm []float32, _ int) ([]float32, error) {
+	return e.runner.RunWindow(pcm)
+}
+
+// Close 释放模型
+func (e *ONNXEmbedder) Close() error {
+	return e.runner.Close()
+}
+
+// Config Tracker 的配置
+type Config struct {
+	// 与上一个片段的余弦相似度低于该值时判定为切换，取值 (0, 1]，0 表示默认 0.5
+	Threshold float32
+	// 短于该时长的片段不计算嵌入，也不参与比较，0 表示默认 500ms
+	MinDuration time.Duration
+}
+
+// Turn 一个语音片段的说话人判定，时间为相对音频开始的秒数
+type Turn struct {
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+	// 与上一个计算了嵌入的片段的余弦相似度，没有可比较的片段时为 0
+	Similarity float32 `json:"similarity"`
+	// 可能换了说话人
+	Change bool `json:"change"`
+	// 片段太短，未计算嵌入
+	Skipped bool `json:"skipped,omitempty"`
+	// 片段的嵌入，Skipped 时为 nil
+	Embedding []float32 `json:"-"`
+}
+
+// Tracker 依次接收语音片段，把每个片段与上一个计算了嵌入的片段比较
+// Tracker 不是并发安全的。
+type Tracker struct {
+	embedder  Embedder
+	threshold float32
+	minDur    float64
+	prev      []float32
+}
+
+// NewTracker 创建说话人切换检测
+func NewTracker(e Embedder, cfg Config) (*Tracker, error) {
+	if e == nil {
+		return nil, fmt.Errorf("invalid nil embedder")
+	}
+	if cfg.Threshold < 0 || cfg.Threshold > 1 {
+		return nil, fmt.Errorf("invalid Threshold: should be in range (0, 1]")
+	}
+	if cfg.MinDuration < 0 {
+		return nil, fmt.Errorf("invalid MinDuration: should not be negative")
+	}
+	if cfg.Threshold == 0 {
+		cfg.Threshold = defaultThreshold
+	}
+	if cfg.MinDuration == 0 {
+		cfg.MinDuration = defaultMinDuration
+	}
+	return &Tracker{
+		embedder:  e,
+		threshold: cfg.Threshold,
+		minDur:    cfg.MinDuration.Seconds(),
+	}, nil
+}
+
+// Observe 计算一个片段的嵌入并与上一个片段比较，clip 为该片段的音频
+// 未结束的片段（SpeechEndAt 为 0）以 clip 的长度作为时长。
+func (t *Tracker) Observe(seg speech.Segment, clip []float32, sampleRate int) (Turn, error) {
+	if sampleRate <= 0 {
+		return Turn{}, fmt.Errorf("invalid sample rate: %d", sampleRate)
+	}
+	turn := Turn{Start: seg.SpeechStartAt, End: seg.SpeechEndAt}
+	if turn.End == 0 {
+		turn.End = turn.Start + float64(len(clip))/float64(sampleRate)
+	}
+	if len(clip) == 0 || float64(len(clip))/float64(sampleRate) < t.minDur {
+		turn.Skipped = true
+		return turn, nil
+	}
+
+	emb, err := t.embedder.Embed(clip, sampleRate)
+	if err != nil {
+		return Turn{}, fmt.Errorf("failed to embed segment %.3f-%.3fs: %w", turn.Start, turn.End, err)
+	}
+	turn.Embedding = emb
+	if t.prev != nil {
+		turn.Similarity, err = CosineSimilarity(t.prev, emb)
+		if err != nil {
+			return Turn{}, err
+		}
+		turn.Change = turn.Similarity < t.threshold
+	}
+	t.prev = emb
+	return turn, nil
+}
+
+// Reset 忘记上一个片段，开始一路新的音频时调用
+func (t *Tracker) Reset() {
+	t.prev = nil
+}
+
+// CosineSimilarity 返回两个嵌入的余弦相似度，任一向量为零向量时返回 0
+func CosineSimilarity(a, b []float32) (float32, error) {
+	if len(a) != len(b) {
+		return 0, fmt.Errorf("embedding size mismatch: %d != %d", len(a), len(b))
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0, nil
+	}
+	return float32(dot / math.Sqrt(na*nb)), nil
+}
+
+// Segments 检测 pcm（模型采样率）中的语音片段，返回与片段一一对应的说话人判定
+func Segments(model *speech.SharedModel, pcm []float32, e Embedder, cfg Config) ([]Turn, error) {
+	if model == nil {
+		return nil, fmt.Errorf("invalid nil shared model")
+	}
+	t, err := NewTracker(e, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	dc := model.NewContext()
+	defer dc.Close()
+	segments, err := dc.Detect(pcm)
+	if err != nil {
+		return nil, err
+	}
+
+	sampleRate := model.GetConfig().SampleRate
+	clips, err := speech.ExtractSegments(pcm, sampleRate, segments)
+	if err != nil {
+		return nil, err
+	}
+
+	turns := make([]Turn, 0, len(segments))
+	for i, seg := range segments {
+		turn, err := t.Observe(seg, clips[i], sampleRate)
+		if err != nil {
+			return turns, err
+		}
+		turns = append(turns, turn)
+	}
+	return turns, nil
+}
@@ -0,0 +1,128 @@
+package speaker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rui-yang-me/silero-vad-go/internal/testutil"
+	"github.com/rui-yang-me/silero-vad-go/ortaudio"
+	"github.com/rui-yang-me/silero-vad-go/speech"
+)
+
+// constant 返回 n 个值为 v 的采样
+func constant(v float32, n int) []float32 {
+	pcm := make([]float32, n)
+	for i := range pcm {
+		pcm[i] = v
+	}
+	return pcm
+}
+
+func TestTracker(t *testing.T) {
+	_, err := NewTracker(nil, Config{})
+	require.Error(t, err)
+
+	// classifier_test.onnx 输出 softmax([0, 50*mean(input)])，均值的正负相当于两个说话人
+	e, err := NewONNXEmbedder(ortaudio.Config{ModelPath: "../testfiles/classifier_test.onnx"})
+	require.NoError(t, err)
+	defer e.Close()
+
+	_, err = NewTracker(e, Config{Threshold: 1.5})
+	require.Error(t, err)
+	tr, err := NewTracker(e, Config{MinDuration: 100 * time.Millisecond})
+	require.NoError(t, err)
+
+	clips := [][]float32{
+		constant(0.1, 16000),
+		constant(0.2, 8000),
+		constant(-0.1, 800), // 太短，跳过
+		constant(-0.1, 16000),
+		constant(-0.3, 16000),
+	}
+	var turns []Turn
+	for i, clip := range clips {
+		turn, err := tr.Observe(speech.Segment{SpeechStartAt: float64(i)}, clip, 16000)
+		require.NoError(t, err)
+		require.InDelta(t, float64(i)+float64(len(clip))/16000, turn.End, 1e-9)
+		turns = append(turns, turn)
+	}
+	require.Zero(t, turns[0].Similarity)
+	require.Len(t, turns[0].Embedding, 2)
+	require.False(t, turns[1].Change)
+	require.Greater(t, turns[1].Similarity, float32(0.99))
+	require.True(t, turns[2].Skipped)
+	require.Nil(t, turns[2].Embedding)
+	require.True(t, turns[3].Change)
+	require.Less(t, turns[3].Similarity, float32(0.1))
+	require.False(t, turns[4].Change)
+
+	// Reset 之后不再与之前的片段比较
+	tr.Reset()
+	turn, err := tr.Observe(speech.Segment{SpeechStartAt: 10, SpeechEndAt: 11}, constant(0.1, 16000), 16000)
+	require.NoError(t, err)
+	require.False(t, turn.Change)
+	require.Zero(t, turn.Similarity)
+	require.Equal(t, 11.0, turn.End)
+
+	_, err = tr.Observe(speech.Segment{}, constant(0.1, 16000), 0)
+	require.Error(t, err)
+
+	// 嵌入维度不一致
+	size := 2
+	tr, err = NewTracker(Func(func(pcm []float32, _ int) ([]float32, error) {
+		size++
+		return make([]float32, size), nil
+	}), Config{})
+	require.NoError(t, err)
+	_, err = tr.Observe(speech.Segment{}, constant(0.1, 16000), 16000)
+	require.NoError(t, err)
+	_, err = tr.Observe(speech.Segment{}, constant(0.1, 16000), 16000)
+	require.ErrorContains(t, err, "mismatch")
+}
+
+func TestSegments(t *testing.T) {
+	sm := testutil.NewSharedModel(t)
+
+	samples := testutil.ReadSamples(t)
+
+	dc := sm.NewContext()
+	segments, err := dc.Detect(samples)
+	require.NoError(t, err)
+	require.NoError(t, dc.Close())
+
+	// 同一个嵌入：没有切换
+	same := Func(func([]float32, int) ([]float32, error) {
+		return []float32{1, 2, 3}, nil
+	})
+	turns, err := Segments(sm, samples, same, Config{MinDuration: time.Millisecond})
+	require.NoError(t, err)
+	require.Len(t, turns, len(segments))
+	for i, turn := range turns {
+		require.Equal(t, segments[i].SpeechStartAt, turn.Start)
+		require.False(t, turn.Change)
+		require.False(t, turn.Skipped)
+		if i > 0 {
+			require.InDelta(t, 1, turn.Similarity, 1e-6)
+		}
+	}
+
+	// 交替的嵌入：每个片段都是切换
+	n := 0
+	alternating := Func(func([]float32, int) ([]float32, error) {
+		n++
+		return []float32{float32(n % 2), float32(1 - n%2)}, nil
+	})
+	turns, err = Segments(sm, samples, alternating, Config{MinDuration: time.Millisecond})
+	require.NoError(t, err)
+	for _, turn := range turns[1:] {
+		require.True(t, turn.Change)
+	}
+
+	_, err = Segments(sm, samples, Func(func([]float32, int) ([]float32, error) {
+		return nil, errors.New("boom")
+	}), Config{})
+	require.ErrorContains(t, err, "boom")
+}
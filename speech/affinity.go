@@ -0,0 +1,51 @@
+package speech
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// maxAffinityCPU SessionCPUs 中允许的最大 CPU 编号（不含），与内核 CPU 掩码的常用大小一致
+const maxAffinityCPU = 1024
+
+// validateCPUs 校验一组 CPU 编号
+func validateCPUs(cpus []int) error {
+	if len(cpus) == 0 {
+		return fmt.Errorf("empty cpu set")
+	}
+	for _, c := range cpus {
+		if c < 0 || c >= maxAffinityCPU {
+			return fmt.Errorf("cpu %d out of range [0, %d)", c, maxAffinityCPU)
+		}
+	}
+	return nil
+}
+
+// parseCPUList 解析内核的 CPU 列表格式，例如 "0-3,8-11"
+func parseCPUList(s string) ([]int, error) {
+	var cpus []int
+	for _, part := range strings.Split(strings.TrimSpace(s), ",") {
+		if part == "" {
+			continue
+		}
+		lo, hi, isRange := strings.Cut(part, "-")
+		first, err := strconv.Atoi(lo)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cpu list %q: %w", s, err)
+		}
+		last := first
+		if isRange {
+			if last, err = strconv.Atoi(hi); err != nil {
+				return nil, fmt.Errorf("invalid cpu list %q: %w", s, err)
+			}
+		}
+		if first < 0 || last < first {
+			return nil, fmt.Errorf("invalid cpu list %q", s)
+		}
+		for c := first; c <= last; c++ {
+			cpus = append(cpus, c)
+		}
+	}
+	return cpus, nil
+}
@@ -0,0 +1,79 @@
+package speech
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// NUMANodeCPUs 返回每个 NUMA 节点上的 CPU 编号，按节点编号排序
+// 结果可以直接用作 DetectorConfig.SessionCPUs，并把 SessionPoolSize 设为节点数，使每个节点一个会话。
+func NUMANodeCPUs() ([][]int, error) {
+	paths, err := filepath.Glob("/sys/devices/system/node/node[0-9]*/cpulist")
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no NUMA nodes found in sysfs")
+	}
+
+	nodeID := func(path string) int {
+		id, _ := strconv.Atoi(strings.TrimPrefix(filepath.Base(filepath.Dir(path)), "node"))
+		return id
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		return nodeID(paths[i]) < nodeID(paths[j])
+	})
+
+	var nodes [][]int
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		cpus, err := parseCPUList(string(data))
+		if err != nil {
+			return nil, err
+		}
+		// 没有 CPU 的节点（只有内存）无法放置会话
+		if len(cpus) > 0 {
+			nodes = append(nodes, cpus)
+		}
+	}
+	return nodes, nil
+}
+
+// pinThread 把当前协程锁定到一个 OS 线程并把线程的 CPU 亲和性设为 cpus
+// 返回的 restore 恢复原来的亲和性并解除锁定，必须在同一协程中调用。
+func pinThread(cpus []int) (restore func(), err error) {
+	runtime.LockOSThread()
+
+	var old unix.CPUSet
+	if err := unix.SchedGetaffinity(0, &old); err != nil {
+		runtime.UnlockOSThread()
+		return nil, fmt.Errorf("failed to get cpu affinity: %w", err)
+	}
+
+	var set unix.CPUSet
+	for _, c := range cpus {
+		set.Set(c)
+	}
+	if err := unix.SchedSetaffinity(0, &set); err != nil {
+		runtime.UnlockOSThread()
+		return nil, fmt.Errorf("failed to set cpu affinity to %v: %w", cpus, err)
+	}
+
+	return func() {
+		if err := unix.SchedSetaffinity(0, &old); err != nil {
+			// 无法恢复时保持锁定，线程会在协程结束时退出，不会把受限的亲和性带给其它协程
+			return
+		}
+		runtime.UnlockOSThread()
+	}, nil
+}
@@ -0,0 +1,82 @@
+package speech
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+func TestSessionCPUs(t *testing.T) {
+	// 很多容器和没有 NUMA 的虚拟机不提供 sysfs 中的节点信息
+	if _, err := os.Stat("/sys/devices/system/node"); errors.Is(err, fs.ErrNotExist) {
+		t.Skip("NUMA topology is not exposed in /sys/devices/system/node")
+	}
+	nodes, err := NUMANodeCPUs()
+	require.NoError(t, err)
+	require.NotEmpty(t, nodes)
+
+	// pinThread 在返回的函数中恢复原来的亲和性
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	var before, pinned, after unix.CPUSet
+	require.NoError(t, unix.SchedGetaffinity(0, &before))
+
+	// 使用节点中当前进程允许使用的第一个 CPU，容器的 cpuset 可能不包含 CPU 0
+	cpu := -1
+	for _, node := range nodes {
+		for _, c := range node {
+			if cpu < 0 && before.IsSet(c) {
+				cpu = c
+			}
+		}
+	}
+	if cpu < 0 {
+		t.Skip("no CPU of the NUMA nodes is in the affinity mask")
+	}
+	restore, err := pinThread([]int{cpu})
+	require.NoError(t, err)
+	require.NoError(t, unix.SchedGetaffinity(0, &pinned))
+	require.Equal(t, 1, pinned.Count())
+	require.True(t, pinned.IsSet(cpu))
+	restore()
+	require.NoError(t, unix.SchedGetaffinity(0, &after))
+	require.Equal(t, before, after)
+
+	samples := readTestSamples(t, "../testfiles/samples.pcm")
+	expected, err := newTestSharedModel(t).NewContext().Detect(samples)
+	require.NoError(t, err)
+
+	sm, err := NewSharedModel(DetectorConfig{
+		ModelPath:       "../testfiles/silero_vad.onnx",
+		SampleRate:      16000,
+		Threshold:       0.5,
+		SessionPoolSize: 2,
+		SessionCPUs:     [][]int{{cpu}},
+	})
+	require.NoError(t, err)
+	defer sm.Destroy()
+
+	// 检测调用期间线程固定在会话的 CPU 上
+	dc := sm.NewContext()
+	defer dc.Close()
+	var windows int
+	dc.SetWindowObserver(func(WindowResult) {
+		var set unix.CPUSet
+		require.NoError(t, unix.SchedGetaffinity(0, &set))
+		require.Equal(t, 1, set.Count())
+		windows++
+	})
+	segments, err := dc.Detect(samples)
+	require.NoError(t, err)
+	require.Equal(t, expected, segments)
+	require.Positive(t, windows)
+
+	require.Error(t, dc.WithConfig(func(cfg *DetectorConfig) {
+		cfg.SessionCPUs = nil
+	}))
+}
@@ -0,0 +1,15 @@
+//go:build !linux
+
+package speech
+
+import "fmt"
+
+// NUMANodeCPUs 返回每个 NUMA 节点上的 CPU 编号，只在 linux 上可用
+func NUMANodeCPUs() ([][]int, error) {
+	return nil, fmt.Errorf("NUMA topology is only available on linux")
+}
+
+// pinThread 当前平台不支持设置线程亲和性，SessionCPUs 被忽略
+func pinThread(cpus []int) (restore func(), err error) {
+	return func() {}, nil
+}
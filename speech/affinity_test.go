@@ -0,0 +1,30 @@
+package speech
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCPUList(t *testing.T) {
+	cpus, err := parseCPUList("0-3,8,10-11\n")
+	require.NoError(t, err)
+	require.Equal(t, []int{0, 1, 2, 3, 8, 10, 11}, cpus)
+
+	cpus, err = parseCPUList("\n")
+	require.NoError(t, err)
+	require.Empty(t, cpus)
+
+	for _, s := range []string{"a", "3-1", "1-x", "-1"} {
+		_, err := parseCPUList(s)
+		require.Error(t, err, s)
+	}
+
+	_, err = NewSharedModel(DetectorConfig{
+		ModelPath:   "../testfiles/silero_vad.onnx",
+		SampleRate:  16000,
+		Threshold:   0.5,
+		SessionCPUs: [][]int{{0}, {}},
+	})
+	require.Error(t, err)
+}
@@ -0,0 +1,190 @@
+package speech
+
+// #cgo CFLAGS: -Wall -Werror -std=c99
+// #cgo LDFLAGS: -lonnxruntime
+// #include "ort_bridge.h"
+import "C"
+
+import (
+	"fmt"
+	"time"
+)
+
+// stateHidden 循环状态每层的长度，状态张量形状为 [2, batch, stateHidden]
+const stateHidden = stateLen / 2
+
+// batchRequest 等待批量推理的一个窗口，保存在上下文中复用
+type batchRequest struct {
+	pcm   []float32
+	state *[stateLen]float32
+	prob  float32
+	err   error
+	done  chan struct{} // 容量为 1，推理完成后写入
+}
+
+// batchScheduler 把多个上下文的窗口合并为一次批量推理
+// 窗口在实时或批处理队列中等待，凑满 maxSize 个或第一个窗口等待超过 maxDelay 时整批推理，
+// 组批时实时队列优先。调度协程只引用推理所需的原生资源而不引用 SharedModel，
+// 因此不会阻止忘记调用 Destroy 的模型被回收。
+type batchScheduler struct {
+	api         *C.OrtApi
+	memoryInfo  *C.OrtMemoryInfo
+	sessions    []*C.OrtSession
+	inputNames  [3]*C.char
+	outputNames [2]*C.char
+	sampleRate  int
+	windowSize  int
+	maxSize     int
+	maxDelay    time.Duration
+
+	realtime   chan *batchRequest
+	background chan *batchRequest
+	stop       chan struct{}
+	exited     chan struct{}
+
+	// 以下只由调度协程使用
+	batch []*batchRequest
+	pcm   []float32 // [maxSize, windowSize]
+	state []float32 // [2, maxSize, stateHidden]
+	probs []float32
+	next  int // 轮询使用的会话
+}
+
+func newBatchScheduler(sm *SharedModel, cfg DetectorConfig) *batchScheduler {
+	windowSize := windowSizeFor(cfg.SampleRate)
+	b := &batchScheduler{
+		api:         sm.api,
+		memoryInfo:  sm.memoryInfo,
+		sessions:    sm.sessions,
+		inputNames:  sm.inputNames,
+		outputNames: sm.outputNames,
+		sampleRate:  cfg.SampleRate,
+		windowSize:  windowSize,
+		maxSize:     cfg.MaxBatchSize,
+		maxDelay:    cfg.MaxBatchDelay,
+		realtime:    make(chan *batchRequest, cfg.MaxBatchSize),
+		background:  make(chan *batchRequest, cfg.MaxBatchSize),
+		stop:        make(chan struct{}),
+		exited:      make(chan struct{}),
+		batch:       make([]*batchRequest, 0, cfg.MaxBatchSize),
+		pcm:         make([]float32, cfg.MaxBatchSize*windowSize),
+		state:       make([]float32, cfg.MaxBatchSize*stateLen),
+		probs:       make([]float32, cfg.MaxBatchSize),
+	}
+	go b.run()
+	return b
+}
+
+// infer 提交一个窗口并等待所在批次推理完成，成功时上下文的状态被更新
+func (b *batchScheduler) infer(dc *DetectorContext, pcm []float32) (float32, error) {
+	if len(pcm) != b.windowSize {
+		return 0, fmt.Errorf("invalid window size %d for batched inference: expected %d", len(pcm), b.windowSize)
+	}
+
+	r := &dc.batch
+	if r.done == nil {
+		r.done = make(chan struct{}, 1)
+	}
+	r.pcm, r.state = pcm, &dc.state
+
+	queue := b.realtime
+	if dc.priority == PriorityBatch {
+		queue = b.background
+	}
+	// 调度协程已退出时请求可能永远得不到处理，返回 ErrModelDestroyed 而不是一直阻塞
+	select {
+	case queue <- r:
+	case <-b.exited:
+		r.pcm = nil
+		return 0, ErrModelDestroyed
+	}
+	select {
+	case <-r.done:
+	case <-b.exited:
+		select {
+		case <-r.done:
+		default:
+			r.pcm = nil
+			return 0, ErrModelDestroyed
+		}
+	}
+
+	r.pcm = nil
+	return r.prob, r.err
+}
+
+// close 停止调度协程，调用方需保证不再有进行中的 infer
+func (b *batchScheduler) close() {
+	close(b.stop)
+	<-b.exited
+}
+
+func (b *batchScheduler) run() {
+	defer close(b.exited)
+
+	for {
+		var first *batchRequest
+		select {
+		case first = <-b.realtime:
+		default:
+			select {
+			case first = <-b.realtime:
+			case first = <-b.background:
+			case <-b.stop:
+				return
+			}
+		}
+
+		b.batch = append(b.batch[:0], first)
+		deadline := time.NewTimer(b.maxDelay)
+	collect:
+		for len(b.batch) < b.maxSize {
+			select {
+			case r := <-b.realtime:
+				b.batch = append(b.batch, r)
+				continue
+			default:
+			}
+			select {
+			case r := <-b.realtime:
+				b.batch = append(b.batch, r)
+			case r := <-b.background:
+				b.batch = append(b.batch, r)
+			case <-deadline.C:
+				break collect
+			}
+		}
+		deadline.Stop()
+
+		b.runBatch()
+	}
+}
+
+// runBatch 推理当前批次并把概率和新状态交还给各个请求
+func (b *batchScheduler) runBatch() {
+	n := len(b.batch)
+	w := b.windowSize
+	for i, r := range b.batch {
+		copy(b.pcm[i*w:(i+1)*w], r.pcm)
+		for l := 0; l < 2; l++ {
+			copy(b.state[(l*n+i)*stateHidden:(l*n+i+1)*stateHidden], r.state[l*stateHidden:(l+1)*stateHidden])
+		}
+	}
+
+	session := b.sessions[b.next%len(b.sessions)]
+	b.next++
+	err := b.inferBatch(session, n)
+
+	for i, r := range b.batch {
+		if err != nil {
+			r.prob, r.err = 0, err
+		} else {
+			for l := 0; l < 2; l++ {
+				copy(r.state[l*stateHidden:(l+1)*stateHidden], b.state[(l*n+i)*stateHidden:(l*n+i+1)*stateHidden])
+			}
+			r.prob, r.err = b.probs[i], nil
+		}
+		r.done <- struct{}{}
+		b.batch[i] = nil
+	}
+}
@@ -0,0 +1,71 @@
+package speech
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchScheduler(t *testing.T) {
+	samples := readTestSamples(t, "../testfiles/samples.pcm")
+	expected, err := newTestSharedModel(t).NewContext().Detect(samples)
+	require.NoError(t, err)
+
+	_, err = NewSharedModel(DetectorConfig{
+		ModelPath:    "../testfiles/silero_vad.onnx",
+		SampleRate:   16000,
+		Threshold:    0.5,
+		MaxBatchSize: 8,
+	})
+	require.Error(t, err)
+
+	sm, err := NewSharedModel(DetectorConfig{
+		ModelPath:     "../testfiles/silero_vad.onnx",
+		SampleRate:    16000,
+		Threshold:     0.5,
+		MaxBatchSize:  8,
+		MaxBatchDelay: 5 * time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	// 多个流的窗口合并推理，每个流的状态互不干扰，结果与逐窗口推理相同
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			dc := sm.NewContext()
+			defer dc.Close()
+			if i%3 == 0 {
+				dc.SetPriority(PriorityBatch)
+			}
+			segments, err := dc.Detect(samples)
+			require.NoError(t, err)
+			require.Len(t, segments, len(expected))
+			for j := range expected {
+				require.InDelta(t, expected[j].SpeechStartAt, segments[j].SpeechStartAt, 0.001)
+				require.InDelta(t, expected[j].SpeechEndAt, segments[j].SpeechEndAt, 0.001)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	// 单个流在等待超时后独自成批
+	dc := sm.NewContext()
+	defer dc.Close()
+	ok, err := dc.IsSpeechQuick(samples, 3)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	require.Error(t, dc.WithConfig(func(cfg *DetectorConfig) {
+		cfg.MaxBatchDelay = time.Millisecond
+	}))
+
+	// 销毁后调度协程已退出，直接提交的窗口返回错误而不是一直阻塞
+	b := sm.batcher
+	require.NoError(t, sm.Destroy())
+	_, err = b.infer(dc, samples[:512])
+	require.ErrorIs(t, err, ErrModelDestroyed)
+}
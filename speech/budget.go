@@ -0,0 +1,106 @@
+package speech
+
+import (
+	"fmt"
+	"time"
+)
+
+// maxBudgetStride 超出预算时窗口步长的上限，再大会明显漏掉短语音
+const maxBudgetStride = 4
+
+// BudgetResult DetectWithBudget 的结果
+type BudgetResult struct {
+	// 检测到的语音片段；提前结束时最后一个片段可能尚未结束（SpeechEndAt 为 0）
+	Segments []Segment
+	// 为 true 表示处理速度不足以在预算内完成，结果是降级的：部分窗口被跳过或检测提前结束
+	Degraded bool
+	// 结束时使用的窗口步长，1 表示逐窗口推理，2 表示每两个窗口推理一个，以此类推
+	Stride int
+	// 本次调用实际检测的音频时长（秒），提前结束时小于输入时长
+	Processed float64
+}
+
+// DetectWithBudget 在 budget 时间内检测语音片段，用于在过载的机器上保证调用方的延迟
+//
+// 检测过程中根据已用时间估算剩余耗时，预计会超出预算时把窗口步长逐步加倍（最多 4 倍），
+// 被跳过的窗口沿用上一个推理窗口的概率；已用时间达到预算时提前结束，只返回已检测部分的结果。
+// 两种情况下 Degraded 都为 true。预算充足时结果与 Detect 相同。
+//
+// 跳过窗口会使循环状态与逐窗口推理不同，提前结束时上下文停留在音频中途，
+// 降级后继续使用该上下文前应调用 Reset。配置了 Deterministic 时忽略预算，结果总是与 Detect 相同。
+func (dc *DetectorContext) DetectWithBudget(pcm []float32, budget time.Duration) (BudgetResult, error) {
+	if dc == nil || dc.model == nil {
+		return BudgetResult{}, fmt.Errorf("invalid nil detector context")
+	}
+	if budget <= 0 {
+		return BudgetResult{}, fmt.Errorf("invalid time budget: %s", budget)
+	}
+
+	if err := dc.acquire(); err != nil {
+		return BudgetResult{}, err
+	}
+	defer dc.release()
+
+	start := time.Now()
+	cfg := dc.callConfig()
+	windowSize := windowSizeFor(cfg.SampleRate)
+
+	pcm, err := dc.pre.resample(cfg, pcm)
+	if err != nil {
+		return BudgetResult{}, err
+	}
+
+	if len(pcm) < windowSize {
+		if !cfg.PadShortInput {
+			return BudgetResult{}, notEnoughSamples(len(pcm), windowSize)
+		}
+		dc.padBuf = padShortInput(dc.padBuf, pcm, windowSize)
+		pcm = dc.padBuf
+	}
+
+	pcm, err = dc.pre.apply(cfg, pcm)
+	if err != nil {
+		return BudgetResult{}, err
+	}
+
+	res := BudgetResult{Stride: 1}
+	startSample := dc.currSample
+	windows := (len(pcm) - 1) / windowSize
+	inferred := 0
+	for i := 0; i < windows; {
+		window := pcm[i*windowSize : (i+1)*windowSize]
+		prob, err := dc.predict(cfg, window)
+		if err != nil {
+			return BudgetResult{}, fmt.Errorf("infer failed: %w", err)
+		}
+		inferred++
+
+		// 本窗口和随后被跳过的窗口使用同一个概率
+		for j := 0; j < res.Stride && i < windows; j++ {
+			res.Segments = dc.advance(cfg, prob, pcm[i*windowSize:(i+1)*windowSize], res.Segments, 0)
+			i++
+		}
+		if i == windows {
+			break
+		}
+		if cfg.Deterministic {
+			// 降级取决于机器负载，确定性模式下总是逐窗口处理全部音频
+			continue
+		}
+
+		elapsed := time.Since(start)
+		if elapsed >= budget {
+			res.Degraded = true
+			break
+		}
+		// 按目前每次推理的平均耗时估算剩余耗时
+		perWindow := elapsed / time.Duration(inferred)
+		for res.Stride < maxBudgetStride && elapsed+perWindow*time.Duration((windows-i+res.Stride-1)/res.Stride) > budget {
+			res.Stride *= 2
+			res.Degraded = true
+		}
+	}
+
+	res.Processed = float64(dc.currSample-startSample) / float64(cfg.SampleRate)
+	return res, nil
+}
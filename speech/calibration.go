@@ -0,0 +1,168 @@
+package speech
+
+import (
+	"fmt"
+	"math"
+)
+
+const (
+	// calibrationEps 计算 logit 时概率与 0、1 的最小距离
+	calibrationEps = 1e-6
+	// calibrationIterations 拟合校准参数的牛顿迭代次数上限
+	calibrationIterations = 50
+	// calibrationL2 拟合时的 L2 正则，避免标注完全可分时参数发散
+	calibrationL2 = 1e-3
+)
+
+// Calibration 模型语音概率的温度/偏置校准（Platt scaling）
+// 校准后的概率为 sigmoid(logit(p)/Temperature + Bias)。零值不改变概率。
+type Calibration struct {
+	// 温度，大于 1 时概率向 0.5 收缩，小于 1 时更陡峭，0 表示 1
+	Temperature float32 `json:"temperature" yaml:"temperature"`
+	// 在 logit 上的偏移，正值使概率整体变大
+	Bias float32 `json:"bias" yaml:"bias"`
+}
+
+// IsValid 校验校准参数
+func (c Calibration) IsValid() error {
+	if c.Temperature < 0 || math.IsNaN(float64(c.Temperature)) || math.IsInf(float64(c.Temperature), 0) {
+		return fmt.Errorf("invalid Calibration.Temperature: should be a positive number")
+	}
+	if math.IsNaN(float64(c.Bias)) || math.IsInf(float64(c.Bias), 0) {
+		return fmt.Errorf("invalid Calibration.Bias: should be finite")
+	}
+	return nil
+}
+
+// Apply 返回校准后的概率
+func (c Calibration) Apply(p float32) float32 {
+	if (c.Temperature == 0 || c.Temperature == 1) && c.Bias == 0 {
+		return p
+	}
+	t := float64(c.Temperature)
+	if t == 0 {
+		t = 1
+	}
+	return float32(sigmoid(logit(float64(p))/t + float64(c.Bias)))
+}
+
+// logit 返回概率的对数几率，p 限制在 [calibrationEps, 1-calibrationEps]
+func logit(p float64) float64 {
+	p = min(max(p, calibrationEps), 1-calibrationEps)
+	return math.Log(p / (1 - p))
+}
+
+func sigmoid(x float64) float64 {
+	return 1 / (1 + math.Exp(-x))
+}
+
+// FitCalibration 由一组模型原始概率和对应的标注（是否为语音）拟合校准参数
+// 以逻辑回归拟合 sigmoid(a*logit(p) + b)，Temperature 为 1/a，Bias 为 b。
+// 两类标注都需要存在；模型概率与标注负相关时返回错误。
+func FitCalibration(probs []float32, labels []bool) (Calibration, error) {
+	if len(probs) != len(labels) {
+		return Calibration{}, fmt.Errorf("probabilities and labels size mismatch: %d != %d", len(probs), len(labels))
+	}
+	var positives int
+	for _, l := range labels {
+		if l {
+			positives++
+		}
+	}
+	if positives == 0 || positives == len(labels) {
+		return Calibration{}, fmt.Errorf("invalid labels: both speech and non-speech samples are required")
+	}
+
+	x := make([]float64, len(probs))
+	for i, p := range probs {
+		x[i] = logit(float64(p))
+	}
+
+	// 牛顿法（IRLS），参数从恒等变换开始
+	a, b := 1.0, 0.0
+	for iter := 0; iter < calibrationIterations; iter++ {
+		ga, gb := calibrationL2*a, calibrationL2*b
+		haa, hab, hbb := calibrationL2, 0.0, calibrationL2
+		for i, xi := range x {
+			q := sigmoid(a*xi + b)
+			y := 0.0
+			if labels[i] {
+				y = 1
+			}
+			w := q * (1 - q)
+			ga += (q - y) * xi
+			gb += q - y
+			haa += w * xi * xi
+			hab += w * xi
+			hbb += w
+		}
+		det := haa*hbb - hab*hab
+		if det <= 0 {
+			break
+		}
+		da := (hbb*ga - hab*gb) / det
+		db := (haa*gb - hab*ga) / det
+		a -= da
+		b -= db
+		if math.Abs(da) < 1e-9 && math.Abs(db) < 1e-9 {
+			break
+		}
+	}
+	if !(a > 0) || math.IsInf(a, 0) || math.IsNaN(b) {
+		return Calibration{}, fmt.Errorf("model probabilities do not separate the labels")
+	}
+	return Calibration{Temperature: float32(1 / a), Bias: float32(b)}, nil
+}
+
+// FitCalibration 在一段标注过的音频上运行模型并拟合校准参数，speech 为音频中人工标注的语音区间
+// 中心落在某个区间内的窗口标注为语音，SpeechEndAt 为 0 的区间延伸到音频结尾。
+// 使用模型未经校准和分类融合的原始概率。调用前后都会重置上下文。
+func (dc *DetectorContext) FitCalibration(pcm []float32, speech []Segment) (Calibration, error) {
+	if dc == nil || dc.model == nil {
+		return Calibration{}, fmt.Errorf("invalid nil detector context")
+	}
+	if err := dc.Reset(); err != nil {
+		return Calibration{}, err
+	}
+	defer dc.Reset()
+
+	if err := dc.acquire(); err != nil {
+		return Calibration{}, err
+	}
+	defer dc.release()
+
+	cfg := dc.callConfig()
+	windowSize := windowSizeFor(cfg.SampleRate)
+	pcm, err := dc.pre.resample(cfg, pcm)
+	if err != nil {
+		return Calibration{}, err
+	}
+	if len(pcm) < windowSize {
+		return Calibration{}, notEnoughSamples(len(pcm), windowSize)
+	}
+	pcm, err = dc.pre.apply(cfg, pcm)
+	if err != nil {
+		return Calibration{}, err
+	}
+
+	n := len(pcm) / windowSize
+	probs := make([]float32, 0, n)
+	labels := make([]bool, 0, n)
+	for i := 0; i+windowSize <= len(pcm); i += windowSize {
+		prob, err := dc.inferWindow(pcm[i : i+windowSize])
+		if err != nil {
+			return Calibration{}, fmt.Errorf("infer failed: %w", err)
+		}
+		center := (float64(i) + float64(windowSize)/2) / float64(cfg.SampleRate)
+		label := false
+		for _, seg := range speech {
+			if center >= seg.SpeechStartAt && (seg.SpeechEndAt == 0 || center < seg.SpeechEndAt) {
+				label = true
+				break
+			}
+		}
+		probs = append(probs, prob)
+		labels = append(labels, label)
+	}
+	return FitCalibration(probs, labels)
+}
@@ -0,0 +1,104 @@
+package speech
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalibration(t *testing.T) {
+	require.Equal(t, float32(0.3), Calibration{}.Apply(0.3))
+	require.Equal(t, float32(0.3), Calibration{Temperature: 1}.Apply(0.3))
+	require.InDelta(t, 0.5, Calibration{Temperature: 1e3}.Apply(0.9), 0.01)
+	require.Greater(t, Calibration{Bias: 1}.Apply(0.3), float32(0.3))
+	require.Greater(t, Calibration{Temperature: 0.5}.Apply(0.7), float32(0.7))
+	require.Less(t, Calibration{Temperature: 0.5}.Apply(0.3), float32(0.3))
+	require.Error(t, Calibration{Temperature: -1}.IsValid())
+	require.Error(t, Calibration{Bias: float32(math.Inf(1))}.IsValid())
+
+	_, err := FitCalibration([]float32{0.5}, nil)
+	require.Error(t, err)
+	_, err = FitCalibration([]float32{0.2, 0.8}, []bool{true, true})
+	require.Error(t, err)
+	_, err = FitCalibration([]float32{0.1, 0.2, 0.8, 0.9}, []bool{true, true, false, false})
+	require.Error(t, err)
+
+	// 标注按 sigmoid(2*logit(p) - 1) 的概率生成，拟合应还原出 Temperature 0.5、Bias -1
+	rng := rand.New(rand.NewSource(1))
+	var probs []float32
+	var labels []bool
+	for i := 0; i < 20000; i++ {
+		p := float32(sigmoid(rng.NormFloat64() * 2))
+		probs = append(probs, p)
+		labels = append(labels, rng.Float64() < sigmoid(2*logit(float64(p))-1))
+	}
+	c, err := FitCalibration(probs, labels)
+	require.NoError(t, err)
+	require.InDelta(t, 0.5, c.Temperature, 0.05)
+	require.InDelta(t, -1, c.Bias, 0.1)
+}
+
+func TestDetectorContextCalibration(t *testing.T) {
+	sm := newTestSharedModel(t)
+	samples := readTestSamples(t, "../testfiles/samples.pcm")
+
+	dc := sm.NewContext()
+	defer dc.Close()
+	expected, err := dc.Detect(samples)
+	require.NoError(t, err)
+	require.NotEmpty(t, expected)
+
+	// 以模型自己的结果作为标注，校准后的检测结果不变
+	c, err := dc.FitCalibration(samples, expected)
+	require.NoError(t, err)
+	require.Positive(t, c.Temperature)
+	require.NoError(t, dc.WithConfig(func(cfg *DetectorConfig) {
+		cfg.Calibration = c
+	}))
+	segments, err := dc.Detect(samples)
+	require.NoError(t, err)
+	require.Len(t, segments, len(expected))
+	for i := range segments {
+		require.InDelta(t, expected[i].SpeechStartAt, segments[i].SpeechStartAt, 0.07)
+	}
+
+	// 很大的负偏置使所有窗口都低于阈值
+	require.NoError(t, dc.Reset())
+	require.NoError(t, dc.WithConfig(func(cfg *DetectorConfig) {
+		cfg.Calibration = Calibration{Bias: -20}
+	}))
+	var maxProb float32
+	dc.SetWindowObserver(func(w WindowResult) {
+		maxProb = max(maxProb, w.Probability)
+	})
+	segments, err = dc.Detect(samples)
+	require.NoError(t, err)
+	require.Empty(t, segments)
+	require.Less(t, maxProb, float32(0.01))
+
+	// 检测调用中途更新模型配置时，本次调用仍按开始时的校准计算概率
+	live := sm.NewContext()
+	defer live.Close()
+	updated := false
+	live.SetWindowObserver(func(WindowResult) {
+		if !updated {
+			cfg := sm.GetConfig()
+			cfg.Calibration = Calibration{Bias: -20}
+			_, err := sm.UpdateConfig(cfg)
+			require.NoError(t, err)
+			updated = true
+		}
+	})
+	segments, err = live.Detect(samples)
+	require.NoError(t, err)
+	require.True(t, updated)
+	require.Equal(t, expected, segments)
+
+	require.Error(t, dc.WithConfig(func(cfg *DetectorConfig) {
+		cfg.Calibration = Calibration{Temperature: -1}
+	}))
+	_, err = dc.FitCalibration(samples, nil)
+	require.Error(t, err)
+}
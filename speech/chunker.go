@@ -0,0 +1,138 @@
+package speech
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/rui-yang-me/silero-vad-go/audio"
+)
+
+// windowSizeFor 返回模型在给定采样率下每次推理的窗口大小
+func windowSizeFor(sampleRate int) int {
+	if sampleRate == 8000 {
+		return 256
+	}
+	return 512
+}
+
+// padShortInput 把不足一个窗口的 pcm 补零为 windowSize+1 个采样，复用 dst 的底层数组
+// 检测循环只推理其后仍有采样的窗口，多补的一个采样使补齐后的输入正好推理一个窗口。
+func padShortInput(dst, pcm []float32, windowSize int) []float32 {
+	if cap(dst) < windowSize+1 {
+		dst = make([]float32, windowSize+1)
+	}
+	dst = dst[:windowSize+1]
+	n := copy(dst, pcm)
+	clear(dst[n:])
+	return dst
+}
+
+// StreamChunker 将任意长度的音频块整理为模型窗口大小的帧
+// 适用于 RTP 等每次只送来 20ms 数据的场景：不足一帧的采样（以及不足一个采样点的字节）
+// 会保留到下一次写入，而不会在调用边界丢失。StreamChunker 不是并发安全的。
+type StreamChunker struct {
+	windowSize int
+	buf        []float32 // 尚未取走的采样
+	off        int       // buf 中下一帧的起始位置
+	partial    []byte    // 不足一个采样点的剩余字节
+	conv       []float32 // WriteBytes 的解码缓冲
+}
+
+// NewStreamChunker 创建按 sampleRate 对应的窗口大小分帧的 StreamChunker
+func NewStreamChunker(sampleRate int) (*StreamChunker, error) {
+	if sampleRate != 8000 && sampleRate != 16000 {
+		return nil, fmt.Errorf("invalid sample rate %d: valid values are 8000 and 16000", sampleRate)
+	}
+
+	return &StreamChunker{windowSize: windowSizeFor(sampleRate)}, nil
+}
+
+// WindowSize 返回每一帧的采样数
+func (c *StreamChunker) WindowSize() int {
+	return c.windowSize
+}
+
+// Write 追加采样，数据会被复制
+func (c *StreamChunker) Write(pcm []float32) {
+	// 丢弃已取走的帧，避免缓冲无限增长
+	if c.off > 0 {
+		n := copy(c.buf, c.buf[c.off:])
+		c.buf = c.buf[:n]
+		c.off = 0
+	}
+	c.buf = append(c.buf, pcm...)
+}
+
+// WriteBytes 按小端序解码原始采样后追加
+// data 可以在任意字节处截断，不完整的采样点会与下一次写入拼接。
+func (c *StreamChunker) WriteBytes(data []byte, format audio.SampleFormat) error {
+	size := format.BytesPerSample()
+	if size == 0 {
+		return fmt.Errorf("%w: sample format %s", audio.ErrUnsupportedFormat, format)
+	}
+	if len(c.partial) >= size {
+		return fmt.Errorf("sample format changed with %d pending bytes", len(c.partial))
+	}
+
+	var err error
+	if len(c.partial) > 0 {
+		need := size - len(c.partial)
+		if len(data) < need {
+			c.partial = append(c.partial, data...)
+			return nil
+		}
+		c.partial = append(c.partial, data[:need]...)
+		data = data[need:]
+
+		c.conv, err = audio.BytesToFloat32(c.conv, c.partial, format, binary.LittleEndian)
+		if err != nil {
+			return err
+		}
+		c.Write(c.conv)
+		c.partial = c.partial[:0]
+	}
+
+	whole := len(data) / size * size
+	c.conv, err = audio.BytesToFloat32(c.conv, data[:whole], format, binary.LittleEndian)
+	if err != nil {
+		return err
+	}
+	c.Write(c.conv)
+	c.partial = append(c.partial, data[whole:]...)
+
+	return nil
+}
+
+// Next 返回下一帧完整窗口，数据不足时返回 false
+// 返回的切片在下一次 Write/WriteBytes 之前有效。
+func (c *StreamChunker) Next() ([]float32, bool) {
+	if len(c.buf)-c.off < c.windowSize {
+		return nil, false
+	}
+	frame := c.buf[c.off : c.off+c.windowSize]
+	c.off += c.windowSize
+	return frame, true
+}
+
+// Buffered 返回尚未组成完整帧的采样数
+func (c *StreamChunker) Buffered() int {
+	return len(c.buf) - c.off
+}
+
+// Pad 把尾部不足一帧的采样补零为完整的一帧，之后可以由 Next 取出
+// 用于输入结束时处理最后的尾部音频；没有尾部采样时返回 false。
+func (c *StreamChunker) Pad() bool {
+	tail := c.Buffered()
+	if tail == 0 || tail >= c.windowSize {
+		return false
+	}
+	c.Write(make([]float32, c.windowSize-tail))
+	return true
+}
+
+// Reset 丢弃所有缓冲的数据
+func (c *StreamChunker) Reset() {
+	c.buf = c.buf[:0]
+	c.off = 0
+	c.partial = c.partial[:0]
+}
@@ -0,0 +1,120 @@
+package speech
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/rui-yang-me/silero-vad-go/audio"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamChunker(t *testing.T) {
+	_, err := NewStreamChunker(44100)
+	require.Error(t, err)
+
+	c, err := NewStreamChunker(8000)
+	require.NoError(t, err)
+	require.Equal(t, 256, c.WindowSize())
+
+	// 20ms 的 RTP 负载（160 个采样）逐包写入，帧之间不能丢失采样
+	var next float32
+	var got []float32
+	for i := 0; i < 10; i++ {
+		pkt := make([]float32, 160)
+		for j := range pkt {
+			pkt[j] = next
+			next++
+		}
+		c.Write(pkt)
+		for {
+			frame, ok := c.Next()
+			if !ok {
+				break
+			}
+			require.Len(t, frame, 256)
+			got = append(got, frame...)
+		}
+	}
+	require.Len(t, got, 1536)
+	require.Equal(t, 1600-1536, c.Buffered())
+	for i, v := range got {
+		require.Equal(t, float32(i), v)
+	}
+
+	// 尾部补零为完整的一帧
+	require.True(t, c.Pad())
+	frame, ok := c.Next()
+	require.True(t, ok)
+	require.Equal(t, float32(1536), frame[0])
+	require.Equal(t, float32(1599), frame[63])
+	require.Equal(t, make([]float32, 256-64), frame[64:])
+	require.False(t, c.Pad())
+
+	c.Write(got[:10])
+	c.Reset()
+	require.Zero(t, c.Buffered())
+}
+
+func TestStreamChunkerWriteBytes(t *testing.T) {
+	c, err := NewStreamChunker(8000)
+	require.NoError(t, err)
+
+	data := make([]byte, 2*300)
+	for i := 0; i < 300; i++ {
+		binary.LittleEndian.PutUint16(data[2*i:], uint16(int16(i*100)))
+	}
+
+	// 在奇数字节处切分，半个采样点要与下一次写入拼接
+	require.NoError(t, c.WriteBytes(data[:101], audio.FormatPCM16))
+	require.NoError(t, c.WriteBytes(data[101:102], audio.FormatPCM16))
+	require.NoError(t, c.WriteBytes(data[102:], audio.FormatPCM16))
+
+	frame, ok := c.Next()
+	require.True(t, ok)
+	for i, v := range frame {
+		require.InDelta(t, float64(i*100)/32768, v, 1e-6)
+	}
+	require.Equal(t, 300-256, c.Buffered())
+
+	require.Error(t, c.WriteBytes(data, audio.FormatOggOpus))
+}
+
+func TestDetectChunks(t *testing.T) {
+	sm := newTestSharedModel(t)
+	samples := readTestSamples(t, "../testfiles/samples.pcm")
+
+	expected, err := sm.NewContext().Detect(samples)
+	require.NoError(t, err)
+
+	dc := sm.NewContext()
+	c, err := NewStreamChunker(16000)
+	require.NoError(t, err)
+
+	var segments []Segment
+	for off := 0; off < len(samples); off += 320 {
+		c.Write(samples[off:min(off+320, len(samples))])
+		segs, err := dc.DetectChunks(c)
+		require.NoError(t, err)
+		segments = mergeSegments(segments, segs)
+	}
+
+	require.Equal(t, expected, segments)
+
+	wrong, err := NewStreamChunker(8000)
+	require.NoError(t, err)
+	_, err = dc.DetectChunks(wrong)
+	require.Error(t, err)
+}
+
+// mergeSegments 合并流式检测的结果：尚未结束的片段可能在之后的调用中以完整片段再次返回
+func mergeSegments(all, next []Segment) []Segment {
+	for _, s := range next {
+		if n := len(all); n > 0 && all[n-1].SpeechEndAt == 0 && math.Abs(all[n-1].SpeechStartAt-s.SpeechStartAt) < 1e-9 {
+			all[n-1] = s
+			continue
+		}
+		all = append(all, s)
+	}
+	return all
+}
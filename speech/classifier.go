@@ -0,0 +1,246 @@
+package speech
+
+// #cgo CFLAGS: -Wall -Werror -std=c99
+// #cgo LDFLAGS: -lonnxruntime
+// #include "ort_bridge.h"
+import "C"
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+// defaultMinSpeechScore 未设置 ClassifierConfig.MinSpeechScore 时语音类别的最低概率
+const defaultMinSpeechScore = 0.5
+
+// ClassifierConfig 描述与 VAD 并行运行的音频分类模型（语音/音乐/噪声一类）
+// 模型输入形状为 [1, FrameSize] 的波形，输出形状为 [1, NumClasses] 的各类别概率。
+// 分类结果与 VAD 融合：最近一帧的语音类别概率低于 MinSpeechScore 时，之后窗口的语音概率按 0 处理，
+// 因此电话线路上的等待音乐、广播等有人声特征的音频不会被报告为语音。
+type ClassifierConfig struct {
+	// ONNX 分类模型路径
+	ModelPath string `json:"model_path" yaml:"model_path"`
+	// 每次分类的采样点数，模型以 SampleRate 工作，例如 0.96 秒为 15360
+	FrameSize int `json:"frame_size" yaml:"frame_size"`
+	// 两次分类之间的采样点数，0 表示等于 FrameSize（不重叠）；小于 FrameSize 时各帧重叠，判定更及时
+	Hop int `json:"hop" yaml:"hop"`
+	// 输出的类别数
+	NumClasses int `json:"num_classes" yaml:"num_classes"`
+	// 语音类别在输出中的下标
+	SpeechClass int `json:"speech_class" yaml:"speech_class"`
+	// 语音类别的概率低于该值时抑制 VAD 的语音判定，0 表示默认 0.5
+	MinSpeechScore float32 `json:"min_speech_score" yaml:"min_speech_score"`
+	// 波形输入/输出节点名称，默认为 "input" 和 "output"
+	InputName  string `json:"input_name" yaml:"input_name"`
+	OutputName string `json:"output_name" yaml:"output_name"`
+}
+
+// IsValid 校验分类模型配置
+func (c *ClassifierConfig) IsValid() error {
+	if c.ModelPath == "" {
+		return fmt.Errorf("invalid Classifier.ModelPath: should not be empty")
+	}
+
+	if c.FrameSize <= 0 {
+		return fmt.Errorf("invalid Classifier.FrameSize: should be a positive number")
+	}
+
+	if c.Hop < 0 || c.Hop > c.FrameSize {
+		return fmt.Errorf("invalid Classifier.Hop: should be in range [0, FrameSize]")
+	}
+
+	if c.NumClasses <= 0 {
+		return fmt.Errorf("invalid Classifier.NumClasses: should be a positive number")
+	}
+
+	if c.SpeechClass < 0 || c.SpeechClass >= c.NumClasses {
+		return fmt.Errorf("invalid Classifier.SpeechClass: should be in range [0, NumClasses)")
+	}
+
+	if c.MinSpeechScore < 0 || c.MinSpeechScore >= 1 {
+		return fmt.Errorf("invalid Classifier.MinSpeechScore: should be in range [0, 1)")
+	}
+
+	return nil
+}
+
+// equal 按值比较两个分类模型配置，nil 表示未启用
+func (c *ClassifierConfig) equal(other *ClassifierConfig) bool {
+	if c == nil || other == nil {
+		return c == other
+	}
+	return *c == *other
+}
+
+// classifierModel 是 SharedModel 持有的分类会话，所有上下文共享
+type classifierModel struct {
+	sm       *SharedModel
+	cfg      ClassifierConfig
+	session  *C.OrtSession
+	cStrings map[string]*C.char
+}
+
+// newClassifierModel 使用共享模型的环境和会话选项加载分类模型
+func newClassifierModel(sm *SharedModel, cfg ClassifierConfig) (*classifierModel, error) {
+	if cfg.InputName == "" {
+		cfg.InputName = "input"
+	}
+	if cfg.OutputName == "" {
+		cfg.OutputName = "output"
+	}
+	if cfg.Hop == 0 {
+		cfg.Hop = cfg.FrameSize
+	}
+	if cfg.MinSpeechScore == 0 {
+		cfg.MinSpeechScore = defaultMinSpeechScore
+	}
+
+	cm := &classifierModel{
+		sm:       sm,
+		cfg:      cfg,
+		cStrings: map[string]*C.char{},
+	}
+
+	cm.cStrings["modelPath"] = C.CString(cfg.ModelPath)
+	cm.cStrings["input"] = C.CString(cfg.InputName)
+	cm.cStrings["output"] = C.CString(cfg.OutputName)
+	trackAlloc(nativeCString, len(cm.cStrings))
+
+	status := C.OrtApiCreateSession(sm.api, sm.env, cm.cStrings["modelPath"], sm.sessionOpts, &cm.session)
+	defer C.OrtApiReleaseStatus(sm.api, status)
+	if status != nil {
+		cm.release()
+		return nil, fmt.Errorf("%w: failed to create classifier session: %w", ErrModelLoad, newOrtError(sm.api, status))
+	}
+	trackAlloc(nativeSession, 1)
+
+	return cm, nil
+}
+
+// release 释放分类会话和 C 字符串
+func (cm *classifierModel) release() {
+	if cm.session != nil {
+		C.OrtApiReleaseSession(cm.sm.api, cm.session)
+		trackFree(nativeSession, 1)
+		cm.session = nil
+	}
+	for _, ptr := range cm.cStrings {
+		C.free(unsafe.Pointer(ptr))
+	}
+	trackFree(nativeCString, len(cm.cStrings))
+	cm.cStrings = nil
+}
+
+// run 对一帧音频分类，各类别的概率写入 scores
+func (cm *classifierModel) run(frame, scores []float32) error {
+	api := cm.sm.api
+
+	var frameValue *C.OrtValue
+	frameDims := []C.int64_t{1, C.int64_t(len(frame))}
+	status := C.OrtApiCreateTensorWithDataAsOrtValue(
+		api,
+		cm.sm.memoryInfo,
+		unsafe.Pointer(&frame[0]),
+		C.size_t(len(frame)*4),
+		&frameDims[0],
+		C.size_t(len(frameDims)),
+		C.ONNX_TENSOR_ELEMENT_DATA_TYPE_FLOAT,
+		&frameValue,
+	)
+	defer C.OrtApiReleaseStatus(api, status)
+	if status != nil {
+		return fmt.Errorf("failed to create classifier input value: %w", newOrtError(api, status))
+	}
+	defer C.OrtApiReleaseValue(api, frameValue)
+
+	inputNames := []*C.char{cm.cStrings["input"]}
+	outputNames := []*C.char{cm.cStrings["output"]}
+	var output *C.OrtValue
+	status = C.OrtApiRun(
+		api,
+		cm.session,
+		nil,
+		&inputNames[0],
+		&frameValue,
+		1,
+		&outputNames[0],
+		1,
+		&output,
+	)
+	defer C.OrtApiReleaseStatus(api, status)
+	if status != nil {
+		return fmt.Errorf("failed to run classifier: %w", newOrtError(api, status))
+	}
+	defer C.OrtApiReleaseValue(api, output)
+
+	var data unsafe.Pointer
+	status = C.OrtApiGetTensorMutableData(api, output, &data)
+	defer C.OrtApiReleaseStatus(api, status)
+	if status != nil {
+		return fmt.Errorf("failed to get classifier output data: %w", newOrtError(api, status))
+	}
+	C.memcpy(unsafe.Pointer(&scores[0]), data, C.size_t(len(scores)*4))
+
+	return nil
+}
+
+// classifierStage 是每个上下文独立的分类状态
+// 分类是因果的：保留最近 FrameSize 个采样，每累计 Hop 个新采样分类一次，
+// 结果作用于之后的窗口，直到下一次分类。第一次分类之前不抑制任何窗口。
+type classifierStage struct {
+	model   *classifierModel
+	history []float32 // 最近的采样，最多 FrameSize 个
+	pending int       // 上次分类之后新增的采样数
+	scores  []float32 // 最近一次分类的各类别概率
+	speech  float32   // 最近一次分类的语音类别概率，尚未分类时为 1
+}
+
+func (cm *classifierModel) newStage() *classifierStage {
+	return &classifierStage{
+		model:   cm,
+		history: make([]float32, 0, cm.cfg.FrameSize),
+		scores:  make([]float32, cm.cfg.NumClasses),
+		speech:  1,
+	}
+}
+
+// fuse 把一个窗口加入分类历史并按需分类，返回与分类结果融合后的语音概率
+func (s *classifierStage) fuse(window []float32, prob float32) (float32, error) {
+	cfg := &s.model.cfg
+	if over := len(s.history) + len(window) - cfg.FrameSize; over > 0 {
+		n := copy(s.history, s.history[min(over, len(s.history)):])
+		s.history = s.history[:n]
+	}
+	s.history = append(s.history, window[max(len(window)-cfg.FrameSize, 0):]...)
+	s.pending += len(window)
+
+	if len(s.history) == cfg.FrameSize && s.pending >= cfg.Hop {
+		if err := s.model.run(s.history, s.scores); err != nil {
+			return 0, err
+		}
+		s.pending = 0
+		s.speech = s.scores[cfg.SpeechClass]
+	}
+
+	if s.speech < cfg.MinSpeechScore {
+		return 0, nil
+	}
+	return prob, nil
+}
+
+// reset 清空分类历史，恢复为不抑制
+func (s *classifierStage) reset() {
+	s.history = s.history[:0]
+	s.pending = 0
+	clear(s.scores)
+	s.speech = 1
+}
+
+// AudioClasses 返回该上下文最近一次分类的各类别概率，下标与分类模型的输出一致
+// 未配置 Classifier 或尚未完成第一次分类时返回 nil。
+func (dc *DetectorContext) AudioClasses() []float32 {
+	if dc == nil || dc.classifier == nil || len(dc.classifier.history) < dc.classifier.model.cfg.FrameSize {
+		return nil
+	}
+	return append([]float32(nil), dc.classifier.scores...)
+}
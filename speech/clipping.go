@@ -0,0 +1,46 @@
+package speech
+
+// clipLevel 视为削波的采样幅度；16 位 PCM 的 32767 转换为 float32 后略小于 1
+const clipLevel = 32767.0 / 32768
+
+// clipDetector 在预处理之前查找输入中连续削波的采样
+// 高通滤波等阶段会改变削波的平顶，因此在原始输入上检测，再把位置对应到预处理输出的窗口上。
+// 检测循环按顺序逐窗口消费最近一次 apply 的输出，take 据此判断每个窗口内是否出现了过长的削波。
+type clipDetector struct {
+	run    int   // 输入末尾连续削波的采样数，跨调用保留
+	at     []int // 最近一次 apply 的输出中削波长度超过上限的位置
+	next   int   // at 中下一个未消费的下标
+	cursor int   // 已消费的输出采样数
+}
+
+// scan 查找 in 中长度超过 maxRun 的削波，shift 为输出与输入的长度差（有延迟的降噪阶段）
+func (c *clipDetector) scan(in []float32, maxRun, shift int) {
+	c.at, c.next, c.cursor = c.at[:0], 0, 0
+	for i, v := range in {
+		if !(v >= clipLevel || v <= -clipLevel) { // NaN 不算削波
+			c.run = 0
+			continue
+		}
+		c.run++
+		if c.run == maxRun+1 {
+			c.at = append(c.at, max(i+shift, 0))
+		}
+	}
+}
+
+// take 消费输出中的下一个窗口，返回窗口内是否有超过上限的削波
+func (c *clipDetector) take(windowSize int) bool {
+	c.cursor += windowSize
+	clipped := false
+	for c.next < len(c.at) && c.at[c.next] < c.cursor {
+		clipped = true
+		c.next++
+	}
+	return clipped
+}
+
+// reset 清空削波状态
+func (c *clipDetector) reset() {
+	c.run = 0
+	c.at, c.next, c.cursor = c.at[:0], 0, 0
+}
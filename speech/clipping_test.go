@@ -0,0 +1,67 @@
+package speech
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaxClippedRun(t *testing.T) {
+	sm := newTestSharedModel(t)
+	samples := readTestSamples(t, "../testfiles/samples.pcm")
+
+	cfg := sm.GetConfig()
+	cfg.MaxClippedRun = -1
+	require.Error(t, cfg.IsValid())
+
+	dc := sm.NewContext()
+	defer dc.Close()
+	require.NoError(t, dc.WithConfig(func(cfg *DetectorConfig) {
+		cfg.MaxClippedRun = 5
+	}))
+	segments, err := dc.Detect(samples)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, len(segments), 2)
+	for _, seg := range segments {
+		require.False(t, seg.Clipped)
+	}
+
+	// 在第一个片段中间写入 6 个满幅采样（32767 转换后的值），第二个片段中只写入 5 个
+	clip := func(pcm []float32, at float64, n int) {
+		i := int(at * 16000)
+		for j := 0; j < n; j++ {
+			pcm[i+j] = clipLevel
+		}
+	}
+	clipped := append([]float32(nil), samples...)
+	clip(clipped, (segments[0].SpeechStartAt+segments[0].SpeechEndAt)/2, 6)
+	clip(clipped, (segments[1].SpeechStartAt+segments[1].SpeechEndAt)/2, 5)
+
+	require.NoError(t, dc.Reset())
+	got, err := dc.Detect(clipped)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, len(got), 2)
+	require.True(t, got[0].Clipped)
+	require.False(t, got[1].Clipped)
+
+	// 流式输入时削波可以跨越窗口和调用边界
+	require.NoError(t, dc.Reset())
+	mid := int((segments[1].SpeechStartAt+segments[1].SpeechEndAt)/2*16000) / 512 * 512
+	clip(clipped, float64(mid-3)/16000, 6)
+	chunker, err := NewStreamChunker(16000)
+	require.NoError(t, err)
+	var ended []Segment
+	for off := 0; off < len(clipped); off += 320 {
+		chunker.Write(clipped[off:min(off+320, len(clipped))])
+		segs, err := dc.DetectChunks(chunker)
+		require.NoError(t, err)
+		for _, seg := range segs {
+			if seg.SpeechEndAt != 0 {
+				ended = append(ended, seg)
+			}
+		}
+	}
+	require.GreaterOrEqual(t, len(ended), 2)
+	require.True(t, ended[0].Clipped)
+	require.True(t, ended[1].Clipped)
+}
@@ -0,0 +1,108 @@
+package speech
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/rui-yang-me/silero-vad-go/audio"
+)
+
+// Clone 复制该上下文，副本从当前位置继续检测，此后两者互不影响
+// 复制的内容包括模型的循环状态和上下文窗口、采样计数和进行中的片段、WithConfig 设置的配置、
+// 预处理（滤波、降噪、AGC、重采样）和分类模型的流式状态，以及信噪比、响度、削波和低功耗模式的统计，
+// 因此可以把同一路实时音频分叉，例如在副本上用另一个阈值继续检测，而不必从头重建状态。
+// 副本的 RTF 统计从零开始，窗口回调和优先级与原上下文相同。
+// SetPreprocessors 设置的阶段需要实现 audio.Cloner，否则返回错误。不能与该上下文的检测调用同时进行。
+// 副本与 NewContext 创建的上下文一样计入模型的引用，使用完毕后需要 Close。
+func (dc *DetectorContext) Clone() (*DetectorContext, error) {
+	if dc == nil || dc.model == nil {
+		return nil, fmt.Errorf("invalid nil detector context")
+	}
+	if dc.closed.Load() {
+		return nil, ErrContextClosed
+	}
+	if dc.model.destroyed.Load() {
+		return nil, ErrModelDestroyed
+	}
+
+	stages := make([]audio.Processor, len(dc.pre.stages))
+	for i, stage := range dc.pre.stages {
+		c, ok := stage.(audio.Cloner)
+		if !ok {
+			return nil, fmt.Errorf("preprocessor stage %d (%T) does not implement audio.Cloner", i, stage)
+		}
+		stages[i] = c.Clone()
+	}
+
+	c := dc.model.NewContext()
+	c.cfg.Store(dc.cfg.Load())
+	c.state = dc.state
+	c.ctx = dc.ctx
+	c.currSample = dc.currSample
+	c.triggered = dc.triggered
+	c.tempEnd = dc.tempEnd
+	c.startAt = dc.startAt
+	c.observer = dc.observer
+	c.priority = dc.priority
+	c.lastVersion = dc.lastVersion
+	c.snr = dc.snr
+	c.loudness = dc.loudness.clone()
+	c.clipped = dc.clipped
+	c.pre.cloneFrom(&dc.pre, stages)
+	if dc.classifier != nil && c.classifier != nil {
+		c.classifier.copyFrom(dc.classifier)
+	}
+	if dc.duty != nil {
+		c.duty = dc.duty.clone()
+	}
+	return c, nil
+}
+
+// cloneFrom 复制 src 的滤波状态，stages 为已经复制好的调用方阶段；降噪阶段由 NewContext 创建，这里只复制状态
+func (p *preprocessor) cloneFrom(src *preprocessor, stages []audio.Processor) {
+	if src.highPass != nil {
+		p.highPass = src.highPass.Clone().(*audio.Biquad)
+	}
+	p.cutoff = src.cutoff
+	if src.denoiser != nil && p.denoiser != nil {
+		copy(p.denoiser.state, src.denoiser.state)
+		p.denoiser.pending = slices.Clone(src.denoiser.pending)
+	}
+	if src.agc != nil {
+		p.agc = src.agc.Clone().(*audio.AGC)
+	}
+	p.agcCfg = src.agcCfg
+	p.stages = stages
+	if src.resampler != nil {
+		p.resampler = src.resampler.Clone()
+	}
+	p.clip.run = src.clip.run
+}
+
+// copyFrom 复制 src 的采样历史和最近一次分类结果
+func (s *classifierStage) copyFrom(src *classifierStage) {
+	s.history = append(s.history[:0], src.history...)
+	s.pending = src.pending
+	copy(s.scores, src.scores)
+	s.speech = src.speech
+}
+
+// clone 返回响度统计的副本
+func (t loudnessTracker) clone() loudnessTracker {
+	if t.filter != nil {
+		t.filter = t.filter.Clone().(*audio.KWeighting)
+	}
+	t.buf = nil
+	t.windows = slices.Clone(t.windows)
+	t.blockBuf = nil
+	return t
+}
+
+// clone 返回低功耗模式状态的副本，配置与原状态共享
+func (d *dutyCycler) clone() *dutyCycler {
+	c := *d
+	energy := *d.energy
+	c.energy = &energy
+	c.history = slices.Clone(d.history)
+	return &c
+}
@@ -0,0 +1,90 @@
+package speech
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rui-yang-me/silero-vad-go/audio"
+)
+
+// passthrough 不实现 audio.Cloner 的处理阶段
+type passthrough struct{}
+
+func (passthrough) Process(dst, src []float32) []float32 { return append(dst, src...) }
+func (passthrough) Reset()                               {}
+
+func TestClone(t *testing.T) {
+	sm := newTestSharedModel(t)
+	samples := readTestSamples(t, "../testfiles/samples.pcm")
+	// 在第二个片段中间分叉，滤波和 AGC 的状态也需要复制
+	split := 3 * 16000
+
+	detect := func(dc *DetectorContext, pcm []float32) []Segment {
+		chunker, err := NewStreamChunker(16000)
+		require.NoError(t, err)
+		var segments []Segment
+		for off := 0; off < len(pcm); off += 320 {
+			chunker.Write(pcm[off:min(off+320, len(pcm))])
+			segs, err := dc.DetectChunks(chunker)
+			require.NoError(t, err)
+			segments = mergeSegments(segments, segs)
+		}
+		return segments
+	}
+	withPre := func(cfg *DetectorConfig) {
+		cfg.HighPassCutoffHz = 80
+		cfg.AGC = &audio.AGCConfig{TargetDB: -20}
+	}
+
+	ref := sm.NewContext()
+	defer ref.Close()
+	require.NoError(t, ref.WithConfig(withPre))
+	ref.SetPreprocessors(mustNoiseGate(t))
+	expected := detect(ref, samples)
+
+	dc := sm.NewContext()
+	defer dc.Close()
+	require.NoError(t, dc.WithConfig(withPre))
+	dc.SetPreprocessors(mustNoiseGate(t))
+	head := detect(dc, samples[:split])
+
+	clone, err := dc.Clone()
+	require.NoError(t, err)
+	defer clone.Close()
+	require.Equal(t, dc.GetConfig(), clone.GetConfig())
+
+	// 原上下文和副本从分叉处继续，都与不分叉的结果一致
+	tail := detect(dc, samples[split:])
+	require.Equal(t, expected, mergeSegments(append([]Segment(nil), head...), tail))
+	require.Equal(t, tail, detect(clone, samples[split:]))
+
+	// 副本可以换一个阈值继续，不影响原上下文
+	dc2 := sm.NewContext()
+	defer dc2.Close()
+	detect(dc2, samples[:split])
+	fork, err := dc2.Clone()
+	require.NoError(t, err)
+	defer fork.Close()
+	require.NoError(t, fork.WithConfig(func(cfg *DetectorConfig) { cfg.Threshold = 0.95 }))
+	strict := detect(fork, samples[split:])
+	loose := detect(dc2, samples[split:])
+	require.NotEqual(t, strict, loose)
+	require.Equal(t, float32(0.5), dc2.GetConfig().Threshold)
+
+	// 不能复制的处理阶段
+	dc.SetPreprocessors(passthrough{})
+	_, err = dc.Clone()
+	require.ErrorContains(t, err, "audio.Cloner")
+
+	closed := sm.NewContext()
+	require.NoError(t, closed.Close())
+	_, err = closed.Clone()
+	require.ErrorIs(t, err, ErrContextClosed)
+}
+
+func mustNoiseGate(t *testing.T) *audio.NoiseGate {
+	g, err := audio.NewNoiseGate(audio.NoiseGateConfig{SampleRate: 16000, ThresholdDB: -60})
+	require.NoError(t, err)
+	return g
+}
@@ -0,0 +1,228 @@
+package speech
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/rui-yang-me/silero-vad-go/audio"
+)
+
+// 推荐的默认检测参数，DefaultConfig 和 ConfigBuilder 以它们为初始值
+const (
+	DefaultSampleRate           = 16000
+	DefaultThreshold            = 0.5
+	DefaultMinSilenceDurationMs = 100
+	DefaultSpeechPadMs          = 30
+)
+
+// DefaultConfig 返回使用推荐默认值的配置：16kHz、阈值 0.5、静音 100ms、填充 30ms
+// 其余字段为零值，即不启用会话池、预处理和批量推理等可选功能。
+func DefaultConfig(modelPath string) DetectorConfig {
+	return DetectorConfig{
+		ModelPath:            modelPath,
+		SampleRate:           DefaultSampleRate,
+		Threshold:            DefaultThreshold,
+		MinSilenceDurationMs: DefaultMinSilenceDurationMs,
+		SpeechPadMs:          DefaultSpeechPadMs,
+	}
+}
+
+// clone 返回配置的深拷贝，指针、切片和映射字段不与 c 共享
+func (c DetectorConfig) clone() DetectorConfig {
+	if c.SessionCPUs != nil {
+		cpus := make([][]int, len(c.SessionCPUs))
+		for i, set := range c.SessionCPUs {
+			cpus[i] = slices.Clone(set)
+		}
+		c.SessionCPUs = cpus
+	}
+	if c.Denoiser != nil {
+		d := *c.Denoiser
+		d.StateShape = slices.Clone(d.StateShape)
+		c.Denoiser = &d
+	}
+	if c.Classifier != nil {
+		cl := *c.Classifier
+		c.Classifier = &cl
+	}
+	if c.AGC != nil {
+		agc := *c.AGC
+		c.AGC = &agc
+	}
+	if c.DutyCycle != nil {
+		duty := *c.DutyCycle
+		c.DutyCycle = &duty
+	}
+	c.ExecutionProvider.Options = maps.Clone(c.ExecutionProvider.Options)
+	return c
+}
+
+// ConfigBuilder 以链式调用的方式构造 DetectorConfig，未设置的字段使用 DefaultConfig 的默认值
+//
+//	cfg, err := speech.NewConfigBuilder("silero_vad.onnx").
+//		SampleRate(8000).
+//		MinSilence(300 * time.Millisecond).
+//		Build()
+type ConfigBuilder struct {
+	cfg DetectorConfig
+}
+
+// NewConfigBuilder 创建以 DefaultConfig(modelPath) 为初始值的构造器
+func NewConfigBuilder(modelPath string) *ConfigBuilder {
+	return &ConfigBuilder{cfg: DefaultConfig(modelPath)}
+}
+
+// SampleRate 设置输入采样率，支持 8000 和 16000，启用 AutoResample 时可以是其它采样率
+func (b *ConfigBuilder) SampleRate(rate int) *ConfigBuilder {
+	b.cfg.SampleRate = rate
+	return b
+}
+
+// AutoResample 设置是否把模型不支持的采样率在内部重采样为 16kHz（低于 16kHz 时为 8kHz）
+func (b *ConfigBuilder) AutoResample(enabled bool) *ConfigBuilder {
+	b.cfg.AutoResample = enabled
+	return b
+}
+
+// Threshold 设置语音概率阈值
+func (b *ConfigBuilder) Threshold(threshold float32) *ConfigBuilder {
+	b.cfg.Threshold = threshold
+	return b
+}
+
+// MinSilence 设置结束一个语音片段所需的静音时长，按毫秒截断
+func (b *ConfigBuilder) MinSilence(d time.Duration) *ConfigBuilder {
+	b.cfg.MinSilenceDurationMs = int(d.Milliseconds())
+	return b
+}
+
+// SpeechPad 设置片段两端的填充时长，按毫秒截断
+func (b *ConfigBuilder) SpeechPad(d time.Duration) *ConfigBuilder {
+	b.cfg.SpeechPadMs = int(d.Milliseconds())
+	return b
+}
+
+// LogLevel 设置 ONNX Runtime 的日志级别
+func (b *ConfigBuilder) LogLevel(level LogLevel) *ConfigBuilder {
+	b.cfg.LogLevel = level
+	return b
+}
+
+// SessionPoolSize 设置共享模型的会话数
+func (b *ConfigBuilder) SessionPoolSize(n int) *ConfigBuilder {
+	b.cfg.SessionPoolSize = n
+	return b
+}
+
+// HighPass 设置推理前高通滤波的截止频率，0 表示不滤波
+func (b *ConfigBuilder) HighPass(cutoffHz float64) *ConfigBuilder {
+	b.cfg.HighPassCutoffHz = cutoffHz
+	return b
+}
+
+// Denoiser 设置推理前运行的降噪模型
+func (b *ConfigBuilder) Denoiser(cfg DenoiserConfig) *ConfigBuilder {
+	b.cfg.Denoiser = &cfg
+	return b
+}
+
+// Classifier 设置与 VAD 并行运行的语音/音乐分类模型
+func (b *ConfigBuilder) Classifier(cfg ClassifierConfig) *ConfigBuilder {
+	b.cfg.Classifier = &cfg
+	return b
+}
+
+// AGC 设置推理前的自动增益控制
+func (b *ConfigBuilder) AGC(cfg audio.AGCConfig) *ConfigBuilder {
+	b.cfg.AGC = &cfg
+	return b
+}
+
+// InputCheck 设置输入中 NaN、Inf 和非规格化采样的处理方式
+func (b *ConfigBuilder) InputCheck(check InputCheck) *ConfigBuilder {
+	b.cfg.InputCheck = check
+	return b
+}
+
+// MaxConcurrentInferences 设置同时进行的窗口推理数上限，见 Priority
+func (b *ConfigBuilder) MaxConcurrentInferences(n int) *ConfigBuilder {
+	b.cfg.MaxConcurrentInferences = n
+	return b
+}
+
+// Batching 启用批量推理，每批最多 maxSize 个窗口、最多等待 maxDelay
+func (b *ConfigBuilder) Batching(maxSize int, maxDelay time.Duration) *ConfigBuilder {
+	b.cfg.MaxBatchSize = maxSize
+	b.cfg.MaxBatchDelay = maxDelay
+	return b
+}
+
+// SessionCPUs 设置各会话固定的 CPU，见 NUMANodeCPUs
+func (b *ConfigBuilder) SessionCPUs(cpus [][]int) *ConfigBuilder {
+	b.cfg.SessionCPUs = cpus
+	return b
+}
+
+// RecoverAfterFailures 设置连续推理失败多少次后重建会话，见 SetHealthObserver
+func (b *ConfigBuilder) RecoverAfterFailures(n int) *ConfigBuilder {
+	b.cfg.RecoverAfterFailures = n
+	return b
+}
+
+// Deterministic 启用确定性模式，用于比较不同运行和机器的检测结果
+func (b *ConfigBuilder) Deterministic(enabled bool) *ConfigBuilder {
+	b.cfg.Deterministic = enabled
+	return b
+}
+
+// PadShortInput 设置是否补零处理不足一个窗口的输入
+func (b *ConfigBuilder) PadShortInput(enabled bool) *ConfigBuilder {
+	b.cfg.PadShortInput = enabled
+	return b
+}
+
+// EstimateSNR 设置是否估计每个片段的信噪比
+func (b *ConfigBuilder) EstimateSNR(enabled bool) *ConfigBuilder {
+	b.cfg.EstimateSNR = enabled
+	return b
+}
+
+// MeasureLoudness 设置是否测量每个片段的响度（LUFS）和 RMS 电平
+func (b *ConfigBuilder) MeasureLoudness(enabled bool) *ConfigBuilder {
+	b.cfg.MeasureLoudness = enabled
+	return b
+}
+
+// MaxClippedRun 设置连续满幅采样的上限，超过时把片段标记为削波，0 表示不检查
+func (b *ConfigBuilder) MaxClippedRun(n int) *ConfigBuilder {
+	b.cfg.MaxClippedRun = n
+	return b
+}
+
+// Calibration 设置模型语音概率的校准，见 FitCalibration
+func (b *ConfigBuilder) Calibration(c Calibration) *ConfigBuilder {
+	b.cfg.Calibration = c
+	return b
+}
+
+// DutyCycle 设置低功耗模式，nil 表示关闭
+func (b *ConfigBuilder) DutyCycle(c *DutyCycleConfig) *ConfigBuilder {
+	b.cfg.DutyCycle = c
+	return b
+}
+
+// ExecutionProvider 设置推理使用的执行提供者，例如 CUDA(0)
+func (b *ConfigBuilder) ExecutionProvider(ep ExecutionProvider) *ConfigBuilder {
+	b.cfg.ExecutionProvider = ep
+	return b
+}
+
+// Build 校验并返回配置
+func (b *ConfigBuilder) Build() (DetectorConfig, error) {
+	if err := b.cfg.IsValid(); err != nil {
+		return DetectorConfig{}, fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+	}
+	return b.cfg, nil
+}
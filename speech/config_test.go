@@ -0,0 +1,118 @@
+package speech
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rui-yang-me/silero-vad-go/audio"
+)
+
+func TestConfigBuilder(t *testing.T) {
+	cfg := DefaultConfig("model.onnx")
+	require.NoError(t, cfg.IsValid())
+	require.Equal(t, 16000, cfg.SampleRate)
+	require.EqualValues(t, 0.5, cfg.Threshold)
+	require.Equal(t, 100, cfg.MinSilenceDurationMs)
+	require.Equal(t, 30, cfg.SpeechPadMs)
+
+	built, err := NewConfigBuilder("model.onnx").Build()
+	require.NoError(t, err)
+	require.Equal(t, cfg, built)
+
+	built, err = NewConfigBuilder("model.onnx").
+		SampleRate(8000).
+		Threshold(0.6).
+		MinSilence(300*time.Millisecond).
+		SpeechPad(50*time.Millisecond).
+		HighPass(80).
+		AGC(audio.AGCConfig{TargetDB: -20}).
+		InputCheck(InputCheckSanitize).
+		Batching(64, 10*time.Millisecond).
+		Build()
+	require.NoError(t, err)
+	require.Equal(t, 8000, built.SampleRate)
+	require.Equal(t, float32(0.6), built.Threshold)
+	require.Equal(t, 300, built.MinSilenceDurationMs)
+	require.Equal(t, 50, built.SpeechPadMs)
+	require.Equal(t, 80.0, built.HighPassCutoffHz)
+	require.Equal(t, &audio.AGCConfig{TargetDB: -20}, built.AGC)
+	require.Equal(t, InputCheckSanitize, built.InputCheck)
+	require.Equal(t, 64, built.MaxBatchSize)
+
+	_, err = NewConfigBuilder("").Build()
+	require.ErrorIs(t, err, ErrInvalidConfig)
+	_, err = NewConfigBuilder("model.onnx").SampleRate(44100).Build()
+	require.ErrorIs(t, err, ErrInvalidConfig)
+}
+
+func TestConfigClone(t *testing.T) {
+	newConfig := func() DetectorConfig {
+		cfg := DefaultConfig("model.onnx")
+		cfg.SessionCPUs = [][]int{{0, 1}, {2}}
+		cfg.Denoiser = &DenoiserConfig{ModelPath: "denoiser.onnx", FrameSize: 480, StateShape: []int64{2}}
+		cfg.Classifier = &ClassifierConfig{ModelPath: "classifier.onnx", FrameSize: 8000, NumClasses: 2}
+		cfg.AGC = &audio.AGCConfig{TargetDB: -20}
+		cfg.DutyCycle = &DutyCycleConfig{WakeAbove: 0.3}
+		cfg.ExecutionProvider = CUDA(0)
+		return cfg
+	}
+	cfg := newConfig()
+	c := cfg.clone()
+	require.Equal(t, cfg, c)
+
+	// 所有引用类型的字段都需要复制，新增这类字段时需要同时修改 clone
+	v, cv := reflect.ValueOf(cfg), reflect.ValueOf(c)
+	for i := 0; i < v.NumField(); i++ {
+		f, cf := v.Field(i), cv.Field(i)
+		switch f.Kind() {
+		case reflect.Pointer, reflect.Slice, reflect.Map:
+			require.False(t, f.IsNil(), v.Type().Field(i).Name)
+			require.NotEqual(t, f.Pointer(), cf.Pointer(), v.Type().Field(i).Name)
+		}
+	}
+
+	c.SessionCPUs[0][0] = 7
+	c.Denoiser.StateShape[0] = 3
+	c.Classifier.FrameSize = 1
+	c.AGC.TargetDB = -30
+	c.DutyCycle.WakeAbove = 0.5
+	c.ExecutionProvider.Options["device_id"] = "1"
+	require.Equal(t, newConfig(), cfg)
+}
+
+func TestConfigSnapshotIsolation(t *testing.T) {
+	cfg := DefaultConfig("../testfiles/silero_vad.onnx")
+	cfg.AGC = &audio.AGCConfig{TargetDB: -20}
+	sm, err := NewSharedModel(cfg)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, sm.Destroy())
+	}()
+
+	// 调用方持有的配置、GetConfig 的返回值和 WithConfig 中保留的指针都不影响快照
+	cfg.AGC.TargetDB = -10
+	g := sm.GetConfig()
+	require.Equal(t, -20.0, g.AGC.TargetDB)
+	g.AGC.TargetDB = -30
+	require.Equal(t, -20.0, sm.GetConfig().AGC.TargetDB)
+
+	dc := sm.NewContext()
+	defer dc.Close()
+	var kept *audio.AGCConfig
+	require.NoError(t, dc.WithConfig(func(cfg *DetectorConfig) {
+		cfg.AGC.TargetDB = -25
+		kept = cfg.AGC
+	}))
+	kept.TargetDB = -40
+	require.Equal(t, -25.0, dc.GetConfig().AGC.TargetDB)
+	require.Equal(t, -20.0, sm.GetConfig().AGC.TargetDB)
+
+	cfg = sm.GetConfig()
+	_, err = sm.UpdateConfig(cfg)
+	require.NoError(t, err)
+	cfg.AGC.TargetDB = -50
+	require.Equal(t, -20.0, sm.GetConfig().AGC.TargetDB)
+}
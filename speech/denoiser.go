@@ -0,0 +1,272 @@
+package speech
+
+// #cgo CFLAGS: -Wall -Werror -std=c99
+// #cgo LDFLAGS: -lonnxruntime
+// #include "ort_bridge.h"
+import "C"
+
+import (
+	"fmt"
+	"slices"
+	"unsafe"
+)
+
+// DenoiserConfig 描述在 VAD 推理之前运行的降噪模型（RNNoise/DeepFilterNet 一类）
+// 模型以固定长度的帧为单位工作：输入形状为 [1, FrameSize]，输出同形状的降噪后波形；
+// 可选地带有一个循环状态输入/输出，由每个上下文独立维护。
+type DenoiserConfig struct {
+	// ONNX 降噪模型路径
+	ModelPath string `json:"model_path" yaml:"model_path"`
+	// 每次推理的采样点数
+	FrameSize int `json:"frame_size" yaml:"frame_size"`
+	// 波形输入/输出节点名称，默认为 "input" 和 "output"
+	InputName  string `json:"input_name" yaml:"input_name"`
+	OutputName string `json:"output_name" yaml:"output_name"`
+	// 循环状态输入/输出节点名称及形状，StateInputName 为空表示模型无状态
+	StateInputName  string  `json:"state_input_name" yaml:"state_input_name"`
+	StateOutputName string  `json:"state_output_name" yaml:"state_output_name"`
+	StateShape      []int64 `json:"state_shape" yaml:"state_shape"`
+}
+
+// IsValid 校验降噪模型配置
+func (c *DenoiserConfig) IsValid() error {
+	if c.ModelPath == "" {
+		return fmt.Errorf("invalid Denoiser.ModelPath: should not be empty")
+	}
+
+	if c.FrameSize <= 0 {
+		return fmt.Errorf("invalid Denoiser.FrameSize: should be a positive number")
+	}
+
+	if c.StateInputName != "" {
+		if c.StateOutputName == "" || len(c.StateShape) == 0 {
+			return fmt.Errorf("invalid Denoiser state: StateOutputName and StateShape are required with StateInputName")
+		}
+		for _, d := range c.StateShape {
+			if d <= 0 {
+				return fmt.Errorf("invalid Denoiser.StateShape: dimensions should be positive")
+			}
+		}
+	}
+
+	return nil
+}
+
+// equal 按值比较两个降噪配置，nil 表示未启用
+func (c *DenoiserConfig) equal(other *DenoiserConfig) bool {
+	if c == nil || other == nil {
+		return c == other
+	}
+	return c.ModelPath == other.ModelPath &&
+		c.FrameSize == other.FrameSize &&
+		c.InputName == other.InputName &&
+		c.OutputName == other.OutputName &&
+		c.StateInputName == other.StateInputName &&
+		c.StateOutputName == other.StateOutputName &&
+		slices.Equal(c.StateShape, other.StateShape)
+}
+
+// stateSize 返回循环状态包含的元素个数
+func (c *DenoiserConfig) stateSize() int {
+	if c.StateInputName == "" {
+		return 0
+	}
+	n := 1
+	for _, d := range c.StateShape {
+		n *= int(d)
+	}
+	return n
+}
+
+// denoiserModel 是 SharedModel 持有的降噪会话，所有上下文共享
+type denoiserModel struct {
+	sm        *SharedModel
+	cfg       DenoiserConfig
+	session   *C.OrtSession
+	cStrings  map[string]*C.char
+	stateDims []C.int64_t
+}
+
+// newDenoiserModel 使用共享模型的环境和会话选项加载降噪模型
+func newDenoiserModel(sm *SharedModel, cfg DenoiserConfig) (*denoiserModel, error) {
+	if cfg.InputName == "" {
+		cfg.InputName = "input"
+	}
+	if cfg.OutputName == "" {
+		cfg.OutputName = "output"
+	}
+
+	dm := &denoiserModel{
+		sm:       sm,
+		cfg:      cfg,
+		cStrings: map[string]*C.char{},
+	}
+
+	dm.cStrings["modelPath"] = C.CString(cfg.ModelPath)
+	dm.cStrings["input"] = C.CString(cfg.InputName)
+	dm.cStrings["output"] = C.CString(cfg.OutputName)
+	if cfg.StateInputName != "" {
+		dm.cStrings["state"] = C.CString(cfg.StateInputName)
+		dm.cStrings["stateN"] = C.CString(cfg.StateOutputName)
+		for _, d := range cfg.StateShape {
+			dm.stateDims = append(dm.stateDims, C.int64_t(d))
+		}
+	}
+	trackAlloc(nativeCString, len(dm.cStrings))
+
+	status := C.OrtApiCreateSession(sm.api, sm.env, dm.cStrings["modelPath"], sm.sessionOpts, &dm.session)
+	defer C.OrtApiReleaseStatus(sm.api, status)
+	if status != nil {
+		dm.release()
+		return nil, fmt.Errorf("%w: failed to create denoiser session: %w", ErrModelLoad, newOrtError(sm.api, status))
+	}
+	trackAlloc(nativeSession, 1)
+
+	return dm, nil
+}
+
+// release 释放降噪会话和 C 字符串
+func (dm *denoiserModel) release() {
+	if dm.session != nil {
+		C.OrtApiReleaseSession(dm.sm.api, dm.session)
+		trackFree(nativeSession, 1)
+		dm.session = nil
+	}
+	for _, ptr := range dm.cStrings {
+		C.free(unsafe.Pointer(ptr))
+	}
+	trackFree(nativeCString, len(dm.cStrings))
+	dm.cStrings = nil
+}
+
+// run 对一帧音频执行降噪，结果写入 out，state 原地更新
+func (dm *denoiserModel) run(frame, out, state []float32) error {
+	api := dm.sm.api
+
+	var frameValue *C.OrtValue
+	frameDims := []C.int64_t{1, C.int64_t(len(frame))}
+	status := C.OrtApiCreateTensorWithDataAsOrtValue(
+		api,
+		dm.sm.memoryInfo,
+		unsafe.Pointer(&frame[0]),
+		C.size_t(len(frame)*4),
+		&frameDims[0],
+		C.size_t(len(frameDims)),
+		C.ONNX_TENSOR_ELEMENT_DATA_TYPE_FLOAT,
+		&frameValue,
+	)
+	defer C.OrtApiReleaseStatus(api, status)
+	if status != nil {
+		return fmt.Errorf("failed to create denoiser input value: %w", newOrtError(api, status))
+	}
+	defer C.OrtApiReleaseValue(api, frameValue)
+
+	inputs := []*C.OrtValue{frameValue}
+	inputNames := []*C.char{dm.cStrings["input"]}
+	outputNames := []*C.char{dm.cStrings["output"]}
+
+	if len(state) > 0 {
+		var stateValue *C.OrtValue
+		status = C.OrtApiCreateTensorWithDataAsOrtValue(
+			api,
+			dm.sm.memoryInfo,
+			unsafe.Pointer(&state[0]),
+			C.size_t(len(state)*4),
+			&dm.stateDims[0],
+			C.size_t(len(dm.stateDims)),
+			C.ONNX_TENSOR_ELEMENT_DATA_TYPE_FLOAT,
+			&stateValue,
+		)
+		defer C.OrtApiReleaseStatus(api, status)
+		if status != nil {
+			return fmt.Errorf("failed to create denoiser state value: %w", newOrtError(api, status))
+		}
+		defer C.OrtApiReleaseValue(api, stateValue)
+
+		inputs = append(inputs, stateValue)
+		inputNames = append(inputNames, dm.cStrings["state"])
+		outputNames = append(outputNames, dm.cStrings["stateN"])
+	}
+
+	outputs := make([]*C.OrtValue, len(outputNames))
+	status = C.OrtApiRun(
+		api,
+		dm.session,
+		nil,
+		&inputNames[0],
+		&inputs[0],
+		C.size_t(len(inputNames)),
+		&outputNames[0],
+		C.size_t(len(outputNames)),
+		&outputs[0],
+	)
+	defer C.OrtApiReleaseStatus(api, status)
+	if status != nil {
+		return fmt.Errorf("failed to run denoiser: %w", newOrtError(api, status))
+	}
+	for _, v := range outputs {
+		defer C.OrtApiReleaseValue(api, v)
+	}
+
+	var data unsafe.Pointer
+	status = C.OrtApiGetTensorMutableData(api, outputs[0], &data)
+	defer C.OrtApiReleaseStatus(api, status)
+	if status != nil {
+		return fmt.Errorf("failed to get denoiser output data: %w", newOrtError(api, status))
+	}
+	C.memcpy(unsafe.Pointer(&out[0]), data, C.size_t(len(out)*4))
+
+	if len(state) > 0 {
+		status = C.OrtApiGetTensorMutableData(api, outputs[1], &data)
+		defer C.OrtApiReleaseStatus(api, status)
+		if status != nil {
+			return fmt.Errorf("failed to get denoiser state data: %w", newOrtError(api, status))
+		}
+		C.memcpy(unsafe.Pointer(&state[0]), data, C.size_t(len(state)*4))
+	}
+
+	return nil
+}
+
+// denoiserStage 是每个上下文独立的降噪状态
+// 输入按 FrameSize 分帧，不足一帧的尾部保留到下一次调用，因此会引入最多一帧的延迟。
+type denoiserStage struct {
+	model   *denoiserModel
+	state   []float32
+	pending []float32
+}
+
+func (dm *denoiserModel) newStage() *denoiserStage {
+	return &denoiserStage{
+		model: dm,
+		state: make([]float32, dm.cfg.stateSize()),
+	}
+}
+
+// process 对所有完整的帧降噪并把结果追加到 dst 后返回
+func (s *denoiserStage) process(dst, src []float32) ([]float32, error) {
+	frameSize := s.model.cfg.FrameSize
+	s.pending = append(s.pending, src...)
+
+	n := len(s.pending) / frameSize * frameSize
+	for i := 0; i < n; i += frameSize {
+		start := len(dst)
+		dst = append(dst, make([]float32, frameSize)...)
+		if err := s.model.run(s.pending[i:i+frameSize], dst[start:], s.state); err != nil {
+			return dst[:start], err
+		}
+	}
+
+	rest := copy(s.pending, s.pending[n:])
+	s.pending = s.pending[:rest]
+
+	return dst, nil
+}
+
+// reset 清空循环状态和未处理的尾部
+func (s *denoiserStage) reset() {
+	for i := range s.state {
+		s.state[i] = 0
+	}
+	s.pending = s.pending[:0]
+}
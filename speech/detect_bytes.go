@@ -0,0 +1,129 @@
+package speech
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/rui-yang-me/silero-vad-go/audio"
+)
+
+// DetectBytes 解码音频字节后检测语音片段
+// 原始格式按小端序解析，其采样率必须与模型配置的 SampleRate 一致，配置了 InputSampleRate 时须为 InputSampleRate；
+// G.711 µ-law/A-law（电话网络常用的 8kHz 编码）可直接传入；
+// Ogg/Opus、MP3、FLAC 等压缩格式会被直接解码为 SampleRate 采样率的单声道音频。
+func (dc *DetectorContext) DetectBytes(data []byte, format audio.SampleFormat) ([]Segment, error) {
+	if dc == nil || dc.model == nil {
+		return nil, fmt.Errorf("invalid nil detector context")
+	}
+
+	// 解码为检测调用的输入采样率，由 Detect 统一重采样
+	pcm, err := audio.DecodeBytes(data, format, dc.config().inputRate())
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode samples: %w", err)
+	}
+
+	return dc.Detect(pcm)
+}
+
+// readerBlockSamples DetectReader 每次读取并转换的采样数
+const readerBlockSamples = 1 << 16
+
+// DetectReader 读取 r 中的全部音频并检测语音片段，格式约定同 DetectBytes
+// 原始格式按块读取，读取和转换下一块与当前块的推理在不同协程中重叠进行，不会把整个输入读入内存。
+func (dc *DetectorContext) DetectReader(r io.Reader, format audio.SampleFormat) ([]Segment, error) {
+	if dc == nil || dc.model == nil {
+		return nil, fmt.Errorf("invalid nil detector context")
+	}
+
+	return dc.detectReader(r, format, dc.config().inputRate())
+}
+
+// detectReader 实现 DetectReader，原始格式的采样率为 rate，与模型不一致时边读取边重采样
+// 压缩格式解码为输入采样率后交给 Detect。
+func (dc *DetectorContext) detectReader(r io.Reader, format audio.SampleFormat, rate int) ([]Segment, error) {
+	if size := format.BytesPerSample(); size > 0 {
+		var resampler *audio.Resampler
+		if modelRate := dc.config().SampleRate; rate != modelRate {
+			if err := checkInputRate(rate, modelRate); err != nil {
+				return nil, err
+			}
+			var err error
+			if resampler, err = audio.NewResampler(rate, modelRate); err != nil {
+				return nil, err
+			}
+		}
+
+		raw := make([]byte, readerBlockSamples*size)
+		var samples []float32
+		return dc.detectPipelined(func(dst []float32) ([]float32, error) {
+			n, err := io.ReadFull(r, raw)
+			if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+				if n%size != 0 {
+					return nil, fmt.Errorf("failed to decode samples: invalid data length: not a multiple of %d", size)
+				}
+				err = io.EOF
+			} else if err != nil {
+				return nil, err
+			}
+			if resampler == nil {
+				dst, convErr := audio.BytesToFloat32(dst, raw[:n], format, binary.LittleEndian)
+				if convErr != nil {
+					return nil, fmt.Errorf("failed to decode samples: %w", convErr)
+				}
+				return dst, err
+			}
+			var convErr error
+			samples, convErr = audio.BytesToFloat32(samples[:0], raw[:n], format, binary.LittleEndian)
+			if convErr != nil {
+				return nil, fmt.Errorf("failed to decode samples: %w", convErr)
+			}
+			dst = resampler.Process(dst[:0], samples)
+			if err == io.EOF {
+				dst = resampler.Flush(dst)
+			}
+			return dst, err
+		})
+	}
+
+	pcm, err := audio.Decode(r, format, dc.config().inputRate())
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode samples: %w", err)
+	}
+
+	return dc.Detect(pcm)
+}
+
+// checkInputRate 在创建重采样器之前检查配置或文件头声明的输入采样率，超出重采样支持的范围时返回 audio.ErrUnsupportedFormat
+func checkInputRate(rate, modelRate int) error {
+	if rate == modelRate {
+		return nil
+	}
+	if err := audio.CheckResampleRate(rate); err != nil {
+		return fmt.Errorf("%w: %w", audio.ErrUnsupportedFormat, err)
+	}
+	return nil
+}
+
+// DetectFFmpeg 启动 ffmpeg 把任意容器/编码的 input（文件路径或 URL）解码为 s16le 后检测，
+// 例如对 .mp4、.m4a 文件做检测。需要系统中安装 ffmpeg，参数见 audio.FFmpegConfig。
+func (dc *DetectorContext) DetectFFmpeg(ctx context.Context, input string, cfg audio.FFmpegConfig) ([]Segment, error) {
+	if dc == nil || dc.model == nil {
+		return nil, fmt.Errorf("invalid nil detector context")
+	}
+
+	rate := dc.config().SampleRate
+	s, err := audio.OpenFFmpeg(ctx, input, rate, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// ffmpeg 直接输出模型采样率，不需要再重采样
+	segments, err := dc.detectReader(s, audio.FormatPCM16, rate)
+	if closeErr := s.Close(); closeErr != nil {
+		return nil, closeErr
+	}
+	return segments, err
+}
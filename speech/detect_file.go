@@ -0,0 +1,241 @@
+package speech
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/rui-yang-me/silero-vad-go/audio"
+)
+
+// mappedBlockBytes DetectFile 每次解码的字节数，检测完的部分随即提示内核回收
+const mappedBlockBytes = 1 << 20
+
+// DetectFile 以内存映射的方式逐段检测 path 中的音频，适合数 GB 的归档录音
+// format 为 0 时按 WAV 解析文件头，采样率与模型不一致时自动重采样，多声道时先混为单声道；
+// 否则按 format 解析小端序的原始音频（PCM16、Float32 或 G.711），其采样率必须与模型一致，
+// 配置了 InputSampleRate 时须为 InputSampleRate。
+// 文件不会被整体读入 Go 堆，已检测的部分会释放其页面，常驻内存与文件大小无关。
+func (dc *DetectorContext) DetectFile(path string, format audio.SampleFormat) ([]Segment, error) {
+	if dc == nil || dc.model == nil {
+		return nil, fmt.Errorf("invalid nil detector context")
+	}
+
+	cfg := dc.config()
+	src, err := openMappedSource(path, format, cfg.inputRate(), cfg.SampleRate)
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+
+	return dc.detectPipelined(src.next)
+}
+
+// FileChunk ProcessFile 交给回调的一块音频及其检测结果
+type FileChunk struct {
+	// 本块在音频中的开始时间（秒）
+	Start float64
+	// 本块的模型采样率单声道采样，只在回调期间有效
+	PCM []float32
+	// 在本块内结束的语音片段，开始时间可能在之前的块中；最后一块包含以音频结尾作为终点的未结束片段
+	Segments []Segment
+}
+
+// ProcessFile 以 chunkSeconds 秒为一块依次检测 path 中的音频，每块检测完后调用 fn
+// 格式约定同 DetectFile。块之间连续保留检测状态，跨块的语音片段在结束所在的块中以完整的起止时间返回，
+// 因此各块 Segments 拼接起来与一次检测整个文件的结果相同（最后一个未结束的片段除外，它以音频结尾为终点）。
+// 内存占用只与 chunkSeconds 有关，适合按块把音频交给 ASR 等下游处理的长录音。
+// fn 返回错误时停止处理并返回该错误。
+func (dc *DetectorContext) ProcessFile(path string, format audio.SampleFormat, chunkSeconds float64, fn func(chunk FileChunk) error) error {
+	if dc == nil || dc.model == nil {
+		return fmt.Errorf("invalid nil detector context")
+	}
+	if fn == nil {
+		return fmt.Errorf("invalid nil chunk callback")
+	}
+
+	cfg := dc.callConfig()
+	chunkSamples := int(chunkSeconds * float64(cfg.SampleRate))
+	if chunkSamples < windowSizeFor(cfg.SampleRate) {
+		return fmt.Errorf("invalid chunk duration %gs: shorter than one window", chunkSeconds)
+	}
+
+	src, err := openMappedSource(path, format, cfg.inputRate(), cfg.SampleRate)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	chunker, err := NewStreamChunker(cfg.SampleRate)
+	if err != nil {
+		return err
+	}
+
+	var (
+		chunk   = make([]float32, 0, chunkSamples)
+		windows int
+		offset  int // 当前块之前的采样数
+	)
+	flush := func(eof bool) error {
+		chunker.Write(chunk)
+		if eof && cfg.PadShortInput {
+			chunker.Pad()
+		}
+		segments, err := dc.detectBuffered(cfg, chunker, nil, &windows)
+		if err != nil {
+			return err
+		}
+		if windows == 0 {
+			return ErrNotEnoughSamples
+		}
+
+		// 未结束的片段留到结束所在的块中返回
+		done := segments[:0]
+		for _, seg := range segments {
+			if seg.SpeechEndAt != 0 {
+				done = append(done, seg)
+			}
+		}
+		if eof && dc.triggered {
+			done = append(done, Segment{
+				SpeechStartAt: dc.startAt,
+				SpeechEndAt:   float64(offset+len(chunk)) / float64(cfg.SampleRate),
+			})
+		}
+
+		err = fn(FileChunk{
+			Start:    float64(offset) / float64(cfg.SampleRate),
+			PCM:      chunk,
+			Segments: done,
+		})
+		offset += len(chunk)
+		chunk = chunk[:0]
+		return err
+	}
+
+	return pipeline(src.next, func(pcm []float32, eof bool) error {
+		for len(pcm) > 0 {
+			n := min(chunkSamples-len(chunk), len(pcm))
+			chunk = append(chunk, pcm[:n]...)
+			pcm = pcm[n:]
+			if len(chunk) == chunkSamples && (len(pcm) > 0 || !eof) {
+				if err := flush(false); err != nil {
+					return err
+				}
+			}
+		}
+		if eof {
+			return flush(true)
+		}
+		return nil
+	})
+}
+
+// mappedSource 按块解码映射文件中的音频，输出模型采样率的单声道采样
+type mappedSource struct {
+	m          *audio.MappedFile
+	data       []byte
+	offset     int // data 在映射中的偏移，用于释放已处理的页面
+	rate       int
+	channels   int
+	frameBytes int // 一帧（所有声道的一个采样）的字节数，分段时按帧对齐
+	decode     func(dst []float32, b []byte) ([]float32, error)
+	resampler  *audio.Resampler
+
+	samples []float32 // 需要混音或重采样时的解码缓冲
+	pos     int       // 下一块在 data 中的起始位置
+	block   int       // 每块的字节数，按帧对齐
+	end     int       // data 中完整帧的结尾
+}
+
+// openMappedSource 映射 path 并解析音频格式，格式约定见 DetectFile
+// 原始格式的采样率为 rawRate，WAV 以文件头为准，输出统一重采样为 modelRate。
+func openMappedSource(path string, format audio.SampleFormat, rawRate, modelRate int) (*mappedSource, error) {
+	m, err := audio.MapFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	src := &mappedSource{m: m, data: m.Bytes(), rate: rawRate, channels: 1}
+	if format == 0 {
+		info, data, err := audio.ParseWAV(src.data)
+		if err != nil {
+			m.Close()
+			return nil, err
+		}
+		src.data, src.rate, src.channels = data, info.SampleRate, info.Channels
+		src.frameBytes = info.Channels * max(info.BitsPerSample/8, 1)
+		src.decode = func(dst []float32, b []byte) ([]float32, error) {
+			return audio.DecodeWAVSamples(dst, b, info)
+		}
+	} else {
+		bps := format.BytesPerSample()
+		if bps == 0 {
+			m.Close()
+			return nil, fmt.Errorf("%w: streaming format %s", audio.ErrUnsupportedFormat, format)
+		}
+		src.frameBytes = bps
+		src.decode = func(dst []float32, b []byte) ([]float32, error) {
+			return audio.BytesToFloat32(dst, b, format, binary.LittleEndian)
+		}
+	}
+	src.offset = cap(m.Bytes()) - cap(src.data)
+
+	if src.rate != modelRate {
+		if err := checkInputRate(src.rate, modelRate); err != nil {
+			m.Close()
+			return nil, err
+		}
+		if src.resampler, err = audio.NewResampler(src.rate, modelRate); err != nil {
+			m.Close()
+			return nil, err
+		}
+	}
+
+	src.block = mappedBlockBytes - mappedBlockBytes%src.frameBytes
+	src.end = len(src.data) - len(src.data)%src.frameBytes
+	return src, nil
+}
+
+// next 把下一块采样写入 dst 后返回，最后一块返回 io.EOF，约定同 detectPipelined 的 decode
+func (src *mappedSource) next(dst []float32) ([]float32, error) {
+	end := min(src.pos+src.block, src.end)
+	chunk := src.data[src.pos:end]
+	src.pos = end
+	defer src.m.Release(src.offset + end)
+
+	var err error
+	if src.channels == 1 && src.resampler == nil {
+		if dst, err = src.decode(dst, chunk); err != nil {
+			return nil, fmt.Errorf("failed to decode samples: %w", err)
+		}
+	} else {
+		if src.samples, err = src.decode(src.samples, chunk); err != nil {
+			return nil, fmt.Errorf("failed to decode samples: %w", err)
+		}
+		pcm := src.samples
+		if src.channels > 1 {
+			if pcm, err = audio.Downmix(pcm, src.channels); err != nil {
+				return nil, err
+			}
+		}
+		if src.resampler != nil {
+			dst = src.resampler.Process(dst, pcm)
+			if end == src.end {
+				dst = src.resampler.Flush(dst)
+			}
+		} else {
+			dst = append(dst, pcm...)
+		}
+	}
+
+	if end == src.end {
+		return dst, io.EOF
+	}
+	return dst, nil
+}
+
+// Close 解除文件映射
+func (src *mappedSource) Close() error {
+	return src.m.Close()
+}
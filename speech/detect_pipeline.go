@@ -0,0 +1,118 @@
+package speech
+
+import (
+	"errors"
+	"io"
+)
+
+// decodedBlock 解码协程产生的一块音频
+type decodedBlock struct {
+	pcm []float32
+	err error
+}
+
+// detectPipelined 在单独的协程中解码下一块音频，同时在调用方协程中对当前块推理
+// decode 的约定见 pipeline。每块推理期间才登记为进行中的检测调用，阻塞在读取上的调用不会拖住 Destroy。
+func (dc *DetectorContext) detectPipelined(decode func(dst []float32) ([]float32, error)) ([]Segment, error) {
+	cfg := dc.callConfig()
+	chunker, err := NewStreamChunker(cfg.SampleRate)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		segments []Segment
+		windows  int
+	)
+	err = pipeline(decode, func(pcm []float32, eof bool) error {
+		chunker.Write(pcm)
+		// 与 Stream 关闭时相同，PadShortInput 时补零检测最后不足一个窗口的尾部
+		if eof && cfg.PadShortInput {
+			chunker.Pad()
+		}
+		var err error
+		segments, err = dc.detectBuffered(cfg, chunker, segments, &windows)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if windows == 0 {
+		return nil, ErrNotEnoughSamples
+	}
+	return segments, nil
+}
+
+// pipeline 在单独的协程中解码下一块音频，同时在调用方协程中用 consume 处理当前块
+// decode 把下一块模型采样率的单声道采样写入 dst（可复用其容量）后返回，音频结束时返回 io.EOF，
+// 此时返回的采样仍会交给 consume，eof 为 true。两个缓冲交替用于解码，consume 返回后缓冲即被复用，
+// 解码和推理在多核机器上可以重叠进行。consume 返回错误时停止并返回该错误。
+func pipeline(decode func(dst []float32) ([]float32, error), consume func(pcm []float32, eof bool) error) error {
+	blocks := make(chan decodedBlock)
+	free := make(chan []float32, 2)
+	free <- nil
+	free <- nil
+	done := make(chan struct{})
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		for {
+			var buf []float32
+			select {
+			case buf = <-free:
+			case <-done:
+				return
+			}
+			buf, err := decode(buf[:0])
+			select {
+			case blocks <- decodedBlock{pcm: buf, err: err}:
+			case <-done:
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+	// 提前返回时通知解码协程退出，并等待它不再访问 decode 使用的资源
+	defer func() {
+		close(done)
+		<-exited
+	}()
+
+	for {
+		b := <-blocks
+		if b.err != nil && !errors.Is(b.err, io.EOF) {
+			return b.err
+		}
+		eof := b.err != nil
+		if err := consume(b.pcm, eof); err != nil {
+			return err
+		}
+		free <- b.pcm
+		if eof {
+			return nil
+		}
+	}
+}
+
+// detectBuffered 检测 chunker 中所有完整的窗口并累计窗口数，结果追加到 segments
+func (dc *DetectorContext) detectBuffered(cfg *DetectorConfig, c *StreamChunker, segments []Segment, windows *int) ([]Segment, error) {
+	if err := dc.acquire(); err != nil {
+		return nil, err
+	}
+	defer dc.release()
+
+	for {
+		frame, ok := c.Next()
+		if !ok {
+			return segments, nil
+		}
+		var err error
+		if segments, err = dc.detectFrame(cfg, frame, segments, 0); err != nil {
+			return nil, err
+		}
+		*windows++
+	}
+}
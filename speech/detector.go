@@ -8,7 +8,11 @@ import "C"
 import (
 	"fmt"
 	"log/slog"
+	"runtime"
+	"time"
 	"unsafe"
+
+	"github.com/rui-yang-me/silero-vad-go/audio"
 )
 
 const (
@@ -43,22 +47,104 @@ const (
 	LogLevelFatal
 )
 
+// DetectorConfig 检测器和共享模型的配置
+// SessionCPUs 是切片字段，DetectorConfig 不能用 == 比较，需要比较两个配置时使用 reflect.DeepEqual。
 type DetectorConfig struct {
 	// The path to the ONNX Silero VAD model file to load.
-	ModelPath string
+	ModelPath string `json:"model_path" yaml:"model_path"`
 	// The sampling rate of the input audio samples. Supported values are 8000 and 16000.
-	SampleRate int
+	SampleRate int `json:"sample_rate" yaml:"sample_rate"`
+	// Accepts other values of SampleRate, e.g. 48000 or 44100, instead of
+	// failing validation, and resamples the input internally. The model then
+	// runs at 16000, or 8000 for rates below 16kHz: constructors move the
+	// configured rate to InputSampleRate and SampleRate reports the effective
+	// model rate, as returned by GetConfig.
+	AutoResample bool `json:"auto_resample" yaml:"auto_resample"`
+	// The sampling rate of the audio passed to detection calls when it differs
+	// from SampleRate. Inputs are resampled to SampleRate by a streaming
+	// resampler whose state is kept across calls until Reset. Zero means
+	// SampleRate. Usually set by AutoResample.
+	InputSampleRate int `json:"input_sample_rate" yaml:"input_sample_rate"`
 	// The probability threshold above which we detect speech. A good default is 0.5.
-	Threshold float32
+	Threshold float32 `json:"threshold" yaml:"threshold"`
 	// The duration of silence to wait for each speech segment before separating it.
-	MinSilenceDurationMs int
+	MinSilenceDurationMs int `json:"min_silence_duration_ms" yaml:"min_silence_duration_ms"`
 	// The padding to add to speech segments to avoid aggressive cutting.
-	SpeechPadMs int
+	SpeechPadMs int `json:"speech_pad_ms" yaml:"speech_pad_ms"`
 	// The loglevel for the onnx environment, by default it is set to LogLevelWarn.
-	LogLevel LogLevel
+	LogLevel LogLevel `json:"log_level" yaml:"log_level"`
+	// The number of identical ONNX sessions a SharedModel creates. Contexts are
+	// assigned to sessions in round-robin order. Zero means a single session.
+	// Ignored by Detector.
+	SessionPoolSize int `json:"session_pool_size" yaml:"session_pool_size"`
+	// The maximum number of windows a SharedModel runs inference on at the same
+	// time across all contexts. When the limit is reached, windows of realtime
+	// contexts are scheduled ahead of batch contexts (see Priority). Zero means
+	// no limit and no scheduling. Ignored by Detector.
+	MaxConcurrentInferences int `json:"max_concurrent_inferences" yaml:"max_concurrent_inferences"`
+	// The maximum number of windows from different contexts a SharedModel
+	// coalesces into one batched inference. Batching trades a bounded delay
+	// (MaxBatchDelay) for far fewer session runs, which is what makes GPU
+	// execution providers cost-effective with hundreds of streams. Windows of
+	// realtime contexts are batched first. Zero or one disables batching;
+	// MaxConcurrentInferences is ignored when batching. Ignored by Detector.
+	MaxBatchSize int `json:"max_batch_size" yaml:"max_batch_size"`
+	// How long the first window of a batch waits for more windows before the
+	// batch runs, e.g. 10ms. Required when MaxBatchSize is greater than one.
+	MaxBatchDelay time.Duration `json:"max_batch_delay" yaml:"max_batch_delay"`
+	// Optional CPU sets for the sessions of a SharedModel, assigned to sessions
+	// in order and reused round-robin when shorter than SessionPoolSize. Each
+	// session is created on a thread pinned to its CPUs so its weights are
+	// allocated on that NUMA node, and detection calls of contexts bound to the
+	// session run pinned to the same CPUs. See NUMANodeCPUs. Only effective on
+	// Linux. Ignored by Detector.
+	SessionCPUs [][]int `json:"session_cpus" yaml:"session_cpus"`
+	// The number of consecutive failed ONNX Runtime runs after which a
+	// SharedModel rebuilds all its sessions and retries the failed window once,
+	// e.g. after an execution provider device reset. Recovery attempts are
+	// reported to the observer set with SetHealthObserver. Zero disables
+	// recovery. Ignored by Detector.
+	RecoverAfterFailures int `json:"recover_after_failures" yaml:"recover_after_failures"`
+	// Makes segment outputs reproducible across runs and machines. Cannot be combined with batching.
+	Deterministic bool `json:"deterministic" yaml:"deterministic"`
+	// Zero-pads inputs shorter than one window instead of returning ErrNotEnoughSamples.
+	PadShortInput bool `json:"pad_short_input" yaml:"pad_short_input"`
+	// The cutoff frequency of an optional high-pass filter applied before
+	// inference to remove DC offset and low-frequency rumble. 70-100Hz works
+	// well for speech. Zero disables the filter.
+	HighPassCutoffHz float64 `json:"high_pass_cutoff_hz" yaml:"high_pass_cutoff_hz"`
+	// An optional denoising model run on the input before inference. The model
+	// must operate at SampleRate. Only used by SharedModel.
+	Denoiser *DenoiserConfig `json:"denoiser" yaml:"denoiser"`
+	// An optional speech/music classifier; windows it scores as non-speech are treated as silence.
+	Classifier *ClassifierConfig `json:"classifier" yaml:"classifier"`
+	// How inputs are checked for NaN, Inf and denormal samples before
+	// preprocessing and inference. A single NaN poisons the recurrent state and
+	// every later probability until Reset. The default does not check.
+	InputCheck InputCheck `json:"input_check" yaml:"input_check"`
+	// An optional automatic gain control stage that slowly tracks the input
+	// level toward a target RMS. It runs after the high-pass filter and the
+	// denoiser, and helps keep Threshold stable with far-field microphones.
+	AGC *audio.AGCConfig `json:"agc" yaml:"agc"`
+	// Reports the signal-to-noise ratio of each segment in Segment.SNR. Only used by SharedModel.
+	EstimateSNR bool `json:"estimate_snr" yaml:"estimate_snr"`
+	// Reports the loudness and RMS level of each segment in Segment.Loudness and Segment.RMS.
+	MeasureLoudness bool `json:"measure_loudness" yaml:"measure_loudness"`
+	// Flags segments with a longer run of full-scale samples in Segment.Clipped. Zero disables it.
+	MaxClippedRun int `json:"max_clipped_run" yaml:"max_clipped_run"`
+	// An optional calibration of the raw model probability, see DetectorContext.FitCalibration.
+	Calibration Calibration `json:"calibration" yaml:"calibration"`
+	// An optional low-power mode that only wakes the model after energy activity. Nil disables it.
+	DutyCycle *DutyCycleConfig `json:"duty_cycle" yaml:"duty_cycle"`
+	// The ONNX Runtime execution provider used for inference, e.g. CUDA(0).
+	// The zero value runs on the CPU. It applies to the VAD model and the
+	// optional denoiser and classifier models.
+	ExecutionProvider ExecutionProvider `json:"execution_provider" yaml:"execution_provider"`
 }
 
 func (c DetectorConfig) IsValid() error {
+	c.resolveSampleRate()
+
 	if c.ModelPath == "" {
 		return fmt.Errorf("invalid ModelPath: should not be empty")
 	}
@@ -67,6 +153,10 @@ func (c DetectorConfig) IsValid() error {
 		return fmt.Errorf("invalid SampleRate: valid values are 8000 and 16000")
 	}
 
+	if c.InputSampleRate < 0 {
+		return fmt.Errorf("invalid InputSampleRate: should not be negative")
+	}
+
 	if c.Threshold <= 0 || c.Threshold >= 1 {
 		return fmt.Errorf("invalid Threshold: should be in range (0, 1)")
 	}
@@ -79,6 +169,80 @@ func (c DetectorConfig) IsValid() error {
 		return fmt.Errorf("invalid SpeechPadMs: should be a positive number")
 	}
 
+	if c.SessionPoolSize < 0 {
+		return fmt.Errorf("invalid SessionPoolSize: should be a positive number")
+	}
+
+	if c.MaxConcurrentInferences < 0 {
+		return fmt.Errorf("invalid MaxConcurrentInferences: should be a positive number")
+	}
+
+	if c.MaxBatchSize < 0 {
+		return fmt.Errorf("invalid MaxBatchSize: should be a positive number")
+	}
+
+	if c.MaxBatchSize > 1 && c.MaxBatchDelay <= 0 {
+		return fmt.Errorf("invalid MaxBatchDelay: should be positive when batching")
+	}
+
+	if c.RecoverAfterFailures < 0 {
+		return fmt.Errorf("invalid RecoverAfterFailures: should be a positive number")
+	}
+
+	if c.Deterministic && c.MaxBatchSize > 1 {
+		return fmt.Errorf("invalid MaxBatchSize: batching is not allowed in deterministic mode")
+	}
+
+	for i, cpus := range c.SessionCPUs {
+		if err := validateCPUs(cpus); err != nil {
+			return fmt.Errorf("invalid SessionCPUs[%d]: %w", i, err)
+		}
+	}
+
+	if c.HighPassCutoffHz < 0 || c.HighPassCutoffHz >= float64(c.SampleRate)/2 {
+		return fmt.Errorf("invalid HighPassCutoffHz: should be in range [0, SampleRate/2)")
+	}
+
+	if c.Denoiser != nil {
+		if err := c.Denoiser.IsValid(); err != nil {
+			return err
+		}
+	}
+
+	if c.Classifier != nil {
+		if err := c.Classifier.IsValid(); err != nil {
+			return err
+		}
+	}
+
+	if c.MaxClippedRun < 0 {
+		return fmt.Errorf("invalid MaxClippedRun: should not be negative")
+	}
+
+	if err := c.Calibration.IsValid(); err != nil {
+		return err
+	}
+
+	if c.DutyCycle != nil {
+		if err := c.DutyCycle.IsValid(); err != nil {
+			return err
+		}
+	}
+
+	if err := c.ExecutionProvider.IsValid(); err != nil {
+		return err
+	}
+
+	if c.InputCheck < InputCheckNone || c.InputCheck > InputCheckSanitize {
+		return fmt.Errorf("invalid InputCheck: %d", c.InputCheck)
+	}
+
+	if c.AGC != nil {
+		if err := c.AGC.IsValid(); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -98,15 +262,18 @@ type Detector struct {
 	currSample int
 	triggered  bool
 	tempEnd    int
+
+	pre preprocessor
 }
 
 func NewDetector(cfg DetectorConfig) (*Detector, error) {
+	cfg.resolveSampleRate()
 	if err := cfg.IsValid(); err != nil {
-		return nil, fmt.Errorf("invalid config: %w", err)
+		return nil, fmt.Errorf("%w: %w", ErrInvalidConfig, err)
 	}
 
 	sd := Detector{
-		cfg:      cfg,
+		cfg:      cfg.clone(),
 		cStrings: map[string]*C.char{},
 	}
 
@@ -115,56 +282,91 @@ func NewDetector(cfg DetectorConfig) (*Detector, error) {
 		return nil, fmt.Errorf("failed to get API")
 	}
 
+	// Release whatever was already created if initialization fails, so a bad
+	// ModelPath does not show up as a permanent leak in LiveNativeAllocations.
+	created := false
+	defer func() {
+		if !created {
+			sd.releaseNative()
+		}
+	}()
+
 	sd.cStrings["loggerName"] = C.CString("vad")
+	trackAlloc(nativeCString, 1)
 	status := C.OrtApiCreateEnv(sd.api, cfg.LogLevel.OrtLoggingLevel(), sd.cStrings["loggerName"], &sd.env)
 	defer C.OrtApiReleaseStatus(sd.api, status)
 	if status != nil {
-		return nil, fmt.Errorf("failed to create env: %s", C.GoString(C.OrtApiGetErrorMessage(sd.api, status)))
+		return nil, fmt.Errorf("failed to create env: %w", newOrtError(sd.api, status))
 	}
+	trackAlloc(nativeEnv, 1)
 
 	status = C.OrtApiCreateSessionOptions(sd.api, &sd.sessionOpts)
 	defer C.OrtApiReleaseStatus(sd.api, status)
 	if status != nil {
-		return nil, fmt.Errorf("failed to create session options: %s", C.GoString(C.OrtApiGetErrorMessage(sd.api, status)))
+		return nil, fmt.Errorf("failed to create session options: %w", newOrtError(sd.api, status))
 	}
+	trackAlloc(nativeSessionOptions, 1)
 
 	status = C.OrtApiSetIntraOpNumThreads(sd.api, sd.sessionOpts, 1)
 	defer C.OrtApiReleaseStatus(sd.api, status)
 	if status != nil {
-		return nil, fmt.Errorf("failed to set intra threads: %s", C.GoString(C.OrtApiGetErrorMessage(sd.api, status)))
+		return nil, fmt.Errorf("failed to set intra threads: %w", newOrtError(sd.api, status))
 	}
 
 	status = C.OrtApiSetInterOpNumThreads(sd.api, sd.sessionOpts, 1)
 	defer C.OrtApiReleaseStatus(sd.api, status)
 	if status != nil {
-		return nil, fmt.Errorf("failed to set inter threads: %s", C.GoString(C.OrtApiGetErrorMessage(sd.api, status)))
+		return nil, fmt.Errorf("failed to set inter threads: %w", newOrtError(sd.api, status))
 	}
 
-	status = C.OrtApiSetSessionGraphOptimizationLevel(sd.api, sd.sessionOpts, C.ORT_ENABLE_ALL)
+	status = C.OrtApiSetSessionGraphOptimizationLevel(sd.api, sd.sessionOpts, graphOptimizationLevel(cfg))
 	defer C.OrtApiReleaseStatus(sd.api, status)
 	if status != nil {
-		return nil, fmt.Errorf("failed to set session graph optimization level: %s", C.GoString(C.OrtApiGetErrorMessage(sd.api, status)))
+		return nil, fmt.Errorf("failed to set session graph optimization level: %w", newOrtError(sd.api, status))
+	}
+
+	if cfg.Deterministic {
+		if err := setDeterministic(sd.api, sd.sessionOpts); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := appendExecutionProvider(sd.api, sd.sessionOpts, cfg.ExecutionProvider); err != nil {
+		return nil, err
 	}
 
 	sd.cStrings["modelPath"] = C.CString(sd.cfg.ModelPath)
+	trackAlloc(nativeCString, 1)
 	status = C.OrtApiCreateSession(sd.api, sd.env, sd.cStrings["modelPath"], sd.sessionOpts, &sd.session)
 	defer C.OrtApiReleaseStatus(sd.api, status)
 	if status != nil {
-		return nil, fmt.Errorf("failed to create session: %s", C.GoString(C.OrtApiGetErrorMessage(sd.api, status)))
+		return nil, fmt.Errorf("%w: failed to create session: %w", ErrModelLoad, newOrtError(sd.api, status))
 	}
+	trackAlloc(nativeSession, 1)
 
 	status = C.OrtApiCreateCpuMemoryInfo(sd.api, C.OrtArenaAllocator, C.OrtMemTypeDefault, &sd.memoryInfo)
 	defer C.OrtApiReleaseStatus(sd.api, status)
 	if status != nil {
-		return nil, fmt.Errorf("failed to create memory info: %s", C.GoString(C.OrtApiGetErrorMessage(sd.api, status)))
+		return nil, fmt.Errorf("failed to create memory info: %w", newOrtError(sd.api, status))
 	}
+	trackAlloc(nativeMemoryInfo, 1)
 
 	sd.cStrings["input"] = C.CString("input")
 	sd.cStrings["sr"] = C.CString("sr")
 	sd.cStrings["state"] = C.CString("state")
 	sd.cStrings["stateN"] = C.CString("stateN")
 	sd.cStrings["output"] = C.CString("output")
+	trackAlloc(nativeCString, 5)
 
+	// Safety net for callers that forget to call Destroy: native memory is
+	// invisible to the Go GC, so leaked detectors would otherwise only show up
+	// once the process runs out of memory.
+	runtime.SetFinalizer(&sd, func(sd *Detector) {
+		slog.Warn("detector was garbage collected without calling Destroy, releasing native resources")
+		_ = sd.Destroy()
+	})
+
+	created = true
 	return &sd, nil
 }
 
@@ -174,6 +376,23 @@ type Segment struct {
 	SpeechStartAt float64
 	// The relative timestamp in seconds of when a speech segment ends.
 	SpeechEndAt float64
+	// The estimated signal-to-noise ratio of the segment in dB: the mean power
+	// of its speech windows over the recent power of the silence around it.
+	// Set when the segment ends if DetectorConfig.EstimateSNR is enabled,
+	// zero otherwise.
+	SNR float64
+	// The integrated loudness of the segment in LUFS (ITU-R BS.1770 K-weighting
+	// and gating over ~400ms blocks), not lower than -70. Segments shorter than
+	// one block are measured without gating. Set when the segment ends if
+	// DetectorConfig.MeasureLoudness is enabled, zero otherwise.
+	Loudness float64
+	// The RMS level of the segment in dBFS, not lower than -100. Set together
+	// with Loudness.
+	RMS float64
+	// Whether the input clipped for more than DetectorConfig.MaxClippedRun
+	// consecutive samples while the segment was active. Set when the segment
+	// ends.
+	Clipped bool
 }
 
 func (sd *Detector) Detect(pcm []float32) ([]Segment, error) {
@@ -186,8 +405,21 @@ func (sd *Detector) Detect(pcm []float32) ([]Segment, error) {
 		windowSize = 256
 	}
 
+	pcm, err := sd.pre.resample(&sd.cfg, pcm)
+	if err != nil {
+		return nil, err
+	}
+
 	if len(pcm) < windowSize {
-		return nil, fmt.Errorf("not enough samples")
+		if !sd.cfg.PadShortInput {
+			return nil, notEnoughSamples(len(pcm), windowSize)
+		}
+		pcm = padShortInput(nil, pcm, windowSize)
+	}
+
+	pcm, err = sd.pre.apply(&sd.cfg, pcm)
+	if err != nil {
+		return nil, err
 	}
 
 	slog.Debug("starting speech detection", slog.Int("samplesLen", len(pcm)))
@@ -239,7 +471,7 @@ func (sd *Detector) Detect(pcm []float32) ([]Segment, error) {
 			slog.Debug("speech end", slog.Float64("endAt", speechEndAt))
 
 			if len(segments) < 1 {
-				return nil, fmt.Errorf("unexpected speech end")
+				return nil, ErrUnexpectedSpeechEnd
 			}
 
 			segments[len(segments)-1].SpeechEndAt = speechEndAt
@@ -259,6 +491,7 @@ func (sd *Detector) Reset() error {
 	sd.currSample = 0
 	sd.triggered = false
 	sd.tempEnd = 0
+	sd.pre.reset()
 	for i := 0; i < stateLen; i++ {
 		sd.state[i] = 0
 	}
@@ -269,6 +502,13 @@ func (sd *Detector) Reset() error {
 	return nil
 }
 
+// SetPreprocessors sets the processing stages (e.g. audio.NoiseGate) run on
+// the input before inference, after the optional high-pass filter. Stages
+// keep streaming state across calls and are cleared by Reset.
+func (sd *Detector) SetPreprocessors(stages ...audio.Processor) {
+	sd.pre.stages = stages
+}
+
 func (sd *Detector) SetThreshold(value float32) {
 	sd.cfg.Threshold = value
 }
@@ -278,13 +518,33 @@ func (sd *Detector) Destroy() error {
 		return fmt.Errorf("invalid nil detector")
 	}
 
-	C.OrtApiReleaseMemoryInfo(sd.api, sd.memoryInfo)
-	C.OrtApiReleaseSession(sd.api, sd.session)
-	C.OrtApiReleaseSessionOptions(sd.api, sd.sessionOpts)
-	C.OrtApiReleaseEnv(sd.api, sd.env)
+	runtime.SetFinalizer(sd, nil)
+	sd.releaseNative()
+
+	return nil
+}
+
+// releaseNative frees the native resources created so far. It is also used by
+// NewDetector to clean up after a failed initialization.
+func (sd *Detector) releaseNative() {
+	if sd.memoryInfo != nil {
+		C.OrtApiReleaseMemoryInfo(sd.api, sd.memoryInfo)
+		trackFree(nativeMemoryInfo, 1)
+	}
+	if sd.session != nil {
+		C.OrtApiReleaseSession(sd.api, sd.session)
+		trackFree(nativeSession, 1)
+	}
+	if sd.sessionOpts != nil {
+		C.OrtApiReleaseSessionOptions(sd.api, sd.sessionOpts)
+		trackFree(nativeSessionOptions, 1)
+	}
+	if sd.env != nil {
+		C.OrtApiReleaseEnv(sd.api, sd.env)
+		trackFree(nativeEnv, 1)
+	}
 	for _, ptr := range sd.cStrings {
 		C.free(unsafe.Pointer(ptr))
 	}
-
-	return nil
+	trackFree(nativeCString, len(sd.cStrings))
 }
@@ -7,6 +7,7 @@ import (
 	"os"
 	"testing"
 
+	"github.com/rui-yang-me/silero-vad-go/audio"
 	"github.com/stretchr/testify/require"
 )
 
@@ -60,6 +61,36 @@ func TestDetectorConfigIsValid(t *testing.T) {
 			},
 			err: "invalid SpeechPadMs: should be a positive number",
 		},
+		{
+			name: "invalid SessionPoolSize",
+			cfg: DetectorConfig{
+				ModelPath:       "../testfiles/silero_vad.onnx",
+				SampleRate:      16000,
+				Threshold:       0.5,
+				SessionPoolSize: -1,
+			},
+			err: "invalid SessionPoolSize: should be a positive number",
+		},
+		{
+			name: "invalid HighPassCutoffHz",
+			cfg: DetectorConfig{
+				ModelPath:        "../testfiles/silero_vad.onnx",
+				SampleRate:       8000,
+				Threshold:        0.5,
+				HighPassCutoffHz: 4000,
+			},
+			err: "invalid HighPassCutoffHz: should be in range [0, SampleRate/2)",
+		},
+		{
+			name: "invalid AGC",
+			cfg: DetectorConfig{
+				ModelPath:  "../testfiles/silero_vad.onnx",
+				SampleRate: 16000,
+				Threshold:  0.5,
+				AGC:        &audio.AGCConfig{TargetDB: 3},
+			},
+			err: "invalid AGC.TargetDB: should be negative",
+		},
 		{
 			name: "valid",
 			cfg: DetectorConfig{
@@ -0,0 +1,48 @@
+package speech
+
+// #cgo CFLAGS: -Wall -Werror -std=c99
+// #cgo LDFLAGS: -lonnxruntime
+// #include <stdlib.h>
+// #include "ort_bridge.h"
+import "C"
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+// deterministicConfigEntries 确定性模式下固定的会话配置项，键值见 onnxruntime_session_options_config_keys.h
+var deterministicConfigEntries = [][2]string{
+	// 不把非规格化数冲刷为 0，结果不依赖 CPU 的 FTZ/DAZ 设置
+	{"session.set_denormal_as_zero", "0"},
+	// 不预先按 CPU 指令集重排权重，各机器使用相同的权重布局
+	{"session.disable_prepacking", "1"},
+}
+
+// graphOptimizationLevel 返回会话的图优化级别
+// 确定性模式只做与硬件无关的常量折叠和冗余节点消除，不做算子融合和布局变换，
+// 后两者会按 CPU 指令集选择不同的融合内核，使不同机器的概率出现微小差异。
+func graphOptimizationLevel(cfg DetectorConfig) C.GraphOptimizationLevel {
+	if cfg.Deterministic {
+		return C.ORT_ENABLE_BASIC
+	}
+	return C.ORT_ENABLE_ALL
+}
+
+// setDeterministic 在会话选项上固定影响复现的配置项
+// 线程数在所有模式下都固定为 1，CPU 上单线程推理的内核本身是确定的，不需要在这里设置。
+// 不调用 SetDeterministicCompute：它需要 ORT 1.17，且只影响 CUDA 等执行提供者的内核选择。
+func setDeterministic(api *C.OrtApi, opts *C.OrtSessionOptions) error {
+	for _, entry := range deterministicConfigEntries {
+		key, value := C.CString(entry[0]), C.CString(entry[1])
+		status := C.OrtApiAddSessionConfigEntry(api, opts, key, value)
+		C.free(unsafe.Pointer(key))
+		C.free(unsafe.Pointer(value))
+		if status != nil {
+			err := newOrtError(api, status)
+			C.OrtApiReleaseStatus(api, status)
+			return fmt.Errorf("failed to set session config %s: %w", entry[0], err)
+		}
+	}
+	return nil
+}
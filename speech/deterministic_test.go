@@ -0,0 +1,77 @@
+package speech
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeterministic(t *testing.T) {
+	samples := readTestSamples(t, "../testfiles/samples.pcm")
+	cfg := DetectorConfig{
+		ModelPath:     "../testfiles/silero_vad.onnx",
+		SampleRate:    16000,
+		Threshold:     0.5,
+		Deterministic: true,
+	}
+
+	batched := cfg
+	batched.MaxBatchSize = 4
+	batched.MaxBatchDelay = time.Millisecond
+	require.Error(t, batched.IsValid())
+
+	// 两个独立的模型逐窗口得到完全相同的概率
+	run := func() ([]float32, []Segment) {
+		sm, err := NewSharedModel(cfg)
+		require.NoError(t, err)
+		defer func() {
+			require.NoError(t, sm.Destroy())
+		}()
+
+		dc := sm.NewContext()
+		defer dc.Close()
+		var probs []float32
+		dc.SetWindowObserver(func(r WindowResult) {
+			probs = append(probs, r.Probability)
+		})
+		segments, err := dc.Detect(samples)
+		require.NoError(t, err)
+
+		// 预算不足时也不降级
+		require.NoError(t, dc.Reset())
+		dc.SetWindowObserver(nil)
+		res, err := dc.DetectWithBudget(samples, time.Nanosecond)
+		require.NoError(t, err)
+		require.False(t, res.Degraded)
+		require.Equal(t, 1, res.Stride)
+		require.Equal(t, segments, res.Segments)
+
+		require.Error(t, dc.WithConfig(func(cfg *DetectorConfig) {
+			cfg.Deterministic = false
+		}))
+		return probs, segments
+	}
+	probs1, segments1 := run()
+	probs2, segments2 := run()
+	require.Equal(t, probs1, probs2)
+	require.Equal(t, segments1, segments2)
+
+	// 与默认模式的结果一致
+	expected, err := newTestSharedModel(t).NewContext().Detect(samples)
+	require.NoError(t, err)
+	require.Len(t, segments1, len(expected))
+	for i := range expected {
+		require.InDelta(t, expected[i].SpeechStartAt, segments1[i].SpeechStartAt, 0.001)
+		require.InDelta(t, expected[i].SpeechEndAt, segments1[i].SpeechEndAt, 0.001)
+	}
+
+	sd, err := NewDetector(cfg)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, sd.Destroy())
+	}()
+	segments, err := sd.Detect(samples)
+	require.NoError(t, err)
+	require.Len(t, segments, len(segments1))
+}
@@ -0,0 +1,184 @@
+package speech
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// defaultWakeAbove 未设置 DutyCycleConfig.WakeAbove 时唤醒模型的能量语音概率
+	defaultWakeAbove = 0.3
+	// defaultLookback 未设置 DutyCycleConfig.Lookback 时唤醒后预热模型的音频时长
+	defaultLookback = 192 * time.Millisecond
+	// defaultHangover 未设置 DutyCycleConfig.Hangover 时能量回落后保持唤醒的时长
+	defaultHangover = 500 * time.Millisecond
+)
+
+// DutyCycleConfig 常开设备的低功耗模式：持续运行廉价的能量检测，只在能量活动之后唤醒模型
+// 唤醒与休眠之间有滞回：能量语音概率达到 WakeAbove 时唤醒，先用之前 Lookback 时长的音频预热模型，
+// 以免切掉语音的开头；之后只要仍在语音片段中或能量仍有活动就保持唤醒，
+// 能量回落并持续 Hangover 且不在片段中时才再次休眠。休眠期间的窗口按静音处理。
+type DutyCycleConfig struct {
+	// 能量检测的参数，其中 SampleRate、Threshold、MinSilenceDurationMs、SpeechPadMs 取自检测配置
+	Energy EnergyConfig `json:"energy" yaml:"energy"`
+	// 唤醒模型所需的能量语音概率，取值 [0, 1)，0 表示默认 0.3
+	WakeAbove float32 `json:"wake_above" yaml:"wake_above"`
+	// 唤醒时用于预热模型的之前的音频时长，0 表示默认 192ms
+	Lookback time.Duration `json:"lookback" yaml:"lookback"`
+	// 能量回落后保持唤醒的时长，0 表示默认 500ms
+	Hangover time.Duration `json:"hangover" yaml:"hangover"`
+}
+
+// IsValid 校验低功耗模式配置
+func (c *DutyCycleConfig) IsValid() error {
+	if c.WakeAbove < 0 || c.WakeAbove >= 1 {
+		return fmt.Errorf("invalid DutyCycle.WakeAbove: should be in range [0, 1)")
+	}
+	if c.Lookback < 0 || c.Hangover < 0 {
+		return fmt.Errorf("invalid DutyCycle durations: should not be negative")
+	}
+	energy := c.Energy
+	energy.SampleRate = 16000
+	energy.Threshold = 0
+	if err := energy.IsValid(); err != nil {
+		return fmt.Errorf("invalid DutyCycle.Energy: %w", err)
+	}
+	return nil
+}
+
+// DutyCycleStats 低功耗模式的统计
+type DutyCycleStats struct {
+	// 检测过的窗口数
+	Windows int64
+	// 调用了模型的窗口数，包括唤醒时预热的窗口
+	ModelWindows int64
+	// 唤醒次数
+	Wakeups int64
+}
+
+// Ratio 返回调用模型的窗口占比，即占空比，尚未检测时为 0
+func (s DutyCycleStats) Ratio() float64 {
+	if s.Windows == 0 {
+		return 0
+	}
+	return float64(s.ModelWindows) / float64(s.Windows)
+}
+
+// dutyCycler 是每个上下文独立的低功耗模式状态
+type dutyCycler struct {
+	cfg      *DutyCycleConfig // 创建时的配置，配置被替换后重新创建
+	energy   *EnergyDetector
+	wake     float32
+	hangover int
+	lookback int
+	history  []float32 // 休眠期间最近 lookback 个采样
+	awake    bool
+	quiet    int // 唤醒后能量持续低于 WakeAbove 的采样数
+	stats    DutyCycleStats
+}
+
+// dutyCycle 返回与当前配置一致的低功耗模式状态
+func (dc *DetectorContext) dutyCycle(cfg *DetectorConfig) (*dutyCycler, error) {
+	if dc.duty != nil && dc.duty.cfg == cfg.DutyCycle {
+		return dc.duty, nil
+	}
+
+	dcfg := cfg.DutyCycle
+	energyCfg := dcfg.Energy
+	energyCfg.SampleRate = cfg.SampleRate
+	energyCfg.Threshold = cfg.Threshold
+	energyCfg.MinSilenceDurationMs = cfg.MinSilenceDurationMs
+	energyCfg.SpeechPadMs = cfg.SpeechPadMs
+	energy, err := NewEnergyDetector(energyCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &dutyCycler{
+		cfg:      dcfg,
+		energy:   energy,
+		wake:     dcfg.WakeAbove,
+		hangover: int(dcfg.Hangover.Seconds() * float64(cfg.SampleRate)),
+		lookback: int(dcfg.Lookback.Seconds() * float64(cfg.SampleRate)),
+	}
+	if d.wake == 0 {
+		d.wake = defaultWakeAbove
+	}
+	if dcfg.Hangover == 0 {
+		d.hangover = int(defaultHangover.Seconds() * float64(cfg.SampleRate))
+	}
+	if dcfg.Lookback == 0 {
+		d.lookback = int(defaultLookback.Seconds() * float64(cfg.SampleRate))
+	}
+	if dc.duty != nil {
+		d.stats = dc.duty.stats
+	}
+	dc.duty = d
+	return d, nil
+}
+
+// gate 判断一个窗口是否需要调用模型；从休眠中唤醒时清空模型状态并用之前的音频预热
+func (dc *DetectorContext) gate(cfg *DetectorConfig, window []float32) (bool, error) {
+	d, err := dc.dutyCycle(cfg)
+	if err != nil {
+		return false, err
+	}
+	d.stats.Windows++
+	active := d.energy.probability(window) >= d.wake
+
+	if d.awake {
+		if active || dc.triggered {
+			d.quiet = 0
+		} else {
+			d.quiet += len(window)
+		}
+		if d.quiet < d.hangover {
+			d.stats.ModelWindows++
+			return true, nil
+		}
+		d.awake = false
+		d.history = d.history[:0]
+	}
+
+	if !active {
+		d.history = append(d.history, window...)
+		if over := len(d.history) - d.lookback; over > 0 {
+			n := copy(d.history, d.history[over:])
+			d.history = d.history[:n]
+		}
+		return false, nil
+	}
+
+	d.awake = true
+	d.quiet = 0
+	d.stats.Wakeups++
+	clear(dc.state[:])
+	clear(dc.ctx[:])
+	windowSize := len(window)
+	for i := len(d.history) % windowSize; i+windowSize <= len(d.history); i += windowSize {
+		if _, err := dc.inferWindow(d.history[i : i+windowSize]); err != nil {
+			return false, fmt.Errorf("infer failed: %w", err)
+		}
+		d.stats.ModelWindows++
+	}
+	d.history = d.history[:0]
+	d.stats.ModelWindows++
+	return true, nil
+}
+
+// reset 回到休眠状态并清空统计，噪声基底重新估计
+func (d *dutyCycler) reset() {
+	_ = d.energy.Reset()
+	d.history = d.history[:0]
+	d.awake = false
+	d.quiet = 0
+	d.stats = DutyCycleStats{}
+}
+
+// DutyCycle 返回该上下文低功耗模式自创建或上次 Reset 以来的统计，未配置 DutyCycle 时为零值
+func (dc *DetectorContext) DutyCycle() DutyCycleStats {
+	if dc == nil || dc.duty == nil {
+		return DutyCycleStats{}
+	}
+	return dc.duty.stats
+}
@@ -0,0 +1,72 @@
+package speech
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDutyCycle(t *testing.T) {
+	sm := newTestSharedModel(t)
+	samples := readTestSamples(t, "../testfiles/samples.pcm")
+
+	dc := sm.NewContext()
+	defer dc.Close()
+	expected, err := dc.Detect(samples)
+	require.NoError(t, err)
+	require.NotEmpty(t, expected)
+	require.Zero(t, dc.DutyCycle())
+
+	for _, bad := range []DutyCycleConfig{
+		{WakeAbove: 1},
+		{Lookback: -1},
+		{Energy: EnergyConfig{MarginDB: -1}},
+	} {
+		bad := bad
+		require.Error(t, dc.WithConfig(func(cfg *DetectorConfig) {
+			cfg.DutyCycle = &bad
+		}))
+	}
+
+	require.NoError(t, dc.WithConfig(func(cfg *DetectorConfig) {
+		cfg.DutyCycle = &DutyCycleConfig{}
+	}))
+	require.NoError(t, dc.Reset())
+
+	// 片段与一直运行模型时基本一致，静音期间模型在休眠
+	segments, err := dc.Detect(samples)
+	require.NoError(t, err)
+	require.Len(t, segments, len(expected))
+	for i := range segments {
+		require.InDelta(t, expected[i].SpeechStartAt, segments[i].SpeechStartAt, 0.07)
+		require.InDelta(t, expected[i].SpeechEndAt, segments[i].SpeechEndAt, 0.07)
+	}
+	stats := dc.DutyCycle()
+	require.Equal(t, int64(len(samples)/512), stats.Windows)
+	require.Less(t, stats.ModelWindows, stats.Windows)
+	require.Positive(t, stats.Wakeups)
+	require.Greater(t, stats.Ratio(), 0.0)
+	require.Less(t, stats.Ratio(), 1.0)
+
+	// 流式检测与整段检测一致
+	require.NoError(t, dc.Reset())
+	require.Zero(t, dc.DutyCycle().Windows)
+	chunker, err := NewStreamChunker(16000)
+	require.NoError(t, err)
+	var streamed []Segment
+	for off := 0; off < len(samples); off += 320 {
+		chunker.Write(samples[off:min(off+320, len(samples))])
+		segs, err := dc.DetectChunks(chunker)
+		require.NoError(t, err)
+		streamed = mergeSegments(streamed, segs)
+	}
+	require.Equal(t, segments, streamed)
+
+	// 静音期间从不唤醒模型
+	require.NoError(t, dc.Reset())
+	silence, err := dc.Detect(make([]float32, 16000))
+	require.NoError(t, err)
+	require.Empty(t, silence)
+	require.Zero(t, dc.DutyCycle().ModelWindows)
+	require.Zero(t, dc.DutyCycle().Ratio())
+}
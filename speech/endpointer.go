@@ -0,0 +1,217 @@
+package speech
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// defaultEndpointMinSpeech 未设置 EndpointerConfig.MinSpeech 时确认开始说话所需的连续语音时长
+	defaultEndpointMinSpeech = 250 * time.Millisecond
+	// defaultTrailingSilence 未设置 EndpointerConfig.TrailingSilence 时判定说完所需的静音时长
+	defaultTrailingSilence = 700 * time.Millisecond
+)
+
+// EndpointState Endpointer 的状态
+type EndpointState int
+
+const (
+	// EndpointListening 等待用户开始说话
+	EndpointListening EndpointState = iota
+	// EndpointSpeechActive 用户正在说话，包括句中不超过 TrailingSilence 的停顿
+	EndpointSpeechActive
+	// EndpointEndOfUtterance 已判定这一轮说完，调用 Reset 之前不再处理音频
+	EndpointEndOfUtterance
+)
+
+// String 返回状态的名称
+func (s EndpointState) String() string {
+	switch s {
+	case EndpointListening:
+		return "listening"
+	case EndpointSpeechActive:
+		return "speech_active"
+	case EndpointEndOfUtterance:
+		return "end_of_utterance"
+	default:
+		return "unknown"
+	}
+}
+
+// EndpointerConfig Endpointer 的配置
+type EndpointerConfig struct {
+	// 连续多长的语音才确认用户开始说话，短于它的咳嗽、噪声不会开始一轮，0 表示默认 250ms
+	MinSpeech time.Duration
+	// 说话后持续多长的静音判定为说完，0 表示默认 700ms；
+	// 比 MinSilenceDurationMs 更长，以免把句中的停顿当作一轮结束
+	TrailingSilence time.Duration
+	// 一轮最长的时长，达到后立即结束并标记为截断，0 表示不限制
+	MaxUtterance time.Duration
+}
+
+// Utterance Endpointer 判定的一轮说话
+type Utterance struct {
+	// 开始和结束时间，相对音频开始的秒数，结束时间不包括判定用的尾部静音
+	Start float64
+	End   float64
+	// 因达到 MaxUtterance 而结束
+	Truncated bool
+}
+
+// Endpointer 面向对话系统的端点检测，每一轮只给出一次说完的判定，而不是逐个返回语音片段
+// 状态从 EndpointListening 开始，确认开始说话后进入 EndpointSpeechActive，
+// 尾部静音达到 TrailingSilence 或时长达到 MaxUtterance 时进入 EndpointEndOfUtterance。
+// 判定使用上下文配置的 Threshold，语音概率低于 Threshold-0.15 的窗口计为静音，与 Detect 一致；
+// MinSilenceDurationMs 和 SpeechPadMs 不参与判定。Endpointer 不是并发安全的。
+type Endpointer struct {
+	dc      *DetectorContext
+	chunker *StreamChunker
+
+	minSpeech int // 以下三个阈值均为采样数，maxLen 为 0 表示不限制
+	trailing  int
+	maxLen    int
+
+	state   EndpointState
+	run     int // EndpointListening 下连续语音的采样数
+	start   int // 本轮开始的采样位置
+	silence int // EndpointSpeechActive 下连续静音的采样数
+	result  Utterance
+}
+
+// NewEndpointer 创建基于 dc 的端点检测器，音频格式约定同 NewStream
+// Endpointer 使用期间不要再直接调用 dc 的检测方法。
+func (dc *DetectorContext) NewEndpointer(cfg EndpointerConfig) (*Endpointer, error) {
+	if dc == nil || dc.model == nil {
+		return nil, fmt.Errorf("invalid nil detector context")
+	}
+	if cfg.MinSpeech < 0 || cfg.TrailingSilence < 0 || cfg.MaxUtterance < 0 {
+		return nil, fmt.Errorf("invalid endpointer durations: should not be negative")
+	}
+	if cfg.MinSpeech == 0 {
+		cfg.MinSpeech = defaultEndpointMinSpeech
+	}
+	if cfg.TrailingSilence == 0 {
+		cfg.TrailingSilence = defaultTrailingSilence
+	}
+	if cfg.MaxUtterance > 0 && cfg.MaxUtterance < cfg.MinSpeech {
+		return nil, fmt.Errorf("invalid max utterance %s: shorter than min speech %s", cfg.MaxUtterance, cfg.MinSpeech)
+	}
+
+	sampleRate := dc.config().SampleRate
+	chunker, err := NewStreamChunker(sampleRate)
+	if err != nil {
+		return nil, err
+	}
+	samples := func(d time.Duration) int {
+		return int(d.Seconds() * float64(sampleRate))
+	}
+
+	return &Endpointer{
+		dc:        dc,
+		chunker:   chunker,
+		minSpeech: samples(cfg.MinSpeech),
+		trailing:  samples(cfg.TrailingSilence),
+		maxLen:    samples(cfg.MaxUtterance),
+	}, nil
+}
+
+// Write 检测一块音频，判定这一轮说完时返回 true 和这一轮的 Utterance
+// 判定之后本次传入的剩余音频和之后的音频都不再检测，直到调用 Reset；
+// 这些音频仍计入检测位置，之后各轮的时间戳与实际音频对齐。
+func (e *Endpointer) Write(pcm []float32) (Utterance, bool, error) {
+	dc := e.dc
+	if err := dc.acquire(); err != nil {
+		return Utterance{}, false, err
+	}
+	defer dc.release()
+
+	cfg := dc.callConfig()
+	pcm, err := dc.pre.resample(cfg, pcm)
+	if err != nil {
+		return Utterance{}, false, err
+	}
+	if e.state == EndpointEndOfUtterance {
+		dc.currSample += len(pcm)
+		return Utterance{}, false, nil
+	}
+	e.chunker.Write(pcm)
+
+	windowSize := e.chunker.WindowSize()
+	for {
+		frame, ok := e.chunker.Next()
+		if !ok {
+			return Utterance{}, false, nil
+		}
+		// 与 detectFrame 相同，预处理可能一次输出零个或多个窗口
+		out, err := dc.pre.apply(cfg, frame)
+		if err != nil {
+			return Utterance{}, false, err
+		}
+		for i := 0; i+windowSize <= len(out); i += windowSize {
+			prob, err := dc.predict(cfg, out[i:i+windowSize])
+			if err != nil {
+				return Utterance{}, false, fmt.Errorf("infer failed: %w", err)
+			}
+			dc.currSample += windowSize
+			if e.advance(cfg, prob, windowSize) {
+				dc.currSample += len(out) - i - windowSize + e.chunker.Buffered()
+				e.chunker.Reset()
+				return e.result, true, nil
+			}
+		}
+	}
+}
+
+// advance 以一个窗口的语音概率推进状态，进入 EndpointEndOfUtterance 时返回 true
+func (e *Endpointer) advance(cfg *DetectorConfig, prob float32, windowSize int) bool {
+	rate := float64(cfg.SampleRate)
+	pos := e.dc.currSample
+
+	switch e.state {
+	case EndpointListening:
+		if prob < cfg.Threshold {
+			e.run = 0
+			return false
+		}
+		if e.run == 0 {
+			e.start = pos - windowSize
+		}
+		e.run += windowSize
+		if e.run >= e.minSpeech {
+			e.state = EndpointSpeechActive
+			e.silence = 0
+		}
+
+	case EndpointSpeechActive:
+		if prob >= cfg.Threshold {
+			e.silence = 0
+		} else if prob < cfg.Threshold-0.15 {
+			e.silence += windowSize
+		}
+		switch {
+		case e.silence >= e.trailing:
+			e.result = Utterance{Start: float64(e.start) / rate, End: float64(pos-e.silence) / rate}
+		case e.maxLen > 0 && pos-e.start >= e.maxLen:
+			e.result = Utterance{Start: float64(e.start) / rate, End: float64(pos) / rate, Truncated: true}
+		default:
+			return false
+		}
+		e.state = EndpointEndOfUtterance
+		return true
+	}
+	return false
+}
+
+// State 返回当前状态
+func (e *Endpointer) State() EndpointState {
+	return e.state
+}
+
+// Reset 回到 EndpointListening 开始下一轮
+// 检测状态和时间戳在轮与轮之间连续；开始一路新的音频时还应调用上下文的 Reset。
+func (e *Endpointer) Reset() {
+	e.state = EndpointListening
+	e.run = 0
+	e.silence = 0
+	e.result = Utterance{}
+}
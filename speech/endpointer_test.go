@@ -0,0 +1,72 @@
+package speech
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEndpointer(t *testing.T) {
+	sm := newTestSharedModel(t)
+	samples := readTestSamples(t, "../testfiles/samples.pcm")
+
+	dc := sm.NewContext()
+	defer dc.Close()
+	expected, err := dc.Detect(samples)
+	require.NoError(t, err)
+	require.NotEmpty(t, expected)
+	require.NoError(t, dc.Reset())
+
+	_, err = dc.NewEndpointer(EndpointerConfig{TrailingSilence: -time.Second})
+	require.Error(t, err)
+	_, err = dc.NewEndpointer(EndpointerConfig{MinSpeech: time.Second, MaxUtterance: 500 * time.Millisecond})
+	require.Error(t, err)
+
+	// 按 20ms 一块写入，收集各轮的判定
+	run := func(e *Endpointer) []Utterance {
+		var turns []Utterance
+		for off := 0; off < len(samples); off += 320 {
+			u, done, err := e.Write(samples[off:min(off+320, len(samples))])
+			require.NoError(t, err)
+			if done {
+				require.Equal(t, EndpointEndOfUtterance, e.State())
+				turns = append(turns, u)
+				e.Reset()
+				require.Equal(t, EndpointListening, e.State())
+			}
+		}
+		return turns
+	}
+
+	e, err := dc.NewEndpointer(EndpointerConfig{TrailingSilence: 300 * time.Millisecond})
+	require.NoError(t, err)
+	require.Equal(t, EndpointListening, e.State())
+	turns := run(e)
+	require.NotEmpty(t, turns)
+	require.InDelta(t, expected[0].SpeechStartAt, turns[0].Start, 0.05)
+	for i, u := range turns {
+		require.False(t, u.Truncated)
+		require.Greater(t, u.End, u.Start)
+		if i > 0 {
+			require.Greater(t, u.Start, turns[i-1].End)
+		}
+	}
+	// 忽略的音频仍计入检测位置
+	require.Equal(t, len(samples), dc.currSample+e.chunker.Buffered())
+
+	// 更长的尾部静音把句中停顿合并为一轮
+	require.NoError(t, dc.Reset())
+	e, err = dc.NewEndpointer(EndpointerConfig{TrailingSilence: 2 * time.Second})
+	require.NoError(t, err)
+	require.LessOrEqual(t, len(run(e)), len(turns))
+
+	// 达到最长时长时截断
+	require.NoError(t, dc.Reset())
+	e, err = dc.NewEndpointer(EndpointerConfig{MaxUtterance: 500 * time.Millisecond, TrailingSilence: 10 * time.Second})
+	require.NoError(t, err)
+	truncated := run(e)
+	require.NotEmpty(t, truncated)
+	require.True(t, truncated[0].Truncated)
+	require.InDelta(t, 0.5, truncated[0].End-truncated[0].Start, 0.04)
+}
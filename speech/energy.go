@@ -0,0 +1,291 @@
+package speech
+
+import (
+	"fmt"
+	"math"
+)
+
+const (
+	// defaultEnergyMarginDB 未设置 EnergyConfig.MarginDB 时语音概率为 0.5 的窗口能量高于噪声基底的分贝数
+	defaultEnergyMarginDB = 10
+	// defaultEnergyMinDB 未设置 EnergyConfig.MinEnergyDB 时视为静音的窗口能量上限
+	defaultEnergyMinDB = -55
+	// defaultMaxZeroCrossingRate 未设置 EnergyConfig.MaxZeroCrossingRate 时宽带噪声的过零率下限
+	defaultMaxZeroCrossingRate = 0.35
+	// energySlopeDB 语音概率从 0.27 变化到 0.73 所跨越的分贝数
+	energySlopeDB = 3
+	// noiseFloorRiseSeconds 噪声基底向更高能量跟踪的时间常数，向更低能量时立即跟随
+	noiseFloorRiseSeconds = 5
+)
+
+// SegmentDetector 是 DetectorContext 与 EnergyDetector 共有的检测接口
+// 依赖该接口的代码可以在 ONNX 模型不可用时退回能量检测，或者对两种检测做 A/B 比较。
+type SegmentDetector interface {
+	Detect(pcm []float32) ([]Segment, error)
+	DetectChunks(c *StreamChunker) ([]Segment, error)
+	IsSpeech(pcm []float32) (bool, error)
+	Reset() error
+	Close() error
+}
+
+var (
+	_ SegmentDetector = (*DetectorContext)(nil)
+	_ SegmentDetector = (*EnergyDetector)(nil)
+)
+
+// EnergyConfig EnergyDetector 的配置
+// Threshold、MinSilenceDurationMs、SpeechPadMs 的含义与 DetectorConfig 相同。
+type EnergyConfig struct {
+	// 采样率，8000 或 16000，窗口长度与 Silero 模型一致
+	SampleRate int `json:"sample_rate" yaml:"sample_rate"`
+	// 语音概率的阈值，取值 (0, 1)，0 表示默认 0.5
+	Threshold float32 `json:"threshold" yaml:"threshold"`
+	// 判定语音结束所需的静音时长
+	MinSilenceDurationMs int `json:"min_silence_duration_ms" yaml:"min_silence_duration_ms"`
+	// 片段两端的填充时长
+	SpeechPadMs int `json:"speech_pad_ms" yaml:"speech_pad_ms"`
+	// 窗口能量高于噪声基底多少分贝时语音概率为 0.5，0 表示默认 10dB
+	MarginDB float64 `json:"margin_db" yaml:"margin_db"`
+	// 能量低于该值（dBFS）的窗口一律视为静音，0 表示默认 -55dBFS
+	MinEnergyDB float64 `json:"min_energy_db" yaml:"min_energy_db"`
+	// 过零率（每个采样的过零次数）高于该值的窗口视为宽带噪声，只能延续而不能开始片段，
+	// 取值 (0, 1]，0 表示默认 0.35
+	MaxZeroCrossingRate float64 `json:"max_zero_crossing_rate" yaml:"max_zero_crossing_rate"`
+}
+
+// IsValid 校验能量检测配置
+func (c EnergyConfig) IsValid() error {
+	if c.SampleRate != 8000 && c.SampleRate != 16000 {
+		return fmt.Errorf("invalid SampleRate: valid values are 8000 and 16000")
+	}
+
+	if c.Threshold < 0 || c.Threshold >= 1 {
+		return fmt.Errorf("invalid Threshold: should be in range (0, 1)")
+	}
+
+	if c.MinSilenceDurationMs < 0 {
+		return fmt.Errorf("invalid MinSilenceDurationMs: should be a positive number")
+	}
+
+	if c.SpeechPadMs < 0 {
+		return fmt.Errorf("invalid SpeechPadMs: should be a positive number")
+	}
+
+	if c.MarginDB < 0 {
+		return fmt.Errorf("invalid MarginDB: should not be negative")
+	}
+
+	if c.MinEnergyDB > 0 {
+		return fmt.Errorf("invalid MinEnergyDB: should not be positive")
+	}
+
+	if c.MaxZeroCrossingRate < 0 || c.MaxZeroCrossingRate > 1 {
+		return fmt.Errorf("invalid MaxZeroCrossingRate: should be in range (0, 1]")
+	}
+
+	return nil
+}
+
+// EnergyDetector 以自适应能量和过零率判定语音的纯 Go 检测器，不需要 ONNX 模型和 ONNX Runtime 会话
+// 每个窗口的能量与跟踪的噪声基底比较得到语音概率，之后的分段规则与 DetectorContext 相同。
+// 适用于模型无法加载时的降级、作为模型之前的低成本预筛，以及在测试中与模型结果对比。
+// 准确率明显低于 Silero 模型，持续的高能量噪声也会被判为语音。EnergyDetector 不是并发安全的。
+type EnergyDetector struct {
+	cfg        EnergyConfig
+	windowSize int
+	riseAlpha  float64
+	floor      float64 // 噪声基底（dBFS），尚未初始化时为 NaN
+	currSample int
+	triggered  bool
+	tempEnd    int
+	startAt    float64
+	lastProb   float32
+}
+
+// NewEnergyDetector 创建能量检测器
+func NewEnergyDetector(cfg EnergyConfig) (*EnergyDetector, error) {
+	if err := cfg.IsValid(); err != nil {
+		return nil, err
+	}
+	if cfg.Threshold == 0 {
+		cfg.Threshold = 0.5
+	}
+	if cfg.MarginDB == 0 {
+		cfg.MarginDB = defaultEnergyMarginDB
+	}
+	if cfg.MinEnergyDB == 0 {
+		cfg.MinEnergyDB = defaultEnergyMinDB
+	}
+	if cfg.MaxZeroCrossingRate == 0 {
+		cfg.MaxZeroCrossingRate = defaultMaxZeroCrossingRate
+	}
+
+	windowSize := windowSizeFor(cfg.SampleRate)
+	windowSeconds := float64(windowSize) / float64(cfg.SampleRate)
+	return &EnergyDetector{
+		cfg:        cfg,
+		windowSize: windowSize,
+		riseAlpha:  1 - math.Exp(-windowSeconds/noiseFloorRiseSeconds),
+		floor:      math.NaN(),
+	}, nil
+}
+
+// GetConfig 返回填充默认值之后的配置
+func (ed *EnergyDetector) GetConfig() EnergyConfig {
+	return ed.cfg
+}
+
+// Detect 检测语音片段，与 DetectorContext.Detect 一样不检测最后一个窗口之后的剩余采样
+func (ed *EnergyDetector) Detect(pcm []float32) ([]Segment, error) {
+	if ed == nil {
+		return nil, fmt.Errorf("invalid nil energy detector")
+	}
+	if len(pcm) < ed.windowSize {
+		return nil, notEnoughSamples(len(pcm), ed.windowSize)
+	}
+
+	var segments []Segment
+	for i := 0; i < len(pcm)-ed.windowSize; i += ed.windowSize {
+		segments = ed.advance(ed.probability(pcm[i:i+ed.windowSize]), segments)
+	}
+	return segments, nil
+}
+
+// DetectChunks 依次检测 chunker 中所有完整的窗口，剩余采样留待下次调用，约定同 DetectorContext.DetectChunks
+func (ed *EnergyDetector) DetectChunks(c *StreamChunker) ([]Segment, error) {
+	if ed == nil {
+		return nil, fmt.Errorf("invalid nil energy detector")
+	}
+	if c == nil {
+		return nil, fmt.Errorf("invalid nil stream chunker")
+	}
+	if c.WindowSize() != ed.windowSize {
+		return nil, fmt.Errorf("chunker window size %d does not match window size %d", c.WindowSize(), ed.windowSize)
+	}
+
+	var segments []Segment
+	for {
+		frame, ok := c.Next()
+		if !ok {
+			return segments, nil
+		}
+		segments = ed.advance(ed.probability(frame), segments)
+	}
+}
+
+// IsSpeech 从头检测音频，任一窗口的语音概率达到阈值时立即返回 true
+func (ed *EnergyDetector) IsSpeech(pcm []float32) (bool, error) {
+	if ed == nil {
+		return false, fmt.Errorf("invalid nil energy detector")
+	}
+	if len(pcm) < ed.windowSize {
+		return false, notEnoughSamples(len(pcm), ed.windowSize)
+	}
+
+	_ = ed.Reset()
+	for i := 0; i < len(pcm)-ed.windowSize; i += ed.windowSize {
+		if ed.probability(pcm[i:i+ed.windowSize]) >= ed.cfg.Threshold {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// LastProbability 返回最近一个窗口的语音概率
+func (ed *EnergyDetector) LastProbability() float32 {
+	return ed.lastProb
+}
+
+// probability 由窗口的能量、噪声基底和过零率估计语音概率，并更新噪声基底
+func (ed *EnergyDetector) probability(window []float32) float32 {
+	var sum float64
+	crossings := 0
+	for i, v := range window {
+		sum += float64(v) * float64(v)
+		if i > 0 && (v >= 0) != (window[i-1] >= 0) {
+			crossings++
+		}
+	}
+	energy := max(10*math.Log10(sum/float64(len(window))+1e-12), -120)
+	zcr := float64(crossings) / float64(len(window)-1)
+
+	if math.IsNaN(ed.floor) {
+		ed.floor = energy
+	}
+	snr := energy - ed.floor
+	if energy < ed.floor {
+		ed.floor = energy
+	} else {
+		ed.floor += (energy - ed.floor) * ed.riseAlpha
+	}
+
+	var prob float32
+	if energy >= ed.cfg.MinEnergyDB {
+		prob = float32(1 / (1 + math.Exp(-(snr-ed.cfg.MarginDB)/energySlopeDB)))
+	}
+	// 宽带噪声的过零率高：可以延续已经开始的片段，但不足以开始新的片段
+	if zcr > ed.cfg.MaxZeroCrossingRate {
+		prob = min(prob, ed.cfg.Threshold-0.1)
+	}
+	ed.lastProb = prob
+	return prob
+}
+
+// advance 以一个窗口的语音概率推进分段状态机，规则与 DetectorContext.advance 相同
+func (ed *EnergyDetector) advance(speechProb float32, segments []Segment) []Segment {
+	cfg := &ed.cfg
+	minSilenceSamples := cfg.MinSilenceDurationMs * cfg.SampleRate / 1000
+	speechPadSamples := cfg.SpeechPadMs * cfg.SampleRate / 1000
+
+	ed.currSample += ed.windowSize
+
+	if speechProb >= cfg.Threshold && ed.tempEnd != 0 {
+		ed.tempEnd = 0
+	}
+
+	if speechProb >= cfg.Threshold && !ed.triggered {
+		ed.triggered = true
+		ed.startAt = max(float64(ed.currSample-ed.windowSize-speechPadSamples)/float64(cfg.SampleRate), 0)
+		segments = append(segments, Segment{SpeechStartAt: ed.startAt})
+	}
+
+	if speechProb < (cfg.Threshold-0.15) && ed.triggered {
+		if ed.tempEnd == 0 {
+			ed.tempEnd = ed.currSample
+		}
+		if ed.currSample-ed.tempEnd < minSilenceSamples {
+			return segments
+		}
+
+		speechEndAt := float64(ed.tempEnd+speechPadSamples) / float64(cfg.SampleRate)
+		ed.tempEnd = 0
+		ed.triggered = false
+
+		// 片段在之前的调用中开始时，本次结果里补上完整的片段
+		if len(segments) == 0 {
+			segments = append(segments, Segment{SpeechStartAt: ed.startAt})
+		}
+		segments[len(segments)-1].SpeechEndAt = speechEndAt
+	}
+
+	return segments
+}
+
+// Reset 重置检测状态和噪声基底
+func (ed *EnergyDetector) Reset() error {
+	if ed == nil {
+		return fmt.Errorf("invalid nil energy detector")
+	}
+	ed.floor = math.NaN()
+	ed.currSample = 0
+	ed.triggered = false
+	ed.tempEnd = 0
+	ed.startAt = 0
+	ed.lastProb = 0
+	return nil
+}
+
+// Close 实现 SegmentDetector，EnergyDetector 没有需要释放的资源
+func (ed *EnergyDetector) Close() error {
+	return nil
+}
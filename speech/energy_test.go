@@ -0,0 +1,132 @@
+package speech
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// toneBursts 在低电平白噪声上叠加 300Hz 的音调，bursts 为各段音调的起止秒数
+func toneBursts(seconds float64, bursts [][2]float64) []float32 {
+	rng := rand.New(rand.NewSource(1))
+	pcm := make([]float32, int(seconds*16000))
+	for i := range pcm {
+		pcm[i] = float32(rng.NormFloat64() * 0.001)
+	}
+	for _, b := range bursts {
+		for i := int(b[0] * 16000); i < int(b[1]*16000); i++ {
+			pcm[i] += float32(0.3 * math.Sin(2*math.Pi*300*float64(i)/16000))
+		}
+	}
+	return pcm
+}
+
+func TestEnergyDetector(t *testing.T) {
+	for _, bad := range []EnergyConfig{
+		{SampleRate: 44100},
+		{SampleRate: 16000, Threshold: 1},
+		{SampleRate: 16000, MinSilenceDurationMs: -1},
+		{SampleRate: 16000, MinEnergyDB: 3},
+		{SampleRate: 16000, MaxZeroCrossingRate: 2},
+	} {
+		_, err := NewEnergyDetector(bad)
+		require.Error(t, err)
+	}
+
+	ed, err := NewEnergyDetector(EnergyConfig{SampleRate: 16000, MinSilenceDurationMs: 100})
+	require.NoError(t, err)
+	require.Equal(t, float32(0.5), ed.GetConfig().Threshold)
+
+	_, err = ed.Detect(make([]float32, 100))
+	require.ErrorIs(t, err, ErrNotEnoughSamples)
+
+	pcm := toneBursts(6, [][2]float64{{1, 2}, {3.5, 4.5}})
+	segments, err := ed.Detect(pcm)
+	require.NoError(t, err)
+	require.Len(t, segments, 2)
+	require.InDelta(t, 1.0, segments[0].SpeechStartAt, 0.07)
+	require.InDelta(t, 2.0, segments[0].SpeechEndAt, 0.07)
+	require.InDelta(t, 3.5, segments[1].SpeechStartAt, 0.07)
+	require.InDelta(t, 4.5, segments[1].SpeechEndAt, 0.07)
+
+	// 流式检测与整段检测一致
+	require.NoError(t, ed.Reset())
+	chunker, err := NewStreamChunker(16000)
+	require.NoError(t, err)
+	var streamed []Segment
+	for off := 0; off < len(pcm); off += 320 {
+		chunker.Write(pcm[off:min(off+320, len(pcm))])
+		segs, err := ed.DetectChunks(chunker)
+		require.NoError(t, err)
+		for _, seg := range segs {
+			if n := len(streamed); n > 0 && streamed[n-1].SpeechStartAt == seg.SpeechStartAt {
+				streamed[n-1] = seg
+			} else {
+				streamed = append(streamed, seg)
+			}
+		}
+	}
+	require.Equal(t, segments, streamed)
+	chunker8k, err := NewStreamChunker(8000)
+	require.NoError(t, err)
+	_, err = ed.DetectChunks(chunker8k)
+	require.Error(t, err)
+
+	// 只有噪声时没有语音，过零率高的宽带噪声即使很响也不能开始片段
+	speech, err := ed.IsSpeech(pcm)
+	require.NoError(t, err)
+	require.True(t, speech)
+	speech, err = ed.IsSpeech(toneBursts(2, nil))
+	require.NoError(t, err)
+	require.False(t, speech)
+	noise := toneBursts(3, nil)
+	for i := 16000; i < 32000; i++ {
+		noise[i] *= 300
+	}
+	speech, err = ed.IsSpeech(noise)
+	require.NoError(t, err)
+	require.False(t, speech)
+	require.NoError(t, ed.Close())
+}
+
+// TestEnergyDetectorAgainstModel 对比能量检测与模型在真实录音上的结果
+func TestEnergyDetectorAgainstModel(t *testing.T) {
+	sm := newTestSharedModel(t)
+	samples := readTestSamples(t, "../testfiles/samples.pcm")
+
+	var detectors []SegmentDetector
+	detectors = append(detectors, sm.NewContext())
+	ed, err := NewEnergyDetector(EnergyConfig{SampleRate: 16000, MinSilenceDurationMs: 100})
+	require.NoError(t, err)
+	detectors = append(detectors, ed)
+
+	var results [][]Segment
+	for _, d := range detectors {
+		segments, err := d.Detect(samples)
+		require.NoError(t, err)
+		require.NotEmpty(t, segments)
+		results = append(results, segments)
+		require.NoError(t, d.Close())
+	}
+
+	// 模型判定为语音的时间大部分也被能量检测判为语音
+	duration := float64(len(samples)) / 16000
+	var speech, agreed float64
+	for _, a := range results[0] {
+		end := a.SpeechEndAt
+		if end == 0 {
+			end = duration
+		}
+		speech += end - a.SpeechStartAt
+		for _, b := range results[1] {
+			bEnd := b.SpeechEndAt
+			if bEnd == 0 {
+				bEnd = duration
+			}
+			agreed += max(min(end, bEnd)-max(a.SpeechStartAt, b.SpeechStartAt), 0)
+		}
+	}
+	require.Greater(t, agreed/speech, 0.6)
+}
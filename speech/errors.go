@@ -0,0 +1,78 @@
+package speech
+
+// #cgo CFLAGS: -Wall -Werror -std=c99
+// #cgo LDFLAGS: -lonnxruntime
+// #include "ort_bridge.h"
+import "C"
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrNotEnoughSamples 输入的采样点数不足一个推理窗口，补充更多音频后重试即可
+	ErrNotEnoughSamples = errors.New("not enough samples")
+	// ErrInvalidConfig 配置无效，或修改了上下文不能单独修改的字段
+	ErrInvalidConfig = errors.New("invalid config")
+	// ErrUnexpectedSpeechEnd 检测到语音结束但没有对应的语音开始，说明检测状态已损坏，应调用 Reset
+	ErrUnexpectedSpeechEnd = errors.New("unexpected speech end")
+	// ErrModelLoad 模型文件无法加载为 ONNX 会话
+	ErrModelLoad = errors.New("failed to load model")
+	// ErrModelDestroyed 共享模型已被销毁
+	ErrModelDestroyed = errors.New("shared model destroyed")
+	// ErrContextClosed 检测器上下文已被关闭
+	ErrContextClosed = errors.New("detector context closed")
+	// ErrPoolClosed DetectorPool 已被关闭
+	ErrPoolClosed = errors.New("detector pool closed")
+	// ErrInvalidSamples 输入含 NaN 或 Inf 采样，见 InputCheckReject
+	ErrInvalidSamples = errors.New("invalid samples")
+	// ErrStreamClosed Stream 已被关闭
+	ErrStreamClosed = errors.New("stream closed")
+	// ErrInvalidState RestoreState 的数据损坏、版本不支持或与上下文的采样率不符
+	ErrInvalidState = errors.New("invalid detector state")
+)
+
+// notEnoughSamples 返回带实际长度的 ErrNotEnoughSamples
+func notEnoughSamples(n, windowSize int) error {
+	return fmt.Errorf("%w: got %d, need at least %d", ErrNotEnoughSamples, n, windowSize)
+}
+
+// OrtErrorCode 对应 ONNX Runtime 的 OrtErrorCode 枚举
+type OrtErrorCode int
+
+const (
+	OrtOK                OrtErrorCode = C.ORT_OK
+	OrtFail              OrtErrorCode = C.ORT_FAIL
+	OrtInvalidArgument   OrtErrorCode = C.ORT_INVALID_ARGUMENT
+	OrtNoSuchFile        OrtErrorCode = C.ORT_NO_SUCHFILE
+	OrtNoModel           OrtErrorCode = C.ORT_NO_MODEL
+	OrtEngineError       OrtErrorCode = C.ORT_ENGINE_ERROR
+	OrtRuntimeException  OrtErrorCode = C.ORT_RUNTIME_EXCEPTION
+	OrtInvalidProtobuf   OrtErrorCode = C.ORT_INVALID_PROTOBUF
+	OrtModelLoaded       OrtErrorCode = C.ORT_MODEL_LOADED
+	OrtNotImplemented    OrtErrorCode = C.ORT_NOT_IMPLEMENTED
+	OrtInvalidGraph      OrtErrorCode = C.ORT_INVALID_GRAPH
+	OrtExecutionProvider OrtErrorCode = C.ORT_EP_FAIL
+)
+
+// OrtError 表示 ONNX Runtime 返回的错误状态
+// 可以通过 errors.As 取出并根据 Code 做分支处理
+type OrtError struct {
+	// ONNX Runtime 的错误码
+	Code OrtErrorCode
+	// ONNX Runtime 的错误信息
+	Msg string
+}
+
+func (e *OrtError) Error() string {
+	return fmt.Sprintf("%s (ort code %d)", e.Msg, e.Code)
+}
+
+// newOrtError 将非空的 OrtStatus 转换为 *OrtError，调用方仍负责释放 status
+func newOrtError(api *C.OrtApi, status *C.OrtStatus) error {
+	return &OrtError{
+		Code: OrtErrorCode(C.OrtApiGetErrorCode(api, status)),
+		Msg:  C.GoString(C.OrtApiGetErrorMessage(api, status)),
+	}
+}
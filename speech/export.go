@@ -0,0 +1,244 @@
+package speech
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Segments 检测结果列表，提供导出为常见格式的方法
+// Detect 返回的 []Segment 可直接转换：speech.Segments(segments)。
+type Segments []Segment
+
+const (
+	// audacityLabel 导出到 Audacity 标签轨时使用的标签文字
+	audacityLabel = "speech"
+	// cuePlaceholder 没有提供字幕文字时使用的占位文本
+	cuePlaceholder = "[speech]"
+)
+
+// WriteAudacityLabels 以 Audacity 标签文件格式（制表符分隔的 起点、终点、标签）写出片段
+// 在 Audacity 中通过“文件 > 导入 > 标签”载入后，可以直接对照波形检查检测结果，便于调整阈值。
+// 未结束的片段写为起点与终点相同的点标签。
+func (s Segments) WriteAudacityLabels(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	for _, seg := range s {
+		end := seg.SpeechEndAt
+		if end == 0 {
+			end = seg.SpeechStartAt
+		}
+		if _, err := fmt.Fprintf(bw, "%.6f\t%.6f\t%s\n", seg.SpeechStartAt, end, audacityLabel); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// WriteSRT 将片段写为 SRT 字幕文件，texts[i] 为第 i 个片段的字幕文字
+// texts 为 nil 或长度不足时使用占位文本。未结束的片段没有终点时间，会被跳过。
+func (s Segments) WriteSRT(w io.Writer, texts []string) error {
+	bw := bufio.NewWriter(w)
+	n := 0
+	for i, seg := range s {
+		if seg.SpeechEndAt == 0 {
+			continue
+		}
+		n++
+		if _, err := fmt.Fprintf(bw, "%d\n%s --> %s\n%s\n\n", n,
+			formatCueTime(seg.SpeechStartAt, ','), formatCueTime(seg.SpeechEndAt, ','),
+			cueText(texts, i, false)); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// WriteWebVTT 将片段写为 WebVTT 字幕文件，参数约定同 WriteSRT
+func (s Segments) WriteWebVTT(w io.Writer, texts []string) error {
+	bw := bufio.NewWriter(w)
+	if _, err := bw.WriteString("WEBVTT\n\n"); err != nil {
+		return err
+	}
+	for i, seg := range s {
+		if seg.SpeechEndAt == 0 {
+			continue
+		}
+		if _, err := fmt.Fprintf(bw, "%s --> %s\n%s\n\n",
+			formatCueTime(seg.SpeechStartAt, '.'), formatCueTime(seg.SpeechEndAt, '.'),
+			cueText(texts, i, true)); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// formatCueTime 将秒格式化为 HH:MM:SS<sep>mmm
+func formatCueTime(seconds float64, sep byte) string {
+	ms := int64(math.Round(seconds * 1000))
+	return fmt.Sprintf("%02d:%02d:%02d%c%03d", ms/3600000, ms/60000%60, ms/1000%60, sep, ms%1000)
+}
+
+// cueText 返回第 i 个片段的字幕文字
+// 空行会结束字幕块，因此被移除；WebVTT 还需要转义 &、< 和 >。
+func cueText(texts []string, i int, vtt bool) string {
+	text := cuePlaceholder
+	if i < len(texts) && strings.TrimSpace(texts[i]) != "" {
+		text = texts[i]
+	}
+
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	kept := lines[:0]
+	for _, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			kept = append(kept, line)
+		}
+	}
+	text = strings.Join(kept, "\n")
+
+	if vtt {
+		text = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+	}
+	return text
+}
+
+// SegmentSchemaVersion JSON/CSV 导出格式的版本号
+// 只会以向后兼容的方式新增字段；删除或修改已有字段时版本号递增。
+const SegmentSchemaVersion = 1
+
+// segmentJSON 单个片段的 JSON 格式，未结束的片段 end 为 null，没有估计信噪比、测量响度或削波时省略对应字段
+type segmentJSON struct {
+	Start    float64  `json:"start"`
+	End      *float64 `json:"end"`
+	SNR      float64  `json:"snr,omitempty"`
+	Loudness float64  `json:"loudness,omitempty"`
+	RMS      float64  `json:"rms,omitempty"`
+	Clipped  bool     `json:"clipped,omitempty"`
+}
+
+// MarshalJSON 将片段编码为 [{"start": 秒, "end": 秒或 null}, ...]
+func (s Segments) MarshalJSON() ([]byte, error) {
+	out := make([]segmentJSON, len(s))
+	for i, seg := range s {
+		out[i].Start = seg.SpeechStartAt
+		if seg.SpeechEndAt > 0 {
+			end := seg.SpeechEndAt
+			out[i].End = &end
+		}
+		out[i].SNR = seg.SNR
+		out[i].Loudness = seg.Loudness
+		out[i].RMS = seg.RMS
+		out[i].Clipped = seg.Clipped
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON 解析 MarshalJSON 生成的数据
+func (s *Segments) UnmarshalJSON(data []byte) error {
+	var in []segmentJSON
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+
+	out := make(Segments, len(in))
+	for i, seg := range in {
+		out[i].SpeechStartAt = seg.Start
+		if seg.End != nil {
+			out[i].SpeechEndAt = *seg.End
+		}
+		out[i].SNR = seg.SNR
+		out[i].Loudness = seg.Loudness
+		out[i].RMS = seg.RMS
+		out[i].Clipped = seg.Clipped
+	}
+	*s = out
+	return nil
+}
+
+// ReportConfig 生成检测结果时使用的配置，只包含影响结果的参数
+type ReportConfig struct {
+	Threshold            float32 `json:"threshold"`
+	MinSilenceDurationMs int     `json:"min_silence_duration_ms"`
+	SpeechPadMs          int     `json:"speech_pad_ms"`
+	HighPassCutoffHz     float64 `json:"high_pass_cutoff_hz"`
+}
+
+// SegmentReport 带版本号的检测结果，供下游工具直接解析
+//
+// JSON 格式（版本 1）：
+//
+//	{
+//	  "schema_version": 1,
+//	  "sample_rate": 16000,
+//	  "config": {"threshold": 0.5, "min_silence_duration_ms": 0, "speech_pad_ms": 0, "high_pass_cutoff_hz": 0},
+//	  "segments": [{"start": 1.056, "end": 1.632}, {"start": 4.448, "end": null}]
+//	}
+type SegmentReport struct {
+	SchemaVersion int          `json:"schema_version"`
+	SampleRate    int          `json:"sample_rate"`
+	Config        ReportConfig `json:"config"`
+	Segments      Segments     `json:"segments"`
+}
+
+// NewSegmentReport 用检测配置和结果创建 SegmentReport
+func NewSegmentReport(cfg DetectorConfig, segments []Segment) SegmentReport {
+	return SegmentReport{
+		SchemaVersion: SegmentSchemaVersion,
+		SampleRate:    cfg.SampleRate,
+		Config: ReportConfig{
+			Threshold:            cfg.Threshold,
+			MinSilenceDurationMs: cfg.MinSilenceDurationMs,
+			SpeechPadMs:          cfg.SpeechPadMs,
+			HighPassCutoffHz:     cfg.HighPassCutoffHz,
+		},
+		Segments: segments,
+	}
+}
+
+// WriteJSON 以缩进格式写出 JSON
+func (r SegmentReport) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// segmentCSVHeader CSV 格式（版本 1）的列名
+// 时间单位为秒，采样位置按 sample_rate 换算；未结束的片段 end 和 end_sample 为空。
+var segmentCSVHeader = []string{"schema_version", "sample_rate", "index", "start", "end", "start_sample", "end_sample"}
+
+// WriteCSV 写出带表头的 CSV，每行一个片段，列依次为
+// schema_version, sample_rate, index, start, end, start_sample, end_sample
+func (r SegmentReport) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(segmentCSVHeader); err != nil {
+		return err
+	}
+
+	version := strconv.Itoa(r.SchemaVersion)
+	rate := strconv.Itoa(r.SampleRate)
+	for i, seg := range r.Segments {
+		record := []string{
+			version,
+			rate,
+			strconv.Itoa(i),
+			strconv.FormatFloat(seg.SpeechStartAt, 'f', -1, 64),
+			"",
+			strconv.Itoa(int(math.Round(seg.SpeechStartAt * float64(r.SampleRate)))),
+			"",
+		}
+		if seg.SpeechEndAt > 0 {
+			record[4] = strconv.FormatFloat(seg.SpeechEndAt, 'f', -1, 64)
+			record[6] = strconv.Itoa(int(math.Round(seg.SpeechEndAt * float64(r.SampleRate))))
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,71 @@
+package speech
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteAudacityLabels(t *testing.T) {
+	segments := Segments{
+		{SpeechStartAt: 1.056, SpeechEndAt: 1.632},
+		{SpeechStartAt: 4.448},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, segments.WriteAudacityLabels(&buf))
+	require.Equal(t, "1.056000\t1.632000\tspeech\n4.448000\t4.448000\tspeech\n", buf.String())
+}
+
+func TestWriteSubtitles(t *testing.T) {
+	segments := Segments{
+		{SpeechStartAt: 1.056, SpeechEndAt: 1.632},
+		{SpeechStartAt: 3725.5, SpeechEndAt: 3726.0004},
+		{SpeechStartAt: 4000},
+	}
+	texts := []string{"hello <world> & co\n\nsecond line"}
+
+	var buf bytes.Buffer
+	require.NoError(t, segments.WriteSRT(&buf, texts))
+	require.Equal(t, "1\n00:00:01,056 --> 00:00:01,632\nhello <world> & co\nsecond line\n\n"+
+		"2\n01:02:05,500 --> 01:02:06,000\n[speech]\n\n", buf.String())
+
+	buf.Reset()
+	require.NoError(t, segments.WriteWebVTT(&buf, texts))
+	require.Equal(t, "WEBVTT\n\n"+
+		"00:00:01.056 --> 00:00:01.632\nhello &lt;world&gt; &amp; co\nsecond line\n\n"+
+		"01:02:05.500 --> 01:02:06.000\n[speech]\n\n", buf.String())
+}
+
+func TestSegmentReport(t *testing.T) {
+	report := NewSegmentReport(DetectorConfig{
+		ModelPath:   "../testfiles/silero_vad.onnx",
+		SampleRate:  16000,
+		Threshold:   0.5,
+		SpeechPadMs: 30,
+	}, []Segment{
+		{SpeechStartAt: 1.056, SpeechEndAt: 1.632},
+		{SpeechStartAt: 4.448},
+	})
+
+	var buf bytes.Buffer
+	require.NoError(t, report.WriteJSON(&buf))
+	require.JSONEq(t, `{
+		"schema_version": 1,
+		"sample_rate": 16000,
+		"config": {"threshold": 0.5, "min_silence_duration_ms": 0, "speech_pad_ms": 30, "high_pass_cutoff_hz": 0},
+		"segments": [{"start": 1.056, "end": 1.632}, {"start": 4.448, "end": null}]
+	}`, buf.String())
+
+	var decoded SegmentReport
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	require.Equal(t, report, decoded)
+
+	buf.Reset()
+	require.NoError(t, report.WriteCSV(&buf))
+	require.Equal(t, "schema_version,sample_rate,index,start,end,start_sample,end_sample\n"+
+		"1,16000,0,1.056,1.632,16896,26112\n"+
+		"1,16000,1,4.448,,71168,\n", buf.String())
+}
@@ -0,0 +1,141 @@
+package speech
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/rui-yang-me/silero-vad-go/audio"
+)
+
+// GatedWriter 包装一个 io.Writer，只在检测到语音时转发写入的音频
+// 录音管线只需把原来的 writer 换成 GatedWriter 即可得到只含语音的文件。语音开始时会先补写
+// 之前 preRoll 时长的音频，以免丢掉起始音节；语音结束的判定遵循 MinSilenceDurationMs。
+// 写入的数据按窗口检测，不足一个窗口的数据会等待后续写入。GatedWriter 不是并发安全的。
+type GatedWriter struct {
+	w       io.Writer
+	dc      *DetectorContext
+	format  audio.SampleFormat
+	chunker *StreamChunker
+
+	frameBytes int
+	pending    []byte // 已写入但尚未完成检测的原始字节
+	preRoll    []byte // 最近的非语音音频，语音开始时补写
+	maxPreRoll int
+	active     bool
+}
+
+// NewGatedWriter 创建 GatedWriter，写入的数据须为 format 格式的小端原始采样，
+// 采样率与 dc 的模型配置一致；转发的是原始字节，因此不支持 InputSampleRate
+func NewGatedWriter(w io.Writer, dc *DetectorContext, format audio.SampleFormat, preRoll time.Duration) (*GatedWriter, error) {
+	if w == nil {
+		return nil, fmt.Errorf("invalid nil writer")
+	}
+	if dc == nil || dc.model == nil {
+		return nil, fmt.Errorf("invalid nil detector context")
+	}
+	size := format.BytesPerSample()
+	if size == 0 {
+		return nil, fmt.Errorf("%w: sample format %s", audio.ErrUnsupportedFormat, format)
+	}
+	if preRoll < 0 {
+		return nil, fmt.Errorf("invalid pre-roll: %s", preRoll)
+	}
+
+	cfg := dc.config()
+	if cfg.inputRate() != cfg.SampleRate {
+		return nil, fmt.Errorf("%w: gated writer requires input at the model sample rate %d", ErrInvalidConfig, cfg.SampleRate)
+	}
+	sampleRate := cfg.SampleRate
+	chunker, err := NewStreamChunker(sampleRate)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GatedWriter{
+		w:          w,
+		dc:         dc,
+		format:     format,
+		chunker:    chunker,
+		frameBytes: chunker.WindowSize() * size,
+		maxPreRoll: int(preRoll.Seconds()*float64(sampleRate)) * size,
+	}, nil
+}
+
+// Write 检测 p 中的音频并转发其中的语音部分，成功时总是返回 len(p)
+func (g *GatedWriter) Write(p []byte) (int, error) {
+	if err := g.chunker.WriteBytes(p, g.format); err != nil {
+		return 0, err
+	}
+	g.pending = append(g.pending, p...)
+
+	if err := g.dc.acquire(); err != nil {
+		return 0, err
+	}
+	defer g.dc.release()
+
+	cfg := g.dc.callConfig()
+	consumed := 0
+	for {
+		frame, ok := g.chunker.Next()
+		if !ok {
+			break
+		}
+
+		if _, err := g.dc.detectFrame(cfg, frame, nil, 0); err != nil {
+			return 0, err
+		}
+
+		raw := g.pending[consumed : consumed+g.frameBytes]
+		consumed += g.frameBytes
+		if err := g.route(raw); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(g.pending, g.pending[consumed:])
+	g.pending = g.pending[:n]
+
+	return len(p), nil
+}
+
+// route 根据检测状态转发一个窗口的原始字节，或将其放入 pre-roll 缓冲
+func (g *GatedWriter) route(raw []byte) error {
+	if !g.dc.triggered {
+		g.active = false
+		g.preRoll = append(g.preRoll, raw...)
+		if over := len(g.preRoll) - g.maxPreRoll; over > 0 {
+			n := copy(g.preRoll, g.preRoll[over:])
+			g.preRoll = g.preRoll[:n]
+		}
+		return nil
+	}
+
+	if !g.active {
+		g.active = true
+		if _, err := g.w.Write(g.preRoll); err != nil {
+			return err
+		}
+		g.preRoll = g.preRoll[:0]
+	}
+
+	_, err := g.w.Write(raw)
+	return err
+}
+
+// Active 返回当前是否处于语音中
+func (g *GatedWriter) Active() bool {
+	return g.active
+}
+
+// Flush 在语音进行中时转发尚不足一个窗口的剩余数据，通常在输入结束时调用
+func (g *GatedWriter) Flush() error {
+	if g.active && len(g.pending) > 0 {
+		if _, err := g.w.Write(g.pending); err != nil {
+			return err
+		}
+	}
+	g.pending = g.pending[:0]
+	g.chunker.Reset()
+	return nil
+}
@@ -0,0 +1,61 @@
+package speech
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/rui-yang-me/silero-vad-go/audio"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGatedWriter(t *testing.T) {
+	sm := newTestSharedModel(t)
+	samples := readTestSamples(t, "../testfiles/samples.pcm")
+
+	pcm16 := audio.Float32ToInt16(nil, samples)
+	data := make([]byte, 2*len(pcm16))
+	for i, v := range pcm16 {
+		binary.LittleEndian.PutUint16(data[2*i:], uint16(v))
+	}
+
+	write := func(preRoll time.Duration, input []byte) []byte {
+		dc := sm.NewContext()
+		defer dc.Close()
+
+		var out bytes.Buffer
+		g, err := NewGatedWriter(&out, dc, audio.FormatPCM16, preRoll)
+		require.NoError(t, err)
+
+		// 按 20ms 的 RTP 包大小写入，并在奇数字节处切分
+		for off := 0; off < len(input); off += 641 {
+			n, err := g.Write(input[off:min(off+641, len(input))])
+			require.NoError(t, err)
+			require.Equal(t, min(641, len(input)-off), n)
+		}
+		require.NoError(t, g.Flush())
+		return out.Bytes()
+	}
+
+	speech := write(0, data)
+	require.NotEmpty(t, speech)
+	require.Less(t, len(speech), len(data))
+	require.Zero(t, len(speech)%2)
+
+	// 输出的第一个字节来自第一个语音窗口
+	dc := sm.NewContext()
+	defer dc.Close()
+	segments, err := dc.Detect(samples)
+	require.NoError(t, err)
+	first := int(segments[0].SpeechStartAt*16000) / 512 * 512
+	require.Equal(t, data[2*first:2*first+64], speech[:64])
+
+	withPreRoll := write(300*time.Millisecond, data)
+	require.Greater(t, len(withPreRoll), len(speech))
+
+	require.Empty(t, write(300*time.Millisecond, make([]byte, 32000)))
+
+	_, err = NewGatedWriter(&bytes.Buffer{}, dc, audio.FormatOggOpus, 0)
+	require.Error(t, err)
+}
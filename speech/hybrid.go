@@ -0,0 +1,316 @@
+package speech
+
+import (
+	"fmt"
+)
+
+const (
+	// defaultGateBelow 未设置 HybridConfig.GateBelow 时不调用模型的能量语音概率上限
+	defaultGateBelow = 0.1
+	// defaultVetoBelow 未设置 HybridConfig.VetoBelow 时否决单窗口误判的能量语音概率上限
+	defaultVetoBelow = 0.3
+)
+
+// FusionRule 模型与能量检测的语音概率的融合方式
+type FusionRule int
+
+const (
+	// FusionModel 只使用模型的语音概率，能量检测只决定是否调用模型
+	FusionModel FusionRule = iota
+	// FusionMin 取两者中较小的一个，两者都认为是语音才判为语音
+	FusionMin
+	// FusionWeighted 按 HybridConfig.EnergyWeight 加权平均
+	FusionWeighted
+)
+
+// String 返回融合方式的名称
+func (r FusionRule) String() string {
+	switch r {
+	case FusionModel:
+		return "model"
+	case FusionMin:
+		return "min"
+	case FusionWeighted:
+		return "weighted"
+	default:
+		return "unknown"
+	}
+}
+
+// HybridConfig HybridDetector 的配置
+type HybridConfig struct {
+	// 能量检测的参数，其中 SampleRate、Threshold、MinSilenceDurationMs、SpeechPadMs 取自模型配置
+	Energy EnergyConfig
+	// 不在语音片段中且能量语音概率低于该值时不调用模型，窗口按静音处理，取值 [0, 1)，0 表示默认 0.1
+	GateBelow float32
+	// 模型在静音之后第一个判为语音的窗口，若能量语音概率低于该值则否决，取值 [0, 1)，0 表示默认 0.3；
+	// 持续的稳态噪声常让模型偶尔判错单个窗口，而这类窗口的能量并不高于噪声基底
+	VetoBelow float32
+	// 关闭单窗口否决
+	DisableVeto bool
+	// 融合方式
+	Fusion FusionRule
+	// FusionWeighted 中能量语音概率的权重，取值 [0, 1]
+	EnergyWeight float32
+}
+
+// HybridStats HybridDetector 调用模型的统计
+type HybridStats struct {
+	// 检测过的窗口数
+	Windows int64
+	// 调用了模型的窗口数
+	ModelWindows int64
+	// 被能量检测否决的窗口数
+	Vetoed int64
+}
+
+// HybridDetector 结合能量检测与模型：能量检测决定何时调用模型，并否决模型的单窗口误判
+// 静音期间大部分窗口不调用模型，从而降低 CPU 占用，同时减少稳态噪声上的误报。
+// 进入语音片段后每个窗口都调用模型，片段的结束由融合后的概率按模型的规则判定。
+// 跳过窗口之后再次调用模型时，模型的循环状态从初始状态重新开始。HybridDetector 实现 SegmentDetector，不是并发安全的。
+type HybridDetector struct {
+	dc     *DetectorContext
+	energy *EnergyDetector
+	cfg    HybridConfig
+
+	prevSpeech bool // 上一个调用了模型的窗口模型是否判为语音
+	skipped    bool // 上一个窗口没有调用模型
+	stats      HybridStats
+}
+
+var _ SegmentDetector = (*HybridDetector)(nil)
+
+// NewHybridDetector 创建基于 model 的混合检测器，检测器拥有自己的上下文
+func NewHybridDetector(model *SharedModel, cfg HybridConfig) (*HybridDetector, error) {
+	if model == nil {
+		return nil, fmt.Errorf("invalid nil shared model")
+	}
+	if cfg.GateBelow < 0 || cfg.GateBelow >= 1 {
+		return nil, fmt.Errorf("invalid GateBelow: should be in range [0, 1)")
+	}
+	if cfg.VetoBelow < 0 || cfg.VetoBelow >= 1 {
+		return nil, fmt.Errorf("invalid VetoBelow: should be in range [0, 1)")
+	}
+	if cfg.Fusion < FusionModel || cfg.Fusion > FusionWeighted {
+		return nil, fmt.Errorf("invalid Fusion: %d", cfg.Fusion)
+	}
+	if cfg.EnergyWeight < 0 || cfg.EnergyWeight > 1 {
+		return nil, fmt.Errorf("invalid EnergyWeight: should be in range [0, 1]")
+	}
+	if cfg.GateBelow == 0 {
+		cfg.GateBelow = defaultGateBelow
+	}
+	if cfg.VetoBelow == 0 {
+		cfg.VetoBelow = defaultVetoBelow
+	}
+
+	modelCfg := model.GetConfig()
+	cfg.Energy.SampleRate = modelCfg.SampleRate
+	cfg.Energy.Threshold = modelCfg.Threshold
+	cfg.Energy.MinSilenceDurationMs = modelCfg.MinSilenceDurationMs
+	cfg.Energy.SpeechPadMs = modelCfg.SpeechPadMs
+	energy, err := NewEnergyDetector(cfg.Energy)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Energy = energy.GetConfig()
+
+	return &HybridDetector{
+		dc:     model.NewContext(),
+		energy: energy,
+		cfg:    cfg,
+	}, nil
+}
+
+// Context 返回底层的检测上下文，可用于读取 RTF 等信息，不要直接调用它的检测方法
+func (h *HybridDetector) Context() *DetectorContext {
+	return h.dc
+}
+
+// Stats 返回自创建或上次 Reset 以来调用模型的统计
+func (h *HybridDetector) Stats() HybridStats {
+	return h.stats
+}
+
+// Detect 检测语音片段，输入约定同 DetectorContext.Detect
+func (h *HybridDetector) Detect(pcm []float32) ([]Segment, error) {
+	dc := h.dc
+	if err := dc.acquire(); err != nil {
+		return nil, err
+	}
+	defer dc.release()
+
+	cfg := dc.callConfig()
+	windowSize := windowSizeFor(cfg.SampleRate)
+
+	pcm, err := dc.pre.resample(cfg, pcm)
+	if err != nil {
+		return nil, err
+	}
+	if len(pcm) < windowSize {
+		if !cfg.PadShortInput {
+			return nil, notEnoughSamples(len(pcm), windowSize)
+		}
+		dc.padBuf = padShortInput(dc.padBuf, pcm, windowSize)
+		pcm = dc.padBuf
+	}
+	pcm, err = dc.pre.apply(cfg, pcm)
+	if err != nil {
+		return nil, err
+	}
+
+	var segments []Segment
+	for i := 0; i < len(pcm)-windowSize; i += windowSize {
+		segments, err = h.step(cfg, pcm[i:i+windowSize], segments)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return segments, nil
+}
+
+// DetectChunks 依次检测 chunker 中所有完整的窗口，约定同 DetectorContext.DetectChunks
+func (h *HybridDetector) DetectChunks(c *StreamChunker) ([]Segment, error) {
+	if c == nil {
+		return nil, fmt.Errorf("invalid nil stream chunker")
+	}
+	dc := h.dc
+	if err := dc.acquire(); err != nil {
+		return nil, err
+	}
+	defer dc.release()
+
+	cfg := dc.callConfig()
+	windowSize := windowSizeFor(cfg.SampleRate)
+	if c.WindowSize() != windowSize {
+		return nil, fmt.Errorf("chunker window size %d does not match model window size %d", c.WindowSize(), windowSize)
+	}
+
+	var segments []Segment
+	for {
+		frame, ok := c.Next()
+		if !ok {
+			return segments, nil
+		}
+		out, err := dc.pre.apply(cfg, frame)
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i+windowSize <= len(out); i += windowSize {
+			segments, err = h.step(cfg, out[i:i+windowSize], segments)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// IsSpeech 从头检测音频，任一窗口融合后的语音概率达到阈值时立即返回 true
+func (h *HybridDetector) IsSpeech(pcm []float32) (bool, error) {
+	if err := h.Reset(); err != nil {
+		return false, err
+	}
+	dc := h.dc
+	if err := dc.acquire(); err != nil {
+		return false, err
+	}
+	defer dc.release()
+
+	cfg := dc.callConfig()
+	windowSize := windowSizeFor(cfg.SampleRate)
+	pcm, err := dc.pre.resample(cfg, pcm)
+	if err != nil {
+		return false, err
+	}
+	if len(pcm) < windowSize {
+		if !cfg.PadShortInput {
+			return false, notEnoughSamples(len(pcm), windowSize)
+		}
+		dc.padBuf = padShortInput(dc.padBuf, pcm, windowSize)
+		pcm = dc.padBuf
+	}
+	pcm, err = dc.pre.apply(cfg, pcm)
+	if err != nil {
+		return false, err
+	}
+
+	for i := 0; i < len(pcm)-windowSize; i += windowSize {
+		prob, err := h.fuse(cfg, pcm[i:i+windowSize])
+		if err != nil {
+			return false, err
+		}
+		dc.currSample += windowSize
+		if prob >= cfg.Threshold {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// step 融合一个窗口的语音概率并推进上下文的语音状态机
+func (h *HybridDetector) step(cfg *DetectorConfig, window []float32, segments []Segment) ([]Segment, error) {
+	prob, err := h.fuse(cfg, window)
+	if err != nil {
+		return nil, err
+	}
+	return h.dc.advance(cfg, prob, window, segments, 0), nil
+}
+
+// fuse 按配置的规则计算一个窗口融合后的语音概率，必要时调用模型
+func (h *HybridDetector) fuse(cfg *DetectorConfig, window []float32) (float32, error) {
+	h.stats.Windows++
+	energy := h.energy.probability(window)
+	if !h.dc.triggered && energy < h.cfg.GateBelow {
+		h.prevSpeech = false
+		h.skipped = true
+		return 0, nil
+	}
+	// 跳过窗口之后模型的循环状态已经过时，从静音的初始状态重新开始
+	if h.skipped {
+		h.skipped = false
+		clear(h.dc.state[:])
+		clear(h.dc.ctx[:])
+	}
+
+	h.stats.ModelWindows++
+	model, err := h.dc.predict(cfg, window)
+	if err != nil {
+		return 0, fmt.Errorf("infer failed: %w", err)
+	}
+
+	// 静音之后模型第一个判为语音的窗口需要能量检测佐证，下一个窗口仍为语音时不再否决
+	speech := model >= cfg.Threshold
+	first := speech && !h.prevSpeech && !h.dc.triggered
+	h.prevSpeech = speech
+	if first && !h.cfg.DisableVeto && energy < h.cfg.VetoBelow {
+		h.stats.Vetoed++
+		return 0, nil
+	}
+
+	switch h.cfg.Fusion {
+	case FusionMin:
+		return min(model, energy), nil
+	case FusionWeighted:
+		w := h.cfg.EnergyWeight
+		return (1-w)*model + w*energy, nil
+	default:
+		return model, nil
+	}
+}
+
+// Reset 重置上下文、能量检测的噪声基底和统计
+func (h *HybridDetector) Reset() error {
+	if err := h.dc.Reset(); err != nil {
+		return err
+	}
+	_ = h.energy.Reset()
+	h.prevSpeech = false
+	h.skipped = false
+	h.stats = HybridStats{}
+	return nil
+}
+
+// Close 释放检测上下文
+func (h *HybridDetector) Close() error {
+	return h.dc.Close()
+}
@@ -0,0 +1,98 @@
+package speech
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHybridDetector(t *testing.T) {
+	sm := newTestSharedModel(t)
+	samples := readTestSamples(t, "../testfiles/samples.pcm")
+
+	dc := sm.NewContext()
+	expected, err := dc.Detect(samples)
+	require.NoError(t, err)
+	require.NoError(t, dc.Close())
+	require.NotEmpty(t, expected)
+
+	_, err = NewHybridDetector(nil, HybridConfig{})
+	require.Error(t, err)
+	for _, bad := range []HybridConfig{
+		{GateBelow: 1},
+		{VetoBelow: -0.1},
+		{Fusion: FusionWeighted + 1},
+		{EnergyWeight: 2},
+		{Energy: EnergyConfig{MarginDB: -1}},
+	} {
+		_, err = NewHybridDetector(sm, bad)
+		require.Error(t, err)
+	}
+
+	h, err := NewHybridDetector(sm, HybridConfig{})
+	require.NoError(t, err)
+	defer h.Close()
+
+	// 片段与只用模型时基本一致，静音期间跳过了模型
+	segments, err := h.Detect(samples)
+	require.NoError(t, err)
+	require.Len(t, segments, len(expected))
+	for i := range segments {
+		require.InDelta(t, expected[i].SpeechStartAt, segments[i].SpeechStartAt, 0.07)
+		require.InDelta(t, expected[i].SpeechEndAt, segments[i].SpeechEndAt, 0.07)
+	}
+	stats := h.Stats()
+	require.Equal(t, int64(len(samples)/512), stats.Windows)
+	require.Less(t, stats.ModelWindows, stats.Windows)
+	require.Equal(t, uint64(stats.ModelWindows), h.Context().RTF().Windows)
+
+	// 流式检测与整段检测一致
+	require.NoError(t, h.Reset())
+	require.Zero(t, h.Stats().Windows)
+	chunker, err := NewStreamChunker(16000)
+	require.NoError(t, err)
+	var streamed []Segment
+	for off := 0; off < len(samples); off += 320 {
+		chunker.Write(samples[off:min(off+320, len(samples))])
+		segs, err := h.DetectChunks(chunker)
+		require.NoError(t, err)
+		for _, seg := range segs {
+			if n := len(streamed); n > 0 && streamed[n-1].SpeechStartAt == seg.SpeechStartAt {
+				streamed[n-1] = seg
+			} else {
+				streamed = append(streamed, seg)
+			}
+		}
+	}
+	require.Equal(t, segments, streamed)
+
+	// 静音不调用模型
+	speech, err := h.IsSpeech(make([]float32, 16000))
+	require.NoError(t, err)
+	require.False(t, speech)
+	require.Zero(t, h.Stats().ModelWindows)
+	speech, err = h.IsSpeech(samples)
+	require.NoError(t, err)
+	require.True(t, speech)
+
+	// 取较小值的融合更严格，语音总时长不会更长
+	strict, err := NewHybridDetector(sm, HybridConfig{Fusion: FusionMin})
+	require.NoError(t, err)
+	defer strict.Close()
+	strictSegments, err := strict.Detect(samples)
+	require.NoError(t, err)
+	require.NotEmpty(t, strictSegments)
+	total := func(segs []Segment) float64 {
+		var d float64
+		for _, seg := range segs {
+			end := seg.SpeechEndAt
+			if end == 0 {
+				end = float64(len(samples)) / 16000
+			}
+			d += end - seg.SpeechStartAt
+		}
+		return d
+	}
+	require.LessOrEqual(t, total(strictSegments), total(segments)+0.07)
+	require.Equal(t, "min", FusionMin.String())
+}
@@ -30,7 +30,7 @@ func (sd *Detector) Infer(samples []float32) (float32, error) {
 	status := C.OrtApiCreateTensorWithDataAsOrtValue(sd.api, sd.memoryInfo, unsafe.Pointer(&pcm[0]), C.size_t(len(pcm)*4), &pcmInputDims[0], C.size_t(len(pcmInputDims)), C.ONNX_TENSOR_ELEMENT_DATA_TYPE_FLOAT, &pcmValue)
 	defer C.OrtApiReleaseStatus(sd.api, status)
 	if status != nil {
-		return 0, fmt.Errorf("failed to create value: %s", C.GoString(C.OrtApiGetErrorMessage(sd.api, status)))
+		return 0, fmt.Errorf("failed to create value: %w", newOrtError(sd.api, status))
 	}
 	defer C.OrtApiReleaseValue(sd.api, pcmValue)
 
@@ -39,7 +39,7 @@ func (sd *Detector) Infer(samples []float32) (float32, error) {
 	status = C.OrtApiCreateTensorWithDataAsOrtValue(sd.api, sd.memoryInfo, unsafe.Pointer(&sd.state[0]), C.size_t(stateLen*4), &stateNodeInputDims[0], C.size_t(len(stateNodeInputDims)), C.ONNX_TENSOR_ELEMENT_DATA_TYPE_FLOAT, &stateValue)
 	defer C.OrtApiReleaseStatus(sd.api, status)
 	if status != nil {
-		return 0, fmt.Errorf("failed to create value: %s", C.GoString(C.OrtApiGetErrorMessage(sd.api, status)))
+		return 0, fmt.Errorf("failed to create value: %w", newOrtError(sd.api, status))
 	}
 	defer C.OrtApiReleaseValue(sd.api, stateValue)
 
@@ -49,7 +49,7 @@ func (sd *Detector) Infer(samples []float32) (float32, error) {
 	status = C.OrtApiCreateTensorWithDataAsOrtValue(sd.api, sd.memoryInfo, unsafe.Pointer(&rate[0]), C.size_t(8), &rateInputDims[0], C.size_t(len(rateInputDims)), C.ONNX_TENSOR_ELEMENT_DATA_TYPE_INT64, &rateValue)
 	defer C.OrtApiReleaseStatus(sd.api, status)
 	if status != nil {
-		return 0, fmt.Errorf("failed to create value: %s", C.GoString(C.OrtApiGetErrorMessage(sd.api, status)))
+		return 0, fmt.Errorf("failed to create value: %w", newOrtError(sd.api, status))
 	}
 	defer C.OrtApiReleaseValue(sd.api, rateValue)
 
@@ -69,7 +69,7 @@ func (sd *Detector) Infer(samples []float32) (float32, error) {
 	status = C.OrtApiRun(sd.api, sd.session, nil, &inputNames[0], &inputs[0], C.size_t(len(inputNames)), &outputNames[0], C.size_t(len(outputNames)), &outputs[0])
 	defer C.OrtApiReleaseStatus(sd.api, status)
 	if status != nil {
-		return 0, fmt.Errorf("failed to run: %s", C.GoString(C.OrtApiGetErrorMessage(sd.api, status)))
+		return 0, fmt.Errorf("failed to run: %w", newOrtError(sd.api, status))
 	}
 
 	// Get output values from tensor data
@@ -79,20 +79,23 @@ func (sd *Detector) Infer(samples []float32) (float32, error) {
 	status = C.OrtApiGetTensorMutableData(sd.api, outputs[0], &prob)
 	defer C.OrtApiReleaseStatus(sd.api, status)
 	if status != nil {
-		return 0, fmt.Errorf("failed to get tensor data: %s", C.GoString(C.OrtApiGetErrorMessage(sd.api, status)))
+		return 0, fmt.Errorf("failed to get tensor data: %w", newOrtError(sd.api, status))
 	}
 
 	status = C.OrtApiGetTensorMutableData(sd.api, outputs[1], &stateN)
 	defer C.OrtApiReleaseStatus(sd.api, status)
 	if status != nil {
-		return 0, fmt.Errorf("failed to get tensor data: %s", C.GoString(C.OrtApiGetErrorMessage(sd.api, status)))
+		return 0, fmt.Errorf("failed to get tensor data: %w", newOrtError(sd.api, status))
 	}
 
 	C.memcpy(unsafe.Pointer(&sd.state[0]), stateN, stateLen*4)
 
+	// Read the probability before the output tensor is released
+	speechProb := *(*float32)(prob)
+
 	C.OrtApiReleaseValue(sd.api, outputs[0])
 	C.OrtApiReleaseValue(sd.api, outputs[1])
 
 	// Return speech probability
-	return *(*float32)(prob), nil
+	return speechProb, nil
 }
@@ -0,0 +1,280 @@
+package speech
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FieldError 配置文件中某个字段的值无效
+type FieldError struct {
+	// 字段在配置文件中的键，嵌套字段以点分隔，例如 duty_cycle.wake_above
+	Field string
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("field %s: %v", e.Field, e.Err)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// LoadConfig 从 JSON 或 YAML 文件加载检测配置，便于把 VAD 参数放在服务的配置文件中而无需重新编译
+// 键名为 snake_case，例如：
+//
+//	model_path: ${VAD_MODEL_DIR}/silero_vad.onnx
+//	threshold: 0.6
+//	min_silence_duration_ms: 300
+//	max_batch_delay: 10ms
+//	log_level: warn
+//	execution_provider:
+//	  name: cuda
+//	  options: {device_id: "0"}
+//
+// 值中的 $VAR 和 ${VAR} 在解析后替换为环境变量，${VAR:-default} 在变量未设置或为空时使用 default，$$ 表示 $ 本身，
+// 其它 $ 原样保留。替换只作用于单个标量值，变量中的换行和冒号不会产生新的键；键名和注释不做替换。
+// 未出现的字段使用 DefaultConfig 的默认值；未知字段和类型不符的值返回错误，校验失败时返回的错误包含 *FieldError，指出是哪个字段。
+// JSON 作为 YAML 的子集解析，时长字段可以写作 "10ms" 这样的字符串。
+func LoadConfig(path string) (DetectorConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return DetectorConfig{}, fmt.Errorf("failed to read config: %w", err)
+	}
+	cfg, err := ParseConfig(data)
+	if err != nil {
+		return DetectorConfig{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// ParseConfig 解析 JSON 或 YAML 格式的检测配置，规则同 LoadConfig
+func ParseConfig(data []byte) (DetectorConfig, error) {
+	cfg := DefaultConfig("")
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return DetectorConfig{}, fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+	}
+	if len(doc.Content) > 0 {
+		root := doc.Content[0]
+		expandEnvNodes(root)
+		if err := checkConfigNode(root, reflect.TypeOf(cfg), ""); err != nil {
+			return DetectorConfig{}, fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+		}
+		if err := root.Decode(&cfg); err != nil {
+			return DetectorConfig{}, fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+		}
+	}
+	if err := cfg.IsValid(); err != nil {
+		if field := configField(err); field != "" {
+			err = &FieldError{Field: field, Err: err}
+		}
+		return DetectorConfig{}, fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+	}
+	return cfg, nil
+}
+
+// envPattern 匹配 $$、${VAR}、${VAR:-default} 和 $VAR
+var envPattern = regexp.MustCompile(`\$(?:\$|\{([A-Za-z_][A-Za-z0-9_]*)(:-[^}]*)?\}|([A-Za-z_][A-Za-z0-9_]*))`)
+
+// expandEnv 替换 s 中的环境变量，支持 ${VAR:-default} 和 $$，不构成变量引用的 $ 原样保留
+func expandEnv(s string) string {
+	return envPattern.ReplaceAllStringFunc(s, func(ref string) string {
+		if ref == "$$" {
+			return "$"
+		}
+		m := envPattern.FindStringSubmatch(ref)
+		if m[3] != "" {
+			return os.Getenv(m[3])
+		}
+		if v := os.Getenv(m[1]); v != "" || m[2] == "" {
+			return v
+		}
+		return m[2][len(":-"):]
+	})
+}
+
+// expandEnvNodes 替换 n 中各标量值的环境变量，映射的键保持不变
+// 未加引号的值替换后重新推断类型，使 threshold: ${VAD_THRESHOLD} 仍按数值解析。
+func expandEnvNodes(n *yaml.Node) {
+	switch n.Kind {
+	case yaml.ScalarNode:
+		v := expandEnv(n.Value)
+		if v == n.Value {
+			return
+		}
+		n.Value = v
+		if n.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle|yaml.LiteralStyle|yaml.FoldedStyle) == 0 {
+			n.Tag = ""
+			n.Tag = n.ShortTag()
+		}
+	case yaml.MappingNode:
+		for i := 1; i < len(n.Content); i += 2 {
+			expandEnvNodes(n.Content[i])
+		}
+	default:
+		for _, c := range n.Content {
+			expandEnvNodes(c)
+		}
+	}
+}
+
+// checkConfigNode 拒绝配置中的未知字段，并把时长字段中的整数改写为纳秒字符串
+// encoding/json 把 time.Duration 序列化为纳秒数，yaml 只接受 "10ms" 这样的字符串，两者都应能加载。
+func checkConfigNode(n *yaml.Node, t reflect.Type, prefix string) error {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == reflect.TypeOf(time.Duration(0)) {
+		if n.Kind == yaml.ScalarNode && n.Tag == "!!int" {
+			n.Value += "ns"
+			n.Tag = "!!str"
+		}
+		return nil
+	}
+	if t.Kind() != reflect.Struct || n.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		key := n.Content[i]
+		f, ok := fieldByKey(t, key.Value)
+		if !ok {
+			return fmt.Errorf("line %d: unknown field %s%s", key.Line, prefix, key.Value)
+		}
+		if err := checkConfigNode(n.Content[i+1], f.Type, prefix+key.Value+"."); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fieldByKey 返回 yaml 键为 key 的字段
+func fieldByKey(t reflect.Type, key string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if name, _, _ := strings.Cut(f.Tag.Get("yaml"), ","); name == key {
+			return f, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// invalidFieldPattern 匹配校验错误中的字段名，IsValid 的错误均以 "invalid <字段>" 开头，嵌套字段以点分隔
+var invalidFieldPattern = regexp.MustCompile(`invalid ([A-Z][A-Za-z]*(?:\.[A-Z][A-Za-z]*)*)`)
+
+// configField 把校验错误中的 Go 字段名换算为配置文件中的键，无法确定时返回空字符串
+func configField(err error) string {
+	m := invalidFieldPattern.FindStringSubmatch(err.Error())
+	if m == nil {
+		return ""
+	}
+	t := reflect.TypeOf(DetectorConfig{})
+	var keys []string
+	for _, name := range strings.Split(m[1], ".") {
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct {
+			break
+		}
+		f, ok := t.FieldByName(name)
+		if !ok {
+			break
+		}
+		key, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if key == "" {
+			break
+		}
+		keys = append(keys, key)
+		t = f.Type
+	}
+	return strings.Join(keys, ".")
+}
+
+// logLevelNames 配置文件中日志级别的名称
+var logLevelNames = map[LogLevel]string{
+	LevelVerbose:  "verbose",
+	LogLevelInfo:  "info",
+	LogLevelWarn:  "warn",
+	LogLevelError: "error",
+	LogLevelFatal: "fatal",
+}
+
+// String 返回日志级别的名称，零值（默认级别）为空字符串
+func (l LogLevel) String() string {
+	if name, ok := logLevelNames[l]; ok {
+		return name
+	}
+	if l == 0 {
+		return ""
+	}
+	return strconv.Itoa(int(l))
+}
+
+// MarshalText 以名称序列化日志级别
+func (l LogLevel) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+// UnmarshalText 解析日志级别的名称或数值
+func (l *LogLevel) UnmarshalText(text []byte) error {
+	s := string(text)
+	for level, name := range logLevelNames {
+		if strings.EqualFold(s, name) {
+			*l = level
+			return nil
+		}
+	}
+	if s == "" {
+		*l = 0
+		return nil
+	}
+	if n, err := strconv.Atoi(s); err == nil {
+		*l = LogLevel(n)
+		return nil
+	}
+	return fmt.Errorf("invalid LogLevel %q: valid values are verbose, info, warn, error and fatal", s)
+}
+
+// inputCheckNames 配置文件中输入检查方式的名称
+var inputCheckNames = []string{
+	InputCheckNone:     "none",
+	InputCheckReject:   "reject",
+	InputCheckSanitize: "sanitize",
+}
+
+// String 返回输入检查方式的名称
+func (c InputCheck) String() string {
+	if c >= 0 && int(c) < len(inputCheckNames) {
+		return inputCheckNames[c]
+	}
+	return strconv.Itoa(int(c))
+}
+
+// MarshalText 以名称序列化输入检查方式
+func (c InputCheck) MarshalText() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+// UnmarshalText 解析输入检查方式的名称或数值
+func (c *InputCheck) UnmarshalText(text []byte) error {
+	s := string(text)
+	for i, name := range inputCheckNames {
+		if strings.EqualFold(s, name) {
+			*c = InputCheck(i)
+			return nil
+		}
+	}
+	if n, err := strconv.Atoi(s); err == nil {
+		*c = InputCheck(n)
+		return nil
+	}
+	return fmt.Errorf("invalid InputCheck %q: valid values are none, reject and sanitize", s)
+}
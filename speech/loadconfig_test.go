@@ -0,0 +1,127 @@
+package speech
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("VAD_MODEL_DIR", "../testfiles")
+	t.Setenv("VAD_THRESHOLD", "")
+
+	yamlPath := filepath.Join(dir, "vad.yaml")
+	require.NoError(t, os.WriteFile(yamlPath, []byte(`
+model_path: ${VAD_MODEL_DIR}/silero_vad.onnx
+threshold: ${VAD_THRESHOLD:-0.6}
+min_silence_duration_ms: 300
+max_batch_size: 8
+max_batch_delay: 10ms
+log_level: error
+input_check: sanitize
+agc:
+  target_db: -20
+duty_cycle:
+  wake_above: 0.4
+  hangover: 1s
+execution_provider:
+  name: cuda
+  options: {device_id: "1"}
+`), 0o644))
+	cfg, err := LoadConfig(yamlPath)
+	require.NoError(t, err)
+	require.Equal(t, "../testfiles/silero_vad.onnx", cfg.ModelPath)
+	require.Equal(t, float32(0.6), cfg.Threshold)
+	require.Equal(t, 300, cfg.MinSilenceDurationMs)
+	require.Equal(t, DefaultSpeechPadMs, cfg.SpeechPadMs)
+	require.Equal(t, 10*time.Millisecond, cfg.MaxBatchDelay)
+	require.Equal(t, LogLevelError, cfg.LogLevel)
+	require.Equal(t, InputCheckSanitize, cfg.InputCheck)
+	require.Equal(t, -20.0, cfg.AGC.TargetDB)
+	require.Equal(t, float32(0.4), cfg.DutyCycle.WakeAbove)
+	require.Equal(t, time.Second, cfg.DutyCycle.Hangover)
+	require.Equal(t, CUDA(1), cfg.ExecutionProvider)
+
+	// JSON 与 YAML 使用相同的键，序列化的配置可以重新加载
+	data, err := json.Marshal(DefaultConfig("../testfiles/silero_vad.onnx"))
+	require.NoError(t, err)
+	jsonPath := filepath.Join(dir, "vad.json")
+	require.NoError(t, os.WriteFile(jsonPath, data, 0o644))
+	cfg, err = LoadConfig(jsonPath)
+	require.NoError(t, err)
+	require.Equal(t, DefaultConfig("../testfiles/silero_vad.onnx"), cfg)
+
+	// 校验错误指出字段
+	_, err = ParseConfig([]byte(`{"model_path": "m.onnx", "duty_cycle": {"wake_above": 2}}`))
+	require.ErrorIs(t, err, ErrInvalidConfig)
+	var fieldErr *FieldError
+	require.ErrorAs(t, err, &fieldErr)
+	require.Equal(t, "duty_cycle.wake_above", fieldErr.Field)
+
+	_, err = ParseConfig([]byte("model_path: m.onnx\nthreshold: 1.5\n"))
+	require.ErrorAs(t, err, &fieldErr)
+	require.Equal(t, "threshold", fieldErr.Field)
+
+	_, err = ParseConfig([]byte("threshold: 0.5\n"))
+	require.ErrorAs(t, err, &fieldErr)
+	require.Equal(t, "model_path", fieldErr.Field)
+
+	// 未知字段和类型错误
+	_, err = ParseConfig([]byte("model_path: m.onnx\nthreshhold: 0.5\n"))
+	require.ErrorContains(t, err, "threshhold")
+	_, err = ParseConfig([]byte("model_path: m.onnx\nlog_level: loud\n"))
+	require.ErrorContains(t, err, "loud")
+
+	_, err = LoadConfig(filepath.Join(dir, "missing.yaml"))
+	require.Error(t, err)
+}
+
+func TestParseConfigEnv(t *testing.T) {
+	// 变量只替换单个值，不能注入新的键
+	t.Setenv("VAD_MODEL", "m.onnx\nthreshold: 0.9")
+	cfg, err := ParseConfig([]byte("model_path: ${VAD_MODEL}\n"))
+	require.NoError(t, err)
+	require.Equal(t, "m.onnx\nthreshold: 0.9", cfg.ModelPath)
+	require.Equal(t, float32(DefaultThreshold), cfg.Threshold)
+
+	t.Setenv("VAD_MODEL", "m.onnx, threshold: 0.9")
+	cfg, err = ParseConfig([]byte(`{"model_path": "${VAD_MODEL}"}`))
+	require.NoError(t, err)
+	require.Equal(t, "m.onnx, threshold: 0.9", cfg.ModelPath)
+	require.Equal(t, float32(DefaultThreshold), cfg.Threshold)
+
+	// 不构成变量引用的 $ 原样保留，$$ 表示 $
+	t.Setenv("VAD_DIR", "/models")
+	cfg, err = ParseConfig([]byte("model_path: \"$VAD_DIR/a$-b/$$VAD_DIR/${x/c$\"\n"))
+	require.NoError(t, err)
+	require.Equal(t, "/models/a$-b/$VAD_DIR/${x/c$", cfg.ModelPath)
+
+	// 未加引号的值替换后按目标类型解析，包括以纳秒数表示的时长
+	t.Setenv("VAD_THRESHOLD", "0.7")
+	t.Setenv("VAD_DELAY", "10000000")
+	cfg, err = ParseConfig([]byte("model_path: m.onnx\nthreshold: ${VAD_THRESHOLD}\nmax_batch_size: 2\nmax_batch_delay: $VAD_DELAY\n"))
+	require.NoError(t, err)
+	require.Equal(t, float32(0.7), cfg.Threshold)
+	require.Equal(t, 10*time.Millisecond, cfg.MaxBatchDelay)
+}
+
+func TestLoadConfigEnums(t *testing.T) {
+	cfg, err := ParseConfig([]byte("model_path: m.onnx\nlog_level: 3\ninput_check: 1\n"))
+	require.NoError(t, err)
+	require.Equal(t, LogLevelWarn, cfg.LogLevel)
+	require.Equal(t, InputCheckReject, cfg.InputCheck)
+
+	_, err = ParseConfig([]byte("model_path: m.onnx\nagc:\n  target_db: -20\n  gain: 3\n"))
+	require.ErrorContains(t, err, "line 4: unknown field agc.gain")
+
+	_, err = ParseConfig(nil)
+	require.Error(t, err)
+	cfg, err = ParseConfig([]byte("model_path: $$HOME\n"))
+	require.NoError(t, err)
+	require.Equal(t, "$HOME", cfg.ModelPath)
+}
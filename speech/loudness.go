@@ -0,0 +1,96 @@
+package speech
+
+import (
+	"math"
+
+	"github.com/rui-yang-me/silero-vad-go/audio"
+)
+
+const (
+	// loudnessBlockWindows 近似 BS.1770 400ms 门限块的窗口数（每个窗口 32ms）
+	loudnessBlockWindows = 12
+	// loudnessBlockStep 相邻门限块间隔的窗口数，约 75% 重叠
+	loudnessBlockStep = 3
+	// minLoudness 报告的最低响度，等于 BS.1770 的绝对门限
+	minLoudness = -70
+	// minRMS 报告的最低 RMS 电平（dBFS）
+	minRMS = -100
+)
+
+// loudnessWindow 一个窗口的 K 计权均方值和均方值
+type loudnessWindow struct {
+	weighted float64
+	raw      float64
+}
+
+// loudnessTracker 在检测过程中测量当前片段的响度，不需要再遍历一次音频
+// 片段开始后逐窗口记录 K 计权均方值，片段结束时只统计到静音开始之前的窗口，
+// 按 12 个窗口（约 400ms）一块、每 3 个窗口一步做 BS.1770 的门限积分；不足一块的片段不做门限。
+type loudnessTracker struct {
+	filter   *audio.KWeighting
+	rate     int
+	buf      []float32
+	start    int              // 当前片段第一个窗口的采样位置
+	windows  []loudnessWindow // 当前片段各窗口的统计
+	blockBuf []float64
+}
+
+// observe 对一个窗口做 K 计权并在片段进行中记录，pos 为窗口的起始采样位置
+func (t *loudnessTracker) observe(cfg *DetectorConfig, window []float32, pos int, triggered bool) {
+	if t.filter == nil || t.rate != cfg.SampleRate {
+		// SampleRate 是固定字段，这里只在第一次使用时创建
+		t.filter, _ = audio.NewKWeighting(cfg.SampleRate)
+		t.rate = cfg.SampleRate
+	}
+	t.buf = t.filter.Process(t.buf[:0], window)
+	if !triggered {
+		return
+	}
+	if len(t.windows) == 0 {
+		t.start = pos
+	}
+	t.windows = append(t.windows, loudnessWindow{
+		weighted: windowPower(t.buf),
+		raw:      windowPower(window),
+	})
+}
+
+// finish 返回片段在 end（静音开始的采样位置）之前的响度（LUFS）和 RMS 电平（dBFS），并开始统计下一个片段
+func (t *loudnessTracker) finish(end, windowSize int) (float64, float64) {
+	windows := t.windows[:min(max((end-t.start)/windowSize, 1), len(t.windows))]
+	defer func() {
+		t.windows = t.windows[:0]
+	}()
+	if len(windows) == 0 {
+		return minLoudness, minRMS
+	}
+
+	var weighted, raw float64
+	for _, w := range windows {
+		weighted += w.weighted
+		raw += w.raw
+	}
+	loudness := audio.Loudness(weighted / float64(len(windows)))
+	if len(windows) >= loudnessBlockWindows {
+		t.blockBuf = t.blockBuf[:0]
+		for i := 0; i+loudnessBlockWindows <= len(windows); i += loudnessBlockStep {
+			var sum float64
+			for _, w := range windows[i : i+loudnessBlockWindows] {
+				sum += w.weighted
+			}
+			t.blockBuf = append(t.blockBuf, sum/loudnessBlockWindows)
+		}
+		loudness = audio.GatedLoudness(t.blockBuf)
+	}
+	rms := 10 * math.Log10(raw/float64(len(windows)))
+	return max(loudness, minLoudness), max(rms, minRMS)
+}
+
+// reset 清空统计和滤波器状态
+func (t *loudnessTracker) reset() {
+	if t.filter != nil {
+		t.filter.Reset()
+	}
+	t.windows = t.windows[:0]
+	t.start = 0
+}
@@ -0,0 +1,87 @@
+package speech
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMeasureLoudness(t *testing.T) {
+	sm := newTestSharedModel(t)
+	samples := readTestSamples(t, "../testfiles/samples.pcm")
+
+	dc := sm.NewContext()
+	defer dc.Close()
+	plain, err := dc.Detect(samples)
+	require.NoError(t, err)
+	for _, seg := range plain {
+		require.Zero(t, seg.Loudness)
+		require.Zero(t, seg.RMS)
+	}
+
+	require.NoError(t, dc.WithConfig(func(cfg *DetectorConfig) {
+		cfg.MeasureLoudness = true
+	}))
+	require.NoError(t, dc.Reset())
+	segments, err := dc.Detect(samples)
+	require.NoError(t, err)
+	require.Len(t, segments, len(plain))
+	for i, seg := range segments {
+		require.Equal(t, plain[i].SpeechStartAt, seg.SpeechStartAt)
+		if seg.SpeechEndAt == 0 {
+			require.Zero(t, seg.Loudness)
+			continue
+		}
+		require.Greater(t, seg.Loudness, float64(minLoudness))
+		require.Negative(t, seg.Loudness)
+		require.Greater(t, seg.RMS, float64(minRMS))
+		require.Negative(t, seg.RMS)
+	}
+
+	// 音量降低 20dB 后响度和 RMS 相应降低，片段边界略有不同
+	quiet := make([]float32, len(samples))
+	for i, v := range samples {
+		quiet[i] = v * 0.1
+	}
+	require.NoError(t, dc.Reset())
+	quietSegments, err := dc.Detect(quiet)
+	require.NoError(t, err)
+	require.Len(t, quietSegments, len(segments))
+	for i, seg := range quietSegments {
+		if seg.SpeechEndAt != 0 {
+			require.InDelta(t, segments[i].Loudness-20, seg.Loudness, 2)
+			require.InDelta(t, segments[i].RMS-20, seg.RMS, 2)
+		}
+	}
+}
+
+func TestLoudnessTracker(t *testing.T) {
+	cfg := &DetectorConfig{SampleRate: 16000}
+	var tr loudnessTracker
+
+	// 满幅 997Hz 正弦：-3.01 LUFS，RMS -3.01dBFS；之后的静音窗口不计入
+	sine := make([]float32, 16000)
+	for i := range sine {
+		sine[i] = float32(math.Sin(2 * math.Pi * 997 * float64(i) / 16000))
+	}
+	pos := 0
+	for ; pos+512 <= len(sine); pos += 512 {
+		tr.observe(cfg, sine[pos:pos+512], pos, true)
+	}
+	end := pos
+	for i := 0; i < 5; i++ {
+		tr.observe(cfg, make([]float32, 512), pos, true)
+		pos += 512
+	}
+	loudness, rms := tr.finish(end, 512)
+	require.InDelta(t, -3.01, loudness, 0.2)
+	require.InDelta(t, -3.01, rms, 0.05)
+
+	// 数字静音
+	tr.reset()
+	tr.observe(cfg, make([]float32, 512), 0, true)
+	loudness, rms = tr.finish(512, 512)
+	require.Equal(t, float64(minLoudness), loudness)
+	require.Equal(t, float64(minRMS), rms)
+}
@@ -0,0 +1,65 @@
+package speech
+
+import (
+	"os"
+	"sync/atomic"
+)
+
+// MemStats 原生内存的使用情况
+// 本包的大部分内存（ORT 会话、权重、arena 和张量）分配在 C 堆上，对 Go 的 heap profile 和
+// runtime.MemStats 不可见。C 堆是进程级的，其它 cgo 库的分配也会计入 InUse 和 Reserved。
+// ORT 的 CPU arena 从 C 堆分配，因此包含在内；ORT 自身的分配器统计（AllocatorGetStats）需要 ONNX Runtime 1.23，
+// 本包支持的最低版本没有该接口。Linux 上需要 glibc，musl 等 C 库的 InUse、Reserved 和 PeakInUse 为 0。
+type MemStats struct {
+	// C 堆当前分配出去的字节数，包括 ORT 的 CPU arena
+	InUse uint64
+	// C 堆从系统申请的字节数（含空闲块），对应进程 RSS 中的原生内存部分
+	Reserved uint64
+	// InUse 的峰值：darwin 上由系统统计，其它平台为历次 MemStats 调用和模型创建时观测到的最大值
+	PeakInUse uint64
+	// 该模型加载的 ONNX 会话数（含降噪模型），每个会话各持有一份权重
+	Sessions int
+	// 该模型所有会话的模型文件字节数之和，近似权重占用的内存
+	ModelBytes int64
+}
+
+// nativePeak 非 darwin 平台上观测到的 C 堆占用峰值
+var nativePeak atomic.Uint64
+
+// observeHeap 读取 C 堆统计并更新观测到的峰值
+func observeHeap() (inUse, reserved, peak uint64) {
+	inUse, reserved, peak = cHeapStats()
+	for {
+		old := nativePeak.Load()
+		if max(inUse, peak) <= old || nativePeak.CompareAndSwap(old, max(inUse, peak)) {
+			break
+		}
+	}
+	return inUse, reserved, nativePeak.Load()
+}
+
+// MemStats 返回原生内存的使用情况，用于评估容器的内存配额
+// 可以在任意协程中调用，开销为一次 C 调用。
+func (sm *SharedModel) MemStats() MemStats {
+	inUse, reserved, peak := observeHeap()
+	stats := MemStats{
+		InUse:      inUse,
+		Reserved:   reserved,
+		PeakInUse:  peak,
+		Sessions:   len(sm.sessions),
+		ModelBytes: sm.modelBytes,
+	}
+	if sm.denoiser != nil {
+		stats.Sessions++
+	}
+	return stats
+}
+
+// modelFileSize 返回模型文件的字节数，无法获取时返回 0
+func modelFileSize(path string) int64 {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	return fi.Size()
+}
@@ -0,0 +1,11 @@
+package speech
+
+// #include <malloc/malloc.h>
+import "C"
+
+// cHeapStats 通过 malloc_zone_statistics 读取所有 malloc zone 的统计
+func cHeapStats() (inUse, reserved, peak uint64) {
+	var st C.malloc_statistics_t
+	C.malloc_zone_statistics(nil, &st)
+	return uint64(st.size_in_use), uint64(st.size_allocated), uint64(st.max_size_in_use)
+}
@@ -0,0 +1,45 @@
+package speech
+
+// #cgo LDFLAGS: -ldl
+// #define _GNU_SOURCE
+// #include <dlfcn.h>
+// #include <stddef.h>
+//
+// // 与 glibc 的 struct mallinfo2 和 struct mallinfo 布局相同。这里自行声明并在运行时查找函数，
+// // 不依赖只有 glibc 2.33 及以上才声明 mallinfo2 的 malloc.h，本包因此也能在旧版 glibc 和 musl 上编译
+// typedef struct {
+// 	size_t arena, ordblks, smblks, hblks, hblkhd, usmblks, fsmblks, uordblks, fordblks, keepcost;
+// } vad_mallinfo2;
+// typedef struct {
+// 	int arena, ordblks, smblks, hblks, hblkhd, usmblks, fsmblks, uordblks, fordblks, keepcost;
+// } vad_mallinfo;
+//
+// static int vad_heap_stats(size_t *in_use, size_t *reserved) {
+// 	void *f = dlsym(RTLD_DEFAULT, "mallinfo2");
+// 	if (f != NULL) {
+// 		vad_mallinfo2 mi = ((vad_mallinfo2 (*)(void))f)();
+// 		*in_use = mi.uordblks + mi.hblkhd;
+// 		*reserved = mi.arena + mi.hblkhd;
+// 		return 1;
+// 	}
+// 	// glibc 2.33 之前只有 mallinfo，字段为 int，超过 2GB 后按无符号数解释，4GB 以上会回绕
+// 	f = dlsym(RTLD_DEFAULT, "mallinfo");
+// 	if (f != NULL) {
+// 		vad_mallinfo mi = ((vad_mallinfo (*)(void))f)();
+// 		*in_use = (size_t)(unsigned)mi.uordblks + (size_t)(unsigned)mi.hblkhd;
+// 		*reserved = (size_t)(unsigned)mi.arena + (size_t)(unsigned)mi.hblkhd;
+// 		return 1;
+// 	}
+// 	return 0;
+// }
+import "C"
+
+// cHeapStats 读取 glibc 的 C 堆统计，优先使用 mallinfo2，旧版 glibc 使用 mallinfo；
+// musl 等不提供这两个函数的 C 库总是返回 0。glibc 不提供峰值
+func cHeapStats() (inUse, reserved, peak uint64) {
+	var used, res C.size_t
+	if C.vad_heap_stats(&used, &res) == 0 {
+		return 0, 0, 0
+	}
+	return uint64(used), uint64(res), 0
+}
@@ -0,0 +1,8 @@
+//go:build !linux && !darwin
+
+package speech
+
+// cHeapStats 当前平台无法读取 C 堆统计，总是返回 0
+func cHeapStats() (inUse, reserved, peak uint64) {
+	return 0, 0, 0
+}
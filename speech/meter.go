@@ -0,0 +1,121 @@
+package speech
+
+import (
+	"fmt"
+	"math"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// defaultMeterAttack 未设置 MeterConfig.Attack 时上升的时间常数
+	defaultMeterAttack = 30 * time.Millisecond
+	// defaultMeterRelease 未设置 MeterConfig.Release 时回落的时间常数
+	defaultMeterRelease = 300 * time.Millisecond
+)
+
+// MeterConfig Meter 的配置
+type MeterConfig struct {
+	// 开始说话时读数上升的时间常数，0 表示默认 30ms
+	Attack time.Duration
+	// 停止说话后读数回落的时间常数，0 表示默认 300ms；越长指示越平稳
+	Release time.Duration
+}
+
+// Meter 输出平滑后的 0–1 说话可能性，每个窗口更新一次，用于驱动麦克风活动指示等界面元素
+// 读数是语音概率经过快升慢降平滑后的值，不经过分段状态机，也不使用 Threshold 等分段参数。
+// Write 不是并发安全的，Level 可以在任意协程中随时读取。
+type Meter struct {
+	dc      *DetectorContext
+	chunker *StreamChunker
+	attack  float64 // 每个窗口的平滑系数
+	release float64
+	level   atomic.Uint32 // float32 的位模式
+}
+
+// NewMeter 创建基于 dc 的说话指示，音频格式约定同 NewStream
+// Meter 使用期间不要再直接调用 dc 的检测方法。
+func (dc *DetectorContext) NewMeter(cfg MeterConfig) (*Meter, error) {
+	if dc == nil || dc.model == nil {
+		return nil, fmt.Errorf("invalid nil detector context")
+	}
+	if cfg.Attack < 0 || cfg.Release < 0 {
+		return nil, fmt.Errorf("invalid meter attack/release: should not be negative")
+	}
+	if cfg.Attack == 0 {
+		cfg.Attack = defaultMeterAttack
+	}
+	if cfg.Release == 0 {
+		cfg.Release = defaultMeterRelease
+	}
+
+	sampleRate := dc.config().SampleRate
+	chunker, err := NewStreamChunker(sampleRate)
+	if err != nil {
+		return nil, err
+	}
+	window := float64(chunker.WindowSize()) / float64(sampleRate)
+
+	return &Meter{
+		dc:      dc,
+		chunker: chunker,
+		attack:  math.Exp(-window / cfg.Attack.Seconds()),
+		release: math.Exp(-window / cfg.Release.Seconds()),
+	}, nil
+}
+
+// Write 检测一块音频并返回更新后的读数，不足一个窗口的采样保留到下一次写入
+func (m *Meter) Write(pcm []float32) (float32, error) {
+	dc := m.dc
+	if err := dc.acquire(); err != nil {
+		return m.Level(), err
+	}
+	defer dc.release()
+
+	cfg := dc.callConfig()
+	pcm, err := dc.pre.resample(cfg, pcm)
+	if err != nil {
+		return m.Level(), err
+	}
+	m.chunker.Write(pcm)
+
+	level := float64(m.Level())
+	windowSize := m.chunker.WindowSize()
+	for {
+		frame, ok := m.chunker.Next()
+		if !ok {
+			break
+		}
+		// 与 detectFrame 相同，预处理可能一次输出零个或多个窗口
+		out, err := dc.pre.apply(cfg, frame)
+		if err != nil {
+			return float32(level), err
+		}
+		for i := 0; i+windowSize <= len(out); i += windowSize {
+			prob, err := dc.predict(cfg, out[i:i+windowSize])
+			if err != nil {
+				return float32(level), fmt.Errorf("infer failed: %w", err)
+			}
+			dc.currSample += windowSize
+
+			coef := m.release
+			if float64(prob) > level {
+				coef = m.attack
+			}
+			level = float64(prob) + coef*(level-float64(prob))
+			m.level.Store(math.Float32bits(float32(level)))
+		}
+	}
+	return float32(level), nil
+}
+
+// Level 返回最近的读数，范围 [0, 1]
+func (m *Meter) Level() float32 {
+	return math.Float32frombits(m.level.Load())
+}
+
+// Reset 读数归零并丢弃缓冲的音频；开始一路新的音频时还应调用上下文的 Reset
+func (m *Meter) Reset() {
+	m.chunker.Reset()
+	m.level.Store(0)
+}
@@ -0,0 +1,52 @@
+package speech
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMeter(t *testing.T) {
+	sm := newTestSharedModel(t)
+	samples := readTestSamples(t, "../testfiles/samples.pcm")
+
+	dc := sm.NewContext()
+	defer dc.Close()
+	segments, err := dc.Detect(samples)
+	require.NoError(t, err)
+	require.NotEmpty(t, segments)
+	require.NoError(t, dc.Reset())
+
+	_, err = dc.NewMeter(MeterConfig{Release: -time.Second})
+	require.Error(t, err)
+
+	m, err := dc.NewMeter(MeterConfig{})
+	require.NoError(t, err)
+	require.Zero(t, m.Level())
+
+	// 按 20ms 一块写入，记录每块之后的读数
+	levels := make([]float32, 0, len(samples)/320+1)
+	for off := 0; off < len(samples); off += 320 {
+		level, err := m.Write(samples[off:min(off+320, len(samples))])
+		require.NoError(t, err)
+		require.Equal(t, level, m.Level())
+		require.GreaterOrEqual(t, level, float32(0))
+		require.LessOrEqual(t, level, float32(1))
+		levels = append(levels, level)
+	}
+	at := func(sec float64) float32 {
+		return levels[min(int(sec*50), len(levels)-1)]
+	}
+
+	// 语音中读数高，语音开始之前读数低
+	first := segments[0]
+	require.Less(t, at(first.SpeechStartAt-0.3), float32(0.3))
+	require.Greater(t, at((first.SpeechStartAt+first.SpeechEndAt)/2), float32(0.5))
+
+	// 读数在语音结束后逐渐回落，而不是立即归零
+	require.Greater(t, at(first.SpeechEndAt+0.05), float32(0.1))
+
+	m.Reset()
+	require.Zero(t, m.Level())
+}
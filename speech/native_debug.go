@@ -0,0 +1,79 @@
+package speech
+
+import (
+	"log/slog"
+	"sync/atomic"
+)
+
+// nativeKind 标识一类由 ONNX Runtime 或 C 分配的原生资源
+type nativeKind int
+
+const (
+	nativeEnv nativeKind = iota
+	nativeSessionOptions
+	nativeSession
+	nativeMemoryInfo
+	nativeCString
+	nativeKindCount
+)
+
+func (k nativeKind) String() string {
+	switch k {
+	case nativeEnv:
+		return "env"
+	case nativeSessionOptions:
+		return "sessionOptions"
+	case nativeSession:
+		return "session"
+	case nativeMemoryInfo:
+		return "memoryInfo"
+	case nativeCString:
+		return "cString"
+	default:
+		return "unknown"
+	}
+}
+
+var (
+	nativeDebug atomic.Bool
+	nativeLive  [nativeKindCount]atomic.Int64
+)
+
+// SetNativeDebug 开启或关闭原生资源调试模式
+// 开启后每次分配和释放都会以 Debug 级别记录日志，便于定位未调用 Destroy 的实例。
+// 应在创建任何模型之前调用。
+func SetNativeDebug(enabled bool) {
+	nativeDebug.Store(enabled)
+}
+
+// NativeAllocations 返回当前仍存活的原生资源数量（按类型统计）
+func NativeAllocations() map[string]int64 {
+	stats := make(map[string]int64, nativeKindCount)
+	for k := nativeKind(0); k < nativeKindCount; k++ {
+		stats[k.String()] = nativeLive[k].Load()
+	}
+	return stats
+}
+
+// LiveNativeAllocations 返回当前仍存活的原生资源总数，正常退出前应为 0
+func LiveNativeAllocations() int64 {
+	var total int64
+	for k := nativeKind(0); k < nativeKindCount; k++ {
+		total += nativeLive[k].Load()
+	}
+	return total
+}
+
+func trackAlloc(kind nativeKind, n int) {
+	live := nativeLive[kind].Add(int64(n))
+	if nativeDebug.Load() {
+		slog.Debug("native alloc", slog.String("kind", kind.String()), slog.Int("n", n), slog.Int64("live", live))
+	}
+}
+
+func trackFree(kind nativeKind, n int) {
+	live := nativeLive[kind].Add(-int64(n))
+	if nativeDebug.Load() {
+		slog.Debug("native free", slog.String("kind", kind.String()), slog.Int("n", n), slog.Int64("live", live))
+	}
+}
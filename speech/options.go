@@ -0,0 +1,177 @@
+package speech
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/rui-yang-me/silero-vad-go/audio"
+)
+
+// Option 修改 DetectorConfig 的一个选项，供 New 和 BuildConfig 使用
+// 新增的配置项只会新增 Option，已有调用无需修改；需要序列化配置时使用 BuildConfig 得到 DetectorConfig。
+type Option func(cfg *DetectorConfig)
+
+// New 以推荐的默认值和给定选项创建共享模型，是创建模型的首选方式
+//
+//	model, err := speech.New("silero_vad.onnx",
+//		speech.WithThreshold(0.6),
+//		speech.WithSampleRate(8000),
+//		speech.WithEP(speech.CUDA(0)),
+//	)
+//
+// 选项按顺序作用于 DefaultConfig(modelPath)，后面的选项覆盖前面的。
+func New(modelPath string, opts ...Option) (*SharedModel, error) {
+	cfg, err := BuildConfig(modelPath, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return NewSharedModel(cfg)
+}
+
+// BuildConfig 返回 DefaultConfig(modelPath) 依次应用 opts 后的配置并校验
+func BuildConfig(modelPath string, opts ...Option) (DetectorConfig, error) {
+	cfg := DefaultConfig(modelPath)
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&cfg)
+		}
+	}
+	if err := cfg.IsValid(); err != nil {
+		return DetectorConfig{}, fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+	}
+	return cfg, nil
+}
+
+// WithConfigFrom 以 cfg 为基础，用于加载序列化保存的配置后再叠加其它选项
+// cfg.ModelPath 为空时保留 New 的 modelPath。
+func WithConfigFrom(cfg DetectorConfig) Option {
+	return func(c *DetectorConfig) {
+		if cfg.ModelPath == "" {
+			cfg.ModelPath = c.ModelPath
+		}
+		*c = cfg
+	}
+}
+
+// WithSampleRate 设置输入采样率，支持 8000 和 16000，启用 AutoResample 时可以是其它采样率
+func WithSampleRate(rate int) Option {
+	return func(cfg *DetectorConfig) { cfg.SampleRate = rate }
+}
+
+// WithAutoResample 设置是否把模型不支持的采样率在内部重采样
+func WithAutoResample(enabled bool) Option {
+	return func(cfg *DetectorConfig) { cfg.AutoResample = enabled }
+}
+
+// WithThreshold 设置语音概率阈值
+func WithThreshold(threshold float32) Option {
+	return func(cfg *DetectorConfig) { cfg.Threshold = threshold }
+}
+
+// WithMinSilence 设置判定语音结束所需的静音时长，精度为毫秒
+func WithMinSilence(d time.Duration) Option {
+	return func(cfg *DetectorConfig) { cfg.MinSilenceDurationMs = int(d.Milliseconds()) }
+}
+
+// WithSpeechPad 设置片段两端的填充时长，精度为毫秒
+func WithSpeechPad(d time.Duration) Option {
+	return func(cfg *DetectorConfig) { cfg.SpeechPadMs = int(d.Milliseconds()) }
+}
+
+// WithLogLevel 设置 ONNX Runtime 的日志级别
+func WithLogLevel(level LogLevel) Option {
+	return func(cfg *DetectorConfig) { cfg.LogLevel = level }
+}
+
+// WithEP 设置推理使用的执行提供者，例如 CUDA(0)
+func WithEP(ep ExecutionProvider) Option {
+	return func(cfg *DetectorConfig) { cfg.ExecutionProvider = ep }
+}
+
+// WithSessionPoolSize 设置会话池大小
+func WithSessionPoolSize(n int) Option {
+	return func(cfg *DetectorConfig) { cfg.SessionPoolSize = n }
+}
+
+// WithMaxConcurrentInferences 设置同时进行的推理数上限
+func WithMaxConcurrentInferences(n int) Option {
+	return func(cfg *DetectorConfig) { cfg.MaxConcurrentInferences = n }
+}
+
+// WithBatching 启用跨上下文的批量推理
+func WithBatching(maxSize int, maxDelay time.Duration) Option {
+	return func(cfg *DetectorConfig) {
+		cfg.MaxBatchSize = maxSize
+		cfg.MaxBatchDelay = maxDelay
+	}
+}
+
+// WithSessionCPUs 设置每个会话固定使用的 CPU
+func WithSessionCPUs(cpus [][]int) Option {
+	return func(cfg *DetectorConfig) { cfg.SessionCPUs = cpus }
+}
+
+// WithRecoverAfterFailures 设置连续推理失败多少次后重建会话，0 表示不重建
+func WithRecoverAfterFailures(n int) Option {
+	return func(cfg *DetectorConfig) { cfg.RecoverAfterFailures = n }
+}
+
+// WithDeterministic 设置是否启用确定性模式
+func WithDeterministic(enabled bool) Option {
+	return func(cfg *DetectorConfig) { cfg.Deterministic = enabled }
+}
+
+// WithPadShortInput 设置是否把不足一个窗口的输入补零后检测
+func WithPadShortInput(enabled bool) Option {
+	return func(cfg *DetectorConfig) { cfg.PadShortInput = enabled }
+}
+
+// WithHighPass 设置推理前高通滤波的截止频率，0 表示不滤波
+func WithHighPass(cutoffHz float64) Option {
+	return func(cfg *DetectorConfig) { cfg.HighPassCutoffHz = cutoffHz }
+}
+
+// WithDenoiser 设置推理前的降噪模型
+func WithDenoiser(d DenoiserConfig) Option {
+	return func(cfg *DetectorConfig) { cfg.Denoiser = &d }
+}
+
+// WithClassifier 设置与 VAD 概率融合的分类模型
+func WithClassifier(c ClassifierConfig) Option {
+	return func(cfg *DetectorConfig) { cfg.Classifier = &c }
+}
+
+// WithAGC 设置推理前的自动增益控制
+func WithAGC(a audio.AGCConfig) Option {
+	return func(cfg *DetectorConfig) { cfg.AGC = &a }
+}
+
+// WithInputCheck 设置输入中 NaN、Inf 和非规格化采样的处理方式
+func WithInputCheck(check InputCheck) Option {
+	return func(cfg *DetectorConfig) { cfg.InputCheck = check }
+}
+
+// WithEstimateSNR 设置是否估计每个片段的信噪比
+func WithEstimateSNR(enabled bool) Option {
+	return func(cfg *DetectorConfig) { cfg.EstimateSNR = enabled }
+}
+
+// WithMeasureLoudness 设置是否测量每个片段的响度和 RMS 电平
+func WithMeasureLoudness(enabled bool) Option {
+	return func(cfg *DetectorConfig) { cfg.MeasureLoudness = enabled }
+}
+
+// WithMaxClippedRun 设置连续满幅采样的上限，超过时把片段标记为削波
+func WithMaxClippedRun(n int) Option {
+	return func(cfg *DetectorConfig) { cfg.MaxClippedRun = n }
+}
+
+// WithCalibration 设置模型语音概率的校准
+func WithCalibration(c Calibration) Option {
+	return func(cfg *DetectorConfig) { cfg.Calibration = c }
+}
+
+// WithDutyCycle 启用低功耗模式
+func WithDutyCycle(d DutyCycleConfig) Option {
+	return func(cfg *DetectorConfig) { cfg.DutyCycle = &d }
+}
@@ -0,0 +1,57 @@
+package speech
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptions(t *testing.T) {
+	cfg, err := BuildConfig("model.onnx")
+	require.NoError(t, err)
+	require.Equal(t, DefaultConfig("model.onnx"), cfg)
+
+	cfg, err = BuildConfig("model.onnx",
+		WithThreshold(0.6),
+		WithSampleRate(8000),
+		WithMinSilence(300*time.Millisecond),
+		WithEP(CUDA(1)),
+		nil,
+		WithDutyCycle(DutyCycleConfig{WakeAbove: 0.4}),
+	)
+	require.NoError(t, err)
+	require.Equal(t, float32(0.6), cfg.Threshold)
+	require.Equal(t, 8000, cfg.SampleRate)
+	require.Equal(t, 300, cfg.MinSilenceDurationMs)
+	require.Equal(t, ExecutionProvider{Name: ProviderCUDA, Options: map[string]string{"device_id": "1"}}, cfg.ExecutionProvider)
+	require.Equal(t, float32(0.4), cfg.DutyCycle.WakeAbove)
+
+	// 以保存的配置为基础叠加选项
+	saved := cfg
+	saved.ModelPath = ""
+	cfg, err = BuildConfig("other.onnx", WithConfigFrom(saved), WithThreshold(0.7))
+	require.NoError(t, err)
+	require.Equal(t, "other.onnx", cfg.ModelPath)
+	require.Equal(t, 8000, cfg.SampleRate)
+	require.Equal(t, float32(0.7), cfg.Threshold)
+
+	_, err = BuildConfig("model.onnx", WithThreshold(2))
+	require.ErrorIs(t, err, ErrInvalidConfig)
+	_, err = BuildConfig("model.onnx", WithEP(ExecutionProvider{Options: map[string]string{"a": "b"}}))
+	require.ErrorIs(t, err, ErrInvalidConfig)
+
+	sm, err := New("../testfiles/silero_vad.onnx", WithThreshold(0.5), WithEP(CPU()))
+	require.NoError(t, err)
+	require.Equal(t, "cpu", sm.GetConfig().ExecutionProvider.String())
+	_, err = sm.UpdateConfig(DefaultConfig("../testfiles/silero_vad.onnx"))
+	require.NoError(t, err, "the zero value is the cpu provider")
+	changed := DefaultConfig("../testfiles/silero_vad.onnx")
+	changed.ExecutionProvider = CUDA(0)
+	_, err = sm.UpdateConfig(changed)
+	require.ErrorIs(t, err, ErrInvalidConfig)
+	require.NoError(t, sm.Destroy())
+
+	_, err = New("../testfiles/silero_vad.onnx", WithEP(ExecutionProvider{Name: "NoSuchProvider"}))
+	require.ErrorContains(t, err, "NoSuchProvider")
+}
@@ -18,6 +18,10 @@ const char* OrtApiGetErrorMessage(OrtApi* api, OrtStatus* status) {
   return api->GetErrorMessage(status);
 }
 
+OrtErrorCode OrtApiGetErrorCode(OrtApi* api, OrtStatus* status) {
+  return api->GetErrorCode(status);
+}
+
 OrtStatus* OrtApiCreateEnv(OrtApi* api, OrtLoggingLevel log_level, const char* log_id, OrtEnv** env) {
   return api->CreateEnv(log_level, log_id, env);
 }
@@ -46,6 +50,30 @@ OrtStatus* OrtApiSetSessionGraphOptimizationLevel(OrtApi* api, OrtSessionOptions
   return api->SetSessionGraphOptimizationLevel(opts, graph_optimization_level);
 }
 
+OrtStatus* OrtApiAddSessionConfigEntry(OrtApi* api, OrtSessionOptions* opts, const char* config_key, const char* config_value) {
+  return api->AddSessionConfigEntry(opts, config_key, config_value);
+}
+
+OrtStatus* OrtApiSessionOptionsAppendExecutionProvider(OrtApi* api, OrtSessionOptions* opts, const char* provider_name,
+    const char* const* keys, const char* const* values, size_t num_keys) {
+  return api->SessionOptionsAppendExecutionProvider(opts, provider_name, keys, values, num_keys);
+}
+
+OrtStatus* OrtApiSessionOptionsAppendCUDA(OrtApi* api, OrtSessionOptions* opts,
+    const char* const* keys, const char* const* values, size_t num_keys) {
+  OrtCUDAProviderOptionsV2* cuda_options = NULL;
+  OrtStatus* status = api->CreateCUDAProviderOptions(&cuda_options);
+  if (status != NULL) {
+    return status;
+  }
+  status = api->UpdateCUDAProviderOptions(cuda_options, keys, values, num_keys);
+  if (status == NULL) {
+    status = api->SessionOptionsAppendExecutionProvider_CUDA_V2(opts, cuda_options);
+  }
+  api->ReleaseCUDAProviderOptions(cuda_options);
+  return status;
+}
+
 OrtStatus* OrtApiCreateSession(OrtApi* api, OrtEnv* env, const char* model_path, OrtSessionOptions* opts, OrtSession** session) {
   return api->CreateSession(env, model_path, opts, session);
 }
@@ -3,6 +3,7 @@
 const OrtApi *OrtGetApi();
 
 const char *OrtApiGetErrorMessage(OrtApi *api, OrtStatus *status);
+OrtErrorCode OrtApiGetErrorCode(OrtApi *api, OrtStatus *status);
 
 void OrtApiReleaseStatus(OrtApi *api, OrtStatus *status);
 
@@ -15,6 +16,11 @@ void OrtApiReleaseSessionOptions(OrtApi *api, OrtSessionOptions *opts);
 OrtStatus *OrtApiSetIntraOpNumThreads(OrtApi *api, OrtSessionOptions *opts, int intra_op_num_threads);
 OrtStatus *OrtApiSetInterOpNumThreads(OrtApi *api, OrtSessionOptions *opts, int inter_op_num_threads);
 OrtStatus *OrtApiSetSessionGraphOptimizationLevel(OrtApi *api, OrtSessionOptions *opts, GraphOptimizationLevel graph_optimization_level);
+OrtStatus *OrtApiAddSessionConfigEntry(OrtApi *api, OrtSessionOptions *opts, const char *config_key, const char *config_value);
+OrtStatus *OrtApiSessionOptionsAppendExecutionProvider(OrtApi *api, OrtSessionOptions *opts, const char *provider_name,
+                                                     const char *const *keys, const char *const *values, size_t num_keys);
+OrtStatus *OrtApiSessionOptionsAppendCUDA(OrtApi *api, OrtSessionOptions *opts,
+                                          const char *const *keys, const char *const *values, size_t num_keys);
 
 OrtStatus *OrtApiCreateSession(OrtApi *api, OrtEnv *env, const char *model_path, OrtSessionOptions *opts, OrtSession **session);
 void OrtApiReleaseSession(OrtApi *api, OrtSession *session);
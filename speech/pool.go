@@ -0,0 +1,170 @@
+package speech
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// DetectorPool 限制同一模型上同时进行的检测数量
+// 超出上限的请求在 Acquire 中排队等待，而不是让推理线程超额占用 CPU。
+// 池中的上下文在 Release 时被重置并复用。
+type DetectorPool struct {
+	model *SharedModel
+	// sem 的容量即最大并发数
+	sem     chan struct{}
+	waiting atomic.Int64
+	// done 在 Close 时关闭，唤醒排队中的 Acquire
+	done chan struct{}
+
+	mu     sync.Mutex
+	idle   []*DetectorContext
+	closed bool
+}
+
+// PooledContext 从 DetectorPool 借出的检测上下文，使用完毕后调用 Release 归还
+// 不要对其调用 Close。
+type PooledContext struct {
+	*DetectorContext
+	pool     *DetectorPool
+	released atomic.Bool
+}
+
+// NewDetectorPool 创建最多同时借出 maxConcurrent 个上下文的池
+// maxConcurrent 通常不超过 CPU 核数或模型的 SessionPoolSize 的整数倍。
+func (sm *SharedModel) NewDetectorPool(maxConcurrent int) (*DetectorPool, error) {
+	if maxConcurrent <= 0 {
+		return nil, fmt.Errorf("invalid max concurrency: %d", maxConcurrent)
+	}
+	return &DetectorPool{
+		model: sm,
+		sem:   make(chan struct{}, maxConcurrent),
+		done:  make(chan struct{}),
+	}, nil
+}
+
+// Acquire 借出一个状态已重置的上下文，达到并发上限时阻塞直到有上下文归还或 ctx 结束
+func (p *DetectorPool) Acquire(ctx context.Context) (*PooledContext, error) {
+	select {
+	case <-p.done:
+		return nil, ErrPoolClosed
+	case p.sem <- struct{}{}:
+	default:
+		p.waiting.Add(1)
+		select {
+		case p.sem <- struct{}{}:
+			p.waiting.Add(-1)
+		case <-p.done:
+			p.waiting.Add(-1)
+			return nil, ErrPoolClosed
+		case <-ctx.Done():
+			p.waiting.Add(-1)
+			return nil, ctx.Err()
+		}
+	}
+
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		<-p.sem
+		return nil, ErrPoolClosed
+	}
+	var dc *DetectorContext
+	if n := len(p.idle); n > 0 {
+		dc = p.idle[n-1]
+		p.idle = p.idle[:n-1]
+	}
+	p.mu.Unlock()
+
+	if dc == nil {
+		dc = p.model.NewContext()
+	}
+	return &PooledContext{DetectorContext: dc, pool: p}, nil
+}
+
+// Release 重置上下文并归还到池中，重复调用是安全的
+// 上下文的配置恢复为模型配置，预处理阶段和窗口回调会被清除。
+func (pc *PooledContext) Release() {
+	if pc.released.Swap(true) {
+		return
+	}
+
+	dc := pc.DetectorContext
+	dc.recycle()
+
+	p := pc.pool
+	p.mu.Lock()
+	if p.closed {
+		dc.Close()
+	} else {
+		p.idle = append(p.idle, dc)
+	}
+	p.mu.Unlock()
+	<-p.sem
+}
+
+// InUse 返回当前借出的上下文数量
+func (p *DetectorPool) InUse() int {
+	return len(p.sem)
+}
+
+// Waiting 返回正在 Acquire 中排队的调用数量
+func (p *DetectorPool) Waiting() int {
+	return int(p.waiting.Load())
+}
+
+// Close 关闭池并释放空闲的上下文，之后（包括正在排队的）Acquire 返回 ErrPoolClosed
+// 已借出的上下文仍可使用，归还时被释放。应在模型 Destroy 之前调用。
+func (p *DetectorPool) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return
+	}
+	p.closed = true
+	close(p.done)
+	for _, dc := range p.idle {
+		dc.Close()
+	}
+	p.idle = nil
+}
+
+// GetContext 返回一个可用的检测上下文，优先复用 PutContext 归还的上下文
+// 适合每个请求只做一次短检测的场景，避免高 QPS 下反复分配上下文。
+// 返回的上下文状态已重置，与 NewContext 一样计入引用，使用完毕后调用 PutContext（或 Close）。
+func (sm *SharedModel) GetContext() *DetectorContext {
+	dc, _ := sm.contexts.Get().(*DetectorContext)
+	if dc == nil {
+		return sm.NewContext()
+	}
+	sm.refs.Add(1)
+	dc.usage = contextUsage{}
+	dc.closed.Store(false)
+	return dc
+}
+
+// PutContext 重置并归还上下文，之后调用方不能再使用 dc
+// 归还的上下文立即释放对模型的引用，池中的上下文可能随时被 GC 回收。
+// 已关闭的上下文、其它模型的上下文以及模型销毁后的归还会被忽略。
+func (sm *SharedModel) PutContext(dc *DetectorContext) {
+	if dc == nil || dc.model != sm || dc.closed.Swap(true) {
+		return
+	}
+	sm.refs.Add(-1)
+	if sm.destroyed.Load() {
+		return
+	}
+	dc.recycle()
+	sm.contexts.Put(dc)
+}
+
+// recycle 重置检测状态、恢复模型配置并清除调用方设置的预处理阶段、窗口回调和优先级，使上下文可以交给下一个使用者
+func (dc *DetectorContext) recycle() {
+	dc.Reset()
+	dc.cfg.Store(nil)
+	dc.SetPreprocessors()
+	dc.SetWindowObserver(nil)
+	dc.priority = PriorityRealtime
+}
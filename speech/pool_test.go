@@ -0,0 +1,130 @@
+package speech
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectorPool(t *testing.T) {
+	sm := newTestSharedModel(t)
+	samples := readTestSamples(t, "../testfiles/samples.pcm")
+
+	dc := sm.NewContext()
+	expected, err := dc.Detect(samples)
+	require.NoError(t, err)
+	require.NoError(t, dc.Close())
+
+	_, err = sm.NewDetectorPool(0)
+	require.Error(t, err)
+
+	pool, err := sm.NewDetectorPool(2)
+	require.NoError(t, err)
+
+	// 并发数不超过上限，复用的上下文状态已重置
+	var active, peak atomic.Int32
+	var wg sync.WaitGroup
+	results := make([][]Segment, 6)
+	errs := make([]error, len(results))
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			pc, err := pool.Acquire(context.Background())
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			defer pc.Release()
+
+			n := active.Add(1)
+			defer active.Add(-1)
+			for p := peak.Load(); n > p && !peak.CompareAndSwap(p, n); p = peak.Load() {
+			}
+
+			results[i], errs[i] = pc.Detect(samples)
+		}(i)
+	}
+	wg.Wait()
+	for i, segments := range results {
+		require.NoError(t, errs[i])
+		require.Equal(t, expected, segments)
+	}
+	require.LessOrEqual(t, peak.Load(), int32(2))
+	require.Zero(t, pool.InUse())
+	// 上下文被复用而不是每次新建
+	require.EqualValues(t, 2, sm.Stats().ActiveContexts)
+
+	// 达到上限时排队，ctx 结束时返回其错误
+	a, err := pool.Acquire(context.Background())
+	require.NoError(t, err)
+	b, err := pool.Acquire(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 2, pool.InUse())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = pool.Acquire(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Zero(t, pool.Waiting())
+
+	acquired := make(chan *PooledContext)
+	var acquireErr error
+	go func() {
+		var pc *PooledContext
+		pc, acquireErr = pool.Acquire(context.Background())
+		acquired <- pc
+	}()
+	require.Eventually(t, func() bool { return pool.Waiting() == 1 }, time.Second, time.Millisecond)
+	a.Release()
+	a.Release()
+	c := <-acquired
+	require.NoError(t, acquireErr)
+	require.Equal(t, 2, pool.InUse())
+
+	// 关闭后不再借出，已借出的上下文归还时释放
+	pool.Close()
+	_, err = pool.Acquire(context.Background())
+	require.ErrorIs(t, err, ErrPoolClosed)
+	b.Release()
+	c.Release()
+	require.Zero(t, pool.InUse())
+	require.Zero(t, sm.Stats().ActiveContexts)
+}
+
+func TestGetPutContext(t *testing.T) {
+	sm := newTestSharedModel(t)
+	samples := readTestSamples(t, "../testfiles/samples.pcm")
+
+	dc := sm.GetContext()
+	expected, err := dc.Detect(samples)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, sm.Stats().ActiveContexts)
+
+	// 归还后释放引用，重复归还被忽略
+	sm.PutContext(dc)
+	sm.PutContext(dc)
+	require.Zero(t, sm.Stats().ActiveContexts)
+	_, err = dc.Detect(samples)
+	require.ErrorIs(t, err, ErrContextClosed)
+
+	// 复用的上下文（或新建的上下文）状态已重置
+	for i := 0; i < 3; i++ {
+		dc := sm.GetContext()
+		require.EqualValues(t, 1, sm.Stats().ActiveContexts)
+		segments, err := dc.Detect(samples)
+		require.NoError(t, err)
+		require.Equal(t, expected, segments)
+		sm.PutContext(dc)
+	}
+
+	// 已关闭的上下文不会进入池中
+	closed := sm.GetContext()
+	require.NoError(t, closed.Close())
+	sm.PutContext(closed)
+	require.Zero(t, sm.Stats().ActiveContexts)
+}
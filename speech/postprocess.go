@@ -0,0 +1,163 @@
+package speech
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// SegmentFilter 检测之后对片段的一步后处理
+// 输入按时间排序，SpeechEndAt 为 0 的片段尚未结束；实现不能修改输入的切片，结果同样按时间排序。
+type SegmentFilter interface {
+	Filter(segments []Segment) ([]Segment, error)
+}
+
+// SegmentFilterFunc 以函数实现 SegmentFilter，用于自定义的处理步骤
+type SegmentFilterFunc func(segments []Segment) ([]Segment, error)
+
+// Filter 调用 f
+func (f SegmentFilterFunc) Filter(segments []Segment) ([]Segment, error) {
+	return f(segments)
+}
+
+// SegmentPipeline 依次执行的一组后处理步骤，本身也是 SegmentFilter，可以嵌套
+// 各服务共用同一个 SegmentPipeline 即可得到一致的后处理结果，例如：
+//
+//	pipeline := speech.SegmentPipeline{
+//		speech.MergeGaps(300 * time.Millisecond),
+//		speech.MinDuration(250 * time.Millisecond),
+//		speech.PadClamp(100*time.Millisecond, duration),
+//	}
+//	segments, err = pipeline.Filter(segments)
+type SegmentPipeline []SegmentFilter
+
+// Filter 依次执行每个步骤，任一步骤出错时返回该错误
+func (p SegmentPipeline) Filter(segments []Segment) ([]Segment, error) {
+	out := segments
+	for i, f := range p {
+		if f == nil {
+			return nil, fmt.Errorf("invalid nil segment filter at %d", i)
+		}
+		var err error
+		out, err = f.Filter(out)
+		if err != nil {
+			return nil, fmt.Errorf("segment filter %d: %w", i, err)
+		}
+	}
+	if len(p) == 0 {
+		out = append([]Segment(nil), segments...)
+	}
+	return out, nil
+}
+
+// MinDuration 丢弃时长短于 d 的片段，未结束的片段总是保留
+func MinDuration(d time.Duration) SegmentFilter {
+	return SegmentFilterFunc(func(segments []Segment) ([]Segment, error) {
+		if d < 0 {
+			return nil, fmt.Errorf("invalid MinDuration: should not be negative")
+		}
+		minLen := d.Seconds()
+		out := make([]Segment, 0, len(segments))
+		for _, seg := range segments {
+			if seg.SpeechEndAt > 0 && seg.SpeechEndAt-seg.SpeechStartAt < minLen {
+				continue
+			}
+			out = append(out, seg)
+		}
+		return out, nil
+	})
+}
+
+// MergeGaps 合并间隔不超过 maxGap 的相邻片段，重叠的片段总是合并
+// 合并后的 SNR、Loudness 和 RMS 按时长在功率上加权平均（只有一方有值时取该值），Clipped 取或。
+// 与未结束的片段合并后结果仍未结束。
+func MergeGaps(maxGap time.Duration) SegmentFilter {
+	return SegmentFilterFunc(func(segments []Segment) ([]Segment, error) {
+		if maxGap < 0 {
+			return nil, fmt.Errorf("invalid MergeGaps: should not be negative")
+		}
+		gap := maxGap.Seconds()
+		out := make([]Segment, 0, len(segments))
+		for _, seg := range segments {
+			n := len(out)
+			if n == 0 || out[n-1].SpeechEndAt == 0 || seg.SpeechStartAt-out[n-1].SpeechEndAt > gap {
+				out = append(out, seg)
+				continue
+			}
+			out[n-1] = joinSegments(out[n-1], seg)
+		}
+		return out, nil
+	})
+}
+
+// joinSegments 把 b 合并到 a 中，a 已经结束且不晚于 b 开始
+func joinSegments(a, b Segment) Segment {
+	da := a.SpeechEndAt - a.SpeechStartAt
+	db := b.SpeechEndAt - b.SpeechStartAt
+	if b.SpeechEndAt == 0 {
+		db = da // 未结束片段的时长未知，两者等权
+	}
+	merged := Segment{
+		SpeechStartAt: min(a.SpeechStartAt, b.SpeechStartAt),
+		SpeechEndAt:   max(a.SpeechEndAt, b.SpeechEndAt),
+		SNR:           mergeLevel(a.SNR, b.SNR, da, db),
+		Loudness:      mergeLevel(a.Loudness, b.Loudness, da, db),
+		RMS:           mergeLevel(a.RMS, b.RMS, da, db),
+		Clipped:       a.Clipped || b.Clipped,
+	}
+	if b.SpeechEndAt == 0 {
+		merged.SpeechEndAt = 0
+	}
+	return merged
+}
+
+// mergeLevel 按时长加权平均两个 dB 值对应的功率，0 表示没有测量
+func mergeLevel(a, b, da, db float64) float64 {
+	switch {
+	case a == 0:
+		return b
+	case b == 0:
+		return a
+	}
+	da, db = max(da, 0), max(db, 0)
+	if da+db == 0 {
+		da, db = 1, 1
+	}
+	p := (da*math.Pow(10, a/10) + db*math.Pow(10, b/10)) / (da + db)
+	return 10 * math.Log10(p)
+}
+
+// PadClamp 在片段两端添加 pad，不越过相邻片段之间静音的中点，也不超出 [0, duration]
+// duration 为音频总时长（秒），为 0 时不限制结尾。未结束的片段只在开头添加。
+// 检测结果已经包含 SpeechPadMs 的填充，这里的 pad 是额外的填充。
+func PadClamp(pad time.Duration, duration float64) SegmentFilter {
+	return SegmentFilterFunc(func(segments []Segment) ([]Segment, error) {
+		if pad < 0 {
+			return nil, fmt.Errorf("invalid PadClamp pad: should not be negative")
+		}
+		if duration < 0 {
+			return nil, fmt.Errorf("invalid duration: %f", duration)
+		}
+		p := pad.Seconds()
+		out := make([]Segment, len(segments))
+		for i, seg := range segments {
+			start := max(seg.SpeechStartAt-p, 0)
+			if i > 0 && segments[i-1].SpeechEndAt > 0 {
+				start = max(start, min(seg.SpeechStartAt, (segments[i-1].SpeechEndAt+seg.SpeechStartAt)/2))
+			}
+			end := seg.SpeechEndAt
+			if end > 0 {
+				end += p
+				if i+1 < len(segments) {
+					end = min(end, max(seg.SpeechEndAt, (seg.SpeechEndAt+segments[i+1].SpeechStartAt)/2))
+				}
+				if duration > 0 {
+					end = min(end, duration)
+				}
+			}
+			seg.SpeechStartAt, seg.SpeechEndAt = start, end
+			out[i] = seg
+		}
+		return out, nil
+	})
+}
@@ -0,0 +1,60 @@
+package speech
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSegmentPipeline(t *testing.T) {
+	segments := []Segment{
+		{SpeechStartAt: 1.0, SpeechEndAt: 1.1},
+		{SpeechStartAt: 2.0, SpeechEndAt: 3.0, RMS: -20},
+		{SpeechStartAt: 3.2, SpeechEndAt: 4.0, RMS: -20, Clipped: true},
+		{SpeechStartAt: 6.0, SpeechEndAt: 0},
+	}
+	input := append([]Segment(nil), segments...)
+
+	pipeline := SegmentPipeline{
+		MinDuration(250 * time.Millisecond),
+		MergeGaps(300 * time.Millisecond),
+		PadClamp(time.Second, 0),
+	}
+	out, err := pipeline.Filter(segments)
+	require.NoError(t, err)
+	require.Equal(t, input, segments)
+	require.Len(t, out, 2)
+	require.InDelta(t, 1.0, out[0].SpeechStartAt, 1e-9)
+	require.InDelta(t, 5.0, out[0].SpeechEndAt, 1e-9)
+	require.InDelta(t, -20, out[0].RMS, 1e-9)
+	require.True(t, out[0].Clipped)
+	require.Equal(t, Segment{SpeechStartAt: 5.0}, out[1])
+
+	// 填充不越过相邻片段之间的中点和音频结尾
+	out, err = PadClamp(time.Second, 3.5).Filter([]Segment{
+		{SpeechStartAt: 0.5, SpeechEndAt: 1.0},
+		{SpeechStartAt: 1.4, SpeechEndAt: 3.0},
+	})
+	require.NoError(t, err)
+	require.InDeltaSlice(t, []float64{0, 1.2}, []float64{out[0].SpeechStartAt, out[0].SpeechEndAt}, 1e-9)
+	require.InDeltaSlice(t, []float64{1.2, 3.5}, []float64{out[1].SpeechStartAt, out[1].SpeechEndAt}, 1e-9)
+
+	// 自定义步骤和错误
+	bad := errors.New("bad")
+	_, err = SegmentPipeline{SegmentFilterFunc(func([]Segment) ([]Segment, error) { return nil, bad })}.Filter(segments)
+	require.ErrorIs(t, err, bad)
+	_, err = SegmentPipeline{nil}.Filter(segments)
+	require.Error(t, err)
+	_, err = MinDuration(-1).Filter(segments)
+	require.Error(t, err)
+	_, err = MergeGaps(-1).Filter(segments)
+	require.Error(t, err)
+	_, err = PadClamp(0, -1).Filter(segments)
+	require.Error(t, err)
+
+	out, err = SegmentPipeline{}.Filter(segments)
+	require.NoError(t, err)
+	require.Equal(t, segments, out)
+}
@@ -0,0 +1,143 @@
+package speech
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/rui-yang-me/silero-vad-go/audio"
+)
+
+// InputCheck 推理前对输入中 NaN、Inf 和非规格化采样的处理方式
+// 采集缓冲损坏时，一个 NaN 就会污染循环状态，此后所有概率都没有意义，直到调用 Reset。
+type InputCheck int
+
+const (
+	// InputCheckNone 不检查输入，是默认值
+	InputCheckNone InputCheck = iota
+	// InputCheckReject 输入含 NaN 或 Inf 时返回 ErrInvalidSamples，检测状态保持不变
+	InputCheckReject
+	// InputCheckSanitize NaN 和非规格化数替换为 0，±Inf 截断为 ±1 后继续检测
+	InputCheckSanitize
+)
+
+// preprocessor 按配置在推理前处理音频
+// 每个检测器/上下文持有一份，使流式调用之间的滤波状态保持连续。
+type preprocessor struct {
+	highPass *audio.Biquad
+	cutoff   float64
+	denoiser *denoiserStage // 共享模型配置了降噪模型时由 NewContext 设置
+	agc      *audio.AGC
+	agcCfg   audio.AGCConfig
+	stages   []audio.Processor // 调用方追加的处理阶段，在高通滤波之后依次执行
+	bufs     [2][]float32      // 各阶段之间交替使用的输出缓冲
+	cur      int               // 当前结果所在的缓冲下标，-1 表示仍是调用方的输入
+
+	resampler *audio.Resampler // 配置了 InputSampleRate 时由 resample 创建
+	resampled []float32
+
+	clip clipDetector // 配置了 MaxClippedRun 时查找输入中的削波
+}
+
+// apply 返回预处理后的采样，未启用任何处理时直接返回 pcm
+// 返回的切片在下一次调用前有效，调用方的输入不会被修改。
+func (p *preprocessor) apply(cfg *DetectorConfig, pcm []float32) ([]float32, error) {
+	out := pcm
+	p.cur = -1
+
+	// 异常采样须在滤波之前处理，否则会先污染滤波器的状态
+	switch cfg.InputCheck {
+	case InputCheckReject:
+		if i := audio.FindNonFinite(pcm); i >= 0 {
+			return nil, fmt.Errorf("%w: sample %d is %v", ErrInvalidSamples, i, pcm[i])
+		}
+	case InputCheckSanitize:
+		i := p.next()
+		if sanitized, n := audio.Sanitize(p.bufs[i], out); n > 0 {
+			if debugEnabled() {
+				slog.Debug("sanitized invalid samples", slog.Int("count", n))
+			}
+			p.bufs[i], p.cur = sanitized, i
+			out = sanitized
+		}
+	}
+
+	if cfg.HighPassCutoffHz > 0 {
+		if p.highPass == nil || p.cutoff != cfg.HighPassCutoffHz {
+			f, err := audio.NewHighPass(cfg.SampleRate, cfg.HighPassCutoffHz)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create high-pass filter: %w", err)
+			}
+			p.highPass = f
+			p.cutoff = cfg.HighPassCutoffHz
+		}
+		out = p.run(p.highPass, out)
+	}
+
+	if p.denoiser != nil {
+		i := p.next()
+		denoised, err := p.denoiser.process(p.bufs[i][:0], out)
+		if err != nil {
+			return nil, fmt.Errorf("denoiser failed: %w", err)
+		}
+		p.bufs[i], p.cur = denoised, i
+		out = denoised
+	}
+
+	if cfg.AGC != nil {
+		if p.agc == nil || p.agcCfg != *cfg.AGC {
+			agc, err := audio.NewAGC(cfg.SampleRate, *cfg.AGC)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create agc: %w", err)
+			}
+			p.agc = agc
+			p.agcCfg = *cfg.AGC
+		}
+		out = p.run(p.agc, out)
+	}
+
+	for _, stage := range p.stages {
+		out = p.run(stage, out)
+	}
+
+	if cfg.MaxClippedRun > 0 {
+		p.clip.scan(pcm, cfg.MaxClippedRun, len(out)-len(pcm))
+	}
+
+	return out, nil
+}
+
+// run 执行一个处理阶段，输出写入另一个缓冲以免与输入重叠
+func (p *preprocessor) run(stage audio.Processor, in []float32) []float32 {
+	i := p.next()
+	p.bufs[i] = stage.Process(p.bufs[i][:0], in)
+	p.cur = i
+	return p.bufs[i]
+}
+
+// next 返回下一个阶段应写入的缓冲下标
+func (p *preprocessor) next() int {
+	if p.cur == 0 {
+		return 1
+	}
+	return 0
+}
+
+// reset 清空所有阶段的内部状态
+func (p *preprocessor) reset() {
+	if p.resampler != nil {
+		p.resampler.Reset()
+	}
+	if p.highPass != nil {
+		p.highPass.Reset()
+	}
+	if p.denoiser != nil {
+		p.denoiser.reset()
+	}
+	if p.agc != nil {
+		p.agc.Reset()
+	}
+	p.clip.reset()
+	for _, stage := range p.stages {
+		stage.Reset()
+	}
+}
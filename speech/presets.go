@@ -0,0 +1,83 @@
+package speech
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// 预设按用途组合阈值、静音和填充时长，作为新项目的起点：
+// PresetTelephony8k 和 PresetWideband16k 决定采样率和与之相称的参数，
+// PresetAggressive 和 PresetConservative 只调整灵敏度，可以叠加在前两者之后，例如
+//
+//	model, err := speech.New("silero_vad.onnx", speech.PresetTelephony8k(), speech.PresetConservative())
+//
+// Option 与 WithConfig 的参数类型相同，灵敏度预设也可以只用于某个上下文：dc.WithConfig(speech.PresetAggressive())。
+
+// PresetTelephony8k 电话音频（8kHz 窄带）：滤除线路的低频嗡声，较长的静音容忍通话中的停顿
+func PresetTelephony8k() Option {
+	return func(cfg *DetectorConfig) {
+		cfg.SampleRate = 8000
+		cfg.Threshold = 0.5
+		cfg.MinSilenceDurationMs = 300
+		cfg.SpeechPadMs = 50
+		cfg.HighPassCutoffHz = 100
+	}
+}
+
+// PresetWideband16k 麦克风和会议音频（16kHz 宽带）：滤除直流和低频噪声，按句子而不是按词切分
+func PresetWideband16k() Option {
+	return func(cfg *DetectorConfig) {
+		cfg.SampleRate = 16000
+		cfg.Threshold = 0.5
+		cfg.MinSilenceDurationMs = 250
+		cfg.SpeechPadMs = 30
+		cfg.HighPassCutoffHz = 80
+	}
+}
+
+// PresetAggressive 尽量少报非语音：较高的阈值和较短的静音，片段紧贴语音，
+// 适合嘈杂环境中的打断检测和按片段计费的 ASR，代价是可能切掉轻声的词尾
+func PresetAggressive() Option {
+	return func(cfg *DetectorConfig) {
+		cfg.Threshold = 0.7
+		cfg.MinSilenceDurationMs = 100
+		cfg.SpeechPadMs = 30
+	}
+}
+
+// PresetConservative 尽量不漏掉语音：较低的阈值、较长的静音和填充，
+// 适合录音归档和离线转写，代价是片段中包含更多噪声和停顿
+func PresetConservative() Option {
+	return func(cfg *DetectorConfig) {
+		cfg.Threshold = 0.35
+		cfg.MinSilenceDurationMs = 500
+		cfg.SpeechPadMs = 100
+	}
+}
+
+// presets 按名称索引的预设
+var presets = map[string]func() Option{
+	"telephony8k":  PresetTelephony8k,
+	"wideband16k":  PresetWideband16k,
+	"aggressive":   PresetAggressive,
+	"conservative": PresetConservative,
+}
+
+// Preset 按名称返回预设，名称不区分大小写，用于命令行参数和配置文件
+func Preset(name string) (Option, error) {
+	if p, ok := presets[strings.ToLower(name)]; ok {
+		return p(), nil
+	}
+	return nil, fmt.Errorf("unknown preset %q: valid presets are %s", name, strings.Join(PresetNames(), ", "))
+}
+
+// PresetNames 返回所有预设的名称，按字母排序
+func PresetNames() []string {
+	names := make([]string, 0, len(presets))
+	for name := range presets {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
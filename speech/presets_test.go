@@ -0,0 +1,54 @@
+package speech
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPresets(t *testing.T) {
+	cfg, err := BuildConfig("model.onnx", PresetTelephony8k(), PresetConservative())
+	require.NoError(t, err)
+	require.Equal(t, 8000, cfg.SampleRate)
+	require.Equal(t, 100.0, cfg.HighPassCutoffHz)
+	require.Equal(t, float32(0.35), cfg.Threshold)
+	require.Equal(t, 500, cfg.MinSilenceDurationMs)
+
+	require.Equal(t, []string{"aggressive", "conservative", "telephony8k", "wideband16k"}, PresetNames())
+	for _, name := range PresetNames() {
+		p, err := Preset(name)
+		require.NoError(t, err)
+		_, err = BuildConfig("model.onnx", p)
+		require.NoError(t, err, name)
+	}
+	p, err := Preset("Aggressive")
+	require.NoError(t, err)
+	cfg, err = BuildConfig("model.onnx", PresetWideband16k(), p)
+	require.NoError(t, err)
+	require.Equal(t, 16000, cfg.SampleRate)
+	require.Equal(t, float32(0.7), cfg.Threshold)
+
+	_, err = Preset("loud")
+	require.ErrorContains(t, err, "telephony8k")
+
+	// 预设在检测上可用：保守预设不会比激进预设检测到更少的语音
+	sm := newTestSharedModel(t)
+	samples := readTestSamples(t, "../testfiles/samples.pcm")
+	speechDuration := func(p Option) float64 {
+		dc := sm.NewContext()
+		defer dc.Close()
+		require.NoError(t, dc.WithConfig(p))
+		segments, err := dc.Detect(samples)
+		require.NoError(t, err)
+		var total float64
+		end := float64(len(samples)) / 16000
+		for _, s := range segments {
+			if s.SpeechEndAt == 0 {
+				s.SpeechEndAt = end
+			}
+			total += s.SpeechEndAt - s.SpeechStartAt
+		}
+		return total
+	}
+	require.Greater(t, speechDuration(PresetConservative()), speechDuration(PresetAggressive()))
+}
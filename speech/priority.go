@@ -0,0 +1,86 @@
+package speech
+
+import "sync"
+
+// Priority 上下文的调度优先级，只在配置了 MaxConcurrentInferences 时生效
+type Priority int
+
+const (
+	// PriorityRealtime 实时流（通话、直播等），窗口推理优先获得名额，是上下文的默认优先级
+	PriorityRealtime Priority = iota
+	// PriorityBatch 后台批处理（离线转写、重新处理历史音频等），只在没有实时窗口排队时推理
+	PriorityBatch
+
+	numPriorities
+)
+
+// String 返回优先级的名称
+func (p Priority) String() string {
+	switch p {
+	case PriorityRealtime:
+		return "realtime"
+	case PriorityBatch:
+		return "batch"
+	default:
+		return "unknown"
+	}
+}
+
+// SetPriority 设置该上下文的调度优先级，不合法的值会被忽略
+// 应在两次检测调用之间设置，不要与该上下文的检测调用并发执行。
+func (dc *DetectorContext) SetPriority(p Priority) {
+	if dc != nil && p >= 0 && p < numPriorities {
+		dc.priority = p
+	}
+}
+
+// Priority 返回该上下文的调度优先级
+func (dc *DetectorContext) Priority() Priority {
+	if dc == nil {
+		return PriorityRealtime
+	}
+	return dc.priority
+}
+
+// inferScheduler 按优先级分配窗口推理名额
+// 名额用完时推理在各自优先级的队列中排队，归还的名额总是先交给实时队列，
+// 因此后台批处理再多，实时窗口最多只需等待一个正在进行的推理完成。
+type inferScheduler struct {
+	mu      sync.Mutex
+	free    int
+	waiting [numPriorities][]chan struct{}
+}
+
+func newInferScheduler(slots int) *inferScheduler {
+	return &inferScheduler{free: slots}
+}
+
+// acquire 获取一个推理名额，没有空闲名额时阻塞
+func (s *inferScheduler) acquire(p Priority) {
+	s.mu.Lock()
+	// 有排队者时名额会在 release 中直接移交，不会留在 free 中
+	if s.free > 0 {
+		s.free--
+		s.mu.Unlock()
+		return
+	}
+	ch := make(chan struct{})
+	s.waiting[p] = append(s.waiting[p], ch)
+	s.mu.Unlock()
+	<-ch
+}
+
+// release 归还名额，优先移交给排队最久的实时推理
+func (s *inferScheduler) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for p := range s.waiting {
+		if q := s.waiting[p]; len(q) > 0 {
+			close(q[0])
+			q[0] = nil
+			s.waiting[p] = q[1:]
+			return
+		}
+	}
+	s.free++
+}
@@ -0,0 +1,99 @@
+package speech
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInferSchedulerPriority(t *testing.T) {
+	s := newInferScheduler(1)
+	s.acquire(PriorityBatch)
+
+	// 名额被占用时先排队一个批处理推理，再排队一个实时推理
+	order := make(chan Priority, 2)
+	var wg sync.WaitGroup
+	for _, p := range []Priority{PriorityBatch, PriorityRealtime} {
+		wg.Add(1)
+		go func(p Priority) {
+			defer wg.Done()
+			s.acquire(p)
+			order <- p
+			s.release()
+		}(p)
+		require.Eventually(t, func() bool {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			return len(s.waiting[p]) == 1
+		}, time.Second, time.Millisecond)
+	}
+
+	// 归还的名额先交给后排队的实时推理
+	s.release()
+	wg.Wait()
+	require.Equal(t, PriorityRealtime, <-order)
+	require.Equal(t, PriorityBatch, <-order)
+	require.Equal(t, 1, s.free)
+}
+
+func TestContextPriority(t *testing.T) {
+	samples := readTestSamples(t, "../testfiles/samples.pcm")
+
+	_, err := NewSharedModel(DetectorConfig{
+		ModelPath:               "../testfiles/silero_vad.onnx",
+		SampleRate:              16000,
+		Threshold:               0.5,
+		MaxConcurrentInferences: -1,
+	})
+	require.Error(t, err)
+
+	sm, err := NewSharedModel(DetectorConfig{
+		ModelPath:               "../testfiles/silero_vad.onnx",
+		SampleRate:              16000,
+		Threshold:               0.5,
+		MaxConcurrentInferences: 1,
+	})
+	require.NoError(t, err)
+	defer sm.Destroy()
+
+	ref := newTestSharedModel(t).NewContext()
+	defer ref.Close()
+	expected, err := ref.Detect(samples)
+	require.NoError(t, err)
+
+	dc := sm.NewContext()
+	require.Equal(t, PriorityRealtime, dc.Priority())
+	dc.SetPriority(Priority(7))
+	require.Equal(t, PriorityRealtime, dc.Priority())
+	require.NoError(t, dc.Close())
+
+	// 限制推理名额后，不同优先级的上下文并发检测的结果不变
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			dc := sm.NewContext()
+			defer dc.Close()
+			if i%2 == 1 {
+				dc.SetPriority(PriorityBatch)
+			}
+			segments, err := dc.Detect(samples)
+			require.NoError(t, err)
+			require.Equal(t, expected, segments)
+		}(i)
+	}
+	wg.Wait()
+
+	// 归还的上下文恢复默认优先级
+	dc = sm.GetContext()
+	dc.SetPriority(PriorityBatch)
+	sm.PutContext(dc)
+	require.Equal(t, PriorityRealtime, sm.GetContext().Priority())
+
+	require.Error(t, sm.NewContext().WithConfig(func(cfg *DetectorConfig) {
+		cfg.MaxConcurrentInferences = 2
+	}))
+}
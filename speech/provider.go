@@ -0,0 +1,114 @@
+package speech
+
+// #cgo CFLAGS: -Wall -Werror -std=c99
+// #cgo LDFLAGS: -lonnxruntime
+// #include <stdlib.h>
+// #include "ort_bridge.h"
+import "C"
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"unsafe"
+)
+
+// 内置的执行提供者名称
+const (
+	ProviderCPU  = "cpu"
+	ProviderCUDA = "cuda"
+)
+
+// ExecutionProvider 模型推理使用的执行提供者（EP）及其选项，零值表示 CPU
+// 除 ProviderCPU 和 ProviderCUDA 外，Name 按 ONNX Runtime 通用接口 SessionOptionsAppendExecutionProvider
+// 的名称传入（例如 "XNNPACK"、"QNN"），Options 为该提供者的键值选项。
+// 所用的 ONNX Runtime 未编译对应的提供者时，创建模型返回错误。
+type ExecutionProvider struct {
+	Name    string            `json:"name" yaml:"name"`
+	Options map[string]string `json:"options" yaml:"options"`
+}
+
+// CPU 返回默认的 CPU 执行提供者
+func CPU() ExecutionProvider {
+	return ExecutionProvider{Name: ProviderCPU}
+}
+
+// CUDA 返回使用第 deviceID 块 GPU 的 CUDA 执行提供者
+func CUDA(deviceID int) ExecutionProvider {
+	return ExecutionProvider{
+		Name:    ProviderCUDA,
+		Options: map[string]string{"device_id": strconv.Itoa(deviceID)},
+	}
+}
+
+// String 返回提供者名称，零值为 cpu
+func (ep ExecutionProvider) String() string {
+	if ep.Name == "" {
+		return ProviderCPU
+	}
+	return ep.Name
+}
+
+// isCPU 是否只使用 CPU，不需要追加提供者
+func (ep ExecutionProvider) isCPU() bool {
+	return ep.Name == "" || ep.Name == ProviderCPU
+}
+
+// IsValid 校验执行提供者
+func (ep ExecutionProvider) IsValid() error {
+	if ep.isCPU() && len(ep.Options) > 0 {
+		return fmt.Errorf("invalid ExecutionProvider: the cpu provider takes no options")
+	}
+	return nil
+}
+
+// equal 比较两个执行提供者是否相同
+func (ep ExecutionProvider) equal(other ExecutionProvider) bool {
+	return ep.String() == other.String() && maps.Equal(ep.Options, other.Options)
+}
+
+// appendExecutionProvider 在会话选项上追加配置的执行提供者，CPU 不需要追加
+func appendExecutionProvider(api *C.OrtApi, opts *C.OrtSessionOptions, ep ExecutionProvider) error {
+	if ep.isCPU() {
+		return nil
+	}
+
+	// 按键排序，使追加顺序确定
+	keys := make([]string, 0, len(ep.Options))
+	for k := range ep.Options {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	cKeys := make([]*C.char, len(keys))
+	cValues := make([]*C.char, len(keys))
+	for i, k := range keys {
+		cKeys[i] = C.CString(k)
+		cValues[i] = C.CString(ep.Options[k])
+	}
+	defer func() {
+		for i := range cKeys {
+			C.free(unsafe.Pointer(cKeys[i]))
+			C.free(unsafe.Pointer(cValues[i]))
+		}
+	}()
+	var keysPtr, valuesPtr **C.char
+	if len(keys) > 0 {
+		keysPtr, valuesPtr = &cKeys[0], &cValues[0]
+	}
+
+	var status *C.OrtStatus
+	if ep.Name == ProviderCUDA {
+		status = C.OrtApiSessionOptionsAppendCUDA(api, opts, keysPtr, valuesPtr, C.size_t(len(keys)))
+	} else {
+		name := C.CString(ep.Name)
+		defer C.free(unsafe.Pointer(name))
+		status = C.OrtApiSessionOptionsAppendExecutionProvider(api, opts, name, keysPtr, valuesPtr, C.size_t(len(keys)))
+	}
+	if status != nil {
+		err := newOrtError(api, status)
+		C.OrtApiReleaseStatus(api, status)
+		return fmt.Errorf("failed to append %s execution provider: %w", ep, err)
+	}
+	return nil
+}
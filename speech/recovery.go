@@ -0,0 +1,172 @@
+package speech
+
+// #cgo CFLAGS: -Wall -Werror -std=c99
+// #cgo LDFLAGS: -lonnxruntime
+// #include "ort_bridge.h"
+import "C"
+
+import (
+	"errors"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// recoveryBackoff 重建会话失败后，至少间隔这么久才会再次尝试
+const recoveryBackoff = time.Second
+
+// HealthEvent 一次会话恢复的结果，见 DetectorConfig.RecoverAfterFailures
+type HealthEvent struct {
+	// 触发恢复的推理错误
+	Err error
+	// 触发恢复时连续失败的推理次数
+	Failures int
+	// 重建会话的错误，nil 表示恢复成功
+	RecoverErr error
+	// 重建所有会话的耗时
+	Duration time.Duration
+}
+
+// sessionRecovery 连续推理失败后重建会话池
+// 推理期间持有读锁，重建时持有写锁，因此替换会话时没有进行中的推理。
+// 只在配置了 RecoverAfterFailures 时创建，未启用时推理热路径不加锁。
+type sessionRecovery struct {
+	threshold int32
+
+	mu         sync.RWMutex
+	generation uint64    // 每次成功重建后加一，用于识别已被其它协程恢复的失败
+	lastFailed time.Time // 上次重建失败的时间
+
+	failures   atomic.Int32 // 连续失败的推理次数，任意一次成功后清零
+	recoveries atomic.Uint64
+	observer   atomic.Pointer[func(HealthEvent)]
+}
+
+// SetHealthObserver 设置会话恢复的回调，每次尝试重建会话后调用，传入 nil 可取消
+// 回调在触发恢复的检测协程中同步执行，可以用于告警或更新健康检查状态。
+// 未配置 RecoverAfterFailures 时回调不会被调用。
+func (sm *SharedModel) SetHealthObserver(fn func(HealthEvent)) {
+	if sm.recovery == nil {
+		return
+	}
+	if fn == nil {
+		sm.recovery.observer.Store(nil)
+		return
+	}
+	sm.recovery.observer.Store(&fn)
+}
+
+// inferWindow 推理一个窗口
+// 启用恢复时，ORT 连续失败达到 RecoverAfterFailures 次后重建所有会话并重试该窗口一次。
+func (dc *DetectorContext) inferWindow(window []float32) (float32, error) {
+	r := dc.model.recovery
+	if r == nil {
+		return dc.inferOnce(window)
+	}
+
+	r.mu.RLock()
+	gen := r.generation
+	prob, err := dc.inferOnce(window)
+	r.mu.RUnlock()
+	if err == nil {
+		r.succeeded()
+		return prob, nil
+	}
+
+	var ortErr *OrtError
+	if !errors.As(err, &ortErr) || r.failures.Add(1) < r.threshold {
+		return 0, err
+	}
+	if !dc.model.recoverSessions(gen, err) {
+		return 0, err
+	}
+
+	r.mu.RLock()
+	prob, err = dc.inferOnce(window)
+	r.mu.RUnlock()
+	if err != nil {
+		r.failures.Add(1)
+		return 0, err
+	}
+	r.succeeded()
+	return prob, nil
+}
+
+// inferOnce 按是否启用批量推理选择推理方式
+func (dc *DetectorContext) inferOnce(window []float32) (float32, error) {
+	if b := dc.model.batcher; b != nil {
+		return b.infer(dc, window)
+	}
+	return dc.infer(window)
+}
+
+func (r *sessionRecovery) succeeded() {
+	if r.failures.Load() != 0 {
+		r.failures.Store(0)
+	}
+}
+
+// recoverSessions 重建会话池，返回之后是否可以重试
+// gen 为失败的推理开始时的代数，其它协程已经完成重建时直接返回 true。
+func (sm *SharedModel) recoverSessions(gen uint64, cause error) bool {
+	r := sm.recovery
+	r.mu.Lock()
+	if r.generation != gen {
+		r.mu.Unlock()
+		return true
+	}
+	if time.Since(r.lastFailed) < recoveryBackoff {
+		r.mu.Unlock()
+		return false
+	}
+
+	event := HealthEvent{Err: cause, Failures: int(r.failures.Load())}
+	start := time.Now()
+	event.RecoverErr = sm.rebuildSessions()
+	event.Duration = time.Since(start)
+	if event.RecoverErr != nil {
+		r.lastFailed = time.Now()
+	} else {
+		r.generation++
+		r.failures.Store(0)
+		r.recoveries.Add(1)
+	}
+	r.mu.Unlock()
+
+	if event.RecoverErr != nil {
+		slog.Error("failed to recover sessions", slog.Int("failures", event.Failures), slog.Any("error", event.RecoverErr))
+	} else {
+		slog.Warn("sessions recovered after repeated inference failures", slog.Int("failures", event.Failures), slog.Any("error", cause))
+	}
+	if fn := r.observer.Load(); fn != nil {
+		(*fn)(event)
+	}
+	return event.RecoverErr == nil
+}
+
+// rebuildSessions 创建一组新会话替换会话池，调用方需持有恢复写锁
+// 任意一个会话创建失败时保留原有会话。
+func (sm *SharedModel) rebuildSessions() error {
+	cfg := *sm.config()
+	sessions := make([]*C.OrtSession, len(sm.sessions))
+	for i := range sessions {
+		session, err := sm.createSession(i, cfg)
+		if err != nil {
+			for _, s := range sessions[:i] {
+				C.OrtApiReleaseSession(sm.api, s)
+			}
+			trackFree(nativeSession, i)
+			return err
+		}
+		sessions[i] = session
+	}
+
+	// 原地替换，批量调度器持有同一个底层数组
+	for i, session := range sessions {
+		C.OrtApiReleaseSession(sm.api, sm.sessions[i])
+		sm.sessions[i] = session
+	}
+	trackFree(nativeSession, len(sessions))
+	return nil
+}
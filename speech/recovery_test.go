@@ -0,0 +1,72 @@
+package speech
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionRecovery(t *testing.T) {
+	samples := readTestSamples(t, "../testfiles/samples.pcm")
+
+	_, err := NewSharedModel(DetectorConfig{
+		ModelPath:            "../testfiles/silero_vad.onnx",
+		SampleRate:           16000,
+		Threshold:            0.5,
+		RecoverAfterFailures: -1,
+	})
+	require.Error(t, err)
+
+	sm, err := NewSharedModel(DetectorConfig{
+		ModelPath:            "../testfiles/silero_vad.onnx",
+		SampleRate:           16000,
+		Threshold:            0.5,
+		SessionPoolSize:      2,
+		RecoverAfterFailures: 3,
+	})
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, sm.Destroy())
+	}()
+
+	var events []HealthEvent
+	sm.SetHealthObserver(func(e HealthEvent) {
+		events = append(events, e)
+	})
+
+	dc := sm.NewContext()
+	defer dc.Close()
+	expected, err := dc.Detect(samples)
+	require.NoError(t, err)
+	require.NoError(t, dc.Reset())
+
+	// 重建后已有的上下文使用新会话，检测结果不变
+	old := slices.Clone(sm.sessions)
+	cause := &OrtError{Code: OrtEngineError, Msg: "device lost"}
+	sm.recovery.failures.Store(3)
+	require.True(t, sm.recoverSessions(0, cause))
+	for i := range old {
+		require.True(t, old[i] != sm.sessions[i])
+	}
+	require.Zero(t, sm.recovery.failures.Load())
+	require.EqualValues(t, 1, sm.Stats().Recoveries)
+	require.Len(t, events, 1)
+	require.True(t, errors.Is(events[0].Err, cause))
+	require.Equal(t, 3, events[0].Failures)
+	require.NoError(t, events[0].RecoverErr)
+
+	segments, err := dc.Detect(samples)
+	require.NoError(t, err)
+	require.Equal(t, expected, segments)
+
+	// 其它协程已经完成重建时不会重复重建
+	require.True(t, sm.recoverSessions(0, cause))
+	require.EqualValues(t, 1, sm.Stats().Recoveries)
+	require.Len(t, events, 1)
+
+	require.Error(t, dc.WithConfig(func(cfg *DetectorConfig) {
+		cfg.RecoverAfterFailures = 1
+	}))
+}
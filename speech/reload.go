@@ -0,0 +1,88 @@
+package speech
+
+import (
+	"fmt"
+	"slices"
+)
+
+// configSnapshot 带版本号的只读配置
+// 存入的配置是深拷贝，不与调用方共享指针、切片和映射字段。
+// 版本号在同一个模型内唯一且递增，模型创建时的配置为 1，UpdateConfig 和 WithConfig 各分配一个新版本。
+type configSnapshot struct {
+	cfg     DetectorConfig
+	version uint64
+}
+
+// UpdateConfig 原子地替换模型的配置并返回新配置的版本号，用于在线调整检测参数
+// 只能修改检测参数和推理前预处理，规则与 WithConfig 相同；修改模型相关的字段或配置无效时返回错误且配置保持不变。
+// 没有调用过 WithConfig 的上下文从下一次检测调用开始使用新配置，正在进行的调用继续使用旧配置；
+// 调用过 WithConfig 的上下文保留各自的配置。检测调用使用的版本可以通过 DetectorContext.ConfigVersion 查询。
+func (sm *SharedModel) UpdateConfig(cfg DetectorConfig) (uint64, error) {
+	if sm == nil {
+		return 0, fmt.Errorf("invalid nil shared model")
+	}
+
+	cfg.resolveSampleRate()
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if field := fixedFieldChanged(&cfg, sm.config()); field != "" {
+		return 0, fmt.Errorf("%w: %s cannot be changed after the model is created", ErrInvalidConfig, field)
+	}
+	if err := cfg.IsValid(); err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+	}
+
+	s := &configSnapshot{cfg: cfg.clone(), version: sm.versions.Add(1)}
+	sm.cfg.Store(s)
+	return s.version, nil
+}
+
+// ConfigVersion 返回模型当前配置的版本号
+func (sm *SharedModel) ConfigVersion() uint64 {
+	return sm.cfg.Load().version
+}
+
+// ConfigVersion 返回该上下文最近一次检测调用使用的配置版本号，尚未检测过时返回 0
+// 与检测结果一起记录，可以追溯每个结果由哪一版配置产生。
+func (dc *DetectorContext) ConfigVersion() uint64 {
+	if dc == nil {
+		return 0
+	}
+	return dc.lastVersion
+}
+
+// fixedFieldChanged 返回 cfg 相对 base 修改了的模型相关字段名，没有修改时返回空字符串
+// 这些字段决定了会话、调度和线程的创建方式，只能在创建模型时指定。
+func fixedFieldChanged(cfg, base *DetectorConfig) string {
+	switch {
+	case cfg.ModelPath != base.ModelPath:
+		return "ModelPath"
+	case cfg.SampleRate != base.SampleRate:
+		return "SampleRate"
+	case cfg.inputRate() != base.inputRate():
+		return "InputSampleRate"
+	case cfg.LogLevel != base.LogLevel:
+		return "LogLevel"
+	case cfg.SessionPoolSize != base.SessionPoolSize:
+		return "SessionPoolSize"
+	case cfg.MaxConcurrentInferences != base.MaxConcurrentInferences:
+		return "MaxConcurrentInferences"
+	case cfg.MaxBatchSize != base.MaxBatchSize || cfg.MaxBatchDelay != base.MaxBatchDelay:
+		return "batching"
+	case cfg.Deterministic != base.Deterministic:
+		return "Deterministic"
+	case cfg.RecoverAfterFailures != base.RecoverAfterFailures:
+		return "RecoverAfterFailures"
+	case !slices.EqualFunc(cfg.SessionCPUs, base.SessionCPUs, slices.Equal[[]int]):
+		return "SessionCPUs"
+	case !cfg.Denoiser.equal(base.Denoiser):
+		return "Denoiser"
+	case !cfg.Classifier.equal(base.Classifier):
+		return "Classifier"
+	case !cfg.ExecutionProvider.equal(base.ExecutionProvider):
+		return "ExecutionProvider"
+	}
+	return ""
+}
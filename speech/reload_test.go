@@ -0,0 +1,119 @@
+package speech
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateConfig(t *testing.T) {
+	sm := newTestSharedModel(t)
+	samples := readTestSamples(t, "../testfiles/samples.pcm")
+	require.EqualValues(t, 1, sm.ConfigVersion())
+
+	dc := sm.NewContext()
+	defer dc.Close()
+	tuned := sm.NewContext()
+	defer tuned.Close()
+	require.NoError(t, tuned.WithConfig(func(cfg *DetectorConfig) {
+		cfg.SpeechPadMs = 0
+	}))
+	require.Zero(t, dc.ConfigVersion())
+
+	before, err := dc.Detect(samples)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, dc.ConfigVersion())
+
+	// 不合法或修改模型相关字段的配置被拒绝，版本不变
+	cfg := sm.GetConfig()
+	cfg.Threshold = 1.5
+	_, err = sm.UpdateConfig(cfg)
+	require.ErrorIs(t, err, ErrInvalidConfig)
+	cfg = sm.GetConfig()
+	cfg.SessionPoolSize = 4
+	_, err = sm.UpdateConfig(cfg)
+	require.ErrorIs(t, err, ErrInvalidConfig)
+	require.EqualValues(t, 1, sm.ConfigVersion())
+
+	// 新配置从下一次检测调用开始生效，版本号随结果可查
+	cfg = sm.GetConfig()
+	cfg.Threshold = 0.9
+	version, err := sm.UpdateConfig(cfg)
+	require.NoError(t, err)
+	require.Equal(t, version, sm.ConfigVersion())
+	require.Greater(t, version, uint64(2))
+	require.Equal(t, float32(0.9), dc.GetConfig().Threshold)
+
+	require.NoError(t, dc.Reset())
+	after, err := dc.Detect(samples)
+	require.NoError(t, err)
+	require.Equal(t, version, dc.ConfigVersion())
+	require.NotEqual(t, before, after)
+
+	// 单独修改过配置的上下文保留自己的配置和版本
+	_, err = tuned.Detect(samples)
+	require.NoError(t, err)
+	require.Equal(t, float32(0.5), tuned.GetConfig().Threshold)
+	require.EqualValues(t, 2, tuned.ConfigVersion())
+
+	// 回收的上下文重新跟随模型配置
+	pooled := sm.GetContext()
+	require.NoError(t, pooled.WithConfig(func(cfg *DetectorConfig) {
+		cfg.Threshold = 0.3
+	}))
+	sm.PutContext(pooled)
+	pooled = sm.GetContext()
+	require.Equal(t, float32(0.9), pooled.GetConfig().Threshold)
+	sm.PutContext(pooled)
+}
+
+func TestUpdateConfigOptionalModels(t *testing.T) {
+	newConfig := func() DetectorConfig {
+		cfg := DefaultConfig("../testfiles/silero_vad.onnx")
+		cfg.Denoiser = &DenoiserConfig{
+			ModelPath:       "../testfiles/denoiser_test.onnx",
+			FrameSize:       480,
+			StateInputName:  "state",
+			StateOutputName: "stateN",
+			StateShape:      []int64{2},
+		}
+		cfg.Classifier = &ClassifierConfig{
+			ModelPath:   "../testfiles/classifier_test.onnx",
+			FrameSize:   8000,
+			NumClasses:  2,
+			SpeechClass: 1,
+		}
+		return cfg
+	}
+	sm, err := NewSharedModel(newConfig())
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, sm.Destroy())
+	}()
+
+	// 降噪和分类模型按值比较，单独构造的相同配置可以重新加载
+	cfg := newConfig()
+	cfg.Threshold = 0.7
+	_, err = sm.UpdateConfig(cfg)
+	require.NoError(t, err)
+	require.Equal(t, float32(0.7), sm.GetConfig().Threshold)
+
+	cfg = newConfig()
+	cfg.Denoiser.StateShape = []int64{3}
+	_, err = sm.UpdateConfig(cfg)
+	require.ErrorContains(t, err, "Denoiser cannot be changed")
+	cfg = newConfig()
+	cfg.Classifier.FrameSize = 4000
+	_, err = sm.UpdateConfig(cfg)
+	require.ErrorContains(t, err, "Classifier cannot be changed")
+
+	dc := sm.NewContext()
+	defer dc.Close()
+	require.NoError(t, dc.WithConfig(func(cfg *DetectorConfig) {
+		cfg.Denoiser = newConfig().Denoiser
+		cfg.SpeechPadMs = 0
+	}))
+	require.Error(t, dc.WithConfig(func(cfg *DetectorConfig) {
+		cfg.Classifier = nil
+	}))
+}
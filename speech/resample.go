@@ -0,0 +1,48 @@
+package speech
+
+import (
+	"fmt"
+
+	"github.com/rui-yang-me/silero-vad-go/audio"
+)
+
+// resolveSampleRate 配置了 AutoResample 且 SampleRate 不被模型支持时，改为以模型支持的采样率推理
+// 原采样率移到 InputSampleRate，低于 16kHz 的输入按 8kHz 推理，其余按 16kHz。已解析的配置再次调用不会改变。
+func (c *DetectorConfig) resolveSampleRate() {
+	if !c.AutoResample || c.SampleRate <= 0 || c.SampleRate == 8000 || c.SampleRate == 16000 {
+		return
+	}
+	c.InputSampleRate = c.SampleRate
+	c.SampleRate = 16000
+	if c.InputSampleRate < 16000 {
+		c.SampleRate = 8000
+	}
+}
+
+// inputRate 返回检测调用传入的音频的采样率
+func (c *DetectorConfig) inputRate() int {
+	if c.InputSampleRate > 0 {
+		return c.InputSampleRate
+	}
+	return c.SampleRate
+}
+
+// resample 把 InputSampleRate 采样率的输入转换为模型采样率，不需要重采样时直接返回 pcm
+// 重采样器在调用之间保留滤波器历史，使分块传入的音频与整段传入的结果一致；
+// 其固定延迟使最后几毫秒的音频留在重采样器中，直到后续调用传入更多音频。
+// 返回的切片在下一次调用前有效。
+func (p *preprocessor) resample(cfg *DetectorConfig, pcm []float32) ([]float32, error) {
+	rate := cfg.inputRate()
+	if rate == cfg.SampleRate {
+		return pcm, nil
+	}
+	if p.resampler == nil {
+		r, err := audio.NewResampler(rate, cfg.SampleRate)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create resampler: %w", err)
+		}
+		p.resampler = r
+	}
+	p.resampled = p.resampler.Process(p.resampled[:0], pcm)
+	return p.resampled, nil
+}
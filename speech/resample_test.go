@@ -0,0 +1,100 @@
+package speech
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/rui-yang-me/silero-vad-go/audio"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAutoResample(t *testing.T) {
+	samples := readTestSamples(t, "../testfiles/samples.pcm")
+	ref := newTestSharedModel(t).NewContext()
+	expected, err := ref.Detect(samples)
+	require.NoError(t, err)
+	require.NoError(t, ref.Close())
+
+	cfg := DetectorConfig{
+		ModelPath:  "../testfiles/silero_vad.onnx",
+		SampleRate: 48000,
+		Threshold:  0.5,
+	}
+	_, err = NewSharedModel(cfg)
+	require.ErrorIs(t, err, ErrInvalidConfig)
+
+	cfg.AutoResample = true
+	require.NoError(t, cfg.IsValid())
+	sm, err := NewSharedModel(cfg)
+	require.NoError(t, err)
+	defer sm.Destroy()
+
+	// 报告实际的模型采样率，原采样率保存在 InputSampleRate
+	effective := sm.GetConfig()
+	require.Equal(t, 16000, effective.SampleRate)
+	require.Equal(t, 48000, effective.InputSampleRate)
+	_, err = sm.UpdateConfig(cfg)
+	require.NoError(t, err)
+	effective.InputSampleRate = 44100
+	_, err = sm.UpdateConfig(effective)
+	require.ErrorIs(t, err, ErrInvalidConfig)
+
+	// 48kHz 的输入检测结果与原始 16kHz 音频接近
+	input, err := audio.Resample(samples, 16000, 48000)
+	require.NoError(t, err)
+	requireClose := func(segments []Segment) {
+		t.Helper()
+		require.Len(t, segments, len(expected))
+		for i, seg := range segments {
+			require.InDelta(t, expected[i].SpeechStartAt, seg.SpeechStartAt, 0.1)
+			require.InDelta(t, expected[i].SpeechEndAt, seg.SpeechEndAt, 0.1)
+		}
+	}
+
+	dc := sm.NewContext()
+	defer dc.Close()
+	segments, err := dc.Detect(input)
+	require.NoError(t, err)
+	requireClose(segments)
+
+	speech, err := dc.IsSpeech(input)
+	require.NoError(t, err)
+	require.True(t, speech)
+
+	var streamed []Segment
+	require.NoError(t, dc.Reset())
+	s, err := dc.NewStream(StreamConfig{
+		MaxQueue: 100 * time.Millisecond,
+		OnSegments: func(segs []Segment) {
+			streamed = mergeSegments(streamed, segs)
+		},
+	})
+	require.NoError(t, err)
+	for off := 0; off < len(input); off += 960 {
+		_, err := s.Push(input[off:min(off+960, len(input))])
+		require.NoError(t, err)
+	}
+	require.NoError(t, s.Close())
+	requireClose(streamed)
+
+	// 原始字节按输入采样率解析
+	require.NoError(t, dc.Reset())
+	raw := make([]byte, 0, len(input)*4)
+	for _, v := range input {
+		raw = binary.LittleEndian.AppendUint32(raw, math.Float32bits(v))
+	}
+	segments, err = dc.DetectBytes(raw, audio.FormatFloat32)
+	require.NoError(t, err)
+	requireClose(segments)
+	require.NoError(t, dc.Reset())
+	segments, err = dc.DetectReader(bytes.NewReader(raw), audio.FormatFloat32)
+	require.NoError(t, err)
+	requireClose(segments)
+
+	_, err = NewGatedWriter(io.Discard, dc, audio.FormatPCM16, 0)
+	require.Error(t, err)
+}
@@ -0,0 +1,96 @@
+package speech
+
+import (
+	"sync"
+	"time"
+)
+
+// RTFReport 一段时间内处理音频的速度，用于容量规划
+type RTFReport struct {
+	// 统计区间的墙钟时长；上下文的报告中为检测调用的累计耗时
+	Elapsed time.Duration
+	// 区间内推理的音频时长
+	Audio time.Duration
+	// 区间内推理的累计耗时，并发时为各协程之和
+	Inference time.Duration
+	// 区间内推理的窗口数
+	Windows uint64
+}
+
+// Speed 返回每墙钟秒处理的音频秒数，模型级报告中即所有并发流合计的吞吐
+func (r RTFReport) Speed() float64 {
+	if r.Elapsed <= 0 {
+		return 0
+	}
+	return r.Audio.Seconds() / r.Elapsed.Seconds()
+}
+
+// RealTimeFactor 返回推理耗时与音频时长之比，小于 1 表示快于实时
+func (r RTFReport) RealTimeFactor() float64 {
+	if r.Audio <= 0 {
+		return 0
+	}
+	return r.Inference.Seconds() / r.Audio.Seconds()
+}
+
+// StreamsPerCore 估算每个 CPU 核心能同时支撑的实时流数量
+// 每个会话使用单线程推理，因此等于 1 / RealTimeFactor。
+func (r RTFReport) StreamsPerCore() float64 {
+	if r.Inference <= 0 {
+		return 0
+	}
+	return r.Audio.Seconds() / r.Inference.Seconds()
+}
+
+// contextUsage 单个上下文的累计用量，只在持有该上下文的协程中访问
+type contextUsage struct {
+	inferences uint64
+	inferTime  time.Duration
+	samples    int64
+	wallTime   time.Duration // 检测调用内的累计耗时，包括预处理
+	callStart  time.Time
+}
+
+// RTF 返回该上下文自创建以来的速度统计，Reset 不会清零
+// Elapsed 为检测调用的累计耗时（包括预处理），因此 Speed 反映单个流能达到的最高速度。
+func (dc *DetectorContext) RTF() RTFReport {
+	if dc == nil || dc.model == nil {
+		return RTFReport{}
+	}
+	return RTFReport{
+		Elapsed:   dc.usage.wallTime,
+		Audio:     time.Duration(dc.usage.samples) * time.Second / time.Duration(dc.config().SampleRate),
+		Inference: dc.usage.inferTime,
+		Windows:   dc.usage.inferences,
+	}
+}
+
+// RTFMeter 按区间统计整个模型的速度，可以在任意协程中调用
+type RTFMeter struct {
+	model *SharedModel
+
+	mu   sync.Mutex
+	last ModelStats
+	at   time.Time
+}
+
+// NewRTFMeter 创建速度统计，第一个区间从现在开始
+func (sm *SharedModel) NewRTFMeter() *RTFMeter {
+	return &RTFMeter{model: sm, last: sm.Stats(), at: time.Now()}
+}
+
+// Report 返回从上次调用（或创建）到现在的统计，并开始新的区间
+func (m *RTFMeter) Report() RTFReport {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now, stats := time.Now(), m.model.Stats()
+	r := RTFReport{
+		Elapsed:   now.Sub(m.at),
+		Audio:     stats.AudioProcessed - m.last.AudioProcessed,
+		Inference: stats.InferenceTime - m.last.InferenceTime,
+		Windows:   stats.Inferences - m.last.Inferences,
+	}
+	m.last, m.at = stats, now
+	return r
+}
@@ -0,0 +1,52 @@
+package speech
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRTF(t *testing.T) {
+	sm := newTestSharedModel(t)
+	samples := readTestSamples(t, "../testfiles/samples.pcm")
+	windows := (len(samples) - 1) / 512
+	audioLen := time.Duration(windows*512) * time.Second / 16000
+
+	meter := sm.NewRTFMeter()
+
+	var wg sync.WaitGroup
+	reports := make([]RTFReport, 3)
+	for i := range reports {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			dc := sm.NewContext()
+			defer dc.Close()
+			_, err := dc.Detect(samples)
+			require.NoError(t, err)
+			reports[i] = dc.RTF()
+		}(i)
+	}
+	wg.Wait()
+
+	for _, r := range reports {
+		require.EqualValues(t, windows, r.Windows)
+		require.Equal(t, audioLen, r.Audio)
+		require.LessOrEqual(t, r.Inference, r.Elapsed)
+		require.Greater(t, r.Speed(), 1.0)
+		require.InDelta(t, 1/r.RealTimeFactor(), r.StreamsPerCore(), 1e-9)
+	}
+
+	r := meter.Report()
+	require.EqualValues(t, 3*windows, r.Windows)
+	require.Equal(t, 3*audioLen, r.Audio)
+	require.Greater(t, r.Speed(), 1.0)
+
+	// 新区间从上次 Report 开始
+	r = meter.Report()
+	require.Zero(t, r.Windows)
+	require.Zero(t, r.Speed())
+	require.Zero(t, r.RealTimeFactor())
+}
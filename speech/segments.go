@@ -0,0 +1,168 @@
+package speech
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/rui-yang-me/silero-vad-go/audio"
+)
+
+// sampleRange 将片段的时间戳换算为 pcm 中的采样区间 [start, end)
+// SpeechEndAt 为 0 表示语音持续到音频结尾。时间戳由采样数换算而来，
+// 这里四舍五入以免浮点误差导致差一个采样。
+func (s Segment) sampleRange(sampleRate, n int) (int, int) {
+	start := int(math.Round(s.SpeechStartAt * float64(sampleRate)))
+	end := n
+	if s.SpeechEndAt > 0 {
+		end = int(math.Round(s.SpeechEndAt * float64(sampleRate)))
+	}
+
+	if start < 0 {
+		start = 0
+	}
+	if end > n {
+		end = n
+	}
+	if start > end {
+		start = end
+	}
+
+	return start, end
+}
+
+// ExtractSegments 返回每个语音片段对应的音频，结果直接引用 pcm 的数据而不复制
+// 片段边界直接使用检测结果，其中已经包含 SpeechPadMs 指定的填充；未结束的片段
+// 延伸到 pcm 结尾，超出范围的部分会被截断。相邻片段因填充而重叠时会共享数据，
+// 返回的切片容量被限制在片段末尾，对其 append 不会覆盖后面的音频。
+func ExtractSegments(pcm []float32, sampleRate int, segments []Segment) ([][]float32, error) {
+	if sampleRate <= 0 {
+		return nil, fmt.Errorf("invalid sample rate: %d", sampleRate)
+	}
+
+	out := make([][]float32, len(segments))
+	for i, seg := range segments {
+		start, end := seg.sampleRange(sampleRate, len(pcm))
+		out[i] = pcm[start:end:end]
+	}
+
+	return out, nil
+}
+
+// RemoveSilence 只保留语音片段并依次拼接，生成便于人工快速审听或降低 ASR 成本的“浓缩”音频
+// 每个拼接点使用 crossfade 时长的线性交叉淡化以避免咔嗒声（不超过相邻片段本身的长度），
+// 因填充而重叠的片段会先合并，避免同一段音频出现两次。返回新分配的切片。
+func RemoveSilence(pcm []float32, sampleRate int, segments []Segment, crossfade time.Duration) ([]float32, error) {
+	if sampleRate <= 0 {
+		return nil, fmt.Errorf("invalid sample rate: %d", sampleRate)
+	}
+	if crossfade < 0 {
+		return nil, fmt.Errorf("invalid crossfade: %s", crossfade)
+	}
+
+	// 合并重叠或相接的片段
+	var ranges [][2]int
+	for _, seg := range segments {
+		start, end := seg.sampleRange(sampleRate, len(pcm))
+		if start == end {
+			continue
+		}
+		if n := len(ranges); n > 0 && start <= ranges[n-1][1] {
+			ranges[n-1][1] = max(ranges[n-1][1], end)
+			continue
+		}
+		ranges = append(ranges, [2]int{start, end})
+	}
+
+	total := 0
+	for _, r := range ranges {
+		total += r[1] - r[0]
+	}
+
+	fadeSamples := int(crossfade.Seconds() * float64(sampleRate))
+	out := make([]float32, 0, total)
+	prevLen := 0
+	for _, r := range ranges {
+		clip := pcm[r[0]:r[1]]
+		n := min(fadeSamples, prevLen, len(clip))
+
+		tail := out[len(out)-n:]
+		for i := range tail {
+			w := (float32(i) + 0.5) / float32(n)
+			tail[i] = tail[i]*(1-w) + clip[i]*w
+		}
+		out = append(out, clip[n:]...)
+		prevLen = len(clip)
+	}
+
+	return out, nil
+}
+
+// ExportSegmentsWAV 将每个语音片段裁剪为单独的 16 位单声道 WAV 文件写入 dir
+// 片段边界直接使用检测结果，其中已经包含 SpeechPadMs 指定的填充。
+// 返回按片段顺序生成的文件路径。
+func ExportSegmentsWAV(pcm []float32, sampleRate int, segments []Segment, dir string) ([]string, error) {
+	clips, err := ExtractSegments(pcm, sampleRate, segments)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create output dir: %w", err)
+	}
+
+	paths := make([]string, 0, len(segments))
+	for i, clip := range clips {
+		path := filepath.Join(dir, fmt.Sprintf("segment_%03d.wav", i+1))
+		if err := audio.WriteWAVFile(path, clip, audio.WAVInfo{
+			SampleRate: sampleRate,
+			Channels:   1,
+		}); err != nil {
+			return paths, fmt.Errorf("failed to write segment %d: %w", i+1, err)
+		}
+		paths = append(paths, path)
+	}
+
+	return paths, nil
+}
+
+// TrimSilence 去除 pcm 开头和结尾的非语音部分，返回 pcm 的子切片（不复制数据）
+// 保留范围从第一个语音片段的开始到最后一个片段的结束，片段间的停顿不受影响，
+// 边界包含 cfg.SpeechPadMs 指定的填充。未检测到语音时返回空切片。
+// 每次调用都会加载模型，需要处理大量音频时请改用 DetectorContext.TrimSilence。
+func TrimSilence(pcm []float32, cfg DetectorConfig) ([]float32, error) {
+	sm, err := NewSharedModel(cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer sm.Destroy()
+
+	dc := sm.NewContext()
+	defer dc.Close()
+
+	return dc.TrimSilence(pcm)
+}
+
+// TrimSilence 与包级函数 TrimSilence 相同，但复用已加载的模型
+// 检测前会重置上下文状态。
+func (dc *DetectorContext) TrimSilence(pcm []float32) ([]float32, error) {
+	if err := dc.Reset(); err != nil {
+		return nil, err
+	}
+
+	segments, err := dc.Detect(pcm)
+	if err != nil {
+		return nil, err
+	}
+	if len(segments) == 0 {
+		return pcm[:0], nil
+	}
+
+	sampleRate := dc.config().inputRate()
+	start, _ := segments[0].sampleRange(sampleRate, len(pcm))
+	_, end := segments[len(segments)-1].sampleRange(sampleRate, len(pcm))
+
+	return pcm[start:end], nil
+}
@@ -0,0 +1,135 @@
+package speech
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rui-yang-me/silero-vad-go/audio"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractSegments(t *testing.T) {
+	pcm := make([]float32, 16000*3)
+	for i := range pcm {
+		pcm[i] = float32(i)
+	}
+
+	_, err := ExtractSegments(pcm, 0, nil)
+	require.Error(t, err)
+
+	// 1001/16000 再乘回 16000 时略小于 1001，不能被截断为 1000
+	clips, err := ExtractSegments(pcm, 16000, []Segment{
+		{SpeechStartAt: 1001.0 / 16000, SpeechEndAt: 1.632},
+		{SpeechStartAt: 1.5, SpeechEndAt: 2},
+		{SpeechStartAt: 2.5, SpeechEndAt: 10},
+		{SpeechStartAt: 2.75},
+	})
+	require.NoError(t, err)
+	require.Len(t, clips, 4)
+
+	require.Equal(t, float32(1001), clips[0][0])
+	require.Len(t, clips[0], 26112-1001)
+	require.Same(t, &pcm[1001], &clips[0][0])
+
+	// 重叠的片段共享数据，但 append 不会覆盖后面的音频
+	_ = append(clips[0], -1)
+	require.Equal(t, float32(26112), pcm[26112])
+
+	require.Len(t, clips[2], 8000)
+	require.Len(t, clips[3], 4000)
+}
+
+func TestRemoveSilence(t *testing.T) {
+	pcm := make([]float32, 16000)
+	for i := range pcm {
+		pcm[i] = 1
+		if i >= 8000 {
+			pcm[i] = -1
+		}
+	}
+
+	_, err := RemoveSilence(pcm, 16000, nil, -time.Millisecond)
+	require.Error(t, err)
+
+	// 前两个片段重叠会被合并，与第三个片段之间有 10ms 交叉淡化
+	out, err := RemoveSilence(pcm, 16000, []Segment{
+		{SpeechStartAt: 0.1, SpeechEndAt: 0.2},
+		{SpeechStartAt: 0.15, SpeechEndAt: 0.3},
+		{SpeechStartAt: 0.6, SpeechEndAt: 0.8},
+	}, 10*time.Millisecond)
+	require.NoError(t, err)
+	require.Len(t, out, 3200+3200-160)
+
+	require.Equal(t, float32(1), out[0])
+	require.Equal(t, float32(-1), out[len(out)-1])
+	// 淡化区间内从 1 平滑过渡到 -1
+	fade := out[3200-160 : 3200]
+	for i := 1; i < len(fade); i++ {
+		require.Less(t, fade[i], fade[i-1])
+	}
+	require.InDelta(t, 0, fade[80], 0.02)
+
+	out, err = RemoveSilence(pcm, 16000, nil, 0)
+	require.NoError(t, err)
+	require.Empty(t, out)
+}
+
+func TestExportSegmentsWAV(t *testing.T) {
+	pcm := make([]float32, 16000*3)
+	for i := range pcm {
+		pcm[i] = float32(i%100) / 100
+	}
+
+	dir := t.TempDir()
+	paths, err := ExportSegmentsWAV(pcm, 16000, []Segment{
+		{SpeechStartAt: 0.5, SpeechEndAt: 1},
+		{SpeechStartAt: 2.5},
+	}, dir)
+	require.NoError(t, err)
+	require.Len(t, paths, 2)
+
+	samples, info, err := audio.ReadWAVFile(paths[0])
+	require.NoError(t, err)
+	require.Equal(t, 16000, info.SampleRate)
+	require.Len(t, samples, 8000)
+
+	// 未结束的片段延伸到音频结尾
+	samples, _, err = audio.ReadWAVFile(paths[1])
+	require.NoError(t, err)
+	require.Len(t, samples, 8000)
+}
+
+func TestTrimSilence(t *testing.T) {
+	cfg := DetectorConfig{
+		ModelPath:  "../testfiles/silero_vad.onnx",
+		SampleRate: 16000,
+		Threshold:  0.5,
+	}
+	samples := readTestSamples(t, "../testfiles/samples.pcm")
+
+	// 在前后补约 1 秒（整数个窗口）的静音，裁剪结果应从原始音频的第一个片段开始
+	const pad = 32 * 512
+	padded := make([]float32, pad, len(samples)+2*pad)
+	padded = append(padded, samples...)
+	padded = append(padded, make([]float32, pad)...)
+
+	trimmed, err := TrimSilence(padded, cfg)
+	require.NoError(t, err)
+	require.NotEmpty(t, trimmed)
+	require.Less(t, len(trimmed), len(samples))
+
+	dc := newTestSharedModel(t).NewContext()
+	defer dc.Close()
+	segments, err := dc.Detect(samples)
+	require.NoError(t, err)
+	start, _ := segments[0].sampleRange(16000, len(samples))
+	require.Same(t, &padded[pad+start], &trimmed[0])
+
+	// 纯静音返回空切片
+	trimmed, err = TrimSilence(make([]float32, 16000), cfg)
+	require.NoError(t, err)
+	require.Empty(t, trimmed)
+
+	_, err = TrimSilence(samples, DetectorConfig{})
+	require.Error(t, err)
+}
@@ -6,10 +6,16 @@ package speech
 import "C"
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
+	"runtime"
 	"sync"
+	"sync/atomic"
+	"time"
 	"unsafe"
+
+	"github.com/rui-yang-me/silero-vad-go/audio"
 )
 
 // SharedModel 包含可共享的 ONNX 运行时资源
@@ -17,33 +23,78 @@ type SharedModel struct {
 	api         *C.OrtApi
 	env         *C.OrtEnv
 	sessionOpts *C.OrtSessionOptions
-	session     *C.OrtSession
+	sessions    []*C.OrtSession // 会话池，至少包含一个会话
+	nextSession atomic.Uint32   // 轮询分配会话的计数器
 	memoryInfo  *C.OrtMemoryInfo
 	cStrings    map[string]*C.char
-	cfg         DetectorConfig
-	mu          sync.RWMutex // 保护共享资源的读写锁
+	inputNames  [3]*C.char // 推理输入名称，顺序与 infer 中的输入张量一致
+	outputNames [2]*C.char
+	denoiser    *denoiserModel   // 可选的降噪模型，未配置时为 nil
+	classifier  *classifierModel // 可选的语音/音乐分类模型，未配置时为 nil
+	modelBytes  int64            // 所有会话的模型文件字节数之和，见 MemStats
+	sched       *inferScheduler  // 按优先级分配推理名额，未配置 MaxConcurrentInferences 时为 nil
+	batcher     *batchScheduler  // 合并多个上下文窗口的批量推理，未配置 MaxBatchSize 时为 nil
+	recovery    *sessionRecovery // 连续推理失败后重建会话，未配置 RecoverAfterFailures 时为 nil
+	// cfg 保存模型的当前配置，没有单独修改配置的上下文使用它，UpdateConfig 整体替换
+	cfg      atomic.Pointer[configSnapshot]
+	versions atomic.Uint64 // 已分配的最大配置版本号
+	// mu 只在销毁资源时使用，推理热路径不持有
+	mu sync.Mutex
+
+	refs      atomic.Int64 // 尚未 Close 的上下文数量
+	active    atomic.Int64 // 正在进行中的检测调用数量
+	destroyed atomic.Bool
+	released  atomic.Bool   // 原生资源已释放，之后不得再访问任何 ORT 句柄
+	drained   chan struct{} // active 在销毁后归零时发出通知
+	stats     modelStats
+	contexts  sync.Pool // PutContext 归还的上下文，供 GetContext 复用
 }
 
 // DetectorContext 包含每个检测器的独立状态
 type DetectorContext struct {
 	model      *SharedModel
+	slot       int                            // 该上下文绑定的会话在会话池中的下标，会话可能被恢复机制替换
+	cfg        atomic.Pointer[configSnapshot] // WithConfig 设置的只读配置快照，nil 表示使用模型的当前配置
 	state      [stateLen]float32
 	ctx        [contextLen]float32
 	currSample int
 	triggered  bool
 	tempEnd    int
+	startAt    float64 // 当前语音片段的开始时间，用于补全跨调用的片段
+	closed     atomic.Bool
+	pre        preprocessor // 推理前的预处理，滤波状态在调用之间保留
+	observer   func(WindowResult)
+	usage      contextUsage
+	scratch    inferScratch
+	priority   Priority
+	cpus       []int  // 检测调用固定的 CPU，nil 表示不固定
+	unpin      func() // 检测调用期间恢复线程亲和性的函数
+	batch      batchRequest
+	// lastVersion 最近一次检测调用使用的配置版本
+	lastVersion uint64
+	padBuf      []float32        // PadShortInput 补齐短输入的缓冲
+	classifier  *classifierStage // 共享模型配置了分类模型时由 NewContext 设置
+	snr         snrTracker       // EstimateSNR 的功率统计
+	loudness    loudnessTracker  // MeasureLoudness 的响度统计
+	clipped     bool             // 当前片段中出现过超过 MaxClippedRun 的削波
+	duty        *dutyCycler      // 配置了 DutyCycle 时在第一次检测时创建
 }
 
 // NewSharedModel 创建一个可共享的模型实例
 func NewSharedModel(cfg DetectorConfig) (*SharedModel, error) {
+	cfg.resolveSampleRate()
 	if err := cfg.IsValid(); err != nil {
-		return nil, fmt.Errorf("invalid config: %w", err)
+		return nil, fmt.Errorf("%w: %w", ErrInvalidConfig, err)
 	}
 
 	sm := &SharedModel{
-		cfg:      cfg,
 		cStrings: map[string]*C.char{},
 	}
+	sm.cfg.Store(&configSnapshot{cfg: cfg.clone(), version: sm.versions.Add(1)})
+	sm.drained = make(chan struct{}, 1)
+	if cfg.MaxConcurrentInferences > 0 && cfg.MaxBatchSize <= 1 {
+		sm.sched = newInferScheduler(cfg.MaxConcurrentInferences)
+	}
 
 	// 获取 ONNX Runtime API
 	sm.api = C.OrtGetApi()
@@ -51,55 +102,88 @@ func NewSharedModel(cfg DetectorConfig) (*SharedModel, error) {
 		return nil, fmt.Errorf("failed to get API")
 	}
 
+	// 初始化失败时释放已经创建的原生资源，否则 LiveNativeAllocations 会一直报告泄漏
+	created := false
+	defer func() {
+		if !created {
+			sm.releaseNative()
+		}
+	}()
+
 	// 创建环境
 	sm.cStrings["loggerName"] = C.CString("vad_shared")
+	trackAlloc(nativeCString, 1)
 	status := C.OrtApiCreateEnv(sm.api, cfg.LogLevel.OrtLoggingLevel(), sm.cStrings["loggerName"], &sm.env)
 	defer C.OrtApiReleaseStatus(sm.api, status)
 	if status != nil {
-		return nil, fmt.Errorf("failed to create env: %s", C.GoString(C.OrtApiGetErrorMessage(sm.api, status)))
+		return nil, fmt.Errorf("failed to create env: %w", newOrtError(sm.api, status))
 	}
+	trackAlloc(nativeEnv, 1)
 
 	// 创建会话选项
 	status = C.OrtApiCreateSessionOptions(sm.api, &sm.sessionOpts)
 	defer C.OrtApiReleaseStatus(sm.api, status)
 	if status != nil {
-		return nil, fmt.Errorf("failed to create session options: %s", C.GoString(C.OrtApiGetErrorMessage(sm.api, status)))
+		return nil, fmt.Errorf("failed to create session options: %w", newOrtError(sm.api, status))
 	}
+	trackAlloc(nativeSessionOptions, 1)
 
 	// 设置线程数
 	status = C.OrtApiSetIntraOpNumThreads(sm.api, sm.sessionOpts, 1)
 	defer C.OrtApiReleaseStatus(sm.api, status)
 	if status != nil {
-		return nil, fmt.Errorf("failed to set intra threads: %s", C.GoString(C.OrtApiGetErrorMessage(sm.api, status)))
+		return nil, fmt.Errorf("failed to set intra threads: %w", newOrtError(sm.api, status))
 	}
 
 	status = C.OrtApiSetInterOpNumThreads(sm.api, sm.sessionOpts, 1)
 	defer C.OrtApiReleaseStatus(sm.api, status)
 	if status != nil {
-		return nil, fmt.Errorf("failed to set inter threads: %s", C.GoString(C.OrtApiGetErrorMessage(sm.api, status)))
+		return nil, fmt.Errorf("failed to set inter threads: %w", newOrtError(sm.api, status))
 	}
 
 	// 设置图优化级别
-	status = C.OrtApiSetSessionGraphOptimizationLevel(sm.api, sm.sessionOpts, C.ORT_ENABLE_ALL)
+	status = C.OrtApiSetSessionGraphOptimizationLevel(sm.api, sm.sessionOpts, graphOptimizationLevel(cfg))
 	defer C.OrtApiReleaseStatus(sm.api, status)
 	if status != nil {
-		return nil, fmt.Errorf("failed to set session graph optimization level: %s", C.GoString(C.OrtApiGetErrorMessage(sm.api, status)))
+		return nil, fmt.Errorf("failed to set session graph optimization level: %w", newOrtError(sm.api, status))
 	}
 
-	// 创建会话
-	sm.cStrings["modelPath"] = C.CString(sm.cfg.ModelPath)
-	status = C.OrtApiCreateSession(sm.api, sm.env, sm.cStrings["modelPath"], sm.sessionOpts, &sm.session)
-	defer C.OrtApiReleaseStatus(sm.api, status)
-	if status != nil {
-		return nil, fmt.Errorf("failed to create session: %s", C.GoString(C.OrtApiGetErrorMessage(sm.api, status)))
+	// 确定性模式
+	if cfg.Deterministic {
+		if err := setDeterministic(sm.api, sm.sessionOpts); err != nil {
+			return nil, err
+		}
+	}
+
+	// 执行提供者
+	if err := appendExecutionProvider(sm.api, sm.sessionOpts, cfg.ExecutionProvider); err != nil {
+		return nil, err
+	}
+
+	// 创建会话池
+	poolSize := cfg.SessionPoolSize
+	if poolSize == 0 {
+		poolSize = 1
+	}
+	sm.cStrings["modelPath"] = C.CString(cfg.ModelPath)
+	trackAlloc(nativeCString, 1)
+	sm.sessions = make([]*C.OrtSession, poolSize)
+	for i := range sm.sessions {
+		session, err := sm.createSession(i, cfg)
+		if err != nil {
+			return nil, err
+		}
+		sm.sessions[i] = session
 	}
+	sm.modelBytes = modelFileSize(cfg.ModelPath) * int64(poolSize)
 
 	// 创建内存信息
 	status = C.OrtApiCreateCpuMemoryInfo(sm.api, C.OrtArenaAllocator, C.OrtMemTypeDefault, &sm.memoryInfo)
 	defer C.OrtApiReleaseStatus(sm.api, status)
 	if status != nil {
-		return nil, fmt.Errorf("failed to create memory info: %s", C.GoString(C.OrtApiGetErrorMessage(sm.api, status)))
+		return nil, fmt.Errorf("failed to create memory info: %w", newOrtError(sm.api, status))
 	}
+	trackAlloc(nativeMemoryInfo, 1)
 
 	// 创建输入输出名称的C字符串
 	sm.cStrings["input"] = C.CString("input")
@@ -107,18 +191,126 @@ func NewSharedModel(cfg DetectorConfig) (*SharedModel, error) {
 	sm.cStrings["state"] = C.CString("state")
 	sm.cStrings["stateN"] = C.CString("stateN")
 	sm.cStrings["output"] = C.CString("output")
+	trackAlloc(nativeCString, 5)
+	sm.inputNames = [3]*C.char{sm.cStrings["input"], sm.cStrings["state"], sm.cStrings["sr"]}
+	sm.outputNames = [2]*C.char{sm.cStrings["output"], sm.cStrings["stateN"]}
+
+	// 加载可选的降噪模型
+	if cfg.Denoiser != nil {
+		dm, err := newDenoiserModel(sm, *cfg.Denoiser)
+		if err != nil {
+			return nil, err
+		}
+		sm.denoiser = dm
+		sm.modelBytes += modelFileSize(cfg.Denoiser.ModelPath)
+	}
+
+	// 加载可选的分类模型
+	if cfg.Classifier != nil {
+		cm, err := newClassifierModel(sm, *cfg.Classifier)
+		if err != nil {
+			return nil, err
+		}
+		sm.classifier = cm
+		sm.modelBytes += modelFileSize(cfg.Classifier.ModelPath)
+	}
+
+	if cfg.MaxBatchSize > 1 {
+		sm.batcher = newBatchScheduler(sm, cfg)
+	}
+	if cfg.RecoverAfterFailures > 0 {
+		sm.recovery = &sessionRecovery{threshold: int32(cfg.RecoverAfterFailures)}
+	}
+
+	// 会话加载完成时 C 堆占用通常最高，记录一次峰值
+	observeHeap()
 
+	// 兜底：原生内存对 Go GC 不可见，忘记调用 Destroy 的模型在被回收时释放资源并告警
+	runtime.SetFinalizer(sm, func(sm *SharedModel) {
+		slog.Warn("shared model was garbage collected without calling Destroy, releasing native resources")
+		_ = sm.Destroy()
+	})
+
+	created = true
 	return sm, nil
 }
 
+// createSession 创建会话池中的第 i 个会话
+// 配置了 SessionCPUs 时在固定到对应 CPU 的线程上创建，使权重按首次访问分配在该 NUMA 节点上。
+func (sm *SharedModel) createSession(i int, cfg DetectorConfig) (*C.OrtSession, error) {
+	if cpus := sessionCPUs(cfg, i); cpus != nil {
+		restore, err := pinThread(cpus)
+		if err != nil {
+			return nil, err
+		}
+		defer restore()
+	}
+
+	var session *C.OrtSession
+	status := C.OrtApiCreateSession(sm.api, sm.env, sm.cStrings["modelPath"], sm.sessionOpts, &session)
+	defer C.OrtApiReleaseStatus(sm.api, status)
+	if status != nil {
+		return nil, fmt.Errorf("%w: failed to create session %d: %w", ErrModelLoad, i, newOrtError(sm.api, status))
+	}
+	trackAlloc(nativeSession, 1)
+	return session, nil
+}
+
+// sessionCPUs 返回第 i 个会话固定的 CPU，未配置 SessionCPUs 时返回 nil
+func sessionCPUs(cfg DetectorConfig, i int) []int {
+	if len(cfg.SessionCPUs) == 0 {
+		return nil
+	}
+	return cfg.SessionCPUs[i%len(cfg.SessionCPUs)]
+}
+
 // NewContext 创建一个新的检测器上下文
+// 启用会话池时，上下文按轮询顺序绑定到其中一个会话；配置了 SessionCPUs 时检测调用在该会话的 CPU 上执行。
+// 每个上下文都会增加模型的引用计数，使用完毕后应调用 Close。
+// 模型已销毁时返回的上下文不绑定会话，所有检测调用都返回 ErrModelDestroyed。
 func (sm *SharedModel) NewContext() *DetectorContext {
-	return &DetectorContext{
+	sm.refs.Add(1)
+	if sm.destroyed.Load() {
+		return &DetectorContext{model: sm}
+	}
+	idx := (sm.nextSession.Add(1) - 1) % uint32(len(sm.sessions))
+	dc := &DetectorContext{
 		model: sm,
+		slot:  int(idx),
+		cpus:  sessionCPUs(*sm.config(), int(idx)),
+	}
+	if sm.denoiser != nil {
+		dc.pre.denoiser = sm.denoiser.newStage()
+	}
+	if sm.classifier != nil {
+		dc.classifier = sm.classifier.newStage()
+	}
+	return dc
+}
+
+// acquire 登记一次进行中的检测调用，模型已销毁时返回 ErrModelDestroyed
+func (sm *SharedModel) acquire() error {
+	sm.active.Add(1)
+	if sm.destroyed.Load() {
+		sm.release()
+		return ErrModelDestroyed
+	}
+	return nil
+}
+
+// release 结束一次检测调用，必要时唤醒等待中的 Destroy
+func (sm *SharedModel) release() {
+	if sm.active.Add(-1) == 0 && sm.destroyed.Load() {
+		select {
+		case sm.drained <- struct{}{}:
+		default:
+		}
 	}
 }
 
 // Destroy 销毁共享模型资源
+// 销毁前会等待正在进行的检测调用结束，之后所有上下文都会失效并返回 ErrModelDestroyed。
+// 重复调用是安全的。
 func (sm *SharedModel) Destroy() error {
 	if sm == nil {
 		return fmt.Errorf("invalid nil shared model")
@@ -127,102 +319,409 @@ func (sm *SharedModel) Destroy() error {
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
 
-	C.OrtApiReleaseMemoryInfo(sm.api, sm.memoryInfo)
-	C.OrtApiReleaseSession(sm.api, sm.session)
-	C.OrtApiReleaseSessionOptions(sm.api, sm.sessionOpts)
-	C.OrtApiReleaseEnv(sm.api, sm.env)
+	if sm.destroyed.Swap(true) {
+		return nil
+	}
+	for sm.active.Load() != 0 {
+		<-sm.drained
+	}
+
+	if refs := sm.refs.Load(); refs > 0 {
+		slog.Warn("destroying shared model with open contexts, they are now invalid", slog.Int64("contexts", refs))
+	}
+
+	runtime.SetFinalizer(sm, nil)
+
+	if sm.batcher != nil {
+		sm.batcher.close()
+	}
+	sm.releaseNative()
+	sm.released.Store(true)
+
+	return nil
+}
+
+// releaseNative 释放模型持有的原生资源，也用于 NewSharedModel 失败时释放已经创建的部分
+func (sm *SharedModel) releaseNative() {
+	if sm.denoiser != nil {
+		sm.denoiser.release()
+	}
+	if sm.classifier != nil {
+		sm.classifier.release()
+	}
+
+	if sm.memoryInfo != nil {
+		C.OrtApiReleaseMemoryInfo(sm.api, sm.memoryInfo)
+		trackFree(nativeMemoryInfo, 1)
+	}
+	for _, session := range sm.sessions {
+		if session != nil {
+			C.OrtApiReleaseSession(sm.api, session)
+			trackFree(nativeSession, 1)
+		}
+	}
+	if sm.sessionOpts != nil {
+		C.OrtApiReleaseSessionOptions(sm.api, sm.sessionOpts)
+		trackFree(nativeSessionOptions, 1)
+	}
+	if sm.env != nil {
+		C.OrtApiReleaseEnv(sm.api, sm.env)
+		trackFree(nativeEnv, 1)
+	}
 
 	for _, ptr := range sm.cStrings {
 		C.free(unsafe.Pointer(ptr))
 	}
+	trackFree(nativeCString, len(sm.cStrings))
+}
 
-	return nil
+// Destroyed 返回模型是否已被销毁（或正在销毁）
+func (sm *SharedModel) Destroyed() bool {
+	return sm.destroyed.Load()
 }
 
 // GetConfig 获取配置（线程安全）
+// 返回的是深拷贝，修改其中的指针、切片和映射字段不会影响模型的配置。
 func (sm *SharedModel) GetConfig() DetectorConfig {
-	sm.mu.RLock()
-	defer sm.mu.RUnlock()
-	return sm.cfg
+	return sm.config().clone()
+}
+
+// config 返回当前配置快照，调用方不得修改返回值
+func (sm *SharedModel) config() *DetectorConfig {
+	return &sm.cfg.Load().cfg
+}
+
+// GetConfig 获取该上下文当前的配置（线程安全），返回深拷贝
+func (dc *DetectorContext) GetConfig() DetectorConfig {
+	return dc.config().clone()
+}
+
+// config 返回上下文当前的配置快照，调用方不得修改返回值
+func (dc *DetectorContext) config() *DetectorConfig {
+	return &dc.snapshot().cfg
+}
+
+// snapshot 返回上下文当前使用的带版本配置
+func (dc *DetectorContext) snapshot() *configSnapshot {
+	if s := dc.cfg.Load(); s != nil {
+		return s
+	}
+	return dc.model.cfg.Load()
+}
+
+// callConfig 返回本次检测调用使用的配置并记录其版本，见 ConfigVersion
+// 检测调用开始时调用一次，整个调用都使用返回的快照。
+func (dc *DetectorContext) callConfig() *DetectorConfig {
+	s := dc.snapshot()
+	dc.lastVersion = s.version
+	return &s.cfg
+}
+
+// WithConfig 为该上下文单独修改配置，不影响共享同一模型的其它上下文
+// override 在当前配置的副本上修改，例如：
+//
+//	err := dc.WithConfig(func(cfg *speech.DetectorConfig) {
+//		cfg.Threshold = 0.6
+//		cfg.SpeechPadMs = 100
+//	})
+//
+// 只能修改检测参数和推理前预处理（Threshold、Calibration、DutyCycle、MinSilenceDurationMs、SpeechPadMs、PadShortInput、HighPassCutoffHz、InputCheck、AGC），
+// 修改模型相关的字段或配置无效时返回错误且配置保持不变。
+// 新配置从下一次检测调用开始生效，正在进行的调用继续使用旧配置。
+// 修改后的配置获得新的版本号，此后该上下文不再跟随 UpdateConfig，直到被 PutContext 或 DetectorPool 回收。
+func (dc *DetectorContext) WithConfig(override func(cfg *DetectorConfig)) error {
+	if dc == nil || dc.model == nil {
+		return fmt.Errorf("invalid nil detector context")
+	}
+
+	cfg := dc.config().clone()
+	override(&cfg)
+	cfg.resolveSampleRate()
+
+	if field := fixedFieldChanged(&cfg, dc.model.config()); field != "" {
+		return fmt.Errorf("%w: %s cannot be changed per context", ErrInvalidConfig, field)
+	}
+	if err := cfg.IsValid(); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+	}
+
+	// override 可能保留了它设置的指针，快照存一份独立的拷贝
+	dc.cfg.Store(&configSnapshot{cfg: cfg.clone(), version: dc.model.versions.Add(1)})
+	return nil
+}
+
+// debugEnabled 返回是否输出调试日志
+// 检测热路径上先检查级别，避免日志参数在关闭调试时仍被装箱分配。
+func debugEnabled() bool {
+	return slog.Default().Enabled(context.Background(), slog.LevelDebug)
 }
 
 // Detect 检测语音片段
 func (dc *DetectorContext) Detect(pcm []float32) ([]Segment, error) {
+	return dc.DetectInto(pcm, nil)
+}
+
+// DetectInto 与 Detect 相同，但把检测到的片段追加到 segs 后返回
+// 传入上次结果的 segs[:0] 并且不启用预处理时，检测过程不在 Go 堆上分配内存，适合高并发的热路径。
+// 出错时返回 nil。
+func (dc *DetectorContext) DetectInto(pcm []float32, segs []Segment) ([]Segment, error) {
 	if dc == nil || dc.model == nil {
 		return nil, fmt.Errorf("invalid nil detector context")
 	}
 
-	windowSize := 512
-	if dc.model.cfg.SampleRate == 8000 {
-		windowSize = 256
+	if err := dc.acquire(); err != nil {
+		return nil, err
+	}
+	defer dc.release()
+
+	// 整次检测使用同一份配置快照，避免中途被 SetThreshold 修改
+	cfg := dc.callConfig()
+
+	windowSize := windowSizeFor(cfg.SampleRate)
+
+	pcm, err := dc.pre.resample(cfg, pcm)
+	if err != nil {
+		return nil, err
 	}
 
 	if len(pcm) < windowSize {
-		return nil, fmt.Errorf("not enough samples")
+		if !cfg.PadShortInput {
+			return nil, notEnoughSamples(len(pcm), windowSize)
+		}
+		dc.padBuf = padShortInput(dc.padBuf, pcm, windowSize)
+		pcm = dc.padBuf
 	}
 
-	slog.Debug("starting speech detection", slog.Int("samplesLen", len(pcm)))
+	pcm, err = dc.pre.apply(cfg, pcm)
+	if err != nil {
+		return nil, err
+	}
 
-	minSilenceSamples := dc.model.cfg.MinSilenceDurationMs * dc.model.cfg.SampleRate / 1000
-	speechPadSamples := dc.model.cfg.SpeechPadMs * dc.model.cfg.SampleRate / 1000
+	if debugEnabled() {
+		slog.Debug("starting speech detection", slog.Int("samplesLen", len(pcm)))
+	}
 
-	var segments []Segment
+	base := len(segs)
 	for i := 0; i < len(pcm)-windowSize; i += windowSize {
-		speechProb, err := dc.infer(pcm[i : i+windowSize])
-		// if speechProb >= 0.5 {
-		// 	fmt.Printf("===infer speech prob: %f\n", speechProb)
-		// }
+		segs, err = dc.step(cfg, pcm[i:i+windowSize], segs, base)
 		if err != nil {
-			return nil, fmt.Errorf("infer failed: %w", err)
+			return nil, err
 		}
+	}
 
-		dc.currSample += windowSize
+	if debugEnabled() {
+		slog.Debug("speech detection done", slog.Int("segmentsLen", len(segs)-base))
+	}
+
+	return segs, nil
+}
+
+// DetectChunks 依次检测 chunker 中所有完整的窗口，剩余采样留待下次调用
+// 适合流式输入：把每个到达的数据包写入 chunker 后调用本方法即可，
+// 跨调用的语音片段会在结束时返回完整的起止时间。chunker 中须为模型采样率的采样，不按 InputSampleRate 重采样。
+func (dc *DetectorContext) DetectChunks(c *StreamChunker) ([]Segment, error) {
+	if dc == nil || dc.model == nil {
+		return nil, fmt.Errorf("invalid nil detector context")
+	}
+	if c == nil {
+		return nil, fmt.Errorf("invalid nil stream chunker")
+	}
+
+	if err := dc.acquire(); err != nil {
+		return nil, err
+	}
+	defer dc.release()
+
+	cfg := dc.callConfig()
+	windowSize := windowSizeFor(cfg.SampleRate)
+	if c.WindowSize() != windowSize {
+		return nil, fmt.Errorf("chunker window size %d does not match model window size %d", c.WindowSize(), windowSize)
+	}
 
-		if speechProb >= dc.model.cfg.Threshold && dc.tempEnd != 0 {
-			dc.tempEnd = 0
+	var segments []Segment
+	for {
+		frame, ok := c.Next()
+		if !ok {
+			return segments, nil
 		}
 
-		if speechProb >= dc.model.cfg.Threshold && !dc.triggered {
-			dc.triggered = true
-			speechStartAt := (float64(dc.currSample-windowSize-speechPadSamples) / float64(dc.model.cfg.SampleRate))
+		var err error
+		segments, err = dc.detectFrame(cfg, frame, segments, 0)
+		if err != nil {
+			return nil, err
+		}
+	}
+}
 
-			// 由于padding的存在，起始位置可能为负数，我们将其限制在0
-			if speechStartAt < 0 {
-				speechStartAt = 0
-			}
+// detectFrame 预处理并检测一个输入窗口，调用方需已持有 acquire
+func (dc *DetectorContext) detectFrame(cfg *DetectorConfig, frame []float32, segments []Segment, base int) ([]Segment, error) {
+	windowSize := len(frame)
 
+	// 降噪等有延迟的阶段可能一次输出零个或多个窗口
+	pcm, err := dc.pre.apply(cfg, frame)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i+windowSize <= len(pcm); i += windowSize {
+		segments, err = dc.step(cfg, pcm[i:i+windowSize], segments, base)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return segments, nil
+}
+
+// step 对一个窗口推理并推进语音状态机，新开始或结束的片段追加/更新到 segments
+// segments[base:] 是本次调用的结果，base 之前的内容不会被修改。
+func (dc *DetectorContext) step(cfg *DetectorConfig, window []float32, segments []Segment, base int) ([]Segment, error) {
+	if cfg.DutyCycle != nil {
+		awake, err := dc.gate(cfg, window)
+		if err != nil {
+			return nil, err
+		}
+		if !awake {
+			return dc.advance(cfg, 0, window, segments, base), nil
+		}
+	}
+	speechProb, err := dc.predict(cfg, window)
+	if err != nil {
+		return nil, fmt.Errorf("infer failed: %w", err)
+	}
+	return dc.advance(cfg, speechProb, window, segments, base), nil
+}
+
+// advance 以一个窗口的语音概率推进语音状态机，window 只用于估计信噪比和测量响度
+func (dc *DetectorContext) advance(cfg *DetectorConfig, speechProb float32, window []float32, segments []Segment, base int) []Segment {
+	windowSize := len(window)
+	if cfg.EstimateSNR {
+		dc.snr.observe(cfg, window, speechProb)
+	}
+	minSilenceSamples := cfg.MinSilenceDurationMs * cfg.SampleRate / 1000
+	speechPadSamples := cfg.SpeechPadMs * cfg.SampleRate / 1000
+
+	dc.currSample += windowSize
+
+	if speechProb >= cfg.Threshold && dc.tempEnd != 0 {
+		dc.tempEnd = 0
+	}
+
+	if speechProb >= cfg.Threshold && !dc.triggered {
+		dc.triggered = true
+		speechStartAt := (float64(dc.currSample-windowSize-speechPadSamples) / float64(cfg.SampleRate))
+
+		// 由于padding的存在，起始位置可能为负数，我们将其限制在0
+		if speechStartAt < 0 {
+			speechStartAt = 0
+		}
+
+		if debugEnabled() {
 			slog.Debug("speech start", slog.Float64("startAt", speechStartAt))
-			segments = append(segments, Segment{
-				SpeechStartAt: speechStartAt,
-			})
 		}
+		dc.model.stats.segments.Add(1)
+		dc.startAt = speechStartAt
+		segments = append(segments, Segment{
+			SpeechStartAt: speechStartAt,
+		})
+	}
+
+	if cfg.MeasureLoudness {
+		dc.loudness.observe(cfg, window, dc.currSample-windowSize, dc.triggered)
+	}
+	if cfg.MaxClippedRun > 0 && dc.pre.clip.take(windowSize) && dc.triggered {
+		dc.clipped = true
+	}
 
-		if speechProb < (dc.model.cfg.Threshold-0.15) && dc.triggered {
-			if dc.tempEnd == 0 {
-				dc.tempEnd = dc.currSample
-			}
+	if speechProb < (cfg.Threshold-0.15) && dc.triggered {
+		if dc.tempEnd == 0 {
+			dc.tempEnd = dc.currSample
+		}
 
-			// 静音时间不够长，继续等待
-			if dc.currSample-dc.tempEnd < minSilenceSamples {
-				continue
-			}
+		// 静音时间不够长，继续等待
+		if dc.currSample-dc.tempEnd < minSilenceSamples {
+			return segments
+		}
 
-			speechEndAt := (float64(dc.tempEnd+speechPadSamples) / float64(dc.model.cfg.SampleRate))
-			dc.tempEnd = 0
-			dc.triggered = false
+		speechEndAt := (float64(dc.tempEnd+speechPadSamples) / float64(cfg.SampleRate))
+		silenceAt := dc.tempEnd
+		dc.tempEnd = 0
+		dc.triggered = false
+		if debugEnabled() {
 			slog.Debug("speech end", slog.Float64("endAt", speechEndAt))
+		}
 
-			if len(segments) < 1 {
-				return nil, fmt.Errorf("unexpected speech end")
-			}
+		// 片段在之前的调用中开始时，本次结果里补上完整的片段
+		if len(segments) <= base {
+			segments = append(segments, Segment{SpeechStartAt: dc.startAt})
+		}
 
-			segments[len(segments)-1].SpeechEndAt = speechEndAt
+		segments[len(segments)-1].SpeechEndAt = speechEndAt
+		if cfg.EstimateSNR {
+			segments[len(segments)-1].SNR = dc.snr.finish()
+		}
+		if cfg.MeasureLoudness {
+			seg := &segments[len(segments)-1]
+			seg.Loudness, seg.RMS = dc.loudness.finish(silenceAt, windowSize)
 		}
+		segments[len(segments)-1].Clipped = dc.clipped
+		dc.clipped = false
 	}
 
-	slog.Debug("speech detection done", slog.Int("segmentsLen", len(segments)))
+	return segments
+}
 
-	return segments, nil
+// SetPreprocessors 设置推理前依次执行的处理阶段（例如 audio.NoiseGate）
+// 这些阶段在配置中的高通滤波、降噪和 AGC 之后执行，且各自保存流式状态，
+// 因此不能在多个上下文之间共享同一个实例。传入空列表可清除已有阶段。
+func (dc *DetectorContext) SetPreprocessors(stages ...audio.Processor) {
+	if dc != nil {
+		dc.pre.stages = stages
+	}
+}
+
+// Close 释放上下文对共享模型的引用，之后该上下文不可再使用
+// 重复调用是安全的。
+func (dc *DetectorContext) Close() error {
+	if dc == nil || dc.model == nil {
+		return fmt.Errorf("invalid nil detector context")
+	}
+
+	if !dc.closed.Swap(true) {
+		dc.model.refs.Add(-1)
+	}
+
+	return nil
+}
+
+// acquire 检查上下文和模型是否仍然可用，成功后调用方需执行 dc.release()
+// 配置了 SessionCPUs 时在调用期间把当前协程固定到会话的 CPU 上。
+func (dc *DetectorContext) acquire() error {
+	if dc.closed.Load() {
+		return ErrContextClosed
+	}
+	if err := dc.model.acquire(); err != nil {
+		return err
+	}
+	if dc.cpus != nil {
+		unpin, err := pinThread(dc.cpus)
+		if err != nil {
+			dc.model.release()
+			return err
+		}
+		dc.unpin = unpin
+	}
+	dc.usage.callStart = time.Now()
+	return nil
+}
+
+// release 结束一次检测调用并累计调用耗时
+func (dc *DetectorContext) release() {
+	dc.usage.wallTime += time.Since(dc.usage.callStart)
+	if dc.unpin != nil {
+		dc.unpin()
+		dc.unpin = nil
+	}
+	dc.model.release()
 }
 
 // Reset 重置检测器状态
@@ -234,6 +733,17 @@ func (dc *DetectorContext) Reset() error {
 	dc.currSample = 0
 	dc.triggered = false
 	dc.tempEnd = 0
+	dc.startAt = 0
+	dc.snr.reset()
+	dc.loudness.reset()
+	dc.clipped = false
+	dc.pre.reset()
+	if dc.duty != nil {
+		dc.duty.reset()
+	}
+	if dc.classifier != nil {
+		dc.classifier.reset()
+	}
 	for i := 0; i < stateLen; i++ {
 		dc.state[i] = 0
 	}
@@ -244,12 +754,13 @@ func (dc *DetectorContext) Reset() error {
 	return nil
 }
 
-// SetThreshold 设置阈值
+// SetThreshold 设置该上下文的阈值，不影响其它上下文
+// 超出 (0, 1) 的值会被忽略，需要得到错误时使用 WithConfig。
 func (dc *DetectorContext) SetThreshold(value float32) {
 	if dc != nil && dc.model != nil {
-		dc.model.mu.Lock()
-		dc.model.cfg.Threshold = value
-		dc.model.mu.Unlock()
+		_ = dc.WithConfig(func(cfg *DetectorConfig) {
+			cfg.Threshold = value
+		})
 	}
 }
 
@@ -260,13 +771,33 @@ func (dc *DetectorContext) IsSpeech(pcm []float32) (bool, error) {
 		return false, fmt.Errorf("invalid nil detector context")
 	}
 
+	if err := dc.acquire(); err != nil {
+		return false, err
+	}
+	defer dc.release()
+
+	cfg := dc.callConfig()
+
 	windowSize := 512
-	if dc.model.cfg.SampleRate == 8000 {
+	if cfg.SampleRate == 8000 {
 		windowSize = 256
 	}
 
+	// 每次调用独立检测，重采样也不接续上一次调用的音频
+	if dc.pre.resampler != nil {
+		dc.pre.resampler.Reset()
+	}
+	pcm, err := dc.pre.resample(cfg, pcm)
+	if err != nil {
+		return false, err
+	}
+
 	if len(pcm) < windowSize {
-		return false, fmt.Errorf("not enough samples")
+		if !cfg.PadShortInput {
+			return false, notEnoughSamples(len(pcm), windowSize)
+		}
+		dc.padBuf = padShortInput(dc.padBuf, pcm, windowSize)
+		pcm = dc.padBuf
 	}
 
 	slog.Debug("starting speech detection (IsSpeech)", slog.Int("samplesLen", len(pcm)))
@@ -275,13 +806,25 @@ func (dc *DetectorContext) IsSpeech(pcm []float32) (bool, error) {
 	dc.currSample = 0
 	dc.triggered = false
 	dc.tempEnd = 0
+	dc.snr.reset()
+	dc.loudness.reset()
+	dc.clipped = false
 	for i := 0; i < stateLen; i++ {
 		dc.state[i] = 0
 	}
+	dc.pre.reset()
+	if dc.classifier != nil {
+		dc.classifier.reset()
+	}
+
+	pcm, err = dc.pre.apply(cfg, pcm)
+	if err != nil {
+		return false, err
+	}
 
 	// 遍历音频窗口
 	for i := 0; i < len(pcm)-windowSize; i += windowSize {
-		speechProb, err := dc.infer(pcm[i : i+windowSize])
+		speechProb, err := dc.predict(cfg, pcm[i:i+windowSize])
 		if err != nil {
 			return false, fmt.Errorf("infer failed: %w", err)
 		}
@@ -289,7 +832,7 @@ func (dc *DetectorContext) IsSpeech(pcm []float32) (bool, error) {
 		dc.currSample += windowSize
 
 		// 如果检测到语音概率超过阈值，立即返回 true
-		if speechProb >= dc.model.cfg.Threshold {
+		if speechProb >= cfg.Threshold {
 			slog.Debug("speech detected", slog.Float64("probability", float64(speechProb)))
 			return true, nil
 		}
@@ -306,13 +849,33 @@ func (dc *DetectorContext) IsSpeechQuick(pcm []float32, maxWindows int) (bool, e
 		return false, fmt.Errorf("invalid nil detector context")
 	}
 
+	if err := dc.acquire(); err != nil {
+		return false, err
+	}
+	defer dc.release()
+
+	cfg := dc.callConfig()
+
 	windowSize := 512
-	if dc.model.cfg.SampleRate == 8000 {
+	if cfg.SampleRate == 8000 {
 		windowSize = 256
 	}
 
+	// 每次调用独立检测，重采样也不接续上一次调用的音频
+	if dc.pre.resampler != nil {
+		dc.pre.resampler.Reset()
+	}
+	pcm, err := dc.pre.resample(cfg, pcm)
+	if err != nil {
+		return false, err
+	}
+
 	if len(pcm) < windowSize {
-		return false, fmt.Errorf("not enough samples")
+		if !cfg.PadShortInput {
+			return false, notEnoughSamples(len(pcm), windowSize)
+		}
+		dc.padBuf = padShortInput(dc.padBuf, pcm, windowSize)
+		pcm = dc.padBuf
 	}
 
 	if maxWindows <= 0 {
@@ -327,14 +890,27 @@ func (dc *DetectorContext) IsSpeechQuick(pcm []float32, maxWindows int) (bool, e
 	dc.currSample = 0
 	dc.triggered = false
 	dc.tempEnd = 0
+	dc.snr.reset()
+	dc.loudness.reset()
+	dc.clipped = false
 	for i := 0; i < stateLen; i++ {
 		dc.state[i] = 0
 	}
+	dc.pre.reset()
+	if dc.classifier != nil {
+		dc.classifier.reset()
+	}
+
+	// 只预处理需要检测的前几个窗口
+	pcm, err = dc.pre.apply(cfg, pcm[:min(len(pcm), (maxWindows+1)*windowSize)])
+	if err != nil {
+		return false, err
+	}
 
 	// 只检测指定数量的窗口
 	windowCount := 0
 	for i := 0; i < len(pcm)-windowSize && windowCount < maxWindows; i += windowSize {
-		speechProb, err := dc.infer(pcm[i : i+windowSize])
+		speechProb, err := dc.predict(cfg, pcm[i:i+windowSize])
 		if err != nil {
 			return false, fmt.Errorf("infer failed: %w", err)
 		}
@@ -343,7 +919,7 @@ func (dc *DetectorContext) IsSpeechQuick(pcm []float32, maxWindows int) (bool, e
 		windowCount++
 
 		// 如果检测到语音概率超过阈值，立即返回 true
-		if speechProb >= dc.model.cfg.Threshold {
+		if speechProb >= cfg.Threshold {
 			slog.Debug("speech detected quickly",
 				slog.Float64("probability", float64(speechProb)),
 				slog.Int("windowIndex", windowCount))
@@ -0,0 +1,923 @@
+package speech
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"log/slog"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"sync"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/rui-yang-me/silero-vad-go/audio"
+	"github.com/stretchr/testify/require"
+)
+
+func readTestSamples(t *testing.T, path string) []float32 {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	samples, err := audio.BytesToFloat32(nil, data, audio.FormatFloat32, binary.LittleEndian)
+	require.NoError(t, err)
+	return samples
+}
+
+func newTestSharedModel(t *testing.T) *SharedModel {
+	t.Helper()
+
+	sm, err := NewSharedModel(DetectorConfig{
+		ModelPath:  "../testfiles/silero_vad.onnx",
+		SampleRate: 16000,
+		Threshold:  0.5,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, sm)
+	t.Cleanup(func() {
+		require.NoError(t, sm.Destroy())
+	})
+
+	return sm
+}
+
+func TestSharedModelConcurrentDetect(t *testing.T) {
+	sm := newTestSharedModel(t)
+	samples := readTestSamples(t, "../testfiles/samples.pcm")
+
+	// 同一会话上的并发 Run 可能带来极小的数值差异，这里只校验没有错误和数据竞争
+	var wg sync.WaitGroup
+	results := make([][]Segment, 4)
+	errs := make([]error, len(results))
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], errs[i] = sm.NewContext().Detect(samples)
+		}(i)
+	}
+
+	// 并发修改配置不应与推理产生数据竞争
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		sm.NewContext().SetThreshold(0.5)
+	}()
+
+	wg.Wait()
+	for i, segments := range results {
+		require.NoError(t, errs[i])
+		require.NotEmpty(t, segments)
+	}
+	require.Equal(t, float32(0.5), sm.GetConfig().Threshold)
+}
+
+func TestSharedModelSessionPool(t *testing.T) {
+	sm, err := NewSharedModel(DetectorConfig{
+		ModelPath:       "../testfiles/silero_vad.onnx",
+		SampleRate:      16000,
+		Threshold:       0.5,
+		SessionPoolSize: 3,
+	})
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, sm.Destroy())
+	}()
+	require.Len(t, sm.sessions, 3)
+
+	samples := readTestSamples(t, "../testfiles/samples.pcm")
+
+	// 上下文按轮询顺序绑定会话
+	contexts := make([]*DetectorContext, 4)
+	for i := range contexts {
+		contexts[i] = sm.NewContext()
+		require.Equal(t, i%3, contexts[i].slot)
+	}
+
+	expected, err := contexts[0].Detect(samples)
+	require.NoError(t, err)
+	for _, dc := range contexts[1:] {
+		segments, err := dc.Detect(samples)
+		require.NoError(t, err)
+		require.Equal(t, expected, segments)
+	}
+}
+
+func TestSharedModelNativeLeakDetection(t *testing.T) {
+	base := LiveNativeAllocations()
+
+	sm, err := NewSharedModel(DetectorConfig{
+		ModelPath:  "../testfiles/silero_vad.onnx",
+		SampleRate: 16000,
+		Threshold:  0.5,
+	})
+	require.NoError(t, err)
+	require.Greater(t, LiveNativeAllocations(), base)
+	require.EqualValues(t, 1, NativeAllocations()["session"])
+
+	require.NoError(t, sm.Destroy())
+	require.Equal(t, base, LiveNativeAllocations())
+
+	// 未调用 Destroy 的模型在被 GC 回收时由 finalizer 释放
+	func() {
+		_, err := NewSharedModel(DetectorConfig{
+			ModelPath:  "../testfiles/silero_vad.onnx",
+			SampleRate: 16000,
+			Threshold:  0.5,
+		})
+		require.NoError(t, err)
+	}()
+	require.Eventually(t, func() bool {
+		runtime.GC()
+		return LiveNativeAllocations() == base
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// 创建失败时释放已经创建的环境、会话选项和 C 字符串
+	missing := DetectorConfig{
+		ModelPath:       "../testfiles/missing.onnx",
+		SampleRate:      16000,
+		Threshold:       0.5,
+		SessionPoolSize: 2,
+	}
+	_, err = NewSharedModel(missing)
+	require.ErrorIs(t, err, ErrModelLoad)
+	require.Equal(t, base, LiveNativeAllocations())
+	_, err = NewDetector(missing)
+	require.ErrorIs(t, err, ErrModelLoad)
+	require.Equal(t, base, LiveNativeAllocations())
+}
+
+func TestSharedModelErrors(t *testing.T) {
+	t.Run("model load", func(t *testing.T) {
+		_, err := NewSharedModel(DetectorConfig{
+			ModelPath:  "../testfiles/missing.onnx",
+			SampleRate: 16000,
+			Threshold:  0.5,
+		})
+		require.ErrorIs(t, err, ErrModelLoad)
+
+		var ortErr *OrtError
+		require.ErrorAs(t, err, &ortErr)
+		require.NotEqual(t, OrtOK, ortErr.Code)
+		require.NotEmpty(t, ortErr.Msg)
+	})
+
+	t.Run("invalid config", func(t *testing.T) {
+		_, err := NewSharedModel(DetectorConfig{ModelPath: "../testfiles/silero_vad.onnx", SampleRate: 44100, Threshold: 0.5})
+		require.ErrorIs(t, err, ErrInvalidConfig)
+
+		dc := newTestSharedModel(t).NewContext()
+		require.ErrorIs(t, dc.WithConfig(func(cfg *DetectorConfig) {
+			cfg.Threshold = 2
+		}), ErrInvalidConfig)
+		require.ErrorIs(t, dc.WithConfig(func(cfg *DetectorConfig) {
+			cfg.SampleRate = 8000
+		}), ErrInvalidConfig)
+	})
+
+	t.Run("not enough samples", func(t *testing.T) {
+		sm := newTestSharedModel(t)
+		dc := sm.NewContext()
+
+		_, err := dc.Detect(make([]float32, 100))
+		require.ErrorIs(t, err, ErrNotEnoughSamples)
+		require.ErrorContains(t, err, "got 100, need at least 512")
+
+		_, err = dc.IsSpeech(make([]float32, 100))
+		require.ErrorIs(t, err, ErrNotEnoughSamples)
+
+		// 宽松模式下补零后按一个窗口处理
+		require.NoError(t, dc.WithConfig(func(cfg *DetectorConfig) {
+			cfg.PadShortInput = true
+		}))
+		var windows int
+		dc.SetWindowObserver(func(WindowResult) {
+			windows++
+		})
+		require.NoError(t, dc.Reset())
+		segments, err := dc.Detect(make([]float32, 100))
+		require.NoError(t, err)
+		require.Empty(t, segments)
+		require.Equal(t, 1, windows)
+		require.Equal(t, 512, dc.currSample)
+
+		samples := readTestSamples(t, "../testfiles/samples.pcm")
+		ok, err := dc.IsSpeech(samples[int(1.2*16000):][:300])
+		require.NoError(t, err)
+		require.True(t, ok)
+		ok, err = dc.IsSpeechQuick(make([]float32, 10), 3)
+		require.NoError(t, err)
+		require.False(t, ok)
+		res, err := dc.DetectWithBudget(make([]float32, 10), time.Second)
+		require.NoError(t, err)
+		require.Equal(t, 512.0/16000, res.Processed)
+	})
+}
+
+func TestPadShortInputEntryPoints(t *testing.T) {
+	samples := readTestSamples(t, "../testfiles/samples.pcm")
+	short := samples[int(1.2*16000):][:300]
+	data := make([]byte, 0, 2*len(short))
+	for _, v := range audio.Float32ToInt16(nil, short) {
+		data = binary.LittleEndian.AppendUint16(data, uint16(v))
+	}
+	path := filepath.Join(t.TempDir(), "short.pcm")
+	require.NoError(t, os.WriteFile(path, data, 0o644))
+
+	newModel := func(pad bool) *SharedModel {
+		sm, err := NewSharedModel(DetectorConfig{
+			ModelPath:     "../testfiles/silero_vad.onnx",
+			SampleRate:    16000,
+			Threshold:     0.5,
+			PadShortInput: pad,
+		})
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			require.NoError(t, sm.Destroy())
+		})
+		return sm
+	}
+
+	// 每个入口对短输入的处理与 Detect 一致
+	for _, pad := range []bool{false, true} {
+		sm := newModel(pad)
+		newContext := func() *DetectorContext {
+			dc := sm.NewContext()
+			t.Cleanup(func() {
+				require.NoError(t, dc.Close())
+			})
+			return dc
+		}
+		check := func(name string, segments []Segment, err error) {
+			if !pad {
+				require.ErrorIs(t, err, ErrNotEnoughSamples, name)
+				return
+			}
+			require.NoError(t, err, name)
+			require.Len(t, segments, 1, name)
+			require.Zero(t, segments[0].SpeechStartAt, name)
+		}
+
+		segments, err := newContext().DetectBytes(data, audio.FormatPCM16)
+		check("DetectBytes", segments, err)
+		segments, err = newContext().DetectReader(bytes.NewReader(data), audio.FormatPCM16)
+		check("DetectReader", segments, err)
+		segments, err = newContext().DetectFile(path, audio.FormatPCM16)
+		check("DetectFile", segments, err)
+
+		segments = nil
+		err = newContext().ProcessFile(path, audio.FormatPCM16, 1, func(chunk FileChunk) error {
+			require.Len(t, chunk.PCM, len(short))
+			segments = append(segments, chunk.Segments...)
+			return nil
+		})
+		check("ProcessFile", segments, err)
+
+		h, err := NewHybridDetector(sm, HybridConfig{})
+		require.NoError(t, err)
+		_, err = h.IsSpeech(short)
+		if pad {
+			require.NoError(t, err)
+			require.EqualValues(t, 1, h.Stats().Windows)
+		} else {
+			require.ErrorIs(t, err, ErrNotEnoughSamples)
+		}
+		require.NoError(t, h.Close())
+	}
+}
+
+func TestSharedModelDestroyWithContexts(t *testing.T) {
+	sm, err := NewSharedModel(DetectorConfig{
+		ModelPath:  "../testfiles/silero_vad.onnx",
+		SampleRate: 16000,
+		Threshold:  0.5,
+	})
+	require.NoError(t, err)
+
+	samples := readTestSamples(t, "../testfiles/samples.pcm")
+
+	t.Run("closed context", func(t *testing.T) {
+		dc := sm.NewContext()
+		require.EqualValues(t, 1, sm.refs.Load())
+		require.NoError(t, dc.Close())
+		require.NoError(t, dc.Close())
+		require.EqualValues(t, 0, sm.refs.Load())
+
+		_, err := dc.Detect(samples)
+		require.ErrorIs(t, err, ErrContextClosed)
+	})
+
+	t.Run("destroy waits for in-flight detections", func(t *testing.T) {
+		var wg sync.WaitGroup
+		started := make(chan struct{})
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				dc := sm.NewContext()
+				defer dc.Close()
+				if i == 0 {
+					close(started)
+				}
+				for {
+					if _, err := dc.Detect(samples); err != nil {
+						require.ErrorIs(t, err, ErrModelDestroyed)
+						return
+					}
+					_ = dc.Reset()
+				}
+			}(i)
+		}
+
+		lingering := sm.NewContext()
+		<-started
+		require.NoError(t, sm.Destroy())
+		wg.Wait()
+
+		_, err := sm.NewContext().IsSpeech(samples)
+		require.ErrorIs(t, err, ErrModelDestroyed)
+
+		// 绕过检测调用直接推理也不会访问已释放的会话
+		_, err = lingering.infer(samples[:512])
+		require.ErrorIs(t, err, ErrModelDestroyed)
+		require.NoError(t, sm.Destroy())
+	})
+}
+
+func TestDetectBytes(t *testing.T) {
+	sm := newTestSharedModel(t)
+	samples := readTestSamples(t, "../testfiles/samples.pcm")
+
+	expected, err := sm.NewContext().Detect(samples)
+	require.NoError(t, err)
+
+	data, err := os.ReadFile("../testfiles/samples.pcm")
+	require.NoError(t, err)
+
+	segments, err := sm.NewContext().DetectBytes(data, audio.FormatFloat32)
+	require.NoError(t, err)
+	require.Equal(t, expected, segments)
+
+	segments, err = sm.NewContext().DetectReader(bytes.NewReader(data), audio.FormatFloat32)
+	require.NoError(t, err)
+	require.Equal(t, expected, segments)
+
+	// 分块读取时，块边界和读取方式不影响结果
+	segments, err = sm.NewContext().DetectReader(iotest.HalfReader(bytes.NewReader(data)), audio.FormatFloat32)
+	require.NoError(t, err)
+	require.Equal(t, expected, segments)
+
+	readErr := errors.New("read failed")
+	_, err = sm.NewContext().DetectReader(io.MultiReader(bytes.NewReader(data), iotest.ErrReader(readErr)), audio.FormatFloat32)
+	require.ErrorIs(t, err, readErr)
+	_, err = sm.NewContext().DetectReader(bytes.NewReader(data[:len(data)-1]), audio.FormatFloat32)
+	require.Error(t, err)
+
+	// µ-law 编码的静音不包含语音
+	silence := bytes.Repeat([]byte{0xff}, 16000)
+	segments, err = sm.NewContext().DetectBytes(silence, audio.FormatULaw)
+	require.NoError(t, err)
+	require.Empty(t, segments)
+
+	_, err = sm.NewContext().DetectBytes([]byte{1, 2, 3}, audio.FormatPCM16)
+	require.Error(t, err)
+
+	// 超出重采样范围的输入采样率在分配滤波器之前拒绝
+	for _, rate := range []int{1, 2147483647} {
+		_, err = sm.NewContext().detectReader(bytes.NewReader(data), audio.FormatFloat32, rate)
+		require.ErrorIs(t, err, audio.ErrUnsupportedFormat)
+	}
+}
+
+func TestDetectInto(t *testing.T) {
+	sm := newTestSharedModel(t)
+	samples := readTestSamples(t, "../testfiles/samples.pcm")
+
+	expected, err := sm.NewContext().Detect(samples)
+	require.NoError(t, err)
+	require.NotEmpty(t, expected)
+
+	// 追加在已有内容之后，已有内容不变
+	sentinel := Segment{SpeechStartAt: -1, SpeechEndAt: -1}
+	segments, err := sm.NewContext().DetectInto(samples, []Segment{sentinel})
+	require.NoError(t, err)
+	require.Equal(t, append([]Segment{sentinel}, expected...), segments)
+
+	// 在语音中途切分时，跨调用补全的片段同样追加而不是覆盖已有内容
+	split := int((expected[0].SpeechStartAt+expected[0].SpeechEndAt)/2*16000) / 512 * 512
+	dc1, dc2 := sm.NewContext(), sm.NewContext()
+	_, err = dc1.Detect(samples[:split])
+	require.NoError(t, err)
+	_, err = dc2.Detect(samples[:split])
+	require.NoError(t, err)
+	rest, err := dc1.Detect(samples[split:])
+	require.NoError(t, err)
+	segments, err = dc2.DetectInto(samples[split:], []Segment{sentinel})
+	require.NoError(t, err)
+	require.Equal(t, append([]Segment{sentinel}, rest...), segments)
+
+	// 复用结果切片时不分配内存（其它测试可能开启了调试日志）
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	dc := sm.NewContext()
+	allocs := testing.AllocsPerRun(3, func() {
+		require.NoError(t, dc.Reset())
+		segments, err = dc.DetectInto(samples, segments[:0])
+	})
+	require.NoError(t, err)
+	require.Equal(t, expected, segments)
+	require.Zero(t, allocs)
+}
+
+func TestContextConfig(t *testing.T) {
+	sm := newTestSharedModel(t)
+	samples := readTestSamples(t, "../testfiles/samples.pcm")
+
+	expected, err := sm.NewContext().Detect(samples)
+	require.NoError(t, err)
+
+	// 修改一个上下文的配置不影响其它上下文和模型
+	tuned, other := sm.NewContext(), sm.NewContext()
+	require.NoError(t, tuned.WithConfig(func(cfg *DetectorConfig) {
+		cfg.SpeechPadMs = 200
+	}))
+	require.Equal(t, 200, tuned.GetConfig().SpeechPadMs)
+	require.Zero(t, other.GetConfig().SpeechPadMs)
+	require.Zero(t, sm.GetConfig().SpeechPadMs)
+
+	segments, err := tuned.Detect(samples)
+	require.NoError(t, err)
+	require.Len(t, segments, len(expected))
+	require.Less(t, segments[0].SpeechStartAt, expected[0].SpeechStartAt)
+	segments, err = other.Detect(samples)
+	require.NoError(t, err)
+	require.Equal(t, expected, segments)
+
+	other.SetThreshold(0.9)
+	require.Equal(t, float32(0.9), other.GetConfig().Threshold)
+	require.Equal(t, float32(0.5), tuned.GetConfig().Threshold)
+	require.Equal(t, float32(0.5), sm.GetConfig().Threshold)
+
+	// 无效的修改返回错误，配置保持不变
+	require.Error(t, tuned.WithConfig(func(cfg *DetectorConfig) { cfg.Threshold = 1.5 }))
+	require.Error(t, tuned.WithConfig(func(cfg *DetectorConfig) { cfg.SampleRate = 8000 }))
+	require.Error(t, tuned.WithConfig(func(cfg *DetectorConfig) { cfg.ModelPath = "other.onnx" }))
+	other.SetThreshold(0)
+	require.Equal(t, float32(0.9), other.GetConfig().Threshold)
+	require.Equal(t, float32(0.5), tuned.GetConfig().Threshold)
+	require.Equal(t, 200, tuned.GetConfig().SpeechPadMs)
+
+	// 归还到池中的上下文恢复模型配置
+	sm.PutContext(tuned)
+	require.Zero(t, sm.GetContext().GetConfig().SpeechPadMs)
+}
+
+func TestDetectWithBudget(t *testing.T) {
+	sm := newTestSharedModel(t)
+	samples := readTestSamples(t, "../testfiles/samples.pcm")
+
+	expected, err := sm.NewContext().Detect(samples)
+	require.NoError(t, err)
+
+	// 预算充足时与 Detect 相同
+	res, err := sm.NewContext().DetectWithBudget(samples, time.Minute)
+	require.NoError(t, err)
+	require.Equal(t, expected, res.Segments)
+	require.False(t, res.Degraded)
+	require.Equal(t, 1, res.Stride)
+	require.InDelta(t, float64(len(samples))/16000, res.Processed, 512.0/16000)
+
+	// 预算耗尽时提前结束并标记为降级
+	res, err = sm.NewContext().DetectWithBudget(samples, time.Nanosecond)
+	require.NoError(t, err)
+	require.True(t, res.Degraded)
+	require.Less(t, res.Processed, float64(len(samples))/16000)
+
+	_, err = sm.NewContext().DetectWithBudget(samples, 0)
+	require.Error(t, err)
+}
+
+func TestDetectFile(t *testing.T) {
+	sm := newTestSharedModel(t)
+	samples := readTestSamples(t, "../testfiles/samples.pcm")
+
+	expected, err := sm.NewContext().Detect(samples)
+	require.NoError(t, err)
+
+	segments, err := sm.NewContext().DetectFile("../testfiles/samples.pcm", audio.FormatFloat32)
+	require.NoError(t, err)
+	require.Equal(t, expected, segments)
+
+	// 双声道 WAV 混为单声道后检测
+	dir := t.TempDir()
+	stereo := make([]float32, 0, 2*len(samples))
+	for _, v := range samples {
+		stereo = append(stereo, v, v)
+	}
+	path := filepath.Join(dir, "stereo.wav")
+	require.NoError(t, audio.WriteWAVFile(path, stereo, audio.WAVInfo{SampleRate: 16000, Channels: 2, BitsPerSample: 32, Float: true}))
+	segments, err = sm.NewContext().DetectFile(path, 0)
+	require.NoError(t, err)
+	require.Equal(t, expected, segments)
+
+	// 采样率与模型不一致时重新采样
+	resampled, err := audio.Resample(samples, 16000, 8000)
+	require.NoError(t, err)
+	path = filepath.Join(dir, "8k.wav")
+	require.NoError(t, audio.WriteWAVFile(path, resampled, audio.WAVInfo{SampleRate: 8000, Channels: 1, BitsPerSample: 16}))
+	segments, err = sm.NewContext().DetectFile(path, 0)
+	require.NoError(t, err)
+	require.Len(t, segments, len(expected))
+	for i := range expected {
+		require.InDelta(t, expected[i].SpeechStartAt, segments[i].SpeechStartAt, 0.1)
+	}
+
+	// 文件头中超出范围的采样率
+	wav, err := os.ReadFile(path)
+	require.NoError(t, err)
+	binary.LittleEndian.PutUint32(wav[24:28], 2147483647)
+	path = filepath.Join(dir, "bad-rate.wav")
+	require.NoError(t, os.WriteFile(path, wav, 0o644))
+	_, err = sm.NewContext().DetectFile(path, 0)
+	require.ErrorIs(t, err, audio.ErrUnsupportedFormat)
+
+	_, err = sm.NewContext().DetectFile("../testfiles/samples.pcm", audio.FormatMP3)
+	require.Error(t, err)
+	_, err = sm.NewContext().DetectFile("../testfiles/samples.pcm", 0)
+	require.ErrorIs(t, err, audio.ErrInvalidWAV)
+}
+
+func TestProcessFile(t *testing.T) {
+	sm := newTestSharedModel(t)
+	samples := readTestSamples(t, "../testfiles/samples.pcm")
+
+	expected, err := sm.NewContext().DetectFile("../testfiles/samples.pcm", audio.FormatFloat32)
+	require.NoError(t, err)
+	require.NotEmpty(t, expected)
+
+	// 块长不是窗口的整数倍，跨块的片段仍与整体检测的结果一致
+	var (
+		segments []Segment
+		total    int
+	)
+	err = sm.NewContext().ProcessFile("../testfiles/samples.pcm", audio.FormatFloat32, 0.7, func(chunk FileChunk) error {
+		require.InDelta(t, float64(total)/16000, chunk.Start, 1e-9)
+		require.LessOrEqual(t, len(chunk.PCM), 11200)
+		require.Equal(t, samples[total:total+len(chunk.PCM)], chunk.PCM)
+		total += len(chunk.PCM)
+		for _, seg := range chunk.Segments {
+			require.NotZero(t, seg.SpeechEndAt)
+		}
+		segments = append(segments, chunk.Segments...)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, len(samples), total)
+	require.Len(t, segments, len(expected))
+	for i := range expected {
+		require.Equal(t, expected[i].SpeechStartAt, segments[i].SpeechStartAt)
+		if expected[i].SpeechEndAt != 0 {
+			require.Equal(t, expected[i].SpeechEndAt, segments[i].SpeechEndAt)
+		}
+	}
+
+	// 回调的错误会中止处理
+	stop := errors.New("stop")
+	calls := 0
+	err = sm.NewContext().ProcessFile("../testfiles/samples.pcm", audio.FormatFloat32, 1, func(FileChunk) error {
+		calls++
+		return stop
+	})
+	require.ErrorIs(t, err, stop)
+	require.Equal(t, 1, calls)
+
+	err = sm.NewContext().ProcessFile("../testfiles/samples.pcm", audio.FormatFloat32, 0.01, func(FileChunk) error { return nil })
+	require.Error(t, err)
+}
+
+func TestSharedModelHighPass(t *testing.T) {
+	sm, err := NewSharedModel(DetectorConfig{
+		ModelPath:        "../testfiles/silero_vad.onnx",
+		SampleRate:       16000,
+		Threshold:        0.5,
+		HighPassCutoffHz: 80,
+	})
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, sm.Destroy())
+	}()
+
+	samples := readTestSamples(t, "../testfiles/samples.pcm")
+	expected, err := sm.NewContext().Detect(samples)
+	require.NoError(t, err)
+	require.NotEmpty(t, expected)
+
+	// 直流偏置被滤除后检测结果保持不变，且不修改调用方的数据
+	shifted := make([]float32, len(samples))
+	for i, v := range samples {
+		shifted[i] = v + 0.2
+	}
+	segments, err := sm.NewContext().Detect(shifted)
+	require.NoError(t, err)
+	require.Equal(t, len(expected), len(segments))
+	require.Equal(t, samples[100]+0.2, shifted[100])
+}
+
+func TestInputCheck(t *testing.T) {
+	sm := newTestSharedModel(t)
+	samples := readTestSamples(t, "../testfiles/samples.pcm")
+
+	expected, err := sm.NewContext().Detect(samples)
+	require.NoError(t, err)
+
+	corrupted := slices.Clone(samples)
+	zeroed := slices.Clone(samples)
+	for _, i := range []int{1000, 20000, 20001} {
+		corrupted[i] = float32(math.NaN())
+		zeroed[i] = 0
+	}
+	corrupted[30000] = float32(math.Inf(-1))
+	zeroed[30000] = -1
+
+	// 拒绝含 NaN 的输入且不改变检测状态
+	dc := sm.NewContext()
+	require.NoError(t, dc.WithConfig(func(cfg *DetectorConfig) {
+		cfg.InputCheck = InputCheckReject
+	}))
+	_, err = dc.Detect(corrupted)
+	require.ErrorIs(t, err, ErrInvalidSamples)
+	segments, err := dc.Detect(samples)
+	require.NoError(t, err)
+	require.Equal(t, expected, segments)
+
+	// 修正后的结果与手动替换异常采样相同，且不修改调用方的数据
+	want, err := sm.NewContext().Detect(zeroed)
+	require.NoError(t, err)
+	dc = sm.NewContext()
+	require.NoError(t, dc.WithConfig(func(cfg *DetectorConfig) {
+		cfg.InputCheck = InputCheckSanitize
+	}))
+	segments, err = dc.Detect(corrupted)
+	require.NoError(t, err)
+	require.Equal(t, want, segments)
+	require.True(t, math.IsNaN(float64(corrupted[1000])))
+
+	require.Error(t, dc.WithConfig(func(cfg *DetectorConfig) {
+		cfg.InputCheck = InputCheck(9)
+	}))
+}
+
+func TestSharedModelAGC(t *testing.T) {
+	sm, err := NewSharedModel(DetectorConfig{
+		ModelPath:  "../testfiles/silero_vad.onnx",
+		SampleRate: 16000,
+		Threshold:  0.5,
+		AGC:        &audio.AGCConfig{TargetDB: -20},
+	})
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, sm.Destroy())
+	}()
+
+	// 衰减 30dB 的输入经过 AGC 后仍能检测到语音
+	samples := readTestSamples(t, "../testfiles/samples.pcm")
+	quiet := make([]float32, len(samples))
+	for i, v := range samples {
+		quiet[i] = v * 0.03
+	}
+
+	segments, err := sm.NewContext().Detect(quiet)
+	require.NoError(t, err)
+	require.NotEmpty(t, segments)
+}
+
+func TestSharedModelPreprocessors(t *testing.T) {
+	sm := newTestSharedModel(t)
+	samples := readTestSamples(t, "../testfiles/samples.pcm")
+
+	dc := sm.NewContext()
+	gate, err := audio.NewNoiseGate(audio.NoiseGateConfig{
+		SampleRate:  16000,
+		ThresholdDB: 0,
+	})
+	require.NoError(t, err)
+
+	// 阈值为 0dBFS 的噪声门会屏蔽全部输入
+	dc.SetPreprocessors(gate)
+	segments, err := dc.Detect(samples)
+	require.NoError(t, err)
+	require.Empty(t, segments)
+
+	require.NoError(t, dc.Reset())
+	dc.SetPreprocessors()
+	segments, err = dc.Detect(samples)
+	require.NoError(t, err)
+	require.NotEmpty(t, segments)
+}
+
+func TestSharedModelDenoiser(t *testing.T) {
+	// denoiser_test.onnx: output = input * 0.5, stateN = state + 1
+	sm, err := NewSharedModel(DetectorConfig{
+		ModelPath:  "../testfiles/silero_vad.onnx",
+		SampleRate: 16000,
+		Threshold:  0.5,
+		Denoiser: &DenoiserConfig{
+			ModelPath:       "../testfiles/denoiser_test.onnx",
+			FrameSize:       480,
+			StateInputName:  "state",
+			StateOutputName: "stateN",
+			StateShape:      []int64{2},
+		},
+	})
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, sm.Destroy())
+	}()
+
+	dc := sm.NewContext()
+	stage := dc.pre.denoiser
+	require.NotNil(t, stage)
+
+	in := make([]float32, 1000)
+	for i := range in {
+		in[i] = 0.4
+	}
+	out, err := stage.process(nil, in)
+	require.NoError(t, err)
+	require.Len(t, out, 960)
+	require.Equal(t, float32(0.2), out[0])
+	require.Equal(t, []float32{2, 2}, stage.state)
+	require.Len(t, stage.pending, 40)
+
+	require.NoError(t, dc.Reset())
+	require.Equal(t, []float32{0, 0}, stage.state)
+	require.Empty(t, stage.pending)
+
+	samples := readTestSamples(t, "../testfiles/samples.pcm")
+	segments, err := dc.Detect(samples)
+	require.NoError(t, err)
+	require.NotEmpty(t, segments)
+}
+
+func TestSharedModelStats(t *testing.T) {
+	sm := newTestSharedModel(t)
+	require.Equal(t, ModelStats{}, sm.Stats())
+
+	var observed int
+	sm.SetInferenceObserver(func(d time.Duration) {
+		require.Positive(t, d)
+		observed++
+	})
+
+	samples := readTestSamples(t, "../testfiles/samples.pcm")
+	dc := sm.NewContext()
+	segments, err := dc.Detect(samples)
+	require.NoError(t, err)
+
+	stats := sm.Stats()
+	windows := (len(samples) - 1) / 512
+	require.EqualValues(t, windows, stats.Inferences)
+	require.Equal(t, windows, observed)
+	require.Equal(t, time.Duration(windows*512)*time.Second/16000, stats.AudioProcessed)
+	require.EqualValues(t, len(segments), stats.Segments)
+	require.EqualValues(t, 1, stats.ActiveContexts)
+	require.Zero(t, stats.ActiveCalls)
+	require.Positive(t, stats.RealTimeFactor())
+	require.Less(t, stats.RealTimeFactor(), 1.0)
+	require.EqualValues(t, windows, stats.Latency.Count())
+	require.Positive(t, stats.Latency.Quantile(0.5))
+	require.LessOrEqual(t, stats.Latency.Quantile(0.5), stats.Latency.Quantile(0.99))
+
+	sm.SetInferenceObserver(nil)
+	require.NoError(t, dc.Close())
+	stats = sm.Stats()
+	require.Zero(t, stats.ActiveContexts)
+	require.Equal(t, windows, observed)
+}
+
+func TestLatencyHistogram(t *testing.T) {
+	var h LatencyHistogram
+	require.Zero(t, h.Quantile(0.5))
+	require.Len(t, h.Bounds(), len(h.Counts)-1)
+
+	for _, d := range []time.Duration{10 * time.Microsecond, 25 * time.Microsecond, 30 * time.Microsecond, 80 * time.Microsecond, time.Second} {
+		h.Counts[latencyBucket(d)]++
+	}
+	require.Equal(t, [latencyBucketCount]uint64{2, 1, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1}, h.Counts)
+	require.EqualValues(t, 5, h.Count())
+	require.Equal(t, 25*time.Microsecond, h.Quantile(0))
+	require.Equal(t, 50*time.Microsecond, h.Quantile(0.6))
+	require.Equal(t, 100*time.Microsecond, h.Quantile(0.8))
+	require.Equal(t, h.Bounds()[len(h.Bounds())-1], h.Quantile(1))
+}
+
+func TestSharedModelMemStats(t *testing.T) {
+	sm := newTestSharedModel(t)
+
+	fi, err := os.Stat("../testfiles/silero_vad.onnx")
+	require.NoError(t, err)
+
+	stats := sm.MemStats()
+	require.Equal(t, 1, stats.Sessions)
+	require.Equal(t, fi.Size(), stats.ModelBytes)
+	// musl 等不提供 mallinfo 的 C 库没有 C 堆统计
+	if runtime.GOOS == "darwin" || runtime.GOOS == "linux" && stats.Reserved > 0 {
+		// 会话权重在 C 堆上，占用至少接近模型文件大小
+		require.Greater(t, stats.InUse, uint64(fi.Size())/2)
+		require.GreaterOrEqual(t, stats.Reserved, stats.InUse)
+		require.GreaterOrEqual(t, stats.PeakInUse, stats.InUse)
+	}
+}
+
+func TestDetectFFmpeg(t *testing.T) {
+	sm := newTestSharedModel(t)
+	samples := readTestSamples(t, "../testfiles/samples.pcm")
+
+	// 模拟 ffmpeg 输出 s16le，结果应与检测量化后的音频相同
+	pcm := audio.Float32ToInt16(nil, samples)
+	raw := make([]byte, 2*len(pcm))
+	for i, v := range pcm {
+		binary.LittleEndian.PutUint16(raw[2*i:], uint16(v))
+	}
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(dir+"/raw", raw, 0o644))
+	require.NoError(t, os.WriteFile(dir+"/ffmpeg", []byte("#!/bin/sh\ncat "+dir+"/raw\n"), 0o755))
+
+	expected, err := sm.NewContext().Detect(audio.Int16ToFloat32(nil, pcm))
+	require.NoError(t, err)
+
+	segments, err := sm.NewContext().DetectFFmpeg(context.Background(), "talk.mp4", audio.FFmpegConfig{Binary: dir + "/ffmpeg"})
+	require.NoError(t, err)
+	require.Equal(t, expected, segments)
+}
+
+func TestSharedModelClassifier(t *testing.T) {
+	// classifier_test.onnx: output = softmax([0, 50*mean(input)])，均值为正的帧判为类别 1
+	cfg := DetectorConfig{
+		ModelPath:  "../testfiles/silero_vad.onnx",
+		SampleRate: 16000,
+		Threshold:  0.5,
+		Classifier: &ClassifierConfig{
+			ModelPath:   "../testfiles/classifier_test.onnx",
+			FrameSize:   8000,
+			Hop:         4000,
+			NumClasses:  2,
+			SpeechClass: 1,
+		},
+	}
+	sm, err := NewSharedModel(cfg)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, sm.Destroy())
+	}()
+	plain := newTestSharedModel(t)
+
+	samples := readTestSamples(t, "../testfiles/samples.pcm")
+	shifted := func(offset float32) []float32 {
+		out := make([]float32, len(samples))
+		for i, v := range samples {
+			out[i] = v + offset
+		}
+		return out
+	}
+
+	dc := sm.NewContext()
+	defer dc.Close()
+	require.Nil(t, dc.AudioClasses())
+
+	// 分类为语音时结果与不使用分类模型相同
+	speech := shifted(0.1)
+	ref := plain.NewContext()
+	expected, err := ref.Detect(speech)
+	require.NoError(t, err)
+	require.NoError(t, ref.Close())
+	require.NotEmpty(t, expected)
+	segments, err := dc.Detect(speech)
+	require.NoError(t, err)
+	require.Equal(t, expected, segments)
+	classes := dc.AudioClasses()
+	require.Len(t, classes, 2)
+	require.Greater(t, classes[1], float32(0.9))
+
+	// 分类为非语音（例如等待音乐）的音频不报告语音，第一帧分类之前不抑制
+	require.NoError(t, dc.Reset())
+	require.Nil(t, dc.AudioClasses())
+	segments, err = dc.Detect(shifted(-0.1))
+	require.NoError(t, err)
+	for _, seg := range segments {
+		require.Less(t, seg.SpeechStartAt, 0.5)
+	}
+	require.Less(t, dc.AudioClasses()[1], float32(0.1))
+
+	cfg.Classifier.SpeechClass = 2
+	_, err = NewSharedModel(cfg)
+	require.ErrorIs(t, err, ErrInvalidConfig)
+}
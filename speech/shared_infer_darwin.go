@@ -12,130 +12,223 @@ import (
 	"unsafe"
 )
 
+// inferScratch 推理时传给 ORT 的维度和张量数组
+// 保存在上下文中复用，使每个窗口的推理不在 Go 堆上分配内存。
+type inferScratch struct {
+	pcmDims   [2]C.longlong
+	stateDims [3]C.longlong
+	rateDims  [1]C.longlong
+	rate      [1]C.int64_t
+	inputs    [3]*C.OrtValue // pcm、state、sr
+	outputs   [2]*C.OrtValue // output、stateN
+	data      [2]unsafe.Pointer
+}
+
 // infer 使用共享模型进行推理，但每个上下文有独立的状态
 func (dc *DetectorContext) infer(pcm []float32) (float32, error) {
 	if dc == nil || dc.model == nil {
 		return 0, fmt.Errorf("invalid detector context")
 	}
+	// 检测调用在 acquire 中已检查过销毁状态，这里防止绕过 acquire 的调用访问已释放的句柄
+	if dc.model.released.Load() {
+		return 0, ErrModelDestroyed
+	}
 
-	// 使用读锁保护共享资源的访问
-	dc.model.mu.RLock()
-	defer dc.model.mu.RUnlock()
+	// ORT 的 Run 在同一会话上是线程安全的，这里无需加锁；
+	// 配置通过原子快照读取
+	cfg := dc.config()
 
 	// 创建PCM输入张量
-	var pcmValue *C.OrtValue
-	pcmInputDims := []C.longlong{
-		1,
-		C.longlong(len(pcm)),
-	}
+	sc := &dc.scratch
+	sc.pcmDims = [2]C.longlong{1, C.longlong(len(pcm))}
 	status := C.OrtApiCreateTensorWithDataAsOrtValue(
 		dc.model.api,
 		dc.model.memoryInfo,
 		unsafe.Pointer(&pcm[0]),
 		C.size_t(len(pcm)*4),
-		&pcmInputDims[0],
-		C.size_t(len(pcmInputDims)),
+		&sc.pcmDims[0],
+		C.size_t(len(sc.pcmDims)),
 		C.ONNX_TENSOR_ELEMENT_DATA_TYPE_FLOAT,
-		&pcmValue,
+		&sc.inputs[0],
 	)
 	defer C.OrtApiReleaseStatus(dc.model.api, status)
 	if status != nil {
-		return 0, fmt.Errorf("failed to create pcm value: %s", C.GoString(C.OrtApiGetErrorMessage(dc.model.api, status)))
+		return 0, fmt.Errorf("failed to create pcm value: %w", newOrtError(dc.model.api, status))
 	}
-	defer C.OrtApiReleaseValue(dc.model.api, pcmValue)
+	defer C.OrtApiReleaseValue(dc.model.api, sc.inputs[0])
 
 	// 创建状态输入张量（使用上下文的独立状态）
-	var stateValue *C.OrtValue
-	stateNodeInputDims := []C.longlong{2, 1, 128}
+	sc.stateDims = [3]C.longlong{2, 1, 128}
 	status = C.OrtApiCreateTensorWithDataAsOrtValue(
 		dc.model.api,
 		dc.model.memoryInfo,
 		unsafe.Pointer(&dc.state[0]),
 		C.size_t(stateLen*4),
-		&stateNodeInputDims[0],
-		C.size_t(len(stateNodeInputDims)),
+		&sc.stateDims[0],
+		C.size_t(len(sc.stateDims)),
 		C.ONNX_TENSOR_ELEMENT_DATA_TYPE_FLOAT,
-		&stateValue,
+		&sc.inputs[1],
 	)
 	defer C.OrtApiReleaseStatus(dc.model.api, status)
 	if status != nil {
-		return 0, fmt.Errorf("failed to create state value: %s", C.GoString(C.OrtApiGetErrorMessage(dc.model.api, status)))
+		return 0, fmt.Errorf("failed to create state value: %w", newOrtError(dc.model.api, status))
 	}
-	defer C.OrtApiReleaseValue(dc.model.api, stateValue)
+	defer C.OrtApiReleaseValue(dc.model.api, sc.inputs[1])
 
 	// 创建采样率输入张量
-	var rateValue *C.OrtValue
-	rateInputDims := []C.longlong{1}
-	rate := []C.int64_t{C.int64_t(dc.model.cfg.SampleRate)}
+	sc.rateDims = [1]C.longlong{1}
+	sc.rate[0] = C.int64_t(cfg.SampleRate)
 	status = C.OrtApiCreateTensorWithDataAsOrtValue(
 		dc.model.api,
 		dc.model.memoryInfo,
-		unsafe.Pointer(&rate[0]),
+		unsafe.Pointer(&sc.rate[0]),
 		C.size_t(8),
-		&rateInputDims[0],
-		C.size_t(len(rateInputDims)),
+		&sc.rateDims[0],
+		C.size_t(len(sc.rateDims)),
 		C.ONNX_TENSOR_ELEMENT_DATA_TYPE_INT64,
-		&rateValue,
+		&sc.inputs[2],
 	)
 	defer C.OrtApiReleaseStatus(dc.model.api, status)
 	if status != nil {
-		return 0, fmt.Errorf("failed to create rate value: %s", C.GoString(C.OrtApiGetErrorMessage(dc.model.api, status)))
+		return 0, fmt.Errorf("failed to create rate value: %w", newOrtError(dc.model.api, status))
 	}
-	defer C.OrtApiReleaseValue(dc.model.api, rateValue)
+	defer C.OrtApiReleaseValue(dc.model.api, sc.inputs[2])
 
 	// 运行推理
-	inputs := []*C.OrtValue{pcmValue, stateValue, rateValue}
-	outputs := []*C.OrtValue{nil, nil}
-
-	inputNames := []*C.char{
-		dc.model.cStrings["input"],
-		dc.model.cStrings["state"],
-		dc.model.cStrings["sr"],
-	}
-	outputNames := []*C.char{
-		dc.model.cStrings["output"],
-		dc.model.cStrings["stateN"],
-	}
+	sc.outputs = [2]*C.OrtValue{}
 
 	status = C.OrtApiRun(
 		dc.model.api,
-		dc.model.session,
+		dc.model.sessions[dc.slot],
 		nil,
-		&inputNames[0],
-		&inputs[0],
-		C.size_t(len(inputNames)),
-		&outputNames[0],
-		C.size_t(len(outputNames)),
-		&outputs[0],
+		&dc.model.inputNames[0],
+		&sc.inputs[0],
+		C.size_t(len(sc.inputs)),
+		&dc.model.outputNames[0],
+		C.size_t(len(sc.outputs)),
+		&sc.outputs[0],
 	)
 	defer C.OrtApiReleaseStatus(dc.model.api, status)
 	if status != nil {
-		return 0, fmt.Errorf("failed to run inference: %s", C.GoString(C.OrtApiGetErrorMessage(dc.model.api, status)))
+		return 0, fmt.Errorf("failed to run inference: %w", newOrtError(dc.model.api, status))
 	}
 
 	// 获取输出张量数据
-	var prob unsafe.Pointer
-	var stateN unsafe.Pointer
-
-	status = C.OrtApiGetTensorMutableData(dc.model.api, outputs[0], &prob)
+	status = C.OrtApiGetTensorMutableData(dc.model.api, sc.outputs[0], &sc.data[0])
 	defer C.OrtApiReleaseStatus(dc.model.api, status)
 	if status != nil {
-		return 0, fmt.Errorf("failed to get probability tensor data: %s", C.GoString(C.OrtApiGetErrorMessage(dc.model.api, status)))
+		return 0, fmt.Errorf("failed to get probability tensor data: %w", newOrtError(dc.model.api, status))
 	}
 
-	status = C.OrtApiGetTensorMutableData(dc.model.api, outputs[1], &stateN)
+	status = C.OrtApiGetTensorMutableData(dc.model.api, sc.outputs[1], &sc.data[1])
 	defer C.OrtApiReleaseStatus(dc.model.api, status)
 	if status != nil {
-		return 0, fmt.Errorf("failed to get state tensor data: %s", C.GoString(C.OrtApiGetErrorMessage(dc.model.api, status)))
+		return 0, fmt.Errorf("failed to get state tensor data: %w", newOrtError(dc.model.api, status))
 	}
 
 	// 更新上下文的状态（这是每个上下文独立的）
-	C.memcpy(unsafe.Pointer(&dc.state[0]), stateN, stateLen*4)
+	C.memcpy(unsafe.Pointer(&dc.state[0]), sc.data[1], stateLen*4)
+
+	// 先读出语音概率再释放输出张量，释放后的内存可能立即被并发的推理复用
+	prob := *(*float32)(sc.data[0])
+	C.OrtApiReleaseValue(dc.model.api, sc.outputs[0])
+	C.OrtApiReleaseValue(dc.model.api, sc.outputs[1])
+
+	return prob, nil
+}
+
+// inferBatch 在 session 上推理 b.pcm 中的前 n 个窗口
+// 输入状态取自 b.state，推理后 b.state 被替换为新状态，各窗口的语音概率写入 b.probs。
+func (b *batchScheduler) inferBatch(session *C.OrtSession, n int) error {
+	var (
+		pcmDims   = [2]C.longlong{C.longlong(n), C.longlong(b.windowSize)}
+		stateDims = [3]C.longlong{2, C.longlong(n), stateHidden}
+		rateDims  = [1]C.longlong{1}
+		rate      = [1]C.int64_t{C.int64_t(b.sampleRate)}
+		inputs    [3]*C.OrtValue
+		outputs   [2]*C.OrtValue
+		data      [2]unsafe.Pointer
+	)
+
+	status := C.OrtApiCreateTensorWithDataAsOrtValue(
+		b.api,
+		b.memoryInfo,
+		unsafe.Pointer(&b.pcm[0]),
+		C.size_t(n*b.windowSize*4),
+		&pcmDims[0],
+		C.size_t(len(pcmDims)),
+		C.ONNX_TENSOR_ELEMENT_DATA_TYPE_FLOAT,
+		&inputs[0],
+	)
+	defer C.OrtApiReleaseStatus(b.api, status)
+	if status != nil {
+		return fmt.Errorf("failed to create pcm value: %w", newOrtError(b.api, status))
+	}
+	defer C.OrtApiReleaseValue(b.api, inputs[0])
+
+	status = C.OrtApiCreateTensorWithDataAsOrtValue(
+		b.api,
+		b.memoryInfo,
+		unsafe.Pointer(&b.state[0]),
+		C.size_t(n*stateLen*4),
+		&stateDims[0],
+		C.size_t(len(stateDims)),
+		C.ONNX_TENSOR_ELEMENT_DATA_TYPE_FLOAT,
+		&inputs[1],
+	)
+	defer C.OrtApiReleaseStatus(b.api, status)
+	if status != nil {
+		return fmt.Errorf("failed to create state value: %w", newOrtError(b.api, status))
+	}
+	defer C.OrtApiReleaseValue(b.api, inputs[1])
 
-	// 释放输出张量
-	C.OrtApiReleaseValue(dc.model.api, outputs[0])
-	C.OrtApiReleaseValue(dc.model.api, outputs[1])
+	status = C.OrtApiCreateTensorWithDataAsOrtValue(
+		b.api,
+		b.memoryInfo,
+		unsafe.Pointer(&rate[0]),
+		C.size_t(8),
+		&rateDims[0],
+		C.size_t(len(rateDims)),
+		C.ONNX_TENSOR_ELEMENT_DATA_TYPE_INT64,
+		&inputs[2],
+	)
+	defer C.OrtApiReleaseStatus(b.api, status)
+	if status != nil {
+		return fmt.Errorf("failed to create rate value: %w", newOrtError(b.api, status))
+	}
+	defer C.OrtApiReleaseValue(b.api, inputs[2])
+
+	status = C.OrtApiRun(
+		b.api,
+		session,
+		nil,
+		&b.inputNames[0],
+		&inputs[0],
+		C.size_t(len(inputs)),
+		&b.outputNames[0],
+		C.size_t(len(outputs)),
+		&outputs[0],
+	)
+	defer C.OrtApiReleaseStatus(b.api, status)
+	if status != nil {
+		return fmt.Errorf("failed to run batched inference: %w", newOrtError(b.api, status))
+	}
+	defer C.OrtApiReleaseValue(b.api, outputs[0])
+	defer C.OrtApiReleaseValue(b.api, outputs[1])
+
+	status = C.OrtApiGetTensorMutableData(b.api, outputs[0], &data[0])
+	defer C.OrtApiReleaseStatus(b.api, status)
+	if status != nil {
+		return fmt.Errorf("failed to get probability tensor data: %w", newOrtError(b.api, status))
+	}
+
+	status = C.OrtApiGetTensorMutableData(b.api, outputs[1], &data[1])
+	defer C.OrtApiReleaseStatus(b.api, status)
+	if status != nil {
+		return fmt.Errorf("failed to get state tensor data: %w", newOrtError(b.api, status))
+	}
 
-	// 返回语音概率
-	return *(*float32)(prob), nil
+	copy(b.probs[:n], unsafe.Slice((*float32)(data[0]), n))
+	copy(b.state[:n*stateLen], unsafe.Slice((*float32)(data[1]), n*stateLen))
+	return nil
 }
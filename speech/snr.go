@@ -0,0 +1,60 @@
+package speech
+
+import "math"
+
+const (
+	// snrNoiseAlpha 静音窗口功率指数平均的系数，约覆盖最近 10 个窗口（16kHz 下 0.3 秒）
+	snrNoiseAlpha = 0.1
+	// snrNoiseFloor 噪声功率的下限（-100dBFS），避免数字静音时信噪比为无穷大
+	snrNoiseFloor = 1e-10
+)
+
+// snrTracker 按窗口统计语音和静音的功率，在片段结束时估计信噪比
+// 语音功率是片段内语音窗口（概率不低于 Threshold）的平均功率，
+// 噪声功率是片段前后静音窗口（概率低于 Threshold-0.15）的指数滑动平均，包括判定结束所用的尾部静音。
+type snrTracker struct {
+	noise    float64 // 静音窗口功率的滑动平均
+	hasNoise bool    // 已经见过静音窗口
+	speech   float64 // 当前片段语音窗口的功率之和
+	windows  int     // 当前片段语音窗口的数量
+}
+
+// observe 记录一个窗口的功率
+func (t *snrTracker) observe(cfg *DetectorConfig, window []float32, prob float32) {
+	switch {
+	case prob >= cfg.Threshold:
+		t.speech += windowPower(window)
+		t.windows++
+	case prob < cfg.Threshold-0.15:
+		p := windowPower(window)
+		if !t.hasNoise {
+			t.noise, t.hasNoise = p, true
+		} else {
+			t.noise += snrNoiseAlpha * (p - t.noise)
+		}
+	}
+}
+
+// finish 返回当前片段的信噪比（dB），并开始统计下一个片段
+func (t *snrTracker) finish() float64 {
+	if t.windows == 0 {
+		return 0
+	}
+	snr := 10 * math.Log10(t.speech/float64(t.windows)/max(t.noise, snrNoiseFloor))
+	t.speech, t.windows = 0, 0
+	return snr
+}
+
+// reset 清空统计
+func (t *snrTracker) reset() {
+	*t = snrTracker{}
+}
+
+// windowPower 返回窗口的平均功率
+func windowPower(window []float32) float64 {
+	var sum float64
+	for _, v := range window {
+		sum += float64(v) * float64(v)
+	}
+	return sum / float64(len(window))
+}
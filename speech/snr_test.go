@@ -0,0 +1,72 @@
+package speech
+
+import (
+	"encoding/json"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEstimateSNR(t *testing.T) {
+	sm := newTestSharedModel(t)
+	samples := readTestSamples(t, "../testfiles/samples.pcm")
+
+	dc := sm.NewContext()
+	defer dc.Close()
+	plain, err := dc.Detect(samples)
+	require.NoError(t, err)
+	for _, seg := range plain {
+		require.Zero(t, seg.SNR)
+	}
+
+	snr := sm.NewContext()
+	defer snr.Close()
+	require.NoError(t, snr.WithConfig(func(cfg *DetectorConfig) {
+		cfg.EstimateSNR = true
+	}))
+	segments, err := snr.Detect(samples)
+	require.NoError(t, err)
+	require.Len(t, segments, len(plain))
+	for i, seg := range segments {
+		require.Equal(t, plain[i].SpeechStartAt, seg.SpeechStartAt)
+		require.Equal(t, plain[i].SpeechEndAt, seg.SpeechEndAt)
+		if seg.SpeechEndAt == 0 {
+			// 未结束的片段没有估计值
+			require.Zero(t, seg.SNR)
+			continue
+		}
+		require.Positive(t, seg.SNR)
+	}
+
+	// 逐窗口检测的其它路径给出相同的估计值
+	require.NoError(t, snr.Reset())
+	res, err := snr.DetectWithBudget(samples, time.Minute)
+	require.NoError(t, err)
+	require.Equal(t, segments, res.Segments)
+
+	// 叠加白噪声后信噪比下降
+	rng := rand.New(rand.NewSource(1))
+	noisy := make([]float32, len(samples))
+	for i, v := range samples {
+		noisy[i] = v + float32(rng.NormFloat64()*0.02)
+	}
+	require.NoError(t, snr.Reset())
+	noisySegments, err := snr.Detect(noisy)
+	require.NoError(t, err)
+	require.Len(t, noisySegments, len(segments))
+	for i, seg := range noisySegments {
+		if seg.SpeechEndAt != 0 {
+			require.Less(t, seg.SNR, segments[i].SNR)
+		}
+	}
+
+	// JSON 导出包含信噪比
+	data, err := json.Marshal(Segments(segments))
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"snr":`)
+	var decoded Segments
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.Equal(t, Segments(segments), decoded)
+}
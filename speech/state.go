@@ -0,0 +1,105 @@
+package speech
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"math"
+)
+
+const (
+	// stateMagic SaveState 数据的开头
+	stateMagic = "SVAD"
+	// stateVersion SaveState 数据的格式版本，格式不兼容地修改时递增
+	stateVersion = 1
+	// stateSize SaveState 数据的长度：magic、版本、采样率、标志、三个计数、循环状态、上下文窗口和 CRC32
+	stateSize = 4 + 2 + 4 + 2 + 3*8 + 4*stateLen + 4*contextLen + 4
+)
+
+// 状态数据中的标志位
+const (
+	stateTriggered = 1 << iota
+	stateClipped
+)
+
+// SaveState 把该上下文的检测状态序列化为字节，供 RestoreState 在另一个进程或机器上恢复
+// 包括模型的循环状态和上下文窗口、采样计数、进行中的片段（是否在语音中、开始时间、候选结束位置）和削波标志，
+// 长时间运行的流因此可以在进程重启后继续，或迁移到其它节点。
+// 数据为带版本和校验和的小端二进制，长度固定，不包含配置：恢复时使用恢复方上下文的配置。
+// 预处理（滤波、降噪、AGC、重采样）、分类模型、信噪比与响度统计和低功耗模式的状态不保存，恢复后从初始状态开始，
+// 它们的记忆都很短，对结果的影响只在恢复后的最初几十毫秒。不能与该上下文的检测调用同时进行。
+func (dc *DetectorContext) SaveState() []byte {
+	if dc == nil || dc.model == nil {
+		return nil
+	}
+	buf := make([]byte, 0, stateSize)
+	buf = append(buf, stateMagic...)
+	buf = binary.LittleEndian.AppendUint16(buf, stateVersion)
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(dc.config().SampleRate))
+	var flags uint16
+	if dc.triggered {
+		flags |= stateTriggered
+	}
+	if dc.clipped {
+		flags |= stateClipped
+	}
+	buf = binary.LittleEndian.AppendUint16(buf, flags)
+	buf = binary.LittleEndian.AppendUint64(buf, uint64(dc.currSample))
+	buf = binary.LittleEndian.AppendUint64(buf, uint64(dc.tempEnd))
+	buf = binary.LittleEndian.AppendUint64(buf, math.Float64bits(dc.startAt))
+	for _, v := range dc.state {
+		buf = binary.LittleEndian.AppendUint32(buf, math.Float32bits(v))
+	}
+	for _, v := range dc.ctx {
+		buf = binary.LittleEndian.AppendUint32(buf, math.Float32bits(v))
+	}
+	return binary.LittleEndian.AppendUint32(buf, crc32.ChecksumIEEE(buf))
+}
+
+// RestoreState 恢复 SaveState 保存的检测状态，之后的检测从保存时的位置继续，片段时间戳也从该位置继续计算
+// 上下文先被重置，SaveState 不保存的状态从初始状态开始。数据损坏、版本不支持或采样率与该上下文的配置不符时
+// 返回 ErrInvalidState，上下文保持不变。
+func (dc *DetectorContext) RestoreState(data []byte) error {
+	if dc == nil || dc.model == nil {
+		return fmt.Errorf("invalid nil detector context")
+	}
+	if dc.closed.Load() {
+		return ErrContextClosed
+	}
+	if len(data) != stateSize {
+		return fmt.Errorf("%w: size %d, expected %d", ErrInvalidState, len(data), stateSize)
+	}
+	if string(data[:4]) != stateMagic {
+		return fmt.Errorf("%w: bad magic", ErrInvalidState)
+	}
+	body, sum := data[:stateSize-4], binary.LittleEndian.Uint32(data[stateSize-4:])
+	if crc32.ChecksumIEEE(body) != sum {
+		return fmt.Errorf("%w: checksum mismatch", ErrInvalidState)
+	}
+	if v := binary.LittleEndian.Uint16(data[4:]); v != stateVersion {
+		return fmt.Errorf("%w: unsupported version %d", ErrInvalidState, v)
+	}
+	if rate := int(binary.LittleEndian.Uint32(data[6:])); rate != dc.config().SampleRate {
+		return fmt.Errorf("%w: saved at %dHz, context runs at %dHz", ErrInvalidState, rate, dc.config().SampleRate)
+	}
+
+	if err := dc.Reset(); err != nil {
+		return err
+	}
+	flags := binary.LittleEndian.Uint16(data[10:])
+	dc.triggered = flags&stateTriggered != 0
+	dc.clipped = flags&stateClipped != 0
+	dc.currSample = int(binary.LittleEndian.Uint64(data[12:]))
+	dc.tempEnd = int(binary.LittleEndian.Uint64(data[20:]))
+	dc.startAt = math.Float64frombits(binary.LittleEndian.Uint64(data[28:]))
+	off := 36
+	for i := range dc.state {
+		dc.state[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[off:]))
+		off += 4
+	}
+	for i := range dc.ctx {
+		dc.ctx[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[off:]))
+		off += 4
+	}
+	return nil
+}
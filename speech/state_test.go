@@ -0,0 +1,65 @@
+package speech
+
+import (
+	"encoding/binary"
+	"hash/crc32"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSaveRestoreState(t *testing.T) {
+	sm := newTestSharedModel(t)
+	samples := readTestSamples(t, "../testfiles/samples.pcm")
+	// 在第二个片段中间的窗口边界保存，恢复后需要补全进行中的片段；
+	// 分块器中不足一个窗口的采样不属于检测状态，不在边界上保存会丢掉它们
+	split := 94 * 512
+
+	detect := func(dc *DetectorContext, pcm []float32) []Segment {
+		chunker, err := NewStreamChunker(16000)
+		require.NoError(t, err)
+		var segments []Segment
+		for off := 0; off < len(pcm); off += 320 {
+			chunker.Write(pcm[off:min(off+320, len(pcm))])
+			segs, err := dc.DetectChunks(chunker)
+			require.NoError(t, err)
+			segments = mergeSegments(segments, segs)
+		}
+		return segments
+	}
+
+	ref := sm.NewContext()
+	defer ref.Close()
+	expected := detect(ref, samples)
+
+	dc := sm.NewContext()
+	head := detect(dc, samples[:split])
+	data := dc.SaveState()
+	require.Len(t, data, stateSize)
+	require.NoError(t, dc.Close())
+
+	// 在另一个上下文中恢复后继续，与不中断的结果一致
+	restored := sm.NewContext()
+	defer restored.Close()
+	detect(restored, samples[:16000])
+	require.NoError(t, restored.RestoreState(data))
+	require.Equal(t, data, restored.SaveState())
+	tail := detect(restored, samples[split:])
+	require.Equal(t, expected, mergeSegments(head, tail))
+
+	// 损坏、截断和采样率不符的数据，上下文保持不变
+	before := restored.SaveState()
+	corrupt := append([]byte(nil), data...)
+	corrupt[100] ^= 0xff
+	require.ErrorIs(t, restored.RestoreState(corrupt), ErrInvalidState)
+	require.ErrorIs(t, restored.RestoreState(data[:len(data)-1]), ErrInvalidState)
+	require.ErrorIs(t, restored.RestoreState(nil), ErrInvalidState)
+	require.Equal(t, before, restored.SaveState())
+
+	narrow := append([]byte(nil), data[:stateSize-4]...)
+	binary.LittleEndian.PutUint32(narrow[6:], 8000)
+	narrow = binary.LittleEndian.AppendUint32(narrow, crc32.ChecksumIEEE(narrow))
+	require.ErrorContains(t, restored.RestoreState(narrow), "saved at 8000Hz")
+
+	require.ErrorIs(t, dc.RestoreState(data), ErrContextClosed)
+}
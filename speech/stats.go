@@ -0,0 +1,193 @@
+package speech
+
+import (
+	"math"
+	"sync/atomic"
+	"time"
+)
+
+// ModelStats 共享模型自创建以来的累计运行统计，所有上下文共同计入
+type ModelStats struct {
+	// 推理的窗口数
+	Inferences uint64
+	// 推理累计耗时
+	InferenceTime time.Duration
+	// 已推理的音频时长
+	AudioProcessed time.Duration
+	// 检测到的语音片段数（按片段开始计数）
+	Segments uint64
+	// 尚未 Close 的上下文数量
+	ActiveContexts int64
+	// 正在进行中的检测调用数量
+	ActiveCalls int64
+	// 单窗口推理耗时的分布
+	Latency LatencyHistogram
+	// 连续推理失败后成功重建会话的次数，见 DetectorConfig.RecoverAfterFailures
+	Recoveries uint64
+}
+
+// latencyBucketCount 推理耗时直方图的分桶数：上界从 25µs 起逐桶翻倍到约 51ms，最后一桶没有上界
+const latencyBucketCount = 13
+
+// latencyBounds 各分桶的上界（含），与 metrics 包的默认分桶一致
+var latencyBounds = func() (b [latencyBucketCount - 1]time.Duration) {
+	for i := range b {
+		b[i] = 25 * time.Microsecond << i
+	}
+	return b
+}()
+
+// LatencyHistogram 单窗口推理耗时的直方图，用于发现更换 EP、线程设置或升级 ORT 带来的性能退化
+type LatencyHistogram struct {
+	// Counts[i] 为耗时在 (Bounds()[i-1], Bounds()[i]] 内的推理次数，最后一项为超过最大上界的次数
+	Counts [latencyBucketCount]uint64
+}
+
+// Bounds 返回各分桶的上界，长度比 Counts 少 1
+func (h LatencyHistogram) Bounds() []time.Duration {
+	return latencyBounds[:]
+}
+
+// Count 返回直方图中的推理次数
+func (h LatencyHistogram) Count() uint64 {
+	var n uint64
+	for _, c := range h.Counts {
+		n += c
+	}
+	return n
+}
+
+// Quantile 返回第 q 分位（0 到 1）所在分桶的上界，是该分位耗时的上限估计
+// 没有数据时返回 0，落在最后一个分桶时返回最大上界。
+func (h LatencyHistogram) Quantile(q float64) time.Duration {
+	total := h.Count()
+	if total == 0 {
+		return 0
+	}
+	rank := max(uint64(math.Ceil(min(max(q, 0), 1)*float64(total))), 1)
+	var seen uint64
+	for i, c := range h.Counts[:len(latencyBounds)] {
+		seen += c
+		if seen >= rank {
+			return latencyBounds[i]
+		}
+	}
+	return latencyBounds[len(latencyBounds)-1]
+}
+
+// latencyBucket 返回耗时 d 所在分桶的下标
+func latencyBucket(d time.Duration) int {
+	for i, bound := range latencyBounds {
+		if d <= bound {
+			return i
+		}
+	}
+	return len(latencyBounds)
+}
+
+// RealTimeFactor 返回推理耗时与音频时长之比，小于 1 表示快于实时
+func (s ModelStats) RealTimeFactor() float64 {
+	if s.AudioProcessed == 0 {
+		return 0
+	}
+	return float64(s.InferenceTime) / float64(s.AudioProcessed)
+}
+
+// modelStats SharedModel 内部的原子计数器
+type modelStats struct {
+	inferences atomic.Uint64
+	inferNanos atomic.Int64
+	samples    atomic.Uint64
+	segments   atomic.Uint64
+	latency    [latencyBucketCount]atomic.Uint64
+	observer   atomic.Pointer[func(time.Duration)]
+}
+
+// Stats 返回当前的累计统计，可以在任意协程中调用
+func (sm *SharedModel) Stats() ModelStats {
+	cfg := sm.config()
+	stats := ModelStats{
+		Inferences:     sm.stats.inferences.Load(),
+		InferenceTime:  time.Duration(sm.stats.inferNanos.Load()),
+		AudioProcessed: time.Duration(sm.stats.samples.Load()) * time.Second / time.Duration(cfg.SampleRate),
+		Segments:       sm.stats.segments.Load(),
+		ActiveContexts: sm.refs.Load(),
+		ActiveCalls:    sm.active.Load(),
+	}
+	if sm.recovery != nil {
+		stats.Recoveries = sm.recovery.recoveries.Load()
+	}
+	for i := range stats.Latency.Counts {
+		stats.Latency.Counts[i] = sm.stats.latency[i].Load()
+	}
+	return stats
+}
+
+// SetInferenceObserver 设置每个窗口推理完成后的回调，参数为该窗口的推理耗时
+// 回调在推理协程中同步执行，应当足够轻量（例如写入直方图）。传入 nil 可取消。
+func (sm *SharedModel) SetInferenceObserver(fn func(d time.Duration)) {
+	if fn == nil {
+		sm.stats.observer.Store(nil)
+		return
+	}
+	sm.stats.observer.Store(&fn)
+}
+
+// WindowResult 单个窗口的推理结果
+type WindowResult struct {
+	// 窗口结束位置的采样下标，从上下文创建或上次 Reset 起计算
+	Sample int
+	// 语音概率
+	Probability float32
+	// 推理耗时
+	Latency time.Duration
+}
+
+// SetWindowObserver 设置该上下文每个窗口推理后的回调，用于追踪或调试，传入 nil 可取消
+// 回调在检测调用中同步执行，不要在回调中调用该上下文的方法。
+func (dc *DetectorContext) SetWindowObserver(fn func(WindowResult)) {
+	if dc != nil {
+		dc.observer = fn
+	}
+}
+
+// predict 按检测调用开始时的配置 cfg 推理一个窗口并记录统计
+// 配置了 MaxConcurrentInferences 时先按上下文的优先级排队获取推理名额，排队时间不计入推理耗时；
+// 启用批量推理时窗口交给批量调度器，耗时包括组批等待的时间。
+func (dc *DetectorContext) predict(cfg *DetectorConfig, window []float32) (float32, error) {
+	sched := dc.model.sched
+	if sched != nil {
+		sched.acquire(dc.priority)
+	}
+	start := time.Now()
+	prob, err := dc.inferWindow(window)
+	d := time.Since(start)
+	if sched != nil {
+		sched.release()
+	}
+	if err != nil {
+		return 0, err
+	}
+	prob = cfg.Calibration.Apply(prob)
+	if dc.classifier != nil {
+		if prob, err = dc.classifier.fuse(window, prob); err != nil {
+			return 0, err
+		}
+	}
+
+	stats := &dc.model.stats
+	stats.inferences.Add(1)
+	stats.inferNanos.Add(int64(d))
+	stats.latency[latencyBucket(d)].Add(1)
+	stats.samples.Add(uint64(len(window)))
+	if fn := stats.observer.Load(); fn != nil {
+		(*fn)(d)
+	}
+	dc.usage.inferences++
+	dc.usage.inferTime += d
+	dc.usage.samples += int64(len(window))
+	if dc.observer != nil {
+		dc.observer(WindowResult{Sample: dc.currSample + len(window), Probability: prob, Latency: d})
+	}
+	return prob, nil
+}
@@ -0,0 +1,262 @@
+package speech
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rui-yang-me/silero-vad-go/audio"
+)
+
+// defaultStreamQueue Stream 默认最多排队的音频时长
+const defaultStreamQueue = time.Second
+
+// OverflowPolicy Stream 的队列已满时 Push 的处理方式
+type OverflowPolicy int
+
+const (
+	// OverflowBlock Push 阻塞直到检测腾出队列空间，把压力传回采集端
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropOldest 丢弃队列中最早的音频，Push 永不阻塞，适合必须保持实时的采集回调
+	OverflowDropOldest
+)
+
+// StreamConfig Stream 的配置
+type StreamConfig struct {
+	// 队列最多缓存的音频时长，0 表示默认 1 秒
+	MaxQueue time.Duration
+	// 队列已满时的处理方式，零值为 OverflowBlock
+	Policy OverflowPolicy
+	// 每次检测得到片段后在检测协程中调用，可以为 nil；片段的含义与 DetectChunks 相同，
+	// 尚未结束的语音片段 SpeechEndAt 为 0。回调耗时会计入检测延迟。
+	OnSegments func(segments []Segment)
+}
+
+// StreamStatus Stream 的队列状态
+type StreamStatus struct {
+	// 排队等待检测的音频时长
+	Queued time.Duration
+	// 已写入但尚未检测完的音频时长，包括正在检测的部分；持续增长说明检测跟不上实时
+	Lag time.Duration
+	// 因队列已满累计丢弃的音频时长
+	Dropped time.Duration
+}
+
+// Stream 在独立协程中异步检测推入的音频，队列有界
+// 采集端调用 Push 写入音频并根据返回的 StreamStatus 判断检测是否落后于实时，
+// 队列满时按 OverflowPolicy 阻塞或丢弃最早的音频，而不是无限增长缓冲。
+// 不足一个窗口的尾部采样保留在内部，与之后推入的音频拼接后检测，因此 Push 的块大小不影响检测结果；
+// Close 时剩余的尾部在配置了 PadShortInput 时补零检测，否则丢弃。
+// Push、Status 和 Close 可以在不同协程中调用。
+type Stream struct {
+	dc         *DetectorContext
+	cfg        StreamConfig
+	sampleRate int
+	maxQueue   int              // 队列容量（模型采样率的采样数）
+	resampler  *audio.Resampler // 配置了 InputSampleRate 时把推入的音频转换为模型采样率
+
+	mu       sync.Mutex
+	cond     *sync.Cond // 队列、检测进度或关闭状态变化时广播
+	queue    []float32
+	spare    []float32 // 检测协程归还的缓冲，与 queue 交替使用
+	skip     int       // 队列头部之前被丢弃、尚未计入检测位置的采样数
+	inflight int       // 正在检测的采样数
+	dropped  int
+	closed   bool
+	err      error
+	done     chan struct{}
+}
+
+// NewStream 创建在后台检测的 Stream，推入的音频须为模型采样率的单声道采样，
+// 配置了 InputSampleRate 时须为 InputSampleRate，入队前重采样为模型采样率
+// Stream 运行期间不要再直接调用 dc 的检测方法；Close 之后 dc 仍由调用方负责关闭。
+func (dc *DetectorContext) NewStream(cfg StreamConfig) (*Stream, error) {
+	if dc == nil || dc.model == nil {
+		return nil, fmt.Errorf("invalid nil detector context")
+	}
+	if cfg.MaxQueue < 0 {
+		return nil, fmt.Errorf("invalid max queue: %s", cfg.MaxQueue)
+	}
+	if cfg.Policy != OverflowBlock && cfg.Policy != OverflowDropOldest {
+		return nil, fmt.Errorf("invalid overflow policy: %d", cfg.Policy)
+	}
+	if cfg.MaxQueue == 0 {
+		cfg.MaxQueue = defaultStreamQueue
+	}
+
+	dcCfg := dc.config()
+	sampleRate := dcCfg.SampleRate
+	chunker, err := NewStreamChunker(sampleRate)
+	if err != nil {
+		return nil, err
+	}
+	var resampler *audio.Resampler
+	if rate := dcCfg.inputRate(); rate != sampleRate {
+		if resampler, err = audio.NewResampler(rate, sampleRate); err != nil {
+			return nil, err
+		}
+	}
+
+	s := &Stream{
+		dc:         dc,
+		cfg:        cfg,
+		sampleRate: sampleRate,
+		maxQueue:   max(int(cfg.MaxQueue.Seconds()*float64(sampleRate)), chunker.WindowSize()),
+		resampler:  resampler,
+		done:       make(chan struct{}),
+	}
+	s.cond = sync.NewCond(&s.mu)
+	go s.run(chunker)
+	return s, nil
+}
+
+// Push 把 pcm 加入检测队列并返回加入后的队列状态，数据会被复制
+// 队列已满时按 OverflowBlock 阻塞或按 OverflowDropOldest 丢弃最早的音频。
+// 检测出错后返回该错误，Close 之后返回 ErrStreamClosed。
+func (s *Stream) Push(pcm []float32) (StreamStatus, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.resampler != nil && !s.closed {
+		// 阻塞等待队列空间时会释放锁，每次推入使用新的缓冲，避免被其它协程的 Push 覆盖
+		pcm = s.resampler.Process(nil, pcm)
+	}
+
+	for len(pcm) > 0 {
+		if s.closed {
+			return s.status(), ErrStreamClosed
+		}
+		if s.err != nil {
+			return s.status(), s.err
+		}
+
+		if s.cfg.Policy == OverflowDropOldest {
+			s.queue = append(s.queue, pcm...)
+			pcm = nil
+			if over := len(s.queue) - s.maxQueue; over > 0 {
+				n := copy(s.queue, s.queue[over:])
+				s.queue = s.queue[:n]
+				s.skip += over
+				s.dropped += over
+			}
+			break
+		}
+
+		space := s.maxQueue - len(s.queue)
+		if space == 0 {
+			s.cond.Wait()
+			continue
+		}
+		n := min(space, len(pcm))
+		s.queue = append(s.queue, pcm[:n]...)
+		pcm = pcm[n:]
+		s.cond.Broadcast()
+	}
+	s.cond.Broadcast()
+
+	return s.status(), nil
+}
+
+// Status 返回当前的队列状态
+func (s *Stream) Status() StreamStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.status()
+}
+
+func (s *Stream) status() StreamStatus {
+	return StreamStatus{
+		Queued:  s.duration(len(s.queue)),
+		Lag:     s.duration(len(s.queue) + s.inflight),
+		Dropped: s.duration(s.dropped),
+	}
+}
+
+func (s *Stream) duration(samples int) time.Duration {
+	return time.Duration(samples) * time.Second / time.Duration(s.sampleRate)
+}
+
+// Close 停止接收音频，等待已排队的音频检测完毕后返回检测过程中的错误
+// 重复调用是安全的。
+func (s *Stream) Close() error {
+	s.mu.Lock()
+	if s.resampler != nil && !s.closed {
+		// 取出重采样器延迟中的尾部采样
+		s.queue = s.resampler.Flush(s.queue)
+	}
+	s.closed = true
+	s.cond.Broadcast()
+	s.mu.Unlock()
+
+	<-s.done
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// flush 在关闭时按 PadShortInput 检测最后不足一个窗口的尾部
+func (s *Stream) flush(chunker *StreamChunker) {
+	if !s.dc.config().PadShortInput || !chunker.Pad() {
+		return
+	}
+	segments, err := s.dc.DetectChunks(chunker)
+	if err != nil {
+		s.mu.Lock()
+		s.err = err
+		s.mu.Unlock()
+		return
+	}
+	if len(segments) > 0 && s.cfg.OnSegments != nil {
+		s.cfg.OnSegments(segments)
+	}
+}
+
+// run 检测协程，每次取走队列中的全部音频
+func (s *Stream) run(chunker *StreamChunker) {
+	defer close(s.done)
+
+	for {
+		s.mu.Lock()
+		for len(s.queue) == 0 && !s.closed {
+			s.cond.Wait()
+		}
+		if len(s.queue) == 0 {
+			s.mu.Unlock()
+			s.flush(chunker)
+			return
+		}
+		batch := s.queue
+		s.queue = s.spare[:0]
+		skip := s.skip
+		s.skip = 0
+		s.inflight = len(batch)
+		s.cond.Broadcast()
+		s.mu.Unlock()
+
+		if skip > 0 {
+			// 丢弃的音频与尚未组成窗口的采样不再连续，一并跳过并保持时间戳与实际音频对齐
+			s.dc.currSample += skip + chunker.Buffered()
+			chunker.Reset()
+		}
+		chunker.Write(batch)
+		segments, err := s.dc.DetectChunks(chunker)
+
+		s.mu.Lock()
+		s.inflight = 0
+		s.spare = batch
+		if err != nil {
+			s.err = err
+			s.queue = s.queue[:0]
+		}
+		s.cond.Broadcast()
+		s.mu.Unlock()
+		if err != nil {
+			return
+		}
+
+		if len(segments) > 0 && s.cfg.OnSegments != nil {
+			s.cfg.OnSegments(segments)
+		}
+	}
+}
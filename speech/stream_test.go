@@ -0,0 +1,129 @@
+package speech
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStream(t *testing.T) {
+	sm := newTestSharedModel(t)
+	samples := readTestSamples(t, "../testfiles/samples.pcm")
+
+	expected, err := sm.NewContext().Detect(samples)
+	require.NoError(t, err)
+
+	dc := sm.NewContext()
+	defer dc.Close()
+
+	_, err = dc.NewStream(StreamConfig{MaxQueue: -time.Second})
+	require.Error(t, err)
+	_, err = dc.NewStream(StreamConfig{Policy: OverflowPolicy(5)})
+	require.Error(t, err)
+
+	// 阻塞策略下队列容量很小，所有音频仍被完整检测
+	var segments []Segment
+	s, err := dc.NewStream(StreamConfig{
+		MaxQueue: 50 * time.Millisecond,
+		OnSegments: func(segs []Segment) {
+			segments = mergeSegments(segments, segs)
+		},
+	})
+	require.NoError(t, err)
+	for off := 0; off < len(samples); off += 320 {
+		status, err := s.Push(samples[off:min(off+320, len(samples))])
+		require.NoError(t, err)
+		require.LessOrEqual(t, status.Queued, 50*time.Millisecond)
+		require.Zero(t, status.Dropped)
+	}
+	require.NoError(t, s.Close())
+	require.NoError(t, s.Close())
+	require.Equal(t, expected, segments)
+	require.Equal(t, StreamStatus{}, s.Status())
+
+	_, err = s.Push(samples[:320])
+	require.ErrorIs(t, err, ErrStreamClosed)
+}
+
+func TestStreamDropOldest(t *testing.T) {
+	sm := newTestSharedModel(t)
+	samples := readTestSamples(t, "../testfiles/samples.pcm")
+
+	dc := sm.NewContext()
+	defer dc.Close()
+
+	// 让检测协程停在第一个窗口，模拟检测跟不上实时
+	blocked := make(chan struct{})
+	dc.SetWindowObserver(func(WindowResult) {
+		<-blocked
+	})
+	s, err := dc.NewStream(StreamConfig{
+		MaxQueue: 100 * time.Millisecond,
+		Policy:   OverflowDropOldest,
+	})
+	require.NoError(t, err)
+
+	// 丢弃策略下 Push 永不阻塞，队列不超过容量
+	var status StreamStatus
+	for off := 0; off < len(samples); off += 320 {
+		status, err = s.Push(samples[off:min(off+320, len(samples))])
+		require.NoError(t, err)
+		require.LessOrEqual(t, status.Queued, 100*time.Millisecond)
+	}
+	require.Greater(t, status.Dropped, time.Duration(0))
+	require.Greater(t, status.Lag, time.Duration(0))
+
+	close(blocked)
+	require.NoError(t, s.Close())
+
+	// 丢弃的音频仍计入检测位置，时间戳与实际音频对齐
+	require.Greater(t, dc.currSample, len(samples)-512)
+	require.LessOrEqual(t, dc.currSample, len(samples))
+}
+
+func TestStreamCarryOver(t *testing.T) {
+	sm := newTestSharedModel(t)
+	samples := readTestSamples(t, "../testfiles/samples.pcm")
+
+	run := func(sizes []int, pad bool) ([]Segment, int) {
+		dc := sm.NewContext()
+		defer dc.Close()
+		require.NoError(t, dc.WithConfig(func(cfg *DetectorConfig) {
+			cfg.PadShortInput = pad
+		}))
+		windows := 0
+		dc.SetWindowObserver(func(WindowResult) {
+			windows++
+		})
+
+		var segments []Segment
+		s, err := dc.NewStream(StreamConfig{
+			OnSegments: func(segs []Segment) {
+				segments = mergeSegments(segments, segs)
+			},
+		})
+		require.NoError(t, err)
+		for off, i := 0, 0; off < len(samples); i++ {
+			n := min(sizes[i%len(sizes)], len(samples)-off)
+			_, err := s.Push(samples[off : off+n])
+			require.NoError(t, err)
+			off += n
+		}
+		require.NoError(t, s.Close())
+		return segments, windows
+	}
+
+	// 任意块大小都不会在块边界丢失采样，结果与整段推入相同
+	expected, windows := run([]int{len(samples)}, false)
+	require.Equal(t, len(samples)/512, windows)
+	for _, sizes := range [][]int{{1, 7, 333}, {160}, {511, 513}} {
+		segments, n := run(sizes, false)
+		require.Equal(t, expected, segments)
+		require.Equal(t, windows, n)
+	}
+
+	// PadShortInput 时关闭前补零检测最后的尾部
+	_, n := run([]int{320}, true)
+	require.Equal(t, windows+1, n)
+}
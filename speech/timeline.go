@@ -0,0 +1,123 @@
+package speech
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+const (
+	// timelineGapTolerance 数据到达时间与预测时间的偏差超过该值时视为丢包或时钟跳变，
+	// 直接以到达时间重新定位，而不是当作时钟漂移平滑处理
+	timelineGapTolerance = 200 * time.Millisecond
+	// timelineDriftWindow 估计实际采样率所用的最短观测时长，
+	// 过短时网络抖动会淹没真实的时钟漂移
+	timelineDriftWindow = 2 * time.Second
+	// timelineMaxAnchors 保留的锚点数量上限，更早的采样按最早的锚点外推
+	timelineMaxAnchors = 4096
+)
+
+// timelineAnchor 从 sample 开始的一段线性映射
+type timelineAnchor struct {
+	sample int64
+	at     time.Time
+	rate   float64
+}
+
+// Timeline 将检测器的采样位置映射为墙钟时间
+// 每收到一块音频就调用 Observe 记录其采集时间。两次 Observe 之间的时间差如果与
+// 采样数明显不符（丢包、采集中断），Timeline 会在该处重新定位；较小的持续偏差被视为
+// 声卡与系统时钟之间的漂移，通过估计实际采样率逐步修正。
+// Timeline 不是并发安全的。
+type Timeline struct {
+	nominal  float64
+	rate     float64 // 估计的实际采样率
+	received int64
+	anchors  []timelineAnchor
+	// 最近一次重新定位的位置，作为估计采样率的基线起点；基线越长，抖动的影响越小
+	refSample int64
+	refAt     time.Time
+}
+
+// NewTimeline 创建时间轴，start 为第一个采样的采集时间
+func NewTimeline(sampleRate int, start time.Time) (*Timeline, error) {
+	if sampleRate <= 0 {
+		return nil, fmt.Errorf("invalid sample rate: %d", sampleRate)
+	}
+
+	return &Timeline{
+		nominal: float64(sampleRate),
+		rate:    float64(sampleRate),
+		anchors: []timelineAnchor{{at: start, rate: float64(sampleRate)}},
+		refAt:   start,
+	}, nil
+}
+
+// Observe 记录一块包含 n 个采样、首个采样在 at 时刻采集的音频
+// 必须按顺序对送入检测器的每一块音频调用，丢失的数据块不需要调用。
+func (t *Timeline) Observe(n int, at time.Time) {
+	if n <= 0 {
+		return
+	}
+
+	last := t.anchors[len(t.anchors)-1]
+	expected := last.at.Add(samplesToDuration(t.received-last.sample, last.rate))
+	diff := at.Sub(expected)
+
+	switch {
+	case diff > timelineGapTolerance || diff < -timelineGapTolerance:
+		// 丢包或时钟跳变：以实际到达时间为准重新定位
+		t.addAnchor(timelineAnchor{sample: t.received, at: at, rate: t.rate})
+		t.refSample, t.refAt = t.received, at
+	case at.Sub(last.at) >= timelineDriftWindow && at.Sub(t.refAt) >= timelineDriftWindow:
+		// 用自基线起点以来的全部数据估计实际采样率，并只修正一部分时间偏差以抑制抖动
+		t.rate = float64(t.received-t.refSample) / at.Sub(t.refAt).Seconds()
+		t.addAnchor(timelineAnchor{sample: t.received, at: expected.Add(diff / 4), rate: t.rate})
+	}
+
+	t.received += int64(n)
+}
+
+func (t *Timeline) addAnchor(a timelineAnchor) {
+	if len(t.anchors) >= timelineMaxAnchors {
+		n := copy(t.anchors, t.anchors[len(t.anchors)/2:])
+		t.anchors = t.anchors[:n]
+	}
+	t.anchors = append(t.anchors, a)
+}
+
+// Time 返回第 sample 个采样（从 0 开始、只计入收到的采样）的采集时间
+func (t *Timeline) Time(sample int64) time.Time {
+	i := sort.Search(len(t.anchors), func(i int) bool {
+		return t.anchors[i].sample > sample
+	}) - 1
+	if i < 0 {
+		i = 0
+	}
+
+	a := t.anchors[i]
+	return a.at.Add(samplesToDuration(sample-a.sample, a.rate))
+}
+
+// SegmentTimes 返回语音片段起止位置对应的墙钟时间，未结束的片段 end 为零值
+func (t *Timeline) SegmentTimes(s Segment) (start, end time.Time) {
+	start = t.Time(int64(s.SpeechStartAt * t.nominal))
+	if s.SpeechEndAt > 0 {
+		end = t.Time(int64(s.SpeechEndAt * t.nominal))
+	}
+	return start, end
+}
+
+// Received 返回已记录的采样总数
+func (t *Timeline) Received() int64 {
+	return t.received
+}
+
+// Rate 返回估计的实际采样率
+func (t *Timeline) Rate() float64 {
+	return t.rate
+}
+
+func samplesToDuration(n int64, rate float64) time.Duration {
+	return time.Duration(float64(n) / rate * float64(time.Second))
+}
@@ -0,0 +1,63 @@
+package speech
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeline(t *testing.T) {
+	_, err := NewTimeline(0, time.Now())
+	require.Error(t, err)
+
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tl, err := NewTimeline(16000, start)
+	require.NoError(t, err)
+
+	// 20ms 一包，连续 5 秒
+	at := start
+	for i := 0; i < 250; i++ {
+		tl.Observe(320, at)
+		at = at.Add(20 * time.Millisecond)
+	}
+	require.EqualValues(t, 80000, tl.Received())
+	require.Equal(t, start.Add(time.Second), tl.Time(16000))
+
+	// 丢失 1 秒的数据后，之后的采样应映射到实际到达时间
+	at = at.Add(time.Second)
+	gapSample := tl.Received()
+	tl.Observe(320, at)
+	require.Equal(t, at, tl.Time(gapSample))
+	require.Equal(t, start.Add(time.Second), tl.Time(16000))
+
+	seg := Segment{SpeechStartAt: 1, SpeechEndAt: float64(gapSample+160) / 16000}
+	s, e := tl.SegmentTimes(seg)
+	require.Equal(t, start.Add(time.Second), s)
+	require.Equal(t, at.Add(10*time.Millisecond), e)
+
+	s, e = tl.SegmentTimes(Segment{SpeechStartAt: 1})
+	require.Equal(t, start.Add(time.Second), s)
+	require.True(t, e.IsZero())
+}
+
+func TestTimelineDrift(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tl, err := NewTimeline(16000, start)
+	require.NoError(t, err)
+
+	// 声卡实际以 16016Hz 采样（快 0.1%），每包到达时间带 ±3ms 抖动
+	const actual = 16016.0
+	for i := 0; i < 5*60*50; i++ {
+		jitter := time.Duration((i*7919)%7-3) * time.Millisecond
+		truth := start.Add(time.Duration(float64(i*320) / actual * float64(time.Second)))
+		tl.Observe(320, truth.Add(jitter))
+	}
+
+	require.InDelta(t, actual, tl.Rate(), 2)
+
+	// 5 分钟后按标称采样率计算会偏差约 300ms，漂移修正后误差应在几毫秒内
+	n := tl.Received()
+	truth := start.Add(time.Duration(float64(n) / actual * float64(time.Second)))
+	require.InDelta(t, 0, tl.Time(n).Sub(truth).Seconds(), 0.005)
+}
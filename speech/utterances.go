@@ -0,0 +1,129 @@
+package speech
+
+import (
+	"fmt"
+	"time"
+)
+
+// UtteranceConfig AggregateUtterances 的配置
+type UtteranceConfig struct {
+	// 每段话的最长时长（包括 Pad），例如 ASR 接口限制的 15 秒，必须为正
+	MaxDuration time.Duration
+	// 相邻片段之间的静音超过该时长时总是分成两段话，0 表示不限制，只按 MaxDuration 合并
+	MaxGap time.Duration
+	// 每段话两端额外保留的音频；相邻两段话之间的静音不足两倍 Pad 时各取一半，互不重叠
+	Pad time.Duration
+	// 单个片段超过 MaxDuration 时被强制切分，相邻两块之间重叠的时长，切分处不再添加 Pad
+	Overlap time.Duration
+}
+
+// IsValid 校验配置
+func (c UtteranceConfig) IsValid() error {
+	if c.MaxDuration <= 0 {
+		return fmt.Errorf("invalid MaxDuration: should be a positive duration")
+	}
+	if c.MaxGap < 0 || c.Pad < 0 || c.Overlap < 0 {
+		return fmt.Errorf("invalid utterance durations: should not be negative")
+	}
+	if 2*c.Pad+c.Overlap >= c.MaxDuration {
+		return fmt.Errorf("invalid Pad and Overlap: 2*Pad+Overlap should be shorter than MaxDuration")
+	}
+	return nil
+}
+
+// utterancePiece 一段连续的语音，cutStart/cutEnd 表示该端是强制切分处
+type utterancePiece struct {
+	start, end       float64
+	cutStart, cutEnd bool
+}
+
+// AggregateUtterances 把检测到的语音片段整理为不超过 MaxDuration 的若干段话，便于送入有长度限制的 ASR 接口
+// 相邻片段尽量合并到同一段话中，只在片段之间的静音处分开；单个片段本身过长时才在语音中强制切分，
+// 切分出的各块按 Overlap 重叠，避免切断的词完全丢失。
+// duration 为音频总时长（秒），用于限制结尾的 Pad 和补齐未结束的片段；为 0 时不限制结尾，未结束的片段被忽略。
+// 结果按时间排序，可直接用于 ExtractSegments、ExportSegmentsWAV 等函数。
+func AggregateUtterances(segments []Segment, duration float64, cfg UtteranceConfig) ([]Segment, error) {
+	if err := cfg.IsValid(); err != nil {
+		return nil, err
+	}
+	if duration < 0 {
+		return nil, fmt.Errorf("invalid duration: %f", duration)
+	}
+
+	maxLen := (cfg.MaxDuration - 2*cfg.Pad).Seconds()
+	overlap := cfg.Overlap.Seconds()
+	maxGap := cfg.MaxGap.Seconds()
+	pad := cfg.Pad.Seconds()
+
+	// 合并因填充而重叠的片段
+	var ranges [][2]float64
+	for _, seg := range segments {
+		end := seg.SpeechEndAt
+		if end == 0 {
+			if duration == 0 {
+				continue
+			}
+			end = duration
+		}
+		if duration > 0 {
+			end = min(end, duration)
+		}
+		start := max(seg.SpeechStartAt, 0)
+		if start >= end {
+			continue
+		}
+		if n := len(ranges); n > 0 && start <= ranges[n-1][1] {
+			ranges[n-1][1] = max(ranges[n-1][1], end)
+			continue
+		}
+		ranges = append(ranges, [2]float64{start, end})
+	}
+
+	// 过长的片段按 maxLen 强制切分，相邻块重叠 overlap
+	var pieces []utterancePiece
+	for _, r := range ranges {
+		start := r[0]
+		for r[1]-start > maxLen {
+			pieces = append(pieces, utterancePiece{start: start, end: start + maxLen, cutStart: start > r[0], cutEnd: true})
+			start += maxLen - overlap
+		}
+		pieces = append(pieces, utterancePiece{start: start, end: r[1], cutStart: start > r[0]})
+	}
+
+	// 依次合并相邻的块，合并后仍不超过 maxLen 且间隔不超过 MaxGap
+	var merged []utterancePiece
+	for _, p := range pieces {
+		if n := len(merged); n > 0 {
+			last := &merged[n-1]
+			if !last.cutEnd && p.end-last.start <= maxLen && (maxGap == 0 || p.start-last.end <= maxGap) {
+				last.end = p.end
+				last.cutEnd = p.cutEnd
+				continue
+			}
+		}
+		merged = append(merged, p)
+	}
+
+	// 两端添加 Pad，不越过相邻两段话之间静音的中点和音频边界
+	out := make([]Segment, len(merged))
+	for i, p := range merged {
+		start, end := p.start, p.end
+		if !p.cutStart {
+			start = max(start-pad, 0)
+			if i > 0 {
+				start = max(start, (merged[i-1].end+p.start)/2)
+			}
+		}
+		if !p.cutEnd {
+			end += pad
+			if i+1 < len(merged) {
+				end = min(end, (p.end+merged[i+1].start)/2)
+			}
+			if duration > 0 {
+				end = min(end, duration)
+			}
+		}
+		out[i] = Segment{SpeechStartAt: start, SpeechEndAt: end}
+	}
+	return out, nil
+}
@@ -0,0 +1,74 @@
+package speech
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAggregateUtterances(t *testing.T) {
+	for _, bad := range []UtteranceConfig{
+		{},
+		{MaxDuration: time.Second, Pad: -time.Millisecond},
+		{MaxDuration: time.Second, Pad: 400 * time.Millisecond, Overlap: 200 * time.Millisecond},
+	} {
+		_, err := AggregateUtterances(nil, 0, bad)
+		require.Error(t, err)
+	}
+	_, err := AggregateUtterances(nil, -1, UtteranceConfig{MaxDuration: time.Second})
+	require.Error(t, err)
+
+	segments := []Segment{
+		{SpeechStartAt: 1, SpeechEndAt: 4},
+		{SpeechStartAt: 3.9, SpeechEndAt: 6}, // 因填充与上一个重叠
+		{SpeechStartAt: 7, SpeechEndAt: 9},
+		{SpeechStartAt: 12, SpeechEndAt: 13},
+		{SpeechStartAt: 20, SpeechEndAt: 45}, // 过长，强制切分
+		{SpeechStartAt: 46, SpeechEndAt: 47},
+		{SpeechStartAt: 58}, // 未结束
+	}
+
+	// 只按时长合并
+	utts, err := AggregateUtterances(segments, 60, UtteranceConfig{MaxDuration: 10 * time.Second})
+	require.NoError(t, err)
+	require.Equal(t, []Segment{
+		{SpeechStartAt: 1, SpeechEndAt: 9},
+		{SpeechStartAt: 12, SpeechEndAt: 13},
+		{SpeechStartAt: 20, SpeechEndAt: 30},
+		{SpeechStartAt: 30, SpeechEndAt: 40},
+		{SpeechStartAt: 40, SpeechEndAt: 47},
+		{SpeechStartAt: 58, SpeechEndAt: 60},
+	}, utts)
+
+	// 限制静音间隔、添加 Pad 和切分重叠
+	utts, err = AggregateUtterances(segments, 60, UtteranceConfig{
+		MaxDuration: 10 * time.Second,
+		MaxGap:      500 * time.Millisecond,
+		Pad:         time.Second,
+		Overlap:     time.Second,
+	})
+	require.NoError(t, err)
+	want := []Segment{
+		{SpeechStartAt: 0, SpeechEndAt: 6.5},
+		{SpeechStartAt: 6.5, SpeechEndAt: 10},
+		{SpeechStartAt: 11, SpeechEndAt: 14},
+		{SpeechStartAt: 19, SpeechEndAt: 28},
+		{SpeechStartAt: 27, SpeechEndAt: 35},
+		{SpeechStartAt: 34, SpeechEndAt: 42},
+		{SpeechStartAt: 41, SpeechEndAt: 45.5},
+		{SpeechStartAt: 45.5, SpeechEndAt: 48},
+		{SpeechStartAt: 57, SpeechEndAt: 60},
+	}
+	require.Len(t, utts, len(want))
+	for i := range want {
+		require.InDelta(t, want[i].SpeechStartAt, utts[i].SpeechStartAt, 1e-9, "utterance %d", i)
+		require.InDelta(t, want[i].SpeechEndAt, utts[i].SpeechEndAt, 1e-9, "utterance %d", i)
+		require.LessOrEqual(t, utts[i].SpeechEndAt-utts[i].SpeechStartAt, 10.0)
+	}
+
+	// 不知道总时长时忽略未结束的片段
+	utts, err = AggregateUtterances(segments, 0, UtteranceConfig{MaxDuration: 10 * time.Second})
+	require.NoError(t, err)
+	require.Len(t, utts, 5)
+}
@@ -0,0 +1,100 @@
+// Package tracing 为语音检测调用生成 OpenTelemetry span，使 VAD 的耗时出现在语音流水线已有的分布式追踪中。
+//
+// 本包是可选的，只有导入它的程序才会依赖 OpenTelemetry：
+//
+//	tracer := tracing.New(otel.GetTracerProvider())
+//	segments, err := tracer.Detect(ctx, dc, pcm)
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/rui-yang-me/silero-vad-go/speech"
+)
+
+// instrumentationName 本包作为 instrumentation scope 的名称
+const instrumentationName = "github.com/rui-yang-me/silero-vad-go/tracing"
+
+// span 属性名
+const (
+	attrSamples     = attribute.Key("vad.samples")
+	attrWindows     = attribute.Key("vad.windows")
+	attrSegments    = attribute.Key("vad.segments")
+	attrSample      = attribute.Key("vad.window.sample")
+	attrProbability = attribute.Key("vad.window.probability")
+	attrLatency     = attribute.Key("vad.window.latency_us")
+)
+
+// Option 配置 Tracer
+type Option func(*Tracer)
+
+// WithWindowEvents 为每个推理窗口添加一个 span 事件，包含语音概率和推理耗时
+// 每秒音频约产生 31 个事件，超出 SDK 的 span 事件数上限（默认 128）的部分会被丢弃，
+// 适合排查短音频问题时临时开启。
+func WithWindowEvents() Option {
+	return func(t *Tracer) {
+		t.windowEvents = true
+	}
+}
+
+// Tracer 以 span 包装检测调用
+type Tracer struct {
+	tracer       trace.Tracer
+	windowEvents bool
+}
+
+// New 使用 tp 创建 Tracer，tp 通常为 otel.GetTracerProvider()
+func New(tp trace.TracerProvider, opts ...Option) *Tracer {
+	t := &Tracer{tracer: tp.Tracer(instrumentationName)}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// Detect 在名为 "vad.Detect" 的 span 中调用 dc.Detect
+func (t *Tracer) Detect(ctx context.Context, dc *speech.DetectorContext, pcm []float32) ([]speech.Segment, error) {
+	return t.run(ctx, "vad.Detect", dc, len(pcm), func() ([]speech.Segment, error) {
+		return dc.Detect(pcm)
+	})
+}
+
+// DetectChunks 在名为 "vad.DetectChunks" 的 span 中调用 dc.DetectChunks
+func (t *Tracer) DetectChunks(ctx context.Context, dc *speech.DetectorContext, c *speech.StreamChunker) ([]speech.Segment, error) {
+	return t.run(ctx, "vad.DetectChunks", dc, c.Buffered(), func() ([]speech.Segment, error) {
+		return dc.DetectChunks(c)
+	})
+}
+
+func (t *Tracer) run(ctx context.Context, name string, dc *speech.DetectorContext, samples int, detect func() ([]speech.Segment, error)) ([]speech.Segment, error) {
+	_, span := t.tracer.Start(ctx, name, trace.WithAttributes(attrSamples.Int(samples)))
+	defer span.End()
+
+	windows := 0
+	dc.SetWindowObserver(func(w speech.WindowResult) {
+		windows++
+		if t.windowEvents {
+			span.AddEvent("vad.window", trace.WithAttributes(
+				attrSample.Int(w.Sample),
+				attrProbability.Float64(float64(w.Probability)),
+				attrLatency.Int64(w.Latency.Microseconds()),
+			))
+		}
+	})
+	defer dc.SetWindowObserver(nil)
+
+	segments, err := detect()
+	span.SetAttributes(attrWindows.Int(windows))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	span.SetAttributes(attrSegments.Int(len(segments)))
+	return segments, nil
+}
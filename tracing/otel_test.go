@@ -0,0 +1,55 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/rui-yang-me/silero-vad-go/internal/testutil"
+	"github.com/rui-yang-me/silero-vad-go/speech"
+)
+
+func TestTracer(t *testing.T) {
+	sm := testutil.NewSharedModel(t)
+
+	samples := testutil.ReadSamples(t)
+
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	dc := sm.NewContext()
+	defer dc.Close()
+
+	segments, err := New(tp).Detect(context.Background(), dc, samples)
+	require.NoError(t, err)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	require.Equal(t, "vad.Detect", spans[0].Name())
+	windows := (len(samples) - 1) / 512
+	require.ElementsMatch(t, []attribute.KeyValue{
+		attrSamples.Int(len(samples)),
+		attrWindows.Int(windows),
+		attrSegments.Int(len(segments)),
+	}, spans[0].Attributes())
+	require.Empty(t, spans[0].Events())
+
+	// 开启窗口事件后每个窗口一个事件，超出 SDK 事件数上限的部分计入 DroppedEvents
+	require.NoError(t, dc.Reset())
+	_, err = New(tp, WithWindowEvents()).Detect(context.Background(), dc, samples)
+	require.NoError(t, err)
+	spans = recorder.Ended()
+	require.Len(t, spans, 2)
+	require.Equal(t, windows, len(spans[1].Events())+spans[1].DroppedEvents())
+	require.NotEmpty(t, spans[1].Events())
+
+	// 错误记录到 span 上
+	_, err = New(tp).Detect(context.Background(), dc, samples[:100])
+	require.ErrorIs(t, err, speech.ErrNotEnoughSamples)
+	spans = recorder.Ended()
+	require.Equal(t, "Error", spans[2].Status().Code.String())
+}
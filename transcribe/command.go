@@ -0,0 +1,107 @@
+package transcribe
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+
+	"github.com/rui-yang-me/silero-vad-go/audio"
+)
+
+// WAVPlaceholder Command.Args 中的占位参数，会被替换为片段 WAV 临时文件的路径
+const WAVPlaceholder = "{wav}"
+
+// whisperSampleRate whisper.cpp 只接受 16kHz 音频
+const whisperSampleRate = 16000
+
+var _ Transcriber = (*Command)(nil)
+
+// Command 通过外部命令识别音频，标准输出即为识别文本
+// 每个片段写为 16 位单声道 WAV：Args 中含有 WAVPlaceholder 时写入临时文件并替换为其路径，
+// 否则通过标准输入传给命令。
+type Command struct {
+	// 可执行文件
+	Path string
+	// 命令参数
+	Args []string
+	// 传给命令的音频采样率，与输入不一致时自动重采样，0 表示使用输入的采样率
+	SampleRate int
+}
+
+// WhisperCPP 返回调用 whisper.cpp 命令行工具（whisper-cli，旧版本为 main）的 Command
+// args 追加在默认参数之后，例如 "-l", "zh" 或 "-t", "4"。
+func WhisperCPP(binary, model string, args ...string) *Command {
+	return &Command{
+		Path:       binary,
+		Args:       append([]string{"-m", model, "-f", WAVPlaceholder, "-nt", "-np"}, args...),
+		SampleRate: whisperSampleRate,
+	}
+}
+
+// Transcribe 实现 Transcriber，返回去掉多余空白后的标准输出
+func (c *Command) Transcribe(ctx context.Context, pcm []float32, sampleRate int) (string, error) {
+	if c.SampleRate > 0 && c.SampleRate != sampleRate {
+		var err error
+		if pcm, err = audio.Resample(pcm, sampleRate, c.SampleRate); err != nil {
+			return "", err
+		}
+		sampleRate = c.SampleRate
+	}
+
+	var wav bytes.Buffer
+	if err := audio.WriteWAV(&wav, pcm, audio.WAVInfo{SampleRate: sampleRate, Channels: 1}); err != nil {
+		return "", err
+	}
+
+	args := make([]string, len(c.Args))
+	copy(args, c.Args)
+	var stdin io.Reader = &wav
+	if slices.Contains(args, WAVPlaceholder) {
+		path, err := writeTempWAV(wav.Bytes())
+		if err != nil {
+			return "", err
+		}
+		defer os.Remove(path)
+		for i, arg := range args {
+			if arg == WAVPlaceholder {
+				args[i] = path
+			}
+		}
+		stdin = nil
+	}
+
+	cmd := exec.CommandContext(ctx, c.Path, args...)
+	cmd.Stdin = stdin
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s failed: %w: %s", c.Path, err, msg)
+		}
+		return "", fmt.Errorf("%s failed: %w", c.Path, err)
+	}
+	return strings.Join(strings.Fields(string(out)), " "), nil
+}
+
+// writeTempWAV 把 WAV 数据写入临时文件并返回其路径
+func writeTempWAV(data []byte) (string, error) {
+	f, err := os.CreateTemp("", "segment-*.wav")
+	if err != nil {
+		return "", err
+	}
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", fmt.Errorf("failed to write segment wav: %w", err)
+	}
+	return f.Name(), nil
+}
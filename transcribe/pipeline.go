@@ -0,0 +1,109 @@
+package transcribe
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+
+	"github.com/rui-yang-me/silero-vad-go/audio"
+	"github.com/rui-yang-me/silero-vad-go/speech"
+)
+
+// Pipeline 检测语音片段并把片段并发交给 Transcriber，按片段顺序返回结果
+type Pipeline struct {
+	model       *speech.SharedModel
+	t           Transcriber
+	concurrency int
+}
+
+// NewPipeline 创建识别流水线，concurrency 为同时进行的识别数，0 表示 runtime.GOMAXPROCS(0)
+func NewPipeline(model *speech.SharedModel, t Transcriber, concurrency int) (*Pipeline, error) {
+	if model == nil {
+		return nil, fmt.Errorf("invalid nil shared model")
+	}
+	if t == nil {
+		return nil, fmt.Errorf("invalid nil transcriber")
+	}
+	if concurrency < 0 {
+		return nil, fmt.Errorf("invalid concurrency: %d", concurrency)
+	}
+	if concurrency == 0 {
+		concurrency = runtime.GOMAXPROCS(0)
+	}
+	return &Pipeline{model: model, t: t, concurrency: concurrency}, nil
+}
+
+// Run 识别 pcm 中的所有语音片段，结果与片段一一对应并按时间排序
+// sampleRate 与模型不同时只在检测时重采样，交给 Transcriber 的仍是原始采样率的音频。
+// 任一片段识别失败时取消其余识别并返回第一个错误。
+func (p *Pipeline) Run(ctx context.Context, pcm []float32, sampleRate int) ([]Result, error) {
+	if sampleRate <= 0 {
+		return nil, fmt.Errorf("invalid sample rate: %d", sampleRate)
+	}
+
+	detectPCM := pcm
+	if modelRate := p.model.GetConfig().SampleRate; modelRate != sampleRate {
+		var err error
+		if detectPCM, err = audio.Resample(pcm, sampleRate, modelRate); err != nil {
+			return nil, err
+		}
+	}
+
+	dc := p.model.NewContext()
+	segments, err := dc.Detect(detectPCM)
+	dc.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	clips, err := speech.ExtractSegments(pcm, sampleRate, segments)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+		sem      = make(chan struct{}, p.concurrency)
+		results  = make([]Result, len(segments))
+	)
+	for i := range segments {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			r, err := transcribe(ctx, p.t, segments[i], clips[i], sampleRate, len(pcm))
+			if err != nil {
+				once.Do(func() {
+					firstErr = err
+					cancel()
+				})
+				return
+			}
+			results[i] = r
+		}(i)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
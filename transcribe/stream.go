@@ -0,0 +1,144 @@
+package transcribe
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/rui-yang-me/silero-vad-go/speech"
+)
+
+// Stream 流式检测并在每个语音片段结束时识别，只缓存尚未识别的音频，不是并发安全的
+type Stream struct {
+	t          Transcriber
+	sampleRate int
+	padSamples int
+	dc         *speech.DetectorContext
+	chunker    *speech.StreamChunker
+
+	// buf 保存从绝对采样位置 offset 开始的音频
+	buf    []float32
+	offset int
+	// open 为 true 时 openStart 是尚未结束的片段的开始时间
+	open      bool
+	openStart float64
+	// pending 已结束但音频尚未全部到达的片段（结束时间包含 SpeechPadMs 的填充）
+	pending []speech.Segment
+}
+
+// NewStream 创建流式识别，输入音频需为模型采样率
+func NewStream(model *speech.SharedModel, t Transcriber) (*Stream, error) {
+	if model == nil {
+		return nil, fmt.Errorf("invalid nil shared model")
+	}
+	if t == nil {
+		return nil, fmt.Errorf("invalid nil transcriber")
+	}
+
+	cfg := model.GetConfig()
+	chunker, err := speech.NewStreamChunker(cfg.SampleRate)
+	if err != nil {
+		return nil, err
+	}
+	return &Stream{
+		t:          t,
+		sampleRate: cfg.SampleRate,
+		padSamples: cfg.SpeechPadMs * cfg.SampleRate / 1000,
+		dc:         model.NewContext(),
+		chunker:    chunker,
+	}, nil
+}
+
+// Write 写入一段音频，返回由此完成识别的片段结果
+// 识别在调用方的 goroutine 中同步进行。
+func (s *Stream) Write(ctx context.Context, pcm []float32) ([]Result, error) {
+	s.buf = append(s.buf, pcm...)
+	s.chunker.Write(pcm)
+
+	segments, err := s.dc.DetectChunks(s.chunker)
+	if err != nil {
+		return nil, err
+	}
+	for _, seg := range segments {
+		s.open = seg.SpeechEndAt == 0
+		if s.open {
+			s.openStart = seg.SpeechStartAt
+		} else {
+			s.pending = append(s.pending, seg)
+		}
+	}
+
+	results, err := s.transcribePending(ctx, false)
+	s.trim()
+	return results, err
+}
+
+// Flush 在输入结束时调用：识别所有剩余的片段，尚未结束的片段以音频结尾作为终点
+// 之后不应再调用 Write。
+func (s *Stream) Flush(ctx context.Context) ([]Result, error) {
+	if s.open {
+		s.pending = append(s.pending, speech.Segment{SpeechStartAt: s.openStart})
+		s.open = false
+	}
+
+	results, err := s.transcribePending(ctx, true)
+	s.trim()
+	return results, err
+}
+
+// transcribePending 识别音频已经完整到达的片段，force 为 true 时识别全部片段
+func (s *Stream) transcribePending(ctx context.Context, force bool) ([]Result, error) {
+	total := s.offset + len(s.buf)
+
+	var results []Result
+	for len(s.pending) > 0 {
+		seg := s.pending[0]
+		start, end := s.sampleRange(seg, total)
+		if end > total && !force {
+			break
+		}
+		end = min(end, total)
+
+		r, err := transcribe(ctx, s.t, seg, s.buf[start-s.offset:end-s.offset], s.sampleRate, total)
+		if err != nil {
+			return results, err
+		}
+		results = append(results, r)
+		s.pending = s.pending[1:]
+	}
+	return results, nil
+}
+
+// sampleRange 把片段换算为绝对采样区间，SpeechEndAt 为 0 时延伸到 total
+func (s *Stream) sampleRange(seg speech.Segment, total int) (int, int) {
+	start := max(int(math.Round(seg.SpeechStartAt*float64(s.sampleRate))), s.offset)
+	end := total
+	if seg.SpeechEndAt > 0 {
+		end = int(math.Round(seg.SpeechEndAt * float64(s.sampleRate)))
+	}
+	return min(start, end), end
+}
+
+// trim 丢弃不会再被任何片段用到的音频
+// 新片段的开始时间最早为已检测位置之前一个窗口再减去 SpeechPadMs 的填充。
+func (s *Stream) trim() {
+	keep := s.offset + len(s.buf) - s.chunker.Buffered() - s.chunker.WindowSize() - s.padSamples
+	if len(s.pending) > 0 {
+		start, _ := s.sampleRange(s.pending[0], 0)
+		keep = min(keep, start)
+	}
+	if s.open {
+		keep = min(keep, int(math.Round(s.openStart*float64(s.sampleRate))))
+	}
+
+	if drop := keep - s.offset; drop > 0 {
+		n := copy(s.buf, s.buf[drop:])
+		s.buf = s.buf[:n]
+		s.offset = keep
+	}
+}
+
+// Close 释放检测上下文
+func (s *Stream) Close() error {
+	return s.dc.Close()
+}
@@ -0,0 +1,84 @@
+// Package transcribe 把检测到的语音片段交给语音识别，组装带起止时间的文本结果。
+//
+// 识别器实现 Transcriber 接口，可以是任意函数（Func），也可以是外部命令，例如 whisper.cpp：
+//
+//	whisper := transcribe.WhisperCPP("whisper-cli", "ggml-base.en.bin")
+//	results, err := transcribe.Segments(ctx, model, pcm, whisper)
+//
+// 需要并发识别或输入采样率与模型不同时使用 Pipeline；流式输入使用 Stream，每个片段结束时即送去识别。
+package transcribe
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rui-yang-me/silero-vad-go/speech"
+)
+
+// Transcriber 语音识别器，识别一段单声道音频并返回文本
+// 被 Pipeline 使用时可能被并发调用。
+type Transcriber interface {
+	Transcribe(ctx context.Context, pcm []float32, sampleRate int) (string, error)
+}
+
+// Func 把普通函数适配为 Transcriber
+type Func func(ctx context.Context, pcm []float32, sampleRate int) (string, error)
+
+// Transcribe 实现 Transcriber
+func (f Func) Transcribe(ctx context.Context, pcm []float32, sampleRate int) (string, error) {
+	return f(ctx, pcm, sampleRate)
+}
+
+// Result 一个语音片段的识别结果，时间为相对音频开始的秒数
+type Result struct {
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+	Text  string  `json:"text"`
+}
+
+// Segments 检测 pcm（模型采样率）中的语音片段并依次识别，返回与片段一一对应的结果
+// 未结束的片段延伸到音频结尾。
+func Segments(ctx context.Context, model *speech.SharedModel, pcm []float32, t Transcriber) ([]Result, error) {
+	if model == nil {
+		return nil, fmt.Errorf("invalid nil shared model")
+	}
+
+	dc := model.NewContext()
+	defer dc.Close()
+	segments, err := dc.Detect(pcm)
+	if err != nil {
+		return nil, err
+	}
+
+	sampleRate := model.GetConfig().SampleRate
+	clips, err := speech.ExtractSegments(pcm, sampleRate, segments)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]Result, 0, len(segments))
+	for i, seg := range segments {
+		r, err := transcribe(ctx, t, seg, clips[i], sampleRate, len(pcm))
+		if err != nil {
+			return results, err
+		}
+		results = append(results, r)
+	}
+	return results, nil
+}
+
+// transcribe 识别一个片段，end 为 0 时以音频结尾作为片段终点
+func transcribe(ctx context.Context, t Transcriber, seg speech.Segment, clip []float32, sampleRate, total int) (Result, error) {
+	if err := ctx.Err(); err != nil {
+		return Result{}, err
+	}
+	if seg.SpeechEndAt == 0 {
+		seg.SpeechEndAt = float64(total) / float64(sampleRate)
+	}
+
+	text, err := t.Transcribe(ctx, clip, sampleRate)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to transcribe segment %.3f-%.3fs: %w", seg.SpeechStartAt, seg.SpeechEndAt, err)
+	}
+	return Result{Start: seg.SpeechStartAt, End: seg.SpeechEndAt, Text: text}, nil
+}
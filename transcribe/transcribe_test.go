@@ -0,0 +1,178 @@
+package transcribe
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rui-yang-me/silero-vad-go/audio"
+	"github.com/rui-yang-me/silero-vad-go/internal/testutil"
+	"github.com/rui-yang-me/silero-vad-go/speech"
+)
+
+func setup(t *testing.T) (*speech.SharedModel, []float32) {
+	t.Helper()
+
+	sm := testutil.NewSharedModel(t, func(cfg *speech.DetectorConfig) {
+		cfg.SpeechPadMs = 100
+	})
+	return sm, testutil.ReadSamples(t)
+}
+
+// lengthFunc 以片段的采样数作为识别文本
+var lengthFunc Func = func(_ context.Context, pcm []float32, sampleRate int) (string, error) {
+	if sampleRate != 16000 {
+		return "", errors.New("unexpected sample rate")
+	}
+	return strconv.Itoa(len(pcm)), nil
+}
+
+func TestSegments(t *testing.T) {
+	sm, samples := setup(t)
+
+	dc := sm.NewContext()
+	defer dc.Close()
+	segments, err := dc.Detect(samples)
+	require.NoError(t, err)
+	clips, err := speech.ExtractSegments(samples, 16000, segments)
+	require.NoError(t, err)
+
+	results, err := Segments(context.Background(), sm, samples, lengthFunc)
+	require.NoError(t, err)
+	require.Len(t, results, len(segments))
+	for i, r := range results {
+		require.Equal(t, segments[i].SpeechStartAt, r.Start)
+		require.Positive(t, r.End)
+		require.Equal(t, strconv.Itoa(len(clips[i])), r.Text)
+	}
+
+	_, err = Segments(context.Background(), sm, samples, Func(func(context.Context, []float32, int) (string, error) {
+		return "", errors.New("boom")
+	}))
+	require.ErrorContains(t, err, "boom")
+}
+
+func TestStream(t *testing.T) {
+	sm, samples := setup(t)
+
+	expected, err := Segments(context.Background(), sm, samples, lengthFunc)
+	require.NoError(t, err)
+
+	s, err := NewStream(sm, lengthFunc)
+	require.NoError(t, err)
+	defer s.Close()
+
+	var results []Result
+	for i := 0; i < len(samples); i += 1000 {
+		r, err := s.Write(context.Background(), samples[i:min(i+1000, len(samples))])
+		require.NoError(t, err)
+		results = append(results, r...)
+		// 只缓存尚未识别的音频
+		require.Less(t, len(s.buf), 16000*10)
+	}
+	r, err := s.Flush(context.Background())
+	require.NoError(t, err)
+	results = append(results, r...)
+
+	require.Equal(t, expected, results)
+}
+
+func TestCommand(t *testing.T) {
+	sm, samples := setup(t)
+	dir := t.TempDir()
+
+	// 假的 whisper.cpp：校验参数后输出带多余空白的文本
+	whisper := filepath.Join(dir, "whisper-cli")
+	require.NoError(t, os.WriteFile(whisper, []byte(`#!/bin/sh
+[ "$1" = -m ] && [ "$2" = model.bin ] && [ "$3" = -f ] && [ -s "$4" ] && [ "$7" = -l ] || exit 1
+echo " hello"
+echo " world  "
+`), 0o755))
+
+	results, err := Segments(context.Background(), sm, samples, WhisperCPP(whisper, "model.bin", "-l", "en"))
+	require.NoError(t, err)
+	require.NotEmpty(t, results)
+	for _, r := range results {
+		require.Equal(t, "hello world", r.Text)
+	}
+
+	// 没有占位参数时 WAV 通过标准输入传入：8kHz 16 位，44 字节文件头
+	wc := &Command{Path: "sh", Args: []string{"-c", "wc -c"}, SampleRate: 8000}
+	text, err := wc.Transcribe(context.Background(), make([]float32, 1600), 16000)
+	require.NoError(t, err)
+	require.Equal(t, strconv.Itoa(44+800*2), text)
+
+	fail := &Command{Path: "sh", Args: []string{"-c", "echo bad model >&2; exit 3"}}
+	_, err = fail.Transcribe(context.Background(), make([]float32, 160), 16000)
+	require.ErrorContains(t, err, "bad model")
+}
+
+func TestPipeline(t *testing.T) {
+	sm, samples := setup(t)
+
+	expected, err := Segments(context.Background(), sm, samples, lengthFunc)
+	require.NoError(t, err)
+
+	// 并发识别，结果仍按片段顺序返回
+	var active, peak atomic.Int32
+	slow := Func(func(ctx context.Context, pcm []float32, sampleRate int) (string, error) {
+		n := active.Add(1)
+		defer active.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		return lengthFunc(ctx, pcm, sampleRate)
+	})
+	p, err := NewPipeline(sm, slow, 3)
+	require.NoError(t, err)
+	results, err := p.Run(context.Background(), samples, 16000)
+	require.NoError(t, err)
+	require.Equal(t, expected, results)
+	if len(expected) > 1 {
+		require.Greater(t, peak.Load(), int32(1))
+	}
+	require.LessOrEqual(t, peak.Load(), int32(3))
+
+	// 输入采样率与模型不同时，识别器收到原始采样率的音频
+	pcm48k, err := audio.Resample(samples, 16000, 48000)
+	require.NoError(t, err)
+	var rates sync.Map
+	p, err = NewPipeline(sm, Func(func(_ context.Context, pcm []float32, sampleRate int) (string, error) {
+		rates.Store(sampleRate, true)
+		return "", nil
+	}), 0)
+	require.NoError(t, err)
+	results, err = p.Run(context.Background(), pcm48k, 48000)
+	require.NoError(t, err)
+	require.NotEmpty(t, results)
+	_, ok := rates.Load(48000)
+	require.True(t, ok)
+
+	// 第一个错误取消其余识别
+	var calls atomic.Int32
+	p, err = NewPipeline(sm, Func(func(ctx context.Context, _ []float32, _ int) (string, error) {
+		if calls.Add(1) == 1 {
+			return "", errors.New("boom")
+		}
+		<-ctx.Done()
+		return "", ctx.Err()
+	}), 2)
+	require.NoError(t, err)
+	_, err = p.Run(context.Background(), samples, 16000)
+	require.ErrorContains(t, err, "boom")
+
+	_, err = NewPipeline(sm, nil, 1)
+	require.Error(t, err)
+}